@@ -0,0 +1,143 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package block
+
+import (
+	"io/ioutil"
+
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/transaction"
+)
+
+func getHeaderFormatByHeight(dbase db.Database, height int64) (*V2HeaderFormat, error) {
+	hash, err := GetBlockHeaderHashByHeight(dbase, nil, height)
+	if err != nil {
+		return nil, err
+	}
+	hb, err := db.NewCodedBucket(dbase, db.BytesByHash, nil)
+	if err != nil {
+		return nil, err
+	}
+	hf := new(V2HeaderFormat)
+	if err := hb.Get(db.Raw(hash), hf); err != nil {
+		return nil, err
+	}
+	return hf, nil
+}
+
+func putHeaderFormat(dbase db.Database, hf *V2HeaderFormat) ([]byte, error) {
+	hb, err := db.NewCodedBucket(dbase, db.BytesByHash, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := hb.Put(hf); err != nil {
+		return nil, err
+	}
+	id := crypto.SHA3Sum256(v2Codec.MustMarshalToBytes(hf))
+	hh, err := db.NewCodedBucket(dbase, db.BlockHeaderHashByHeight, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := hh.Set(hf.Height, db.Raw(id)); err != nil {
+		return nil, err
+	}
+	return id, nil
+}
+
+// ForkBlocks rewrites the last three blocks up to the height in the
+// database exported by ExportBlocks, so that the block of the height
+// becomes the last block of a new chain with the result and the validators.
+//
+// The new block of the height has the votes and no normal transactions.
+// Its previous block has the voters as next validators, so the voters
+// may sign the votes for the new block. The block before has no next
+// validators, so empty votes are valid for the new block. The voters and
+// the validators should be flushed to the database by the caller.
+//
+// It returns the serialized bytes of the new block of the height.
+func ForkBlocks(
+	dbase db.Database, height int64, result []byte,
+	voters, validators module.ValidatorList, votes module.CommitVoteSet,
+) ([]byte, error) {
+	if height < 2 {
+		return nil, errors.IllegalArgumentError.Errorf(
+			"InvalidHeight(height=%d)", height)
+	}
+	var prevID []byte
+	var hf *V2HeaderFormat
+	for h := height - 2; h <= height; h++ {
+		var err error
+		if hf, err = getHeaderFormatByHeight(dbase, h); err != nil {
+			return nil, errors.Wrapf(err, "fail to get block height=%d", h)
+		}
+		if hf.Version != module.BlockVersion2 {
+			return nil, errors.UnsupportedError.Errorf(
+				"UnsupportedBlockVersion(height=%d,version=%d)", h, hf.Version)
+		}
+		if prevID != nil {
+			hf.PrevID = prevID
+		}
+		switch h {
+		case height - 2:
+			hf.NextValidatorsHash = nil
+		case height - 1:
+			hf.NextValidatorsHash = voters.Hash()
+		default:
+			hf.NextValidatorsHash = validators.Hash()
+			hf.NormalTransactionsHash = transaction.NewTransactionListFromSlice(dbase, nil).Hash()
+			hf.Result = result
+			hf.VotesHash = votes.Hash()
+			bk, err := dbase.GetBucket(db.BytesByHash)
+			if err != nil {
+				return nil, err
+			}
+			if err := bk.Set(votes.Hash(), votes.Bytes()); err != nil {
+				return nil, err
+			}
+		}
+		if prevID, err = putHeaderFormat(dbase, hf); err != nil {
+			return nil, err
+		}
+	}
+
+	patches := transaction.NewTransactionListFromHash(dbase, hf.PatchTransactionsHash)
+	ptBss, err := bssFromTransactionList(patches)
+	if err != nil {
+		return nil, err
+	}
+	bd, err := GetBTPDigestFromResult(dbase, nil, result)
+	if err != nil {
+		return nil, err
+	}
+	bf := &V2BodyFormat{
+		PatchTransactions:  ptBss,
+		NormalTransactions: [][]byte{},
+		Votes:              votes.Bytes(),
+		BTPDigest:          bd.Bytes(),
+	}
+	if err := SetLastHeight(dbase, nil, height); err != nil {
+		return nil, err
+	}
+	bs, err := ioutil.ReadAll(NewBlockReaderFromFormat(hf, bf))
+	if err != nil {
+		return nil, err
+	}
+	return bs, nil
+}
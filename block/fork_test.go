@@ -0,0 +1,105 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package block_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/block"
+	"github.com/icon-project/goloop/chain/gs"
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/wallet"
+	"github.com/icon-project/goloop/consensus"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service"
+	"github.com/icon-project/goloop/service/state"
+	"github.com/icon-project/goloop/test"
+)
+
+func newValidatorSnapshotForTest(t *testing.T, dbase db.Database, addrs ...module.Address) state.ValidatorSnapshot {
+	var vl []module.Validator
+	for _, addr := range addrs {
+		v, err := state.ValidatorFromAddress(addr)
+		assert.NoError(t, err)
+		vl = append(vl, v)
+	}
+	vss, err := state.ValidatorSnapshotFromSlice(dbase, vl)
+	assert.NoError(t, err)
+	assert.NoError(t, vss.Flush())
+	return vss
+}
+
+func TestForkBlocks(t *testing.T) {
+	nd := test.NewNode(t)
+	defer nd.Close()
+	assert := assert.New(t)
+
+	for i := 0; i < 3; i++ {
+		nd.ProposeFinalizeBlock(consensus.NewEmptyCommitVoteList())
+	}
+	const height, cid = 3, 0x123456
+	blk := nd.GetLastBlock()
+
+	dbase := db.NewMapDB()
+	assert.NoError(nd.BM.ExportBlocks(height, height, dbase, nil))
+	result, err := service.ForkResult(dbase, blk.Result(), nd.Chain.NID(), cid, nil)
+	assert.NoError(err)
+
+	w := nd.Chain.Wallet()
+	voters := newValidatorSnapshotForTest(t, dbase, w.Address())
+	validators := newValidatorSnapshotForTest(t, dbase, w.Address(), wallet.New().Address())
+	bs, err := block.ForkBlocks(dbase, height, result, voters, validators,
+		consensus.NewEmptyCommitVoteList())
+	assert.NoError(err)
+
+	bdf, err := block.NewBlockDataFactory(nd.Chain, nil)
+	assert.NoError(err)
+	fblk, err := bdf.NewBlockDataFromReader(bytes.NewReader(bs))
+	assert.NoError(err)
+	assert.EqualValues(height, fblk.Height())
+	assert.Empty(fblk.NormalTransactions().Hash())
+	votes, err := consensus.NewCommitVoteSetForBlock(w, fblk, fblk.Timestamp()+1)
+	assert.NoError(err)
+
+	genesis, err := json.Marshal(&gs.PrunedGenesis{
+		CID:    common.HexInt32{Value: cid},
+		NID:    common.HexInt32{Value: int32(nd.Chain.NID())},
+		Height: common.HexInt64{Value: height},
+		Block:  fblk.ID(),
+		Votes:  votes.Hash(),
+	})
+	assert.NoError(err)
+
+	// the forked chain starts from the new block and the voters may
+	// sign the votes for the block.
+	nd2 := test.NewNode(t, test.UseDB(dbase), test.UseGenesis(string(genesis)), test.UseWallet(w))
+	defer nd2.Close()
+	lastBlk := nd2.GetLastBlock()
+	assert.Equal(fblk.ID(), lastBlk.ID())
+	assert.Equal(validators.Hash(), lastBlk.NextValidatorsHash())
+	id, err := nd2.SM.GetChainID(lastBlk.Result())
+	assert.NoError(err)
+	assert.EqualValues(cid, id)
+
+	nd2.ProposeFinalizeBlock(votes)
+	assert.EqualValues(height+1, nd2.GetLastBlock().Height())
+}
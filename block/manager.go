@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"sync/atomic"
 
 	"github.com/icon-project/goloop/btp"
 	"github.com/icon-project/goloop/chain/base"
@@ -122,6 +123,9 @@ type manager struct {
 	finalizationCBs []finalizationCB
 	timestamper     module.Timestamper
 
+	// height of the last block pruned by Pruner, accessed atomically
+	prunedHeight int64
+
 	// pcm for last finalized block verification
 	pcmForLastBlock module.BTPProofContextMap
 	// next pcm in the last finalized block's result
@@ -594,10 +598,11 @@ func NewManager(
 			running: true,
 			srcUID:  module.GetSourceNetworkUID(chain),
 		},
-		nmap:        make(map[string]*bnode),
-		cache:       newCache(ConfigCacheCap),
-		timestamper: timestamper,
-		handlers:    handlers,
+		nmap:         make(map[string]*bnode),
+		cache:        newCache(ConfigCacheCap),
+		timestamper:  timestamper,
+		handlers:     handlers,
+		prunedHeight: -1,
 	}
 	m.activeHandlers = m.handlers.upTo(
 		m.sm.GetNextBlockVersion(nil),
@@ -613,6 +618,11 @@ func NewManager(
 	if err != nil {
 		return nil, err
 	}
+	if ps, err := loadPrunerState(m.db()); err != nil {
+		return nil, err
+	} else if ps != nil {
+		m.prunedHeight = ps.prunedHeight()
+	}
 
 	var height int64
 	err = chainPropBucket.Get(db.Raw(keyLastBlockHeight), &height)
@@ -1397,6 +1407,10 @@ func (m *manager) GetLastBlock() (module.Block, error) {
 	return m.finalized.block, nil
 }
 
+func (m *manager) PrunedHeight() int64 {
+	return atomic.LoadInt64(&m.prunedHeight)
+}
+
 func (m *manager) WaitForBlock(height int64) (<-chan module.Block, error) {
 	m.syncer.begin()
 	defer m.syncer.end()
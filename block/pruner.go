@@ -0,0 +1,795 @@
+package block
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/common/merkle"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/transaction"
+)
+
+const (
+	keyPrunerState   = "block.pruner"
+	keyPrunerJournal = "block.pruner.journal"
+
+	// ConfigMinPruneRetention is the minimum number of blocks whose bodies,
+	// receipts and states are kept by the pruner.
+	ConfigMinPruneRetention = 100
+)
+
+var errPrunerStopped = errors.ErrInterrupted
+
+// prunerState is stored in the database to continue pruning after restart.
+// Blocks from Pruned+1 to Tracked are counted as references of the data.
+// Garbage released by blocks from Collected+1 to Pruned isn't removed yet.
+// Genesis is the first block, and it's never pruned.
+type prunerState struct {
+	Genesis   int64
+	Tracked   int64
+	Pruned    int64
+	Collected int64
+}
+
+// prunedHeight returns the height of the last block whose data could be
+// removed. It returns -1 if nothing has been removed.
+func (s *prunerState) prunedHeight() int64 {
+	if s.Pruned > s.Genesis {
+		return s.Pruned
+	}
+	return -1
+}
+
+func loadPrunerState(dbase db.Database) (*prunerState, error) {
+	bk, err := db.NewCodedBucket(dbase, db.ChainProperty, nil)
+	if err != nil {
+		return nil, err
+	}
+	state := new(prunerState)
+	if err := bk.Get(db.Raw(keyPrunerState), state); err != nil {
+		if errors.NotFoundError.Equals(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	// garbage of the state without Collected was kept only in memory.
+	if state.Collected == 0 {
+		state.Collected = state.Pruned
+	}
+	return state, nil
+}
+
+func storePrunerState(dbase db.Database, state *prunerState) error {
+	bk, err := db.NewCodedBucket(dbase, db.ChainProperty, nil)
+	if err != nil {
+		return err
+	}
+	return bk.Set(db.Raw(keyPrunerState), state)
+}
+
+type dataKey struct {
+	bid db.BucketID
+	key []byte
+}
+
+func dataKeyFromRefKey(rk []byte) (dataKey, error) {
+	if len(rk) < 1 || len(rk) < 1+int(rk[0]) {
+		return dataKey{}, errors.CriticalFormatError.Errorf("InvalidRefKey(key=%x)", rk)
+	}
+	n := 1 + int(rk[0])
+	return dataKey{db.BucketID(rk[1:n]), rk[n:]}, nil
+}
+
+// prunerJournal has the changes of a block. It's written before applying
+// the changes, and it's applied again after restart if it remains, so the
+// changes of a block are applied only once.
+type prunerJournal struct {
+	State   prunerState
+	Counts  []refCount
+	Garbage [][]byte
+}
+
+type refCount struct {
+	Key   []byte
+	Count int64
+}
+
+func loadPrunerJournal(dbase db.Database) (*prunerJournal, error) {
+	bk, err := db.NewCodedBucket(dbase, db.ChainProperty, nil)
+	if err != nil {
+		return nil, err
+	}
+	j := new(prunerJournal)
+	if err := bk.Get(db.Raw(keyPrunerJournal), j); err != nil {
+		if errors.NotFoundError.Equals(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return j, nil
+}
+
+func storePrunerJournal(dbase db.Database, j *prunerJournal) error {
+	bk, err := dbase.GetBucket(db.ChainProperty)
+	if err != nil {
+		return err
+	}
+	if j == nil {
+		return bk.Delete([]byte(keyPrunerJournal))
+	}
+	return bk.Set([]byte(keyPrunerJournal), codec.BC.MustMarshalToBytes(j))
+}
+
+func garbageKeyOf(height int64) []byte {
+	return codec.BC.MustMarshalToBytes(height)
+}
+
+// refCounter keeps the number of references to the data. Counts are
+// cached in memory until flush. An entry is removed if it reaches zero.
+type refCounter struct {
+	bucket db.Bucket
+	counts map[string]int64
+}
+
+func refKeyOf(bid db.BucketID, key []byte) []byte {
+	buf := make([]byte, 0, 1+len(bid)+len(key))
+	buf = append(buf, byte(len(bid)))
+	buf = append(buf, bid...)
+	return append(buf, key...)
+}
+
+func (c *refCounter) get(bid db.BucketID, key []byte) (int64, error) {
+	rk := refKeyOf(bid, key)
+	if cnt, ok := c.counts[string(rk)]; ok {
+		return cnt, nil
+	}
+	bs, err := c.bucket.Get(rk)
+	if err != nil || bs == nil {
+		return 0, err
+	}
+	var cnt int64
+	if _, err := codec.BC.UnmarshalFromBytes(bs, &cnt); err != nil {
+		return 0, err
+	}
+	return cnt, nil
+}
+
+func (c *refCounter) set(bid db.BucketID, key []byte, cnt int64) {
+	c.counts[string(refKeyOf(bid, key))] = cnt
+}
+
+func (c *refCounter) size() int {
+	return len(c.counts)
+}
+
+// entries returns the counts cached in memory.
+func (c *refCounter) entries() []refCount {
+	entries := make([]refCount, 0, len(c.counts))
+	for k, cnt := range c.counts {
+		entries = append(entries, refCount{[]byte(k), cnt})
+	}
+	return entries
+}
+
+func (c *refCounter) write(entries []refCount) error {
+	for _, e := range entries {
+		var err error
+		if e.Count > 0 {
+			err = c.bucket.Set(e.Key, codec.BC.MustMarshalToBytes(e.Count))
+		} else {
+			err = c.bucket.Delete(e.Key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *refCounter) flush() error {
+	if err := c.write(c.entries()); err != nil {
+		return err
+	}
+	c.reset()
+	return nil
+}
+
+func (c *refCounter) reset() {
+	c.counts = make(map[string]int64)
+}
+
+func newRefCounter(dbase db.Database) (*refCounter, error) {
+	bk, err := dbase.GetBucket(db.ReferenceCount)
+	if err != nil {
+		return nil, err
+	}
+	return &refCounter{
+		bucket: bk,
+		counts: make(map[string]int64),
+	}, nil
+}
+
+type refRequest struct {
+	key        []byte
+	bucketIDs  []db.BucketID
+	requesters []merkle.DataRequester
+}
+
+type refRequestIterator struct {
+	element *list.Element
+	request *refRequest
+}
+
+func (i *refRequestIterator) Next() bool {
+	if i.element == nil {
+		return false
+	}
+	i.request = i.element.Value.(*refRequest)
+	i.element = i.element.Next()
+	return true
+}
+
+func (i *refRequestIterator) Key() []byte {
+	if i.request != nil {
+		return i.request.key
+	}
+	return nil
+}
+
+func (i *refRequestIterator) BucketIDs() []db.BucketID {
+	if i.request != nil {
+		return i.request.bucketIDs
+	}
+	return nil
+}
+
+// refWalker is a merkle.Builder updating reference counts of the data
+// instead of copying them. On adding, it follows the data only if it's
+// referenced at the first time. On releasing, it follows the data only if
+// it's not referenced anymore, and the data is collected as garbage.
+// Its database is always empty, so all the data are requested.
+type refWalker struct {
+	counter    *refCounter
+	release    bool
+	database   db.Database
+	requests   *list.List
+	reqMap     map[string]*list.Element
+	onDataMark *list.Element
+	resolved   int
+	garbage    []dataKey
+	err        error
+}
+
+func (w *refWalker) OnData(bid db.BucketID, value []byte) error {
+	hasher := bid.Hasher()
+	if hasher == nil {
+		return merkle.ErrNoHasher
+	}
+	key := hasher.Hash(value)
+	reqID := hasher.Name() + ":" + string(key)
+	e, ok := w.reqMap[reqID]
+	if !ok {
+		return merkle.ErrNoRequester
+	}
+	req := e.Value.(*refRequest)
+	w.onDataMark = e
+	defer func() {
+		w.onDataMark = nil
+	}()
+	for _, requester := range req.requesters {
+		if err := requester.OnData(value, w); err != nil {
+			return err
+		}
+	}
+	w.resolved += 1
+	w.requests.Remove(e)
+	delete(w.reqMap, reqID)
+	return w.err
+}
+
+func (w *refWalker) UnresolvedCount() int {
+	return w.requests.Len()
+}
+
+func (w *refWalker) ResolvedCount() int {
+	return w.resolved
+}
+
+func (w *refWalker) Requests() merkle.RequestIterator {
+	return &refRequestIterator{
+		element: w.requests.Front(),
+	}
+}
+
+func (w *refWalker) RequestData(bid db.BucketID, key []byte, requester merkle.DataRequester) {
+	if key == nil || w.err != nil {
+		return
+	}
+	hasher := bid.Hasher()
+	if hasher == nil {
+		return
+	}
+
+	cnt, err := w.counter.get(bid, key)
+	if err != nil {
+		w.err = err
+		return
+	}
+	if w.release {
+		// it's not counted, so it may be used by untracked blocks.
+		if cnt <= 0 {
+			return
+		}
+		cnt -= 1
+	} else {
+		cnt += 1
+	}
+	w.counter.set(bid, key, cnt)
+	if w.release {
+		if cnt > 0 {
+			return
+		}
+		w.garbage = append(w.garbage, dataKey{bid, key})
+	} else if cnt > 1 {
+		return
+	}
+
+	reqID := hasher.Name() + ":" + string(key)
+	if e, ok := w.reqMap[reqID]; ok {
+		req := e.Value.(*refRequest)
+		req.bucketIDs = append(req.bucketIDs, bid)
+		req.requesters = append(req.requesters, requester)
+		return
+	}
+	req := &refRequest{
+		key:        key,
+		bucketIDs:  []db.BucketID{bid},
+		requesters: []merkle.DataRequester{requester},
+	}
+	var e *list.Element
+	if w.onDataMark != nil {
+		e = w.requests.InsertAfter(req, w.onDataMark)
+		w.onDataMark = e
+	} else {
+		e = w.requests.PushBack(req)
+	}
+	w.reqMap[reqID] = e
+}
+
+func (w *refWalker) Database() db.Database {
+	return w.database
+}
+
+func (w *refWalker) Flush(write bool) error {
+	return nil
+}
+
+func newRefWalker(counter *refCounter, release bool) *refWalker {
+	return &refWalker{
+		counter:  counter,
+		release:  release,
+		database: db.NewNullDB(),
+		requests: list.New(),
+		reqMap:   make(map[string]*list.Element),
+	}
+}
+
+// Pruner removes bodies, receipts and states of blocks out of the retention
+// window while the chain is running. Headers and votes are kept. It tracks
+// the number of references to the data from the blocks in the window, and
+// removes the data only if there is no reference to it.
+//
+// Changes of reference counts by a block are written with the state at once
+// through the journal, and keys of the data released by the block are kept
+// in the database until they are removed, so it continues after restart
+// without leaking or double counting.
+//
+// Data of the blocks finalized before starting the pruner are not tracked,
+// so they are not removed.
+type Pruner struct {
+	m         *manager
+	log       log.Logger
+	retention int64
+	counter   *refCounter
+	state     prunerState
+
+	// resolve requests data of the block to the builder of the context.
+	resolve func(blk module.Block, ctx *merkle.CopyContext) error
+
+	notify   chan struct{}
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// NewPruner returns a pruner keeping data of the last retention blocks.
+func NewPruner(bm module.BlockManager, retention int64) (*Pruner, error) {
+	m, ok := bm.(*manager)
+	if !ok {
+		return nil, errors.UnsupportedError.New("UnsupportedBlockManager")
+	}
+	if retention < ConfigMinPruneRetention {
+		return nil, errors.IllegalArgumentError.Errorf(
+			"InvalidRetention(retention=%d,min=%d)", retention, ConfigMinPruneRetention)
+	}
+	counter, err := newRefCounter(m.db())
+	if err != nil {
+		return nil, err
+	}
+	p := &Pruner{
+		m: m,
+		log: m.log.WithFields(log.Fields{
+			log.FieldKeyModule: "BM|PRUNE",
+		}),
+		retention: retention,
+		counter:   counter,
+		notify:    make(chan struct{}, 1),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	p.resolve = p.resolveBlock
+	return p, nil
+}
+
+// Start starts pruning in background. It stops on Stop or on termination
+// of the block manager.
+func (p *Pruner) Start() error {
+	p.m.syncer.begin()
+	defer p.m.syncer.end()
+
+	if !p.m.running {
+		return errors.InvalidStateError.New("NotRunning")
+	}
+	p.m.finalizationCBs = append(p.m.finalizationCBs, p.onFinalize)
+	go p.run()
+	return nil
+}
+
+// Stop stops pruning and waits for the background job to finish.
+func (p *Pruner) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.stop)
+	})
+	<-p.done
+}
+
+func (p *Pruner) onFinalize(blk module.Block) bool {
+	if blk == nil {
+		p.stopOnce.Do(func() {
+			close(p.stop)
+		})
+		return true
+	}
+	select {
+	case p.notify <- struct{}{}:
+	default:
+	}
+	return false
+}
+
+func (p *Pruner) stopped() bool {
+	select {
+	case <-p.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *Pruner) run() {
+	defer close(p.done)
+
+	if err := p.init(); err != nil {
+		if err != errPrunerStopped {
+			p.log.Errorf("Fail to initialize pruner err=%+v", err)
+		}
+		return
+	}
+	for {
+		if err := p.prune(); err != nil {
+			if err != errPrunerStopped {
+				p.log.Errorf("Pruner stops by error err=%+v", err)
+			}
+			return
+		}
+		select {
+		case <-p.stop:
+			return
+		case <-p.notify:
+		}
+	}
+}
+
+func (p *Pruner) saveState() error {
+	if err := storePrunerState(p.m.db(), &p.state); err != nil {
+		return err
+	}
+	atomic.StoreInt64(&p.m.prunedHeight, p.state.prunedHeight())
+	return nil
+}
+
+// commit writes the reference counts in memory, the garbage released by
+// the block of the pruned height and the state through the journal.
+func (p *Pruner) commit(garbage []dataKey) error {
+	j := &prunerJournal{
+		State:  p.state,
+		Counts: p.counter.entries(),
+	}
+	for _, g := range garbage {
+		j.Garbage = append(j.Garbage, refKeyOf(g.bid, g.key))
+	}
+	if err := storePrunerJournal(p.m.db(), j); err != nil {
+		return err
+	}
+	if err := p.apply(j); err != nil {
+		return err
+	}
+	p.counter.reset()
+	return nil
+}
+
+// apply applies the journal. It may be applied more than once, because
+// counts in the journal are not increments.
+func (p *Pruner) apply(j *prunerJournal) error {
+	if err := p.counter.write(j.Counts); err != nil {
+		return err
+	}
+	if len(j.Garbage) > 0 {
+		bk, err := p.m.db().GetBucket(db.PrunerGarbage)
+		if err != nil {
+			return err
+		}
+		err = bk.Set(garbageKeyOf(j.State.Pruned), codec.BC.MustMarshalToBytes(j.Garbage))
+		if err != nil {
+			return err
+		}
+	}
+	p.state = j.State
+	if err := p.saveState(); err != nil {
+		return err
+	}
+	return storePrunerJournal(p.m.db(), nil)
+}
+
+// init loads the state or starts tracking with the blocks in the window.
+// The genesis block is tracked but never released, so that it's kept. The
+// genesis block without data (ex: a node synced its state) isn't tracked.
+func (p *Pruner) init() error {
+	j, err := loadPrunerJournal(p.m.db())
+	if err != nil {
+		return err
+	}
+	if j != nil {
+		p.log.Infof("Apply the journal of the pruner tracked=%d pruned=%d",
+			j.State.Tracked, j.State.Pruned)
+		if err := p.apply(j); err != nil {
+			return err
+		}
+	}
+	state, err := loadPrunerState(p.m.db())
+	if err != nil {
+		return err
+	}
+	if state != nil {
+		p.state = *state
+		return nil
+	}
+
+	last, err := p.m.GetLastBlock()
+	if err != nil {
+		return err
+	}
+	var genesis int64
+	if gs := p.m.chain.GenesisStorage(); gs != nil {
+		genesis = gs.Height()
+	}
+	if blk, err := p.m.GetBlockByHeight(genesis); err == nil {
+		if _, err := p.walk(blk, false); err != nil {
+			p.counter.reset()
+			if !errors.NotFoundError.Equals(err) {
+				return err
+			}
+			p.log.Infof("Genesis with missing data isn't tracked height=%d err=%v", genesis, err)
+		}
+	}
+	base := last.Height() - p.retention + 1
+	if base <= genesis {
+		base = genesis + 1
+	}
+	p.state = prunerState{
+		Genesis:   genesis,
+		Tracked:   base - 1,
+		Pruned:    base - 1,
+		Collected: base - 1,
+	}
+	p.log.Infof("Start pruning genesis=%d base=%d retention=%d", genesis, base, p.retention)
+	return p.commit(nil)
+}
+
+func (p *Pruner) onProgress(height int64, resolved, unresolved int) error {
+	if p.stopped() {
+		return errPrunerStopped
+	}
+	return nil
+}
+
+// resolveBlock requests the result and transactions of the block.
+func (p *Pruner) resolveBlock(blk module.Block, ctx *merkle.CopyContext) error {
+	// validators are used for verifying headers, so they are not walked.
+	if err := p.m.sm.ExportResult(blk.Result(), nil, ctx.TargetDB()); err != nil {
+		return err
+	}
+	if blk.Version() == module.BlockVersion2 {
+		transaction.NewTransactionListWithBuilder(ctx.Builder(), blk.PatchTransactions().Hash())
+		transaction.NewTransactionListWithBuilder(ctx.Builder(), blk.NormalTransactions().Hash())
+		return ctx.Run()
+	}
+	return nil
+}
+
+// walk adds or releases references from the result and transactions of
+// the block. Counts are kept in memory until commit, and it returns the
+// garbage released by the block.
+func (p *Pruner) walk(blk module.Block, release bool) ([]dataKey, error) {
+	w := newRefWalker(p.counter, release)
+	ctx := merkle.NewCopyContextWithBuilder(p.m.db(), w.Database(), w)
+	ctx.SetHeight(blk.Height())
+	ctx.SetProgressCallback(p.onProgress)
+	if err := p.resolve(blk, ctx); err != nil {
+		return nil, err
+	}
+	if w.err != nil {
+		return nil, w.err
+	}
+	return w.garbage, nil
+}
+
+// track adds references from the blocks up to the height. A block with
+// missing data is regarded as pruned, so tracked blocks before it are
+// released first. Their garbage is removed by collect.
+func (p *Pruner) track(to int64, get func(int64) (module.Block, error)) error {
+	for h := p.state.Tracked + 1; h <= to; h++ {
+		blk, err := get(h)
+		if err != nil {
+			return err
+		}
+		if _, err := p.walk(blk, false); err != nil {
+			p.counter.reset()
+			if !errors.NotFoundError.Equals(err) {
+				return err
+			}
+			p.log.Warnf("Block with missing data is regarded as pruned height=%d err=%v", h, err)
+			for p.state.Pruned+1 < h {
+				if err := p.releaseBlock(p.state.Pruned+1, get); err != nil {
+					return err
+				}
+			}
+			p.state.Pruned = h
+		}
+		p.state.Tracked = h
+		if err := p.commit(nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// release releases references from the blocks out of the window.
+func (p *Pruner) release() error {
+	last, err := p.m.GetLastBlock()
+	if err != nil {
+		return err
+	}
+	if err := p.track(last.Height(), p.m.GetBlockByHeight); err != nil {
+		return err
+	}
+	for h := p.state.Pruned + 1; h <= last.Height()-p.retention; h++ {
+		if p.stopped() {
+			return errPrunerStopped
+		}
+		if err := p.releaseBlock(h, p.m.GetBlockByHeight); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseBlock releases references from the block next to the pruned one,
+// and commits them with the garbage. References from a block with missing
+// data are kept, because they can't be released partially.
+func (p *Pruner) releaseBlock(h int64, get func(int64) (module.Block, error)) error {
+	blk, err := get(h)
+	if err != nil {
+		return err
+	}
+	garbage, err := p.walk(blk, true)
+	if err != nil {
+		p.counter.reset()
+		if !errors.NotFoundError.Equals(err) {
+			return err
+		}
+		p.log.Warnf("Fail to release block height=%d err=%v", h, err)
+		garbage = nil
+	}
+	p.state.Pruned = h
+	return p.commit(garbage)
+}
+
+// prune releases references from the blocks out of the window, then
+// removes the data without references.
+func (p *Pruner) prune() error {
+	if err := p.release(); err != nil {
+		return err
+	}
+	return p.collect()
+}
+
+// collect removes the garbage. Blocks finalized after releasing may refer
+// some of them again, so it tracks them before removal with the lock.
+func (p *Pruner) collect() error {
+	if p.state.Collected >= p.state.Pruned {
+		return nil
+	}
+	p.m.syncer.begin()
+	defer p.m.syncer.end()
+
+	if !p.m.running {
+		return errPrunerStopped
+	}
+	if err := p.track(p.m.finalized.block.Height(), p.m.getBlockByHeight); err != nil {
+		return err
+	}
+	gbk, err := p.m.db().GetBucket(db.PrunerGarbage)
+	if err != nil {
+		return err
+	}
+	removed := 0
+	for h := p.state.Collected + 1; h <= p.state.Pruned; h++ {
+		bs, err := gbk.Get(garbageKeyOf(h))
+		if err != nil {
+			return err
+		}
+		if bs == nil {
+			continue
+		}
+		var keys [][]byte
+		if _, err := codec.BC.UnmarshalFromBytes(bs, &keys); err != nil {
+			return err
+		}
+		for _, rk := range keys {
+			g, err := dataKeyFromRefKey(rk)
+			if err != nil {
+				return err
+			}
+			cnt, err := p.counter.get(g.bid, g.key)
+			if err != nil {
+				return err
+			}
+			if cnt > 0 {
+				continue
+			}
+			bk, err := p.m.db().GetBucket(g.bid)
+			if err != nil {
+				return err
+			}
+			if err := bk.Delete(g.key); err != nil {
+				return err
+			}
+			removed += 1
+		}
+		if err := gbk.Delete(garbageKeyOf(h)); err != nil {
+			return err
+		}
+	}
+	p.state.Collected = p.state.Pruned
+	if err := p.saveState(); err != nil {
+		return err
+	}
+	p.log.Debugf("Pruned height=%d removed=%d", p.state.Pruned, removed)
+	return nil
+}
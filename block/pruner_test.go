@@ -0,0 +1,422 @@
+package block
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/merkle"
+	"github.com/icon-project/goloop/common/trie"
+	"github.com/icon-project/goloop/common/trie/trie_manager"
+	"github.com/icon-project/goloop/module"
+)
+
+func walkTrieForTest(t *testing.T, dbase db.Database, counter *refCounter, h []byte, release bool) []dataKey {
+	w := newRefWalker(counter, release)
+	ctx := merkle.NewCopyContextWithBuilder(dbase, w.Database(), w)
+	trie_manager.NewImmutable(w.Database(), h).Resolve(w)
+	assert.NoError(t, ctx.Run())
+	assert.NoError(t, w.err)
+	return w.garbage
+}
+
+func removeGarbageForTest(t *testing.T, dbase db.Database, counter *refCounter, garbage []dataKey) int {
+	removed := 0
+	for _, g := range garbage {
+		cnt, err := counter.get(g.bid, g.key)
+		assert.NoError(t, err)
+		if cnt > 0 {
+			continue
+		}
+		bk, err := dbase.GetBucket(g.bid)
+		assert.NoError(t, err)
+		assert.NoError(t, bk.Delete(g.key))
+		removed += 1
+	}
+	return removed
+}
+
+func assertTrieForTest(t *testing.T, dbase db.Database, h []byte, values map[string]string) {
+	snapshot := trie_manager.NewImmutable(dbase, h)
+	for k, v := range values {
+		value, err := snapshot.Get([]byte(k))
+		assert.NoError(t, err)
+		assert.Equal(t, []byte(v), value)
+	}
+}
+
+func newTrieForTest(t *testing.T, dbase db.Database, base trie.Immutable, values map[string]string) trie.Immutable {
+	var mt trie.Mutable
+	if base == nil {
+		mt = trie_manager.NewMutable(dbase, nil)
+	} else {
+		mt = trie_manager.NewMutableFromImmutable(base)
+	}
+	for k, v := range values {
+		_, err := mt.Set([]byte(k), []byte(v))
+		assert.NoError(t, err)
+	}
+	ss := mt.GetSnapshot()
+	assert.NoError(t, ss.Flush())
+	return ss
+}
+
+func TestRefWalker_AddRelease(t *testing.T) {
+	dbase := db.NewMapDB()
+	counter, err := newRefCounter(dbase)
+	assert.NoError(t, err)
+
+	values1 := make(map[string]string)
+	for i := 0; i < 100; i++ {
+		values1[fmt.Sprintf("key%d", i)] = fmt.Sprintf("value%d", i)
+	}
+	s1 := newTrieForTest(t, dbase, nil, values1)
+
+	changes := map[string]string{
+		"key0":   "changed",
+		"key100": "added",
+	}
+	s2 := newTrieForTest(t, dbase, s1, changes)
+	values2 := make(map[string]string)
+	for k, v := range values1 {
+		values2[k] = v
+	}
+	for k, v := range changes {
+		values2[k] = v
+	}
+
+	// add references, and shared nodes are counted twice.
+	garbage := walkTrieForTest(t, dbase, counter, s1.Hash(), false)
+	assert.Empty(t, garbage)
+	garbage = walkTrieForTest(t, dbase, counter, s2.Hash(), false)
+	assert.Empty(t, garbage)
+	assert.NoError(t, counter.flush())
+
+	cnt, err := counter.get(db.MerkleTrie, s1.Hash())
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+
+	// release the first, then only nodes of the first are removed.
+	garbage = walkTrieForTest(t, dbase, counter, s1.Hash(), true)
+	assert.NotEmpty(t, garbage)
+	assert.NoError(t, counter.flush())
+	assert.Equal(t, len(garbage), removeGarbageForTest(t, dbase, counter, garbage))
+
+	bk, err := dbase.GetBucket(db.MerkleTrie)
+	assert.NoError(t, err)
+	value, err := bk.Get(s1.Hash())
+	assert.NoError(t, err)
+	assert.Nil(t, value)
+	assertTrieForTest(t, dbase, s2.Hash(), values2)
+
+	// release the second, then nothing is left.
+	garbage = walkTrieForTest(t, dbase, counter, s2.Hash(), true)
+	assert.NoError(t, counter.flush())
+	removeGarbageForTest(t, dbase, counter, garbage)
+	value, err = bk.Get(s2.Hash())
+	assert.NoError(t, err)
+	assert.Nil(t, value)
+
+	rbk, err := dbase.GetBucket(db.ReferenceCount)
+	assert.NoError(t, err)
+	for _, g := range garbage {
+		value, err := rbk.Get(refKeyOf(g.bid, g.key))
+		assert.NoError(t, err)
+		assert.Nil(t, value)
+	}
+
+	// releasing again is ignored.
+	garbage = walkTrieForTest(t, dbase, counter, s2.Hash(), true)
+	assert.Empty(t, garbage)
+}
+
+func TestRefWalker_Revive(t *testing.T) {
+	dbase := db.NewMapDB()
+	counter, err := newRefCounter(dbase)
+	assert.NoError(t, err)
+
+	values := map[string]string{
+		"key1": "value1",
+		"key2": "value2",
+		"key3": "value3",
+	}
+	s1 := newTrieForTest(t, dbase, nil, values)
+	walkTrieForTest(t, dbase, counter, s1.Hash(), false)
+	garbage := walkTrieForTest(t, dbase, counter, s1.Hash(), true)
+	assert.NotEmpty(t, garbage)
+
+	// same trie is referenced again before removal.
+	walkTrieForTest(t, dbase, counter, s1.Hash(), false)
+	assert.Zero(t, removeGarbageForTest(t, dbase, counter, garbage))
+	assertTrieForTest(t, dbase, s1.Hash(), values)
+}
+
+func TestPrunerState(t *testing.T) {
+	dbase := db.NewMapDB()
+	ps, err := loadPrunerState(dbase)
+	assert.NoError(t, err)
+	assert.Nil(t, ps)
+
+	state := &prunerState{Genesis: 0, Tracked: 10, Pruned: 0}
+	assert.NoError(t, storePrunerState(dbase, state))
+	ps, err = loadPrunerState(dbase)
+	assert.NoError(t, err)
+	assert.Equal(t, state, ps)
+	assert.EqualValues(t, -1, ps.prunedHeight())
+
+	ps.Pruned = 3
+	assert.EqualValues(t, 3, ps.prunedHeight())
+}
+
+// prunerTestSetUp has blocks having a trie as their data. Pruners of the
+// set up walk the trie of the block instead of its result and transactions.
+type prunerTestSetUp struct {
+	*blockManagerTestSetUp
+	t      *testing.T
+	tries  map[int64]trie.Immutable
+	values map[int64]map[string]string
+}
+
+func newPrunerTestSetUp(t *testing.T) *prunerTestSetUp {
+	s := &prunerTestSetUp{
+		blockManagerTestSetUp: newBlockManagerTestSetUp(t),
+		t:                     t,
+		tries:                 make(map[int64]trie.Immutable),
+		values:                make(map[int64]map[string]string),
+	}
+	values := make(map[string]string)
+	for i := 0; i < 50; i++ {
+		values[fmt.Sprintf("key%d", i)] = fmt.Sprintf("value%d", i)
+	}
+	s.tries[0] = newTrieForTest(t, s.database, nil, values)
+	s.values[0] = values
+	return s
+}
+
+// finalizeWith finalizes a block having the trie of the height, or a new
+// trie changing a value of the previous block if the height is negative.
+func (s *prunerTestSetUp) finalizeWith(height int64) int64 {
+	last, err := s.bm.GetLastBlock()
+	assert.NoError(s.t, err)
+	br := proposeSync(s.bm, last.ID(), newCommitVoteSetWithTimestamp(true, last.Height()))
+	assert.NoError(s.t, br.err)
+	assert.NoError(s.t, br.cberr)
+	assert.NoError(s.t, s.bm.Finalize(br.blk))
+
+	h := last.Height() + 1
+	if height >= 0 {
+		s.tries[h], s.values[h] = s.tries[height], s.values[height]
+		return h
+	}
+	values := make(map[string]string)
+	for k, v := range s.values[h-1] {
+		values[k] = v
+	}
+	values["key0"] = fmt.Sprintf("changed%d", h)
+	s.tries[h] = newTrieForTest(s.t, s.database, s.tries[h-1], map[string]string{"key0": values["key0"]})
+	s.values[h] = values
+	return h
+}
+
+func (s *prunerTestSetUp) finalizeUntil(height int64) {
+	for {
+		if h := s.finalizeWith(-1); h >= height {
+			return
+		}
+	}
+}
+
+func (s *prunerTestSetUp) newPruner(retention int64) *Pruner {
+	p, err := NewPruner(s.bm, ConfigMinPruneRetention)
+	assert.NoError(s.t, err)
+	p.retention = retention
+	p.resolve = func(blk module.Block, ctx *merkle.CopyContext) error {
+		trie_manager.NewImmutable(ctx.Builder().Database(), s.tries[blk.Height()].Hash()).Resolve(ctx.Builder())
+		return ctx.Run()
+	}
+	return p
+}
+
+func (s *prunerTestSetUp) hasRoot(height int64) bool {
+	bk, err := s.database.GetBucket(db.MerkleTrie)
+	assert.NoError(s.t, err)
+	value, err := bk.Get(s.tries[height].Hash())
+	assert.NoError(s.t, err)
+	return value != nil
+}
+
+func (s *prunerTestSetUp) assertKept(heights ...int64) {
+	for _, h := range heights {
+		assertTrieForTest(s.t, s.database, s.tries[h].Hash(), s.values[h])
+	}
+}
+
+func TestPruner_ReviveBeforeCollect(t *testing.T) {
+	s := newPrunerTestSetUp(t)
+	s.finalizeUntil(3)
+
+	p := s.newPruner(2)
+	assert.NoError(t, p.init())
+	assert.Equal(t, prunerState{Genesis: 0, Tracked: 1, Pruned: 1, Collected: 1}, p.state)
+
+	s.finalizeUntil(5)
+	assert.NoError(t, p.release())
+	assert.EqualValues(t, 5, p.state.Tracked)
+	assert.EqualValues(t, 3, p.state.Pruned)
+	assert.EqualValues(t, 1, p.state.Collected)
+	assert.True(t, s.hasRoot(2))
+	assert.True(t, s.hasRoot(3))
+
+	// the block finalized after releasing refers the data of the block 2
+	s.finalizeWith(2)
+	assert.NoError(t, p.collect())
+	assert.EqualValues(t, 6, p.state.Tracked)
+	assert.EqualValues(t, 3, p.state.Collected)
+
+	assert.False(t, s.hasRoot(3))
+	s.assertKept(0, 1, 2, 4, 5, 6)
+
+	gbk, err := s.database.GetBucket(db.PrunerGarbage)
+	assert.NoError(t, err)
+	for _, h := range []int64{2, 3} {
+		value, err := gbk.Get(garbageKeyOf(h))
+		assert.NoError(t, err)
+		assert.Nil(t, value)
+	}
+}
+
+func TestPruner_CollectAfterRestart(t *testing.T) {
+	s := newPrunerTestSetUp(t)
+	s.finalizeUntil(3)
+
+	p := s.newPruner(2)
+	assert.NoError(t, p.init())
+	s.finalizeUntil(5)
+	assert.NoError(t, p.release())
+
+	// garbage released before restart is collected after restart
+	p = s.newPruner(2)
+	assert.NoError(t, p.init())
+	assert.Equal(t, prunerState{Genesis: 0, Tracked: 5, Pruned: 3, Collected: 1}, p.state)
+	assert.NoError(t, p.collect())
+
+	assert.False(t, s.hasRoot(2))
+	assert.False(t, s.hasRoot(3))
+	s.assertKept(0, 1, 4, 5)
+}
+
+func TestPruner_TrackAfterFailure(t *testing.T) {
+	s := newPrunerTestSetUp(t)
+	s.finalizeUntil(3)
+
+	p := s.newPruner(2)
+	assert.NoError(t, p.init())
+
+	// it fails after walking the block 4, then nothing is written for it
+	s.finalizeUntil(4)
+	resolve := p.resolve
+	p.resolve = func(blk module.Block, ctx *merkle.CopyContext) error {
+		if err := resolve(blk, ctx); err != nil {
+			return err
+		}
+		if blk.Height() == 4 {
+			return errors.InvalidStateError.New("Crash")
+		}
+		return nil
+	}
+	assert.Error(t, p.release())
+	assert.EqualValues(t, 3, p.state.Tracked)
+
+	// references of the block 4 are counted once after restart
+	p = s.newPruner(2)
+	assert.NoError(t, p.init())
+	s.finalizeUntil(6)
+	assert.NoError(t, p.prune())
+	assert.EqualValues(t, 4, p.state.Collected)
+
+	assert.False(t, s.hasRoot(4))
+	s.assertKept(0, 1, 5, 6)
+}
+
+func TestPruner_TrackMissingData(t *testing.T) {
+	s := newPrunerTestSetUp(t)
+	s.finalizeUntil(3)
+
+	p := s.newPruner(2)
+	assert.NoError(t, p.init())
+
+	// data of the block 5 is missing after walking a part of it
+	s.finalizeUntil(6)
+	resolve := p.resolve
+	p.resolve = func(blk module.Block, ctx *merkle.CopyContext) error {
+		if err := resolve(blk, ctx); err != nil {
+			return err
+		}
+		if blk.Height() == 5 {
+			return errors.NotFoundError.New("MissingData")
+		}
+		return nil
+	}
+	assert.NoError(t, p.release())
+	assert.Equal(t, prunerState{Genesis: 0, Tracked: 6, Pruned: 5, Collected: 1}, p.state)
+	cnt, err := p.counter.get(db.MerkleTrie, s.tries[5].Hash())
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, cnt)
+
+	// garbage of the blocks released before the block 5 is collected
+	assert.NoError(t, p.collect())
+	assert.EqualValues(t, 5, p.state.Collected)
+	for _, h := range []int64{2, 3, 4} {
+		assert.False(t, s.hasRoot(h))
+	}
+	s.assertKept(0, 1, 6)
+}
+
+func TestPruner_InitWithoutGenesisData(t *testing.T) {
+	s := newPrunerTestSetUp(t)
+	s.finalizeUntil(3)
+
+	p := s.newPruner(2)
+	resolve := p.resolve
+	p.resolve = func(blk module.Block, ctx *merkle.CopyContext) error {
+		if blk.Height() == 0 {
+			return errors.NotFoundError.New("MissingData")
+		}
+		return resolve(blk, ctx)
+	}
+	assert.NoError(t, p.init())
+	assert.Equal(t, prunerState{Genesis: 0, Tracked: 1, Pruned: 1, Collected: 1}, p.state)
+	cnt, err := p.counter.get(db.MerkleTrie, s.tries[0].Hash())
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, cnt)
+}
+
+func TestPruner_ApplyJournal(t *testing.T) {
+	s := newPrunerTestSetUp(t)
+	s.finalizeUntil(3)
+
+	p := s.newPruner(2)
+	assert.NoError(t, p.init())
+
+	// changes of a block written partially are applied again on restart
+	rk := refKeyOf(db.MerkleTrie, s.tries[3].Hash())
+	j := &prunerJournal{
+		State:   prunerState{Genesis: 0, Tracked: 3, Pruned: 2, Collected: 1},
+		Counts:  []refCount{{rk, 0}},
+		Garbage: [][]byte{rk},
+	}
+	assert.NoError(t, storePrunerJournal(s.database, j))
+
+	p = s.newPruner(2)
+	assert.NoError(t, p.init())
+	assert.Equal(t, j.State, p.state)
+	j2, err := loadPrunerJournal(s.database)
+	assert.NoError(t, err)
+	assert.Nil(t, j2)
+
+	assert.NoError(t, p.collect())
+	assert.False(t, s.hasRoot(3))
+}
@@ -0,0 +1,143 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSchedule_Interval(t *testing.T) {
+	s, err := ParseSchedule("6h")
+	assert.NoError(t, err)
+	last := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, last.Add(6*time.Hour), s.Next(last))
+
+	_, err = ParseSchedule("10s")
+	assert.Error(t, err)
+}
+
+func TestParseSchedule_Cron(t *testing.T) {
+	cases := []struct {
+		expr string
+		last time.Time
+		next time.Time
+	}{
+		{
+			"0 3 * * *",
+			time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC),
+			time.Date(2023, 1, 2, 3, 0, 0, 0, time.UTC),
+		},
+		{
+			"0 3 * * *",
+			time.Date(2023, 1, 1, 2, 59, 30, 0, time.UTC),
+			time.Date(2023, 1, 1, 3, 0, 0, 0, time.UTC),
+		},
+		{
+			"*/15 * * * *",
+			time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC),
+			time.Date(2023, 1, 1, 10, 15, 0, 0, time.UTC),
+		},
+		{
+			// every Sunday, 2023-01-01 is Sunday
+			"30 1 * * 7",
+			time.Date(2023, 1, 1, 2, 0, 0, 0, time.UTC),
+			time.Date(2023, 1, 8, 1, 30, 0, 0, time.UTC),
+		},
+		{
+			// either of day of month or day of week
+			"0 0 15 * 1",
+			time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC),
+			time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			"0 0 1 3,6-7 *",
+			time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, c := range cases {
+		s, err := ParseSchedule(c.expr)
+		assert.NoError(t, err, c.expr)
+		assert.Equal(t, c.next, s.Next(c.last), c.expr)
+	}
+
+	for _, expr := range []string{
+		"", "* * * *", "60 * * * *", "* 24 * * *", "5-1 * * * *",
+		"*/0 * * * *", "a * * * *", "0 0 31 2 *",
+	} {
+		_, err := ParseSchedule(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestParseRetention(t *testing.T) {
+	r, err := ParseRetention("")
+	assert.NoError(t, err)
+	assert.Nil(t, r)
+
+	r, err = ParseRetention("7")
+	assert.NoError(t, err)
+	assert.Equal(t, &Retention{Last: 7}, r)
+
+	r, err = ParseRetention("last=2, daily=7,weekly=4")
+	assert.NoError(t, err)
+	assert.Equal(t, &Retention{Last: 2, Daily: 7, Weekly: 4}, r)
+
+	for _, s := range []string{"0", "-1", "monthly=3", "daily", "daily=x"} {
+		_, err := ParseRetention(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestRetention_Expired(t *testing.T) {
+	base := time.Date(2023, 1, 31, 3, 0, 0, 0, time.UTC)
+	var files []File
+	// two backups a day for 30 days
+	for i := 0; i < 60; i++ {
+		files = append(files, File{
+			Name: base.Add(-time.Duration(i) * 12 * time.Hour).Format("0102-15"),
+			Time: base.Add(-time.Duration(i) * 12 * time.Hour),
+		})
+	}
+
+	r := &Retention{Last: 3}
+	assert.Len(t, r.Expired(files), 57)
+
+	r = &Retention{Daily: 7}
+	expired := r.Expired(files)
+	assert.Len(t, expired, 53)
+	for _, f := range expired {
+		assert.NotEqual(t, "0131-03", f.Name)
+		assert.NotEqual(t, "0125-15", f.Name)
+	}
+
+	// 2023-01-31 is Tuesday, so the latest backups of the weeks are
+	// 01-31, 01-29, 01-22 and 01-15.
+	r = &Retention{Last: 1, Weekly: 4}
+	expired = r.Expired(files)
+	assert.Len(t, expired, 56)
+	names := make(map[string]bool)
+	for _, f := range expired {
+		names[f.Name] = true
+	}
+	for _, n := range []string{"0131-03", "0129-15", "0122-15", "0115-15"} {
+		assert.False(t, names[n], n)
+	}
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backup
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/icon-project/goloop/common/errors"
+)
+
+// Retention is the policy to keep backups. It keeps the latest Last
+// backups, the latest backup of each of the latest Daily days and
+// the latest backup of each of the latest Weekly weeks.
+type Retention struct {
+	Last   int
+	Daily  int
+	Weekly int
+}
+
+// ParseRetention parses the retention policy. It's the number of backups
+// to keep (e.g. "7") or comma separated tiers (e.g. "last=3,daily=7,weekly=4").
+// Empty string means keeping all backups.
+func ParseRetention(s string) (*Retention, error) {
+	s = strings.TrimSpace(s)
+	if len(s) == 0 {
+		return nil, nil
+	}
+	r := new(Retention)
+	if v, err := strconv.Atoi(s); err == nil {
+		r.Last = v
+	} else {
+		for _, item := range strings.Split(s, ",") {
+			kv := strings.SplitN(strings.TrimSpace(item), "=", 2)
+			if len(kv) != 2 {
+				return nil, errors.IllegalArgumentError.Errorf(
+					"InvalidRetention(retention=%q)", s)
+			}
+			v, err := strconv.Atoi(kv[1])
+			if err != nil {
+				return nil, errors.IllegalArgumentError.Wrapf(err,
+					"InvalidRetention(retention=%q)", s)
+			}
+			switch kv[0] {
+			case "last":
+				r.Last = v
+			case "daily":
+				r.Daily = v
+			case "weekly":
+				r.Weekly = v
+			default:
+				return nil, errors.IllegalArgumentError.Errorf(
+					"UnknownRetentionTier(tier=%q)", kv[0])
+			}
+		}
+	}
+	if r.Last < 0 || r.Daily < 0 || r.Weekly < 0 {
+		return nil, errors.IllegalArgumentError.Errorf(
+			"NegativeRetention(retention=%q)", s)
+	}
+	if r.Last+r.Daily+r.Weekly == 0 {
+		return nil, errors.IllegalArgumentError.Errorf(
+			"NothingToKeep(retention=%q)", s)
+	}
+	return r, nil
+}
+
+func (r *Retention) String() string {
+	return fmt.Sprintf("last=%d,daily=%d,weekly=%d", r.Last, r.Daily, r.Weekly)
+}
+
+type File struct {
+	Name string
+	Time time.Time
+}
+
+// Expired returns the files not kept by the policy.
+func (r *Retention) Expired(files []File) []File {
+	sorted := make([]File, len(files))
+	copy(sorted, files)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time.After(sorted[j].Time)
+	})
+
+	keep := make([]bool, len(sorted))
+	for i := 0; i < len(sorted) && i < r.Last; i++ {
+		keep[i] = true
+	}
+	keepLatestOf := func(limit int, period func(t time.Time) string) {
+		last := ""
+		for i := 0; i < len(sorted) && limit > 0; i++ {
+			if p := period(sorted[i].Time); p != last {
+				keep[i] = true
+				last = p
+				limit -= 1
+			}
+		}
+	}
+	keepLatestOf(r.Daily, func(t time.Time) string {
+		return t.Format("2006-01-02")
+	})
+	keepLatestOf(r.Weekly, func(t time.Time) string {
+		y, w := t.ISOWeek()
+		return fmt.Sprintf("%d-%d", y, w)
+	})
+
+	var expired []File
+	for i, f := range sorted {
+		if !keep[i] {
+			expired = append(expired, f)
+		}
+	}
+	return expired
+}
@@ -0,0 +1,201 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backup
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/icon-project/goloop/common/errors"
+)
+
+const MinScheduleInterval = time.Minute
+
+// Schedule returns the time of the next backup.
+type Schedule interface {
+	// Next returns the next time after the last.
+	Next(last time.Time) time.Time
+	String() string
+}
+
+// ParseSchedule parses the schedule of the backup. It's an interval
+// (e.g. "24h") or a cron expression with five fields of minute, hour,
+// day of month, month and day of week (e.g. "0 3 * * *").
+func ParseSchedule(s string) (Schedule, error) {
+	s = strings.TrimSpace(s)
+	if d, err := time.ParseDuration(s); err == nil {
+		if d < MinScheduleInterval {
+			return nil, errors.IllegalArgumentError.Errorf(
+				"TooShortInterval(interval=%s,min=%s)", d, MinScheduleInterval)
+		}
+		return intervalSchedule(d), nil
+	}
+	if cs, err := parseCron(s); err != nil {
+		return nil, err
+	} else {
+		return cs, nil
+	}
+}
+
+type intervalSchedule time.Duration
+
+func (s intervalSchedule) Next(last time.Time) time.Time {
+	return last.Add(time.Duration(s))
+}
+
+func (s intervalSchedule) String() string {
+	return time.Duration(s).String()
+}
+
+type cronField struct {
+	min, max int
+}
+
+var cronFields = []cronField{
+	{0, 59}, // minute
+	{0, 23}, // hour
+	{1, 31}, // day of month
+	{1, 12}, // month
+	{0, 7},  // day of week (0 and 7 are Sunday)
+}
+
+type cronSchedule struct {
+	expr   string
+	fields [5]uint64
+
+	// any day of month or day of week
+	anyDOM, anyDOW bool
+}
+
+func parseCron(s string) (*cronSchedule, error) {
+	items := strings.Fields(s)
+	if len(items) != len(cronFields) {
+		return nil, errors.IllegalArgumentError.Errorf(
+			"InvalidSchedule(schedule=%q)", s)
+	}
+	cs := &cronSchedule{
+		expr:   strings.Join(items, " "),
+		anyDOM: items[2] == "*",
+		anyDOW: items[4] == "*",
+	}
+	for i, item := range items {
+		bits, err := parseCronField(item, cronFields[i])
+		if err != nil {
+			return nil, errors.IllegalArgumentError.Wrapf(err,
+				"InvalidSchedule(schedule=%q)", s)
+		}
+		cs.fields[i] = bits
+	}
+	if cs.fields[4]&(1<<7) != 0 {
+		cs.fields[4] |= 1
+	}
+	if cs.Next(time.Now()).IsZero() {
+		return nil, errors.IllegalArgumentError.Errorf(
+			"NoMatchingTime(schedule=%q)", s)
+	}
+	return cs, nil
+}
+
+func parseCronField(s string, f cronField) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(s, ",") {
+		step := 1
+		if idx := strings.IndexByte(item, '/'); idx >= 0 {
+			v, err := strconv.Atoi(item[idx+1:])
+			if err != nil || v < 1 {
+				return 0, errors.Errorf("InvalidStep(%q)", item)
+			}
+			step = v
+			item = item[:idx]
+		}
+		from, to := f.min, f.max
+		if item != "*" {
+			var err error
+			if idx := strings.IndexByte(item, '-'); idx >= 0 {
+				if from, err = strconv.Atoi(item[:idx]); err != nil {
+					return 0, errors.Errorf("InvalidRange(%q)", item)
+				}
+				if to, err = strconv.Atoi(item[idx+1:]); err != nil {
+					return 0, errors.Errorf("InvalidRange(%q)", item)
+				}
+			} else {
+				if from, err = strconv.Atoi(item); err != nil {
+					return 0, errors.Errorf("InvalidValue(%q)", item)
+				}
+				if step == 1 {
+					to = from
+				}
+			}
+		}
+		if from < f.min || to > f.max || from > to {
+			return 0, errors.Errorf("OutOfRange(%q,min=%d,max=%d)",
+				item, f.min, f.max)
+		}
+		for v := from; v <= to; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func (s *cronSchedule) has(idx, v int) bool {
+	return s.fields[idx]&(1<<uint(v)) != 0
+}
+
+func (s *cronSchedule) matchDay(t time.Time) bool {
+	dom := s.has(2, t.Day())
+	dow := s.has(4, int(t.Weekday()))
+	// like cron, either of them matches if both are restricted.
+	if s.anyDOM || s.anyDOW {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// Next returns the first matching minute after the last in the location
+// of the last.
+func (s *cronSchedule) Next(last time.Time) time.Time {
+	t := last.Truncate(time.Minute).Add(time.Minute)
+	// every matching time appears within a few years.
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if !s.has(3, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.has(1, t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.has(0, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	// no matching time (e.g. "0 0 31 2 *")
+	return time.Time{}
+}
+
+func (s *cronSchedule) String() string {
+	return s.expr
+}
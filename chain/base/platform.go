@@ -46,6 +46,25 @@ type Platform interface {
 	Term()
 }
 
+// RewardHistoryPlatform is implemented by platforms which may keep reward
+// history of accounts in the node.
+type RewardHistoryPlatform interface {
+	RewardHistory() module.RewardHistory
+}
+
+// ValidationHistoryPlatform is implemented by platforms which may keep
+// validation history of validators in the node.
+type ValidationHistoryPlatform interface {
+	ValidationHistory() module.ValidationHistory
+}
+
+// ForkPlatform is implemented by platforms managing validators in the
+// extension. ForkExtension returns the extension data for the chain forked
+// with new validators, which should keep the validators of the fork.
+type ForkPlatform interface {
+	ForkExtension(dbase db.Database, ed []byte) ([]byte, error)
+}
+
 type ExecutionResult interface {
 	PatchReceipts() module.ReceiptList
 	NormalReceipts() module.ReceiptList
@@ -13,6 +13,7 @@ import (
 	"github.com/icon-project/goloop/block"
 	"github.com/icon-project/goloop/chain/base"
 	"github.com/icon-project/goloop/chain/gs"
+	"github.com/icon-project/goloop/chain/notify"
 	"github.com/icon-project/goloop/common/db"
 	"github.com/icon-project/goloop/common/errors"
 	"github.com/icon-project/goloop/common/log"
@@ -88,6 +89,10 @@ type singleChain struct {
 	nt       module.NetworkTransport
 	nm       module.NetworkManager
 	plt      base.Platform
+	notifier *notify.Notifier
+	pruner   *block.Pruner
+	dev      *devMode
+	quota    quota
 
 	cid int
 	cfg Config
@@ -409,7 +414,12 @@ func (c *singleChain) prepareDatabase(chainDir string) error {
 		return errors.Wrapf(err, "UnknownCacheStrategy(%s)", c.cfg.NodeCache)
 	}
 	cacheDir := path.Join(chainDir, DefaultCacheDir)
-	c.database = cache.AttachManager(cdb, cacheDir, mLevel, fLevel, stores)
+	c.database, err = cache.AttachManagerWithLimit(cdb, cacheDir,
+		mLevel, fLevel, stores, c.quota.nodeCacheMemory)
+	if err != nil {
+		_ = cdb.Close()
+		return err
+	}
 	return nil
 }
 
@@ -436,10 +446,16 @@ func (c *singleChain) _init() error {
 		c.cfg.GenesisStorage = gs.NewFromTx(c.cfg.Genesis)
 	}
 
+	if pm, err := c.quota.init(&c.cfg, c.pm); err != nil {
+		return err
+	} else {
+		c.pm = pm
+	}
+
 	chainDir := c.cfg.AbsBaseDir()
 	log.Println("ConfigFilepath", c.cfg.FilePath, "BaseDir", c.cfg.BaseDir, "ChainDir", chainDir)
 
-	if plt, err := NewPlatform(c.cfg.Platform, chainDir, c.cid); err != nil {
+	if plt, err := NewPlatform(c.cfg.Platform, chainDir, c.cid, c.cfg.PlatformConfig); err != nil {
 		return err
 	} else {
 		c.plt = plt
@@ -454,6 +470,13 @@ func (c *singleChain) _init() error {
 		c.vld = consensus.NewCommitVoteSetFromBytes
 	}
 	c.pd = consensus.DecodePatch
+	if c.cfg.DevMode != "" {
+		if err := CheckDevMode(c.cfg.DevMode); err != nil {
+			return err
+		}
+		c.dev = newDevMode(c, c.cfg.DevMode)
+		c.pd = decodeDevPatch
+	}
 	c.metricCtx = metric.GetMetricContextByCID(c.CID())
 	return nil
 }
@@ -474,6 +497,10 @@ func (c *singleChain) prepareManagers() error {
 	if err != nil {
 		return err
 	}
+	if c.dev != nil {
+		c.cs = newDevConsensus(c, c.dev)
+		return nil
+	}
 	WALDir := path.Join(chainDir, DefaultWALDir)
 	c.cs, err = c.plt.NewConsensus(c, WALDir)
 	if err != nil {
@@ -657,16 +684,41 @@ func (c *singleChain) Verify() error {
 	return errors.UnsupportedError.New("UnsupportedFeatureVerify")
 }
 
-func (c *singleChain) Reset(gs string, height int64, blockHash []byte) error {
+func (c *singleChain) newTaskReset(gs string, height int64, blockHash []byte) chainTask {
 	if len(gs) == 0 {
 		chainDir := c.cfg.AbsBaseDir()
 		const chainGenesisZipFileName = "genesis.zip"
 		gs = path.Join(chainDir, chainGenesisZipFileName)
 	}
-	task := newTaskReset(c, gs, height, blockHash)
+	return newTaskReset(c, gs, height, blockHash)
+}
+
+func (c *singleChain) Reset(gs string, height int64, blockHash []byte) error {
+	task := c.newTaskReset(gs, height, blockHash)
 	return c._runTask(task, false)
 }
 
+func (c *singleChain) DevMode() module.DevMode {
+	if c.dev == nil {
+		return nil
+	}
+	return c.dev
+}
+
+func (c *singleChain) RewardHistory() module.RewardHistory {
+	if p, ok := c.plt.(base.RewardHistoryPlatform); ok {
+		return p.RewardHistory()
+	}
+	return nil
+}
+
+func (c *singleChain) ValidationHistory() module.ValidationHistory {
+	if p, ok := c.plt.(base.ValidationHistoryPlatform); ok {
+		return p.ValidationHistory()
+	}
+	return nil
+}
+
 func (c *singleChain) Logger() log.Logger {
 	return c.logger
 }
@@ -6,10 +6,14 @@ import (
 	"path"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/icon-project/goloop/block"
+	"github.com/icon-project/goloop/chain/backup"
 	"github.com/icon-project/goloop/common/crypto"
 	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/trie/cache"
 	"github.com/icon-project/goloop/module"
 )
 
@@ -34,7 +38,8 @@ type Config struct {
 	NID    int    `json:"nid"`
 	DBType string `json:"db_type"`
 
-	Platform string `json:"platform,omitempty"`
+	Platform       string          `json:"platform,omitempty"`
+	PlatformConfig json.RawMessage `json:"platform_config,omitempty"`
 
 	// static
 	SeedAddr         string `json:"seed_addr"`
@@ -48,11 +53,22 @@ type Config struct {
 	ChildrenLimit    *int   `json:"children_limit,omitempty"`
 	NephewsLimit     *int   `json:"nephews_limit,omitempty"`
 	ValidateTxOnSend bool   `json:"validate_tx_on_send,omitempty"`
+	PruneRetention   int64  `json:"prune_retention,omitempty"`
+	BackupSchedule   string `json:"backup_schedule,omitempty"`
+	BackupRetention  string `json:"backup_retention,omitempty"`
+	DevMode          string `json:"dev_mode,omitempty"`
+	EEInstances      int    `json:"ee_instances,omitempty"`
+	NodeCacheMemory  string `json:"node_cache_memory,omitempty"`
+	RPCConcurrency   int    `json:"rpc_concurrency,omitempty"`
+	NetworkBandwidth string `json:"network_bandwidth,omitempty"`
+
+	Notifier json.RawMessage `json:"notifier,omitempty"`
 
 	// runtime
 	Channel        string `json:"channel"`
 	SecureSuites   string `json:"secureSuites"`
 	SecureAeads    string `json:"secureAeads"`
+	Compressions   string `json:"compressions"`
 	DefWaitTimeout int64  `json:"waitTimeout"`
 	MaxWaitTimeout int64  `json:"maxTimeout"`
 	TxTimeout      int64  `json:"txTimeout"`
@@ -137,6 +153,102 @@ func GetChannel(channel string, nid int) string {
 	return channel
 }
 
+// CheckPruneRetention checks the number of blocks kept by pruning.
+// Zero disables pruning.
+func CheckPruneRetention(v int64) error {
+	if v != 0 && v < block.ConfigMinPruneRetention {
+		return errors.IllegalArgumentError.Errorf(
+			"InvalidPruneRetention(value=%d,min=%d)", v, block.ConfigMinPruneRetention)
+	}
+	return nil
+}
+
+// CheckBackupConfig checks the schedule and the retention policy of
+// scheduled backups. Empty schedule disables scheduled backups.
+func CheckBackupConfig(schedule, retention string) error {
+	if len(schedule) > 0 {
+		if _, err := backup.ParseSchedule(schedule); err != nil {
+			return err
+		}
+	}
+	_, err := backup.ParseRetention(retention)
+	return err
+}
+
+// CheckDevMode checks the dev mode. Empty value disables dev mode.
+func CheckDevMode(s string) error {
+	switch s {
+	case "", DevModeInstant, DevModeManual:
+		return nil
+	default:
+		return errors.IllegalArgumentError.Errorf("InvalidDevMode(%q)", s)
+	}
+}
+
+// ParseSize parses the number of bytes with an optional unit, K, M or G
+// (powers of 1024, optionally followed by "B"). Empty value returns zero.
+func ParseSize(s string) (int64, error) {
+	if len(s) == 0 {
+		return 0, nil
+	}
+	v := strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(s)), "B")
+	unit := int64(1)
+	if len(v) > 0 {
+		switch v[len(v)-1] {
+		case 'K':
+			unit = 1 << 10
+		case 'M':
+			unit = 1 << 20
+		case 'G':
+			unit = 1 << 30
+		}
+		if unit > 1 {
+			v = v[:len(v)-1]
+		}
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 || n > (1<<62)/unit {
+		return 0, errors.IllegalArgumentError.Errorf("InvalidSize(%q)", s)
+	}
+	return n * unit, nil
+}
+
+// CheckQuota checks the quotas of the resources shared with other chains
+// of the node. Zero or empty value means no limit.
+func CheckQuota(nodeCache string, eeInstances int, nodeCacheMemory string,
+	rpcConcurrency int, networkBandwidth string,
+) error {
+	if eeInstances < 0 {
+		return errors.IllegalArgumentError.Errorf(
+			"InvalidEEInstances(%d)", eeInstances)
+	}
+	if rpcConcurrency < 0 {
+		return errors.IllegalArgumentError.Errorf(
+			"InvalidRPCConcurrency(%d)", rpcConcurrency)
+	}
+	if _, err := ParseSize(networkBandwidth); err != nil {
+		return err
+	}
+	mem, err := ParseSize(nodeCacheMemory)
+	if err != nil {
+		return err
+	}
+	if mem > 0 {
+		if len(nodeCache) == 0 {
+			nodeCache = NodeCacheDefault
+		}
+		mLevel, _, _, err := ParseNodeCacheOption(nodeCache)
+		if err != nil {
+			return err
+		}
+		if min := cache.MinMemoryFor(mLevel); mem < min {
+			return errors.IllegalArgumentError.Errorf(
+				"NodeCacheMemoryTooSmall(limit=%d,min=%d)", mem, min)
+		}
+	}
+	return nil
+}
+
 func IsNodeCacheOption(s string) bool {
 	_, _, _, err := ParseNodeCacheOption(s)
 	return err == nil
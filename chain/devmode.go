@@ -0,0 +1,440 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chain
+
+import (
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/consensus"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service"
+)
+
+const (
+	// DevModeInstant produces blocks when transactions arrive, and on
+	// requests.
+	DevModeInstant = "instant"
+	// DevModeManual produces blocks only on requests.
+	DevModeManual = "manual"
+)
+
+const (
+	ConfigDevMaxMineBlocks  = 1000
+	configDevStopPollPeriod = 10 * time.Millisecond
+)
+
+type devSnapshot struct {
+	height int64
+	id     []byte
+}
+
+// devMode keeps states of dev mode, which should be kept while the chain
+// is restarted for revert.
+type devMode struct {
+	chain   *singleChain
+	instant bool
+	log     log.Logger
+
+	mtx       sync.Mutex
+	nextTS    int64
+	snapshots map[int]*devSnapshot
+	nextID    int
+}
+
+func (dm *devMode) consensus() (*devConsensus, error) {
+	if cs, ok := dm.chain.Consensus().(*devConsensus); ok {
+		return cs, nil
+	}
+	return nil, errors.InvalidStateError.New("ChainNotStarted")
+}
+
+func (dm *devMode) Mine(n int) (int64, error) {
+	if n < 1 || n > ConfigDevMaxMineBlocks {
+		return 0, errors.IllegalArgumentError.Errorf(
+			"InvalidBlockCount(count=%d,max=%d)", n, ConfigDevMaxMineBlocks)
+	}
+	cs, err := dm.consensus()
+	if err != nil {
+		return 0, err
+	}
+	return cs.mine(n, nil)
+}
+
+func (dm *devMode) SetNextTimestamp(ts int64) error {
+	if cs, err := dm.consensus(); err == nil {
+		if last := cs.lastBlock(); last != nil && ts <= last.Timestamp() {
+			return errors.IllegalArgumentError.Errorf(
+				"InvalidTimestamp(ts=%d,last=%d)", ts, last.Timestamp())
+		}
+	}
+	dm.mtx.Lock()
+	defer dm.mtx.Unlock()
+
+	dm.nextTS = ts
+	return nil
+}
+
+// timestampFor returns the timestamp of the votes for the last block, which
+// becomes the timestamp of the next block.
+func (dm *devMode) timestampFor(last module.Block) (int64, error) {
+	dm.mtx.Lock()
+	defer dm.mtx.Unlock()
+
+	if ts := dm.nextTS; ts != 0 {
+		dm.nextTS = 0
+		if ts <= last.Timestamp() {
+			return 0, errors.InvalidStateError.Errorf(
+				"InvalidTimestamp(ts=%d,last=%d)", ts, last.Timestamp())
+		}
+		return ts, nil
+	}
+	ts := time.Now().UnixNano() / int64(time.Microsecond)
+	if ts <= last.Timestamp() {
+		ts = last.Timestamp() + 1
+	}
+	return ts, nil
+}
+
+func (dm *devMode) Snapshot() (int, error) {
+	cs, err := dm.consensus()
+	if err != nil {
+		return 0, err
+	}
+	last := cs.lastBlock()
+	if last == nil {
+		return 0, errors.InvalidStateError.New("ChainNotStarted")
+	}
+	// reset doesn't support the block at height 1
+	if last.Height() == 1 {
+		return 0, errors.InvalidStateError.New("SnapshotAtHeight1(mine more blocks)")
+	}
+	// pruned genesis for reset requires the chain ID in the state
+	if last.Height() > 0 {
+		if _, err := dm.chain.ServiceManager().GetChainID(last.Result()); err != nil {
+			return 0, errors.InvalidStateError.New("No ChainID is recorded (require Revision 8)")
+		}
+	}
+
+	dm.mtx.Lock()
+	defer dm.mtx.Unlock()
+
+	dm.nextID += 1
+	dm.snapshots[dm.nextID] = &devSnapshot{
+		height: last.Height(),
+		id:     last.ID(),
+	}
+	return dm.nextID, nil
+}
+
+func (dm *devMode) Revert(id int) error {
+	dm.mtx.Lock()
+	s, ok := dm.snapshots[id]
+	dm.mtx.Unlock()
+	if !ok {
+		return errors.NotFoundError.Errorf("SnapshotNotFound(id=%d)", id)
+	}
+
+	cs, err := dm.consensus()
+	if err != nil {
+		return err
+	}
+	if last := cs.lastBlock(); last == nil || last.Height() != s.height {
+		if err := dm.reset(s.height, s.id); err != nil {
+			return err
+		}
+	}
+
+	// blocks below the snapshot are not available after reset, so other
+	// snapshots can't be used any more.
+	dm.mtx.Lock()
+	defer dm.mtx.Unlock()
+	dm.snapshots = make(map[int]*devSnapshot)
+	dm.nextTS = 0
+	return nil
+}
+
+// reset stops the chain, resets it to the block, then starts it again.
+func (dm *devMode) reset(height int64, id []byte) error {
+	c := dm.chain
+	dm.log.Infof("Revert(height=%d,id=%#x)", height, id)
+	if err := c.Stop(); err != nil {
+		return err
+	}
+	for !c.IsStopped() {
+		time.Sleep(configDevStopPollPeriod)
+	}
+	if height == 0 {
+		id = nil
+	}
+	if err := c._runTask(c.newTaskReset("", height, id), true); err != nil {
+		// changes are reverted on failure, so it can be started again.
+		log.Must(c.Stop())
+		if serr := c.Start(); serr != nil {
+			dm.log.Warnf("Fail to start after failure of revert err=%+v", serr)
+		}
+		return err
+	}
+	return c.Start()
+}
+
+func (dm *devMode) SetBalance(addr module.Address, balance *big.Int) error {
+	if balance == nil || balance.Sign() < 0 {
+		return errors.IllegalArgumentError.Errorf("InvalidBalance(%v)", balance)
+	}
+	cs, err := dm.consensus()
+	if err != nil {
+		return err
+	}
+	// the change is recorded in the first block as a patch, and its result
+	// is committed by the second one.
+	_, err = cs.mine(2, []module.AccountOverride{{
+		Address: addr,
+		Balance: balance,
+	}})
+	return err
+}
+
+// decodeDevPatch decodes patches including the account override patch,
+// which is accepted only by the chain in dev mode.
+func decodeDevPatch(t string, bs []byte) (module.Patch, error) {
+	if t == module.PatchTypeAccountOverride {
+		return service.DecodeAccountOverridePatch(bs)
+	}
+	return consensus.DecodePatch(t, bs)
+}
+
+func newDevMode(c *singleChain, mode string) *devMode {
+	return &devMode{
+		chain:   c,
+		instant: mode == DevModeInstant,
+		log: c.logger.WithFields(log.Fields{
+			log.FieldKeyModule: "DEV",
+		}),
+		snapshots: make(map[int]*devSnapshot),
+	}
+}
+
+// devConsensus produces blocks with the votes signed by the node instead of
+// consensus. It's used for the chain in dev mode.
+type devConsensus struct {
+	c   module.Chain
+	dm  *devMode
+	log log.Logger
+
+	mtx     sync.Mutex
+	started bool
+	last    module.Block
+	txCh    chan struct{}
+	stopCh  chan struct{}
+}
+
+func (cs *devConsensus) Start() error {
+	cs.mtx.Lock()
+	defer cs.mtx.Unlock()
+
+	last, err := cs.c.BlockManager().GetLastBlock()
+	if err != nil {
+		return err
+	}
+	nvs := last.NextValidators()
+	addr := cs.c.Wallet().Address()
+	if nvs == nil || nvs.Len() != 1 || nvs.IndexOf(addr) < 0 {
+		return errors.InvalidStateError.Errorf(
+			"InvalidValidatorsForDevMode(height=%d,node=%s)", last.Height(), addr)
+	}
+	if bd, err := last.BTPDigest(); err != nil {
+		return err
+	} else if len(bd.NetworkTypeDigests()) > 0 {
+		return errors.UnsupportedError.Errorf(
+			"DevModeWithBTPDigest(height=%d)", last.Height())
+	}
+	cs.last = last
+	cs.started = true
+	if cs.dm.instant {
+		go cs.loop(cs.stopCh)
+		cs.notify()
+	}
+	cs.log.Infof("Start dev mode instant=%t height=%d", cs.dm.instant, last.Height())
+	return nil
+}
+
+func (cs *devConsensus) notify() {
+	select {
+	case cs.txCh <- struct{}{}:
+	default:
+	}
+}
+
+// loop produces blocks for transactions in instant mode.
+func (cs *devConsensus) loop(stopCh <-chan struct{}) {
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-cs.txCh:
+		}
+		cs.mtx.Lock()
+		if cs.started {
+			wait, err := cs.c.BlockManager().WaitForTransaction(cs.last.ID(), cs.notify)
+			if err != nil {
+				cs.log.Warnf("Fail to wait for transactions err=%+v", err)
+			} else if !wait {
+				if _, err := cs._mine(1, nil); err != nil {
+					cs.log.Warnf("Fail to produce block err=%+v", err)
+				}
+			}
+		}
+		cs.mtx.Unlock()
+	}
+}
+
+func (cs *devConsensus) lastBlock() module.Block {
+	cs.mtx.Lock()
+	defer cs.mtx.Unlock()
+
+	return cs.last
+}
+
+func (cs *devConsensus) mine(n int, ovs []module.AccountOverride) (int64, error) {
+	cs.mtx.Lock()
+	defer cs.mtx.Unlock()
+
+	if !cs.started {
+		return 0, errors.InvalidStateError.New("ConsensusNotStarted")
+	}
+	return cs._mine(n, ovs)
+}
+
+func (cs *devConsensus) _mine(n int, ovs []module.AccountOverride) (int64, error) {
+	if len(ovs) > 0 {
+		// overrides are recorded in the next block as a patch, so others
+		// replaying the block get the same result.
+		p := service.NewAccountOverridePatch(cs.last.Height()+1, ovs)
+		if err := cs.c.ServiceManager().SendPatch(p); err != nil {
+			return 0, err
+		}
+	}
+	for i := 0; i < n; i++ {
+		if err := cs._mineOne(); err != nil {
+			return 0, err
+		}
+	}
+	if len(cs.last.NormalTransactions().Hash()) > 0 {
+		if err := cs._mineOne(); err != nil {
+			return 0, err
+		}
+	}
+	if cs.dm.instant {
+		cs.notify()
+	}
+	return cs.last.Height(), nil
+}
+
+type devProposal struct {
+	bc  module.BlockCandidate
+	err error
+}
+
+func (cs *devConsensus) _mineOne() error {
+	var votes module.CommitVoteSet
+	if cs.last.Height() == 0 {
+		votes = consensus.NewEmptyCommitVoteList()
+	} else {
+		ts, err := cs.dm.timestampFor(cs.last)
+		if err != nil {
+			return err
+		}
+		votes, err = consensus.NewCommitVoteSetForBlock(cs.c.Wallet(), cs.last, ts)
+		if err != nil {
+			return err
+		}
+	}
+
+	bm := cs.c.BlockManager()
+	cs.c.Regulator().OnPropose(time.Now())
+	ch := make(chan devProposal, 1)
+	_, err := bm.Propose(cs.last.ID(), votes, func(bc module.BlockCandidate, err error) {
+		ch <- devProposal{bc, err}
+	})
+	if err != nil {
+		return err
+	}
+	p := <-ch
+	if p.err != nil {
+		return p.err
+	}
+	defer p.bc.Dispose()
+	if err := bm.Finalize(p.bc); err != nil {
+		return err
+	}
+	blk, err := bm.GetBlock(p.bc.ID())
+	if err != nil {
+		return err
+	}
+	cs.last = blk
+	cs.log.Infof("Produce block height=%d id=%#x", blk.Height(), blk.ID())
+	return nil
+}
+
+func (cs *devConsensus) Term() {
+	cs.mtx.Lock()
+	defer cs.mtx.Unlock()
+
+	if cs.started {
+		cs.started = false
+		close(cs.stopCh)
+	}
+}
+
+func (cs *devConsensus) GetStatus() *module.ConsensusStatus {
+	cs.mtx.Lock()
+	defer cs.mtx.Unlock()
+
+	res := &module.ConsensusStatus{Proposer: true}
+	if cs.last != nil {
+		res.Height = cs.last.Height() + 1
+	}
+	return res
+}
+
+func (cs *devConsensus) GetVotesByHeight(height int64) (module.CommitVoteSet, error) {
+	blk, err := cs.c.BlockManager().GetBlockByHeight(height + 1)
+	if err != nil {
+		return nil, errors.NotFoundError.Wrapf(err, "not found vote height=%d", height)
+	}
+	return blk.Votes(), nil
+}
+
+func (cs *devConsensus) GetBTPBlockHeaderAndProof(
+	blk module.Block, nid int64, flag uint,
+) (module.BTPBlockHeader, []byte, error) {
+	return nil, nil, errors.UnsupportedError.New("BTPNotSupportedInDevMode")
+}
+
+func newDevConsensus(c module.Chain, dm *devMode) *devConsensus {
+	return &devConsensus{
+		c:      c,
+		dm:     dm,
+		log:    dm.log,
+		txCh:   make(chan struct{}, 1),
+		stopCh: make(chan struct{}),
+	}
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package export
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+)
+
+const CursorFile = "cursor.json"
+
+// cursor is the progress of the export. Offsets are sizes of table files
+// including all rows of blocks before Next.
+type cursor struct {
+	Format  string           `json:"format"`
+	Next    int64            `json:"next"`
+	Offsets map[string]int64 `json:"offsets"`
+}
+
+func loadCursor(dir string) (*cursor, error) {
+	bs, err := os.ReadFile(path.Join(dir, CursorFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	c := new(cursor)
+	if err := json.Unmarshal(bs, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// save writes the cursor to a temporary file and renames it after syncing,
+// so the cursor file is always complete.
+func (c *cursor) save(dir string) error {
+	bs, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	fp := path.Join(dir, CursorFile)
+	tmp := fp + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(bs)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, fp)
+}
@@ -0,0 +1,459 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package export
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/txresult"
+)
+
+const (
+	StateRunning = "running"
+	StateDone    = "done"
+	StateStopped = "stopped"
+	StateFailed  = "failed"
+
+	cursorSaveInterval = time.Second
+)
+
+// Params is the parameter of the export. Negative To means that it
+// follows the chain until it's stopped.
+type Params struct {
+	From   int64  `json:"from"`
+	To     int64  `json:"to"`
+	Format string `json:"format"`
+	Dir    string `json:"dir"`
+}
+
+type Status struct {
+	Params
+	Next  int64  `json:"next"`
+	State string `json:"state"`
+	Error string `json:"error,omitempty"`
+}
+
+// Exporter writes blocks, transactions, receipts, event logs and fee
+// payments of the chain to table files in the directory. Receipts of
+// transactions in a block come from the next block, so a block is
+// exported after the next block is finalized.
+type Exporter struct {
+	chain  module.Chain
+	params Params
+	log    log.Logger
+
+	lock  sync.Mutex
+	next  int64
+	state string
+	err   error
+
+	stopCh chan struct{}
+	doneCh chan struct{}
+
+	writers  map[*table]*tableWriter
+	lastSave time.Time
+
+	// partial is set while rows of a block are being written, so the
+	// offsets of the writers may be in the middle of the block.
+	partial bool
+}
+
+func (e *Exporter) Status() *Status {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	s := &Status{
+		Params: e.params,
+		Next:   e.next,
+		State:  e.state,
+	}
+	if e.err != nil {
+		s.Error = e.err.Error()
+	}
+	return s
+}
+
+func (e *Exporter) Start() {
+	go e.run()
+}
+
+// Stop stops the export and waits for the cursor to be saved.
+func (e *Exporter) Stop() {
+	e.lock.Lock()
+	select {
+	case <-e.stopCh:
+	default:
+		close(e.stopCh)
+	}
+	e.lock.Unlock()
+	<-e.doneCh
+}
+
+// Done returns a channel closed on the end of the export.
+func (e *Exporter) Done() <-chan struct{} {
+	return e.doneCh
+}
+
+func (e *Exporter) run() {
+	defer close(e.doneCh)
+
+	err := e.export()
+	if cerr := e.close(); err == nil {
+		err = cerr
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	switch {
+	case err == nil:
+		e.state = StateDone
+	case errors.InterruptedError.Equals(err):
+		e.state = StateStopped
+	default:
+		e.state = StateFailed
+		e.err = err
+		e.log.Warnf("Export failed next=%d err=%+v", e.next, err)
+	}
+}
+
+func (e *Exporter) waitBlock(bm module.BlockManager, height int64) (module.Block, error) {
+	bch, err := bm.WaitForBlock(height)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case blk, ok := <-bch:
+		if !ok {
+			return nil, errors.InvalidStateError.Errorf("BlockManagerStopped(height=%d)", height)
+		}
+		return blk, nil
+	case <-e.stopCh:
+		return nil, errors.ErrInterrupted
+	}
+}
+
+func (e *Exporter) export() error {
+	bm := e.chain.BlockManager()
+	sm := e.chain.ServiceManager()
+	if bm == nil || sm == nil {
+		return errors.InvalidStateError.New("ChainIsNotRunning")
+	}
+	blk, err := e.waitBlock(bm, e.next)
+	if err != nil {
+		return err
+	}
+	for e.params.To < 0 || e.next <= e.params.To {
+		nblk, err := e.waitBlock(bm, e.next+1)
+		if err != nil {
+			return err
+		}
+		e.partial = true
+		if err := e.exportBlock(sm, blk, nblk); err != nil {
+			return errors.Wrapf(err, "FailToExport(height=%d)", e.next)
+		}
+		e.partial = false
+		e.lock.Lock()
+		e.next += 1
+		e.lock.Unlock()
+		if time.Since(e.lastSave) >= cursorSaveInterval {
+			if err := e.saveCursor(); err != nil {
+				return err
+			}
+		}
+		blk = nblk
+	}
+	return nil
+}
+
+func hexOf(bs []byte) string {
+	if bs == nil {
+		return ""
+	}
+	return common.HexBytes(bs).String()
+}
+
+func addressOf(addr module.Address) string {
+	if p := common.AddressToPtr(addr); p != nil {
+		return p.String()
+	}
+	return ""
+}
+
+func bigIntOf(v *big.Int) string {
+	if v == nil {
+		return ""
+	}
+	return intconv.FormatBigInt(v)
+}
+
+// jsonValueOf returns a string for a value encoded as a JSON string, or
+// raw JSON for others.
+func jsonValueOf(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var s string
+	if json.Unmarshal(bs, &s) == nil {
+		return s
+	}
+	return json.RawMessage(bs)
+}
+
+// decodeEventLog returns the signature, the name and parameters of the
+// event log. Parameters are decoded with types in the signature, and
+// they are in hex if they can't be decoded.
+func decodeEventLog(ev module.EventLog) (string, string, []interface{}) {
+	indexed := ev.Indexed()
+	if len(indexed) == 0 {
+		return "", "", []interface{}{}
+	}
+	sig := string(indexed[0])
+	name, pts := txresult.DecomposeEventSignature(sig)
+	values := append(append([][]byte{}, indexed[1:]...), ev.Data()...)
+	params := make([]interface{}, len(values))
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+		if len(pts) == len(values) {
+			if jso, err := txresult.DecodeForJSONByType(pts[i], v); err == nil {
+				params[i] = jso
+				continue
+			}
+		}
+		params[i] = hexOf(v)
+	}
+	return sig, name, params
+}
+
+func (e *Exporter) exportBlock(sm module.ServiceManager, blk, nblk module.Block) error {
+	height := blk.Height()
+	txs := blk.NormalTransactions()
+	rl, err := sm.ReceiptListFromResult(nblk.Result(), module.TransactionGroupNormal)
+	if err != nil {
+		return err
+	}
+
+	txCount := int64(0)
+	for itr := txs.Iterator(); itr.Has(); itr.Next() {
+		tx, idx, err := itr.Get()
+		if err != nil {
+			return err
+		}
+		rct, err := rl.Get(idx)
+		if err != nil {
+			return err
+		}
+		if err := e.exportTransaction(height, int64(idx), tx, rct); err != nil {
+			return err
+		}
+		txCount += 1
+	}
+	return e.writers[tableBlocks].Write(
+		height,
+		hexOf(blk.ID()),
+		hexOf(blk.PrevID()),
+		blk.Timestamp(),
+		addressOf(blk.Proposer()),
+		int64(blk.Version()),
+		txCount,
+	)
+}
+
+func (e *Exporter) exportTransaction(height, idx int64, tx module.Transaction, rct module.Receipt) error {
+	txHash := hexOf(tx.ID())
+	jso, err := tx.ToJSON(module.JSONVersion3)
+	if err != nil {
+		return err
+	}
+	fields, _ := jso.(map[string]interface{})
+	field := func(key string) interface{} {
+		return jsonValueOf(fields[key])
+	}
+	if err := e.writers[tableTxs].Write(
+		height, idx, txHash,
+		int64(tx.Version()),
+		addressOf(tx.From()),
+		field("to"),
+		field("value"),
+		field("stepLimit"),
+		field("nonce"),
+		field("timestamp"),
+		field("dataType"),
+		field("data"),
+	); err != nil {
+		return err
+	}
+
+	fee := new(big.Int).Mul(rct.StepUsed(), rct.StepPrice())
+	if err := e.writers[tableReceipts].Write(
+		height, idx, txHash,
+		int64(rct.Status()),
+		addressOf(rct.To()),
+		addressOf(rct.SCOREAddress()),
+		bigIntOf(rct.StepUsed()),
+		bigIntOf(rct.StepPrice()),
+		bigIntOf(rct.CumulativeStepUsed()),
+		bigIntOf(fee),
+	); err != nil {
+		return err
+	}
+
+	logIdx := int64(0)
+	for itr := rct.EventLogIterator(); itr.Has(); itr.Next() {
+		ev, err := itr.Get()
+		if err != nil {
+			return err
+		}
+		sig, name, params := decodeEventLog(ev)
+		if err := e.writers[tableLogs].Write(
+			height, idx, txHash, logIdx,
+			addressOf(ev.Address()),
+			sig, name, params,
+		); err != nil {
+			return err
+		}
+		logIdx += 1
+	}
+
+	for itr := rct.FeePaymentIterator(); itr.Has(); itr.Next() {
+		p, err := itr.Get()
+		if err != nil {
+			return err
+		}
+		if err := e.writers[tableFees].Write(
+			height, idx, txHash,
+			addressOf(p.Payer()),
+			bigIntOf(p.Amount()),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// saveCursor flushes and syncs the table files, then it saves the cursor
+// with the sizes of the files. So rows before the cursor are kept on the
+// crash of the node.
+func (e *Exporter) saveCursor() error {
+	c := &cursor{
+		Format:  e.params.Format,
+		Next:    e.next,
+		Offsets: make(map[string]int64),
+	}
+	for _, t := range tables {
+		offset, err := e.writers[t].Flush()
+		if err != nil {
+			return err
+		}
+		c.Offsets[t.name] = offset
+	}
+	e.lastSave = time.Now()
+	return c.save(e.params.Dir)
+}
+
+// close saves the cursor and closes the table files. If it failed in the
+// middle of a block, it keeps the last cursor, and rows written after it
+// are dropped on the next export.
+func (e *Exporter) close() error {
+	var err error
+	if e.partial {
+		e.log.Warnf("Export keeps the last cursor for the partial block(height=%d)", e.next)
+	} else {
+		err = e.saveCursor()
+	}
+	for _, w := range e.writers {
+		w.Close()
+	}
+	return err
+}
+
+// New returns an exporter for the chain. If there is a cursor in the
+// directory, it continues from the cursor ignoring From.
+func New(c module.Chain, p *Params) (*Exporter, error) {
+	if err := checkFormat(p.Format); err != nil {
+		return nil, err
+	}
+	if p.To >= 0 && p.To < p.From {
+		return nil, errors.IllegalArgumentError.Errorf("InvalidRange(from=%d,to=%d)", p.From, p.To)
+	}
+	if gh := c.GenesisStorage().Height(); p.From < gh {
+		return nil, errors.IllegalArgumentError.Errorf(
+			"InvalidHeight(from=%d,genesis=%d)", p.From, gh)
+	}
+	if err := os.MkdirAll(p.Dir, 0755); err != nil {
+		return nil, err
+	}
+	cur, err := loadCursor(p.Dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "InvalidCursor")
+	}
+	next := p.From
+	if cur != nil {
+		if cur.Format != p.Format {
+			return nil, errors.IllegalArgumentError.Errorf(
+				"FormatMismatch(cursor=%s,format=%s)", cur.Format, p.Format)
+		}
+		next = cur.Next
+	}
+
+	e := &Exporter{
+		chain:   c,
+		params:  *p,
+		log:     c.Logger(),
+		next:    next,
+		state:   StateRunning,
+		stopCh:  make(chan struct{}),
+		doneCh:  make(chan struct{}),
+		writers: make(map[*table]*tableWriter),
+	}
+	for _, t := range tables {
+		offset := int64(-1)
+		if cur != nil {
+			var ok bool
+			if offset, ok = cur.Offsets[t.name]; !ok {
+				err = errors.InvalidStateError.Errorf("NoOffsetInCursor(table=%s)", t.name)
+			}
+		}
+		var w *tableWriter
+		if err == nil {
+			w, err = openTableWriter(p.Dir, t, p.Format, offset)
+		}
+		if err != nil {
+			for _, w := range e.writers {
+				w.Close()
+			}
+			return nil, err
+		}
+		e.writers[t] = w
+	}
+	e.lastSave = time.Now()
+	return e, nil
+}
@@ -0,0 +1,170 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/consensus"
+	"github.com/icon-project/goloop/test"
+)
+
+func readNDJSON(t *testing.T, fp string) []map[string]interface{} {
+	f, err := os.Open(fp)
+	assert.NoError(t, err)
+	defer f.Close()
+	var rows []map[string]interface{}
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		row := make(map[string]interface{})
+		assert.NoError(t, json.Unmarshal(sc.Bytes(), &row))
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func TestExporter(t *testing.T) {
+	nd := test.NewNode(t)
+	defer nd.Close()
+
+	nd.ProposeFinalizeBlockWithTX(
+		consensus.NewEmptyCommitVoteList(),
+		test.NewTx().SetValidatorsNode(nd).String(),
+	)
+	for i := 0; i < 2; i++ {
+		nd.ProposeFinalizeBlockWithTX(
+			consensus.NewEmptyCommitVoteList(),
+			test.NewTx().SetTimestamp(int64(i+1)).String(),
+		)
+	}
+	// height of the last block is 3
+	txCountOf := func(from, to int64) int {
+		cnt := 0
+		for h := from; h <= to; h++ {
+			blk, err := nd.BM.GetBlockByHeight(h)
+			assert.NoError(t, err)
+			for itr := blk.NormalTransactions().Iterator(); itr.Has(); itr.Next() {
+				cnt += 1
+			}
+		}
+		return cnt
+	}
+
+	_, err := New(nd.Chain, &Params{From: 0, To: 1, Format: "parquet", Dir: t.TempDir()})
+	assert.Error(t, err)
+	_, err = New(nd.Chain, &Params{From: 2, To: 1, Format: FormatNDJSON, Dir: t.TempDir()})
+	assert.Error(t, err)
+
+	dir := t.TempDir()
+	e, err := New(nd.Chain, &Params{From: 0, To: 2, Format: FormatNDJSON, Dir: dir})
+	assert.NoError(t, err)
+	e.Start()
+	<-e.Done()
+	st := e.Status()
+	assert.Equal(t, StateDone, st.State)
+	assert.EqualValues(t, 3, st.Next)
+
+	blocks := readNDJSON(t, path.Join(dir, "blocks.ndjson"))
+	assert.Len(t, blocks, 3)
+	blk2, err := nd.BM.GetBlockByHeight(2)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, blocks[2]["height"])
+	assert.Equal(t, common.HexBytes(blk2.ID()).String(), blocks[2]["hash"])
+	assert.EqualValues(t, 1, blocks[2]["txCount"])
+
+	txs := readNDJSON(t, path.Join(dir, "txs.ndjson"))
+	assert.Len(t, txs, txCountOf(0, 2))
+	tx, err := blk2.NormalTransactions().Get(0)
+	assert.NoError(t, err)
+	last := len(txs) - 1
+	assert.Equal(t, common.HexBytes(tx.ID()).String(), txs[last]["txHash"])
+	receipts := readNDJSON(t, path.Join(dir, "receipts.ndjson"))
+	assert.Len(t, receipts, len(txs))
+	assert.Contains(t, []interface{}{0.0, 1.0}, receipts[last]["status"])
+	assert.EqualValues(t, 2, receipts[last]["height"])
+	assert.Equal(t, txs[last]["txHash"], receipts[last]["txHash"])
+
+	// continue from the cursor while the chain grows
+	e, err = New(nd.Chain, &Params{From: 0, To: 5, Format: FormatNDJSON, Dir: dir})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, e.Status().Next)
+	e.Start()
+	for i := 0; i < 3; i++ {
+		nd.ProposeFinalizeBlock(nd.NewVoteListForLastBlock())
+	}
+	<-e.Done()
+	assert.Equal(t, StateDone, e.Status().State)
+	blocks = readNDJSON(t, path.Join(dir, "blocks.ndjson"))
+	assert.Len(t, blocks, 6)
+	for i, b := range blocks {
+		assert.EqualValues(t, i, b["height"])
+	}
+	assert.Len(t, readNDJSON(t, path.Join(dir, "txs.ndjson")), txCountOf(0, 5))
+
+	// format of the cursor should match
+	_, err = New(nd.Chain, &Params{From: 0, To: 5, Format: FormatCSV, Dir: dir})
+	assert.Error(t, err)
+
+	// stop waiting for blocks
+	e, err = New(nd.Chain, &Params{From: 0, To: -1, Format: FormatCSV, Dir: t.TempDir()})
+	assert.NoError(t, err)
+	e.Start()
+	e.Stop()
+	st = e.Status()
+	assert.Equal(t, StateStopped, st.State)
+	f, err := os.Open(path.Join(st.Dir, "blocks.csv"))
+	assert.NoError(t, err)
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, tableBlocks.columns, records[0])
+	assert.Len(t, records, int(st.Next)+1)
+}
+
+func TestExporter_PartialBlock(t *testing.T) {
+	nd := test.NewNode(t)
+	defer nd.Close()
+
+	dir := t.TempDir()
+	e, err := New(nd.Chain, &Params{From: 0, To: 1, Format: FormatNDJSON, Dir: dir})
+	assert.NoError(t, err)
+	assert.NoError(t, e.saveCursor())
+	cur, err := loadCursor(dir)
+	assert.NoError(t, err)
+
+	// failed in the middle of the block
+	e.partial = true
+	assert.NoError(t, e.writers[tableBlocks].Write(0, "", "", 0, "", 0, 0))
+	assert.NoError(t, e.close())
+	cur2, err := loadCursor(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, cur, cur2)
+
+	// rows of the partial block are dropped
+	e, err = New(nd.Chain, &Params{From: 0, To: 1, Format: FormatNDJSON, Dir: dir})
+	assert.NoError(t, err)
+	assert.NoError(t, e.close())
+	assert.Len(t, readNDJSON(t, path.Join(dir, "blocks.ndjson")), 0)
+}
@@ -0,0 +1,200 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"path"
+	"strconv"
+
+	"github.com/icon-project/goloop/common/errors"
+)
+
+const (
+	FormatNDJSON = "ndjson"
+	FormatCSV    = "csv"
+)
+
+type table struct {
+	name    string
+	columns []string
+}
+
+var (
+	tableBlocks = &table{"blocks", []string{
+		"height", "hash", "prevHash", "timestamp", "proposer", "version", "txCount",
+	}}
+	tableTxs = &table{"txs", []string{
+		"height", "txIndex", "txHash", "version", "from", "to", "value",
+		"stepLimit", "nonce", "timestamp", "dataType", "data",
+	}}
+	tableReceipts = &table{"receipts", []string{
+		"height", "txIndex", "txHash", "status", "to", "scoreAddress",
+		"stepUsed", "stepPrice", "cumulativeStepUsed", "fee",
+	}}
+	tableLogs = &table{"logs", []string{
+		"height", "txIndex", "txHash", "logIndex", "address", "signature",
+		"name", "params",
+	}}
+	tableFees = &table{"fees", []string{
+		"height", "txIndex", "txHash", "payer", "amount",
+	}}
+
+	tables = []*table{tableBlocks, tableTxs, tableReceipts, tableLogs, tableFees}
+)
+
+func checkFormat(format string) error {
+	switch format {
+	case FormatNDJSON, FormatCSV:
+		return nil
+	default:
+		return errors.IllegalArgumentError.Errorf("UnknownFormat(format=%s)", format)
+	}
+}
+
+// tableWriter writes rows of a table to a file. Row values should be
+// one of nil, string, int64, bool or values to be encoded in JSON.
+type tableWriter struct {
+	table  *table
+	format string
+	file   *os.File
+	buf    *bufio.Writer
+	csv    *csv.Writer
+}
+
+func csvValue(v interface{}) string {
+	switch obj := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return obj
+	case int64:
+		return strconv.FormatInt(obj, 10)
+	case bool:
+		return strconv.FormatBool(obj)
+	default:
+		bs, _ := json.Marshal(obj)
+		return string(bs)
+	}
+}
+
+func (w *tableWriter) writeNDJSON(values []interface{}) error {
+	buf := bytes.NewBuffer(nil)
+	buf.WriteByte('{')
+	for i, column := range w.table.columns {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(strconv.Quote(column))
+		buf.WriteByte(':')
+		bs, err := json.Marshal(values[i])
+		if err != nil {
+			return err
+		}
+		buf.Write(bs)
+	}
+	buf.WriteString("}\n")
+	_, err := w.buf.Write(buf.Bytes())
+	return err
+}
+
+func (w *tableWriter) Write(values ...interface{}) error {
+	if len(values) != len(w.table.columns) {
+		return errors.IllegalArgumentError.Errorf("InvalidRow(table=%s,values=%d)",
+			w.table.name, len(values))
+	}
+	if w.format == FormatCSV {
+		record := make([]string, len(values))
+		for i, v := range values {
+			record[i] = csvValue(v)
+		}
+		return w.csv.Write(record)
+	}
+	return w.writeNDJSON(values)
+}
+
+// Flush writes buffered rows to the file and syncs it. It returns the size
+// of the file.
+func (w *tableWriter) Flush() (int64, error) {
+	if w.csv != nil {
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return 0, err
+		}
+	}
+	if err := w.buf.Flush(); err != nil {
+		return 0, err
+	}
+	if err := w.file.Sync(); err != nil {
+		return 0, err
+	}
+	return w.file.Seek(0, io.SeekCurrent)
+}
+
+func (w *tableWriter) Close() error {
+	return w.file.Close()
+}
+
+func fileNameOf(t *table, format string) string {
+	return t.name + "." + format
+}
+
+// openTableWriter opens a file for the table. If offset is negative, it
+// creates a new file. Otherwise, it continues from the offset dropping
+// rows written after the offset.
+func openTableWriter(dir string, t *table, format string, offset int64) (*tableWriter, error) {
+	fp := path.Join(dir, fileNameOf(t, format))
+	var f *os.File
+	var err error
+	if offset < 0 {
+		f, err = os.OpenFile(fp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	} else {
+		f, err = os.OpenFile(fp, os.O_WRONLY, 0644)
+		if err == nil {
+			if err = f.Truncate(offset); err == nil {
+				_, err = f.Seek(offset, io.SeekStart)
+			}
+			if err != nil {
+				f.Close()
+			}
+		}
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "FailToOpenTable(file=%s)", fp)
+	}
+	w := &tableWriter{
+		table:  t,
+		format: format,
+		file:   f,
+		buf:    bufio.NewWriter(f),
+	}
+	if format == FormatCSV {
+		w.csv = csv.NewWriter(w.buf)
+		if offset < 0 {
+			if err := w.csv.Write(t.columns); err != nil {
+				f.Close()
+				return nil, err
+			}
+		}
+	}
+	return w, nil
+}
@@ -0,0 +1,176 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package notify
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/server"
+)
+
+const (
+	DefaultRetryMin = 1000
+	DefaultRetryMax = 60000
+
+	redactedSecret = "********"
+)
+
+var sinkNamePattern = regexp.MustCompile("^[A-Za-z0-9_-]+$")
+
+// Config is the configuration of the notifier in the chain configuration.
+type Config struct {
+	Sinks []*SinkConfig `json:"sinks"`
+}
+
+// SinkConfig has common options of a sink. Options for the type of the
+// sink are in the same object, and they are passed to the factory of the
+// type in Raw.
+type SinkConfig struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+
+	// From is the height to start without a cursor. If it's nil, it
+	// starts from the last block on the start.
+	From *int64 `json:"from,omitempty"`
+
+	// RetryMin and RetryMax are the range of the delay between retries
+	// in milliseconds. The delay is doubled on each failure.
+	RetryMin int64 `json:"retryMin,omitempty"`
+	RetryMax int64 `json:"retryMax,omitempty"`
+
+	EventFilters server.EventFilters `json:"eventFilters,omitempty"`
+	Logs         bool                `json:"logs,omitempty"`
+
+	// BaseDir is the base directory of the chain. Relative paths in
+	// options of the sink are resolved against it.
+	BaseDir string `json:"-"`
+
+	Raw json.RawMessage `json:"-"`
+}
+
+// pathOf returns the path resolved against the base directory.
+func (c *SinkConfig) pathOf(p string) string {
+	if filepath.IsAbs(p) || len(c.BaseDir) == 0 {
+		return p
+	}
+	return filepath.Join(c.BaseDir, p)
+}
+
+func (c *SinkConfig) UnmarshalJSON(bs []byte) error {
+	type sinkConfig SinkConfig
+	if err := json.Unmarshal(bs, (*sinkConfig)(c)); err != nil {
+		return err
+	}
+	c.Raw = append(json.RawMessage{}, bs...)
+	return nil
+}
+
+func (c *SinkConfig) retryRange() (time.Duration, time.Duration) {
+	min, max := c.RetryMin, c.RetryMax
+	if min <= 0 {
+		min = DefaultRetryMin
+	}
+	if max <= 0 {
+		max = DefaultRetryMax
+	}
+	if max < min {
+		max = min
+	}
+	return time.Duration(min) * time.Millisecond, time.Duration(max) * time.Millisecond
+}
+
+func (c *SinkConfig) compile() error {
+	if !sinkNamePattern.MatchString(c.Name) {
+		return errors.IllegalArgumentError.Errorf("InvalidSinkName(name=%q)", c.Name)
+	}
+	sink, err := NewSink(c)
+	if err != nil {
+		return err
+	}
+	_ = sink.Close()
+	for i, f := range c.EventFilters {
+		if f == nil {
+			return errors.IllegalArgumentError.Errorf("NullEventFilter(name=%s,idx=%d)", c.Name, i)
+		}
+		if err := f.Compile(); err != nil {
+			return errors.IllegalArgumentError.Wrapf(err, "InvalidEventFilter(name=%s,idx=%d)", c.Name, i)
+		}
+	}
+	return nil
+}
+
+// SetBaseDir sets the base directory of the chain to the sinks.
+func (c *Config) SetBaseDir(dir string) {
+	for _, sc := range c.Sinks {
+		sc.BaseDir = dir
+	}
+}
+
+// RedactConfig returns the configuration of the notifier hiding secrets
+// of the sinks, so it can be shown to users.
+func RedactConfig(bs json.RawMessage) json.RawMessage {
+	if len(bs) == 0 {
+		return bs
+	}
+	var cfg map[string]json.RawMessage
+	var sinks []map[string]json.RawMessage
+	if json.Unmarshal(bs, &cfg) != nil || json.Unmarshal(cfg["sinks"], &sinks) != nil {
+		return bs
+	}
+	redacted := false
+	for _, sc := range sinks {
+		if _, ok := sc["secret"]; ok {
+			sc["secret"], _ = json.Marshal(redactedSecret)
+			redacted = true
+		}
+	}
+	if !redacted {
+		return bs
+	}
+	cfg["sinks"], _ = json.Marshal(sinks)
+	rbs, err := json.Marshal(cfg)
+	if err != nil {
+		return bs
+	}
+	return rbs
+}
+
+// ParseConfig parses and verifies the configuration of the notifier.
+func ParseConfig(bs []byte) (*Config, error) {
+	cfg := new(Config)
+	if err := json.Unmarshal(bs, cfg); err != nil {
+		return nil, errors.IllegalArgumentError.Wrap(err, "InvalidNotifierConfig")
+	}
+	names := make(map[string]bool)
+	for i, sc := range cfg.Sinks {
+		if sc == nil {
+			return nil, errors.IllegalArgumentError.Errorf("NullSink(idx=%d)", i)
+		}
+		if err := sc.compile(); err != nil {
+			return nil, err
+		}
+		if names[sc.Name] {
+			return nil, errors.IllegalArgumentError.Errorf("DuplicateSinkName(name=%s)", sc.Name)
+		}
+		names[sc.Name] = true
+	}
+	return cfg, nil
+}
@@ -0,0 +1,254 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package notify
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"time"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/server"
+)
+
+// BlockNotification is delivered for each block. Events are matched
+// against receipts in the result of the block like event notifications
+// of websocket.
+type BlockNotification struct {
+	Hash      common.HexBytes             `json:"hash"`
+	Height    common.HexInt64             `json:"height"`
+	PrevHash  common.HexBytes             `json:"prevHash"`
+	Timestamp common.HexInt64             `json:"timestamp"`
+	TxCount   common.HexInt32             `json:"txCount"`
+	Events    []*server.EventNotification `json:"events,omitempty"`
+}
+
+type cursor struct {
+	Next int64 `json:"next"`
+}
+
+// sinkRunner delivers notifications to a sink in order of height. It
+// retries a notification until it's delivered, and it keeps the height
+// of the next notification in the cursor file.
+type sinkRunner struct {
+	cfg        *SinkConfig
+	sink       Sink
+	chain      module.Chain
+	cursorFile string
+	log        log.Logger
+
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+func (r *sinkRunner) loadCursor() (int64, error) {
+	bs, err := os.ReadFile(r.cursorFile)
+	if err == nil {
+		var c cursor
+		if err := json.Unmarshal(bs, &c); err != nil {
+			return 0, errors.Wrapf(err, "InvalidCursor(file=%s)", r.cursorFile)
+		}
+		return c.Next, nil
+	}
+	if !os.IsNotExist(err) {
+		return 0, err
+	}
+	if r.cfg.From != nil {
+		return *r.cfg.From, nil
+	}
+	blk, err := r.chain.BlockManager().GetLastBlock()
+	if err != nil {
+		return 0, err
+	}
+	return blk.Height(), nil
+}
+
+func (r *sinkRunner) saveCursor(next int64) error {
+	bs, err := json.Marshal(&cursor{Next: next})
+	if err != nil {
+		return err
+	}
+	tmp := r.cursorFile + ".tmp"
+	if err := os.WriteFile(tmp, bs, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.cursorFile)
+}
+
+func (r *sinkRunner) waitBlock(bm module.BlockManager, height int64) (module.Block, error) {
+	bch, err := bm.WaitForBlock(height)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case blk, ok := <-bch:
+		if !ok {
+			return nil, errors.ErrInterrupted
+		}
+		return blk, nil
+	case <-r.stopCh:
+		return nil, errors.ErrInterrupted
+	}
+}
+
+func (r *sinkRunner) notificationOf(sm module.ServiceManager, blk module.Block) ([]byte, error) {
+	bn := &BlockNotification{
+		Hash:      blk.ID(),
+		Height:    common.HexInt64{Value: blk.Height()},
+		PrevHash:  blk.PrevID(),
+		Timestamp: common.HexInt64{Value: blk.Timestamp()},
+	}
+	for itr := blk.NormalTransactions().Iterator(); itr.Has(); itr.Next() {
+		bn.TxCount.Value += 1
+	}
+	if filters, contained := r.cfg.EventFilters.FilteredByLogBloom(blk.LogsBloom()); contained {
+		rl, err := sm.ReceiptListFromResult(blk.Result(), module.TransactionGroupNormal)
+		if err != nil {
+			return nil, err
+		}
+		index := int32(0)
+		for itr := rl.Iterator(); itr.Has(); itr.Next() {
+			rct, err := itr.Get()
+			if err != nil {
+				return nil, err
+			}
+			es, logs, err := filters.MatchEvents(rct, r.cfg.Logs)
+			if err != nil {
+				return nil, err
+			}
+			if len(es) > 0 {
+				bn.Events = append(bn.Events, &server.EventNotification{
+					Hash:   blk.ID(),
+					Height: bn.Height,
+					Index:  common.HexInt32{Value: index},
+					Events: es,
+					Logs:   logs,
+				})
+			}
+			index++
+		}
+	}
+	return json.Marshal(bn)
+}
+
+func (r *sinkRunner) deliver(height int64, payload []byte) error {
+	delay, max := r.cfg.retryRange()
+	for {
+		err := r.sink.Deliver(height, payload)
+		if err == nil {
+			return nil
+		}
+		r.log.Warnf("Fail to deliver notification sink=%s height=%d retry=%s err=%+v",
+			r.cfg.Name, height, delay, err)
+		select {
+		case <-time.After(delay):
+		case <-r.stopCh:
+			return errors.ErrInterrupted
+		}
+		if delay *= 2; delay > max {
+			delay = max
+		}
+	}
+}
+
+func (r *sinkRunner) run() {
+	defer close(r.doneCh)
+	if err := r.doRun(); err != nil && !errors.InterruptedError.Equals(err) {
+		r.log.Errorf("Notifier stopped sink=%s err=%+v", r.cfg.Name, err)
+	}
+}
+
+func (r *sinkRunner) doRun() error {
+	bm := r.chain.BlockManager()
+	sm := r.chain.ServiceManager()
+	next, err := r.loadCursor()
+	if err != nil {
+		return err
+	}
+	for {
+		blk, err := r.waitBlock(bm, next)
+		if err != nil {
+			return err
+		}
+		payload, err := r.notificationOf(sm, blk)
+		if err != nil {
+			return err
+		}
+		if err := r.deliver(next, payload); err != nil {
+			return err
+		}
+		next += 1
+		if err := r.saveCursor(next); err != nil {
+			return err
+		}
+	}
+}
+
+// Notifier pushes notifications of blocks to the sinks. Each sink has
+// its own cursor, so a slow sink doesn't block others.
+type Notifier struct {
+	runners []*sinkRunner
+}
+
+func (n *Notifier) Start() {
+	for _, r := range n.runners {
+		go r.run()
+	}
+}
+
+func (n *Notifier) Stop() {
+	for _, r := range n.runners {
+		close(r.stopCh)
+	}
+	for _, r := range n.runners {
+		<-r.doneCh
+		_ = r.sink.Close()
+	}
+}
+
+// New returns a notifier for the chain. Cursors of sinks are kept in
+// the directory.
+func New(c module.Chain, cfg *Config, dir string) (*Notifier, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	logger := c.Logger().WithFields(log.Fields{log.FieldKeyModule: "NT"})
+	n := new(Notifier)
+	for _, sc := range cfg.Sinks {
+		sink, err := NewSink(sc)
+		if err != nil {
+			for _, r := range n.runners {
+				_ = r.sink.Close()
+			}
+			return nil, err
+		}
+		n.runners = append(n.runners, &sinkRunner{
+			cfg:        sc,
+			sink:       sink,
+			chain:      c,
+			cursorFile: path.Join(dir, sc.Name+".json"),
+			log:        logger,
+			stopCh:     make(chan struct{}),
+			doneCh:     make(chan struct{}),
+		})
+	}
+	return n, nil
+}
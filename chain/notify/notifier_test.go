@@ -0,0 +1,209 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/consensus"
+	"github.com/icon-project/goloop/test"
+)
+
+func TestParseConfig(t *testing.T) {
+	for _, c := range []string{
+		`{"sinks":[{"name":"a","type":"unknown"}]}`,
+		`{"sinks":[{"name":"a b","type":"file","dir":"/tmp"}]}`,
+		`{"sinks":[{"name":"a","type":"file"}]}`,
+		`{"sinks":[{"name":"a","type":"webhook","url":"ftp://host"}]}`,
+		`{"sinks":[{"name":"a","type":"file","dir":"/tmp"},{"name":"a","type":"file","dir":"/tmp"}]}`,
+		`{"sinks":[{"name":"a","type":"file","dir":"/tmp","eventFilters":[{"event":"Bad"}]}]}`,
+	} {
+		_, err := ParseConfig([]byte(c))
+		assert.Error(t, err, c)
+	}
+
+	cfg, err := ParseConfig([]byte(`{"sinks":[
+		{"name":"a","type":"file","dir":"/tmp","from":3},
+		{"name":"b","type":"webhook","url":"http://localhost","retryMin":10,
+		 "eventFilters":[{"event":"Transfer(Address,int)"}]}
+	]}`))
+	assert.NoError(t, err)
+	assert.Len(t, cfg.Sinks, 2)
+	assert.EqualValues(t, 3, *cfg.Sinks[0].From)
+	min, max := cfg.Sinks[1].retryRange()
+	assert.Equal(t, 10*time.Millisecond, min)
+	assert.Equal(t, DefaultRetryMax*time.Millisecond, max)
+}
+
+func TestConfig_SetBaseDir(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`{"sinks":[
+		{"name":"a","type":"file","dir":"spool"},
+		{"name":"b","type":"file","dir":"/tmp/spool"}
+	]}`))
+	assert.NoError(t, err)
+	cfg.SetBaseDir("/data/chain")
+	var dirs []string
+	for _, sc := range cfg.Sinks {
+		sink, err := NewSink(sc)
+		assert.NoError(t, err)
+		dirs = append(dirs, sink.(*fileSink).dir)
+	}
+	assert.Equal(t, []string{"/data/chain/spool", "/tmp/spool"}, dirs)
+}
+
+func TestRedactConfig(t *testing.T) {
+	bs := json.RawMessage(`{"sinks":[
+		{"name":"a","type":"webhook","url":"http://localhost","secret":"secret"},
+		{"name":"b","type":"file","dir":"/tmp"}
+	]}`)
+	rbs := RedactConfig(bs)
+	assert.NotContains(t, string(rbs), `"secret":"secret"`)
+	cfg, err := ParseConfig(rbs)
+	assert.NoError(t, err)
+	assert.Len(t, cfg.Sinks, 2)
+	var wc webhookSinkConfig
+	assert.NoError(t, json.Unmarshal(cfg.Sinks[0].Raw, &wc))
+	assert.Equal(t, redactedSecret, wc.Secret)
+	assert.Equal(t, "http://localhost", wc.URL)
+
+	// configurations without secrets are kept
+	bs = json.RawMessage(`{"sinks":[{"name":"b","type":"file","dir":"/tmp"}]}`)
+	assert.Equal(t, bs, RedactConfig(bs))
+	assert.Nil(t, RedactConfig(nil))
+}
+
+type webhookRecorder struct {
+	lock     sync.Mutex
+	failures int
+	heights  []int64
+	err      error
+}
+
+func (wr *webhookRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	wr.lock.Lock()
+	defer wr.lock.Unlock()
+
+	if wr.failures > 0 {
+		wr.failures -= 1
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	if sig := r.Header.Get(HeaderSignature); sig != Signature([]byte("secret"), body) {
+		wr.err = fmt.Errorf("invalid signature %s", sig)
+	}
+	var bn BlockNotification
+	if err := json.Unmarshal(body, &bn); err != nil {
+		wr.err = err
+	}
+	height, _ := strconv.ParseInt(r.Header.Get(HeaderHeight), 10, 64)
+	if height != bn.Height.Value {
+		wr.err = fmt.Errorf("height mismatch header=%d body=%d", height, bn.Height.Value)
+	}
+	wr.heights = append(wr.heights, height)
+}
+
+func (wr *webhookRecorder) Heights() []int64 {
+	wr.lock.Lock()
+	defer wr.lock.Unlock()
+	return append([]int64{}, wr.heights...)
+}
+
+func waitFor(t *testing.T, f func() bool) {
+	for i := 0; i < 200; i++ {
+		if f() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("timeout")
+}
+
+func TestNotifier(t *testing.T) {
+	nd := test.NewNode(t)
+	defer nd.Close()
+
+	nd.ProposeFinalizeBlockWithTX(
+		consensus.NewEmptyCommitVoteList(),
+		test.NewTx().SetValidatorsNode(nd).String(),
+	)
+	nd.ProposeFinalizeBlock(consensus.NewEmptyCommitVoteList())
+	// height of the last block is 2
+
+	wr := &webhookRecorder{failures: 2}
+	hs := httptest.NewServer(wr)
+	defer hs.Close()
+
+	spool := t.TempDir()
+	cursors := t.TempDir()
+	cfg, err := ParseConfig([]byte(fmt.Sprintf(`{"sinks":[
+		{"name":"hook","type":"webhook","url":%q,"secret":"secret","from":1,"retryMin":10,"retryMax":20},
+		{"name":"spool","type":"file","dir":%q}
+	]}`, hs.URL, spool)))
+	assert.NoError(t, err)
+
+	n, err := New(nd.Chain, cfg, cursors)
+	assert.NoError(t, err)
+	n.Start()
+
+	// webhook retries on failure, and spool starts from the last block
+	waitFor(t, func() bool { return len(wr.Heights()) == 2 })
+	assert.Equal(t, []int64{1, 2}, wr.Heights())
+	waitFor(t, func() bool {
+		_, err := os.Stat(path.Join(spool, "000000000002.json"))
+		return err == nil
+	})
+	_, err = os.Stat(path.Join(spool, "000000000001.json"))
+	assert.True(t, os.IsNotExist(err))
+
+	n.Stop()
+
+	// continue from cursors after restart
+	nd.ProposeFinalizeBlock(consensus.NewEmptyCommitVoteList())
+	n, err = New(nd.Chain, cfg, cursors)
+	assert.NoError(t, err)
+	n.Start()
+	waitFor(t, func() bool { return len(wr.Heights()) == 3 })
+	assert.Equal(t, []int64{1, 2, 3}, wr.Heights())
+	waitFor(t, func() bool {
+		_, err := os.Stat(path.Join(spool, "000000000003.json"))
+		return err == nil
+	})
+	n.Stop()
+	assert.NoError(t, wr.err)
+
+	bs, err := os.ReadFile(path.Join(spool, "000000000003.json"))
+	assert.NoError(t, err)
+	var bn BlockNotification
+	assert.NoError(t, json.Unmarshal(bs, &bn))
+	blk, err := nd.BM.GetBlockByHeight(3)
+	assert.NoError(t, err)
+	assert.EqualValues(t, blk.ID(), bn.Hash)
+	assert.EqualValues(t, blk.PrevID(), bn.PrevHash)
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/icon-project/goloop/common/errors"
+)
+
+const (
+	SinkTypeFile    = "file"
+	SinkTypeWebhook = "webhook"
+)
+
+// Sink delivers notifications of blocks. Deliver is called again with
+// the same notification on failure, so it should be idempotent for the
+// height.
+type Sink interface {
+	Deliver(height int64, payload []byte) error
+	Close() error
+}
+
+// SinkFactory returns a sink for the configuration. It's also used to
+// verify the configuration, so it should not have side effects until
+// the first delivery.
+type SinkFactory func(cfg *SinkConfig) (Sink, error)
+
+var sinkFactories = map[string]SinkFactory{
+	SinkTypeFile:    newFileSink,
+	SinkTypeWebhook: newWebhookSink,
+}
+
+func RegisterSink(typ string, factory SinkFactory) {
+	sinkFactories[typ] = factory
+}
+
+func NewSink(cfg *SinkConfig) (Sink, error) {
+	if factory, ok := sinkFactories[cfg.Type]; ok {
+		return factory(cfg)
+	}
+	return nil, errors.IllegalArgumentError.Errorf("UnknownSinkType(name=%s,type=%s)", cfg.Name, cfg.Type)
+}
+
+// fileSink spools notifications to files in the directory. A file is
+// named by the height, so a consumer may process files in order of the
+// name and remove them.
+type fileSink struct {
+	dir string
+}
+
+// fileSinkConfig has the directory for the files. A relative path is
+// resolved against the base directory of the chain.
+type fileSinkConfig struct {
+	Dir string `json:"dir"`
+}
+
+func (s *fileSink) Deliver(height int64, payload []byte) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+	fp := path.Join(s.dir, fmt.Sprintf("%012d.json", height))
+	tmp := fp + ".tmp"
+	if err := os.WriteFile(tmp, payload, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, fp)
+}
+
+func (s *fileSink) Close() error {
+	return nil
+}
+
+func newFileSink(cfg *SinkConfig) (Sink, error) {
+	var fc fileSinkConfig
+	if err := json.Unmarshal(cfg.Raw, &fc); err != nil {
+		return nil, errors.IllegalArgumentError.Wrapf(err, "InvalidSinkConfig(name=%s)", cfg.Name)
+	}
+	if len(fc.Dir) == 0 {
+		return nil, errors.IllegalArgumentError.Errorf("NoDirectory(name=%s)", cfg.Name)
+	}
+	return &fileSink{dir: cfg.pathOf(fc.Dir)}, nil
+}
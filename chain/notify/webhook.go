@@ -0,0 +1,106 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package notify
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/icon-project/goloop/common/errors"
+)
+
+const (
+	HeaderHeight    = "X-Goloop-Height"
+	HeaderSignature = "X-Goloop-Signature"
+
+	DefaultWebhookTimeout = 10000
+)
+
+// webhookSink posts notifications to the URL. With the secret, the body
+// is signed with HMAC-SHA256, and the signature is sent in the header
+// as "sha256=<hex>".
+type webhookSink struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+type webhookSinkConfig struct {
+	URL     string `json:"url"`
+	Secret  string `json:"secret,omitempty"`
+	Timeout int64  `json:"timeout,omitempty"`
+}
+
+func Signature(secret, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *webhookSink) Deliver(height int64, payload []byte) error {
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderHeight, strconv.FormatInt(height, 10))
+	if len(s.secret) > 0 {
+		req.Header.Set(HeaderSignature, Signature(s.secret, payload))
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("WebhookFailure(status=%d)", resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *webhookSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+func newWebhookSink(cfg *SinkConfig) (Sink, error) {
+	var wc webhookSinkConfig
+	if err := json.Unmarshal(cfg.Raw, &wc); err != nil {
+		return nil, errors.IllegalArgumentError.Wrapf(err, "InvalidSinkConfig(name=%s)", cfg.Name)
+	}
+	if u, err := url.Parse(wc.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, errors.IllegalArgumentError.Errorf("InvalidURL(name=%s,url=%s)", cfg.Name, wc.URL)
+	}
+	timeout := wc.Timeout
+	if timeout <= 0 {
+		timeout = DefaultWebhookTimeout
+	}
+	return &webhookSink{
+		url:    wc.URL,
+		secret: []byte(wc.Secret),
+		client: &http.Client{Timeout: time.Duration(timeout) * time.Millisecond},
+	}, nil
+}
@@ -17,15 +17,17 @@
 package chain
 
 import (
+	"encoding/json"
+
 	"github.com/icon-project/goloop/chain/base"
 	"github.com/icon-project/goloop/common/errors"
 	"github.com/icon-project/goloop/service/platform/basic"
 )
 
-type PlatformFactory func(base string, cid int) (base.Platform, error)
+type PlatformFactory func(base string, cid int, config json.RawMessage) (base.Platform, error)
 
 var platformFactories = map[string]PlatformFactory{
-	"basic": func(base string, cid int) (base.Platform, error) {
+	"basic": func(base string, cid int, config json.RawMessage) (base.Platform, error) {
 		return basic.Platform, nil
 	},
 }
@@ -34,12 +36,12 @@ func RegisterPlatform(name string, factory PlatformFactory) {
 	platformFactories[name] = factory
 }
 
-func NewPlatform(name string, base string, cid int) (base.Platform, error) {
+func NewPlatform(name string, base string, cid int, config json.RawMessage) (base.Platform, error) {
 	if len(name) == 0 {
 		name = "basic"
 	}
 	if factory, ok := platformFactories[name]; ok {
-		return factory(base, cid)
+		return factory(base, cid, config)
 	}
 	return nil, errors.NotFoundError.Errorf("PlatformNotFound(name=%s)", name)
 }
@@ -0,0 +1,134 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chain
+
+import (
+	"sync"
+
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/trie/cache"
+	"github.com/icon-project/goloop/network"
+	"github.com/icon-project/goloop/service/eeproxy"
+)
+
+// quota keeps the limits of the resources shared with other chains of
+// the node.
+type quota struct {
+	eem              *eeproxy.LimitedManager
+	nodeCacheMemory  int64
+	networkBandwidth int64
+
+	lock           sync.Mutex
+	rpcConcurrency int
+	rpcActive      int
+	rpcRejected    int64
+}
+
+func (q *quota) init(cfg *Config, pm eeproxy.Manager) (eeproxy.Manager, error) {
+	if err := CheckQuota(cfg.NodeCache, cfg.EEInstances, cfg.NodeCacheMemory,
+		cfg.RPCConcurrency, cfg.NetworkBandwidth); err != nil {
+		return nil, err
+	}
+	q.nodeCacheMemory, _ = ParseSize(cfg.NodeCacheMemory)
+	q.networkBandwidth, _ = ParseSize(cfg.NetworkBandwidth)
+	q.rpcConcurrency = cfg.RPCConcurrency
+	if cfg.EEInstances > 0 && pm != nil {
+		q.eem = eeproxy.NewLimitedManager(pm)
+		if err := q.eem.SetInstances(eeInstancesOf(cfg.EEInstances)); err != nil {
+			return nil, err
+		}
+		return q.eem, nil
+	}
+	return pm, nil
+}
+
+// eeInstancesOf returns the limits of the executors for the instances of
+// the chain. An executor is kept for transactions, so queries can't block
+// the execution of blocks.
+func eeInstancesOf(n int) (total, tx, query int) {
+	if n > 1 {
+		return n, n, n - 1
+	}
+	return n, n, n
+}
+
+func (c *singleChain) AcquireRPC() (func(), error) {
+	q := &c.quota
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.rpcConcurrency > 0 && q.rpcActive >= q.rpcConcurrency {
+		q.rpcRejected += 1
+		return nil, errors.InvalidStateError.Errorf(
+			"RPCConcurrencyExceeded(limit=%d)", q.rpcConcurrency)
+	}
+	q.rpcActive += 1
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			q.lock.Lock()
+			defer q.lock.Unlock()
+			q.rpcActive -= 1
+		})
+	}, nil
+}
+
+func (c *singleChain) NetworkBandwidth() int64 {
+	return c.quota.networkBandwidth
+}
+
+func (c *singleChain) InspectQuota() map[string]interface{} {
+	q := &c.quota
+	m := make(map[string]interface{})
+
+	ee := map[string]interface{}{
+		"limit": c.cfg.EEInstances,
+	}
+	if q.eem != nil {
+		tx, query := q.eem.Usage()
+		ee["transaction"] = tx
+		ee["query"] = query
+	}
+	m["eeInstances"] = ee
+
+	c.dbLock.RLock()
+	database := c.database
+	c.dbLock.RUnlock()
+	m["nodeCacheMemory"] = map[string]interface{}{
+		"limit": q.nodeCacheMemory,
+		"usage": cache.MemoryUsageOf(database),
+	}
+
+	q.lock.Lock()
+	m["rpcConcurrency"] = map[string]interface{}{
+		"limit":    q.rpcConcurrency,
+		"usage":    q.rpcActive,
+		"rejected": q.rpcRejected,
+	}
+	q.lock.Unlock()
+
+	limit, sent, rejected := network.BandwidthUsage(c.nm)
+	if c.nm == nil {
+		limit = q.networkBandwidth
+	}
+	m["networkBandwidth"] = map[string]interface{}{
+		"limit":    limit,
+		"sent":     sent,
+		"rejected": rejected,
+	}
+	return m
+}
@@ -0,0 +1,37 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_eeInstancesOf(t *testing.T) {
+	total, tx, query := eeInstancesOf(4)
+	assert.Equal(t, 4, total)
+	assert.Equal(t, 4, tx)
+	assert.Equal(t, 3, query)
+
+	// a single executor is shared
+	total, tx, query = eeInstancesOf(1)
+	assert.Equal(t, []int{1, 1, 1}, []int{total, tx, query})
+
+	total, tx, query = eeInstancesOf(0)
+	assert.Equal(t, []int{0, 0, 0}, []int{total, tx, query})
+}
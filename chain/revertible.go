@@ -36,6 +36,8 @@ func (h *Revertible) Delete(p string) error {
 		*h = append(*h, func(revert bool) {
 			if revert {
 				// log.Tracef("Revertible.Delete os.Rename(%s,%s)", p2, p)
+				// it may be created again after deletion
+				log.Must(os.RemoveAll(p))
 				log.Must(os.Rename(p2, p))
 			} else {
 				// log.Tracef("Revertible.Delete os.RemoveAll(%s)", p2)
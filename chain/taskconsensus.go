@@ -17,9 +17,15 @@
 package chain
 
 import (
+	"path"
+
+	"github.com/icon-project/goloop/block"
+	"github.com/icon-project/goloop/chain/notify"
 	"github.com/icon-project/goloop/common/errors"
 )
 
+const NotifierDir = "notify"
+
 type taskConsensus struct {
 	chain  *singleChain
 	result resultStore
@@ -66,10 +72,55 @@ func (t *taskConsensus) _start(c *singleChain) error {
 	if err := c.nm.Start(); err != nil {
 		return err
 	}
+	if err := t._startNotifier(c); err != nil {
+		return err
+	}
+	return t._startPruner(c)
+}
+
+func (t *taskConsensus) _startPruner(c *singleChain) error {
+	if c.cfg.PruneRetention == 0 {
+		return nil
+	}
+	p, err := block.NewPruner(c.bm, c.cfg.PruneRetention)
+	if err != nil {
+		return err
+	}
+	if err := p.Start(); err != nil {
+		return err
+	}
+	c.pruner = p
+	return nil
+}
+
+func (t *taskConsensus) _startNotifier(c *singleChain) error {
+	if len(c.cfg.Notifier) == 0 {
+		return nil
+	}
+	cfg, err := notify.ParseConfig(c.cfg.Notifier)
+	if err != nil {
+		return err
+	}
+	cfg.SetBaseDir(c.cfg.AbsBaseDir())
+	dir := path.Join(c.cfg.AbsBaseDir(), NotifierDir)
+	n, err := notify.New(c, cfg, dir)
+	if err != nil {
+		return err
+	}
+	n.Start()
+	c.notifier = n
 	return nil
 }
 
 func (t *taskConsensus) Stop() {
+	if t.chain.notifier != nil {
+		t.chain.notifier.Stop()
+		t.chain.notifier = nil
+	}
+	if t.chain.pruner != nil {
+		t.chain.pruner.Stop()
+		t.chain.pruner = nil
+	}
 	t.chain.srv.RemoveChain(t.chain.cfg.Channel)
 	t.chain.releaseManagers()
 	t.result.SetValue(errors.ErrInterrupted)
@@ -0,0 +1,324 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/icon-project/goloop/block"
+	"github.com/icon-project/goloop/chain/base"
+	"github.com/icon-project/goloop/chain/gs"
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/consensus"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service"
+	"github.com/icon-project/goloop/service/state"
+)
+
+const (
+	ForkTask = "fork"
+)
+
+// ForkParams is the parameters of the fork task. The task makes the
+// database, the WAL and the genesis storage of a new chain in Dir from
+// the block of Height. The new chain has the chain ID CID and the network
+// ID NID, and the blocks after Height are produced by Validators.
+type ForkParams struct {
+	Dir         string            `json:"dir"`
+	GenesisFile string            `json:"genesis_file"`
+	CID         int               `json:"cid"`
+	NID         int               `json:"nid"`
+	Height      int64             `json:"height"`
+	Validators  []*common.Address `json:"validators"`
+}
+
+var forkStates = map[State]string{
+	Starting: "fork starting",
+	Stopping: "fork stopping",
+	Failed:   "fork failed",
+	Finished: "fork done",
+}
+
+type taskFork struct {
+	chain  *singleChain
+	params *ForkParams
+	result resultStore
+
+	stopped    int32
+	resolved   uint64
+	unresolved uint64
+}
+
+func (t *taskFork) String() string {
+	return fmt.Sprintf("Fork(height=%d,cid=%#x,nid=%#x)",
+		t.params.Height, t.params.CID, t.params.NID)
+}
+
+func (t *taskFork) DetailOf(s State) string {
+	switch s {
+	case Started:
+		return fmt.Sprintf("fork started resolved=%d unresolved=%d",
+			atomic.LoadUint64(&t.resolved), atomic.LoadUint64(&t.unresolved))
+	default:
+		if st, ok := forkStates[s]; ok {
+			return st
+		} else {
+			return s.String()
+		}
+	}
+}
+
+func (t *taskFork) Start() error {
+	p := t.params
+	if p.Height < 2 {
+		return errors.IllegalArgumentError.Errorf("InvalidHeight(height=%d)", p.Height)
+	}
+	if p.CID == 0 || p.NID == 0 {
+		return errors.IllegalArgumentError.Errorf(
+			"InvalidID(cid=%#x,nid=%#x)", p.CID, p.NID)
+	}
+	if p.CID == t.chain.CID() {
+		return errors.IllegalArgumentError.Errorf("SameChainID(cid=%#x)", p.CID)
+	}
+	if len(p.Validators) == 0 {
+		return errors.IllegalArgumentError.New("NoValidators")
+	}
+	if len(p.Dir) == 0 || len(p.GenesisFile) == 0 {
+		return errors.IllegalArgumentError.New("NoTargetPath")
+	}
+	if err := t.chain.prepareManagers(); err != nil {
+		return err
+	}
+	blk, err := t.chain.bm.GetLastBlock()
+	if err != nil {
+		t.chain.releaseManagers()
+		return err
+	}
+	if p.Height > blk.Height() {
+		t.chain.releaseManagers()
+		return errors.IllegalArgumentError.Errorf(
+			"InvalidHeight(height=%d,last=%d)", p.Height, blk.Height())
+	}
+	go t.doFork()
+	return nil
+}
+
+func (t *taskFork) doFork() {
+	err := t._fork()
+	t.result.SetValue(err)
+}
+
+func (t *taskFork) OnExport(height int64, r, u int) error {
+	if atomic.LoadInt32(&t.stopped) != 0 {
+		return errors.ErrInterrupted
+	}
+	atomic.StoreUint64(&t.resolved, uint64(r))
+	atomic.StoreUint64(&t.unresolved, uint64(u))
+	return nil
+}
+
+func newValidatorSnapshotOf(dbase db.Database, addrs []module.Address) (state.ValidatorSnapshot, error) {
+	vl := make([]module.Validator, len(addrs))
+	for i, addr := range addrs {
+		v, err := state.ValidatorFromAddress(addr)
+		if err != nil {
+			return nil, err
+		}
+		vl[i] = v
+	}
+	vss, err := state.ValidatorSnapshotFromSlice(dbase, vl)
+	if err != nil {
+		return nil, err
+	}
+	return vss, vss.Flush()
+}
+
+func (t *taskFork) _exportGenesis(blk module.BlockData, votes module.CommitVoteSet) (rerr error) {
+	p := t.params
+	fd, err := os.OpenFile(p.GenesisFile, os.O_CREATE|os.O_WRONLY|os.O_EXCL|os.O_TRUNC, 0700)
+	if err != nil {
+		return err
+	}
+	gsw := gs.NewGenesisStorageWriter(fd)
+	defer func() {
+		_ = gsw.Close()
+		_ = fd.Close()
+		if rerr != nil {
+			_ = os.Remove(p.GenesisFile)
+		}
+	}()
+	g, err := json.Marshal(&gs.PrunedGenesis{
+		CID:    common.HexInt32{Value: int32(p.CID)},
+		NID:    common.HexInt32{Value: int32(p.NID)},
+		Height: common.HexInt64{Value: blk.Height()},
+		Block:  blk.ID(),
+		Votes:  votes.Hash(),
+	})
+	if err != nil {
+		return err
+	}
+	if err := gsw.WriteGenesis(g); err != nil {
+		return errors.Wrap(err, "fail to write genesis")
+	}
+	if _, err := gsw.WriteData(votes.Bytes()); err != nil {
+		return errors.Wrap(err, "fail to write votes")
+	}
+	return nil
+}
+
+func (t *taskFork) _fork() (rerr error) {
+	c := t.chain
+	defer c.releaseManagers()
+
+	p := t.params
+	blk, err := c.bm.GetBlockByHeight(p.Height)
+	if err != nil {
+		return err
+	}
+	pblk, err := c.bm.GetBlockByHeight(p.Height - 1)
+	if err != nil {
+		return err
+	}
+	if bd, err := blk.BTPDigest(); err != nil {
+		return err
+	} else if len(bd.NetworkTypeDigests()) > 0 {
+		return errors.UnsupportedError.Errorf(
+			"ForkWithBTPDigest(height=%d)", p.Height)
+	}
+
+	dbDir := path.Join(p.Dir, DefaultDBDir)
+	_ = os.RemoveAll(dbDir)
+	if err := os.MkdirAll(dbDir, 0700); err != nil {
+		return err
+	}
+	newDB, err := db.Open(dbDir, c.cfg.DBType, strconv.FormatInt(int64(p.NID), 16))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		log.Must(newDB.Close())
+		if rerr != nil {
+			log.Must(os.RemoveAll(dbDir))
+		}
+	}()
+
+	c.logger.Infof("Export blocks for fork to=%s height=%d", dbDir, p.Height)
+	if err := c.bm.ExportBlocks(p.Height, p.Height, newDB, t.OnExport); err != nil {
+		return err
+	}
+
+	var forkExtension func(dbase db.Database, ed []byte) ([]byte, error)
+	if fp, ok := c.plt.(base.ForkPlatform); ok {
+		forkExtension = fp.ForkExtension
+	}
+	result, err := service.ForkResult(newDB, blk.Result(), p.NID, p.CID, forkExtension)
+	if err != nil {
+		return err
+	}
+	voters, err := newValidatorSnapshotOf(newDB, []module.Address{c.Wallet().Address()})
+	if err != nil {
+		return err
+	}
+	addrs := make([]module.Address, len(p.Validators))
+	for i, addr := range p.Validators {
+		addrs[i] = addr
+	}
+	validators, err := newValidatorSnapshotOf(newDB, addrs)
+	if err != nil {
+		return err
+	}
+	bs, err := block.ForkBlocks(newDB, p.Height, result, voters, validators,
+		consensus.NewEmptyCommitVoteList())
+	if err != nil {
+		return err
+	}
+	bdf, err := block.NewBlockDataFactory(c, nil)
+	if err != nil {
+		return err
+	}
+	nblk, err := bdf.NewBlockDataFromReader(bytes.NewReader(bs))
+	if err != nil {
+		return err
+	}
+
+	// votes of the node for the new block
+	ts := common.UnixMicroFromTime(time.Now())
+	if ts <= nblk.Timestamp() {
+		ts = nblk.Timestamp() + 1
+	}
+	votes, err := consensus.NewCommitVoteSetForBlock(c.Wallet(), nblk, ts)
+	if err != nil {
+		return err
+	}
+	ntsHashEntries, err := nblk.NTSHashEntryList()
+	if err != nil {
+		return err
+	}
+	rec, err := consensus.WALRecordBytesFromCommitVoteListBytes(
+		votes.Bytes(), p.Height, nblk.ID(), pblk.Result(), voters,
+		ntsHashEntries, newDB, codec.BC,
+	)
+	if err != nil {
+		return err
+	}
+	walDir := path.Join(p.Dir, DefaultWALDir)
+	if err := consensus.ResetWAL(p.Height, walDir, rec); err != nil {
+		return err
+	}
+	defer func() {
+		if rerr != nil {
+			log.Must(os.RemoveAll(walDir))
+		}
+	}()
+
+	c.logger.Infof("Export genesis for fork to=%s block=%#x", p.GenesisFile, nblk.ID())
+	return t._exportGenesis(nblk, votes)
+}
+
+func (t *taskFork) Stop() {
+	atomic.StoreInt32(&t.stopped, 1)
+}
+
+func (t *taskFork) Wait() error {
+	return t.result.Wait()
+}
+
+func taskForkFactory(c *singleChain, params json.RawMessage) (chainTask, error) {
+	p := new(ForkParams)
+	if err := json.Unmarshal(params, p); err != nil {
+		return nil, err
+	}
+	return &taskFork{
+		chain:  c,
+		params: p,
+	}, nil
+}
+
+func init() {
+	registerTaskFactory(ForkTask, taskForkFactory)
+}
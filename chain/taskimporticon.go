@@ -54,6 +54,7 @@ type importICONParams struct {
 	ConfigURL   string               `json:"config_url"`
 	MaxRPS      int                  `json:"max_rps"`
 	CacheConfig *lcstore.CacheConfig `json:"cache_config,omitempty"`
+	Workers     int                  `json:"workers,omitempty"`
 }
 
 type importICONConfig struct {
@@ -171,6 +172,7 @@ func (t *taskImportICON) _import() (ret error) {
 		Validators:  tc.Validators,
 		StoreURI:    t.params.StoreURI,
 		MaxRPS:      t.params.MaxRPS,
+		Workers:     t.params.Workers,
 	}
 	if t.params.CacheConfig != nil {
 		config.CacheConfig = *t.params.CacheConfig
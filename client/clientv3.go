@@ -163,6 +163,12 @@ type BTPSourceInformation struct {
 	NetworkTypeIDs []jsonrpc.HexInt `json:"networkTypeIDs"`
 }
 
+//refer server/v3/api_v3.go multiCall
+type CallResult struct {
+	Result interface{}    `json:"result"`
+	Error  *jsonrpc.Error `json:"error,omitempty"`
+}
+
 func (c *ClientV3) GetLastBlock() (*Block, error) {
 	blk := &Block{}
 	_, err := c.Do("icx_getLastBlock", nil, blk)
@@ -199,6 +205,15 @@ func (c *ClientV3) Call(param *v3.CallParam) (interface{}, error) {
 	return result, nil
 }
 
+func (c *ClientV3) MultiCall(param *v3.MultiCallParam) ([]CallResult, error) {
+	var result []CallResult
+	_, err := c.Do("icx_multiCall", param, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 func (c *ClientV3) GetBalance(param *v3.AddressParam) (*jsonrpc.HexInt, error) {
 	var result jsonrpc.HexInt
 	_, err := c.Do("icx_getBalance", param, &result)
@@ -9,6 +9,7 @@ import (
 	"io/ioutil"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -125,6 +126,12 @@ func NewChainCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Comman
 			param.Role, _ = fs.GetUint("role")
 			param.DBType, _ = fs.GetString("db_type")
 			param.Platform, _ = fs.GetString("platform")
+			if platformConfig, _ := fs.GetString("platform_config"); len(platformConfig) > 0 {
+				if !json.Valid([]byte(platformConfig)) {
+					return errors.Errorf("invalid platform_config=%s", platformConfig)
+				}
+				param.PlatformConfig = json.RawMessage(platformConfig)
+			}
 			param.ConcurrencyLevel, _ = fs.GetInt("concurrency")
 			param.NormalTxPoolSize, _ = fs.GetInt("normal_tx_pool")
 			param.PatchTxPoolSize, _ = fs.GetInt("patch_tx_pool")
@@ -133,6 +140,7 @@ func NewChainCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Comman
 			param.Channel, _ = fs.GetString("channel")
 			param.SecureSuites, _ = fs.GetString("secure_suites")
 			param.SecureAeads, _ = fs.GetString("secure_aeads")
+			param.Compressions, _ = fs.GetString("compressions")
 			param.DefWaitTimeout, _ = fs.GetInt64("default_wait_timeout")
 			param.MaxWaitTimeout, _ = fs.GetInt64("max_wait_timeout")
 			param.TxTimeout, _ = fs.GetInt64("tx_timeout")
@@ -146,6 +154,19 @@ func NewChainCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Comman
 				param.NephewsLimit = &nephewsLimit
 			}
 			param.ValidateTxOnSend, _ = fs.GetBool("validate_tx_on_send")
+			if notifier, _ := fs.GetString("notifier"); len(notifier) > 0 {
+				if !json.Valid([]byte(notifier)) {
+					return errors.Errorf("invalid notifier=%s", notifier)
+				}
+				param.Notifier = json.RawMessage(notifier)
+			}
+			param.PruneRetention, _ = fs.GetInt64("prune_retention")
+			param.BackupSchedule, _ = fs.GetString("backup_schedule")
+			param.BackupRetention, _ = fs.GetString("backup_retention")
+			param.EEInstances, _ = fs.GetInt("ee_instances")
+			param.NodeCacheMemory, _ = fs.GetString("node_cache_memory")
+			param.RPCConcurrency, _ = fs.GetInt("rpc_concurrency")
+			param.NetworkBandwidth, _ = fs.GetString("network_bandwidth")
 
 			var buf *bytes.Buffer
 			if len(genesisZip) > 0 {
@@ -186,6 +207,7 @@ func NewChainCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Comman
 	joinFlags.Uint("role", 3, "[0:None, 1:Seed, 2:Validator, 3:Both]")
 	joinFlags.String("db_type", "goleveldb", "Name of database system("+strings.Join(db.RegisteredBackendTypes(), ", ")+")")
 	joinFlags.String("platform", "", "Name of service platform")
+	joinFlags.String("platform_config", "", "Platform specific configuration in JSON")
 	joinFlags.Int("concurrency", 1, "Maximum number of executors to be used for concurrency")
 	joinFlags.Int("normal_tx_pool", 0, "Size of normal transaction pool")
 	joinFlags.Int("patch_tx_pool", 0, "Size of patch transaction pool")
@@ -196,6 +218,8 @@ func NewChainCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Comman
 		"Supported Secure suites with order (none,tls,ecdhe) - Comma separated string")
 	joinFlags.String("secure_aeads", "chacha,aes128,aes256",
 		"Supported Secure AEAD with order (chacha,aes128,aes256) - Comma separated string")
+	joinFlags.String("compressions", "snappy",
+		"Supported packet compressions with order (none,snappy) - Comma separated string")
 	joinFlags.Int64("default_wait_timeout", 0, "Default wait timeout in milli-second (0: disable)")
 	joinFlags.Int64("max_wait_timeout", 0, "Max wait timeout in milli-second (0: uses same value of default_wait_timeout)")
 	joinFlags.Int64("tx_timeout", 0, "Transaction timeout in milli-second (0: uses system default value)")
@@ -203,6 +227,14 @@ func NewChainCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Comman
 	joinFlags.Int("children_limit", -1, "Maximum number of child connections (-1: uses system default value)")
 	joinFlags.Int("nephews_limit", -1, "Maximum number of nephew connections (-1: uses system default value)")
 	joinFlags.Bool("validate_tx_on_send", false, "Validate transaction on send")
+	joinFlags.String("notifier", "", "Notifier configuration in JSON")
+	joinFlags.Int64("prune_retention", 0, "Number of recent blocks to keep bodies, receipts and states (0: no pruning)")
+	joinFlags.String("backup_schedule", "", "Schedule of backups as interval(ex: 24h) or cron expression(ex: \"0 3 * * *\")")
+	joinFlags.String("backup_retention", "", "Retention of scheduled backups as count(ex: 7) or tiers(ex: last=3,daily=7,weekly=4)")
+	joinFlags.Int("ee_instances", 0, "Maximum number of EE instances used by the chain (0: no limit)")
+	joinFlags.String("node_cache_memory", "", "Maximum memory of node caches(ex: 512MB) (empty: no limit)")
+	joinFlags.Int("rpc_concurrency", 0, "Maximum number of JSON-RPC requests handled concurrently (0: no limit)")
+	joinFlags.String("network_bandwidth", "", "Maximum bytes per second sent by the chain(ex: 10MB) (empty: no limit)")
 
 	leaveCmd := &cobra.Command{
 		Use:   "leave CID",
@@ -385,18 +417,70 @@ func NewChainCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Comman
 	pruneFlags.Int64("height", 0, "Block Height")
 	MarkAnnotationRequired(pruneFlags, "height")
 
+	forkCmd := &cobra.Command{
+		Use:   "fork CID NEWCID",
+		Short: "Start to make a new chain from the state at the height",
+		Args:  ArgsWithDefaultErrorFunc(cobra.ExactArgs(2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fs := cmd.Flags()
+			param := &node.ChainForkParam{}
+			if cid, err := strconv.ParseInt(args[1], 0, 32); err != nil {
+				return errors.Errorf("invalid NEWCID %s", args[1])
+			} else {
+				param.CID.Value = int32(cid)
+			}
+			if s, _ := fs.GetString("nid"); len(s) > 0 {
+				if nid, err := strconv.ParseInt(s, 0, 32); err != nil {
+					return errors.Errorf("invalid nid %s", s)
+				} else {
+					param.NID.Value = int32(nid)
+				}
+			}
+			param.Channel, _ = fs.GetString("channel")
+			param.Height, _ = fs.GetInt64("height")
+			validators, _ := fs.GetStringSlice("validators")
+			for _, v := range validators {
+				param.Validators = append(param.Validators,
+					common.AddressToPtr(mustParseAddress(v)))
+			}
+
+			var v string
+			reqUrl := node.UrlChain + "/" + args[0] + "/fork"
+			_, err := adminClient.PostWithJson(reqUrl, param, &v)
+			if err != nil {
+				return err
+			}
+			fmt.Println(v)
+			return nil
+		},
+	}
+	rootCmd.AddCommand(forkCmd)
+	forkFlags := forkCmd.Flags()
+	forkFlags.Int64("height", 0, "Block Height")
+	forkFlags.StringSlice("validators", nil, "Address or keystore of validators of the new chain")
+	forkFlags.String("nid", "", "Network ID of the new chain(default:NEWCID)")
+	forkFlags.String("channel", "", "Channel of the new chain")
+	MarkAnnotationRequired(forkFlags, "height", "validators")
+
 	backupCmd := &cobra.Command{
 		Use:   "backup CID",
 		Short: "Start to backup the channel",
 		Args:  ArgsWithDefaultErrorFunc(cobra.ExactArgs(1)),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			fs := cmd.Flags()
+			reqUrl := node.UrlChain + "/" + args[0] + "/backup"
+			if status, _ := fs.GetBool("status"); status {
+				resp, err := adminClient.Get(reqUrl, nil)
+				if err != nil {
+					return err
+				}
+				return JsonPrettyCopyAndClose(os.Stdout, resp.Body)
+			}
 			manual, _ := fs.GetBool("manual")
 			param := &node.ChainBackupParam{
 				Manual: manual,
 			}
 			var v string
-			reqUrl := node.UrlChain + "/" + args[0] + "/backup"
 			_, err := adminClient.PostWithJson(reqUrl, param, &v)
 			if err != nil {
 				return err
@@ -408,6 +492,56 @@ func NewChainCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Comman
 	rootCmd.AddCommand(backupCmd)
 	backupFlags := backupCmd.Flags()
 	backupFlags.Bool("manual", false, "Manual backup mode (just release database)")
+	backupFlags.Bool("status", false, "Show the status of scheduled backups")
+
+	exportCmd := &cobra.Command{
+		Use:   "export CID [DIR]",
+		Short: "Export blocks, transactions, receipts and event logs to the directory",
+		Args:  ArgsWithDefaultErrorFunc(cobra.RangeArgs(1, 2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fs := cmd.Flags()
+			reqUrl := node.UrlChain + "/" + args[0] + "/export"
+			if status, _ := fs.GetBool("status"); status {
+				resp, err := adminClient.Get(reqUrl, nil)
+				if err != nil {
+					return err
+				}
+				return JsonPrettyCopyAndClose(os.Stdout, resp.Body)
+			}
+			var v string
+			if stop, _ := fs.GetBool("stop"); stop {
+				if _, err := adminClient.Delete(reqUrl, &v); err != nil {
+					return err
+				}
+				fmt.Println(v)
+				return nil
+			}
+			if len(args) < 2 {
+				return fmt.Errorf("DIR is required")
+			}
+			param := &node.ChainExportParam{}
+			param.From, _ = fs.GetInt64("from")
+			param.To, _ = fs.GetInt64("to")
+			param.Format, _ = fs.GetString("format")
+			dir, err := filepath.Abs(args[1])
+			if err != nil {
+				return err
+			}
+			param.Dir = dir
+			if _, err := adminClient.PostWithJson(reqUrl, param, &v); err != nil {
+				return err
+			}
+			fmt.Println(v)
+			return nil
+		},
+	}
+	rootCmd.AddCommand(exportCmd)
+	exportFlags := exportCmd.Flags()
+	exportFlags.Int64("from", 0, "Block Height to start")
+	exportFlags.Int64("to", -1, "Block Height to end(negative value to follow the chain)")
+	exportFlags.String("format", "ndjson", "Format of tables(ndjson, csv)")
+	exportFlags.Bool("status", false, "Show the status of the last export")
+	exportFlags.Bool("stop", false, "Stop the export")
 
 	genesisCmd := &cobra.Command{
 		Use:   "genesis CID FILE",
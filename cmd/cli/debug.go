@@ -24,6 +24,7 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/icon-project/goloop/client"
+	"github.com/icon-project/goloop/common/intconv"
 	"github.com/icon-project/goloop/server/jsonrpc"
 	v3 "github.com/icon-project/goloop/server/v3"
 )
@@ -52,12 +53,25 @@ func NewDebugCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Comman
 	BindPFlags(vc, rootCmd.PersistentFlags())
 
 	traceCmd := &cobra.Command{
-		Use:   "trace HASH",
-		Short: "Get trace of the transaction",
-		Args:  cobra.ExactArgs(1),
+		Use:   "trace [HASH]",
+		Short: "Get trace of the transaction or the block",
+		Args:  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			param := &v3.TransactionHashParam{
-				Hash: jsonrpc.HexBytes(args[0]),
+			param := &v3.TraceParam{
+				Mode: cmd.Flag("mode").Value.String(),
+			}
+			if len(args) > 0 {
+				param.Hash = jsonrpc.HexBytes(args[0])
+			} else if block := cmd.Flag("block").Value.String(); len(block) > 0 {
+				param.Block = jsonrpc.HexBytes(block)
+			} else if cmd.Flag("height").Changed {
+				height, err := cmd.Flags().GetInt64("height")
+				if err != nil {
+					return err
+				}
+				param.Height = jsonrpc.HexInt(intconv.FormatInt(height))
+			} else {
+				return cmd.Help()
 			}
 			trace, err := debugClient.Do("debug_getTrace", param, nil)
 			if err != nil {
@@ -67,6 +81,11 @@ func NewDebugCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Comman
 		},
 	}
 	rootCmd.AddCommand(traceCmd)
+	traceFlags := traceCmd.Flags()
+	traceFlags.String("mode", v3.TraceModeInvoke,
+		"Trace mode (invoke, accessList, callTree)")
+	traceFlags.String("block", "", "Hash of the block to trace (accessList, callTree)")
+	traceFlags.Int64("height", 0, "Height of the block to trace (accessList, callTree)")
 
 	return rootCmd, vc
 }
@@ -162,7 +162,13 @@ func NewServerCmd(parentCmd *cobra.Command, parentVc *viper.Viper, version, buil
 	rootPFlags.String("log_forwarder_level", "info", "LogForwarder level")
 	rootPFlags.String("log_forwarder_name", "", "LogForwarder name")
 	rootPFlags.StringToString("log_forwarder_options", nil, "LogForwarder options, comma-separated 'key=value'")
-	rootPFlags.String("engines", "python", "Execution engines, comma-separated (python,java)")
+	rootPFlags.String("engines", "python", "Execution engines, comma-separated (python,java,wasm)")
+	rootPFlags.String("ee_remote_addr", "", "Listen ip-port of remote execution engines over TLS")
+	rootPFlags.String("ee_remote_pools", "", "Remote pools running java engine, comma-separated 'name[:weight]'")
+	rootPFlags.String("ee_tls_cert", "", "Certificate file for remote execution engines")
+	rootPFlags.String("ee_tls_key", "", "Private key file for remote execution engines")
+	rootPFlags.String("ee_tls_ca", "", "CA certificate file to verify remote execution engines")
+	rootPFlags.String("ee_shared_dir", "", "Directory shared with remote pools, containing node_dir")
 
 	rootPFlags.String("log_writer_filename", "", "Log filename (rotated files resides in same directory)")
 	rootPFlags.Int("log_writer_maxsize", 100, "Maximum log file size in MiB")
@@ -296,6 +302,9 @@ func MergeWithViper(vc *viper.Viper, cfg *ServerConfig) error {
 	nodeDir := vc.GetString("node_dir")
 	cliSocket := vc.GetString("node_sock")
 	eeSocket := vc.GetString("ee_socket")
+	eeTLSCert := vc.GetString("ee_tls_cert")
+	eeTLSKey := vc.GetString("ee_tls_key")
+	eeTLSCA := vc.GetString("ee_tls_ca")
 	backupDir := vc.GetString("backup_dir")
 	lwFilename := vc.GetString("log_writer_filename")
 
@@ -389,6 +398,15 @@ func MergeWithViper(vc *viper.Viper, cfg *ServerConfig) error {
 	if eeSocket != "" {
 		cfg.EESocket = cfg.ResolveRelative(eeSocket)
 	}
+	if eeTLSCert != "" {
+		cfg.EETLSCert = cfg.ResolveRelative(eeTLSCert)
+	}
+	if eeTLSKey != "" {
+		cfg.EETLSKey = cfg.ResolveRelative(eeTLSKey)
+	}
+	if eeTLSCA != "" {
+		cfg.EETLSCA = cfg.ResolveRelative(eeTLSCA)
+	}
 	if backupDir != "" {
 		cfg.BackupDir = cfg.ResolveRelative(backupDir)
 	}
@@ -26,6 +26,7 @@ import (
 	"github.com/icon-project/goloop/server"
 	"github.com/icon-project/goloop/server/metric"
 	"github.com/icon-project/goloop/service/eeproxy"
+	"github.com/icon-project/goloop/service/platform/basic"
 )
 
 const (
@@ -138,6 +139,8 @@ func main() {
 	flag.IntVar(&cfg.MaxBlockTxBytes, "max_block_tx_bytes", 0, "Maximum size of transactions in a block")
 	flag.StringVar(&cfg.NodeCache, "node_cache", chain.NodeCacheDefault, "Node cache (none,small,large)")
 	flag.BoolVar(&cfg.ValidateTxOnSend, "validate_tx_on_send", false, "Validate transaction on send")
+	flag.Int64Var(&cfg.PruneRetention, "prune_retention", 0, "Number of recent blocks to keep bodies, receipts and states (0: no pruning)")
+	flag.StringVar(&cfg.DevMode, "dev_mode", "", "Dev mode producing blocks without consensus (instant, manual)")
 	cfg.ChildrenLimit = flag.Int("children_limit", -1, "Maximum number of child connections (-1: uses system default value)")
 	cfg.NephewsLimit = flag.Int("nephews_limit", -1, "Maximum number of nephew connections (-1: uses system default value)")
 	flag.StringVar(&cfg.LogLevel, "log_level", "debug", "Main log level")
@@ -151,7 +154,7 @@ func main() {
 	flag.Int64Var(&cfg.DefWaitTimeout, "default_wait_timeout", 0, "Default wait timeout in milli-second (0: disable)")
 	flag.Int64Var(&cfg.MaxWaitTimeout, "max_wait_timeout", 0, "Max wait timeout in milli-second (0: uses same value of default_wait_timeout)")
 	flag.Int64Var(&cfg.TxTimeout, "tx_timeout", 0, "Transaction timeout in milli-second (0: uses system default value)")
-	flag.StringVar(&cfg.Engines, "engines", "python", "Execution engines, comma-separated (python,java)")
+	flag.StringVar(&cfg.Engines, "engines", "python", "Execution engines, comma-separated (python,java,wasm)")
 	flag.IntVar(&cfg.WSMaxSession, "ws_max_session", server.DefaultWSMaxSession, "Websocket session limit (use -1 to disable)")
 	flag.StringVar(&lwCfg.Filename, "log_writer_filename", "", "Log filename")
 	flag.IntVar(&lwCfg.MaxSize, "log_writer_maxsize", 100, "Log file max size")
@@ -280,6 +283,11 @@ func Execute(cmd *cobra.Command, args []string) {
 		if cfg.NID != 0 {
 			genesis["nid"] = fmt.Sprintf("%#x", cfg.NID)
 		}
+		if len(cfg.DevMode) > 0 && len(cfg.Platform) == 0 {
+			// snapshots of dev mode require the chain ID in the state
+			genesis["chain"].(map[string]interface{})["revision"] =
+				fmt.Sprintf("%#x", basic.LatestRevision)
+		}
 		cfg.Genesis, _ = json.Marshal(genesis)
 	}
 
@@ -347,6 +355,10 @@ func Execute(cmd *cobra.Command, args []string) {
 		cfg.LogWriter = nil
 	}
 
+	if err := chain.CheckDevMode(cfg.DevMode); err != nil {
+		log.Panicf("Invalid dev_mode err=%+v", err)
+	}
+
 	if *cfg.ChildrenLimit < 0 {
 		cfg.ChildrenLimit = nil
 	}
@@ -17,10 +17,86 @@
 package main
 
 import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
 	"github.com/icon-project/goloop/chain"
+	"github.com/icon-project/goloop/cmd/cli"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/common/log"
 	"github.com/icon-project/goloop/icon"
+	"github.com/icon-project/goloop/node"
 )
 
 func init() {
 	chain.RegisterPlatform("icon", icon.NewPlatform)
 }
+
+// openChainDatabase opens the database of the chain in the node directory.
+// The chain should be stopped before opening it.
+func openChainDatabase(nodeDir string, cid int64) (db.Database, error) {
+	chainDir := path.Join(nodeDir, strconv.FormatInt(cid, 16))
+	bs, err := ioutil.ReadFile(path.Join(chainDir, node.ChainConfigFileName))
+	if err != nil {
+		return nil, errors.NotFoundError.Wrapf(err, "NoChain(cid=%#x,dir=%s)", cid, chainDir)
+	}
+	cfg := new(chain.Config)
+	if err = json.Unmarshal(bs, cfg); err != nil {
+		return nil, err
+	}
+	dbName := strconv.FormatInt(int64(cfg.NID), 16)
+	return db.Open(path.Join(chainDir, chain.DefaultDBDir), cfg.DBType, dbName)
+}
+
+func newIISSRecalcCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "iiss-recalc CID START_HEIGHT",
+		Short: "Recalculate IISS rewards of the term and compare with the stored result",
+		Long: "Recalculate IISS rewards of the term starting at START_HEIGHT with the snapshots\n" +
+			"stored in the chain database, then compare the result with the stored one.\n" +
+			"The chain should be stopped, and nothing is written to the database.",
+		Args: cli.ArgsWithDefaultErrorFunc(cobra.ExactArgs(2)),
+		// It uses the chain database instead of DEBUG API
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return cli.ValidateFlags(cmd.Flags())
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cid, err := intconv.ParseInt(args[0], 64)
+			if err != nil {
+				return errors.Errorf("invalid CID=%s", args[0])
+			}
+			startHeight, err := intconv.ParseInt(args[1], 64)
+			if err != nil {
+				return errors.Errorf("invalid START_HEIGHT=%s", args[1])
+			}
+			nodeDir, _ := cmd.Flags().GetString("node_dir")
+			dbase, err := openChainDatabase(nodeDir, cid)
+			if err != nil {
+				return err
+			}
+			defer dbase.Close()
+
+			logger := log.New()
+			if lv, err := log.ParseLevel(cmd.Flag("log_level").Value.String()); err == nil {
+				logger.SetLevel(lv)
+			}
+			result, err := icon.RecalculateIISS(dbase, startHeight, logger)
+			if err != nil {
+				return err
+			}
+			return cli.JsonPrettyPrintln(os.Stdout, result)
+		},
+	}
+	flags := cmd.Flags()
+	flags.String("node_dir", "", "Node data directory containing the chain")
+	flags.String("log_level", "warn", "Log level of calculation (trace,debug,info,warn,error)")
+	cli.MarkAnnotationRequired(flags, "node_dir")
+	return cmd
+}
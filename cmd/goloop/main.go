@@ -44,7 +44,8 @@ func main() {
 	cli.NewUserCmd(rootCmd, rootVc)
 	cli.NewStatsCmd(rootCmd, rootVc)
 	cli.NewRpcCmd(rootCmd, nil)
-	cli.NewDebugCmd(rootCmd, nil)
+	debugCmd, _ := cli.NewDebugCmd(rootCmd, nil)
+	debugCmd.AddCommand(newIISSRecalcCmd())
 	rootCmd.AddCommand(
 		cli.NewGStorageCmd("gs"),
 		cli.NewGenesisCmd("gn"),
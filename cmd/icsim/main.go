@@ -0,0 +1,79 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/icon/icsim"
+)
+
+func main() {
+	var output, logLevel string
+	cmd := &cobra.Command{
+		Use:   "icsim SCENARIO",
+		Short: "Run IISS scenario with the simulator",
+		Long: "Run IISS scenario in JSON or YAML with the simulator, and check its expectations.\n" +
+			"Supply, rewards and validators of each term are reported in CSV.",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			lv, err := log.ParseLevel(logLevel)
+			if err != nil {
+				return err
+			}
+			log.GlobalLogger().SetLevel(lv)
+
+			s, err := icsim.LoadScenario(args[0])
+			if err != nil {
+				return err
+			}
+			var w io.Writer = os.Stdout
+			if len(output) > 0 {
+				f, err := os.Create(output)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+			failures, err := icsim.RunScenario(s, w)
+			for _, failure := range failures {
+				fmt.Fprintln(os.Stderr, "FAIL:", failure)
+			}
+			if err != nil {
+				return err
+			}
+			if len(failures) > 0 {
+				return errors.Errorf("%d expectation(s) failed", len(failures))
+			}
+			return nil
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVarP(&output, "output", "o", "", "Output file of the report in CSV (default: stdout)")
+	flags.StringVar(&logLevel, "log_level", "warn", "Log level of the simulator (trace,debug,info,warn,error)")
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
@@ -66,6 +66,14 @@ const (
 	// ListByMerkleRootBase is the base for the bucket that maps list
 	// from network type dependent merkle root(list)
 	ListByMerkleRootBase BucketID = "L"
+
+	// ReferenceCount maps the number of references from bucket ID and key
+	// of the data. It's used for pruning data of old blocks.
+	ReferenceCount BucketID = "N"
+
+	// PrunerGarbage maps keys of the data released by the pruner from the
+	// height of the block releasing them.
+	PrunerGarbage BucketID = "G"
 )
 
 // internalKey returns key prefixed with the bucket's id.
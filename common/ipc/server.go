@@ -1,6 +1,7 @@
 package ipc
 
 import (
+	"crypto/tls"
 	"io"
 	"net"
 	"os"
@@ -30,6 +31,7 @@ type Server interface {
 type server struct {
 	listener net.Listener
 	handler  ConnectionHandler
+	tls      *tls.Config
 }
 
 func (s *server) Addr() net.Addr {
@@ -37,14 +39,14 @@ func (s *server) Addr() net.Addr {
 }
 
 func (s *server) Listen(network, address string) error {
-	d := path.Dir(address)
-	if _, err := os.Stat(d); os.IsNotExist(err) {
-		if err := os.MkdirAll(d, 0755); err != nil {
-			log.Fatalf("Fail to create socket directory=%s err=%+v", d, err)
-		}
-	}
 	switch network {
 	case "unix":
+		d := path.Dir(address)
+		if _, err := os.Stat(d); os.IsNotExist(err) {
+			if err := os.MkdirAll(d, 0755); err != nil {
+				log.Fatalf("Fail to create socket directory=%s err=%+v", d, err)
+			}
+		}
 		os.Remove(address)
 	}
 	listener, err := net.Listen(network, address)
@@ -54,6 +56,9 @@ func (s *server) Listen(network, address string) error {
 	if ulsr, ok := listener.(*net.UnixListener); ok {
 		ulsr.SetUnlinkOnClose(true)
 	}
+	if s.tls != nil {
+		listener = tls.NewListener(listener, s.tls)
+	}
 	s.listener = listener
 	return nil
 }
@@ -63,6 +68,13 @@ func (s *server) SetHandler(handler ConnectionHandler) {
 }
 
 func (s *server) handleConnection(conn net.Conn) {
+	if tc, ok := conn.(*tls.Conn); ok {
+		if err := handshake(tc); err != nil {
+			log.Warnf("Fail to handshake remote=%s err=%+v", conn.RemoteAddr(), err)
+			conn.Close()
+			return
+		}
+	}
 	co := connectionFromConn(conn)
 	handler := s.handler
 	if handler != nil {
@@ -0,0 +1,118 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ipc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"os"
+	"time"
+
+	"github.com/icon-project/goloop/common/errors"
+)
+
+const handshakeTimeout = 10 * time.Second
+
+// NewTLSConfig returns the configuration for mutual TLS. Both of the
+// server and the client should have the certificate issued by the CA.
+func NewTLSConfig(certFile, keyFile, caFile string, server bool) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, errors.IllegalArgumentError.Wrapf(err,
+			"FailToLoadKeyPair(cert=%s,key=%s)", certFile, keyFile)
+	}
+	bs, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, errors.IllegalArgumentError.Wrapf(err,
+			"FailToReadCA(file=%s)", caFile)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(bs) {
+		return nil, errors.IllegalArgumentError.Errorf(
+			"NoCertificatesInCA(file=%s)", caFile)
+	}
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if server {
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	} else {
+		cfg.RootCAs = pool
+	}
+	return cfg, nil
+}
+
+// NewTLSServer returns the server accepting connections over TLS. The
+// handshake is done before OnConnect of the handler.
+func NewTLSServer(cfg *tls.Config) Server {
+	return &server{tls: cfg}
+}
+
+func DialTLS(network, address string, cfg *tls.Config) (Connection, error) {
+	dialer := &net.Dialer{Timeout: handshakeTimeout}
+	if conn, err := tls.DialWithDialer(dialer, network, address, cfg); err != nil {
+		return nil, err
+	} else {
+		return connectionFromConn(conn), nil
+	}
+}
+
+func handshake(conn *tls.Conn) error {
+	if err := conn.SetDeadline(time.Now().Add(handshakeTimeout)); err != nil {
+		return err
+	}
+	if err := conn.Handshake(); err != nil {
+		return err
+	}
+	return conn.SetDeadline(time.Time{})
+}
+
+// PeerName returns the common name of the verified certificate of the
+// peer. It returns empty string if the connection isn't over TLS.
+func PeerName(c Connection) string {
+	if pc, ok := c.(interface{ PeerName() string }); ok {
+		return pc.PeerName()
+	}
+	return ""
+}
+
+func (c *connection) PeerName() string {
+	if tc, ok := c.conn.(*tls.Conn); ok {
+		cs := tc.ConnectionState()
+		if len(cs.VerifiedChains) > 0 && len(cs.PeerCertificates) > 0 {
+			return cs.PeerCertificates[0].Subject.CommonName
+		}
+	}
+	return ""
+}
+
+// IsTLS returns true if the connection is over TLS, regardless of the
+// name of the peer.
+func IsTLS(c Connection) bool {
+	if tc, ok := c.(interface{ IsTLS() bool }); ok {
+		return tc.IsTLS()
+	}
+	return false
+}
+
+func (c *connection) IsTLS() bool {
+	_, ok := c.conn.(*tls.Conn)
+	return ok
+}
@@ -0,0 +1,216 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ipc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testCA struct {
+	cert   *x509.Certificate
+	key    *ecdsa.PrivateKey
+	serial int64
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	return &testCA{cert: cert, key: key, serial: 1}
+}
+
+func writePEM(t *testing.T, file, typ string, bs []byte) {
+	f, err := os.Create(file)
+	assert.NoError(t, err)
+	defer f.Close()
+	assert.NoError(t, pem.Encode(f, &pem.Block{Type: typ, Bytes: bs}))
+}
+
+// issue writes the certificate and the key for the name into the directory,
+// and returns file names of them.
+func (ca *testCA) issue(t *testing.T, dir, name string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	ca.serial += 1
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(ca.serial),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{
+			x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth,
+		},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	assert.NoError(t, err)
+	kbs, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	certFile := path.Join(dir, name+".crt")
+	keyFile := path.Join(dir, name+".key")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", kbs)
+	return certFile, keyFile
+}
+
+func (ca *testCA) write(t *testing.T, dir string) string {
+	file := path.Join(dir, "ca.crt")
+	writePEM(t, file, "CERTIFICATE", ca.cert.Raw)
+	return file
+}
+
+type echoHandler struct {
+	peers chan string
+}
+
+func (h *echoHandler) OnConnect(c Connection) error {
+	h.peers <- PeerName(c)
+	c.SetHandler(1, h)
+	return nil
+}
+
+func (h *echoHandler) OnClose(c Connection) {
+}
+
+func (h *echoHandler) HandleMessage(c Connection, msg uint, data []byte) error {
+	return c.Send(msg, data)
+}
+
+type recvHandler struct {
+	data chan []byte
+}
+
+func (h *recvHandler) HandleMessage(c Connection, msg uint, data []byte) error {
+	h.data <- data
+	return nil
+}
+
+func TestTLSServer(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	caFile := ca.write(t, dir)
+	srvCert, srvKey := ca.issue(t, dir, "localhost")
+	cliCert, cliKey := ca.issue(t, dir, "pool1")
+
+	srvCfg, err := NewTLSConfig(srvCert, srvKey, caFile, true)
+	assert.NoError(t, err)
+	cliCfg, err := NewTLSConfig(cliCert, cliKey, caFile, false)
+	assert.NoError(t, err)
+	cliCfg.ServerName = "localhost"
+
+	s := NewTLSServer(srvCfg)
+	assert.NoError(t, s.Listen("tcp", "127.0.0.1:0"))
+	defer s.Close()
+	h := &echoHandler{peers: make(chan string, 1)}
+	s.SetHandler(h)
+	go s.Loop()
+
+	addr := s.Addr().String()
+	c, err := DialTLS("tcp", addr, cliCfg)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer c.Close()
+	assert.Equal(t, "pool1", <-h.peers)
+	assert.Equal(t, "localhost", PeerName(c))
+	assert.True(t, IsTLS(c))
+
+	rh := &recvHandler{data: make(chan []byte, 1)}
+	c.SetHandler(1, rh)
+	go c.HandleMessage()
+	assert.NoError(t, c.Send(1, []byte("hello")))
+	select {
+	case bs := <-rh.data:
+		assert.Contains(t, string(bs), "hello")
+	case <-time.After(5 * time.Second):
+		t.Fatal("no echo from the server")
+	}
+
+	// connection without client certificate is rejected
+	noCert := &tls.Config{RootCAs: cliCfg.RootCAs, ServerName: "localhost"}
+	if c2, err := DialTLS("tcp", addr, noCert); err == nil {
+		defer c2.Close()
+		err = c2.Send(1, []byte("hello"))
+		if err == nil {
+			err = c2.HandleMessage()
+		}
+		assert.Error(t, err)
+	}
+
+	// client certificate issued by another CA is rejected
+	other := newTestCA(t)
+	otherCert, otherKey := other.issue(t, t.TempDir(), "pool2")
+	otherCfg, err := NewTLSConfig(otherCert, otherKey, caFile, false)
+	assert.NoError(t, err)
+	otherCfg.ServerName = "localhost"
+	if c3, err := DialTLS("tcp", addr, otherCfg); err == nil {
+		defer c3.Close()
+		err = c3.Send(1, []byte("hello"))
+		if err == nil {
+			err = c3.HandleMessage()
+		}
+		assert.Error(t, err)
+	}
+	select {
+	case peer := <-h.peers:
+		t.Errorf("unexpected connection from peer=%q", peer)
+	default:
+	}
+}
+
+func TestNewTLSConfig_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	caFile := ca.write(t, dir)
+	cert, key := ca.issue(t, dir, "pool1")
+
+	_, err := NewTLSConfig(cert, path.Join(dir, "none.key"), caFile, true)
+	assert.Error(t, err)
+	_, err = NewTLSConfig(cert, key, path.Join(dir, "none.crt"), true)
+	assert.Error(t, err)
+	_, err = NewTLSConfig(cert, key, key, true)
+	assert.Error(t, err)
+}
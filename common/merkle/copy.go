@@ -136,6 +136,16 @@ func NewCopyContext(src db.Database, dst db.Database) *CopyContext {
 	}
 }
 
+// NewCopyContextWithBuilder returns CopyContext resolving requests with
+// the builder instead of the default one.
+func NewCopyContextWithBuilder(src db.Database, dst db.Database, builder Builder) *CopyContext {
+	return &CopyContext{
+		builder: builder,
+		src:     src,
+		dst:     dst,
+	}
+}
+
 // PrepareCopyContext prepares CopyContext for copying src to dst.
 // If dst comes from another CopyContext, then it returns the original one
 // for tracking progress properly.
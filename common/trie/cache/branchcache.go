@@ -151,6 +151,10 @@ func openfile(path string) (f *os.File, err error) {
 	return f, nil
 }
 
+func (c *BranchCache) Memory() int64 {
+	return int64(c.offset) * (nodeSlotSize + cacheItemSize)
+}
+
 func (c *BranchCache) OnAttach(id []byte) cacheImpl {
 	if c.missed >= fullCacheMigrationThreshold {
 		if logCacheEvents {
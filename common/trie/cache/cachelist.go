@@ -158,6 +158,18 @@ func (l *nodeCacheList) SetCache(id string, cache *NodeCache) {
 	}
 }
 
+// Memory returns the maximum memory in bytes used by the caches.
+func (l *nodeCacheList) Memory() int64 {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	var sum int64
+	for _, item := range l.idToItem {
+		sum += item.cache.Memory()
+	}
+	return sum
+}
+
 func NewNodeCacheList(sample, limit int, factory func(id string) *NodeCache) *nodeCacheList {
 	return &nodeCacheList{
 		sample:   sample,
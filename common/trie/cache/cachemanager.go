@@ -5,6 +5,7 @@ import (
 	"path"
 
 	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
 )
 
 const (
@@ -21,6 +22,7 @@ const (
 type cacheManager struct {
 	path  string
 	depth [2]int
+	fixed bool
 	world *NodeCache
 	store *nodeCacheList
 }
@@ -36,7 +38,9 @@ func (m *cacheManager) getAccountNodeCache(id []byte) *NodeCache {
 
 func (m *cacheManager) newAccountNodeCache(id []byte, mem, file int) *NodeCache {
 	path := path.Join(m.path, hex.EncodeToString(id))
-	return NewNodeCache(mem, file, path)
+	nc := NewNodeCache(mem, file, path)
+	nc.fixed = m.fixed
+	return nc
 }
 
 func (m *cacheManager) newNodeCache(id string) *NodeCache {
@@ -87,21 +91,62 @@ func EnableAccountNodeCacheByForce(database db.Database, id []byte) bool {
 	}
 }
 
+// MemoryUsageOf returns the maximum memory in bytes used by the node caches
+// of the database.
+func MemoryUsageOf(database db.Database) int64 {
+	if cm := cacheManagerOf(database); cm != nil {
+		return cm.world.Memory() + cm.store.Memory()
+	}
+	return 0
+}
+
+// MinMemoryFor returns the minimum memory limit for the node caches keeping
+// mem levels of tree items in the memory.
+func MinMemoryFor(mem int) int64 {
+	min := MemoryOfDepth(defaultAccountDepth)
+	if mem > 0 {
+		min += MemoryOfDepth(mem)
+	}
+	return min
+}
+
 // AttachManager attach cache manager to the database, and return it.
 // dir is root directory for storing files for cache.
 // mem is number of levels of tree items to store in the memory.
 // file is number of levels of tree items to store in files.
 // stores is number of stores to cache.
 func AttachManager(database db.Database, dir string, mem, file, stores int) db.Database {
+	database, _ = AttachManagerWithLimit(database, dir, mem, file, stores, 0)
+	return database
+}
+
+// AttachManagerWithLimit is same as AttachManager except that it limits
+// the memory in bytes used by the caches. The number of stores is reduced
+// to fit the limit, and caches don't grow beyond the levels. Zero limit
+// means no limit.
+func AttachManagerWithLimit(database db.Database, dir string, mem, file, stores int, limit int64) (db.Database, error) {
 	cm := &cacheManager{
 		path:  dir,
 		depth: [2]int{mem, file},
+		fixed: limit > 0,
 		world: NewNodeCache(defaultAccountDepth, 0, ""),
 	}
+	if limit > 0 {
+		if min := MinMemoryFor(mem); limit < min {
+			return nil, errors.IllegalArgumentError.Errorf(
+				"NodeCacheMemoryTooSmall(limit=%d,min=%d)", limit, min)
+		}
+	}
 	if mem+file > 0 {
 		if stores < 1 {
 			stores = defaultStoreCount
 		}
+		if limit > 0 && mem > 0 {
+			max := int((limit - cm.world.Memory()) / MemoryOfDepth(mem))
+			if stores > max {
+				stores = max
+			}
+		}
 		samples := stores * 100
 		cm.store = NewNodeCacheList(samples, stores, cm.newNodeCache)
 	} else {
@@ -109,5 +154,5 @@ func AttachManager(database db.Database, dir string, mem, file, stores int) db.D
 	}
 	return db.WithFlags(database, db.Flags{
 		nodeCacheManager: cm,
-	})
+	}), nil
 }
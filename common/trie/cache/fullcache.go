@@ -141,6 +141,13 @@ func (c *FullCache) tryMigrate(id []byte) bool {
 	return false
 }
 
+func (c *FullCache) Memory() int64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return int64(fullCacheBranchSize+c.size) * (nodeSlotSize + cacheItemSize)
+}
+
 func (c *FullCache) OnAttach(id []byte) cacheImpl {
 	if c.out > 0 {
 		c.tryMigrate(id)
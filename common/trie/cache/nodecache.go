@@ -9,6 +9,11 @@ const (
 	fullCacheMigrationThreshold = 256
 )
 
+const (
+	// nodeSlotSize is the size of a slot in the memory for a node.
+	nodeSlotSize = 48
+)
+
 func indexByNibs(nibs []byte) int {
 	idx := 0
 	for _, nib := range nibs {
@@ -21,15 +26,23 @@ func sizeByDepth(d int) int {
 	return ((1 << uint(4*d)) - 1) / 15
 }
 
+// MemoryOfDepth returns the maximum memory in bytes used by a node cache
+// keeping the nodes up to the depth in the memory.
+func MemoryOfDepth(d int) int64 {
+	return int64(sizeByDepth(d)) * (nodeSlotSize + cacheItemSize)
+}
+
 type cacheImpl interface {
 	Get(nibs []byte, h []byte) ([]byte, bool)
 	Put(nibs []byte, h []byte, serialized []byte)
 	OnAttach(id []byte) cacheImpl
+	Memory() int64
 }
 
 type NodeCache struct {
-	lock     sync.Mutex
-	impl     cacheImpl
+	lock  sync.Mutex
+	impl  cacheImpl
+	fixed bool
 }
 
 func (c *NodeCache) Get(nibs []byte, h []byte) ([]byte, bool) {
@@ -63,10 +76,27 @@ func (c *NodeCache) OnAttach(id []byte) *NodeCache {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
-	c.impl = c.impl.OnAttach(id)
+	// fixed cache doesn't migrate to the cache using more memory
+	if !c.fixed {
+		c.impl = c.impl.OnAttach(id)
+	}
 	return c
 }
 
+// Memory returns the maximum memory in bytes used by the cache.
+func (c *NodeCache) Memory() int64 {
+	if c == nil {
+		return 0
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.impl == nil {
+		return 0
+	}
+	return c.impl.Memory()
+}
+
 func NewNodeCache(depth int, fdepth int, path string) *NodeCache {
 	bc := NewBranchCache(depth, fdepth, path)
 	return &NodeCache{
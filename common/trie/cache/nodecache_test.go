@@ -6,6 +6,8 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
 )
 
 func Test_indexByNibs(t *testing.T) {
@@ -97,3 +99,24 @@ func Benchmark_NodeCache(b *testing.B) {
 		}
 	})
 }
+
+func TestAttachManagerWithLimit(t *testing.T) {
+	mdb := db.NewMapDB()
+	world := MemoryOfDepth(defaultAccountDepth)
+	store := MemoryOfDepth(5)
+
+	_, err := AttachManagerWithLimit(mdb, t.TempDir(), 5, 0, 0, world+store-1)
+	assert.True(t, errors.IllegalArgumentError.Equals(err))
+
+	cdb, err := AttachManagerWithLimit(mdb, t.TempDir(), 5, 0, 0, world+store*2)
+	assert.NoError(t, err)
+	assert.Equal(t, world, MemoryUsageOf(cdb))
+
+	for _, id := range []string{"a", "b", "c"} {
+		AccountNodeCacheOf(cdb, []byte(id))
+	}
+	assert.Equal(t, world+store*2, MemoryUsageOf(cdb))
+
+	cdb = AttachManager(mdb, t.TempDir(), 0, 0, 0)
+	assert.Equal(t, world, MemoryUsageOf(cdb))
+}
@@ -312,13 +312,22 @@ type iterator struct {
 	value  trie.Object
 	error  error
 	prefix string
+	start  string
 }
 
 func (i *iterator) Get() (trie.Object, []byte, error) {
 	return i.value, []byte(i.key), i.error
 }
 
+// beforeStart returns true if all keys under k are less than the start.
+func (i *iterator) beforeStart(k string) bool {
+	return k < i.start && !strings.HasPrefix(i.start, k)
+}
+
 func (i *iterator) appendItem(k string, n node) (node, error) {
+	if len(i.start) > 0 && i.beforeStart(k) {
+		return n, nil
+	}
 	realized, err := n.realize(i.m)
 	if err == nil {
 		i.stack = append(i.stack, iteratorItem{k: k, n: realized})
@@ -378,6 +387,9 @@ func (i *iterator) Next() error {
 			return nil
 		}
 		if i.value != nil {
+			if i.key < i.start {
+				continue
+			}
 			i.key = string(keysToBytes(i.key))
 			return nil
 		}
@@ -397,6 +409,16 @@ func (m *mpt) Iterator() trie.IteratorForObject {
 }
 
 func (m *mpt) Filter(prefix []byte) trie.IteratorForObject {
+	return m.newIterator(prefix, nil)
+}
+
+// Seek returns an iterator for the items whose keys are equal to or
+// greater than the start.
+func (m *mpt) Seek(start []byte) trie.IteratorForObject {
+	return m.newIterator(nil, start)
+}
+
+func (m *mpt) newIterator(prefix, start []byte) trie.IteratorForObject {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
@@ -417,6 +439,7 @@ func (m *mpt) Filter(prefix []byte) trie.IteratorForObject {
 		m:      m,
 		stack:  []iteratorItem{{k: "", n: root}},
 		prefix: string(bytesToNibs(prefix)),
+		start:  string(bytesToNibs(start)),
 	}
 	i.Next()
 	return i
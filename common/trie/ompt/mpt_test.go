@@ -514,3 +514,39 @@ func Test_mpt_Filter(t *testing.T) {
 		})
 	}
 }
+
+func Test_mpt_Seek(t *testing.T) {
+	data := []string{"a", "abc", "b", "bae", "bc", "bcdefg", "bcf", "c"}
+	tests := []struct {
+		name  string
+		start []byte
+		want  []string
+	}{
+		{"Nil", nil, data},
+		{"First", []byte("a"), data},
+		{"Exact", []byte("bc"), []string{"bc", "bcdefg", "bcf", "c"}},
+		{"Between", []byte("bb"), []string{"bc", "bcdefg", "bcf", "c"}},
+		{"InsideLeaf", []byte("bcd"), []string{"bcdefg", "bcf", "c"}},
+		{"BranchValue", []byte("ab"), []string{"abc", "b", "bae", "bc", "bcdefg", "bcf", "c"}},
+		{"Last", []byte("c"), []string{"c"}},
+		{"AfterAll", []byte("d"), nil},
+	}
+	dbase := db.NewMapDB()
+	m := NewMPTForBytes(dbase, nil)
+	for _, s := range data {
+		_, err := m.Set([]byte(s), []byte(s))
+		assert.NoError(t, err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var keys []string
+			for itr := m.Seek(tt.start); itr.Has(); itr.Next() {
+				key, value, err := itr.Get()
+				assert.NoError(t, err)
+				assert.True(t, bytes.Equal(key, value))
+				keys = append(keys, string(key))
+			}
+			assert.Equal(t, tt.want, keys)
+		})
+	}
+}
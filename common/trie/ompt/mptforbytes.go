@@ -88,6 +88,10 @@ func (m *mptForBytes) Filter(prefix []byte) trie.Iterator {
 	return &iteratorForBytes{i}
 }
 
+func (m *mptForBytes) Seek(start []byte) trie.Iterator {
+	return &iteratorForBytes{m.mpt.Seek(start)}
+}
+
 func (m *mptForBytes) Equal(object trie.Immutable, exact bool) bool {
 	if m2, ok := object.(*mptForBytes); ok {
 		return m.mpt.Equal(m2.mpt, exact)
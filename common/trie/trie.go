@@ -36,6 +36,12 @@ type (
 		Get() (value []byte, key []byte, err error)
 	}
 
+	// Seeker is implemented by the tries which can start iteration from
+	// the given key.
+	Seeker interface {
+		Seek(start []byte) Iterator
+	}
+
 	Mutable interface {
 		Get(k []byte) ([]byte, error)
 		Set(k, v []byte) ([]byte, error)
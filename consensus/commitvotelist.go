@@ -315,6 +315,29 @@ func NewEmptyCommitVoteList() module.CommitVoteSet {
 	return cvl
 }
 
+// NewCommitVoteSetForBlock returns the commit vote set for the block signed
+// by the wallet. It's for the block not committed by consensus (e.g. the
+// last block of a forked chain), so NTS votes are not included.
+func NewCommitVoteSetForBlock(w module.Wallet, blk module.BlockData, ts int64) (module.CommitVoteSet, error) {
+	psb := NewPartSetBuffer(ConfigBlockPartSize)
+	if err := blk.MarshalHeader(psb); err != nil {
+		return nil, err
+	}
+	if err := blk.MarshalBody(psb); err != nil {
+		return nil, err
+	}
+	vm := newVoteMessage()
+	vm.Height = blk.Height()
+	vm.Type = VoteTypePrecommit
+	vm.BlockID = blk.ID()
+	vm.BlockPartSetIDAndNTSVoteCount = psb.PartSet().ID().WithAppData(0)
+	vm.Timestamp = ts
+	if err := vm.Sign(w); err != nil {
+		return nil, err
+	}
+	return newCommitVoteList(nil, []*VoteMessage{vm})
+}
+
 // NewCommitVoteSetFromBytes returns VoteList from serialized bytes
 func NewCommitVoteSetFromBytes(bs []byte) module.CommitVoteSet {
 	vl := &CommitVoteList{}
@@ -28,6 +28,10 @@ type client struct {
 
 	fetchID uint16
 	fr      *fetchRequest
+
+	// firstHeights keeps the lowest height of available blocks reported
+	// by the peers. Unknown peers are assumed to have all blocks.
+	firstHeights map[string]int64
 }
 
 type blockResult struct {
@@ -61,6 +65,9 @@ func (br *blockResult) Consume() {
 	copy(fr.pendingResults, fr.pendingResults[1:])
 	fr.pendingResults[len(fr.pendingResults)-1] = nil
 	fr._reschedule()
+	if cl.fr != fr {
+		return
+	}
 	if fr.consumeOffset > fr.heightSet.end || len(fr.validPeers) == 0 && fr.pendingResults[0] == nil {
 		cb := fr.cb
 		cl.log.Tracef("OnEnd Consume %d validPeers:%d pendingResult[0]:%p\n", br.blk.Height(), len(fr.validPeers), fr.pendingResults[0])
@@ -141,9 +148,22 @@ func newClient(nm module.NetworkManager, ph module.ProtocolHandler,
 	cl.ph = ph
 	cl.bm = bm
 	cl.log = logger
+	cl.firstHeights = make(map[string]int64)
 	return cl
 }
 
+func (cl *client) firstHeightOf(id module.PeerID) int64 {
+	return cl.firstHeights[string(id.Bytes())]
+}
+
+func (cl *client) setFirstHeight(id module.PeerID, height int64) {
+	if height > 0 {
+		cl.firstHeights[string(id.Bytes())] = height
+	} else {
+		delete(cl.firstHeights, string(id.Bytes()))
+	}
+}
+
 func (cl *client) fetchBlocks(
 	begin int64,
 	end int64,
@@ -217,6 +237,7 @@ func (cl *client) onLeave(id module.PeerID) {
 	cl.Lock()
 	defer cl.Unlock()
 
+	delete(cl.firstHeights, string(id.Bytes()))
 	fr := cl.fr
 	if fr == nil {
 		return
@@ -252,13 +273,19 @@ func (fr *fetchRequest) _reschedule() {
 		}
 		var peer *peer
 		for _, p := range fr.validPeers {
-			if p.f == nil {
+			if p.f == nil && fr.cl.firstHeightOf(p.id) <= l {
 				peer = p
 				break
 			}
 		}
 		if peer == nil {
-			panic("wrong validPeers state")
+			// wait for busy peers unless nobody has the block.
+			if !fr._hasPeerFor(l) && l == fr.consumeOffset {
+				cb := fr.cb
+				fr._cancel()
+				go cb.OnEnd(errors.NotFoundError.Errorf("NoPeerForHeight(height=%d)", l))
+			}
+			return
 		}
 		requestID := uint32(fr.cl.fetchID)<<16 | uint32(peer.requestID)
 		peer.f = fr.newFetcher(peer.id, l, requestID)
@@ -268,6 +295,15 @@ func (fr *fetchRequest) _reschedule() {
 	}
 }
 
+func (fr *fetchRequest) _hasPeerFor(height int64) bool {
+	for _, p := range fr.validPeers {
+		if fr.cl.firstHeightOf(p.id) <= height {
+			return true
+		}
+	}
+	return false
+}
+
 func (cl *client) _findPeerByFetcher(f *fetcher) (int, *peer) {
 	for i, p := range cl.fr.validPeers {
 		if p.f == f {
@@ -298,6 +334,13 @@ func (cl *client) onResult(f *fetcher, err error, blk module.BlockData, votes []
 			return
 		}
 		fr.nActivePeers--
+		if isNoBlock(err) && cl.firstHeightOf(f.id) > f.height {
+			// the peer pruned the block, but it may have higher ones.
+			p.f = nil
+			fr.heightSet.add(f.height)
+			fr._reschedule()
+			return
+		}
 		last := len(fr.validPeers) - 1
 		fr.validPeers[i] = fr.validPeers[last]
 		fr.validPeers[last] = nil
@@ -341,6 +384,9 @@ func (cl *client) onResult(f *fetcher, err error, blk module.BlockData, votes []
 	p.f = nil
 
 	fr._reschedule()
+	if cl.fr != fr {
+		return
+	}
 	if offset == 0 {
 		cl.notifyBlockResult()
 	}
@@ -496,6 +542,7 @@ func (f *fetcher) onReceive(pi module.ProtocolInfo, b []byte) {
 			return
 		}
 		f.cl.log.Tracef("onReceive BlockMetadata rid=%d, len=%d\n", msg.RequestID, msg.BlockLength)
+		f.cl.setFirstHeight(f.id, msg.FirstHeight)
 		if msg.BlockLength < 0 {
 			f.step = fstepFin
 			if f.timer != nil {
@@ -503,6 +550,7 @@ func (f *fetcher) onReceive(pi module.ProtocolInfo, b []byte) {
 				f.timer = nil
 			}
 			f.cl.onResult(f, errNoBlock, nil, nil)
+			return
 		}
 		f.left = msg.BlockLength
 		f.voteList = msg.Proof
@@ -95,7 +95,7 @@ func (s *clientTestSetUp) respondBlockRequest(
 	votes []byte,
 	id module.PeerID,
 ) {
-	s.send(ph, ProtoBlockMetadata, &BlockMetadata{rid, int32(len(blk)), votes}, id)
+	s.send(ph, ProtoBlockMetadata, &BlockMetadata{rid, int32(len(blk)), votes, 0}, id)
 	s.send(ph, ProtoBlockData, &BlockData{rid, blk}, id)
 }
 
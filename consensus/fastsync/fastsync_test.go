@@ -104,12 +104,14 @@ func (vs *tCommitVoteSet) VoteRound() int32 {
 
 type tBlockManager struct {
 	module.BlockManager
-	bmap map[int64]module.Block
+	bmap   map[int64]module.Block
+	pruned int64
 }
 
 func newTBlockManager() *tBlockManager {
 	bm := &tBlockManager{
-		bmap: make(map[int64]module.Block),
+		bmap:   make(map[int64]module.Block),
+		pruned: -1,
 	}
 	return bm
 }
@@ -118,6 +120,10 @@ func (bm *tBlockManager) SetBlock(height int64, blk module.Block) {
 	bm.bmap[height] = blk
 }
 
+func (bm *tBlockManager) PrunedHeight() int64 {
+	return bm.pruned
+}
+
 func (bm *tBlockManager) GetBlockByHeight(height int64) (module.Block, error) {
 	blk := bm.bmap[height]
 	if blk == nil {
@@ -65,6 +65,10 @@ type BlockMetadata struct {
 	RequestID   uint32
 	BlockLength int32 // -1 if fails
 	Proof       []byte
+
+	// FirstHeight is the lowest height of the blocks available in the
+	// server. Blocks below it are pruned. Old servers don't send it.
+	FirstHeight int64
 }
 
 type BlockData struct {
@@ -95,6 +95,51 @@ func TestBlockRequest_SendV2ReceiveV2(t *testing.T) {
 	)
 }
 
+type blockMetadataV1 struct {
+	RequestID   uint32
+	BlockLength int32
+	Proof       []byte
+}
+
+func TestBlockMetadata_SendV1ReceiveV2(t *testing.T) {
+	msgV1 := blockMetadataV1{
+		RequestID:   1,
+		BlockLength: 10,
+		Proof:       []byte{0x01},
+	}
+	bsV1 := codec.MustMarshalToBytes(&msgV1)
+	var msg BlockMetadata
+	codec.MustUnmarshalFromBytes(bsV1, &msg)
+	assert.Equal(t,
+		BlockMetadata{
+			RequestID:   1,
+			BlockLength: 10,
+			Proof:       []byte{0x01},
+			FirstHeight: 0,
+		},
+		msg,
+	)
+}
+
+func TestBlockMetadata_SendV2ReceiveV1(t *testing.T) {
+	msg := BlockMetadata{
+		RequestID:   1,
+		BlockLength: -1,
+		Proof:       nil,
+		FirstHeight: 100,
+	}
+	bs := codec.MustMarshalToBytes(&msg)
+	var msgV1 blockMetadataV1
+	codec.MustUnmarshalFromBytes(bs, &msgV1)
+	assert.Equal(t,
+		blockMetadataV1{
+			RequestID:   1,
+			BlockLength: -1,
+		},
+		msgV1,
+	)
+}
+
 func FuzzBlockRequest(f *testing.F) {
 	f.Fuzz(func(t *testing.T, data []byte) {
 		var msg BlockRequest
@@ -224,6 +224,17 @@ func (h *sconHandler) cancelAllRequests() {
 	h.nextItems = nil
 }
 
+func (h *sconHandler) setNoBlock(requestID uint32, firstHeight int64) {
+	h.nextMsgPI = ProtoBlockMetadata
+	h.nextMsg = codec.MustMarshalToBytes(&BlockMetadata{
+		RequestID:   requestID,
+		BlockLength: -1,
+		Proof:       nil,
+		FirstHeight: firstHeight,
+	})
+	h.buf = nil
+}
+
 func (h *sconHandler) updateCurrentTask() {
 	if len(h.nextItems) == 0 {
 		return
@@ -232,36 +243,41 @@ func (h *sconHandler) updateCurrentTask() {
 	copy(h.nextItems, h.nextItems[1:])
 	h.nextItems = h.nextItems[:len(h.nextItems)-1]
 	h.requestID = ni.RequestID
+
+	// bodies of pruned blocks are not available.
+	firstHeight := h.bm.PrunedHeight() + 1
+	if ni.Height < firstHeight {
+		h.setNoBlock(ni.RequestID, firstHeight)
+		return
+	}
 	blk, err := h.bm.GetBlockByHeight(ni.Height)
 	if err != nil {
-		h.nextMsgPI = ProtoBlockMetadata
-		h.nextMsg = codec.MustMarshalToBytes(&BlockMetadata{
-			RequestID:   ni.RequestID,
-			BlockLength: -1,
-			Proof:       nil,
-		})
-		h.buf = nil
+		h.setNoBlock(ni.RequestID, firstHeight)
 		return
 	}
 	proof, err := h.bpp.GetBlockProof(ni.Height, ni.ProofOption)
 	if err != nil {
-		h.nextMsgPI = ProtoBlockMetadata
-		h.nextMsg = codec.MustMarshalToBytes(&BlockMetadata{
-			RequestID:   ni.RequestID,
-			BlockLength: -1,
-			Proof:       nil,
-		})
-		h.buf = nil
+		h.setNoBlock(ni.RequestID, firstHeight)
+		return
+	}
+	buf := bytes.NewBuffer(nil)
+	if err := blk.MarshalHeader(buf); err != nil {
+		h.log.Warnf("Fail to marshal header height=%d err=%+v", ni.Height, err)
+		h.setNoBlock(ni.RequestID, firstHeight)
+		return
+	}
+	if err := blk.MarshalBody(buf); err != nil {
+		h.log.Warnf("Fail to marshal body height=%d err=%+v", ni.Height, err)
+		h.setNoBlock(ni.RequestID, firstHeight)
 		return
 	}
-	h.buf = bytes.NewBuffer(nil)
-	h.log.Must(blk.MarshalHeader(h.buf))
-	h.log.Must(blk.MarshalBody(h.buf))
+	h.buf = buf
 	h.nextMsgPI = ProtoBlockMetadata
 	h.nextMsg = codec.MustMarshalToBytes(&BlockMetadata{
 		RequestID:   ni.RequestID,
 		BlockLength: int32(h.buf.Len()),
 		Proof:       proof,
+		FirstHeight: firstHeight,
 	})
 }
 
@@ -67,7 +67,7 @@ func TestServer_Success(t *testing.T) {
 	s := newServerTestSetUp(t)
 	s.sendBlockRequest(s.ph2, 0, 0)
 	ev := <-s.r2.ch
-	md := &BlockMetadata{0, int32(len(s.rawBlocks[0])), s.votes[1]}
+	md := &BlockMetadata{0, int32(len(s.rawBlocks[0])), s.votes[1], 0}
 	s.assertEqualReceiveEvent(ProtoBlockMetadata, md, s.nm.ID, ev)
 	recv := 0
 	data := make([]byte, md.BlockLength)
@@ -83,6 +83,15 @@ func TestServer_Success(t *testing.T) {
 	assert.Equal(t, data, s.rawBlocks[0])
 }
 
+func TestServer_Pruned(t *testing.T) {
+	s := newServerTestSetUp(t)
+	s.bm.pruned = 1
+	s.sendBlockRequest(s.ph2, 0, 1)
+	ev := <-s.r2.ch
+	md := &BlockMetadata{0, -1, nil, 2}
+	s.assertEqualReceiveEvent(ProtoBlockMetadata, md, s.nm.ID, ev)
+}
+
 func TestServer_Fail(t *testing.T) {
 }
 
@@ -0,0 +1,179 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcstore
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/icon-project/goloop/common/errors"
+)
+
+const (
+	DirDBPrefix = "dir://"
+
+	dirBlocks       = "blocks"
+	dirResults      = "results"
+	dirReps         = "reps"
+	fileUnconfirmed = "unconfirmed.json"
+)
+
+// DirDB reads exported JSON files in a directory.
+//
+//	blocks/<height>.json    block of the height
+//	results/<tx hash>.json  transaction information including the receipt
+//	reps/<reps hash>.json   list of representatives
+//	unconfirmed.json        unconfirmed block after the last block
+//
+// Transaction information has the same format as LevelDB of the legacy
+// node. Hashes are in hex without prefix.
+type DirDB struct {
+	dir string
+
+	lock       sync.Mutex
+	lastHeight int
+}
+
+func (ds *DirDB) read(elem ...string) ([]byte, error) {
+	bs, err := os.ReadFile(path.Join(append([]string{ds.dir}, elem...)...))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.ErrNotFound
+		}
+		return nil, err
+	}
+	return bs, nil
+}
+
+func (ds *DirDB) GetBlockJSONByHeight(height int, unconfirmed bool) ([]byte, error) {
+	bs, err := ds.read(dirBlocks, strconv.Itoa(height)+".json")
+	if err == errors.ErrNotFound && unconfirmed {
+		return ds.read(fileUnconfirmed)
+	}
+	return bs, err
+}
+
+// GetBlockJSONByID returns ErrNotFound as blocks are indexed by height only.
+func (ds *DirDB) GetBlockJSONByID(id []byte) ([]byte, error) {
+	return nil, errors.ErrNotFound
+}
+
+func (ds *DirDB) hasBlock(height int) bool {
+	_, err := os.Stat(path.Join(ds.dir, dirBlocks, strconv.Itoa(height)+".json"))
+	return err == nil
+}
+
+// getLastHeight returns the height of the last block. Blocks may be
+// exported while they are imported, so the following blocks are checked
+// after the directory is scanned once.
+func (ds *DirDB) getLastHeight() (int, error) {
+	ds.lock.Lock()
+	defer ds.lock.Unlock()
+
+	if ds.lastHeight < 0 {
+		entries, err := os.ReadDir(path.Join(ds.dir, dirBlocks))
+		if err != nil {
+			return -1, err
+		}
+		for _, e := range entries {
+			name := e.Name()
+			if !strings.HasSuffix(name, ".json") {
+				continue
+			}
+			h, err := strconv.Atoi(strings.TrimSuffix(name, ".json"))
+			if err != nil {
+				continue
+			}
+			if h > ds.lastHeight {
+				ds.lastHeight = h
+			}
+		}
+	}
+	for ds.lastHeight >= 0 && ds.hasBlock(ds.lastHeight+1) {
+		ds.lastHeight += 1
+	}
+	if ds.lastHeight < 0 {
+		return -1, errors.ErrNotFound
+	}
+	return ds.lastHeight, nil
+}
+
+func (ds *DirDB) GetLastBlockJSON() ([]byte, error) {
+	height, err := ds.getLastHeight()
+	if err != nil {
+		return nil, err
+	}
+	return ds.GetBlockJSONByHeight(height, false)
+}
+
+func (ds *DirDB) GetResultJSON(id []byte) ([]byte, error) {
+	return ds.read(dirResults, hex.EncodeToString(id)+".json")
+}
+
+func (ds *DirDB) getTransactionInfo(id []byte) (*rawTransactionInfo, error) {
+	bs, err := ds.GetResultJSON(id)
+	if err != nil {
+		return nil, err
+	}
+	tinfo := new(rawTransactionInfo)
+	if err := json.Unmarshal(bs, tinfo); err != nil {
+		return nil, err
+	}
+	return tinfo, nil
+}
+
+func (ds *DirDB) GetTransactionJSON(id []byte) ([]byte, error) {
+	tinfo, err := ds.getTransactionInfo(id)
+	if err != nil {
+		return nil, err
+	}
+	return tinfo.Transaction, nil
+}
+
+func (ds *DirDB) GetReceiptJSON(id []byte) ([]byte, error) {
+	tinfo, err := ds.getTransactionInfo(id)
+	if err != nil {
+		return nil, err
+	}
+	return tinfo.Result, nil
+}
+
+func (ds *DirDB) GetRepsJSONByHash(id []byte) ([]byte, error) {
+	return ds.read(dirReps, hex.EncodeToString(id)+".json")
+}
+
+func (ds *DirDB) GetTPS() float32 {
+	return 0
+}
+
+func (ds *DirDB) Close() error {
+	return nil
+}
+
+func OpenDirDB(dir string) (Database, error) {
+	if fi, err := os.Stat(path.Join(dir, dirBlocks)); err != nil {
+		return nil, err
+	} else if !fi.IsDir() {
+		return nil, errors.IllegalArgumentError.Errorf("NotDirectory(path=%s)", fi.Name())
+	}
+	return &DirDB{dir: dir, lastHeight: -1}, nil
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcstore
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common/errors"
+)
+
+func TestDirDB(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := OpenDirDB(dir)
+	assert.Error(t, err)
+
+	write := func(name string, value string) {
+		fp := path.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(path.Dir(fp), 0700))
+		assert.NoError(t, os.WriteFile(fp, []byte(value), 0600))
+	}
+	write("blocks/0.json", `{"height":0}`)
+	write("blocks/1.json", `{"height":1}`)
+	write("blocks/README", `ignored`)
+	write("unconfirmed.json", `{"height":2}`)
+	write("results/0102.json", `{"block_height":1,"transaction":{"tx_hash":"0102"},"result":{"status":"0x1"}}`)
+	write("reps/0304.json", `[]`)
+
+	store, err := OpenStore(DirDBPrefix+dir, 0)
+	assert.NoError(t, err)
+	ds := store.Database
+
+	bs, err := ds.GetBlockJSONByHeight(1, false)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"height":1}`, string(bs))
+
+	_, err = ds.GetBlockJSONByHeight(2, false)
+	assert.Equal(t, errors.ErrNotFound, err)
+	bs, err = ds.GetBlockJSONByHeight(2, true)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"height":2}`, string(bs))
+
+	bs, err = ds.GetLastBlockJSON()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"height":1}`, string(bs))
+
+	// blocks exported after the scan are found
+	write("blocks/2.json", `{"height":2}`)
+	bs, err = ds.GetLastBlockJSON()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"height":2}`, string(bs))
+
+	bs, err = ds.GetTransactionJSON([]byte{1, 2})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"tx_hash":"0102"}`, string(bs))
+	bs, err = ds.GetReceiptJSON([]byte{1, 2})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"status":"0x1"}`, string(bs))
+	_, err = ds.GetReceiptJSON([]byte{1, 3})
+	assert.Equal(t, errors.ErrNotFound, err)
+
+	bs, err = ds.GetRepsJSONByHash([]byte{3, 4})
+	assert.NoError(t, err)
+	assert.Equal(t, `[]`, string(bs))
+}
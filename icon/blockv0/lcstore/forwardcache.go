@@ -80,6 +80,10 @@ type ForwardCache struct {
 	tasks       list.List
 	blockInfo   map[int64]*blockTask
 	receiptInfo map[string]*receiptTask
+
+	// highest height requested. Blocks up to it are not fetched in
+	// advance, because blocks may be requested by multiple workers.
+	requested int64
 }
 
 func (cs *ForwardCache) workLoop() {
@@ -110,6 +114,9 @@ func (cs *ForwardCache) getBlockTask(height int64) *blockTask {
 	cs.lock.Lock()
 	defer cs.lock.Unlock()
 
+	if height > cs.requested {
+		cs.requested = height
+	}
 	if t, ok := cs.blockInfo[height]; ok {
 		delete(cs.blockInfo, height)
 		return t
@@ -158,8 +165,24 @@ func (cs *ForwardCache) scheduleFollowings(b blockv0.Block) {
 	for _, tx := range txs {
 		cs.scheduleReceiptInLock(tx.ID())
 	}
-	for h := b.Height() + 1; len(cs.blockInfo) < cs.config.MaxBlocks; h += 1 {
-		cs.scheduleBlockInLock(int64(h))
+	h := b.Height() + 1
+	if h <= cs.requested {
+		h = cs.requested + 1
+	}
+	for ; len(cs.blockInfo) < cs.config.MaxBlocks; h += 1 {
+		cs.scheduleBlockInLock(h)
+	}
+}
+
+// Prefetch starts fetching blocks from the height in advance. It's used
+// to warm up the cache before blocks are requested from the height.
+func (cs *ForwardCache) Prefetch(height int64) {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+
+	cs.requested = height - 1
+	for h := height; len(cs.blockInfo) < cs.config.MaxBlocks; h += 1 {
+		cs.scheduleBlockInLock(h)
 	}
 }
 
@@ -291,6 +314,7 @@ func NewForwardCache(store *Store, logger log.Logger, config *CacheConfig) *Forw
 		config:      *config,
 		blockInfo:   make(map[int64]*blockTask),
 		receiptInfo: make(map[string]*receiptTask),
+		requested:   -1,
 	}
 	cs.tasks.Init()
 	return cs
@@ -170,6 +170,12 @@ func OpenStore(blockuri string, rps int) (*Store, error) {
 			} else {
 				dbs = append(dbs, bs)
 			}
+		} else if strings.HasPrefix(uri, DirDBPrefix) {
+			if bs, err := OpenDirDB(strings.TrimPrefix(uri, DirDBPrefix)); err != nil {
+				return nil, err
+			} else {
+				dbs = append(dbs, bs)
+			}
 		} else {
 			if bs, err := OpenLevelDB(uri); err != nil {
 				return nil, err
@@ -360,6 +360,29 @@ var chainMethods = []*chainMethod{
 			scoreapi.Dict,
 		},
 	}, icmodule.RevisionIISS, 0},
+	{scoreapi.Method{
+		scoreapi.Function, "estimateIScore",
+		scoreapi.FlagReadOnly | scoreapi.FlagExternal, 1,
+		[]scoreapi.Parameter{
+			{"address", scoreapi.Address, nil, nil},
+			{"delegations", scoreapi.ListTypeOf(1, scoreapi.Struct), nil,
+				[]scoreapi.Field{
+					{"address", scoreapi.Address, nil},
+					{"value", scoreapi.Integer, nil},
+				},
+			},
+			{"bonds", scoreapi.ListTypeOf(1, scoreapi.Struct), nil,
+				[]scoreapi.Field{
+					{"address", scoreapi.Address, nil},
+					{"value", scoreapi.Integer, nil},
+				},
+			},
+			{"stake", scoreapi.Integer, nil, nil},
+		},
+		[]scoreapi.DataType{
+			scoreapi.Dict,
+		},
+	}, icmodule.RevisionEstimateIScore, 0},
 	{scoreapi.Method{
 		scoreapi.Function, "registerPRep",
 		scoreapi.FlagExternal | scoreapi.FlagPayable, 7,
@@ -368,6 +368,10 @@ func (s *chainScore) Ex_setBond(bondList []interface{}) error {
 	if err = es.SetBond(s.cc.BlockHeight(), s.from, bonds); err != nil {
 		return err
 	}
+	cc := s.newCallContext(s.cc)
+	if err = iiss.BondSetEventLog(cc, s.from, es.State.GetAccountState(s.from)); err != nil {
+		return err
+	}
 	logger.Tracef("Ex_setBond() end")
 	return nil
 }
@@ -463,6 +467,36 @@ func (s *chainScore) Ex_queryIScore(address module.Address) (map[string]interfac
 	return jso, nil
 }
 
+func (s *chainScore) Ex_estimateIScore(
+	address module.Address, delegations []interface{}, bonds []interface{}, stake *common.HexInt,
+) (map[string]interface{}, error) {
+	if err := s.tryChargeCall(true); err != nil {
+		return nil, err
+	}
+	es, err := s.getExtensionState()
+	if err != nil {
+		return nil, err
+	}
+	var ds icstate.Delegations
+	if delegations != nil {
+		if ds, err = icstate.NewDelegations(delegations, es.State.GetDelegationSlotMax()); err != nil {
+			return nil, err
+		}
+	}
+	var bs icstate.Bonds
+	if bonds != nil {
+		if bs, err = icstate.NewBonds(bonds, s.cc.Revision().Value()); err != nil {
+			return nil, scoreresult.InvalidParameterError.Wrap(err, "InvalidBonds")
+		}
+	}
+	var amount *big.Int
+	if stake != nil {
+		amount = stake.Value()
+	}
+	cc := s.newCallContext(s.cc)
+	return es.EstimateIScore(cc, address, amount, ds, bs)
+}
+
 func (s *chainScore) Ex_estimateUnstakeLockPeriod() (map[string]interface{}, error) {
 	if err := s.tryChargeCall(true); err != nil {
 		return nil, err
@@ -18,6 +18,7 @@ package icon
 
 import (
 	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/icon/iiss"
 	"github.com/icon-project/goloop/icon/iiss/icutils"
 	"github.com/icon-project/goloop/service/state"
 
@@ -35,7 +36,8 @@ type contractManager struct {
 	contract.ContractManager
 	log log.Logger
 
-	eeTypes state.EETypes
+	eeTypes    state.EETypes
+	validation *iiss.ValidationHistory
 }
 
 func (cm *contractManager) GetSystemScore(contentID string, cc contract.CallContext, from module.Address, value *big.Int) (contract.SystemScore, error) {
@@ -100,6 +102,10 @@ func (cm *contractManager) GetCallHandler(from, to module.Address, value *big.In
 	return ch, nil
 }
 
+func (cm *contractManager) ValidationHistory() *iiss.ValidationHistory {
+	return cm.validation
+}
+
 const (
 	EETypesJavaAndPython = string(state.JavaEE + "," + state.PythonEE)
 	EETypesPythonOnly    = string(state.PythonEE)
@@ -115,5 +121,5 @@ func newContractManager(plt *platform, dbase db.Database, dir string, logger log
 	if err != nil {
 		return nil, errors.Wrapf(err, "InvalidEETypes(s=%s)", EETypesPythonOnly)
 	}
-	return &contractManager{cm, logger, eeTypes}, nil
+	return &contractManager{cm, logger, eeTypes, plt.validation}, nil
 }
@@ -54,6 +54,13 @@ func (g *governanceHandler) TraceLogger() *trace.Logger {
 	return g.log
 }
 
+func (g *governanceHandler) TraceCall() *module.TraceCall {
+	if th, ok := g.ch.(contract.TraceableHandler); ok {
+		return th.TraceCall()
+	}
+	return new(module.TraceCall)
+}
+
 func applyGovernanceVariablesToSystem(cc contract.CallContext, govAs, sysAs containerdb.BytesStoreState) error {
 	price := scoredb.NewVarDB(govAs, state.VarStepPrice).Int64()
 	if price == 0 {
@@ -26,4 +26,12 @@ const (
 	// BlockMerkle basically maps node hash to block merkle node for v1 block.
 	// In addition, it also has merkleTreeData.
 	BlockMerkle db.BucketID = "H"
+
+	// RewardHistory keeps reward history of accounts for each term.
+	// It's not a part of the state, so it's not synchronized.
+	RewardHistory db.BucketID = "R"
+
+	// ValidationHistory keeps recent block validation of validators.
+	// It's not a part of the state, so it's not synchronized.
+	ValidationHistory db.BucketID = "V"
 )
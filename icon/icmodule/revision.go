@@ -40,6 +40,9 @@ const (
 	Revision19
 	Revision20
 	Revision21
+	Revision22
+	Revision23
+	Revision24
 	RevisionReserved
 )
 
@@ -105,6 +108,12 @@ const (
 	// RevisionJavaFixMapValues = Revision20
 
 	RevisionBTP2 = Revision21
+
+	RevisionIISSStateEvents = Revision22
+
+	RevisionFeeMarket = Revision23
+
+	RevisionEstimateIScore = Revision24
 )
 
 var revisionFlags = []module.Revision{
@@ -152,6 +161,12 @@ var revisionFlags = []module.Revision{
 	module.FixMapValues,
 	// Revision21
 	module.MultipleFeePayers,
+	// Revision22
+	0,
+	// Revision23
+	module.FeeMarket,
+	// Revision24
+	0,
 }
 
 func init() {
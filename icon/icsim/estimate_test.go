@@ -0,0 +1,233 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package icsim
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/icon/icmodule"
+	"github.com/icon-project/goloop/icon/iiss/icstate"
+	"github.com/icon-project/goloop/icon/iiss/icutils"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/state"
+)
+
+// goToNextTerm goes to the first block of the next term,
+// where the result of the previous calculation is applied.
+func goToNextTerm(t *testing.T, sim Simulator) {
+	assert.NoError(t, sim.GoToTermEnd(nil))
+	assert.NoError(t, sim.Go(1, nil))
+}
+
+// queryIScores returns I-Scores of addrs
+func queryIScores(sim Simulator, addrs []module.Address) []*big.Int {
+	iscores := make([]*big.Int, len(addrs))
+	for i, addr := range addrs {
+		iscores[i] = sim.QueryIScore(addr)
+	}
+	return iscores
+}
+
+// earnedIScores returns I-Scores which addrs earned during the current term.
+// It should be called at the first block of the term.
+// Reward of a term is calculated during the next term, and it's applied
+// at the start of the term after.
+func earnedIScores(t *testing.T, sim Simulator, addrs []module.Address) []*big.Int {
+	goToNextTerm(t, sim)
+	is0 := queryIScores(sim, addrs)
+	goToNextTerm(t, sim)
+	is1 := queryIScores(sim, addrs)
+	for i := range addrs {
+		is1[i].Sub(is1[i], is0[i])
+	}
+	return is1
+}
+
+func estimatedIScores(sim Simulator, addrs []module.Address) []*big.Int {
+	iscores := make([]*big.Int, len(addrs))
+	for i, addr := range addrs {
+		jso := sim.EstimateIScore(addr, nil, nil)
+		iscores[i] = jso["iscore"].(*big.Int)
+	}
+	return iscores
+}
+
+// newRewardTestSimulator returns a simulator whose P-Reps are registered
+// and voted after the first term, so that rewards are calculated
+// with all the voting history.
+func newRewardTestSimulator(t *testing.T) (Simulator, []module.Address, []module.Address, []module.Address) {
+	c := NewConfig()
+	c.MainPRepCount = 4
+	c.SubPRepCount = 4
+	c.TermPeriod = 100
+	prepLen := int(c.MainPRepCount + c.SubPRepCount)
+
+	preps := newDummyAddresses(1000, prepLen)
+	users := newDummyAddresses(2000, prepLen)
+	bonders := newDummyAddresses(3000, prepLen)
+
+	validators := make([]module.Validator, c.MainPRepCount)
+	for i := range validators {
+		validators[i], _ = state.ValidatorFromAddress(newDummyAddress(4000 + i))
+	}
+	balances := make(map[string]*big.Int)
+	for _, prep := range preps {
+		balances[icutils.ToKey(prep)] = icutils.ToLoop(2000)
+	}
+	for _, user := range users {
+		balances[icutils.ToKey(user)] = icutils.ToLoop(10000)
+	}
+	for _, bonder := range bonders {
+		balances[icutils.ToKey(bonder)] = icutils.ToLoop(2000)
+	}
+
+	sim := NewSimulator(icmodule.ValueToRevision(icmodule.RevisionICON2R1), validators, balances, c)
+	assert.NotNil(t, sim)
+	assert.NoError(t, sim.Go(1, nil))
+
+	var receipts []Receipt
+	var err error
+	block := NewBlock()
+	for i, prep := range preps {
+		block.AddTransaction(sim.RegisterPRep(prep, newDummyPRepInfo(i)))
+	}
+	for _, user := range users {
+		block.AddTransaction(sim.SetStake(user, sim.GetBalance(user)))
+	}
+	for i, bonder := range bonders {
+		block.AddTransaction(sim.SetStake(bonder, sim.GetBalance(bonder)))
+		block.AddTransaction(sim.SetBonderList(preps[i], icstate.BonderList{common.AddressToPtr(bonder)}))
+	}
+	receipts, err = sim.GoByBlock(block, nil)
+	assert.NoError(t, err)
+	assert.True(t, checkReceipts(receipts))
+
+	// Vote in the second term whose reward variables are for IISS 3.x
+	goToNextTerm(t, sim)
+	block = NewBlock()
+	for i, user := range users {
+		ds := icstate.Delegations{
+			icstate.NewDelegation(common.AddressToPtr(preps[i]), icutils.ToLoop(10000-i*100)),
+		}
+		block.AddTransaction(sim.SetDelegation(user, ds))
+	}
+	for i, bonder := range bonders {
+		bonds := icstate.Bonds{icstate.NewBond(common.AddressToPtr(preps[i]), icutils.ToLoop(1000))}
+		block.AddTransaction(sim.SetBond(bonder, bonds))
+	}
+	receipts, err = sim.GoByBlock(block, nil)
+	assert.NoError(t, err)
+	assert.True(t, checkReceipts(receipts))
+
+	// Decentralization is activated
+	goToNextTerm(t, sim)
+	assert.Equal(t, int(c.MainPRepCount), len(sim.GetMainPReps()["preps"].([]interface{})))
+	return sim, preps, users, bonders
+}
+
+func TestSimulator_EstimateIScore(t *testing.T) {
+	sim, preps, users, bonders := newRewardTestSimulator(t)
+	addrs := []module.Address{users[0], users[1], bonders[0], preps[0], preps[1]}
+
+	// Estimation with current votes
+	estimated := estimatedIScores(sim, addrs)
+	earned := earnedIScores(t, sim, addrs)
+	for i := range addrs {
+		assert.True(t, earned[i].Sign() > 0)
+		assert.Zero(t, earned[i].Cmp(estimated[i]), "addr=%s earned=%s estimated=%s",
+			addrs[i], earned[i], estimated[i])
+	}
+
+	// Estimation with hypothetical delegations
+	amount := icutils.ToLoop(5000)
+	ds := icstate.Delegations{
+		icstate.NewDelegation(common.AddressToPtr(preps[1]), amount),
+		icstate.NewDelegation(common.AddressToPtr(preps[2]), amount),
+	}
+	jso := sim.EstimateIScore(users[0], ds, nil)
+	hypothetical := jso["iscore"].(*big.Int)
+	delegations := jso["delegations"].([]interface{})
+	assert.Equal(t, 2, len(delegations))
+	assert.Zero(t, jso["bonding"].(*big.Int).Sign())
+
+	// Too many votes for the stake
+	ds2 := icstate.Delegations{
+		icstate.NewDelegation(common.AddressToPtr(preps[1]), icutils.ToLoop(10001)),
+	}
+	assert.Nil(t, sim.EstimateIScore(users[0], ds2, nil))
+
+	// Apply the hypothetical delegations and check the reward of the next term
+	receipts, err := sim.GoByTransaction(sim.SetDelegation(users[0], ds), nil)
+	assert.NoError(t, err)
+	assert.True(t, checkReceipts(receipts))
+	goToNextTerm(t, sim)
+
+	estimated = estimatedIScores(sim, addrs)
+	assert.Zero(t, hypothetical.Cmp(estimated[0]))
+	earned = earnedIScores(t, sim, addrs)
+	for i := range addrs {
+		assert.Zero(t, earned[i].Cmp(estimated[i]), "addr=%s earned=%s estimated=%s",
+			addrs[i], earned[i], estimated[i])
+	}
+}
+
+func TestSimulator_EstimateIScoreWithBonds(t *testing.T) {
+	sim, preps, _, bonders := newRewardTestSimulator(t)
+	bonder := bonders[0]
+
+	// Moving bond from a P-Rep to another changes voted rewards of both
+	amount := icutils.ToLoop(500)
+	bonds := icstate.Bonds{
+		icstate.NewBond(common.AddressToPtr(preps[0]), amount),
+		icstate.NewBond(common.AddressToPtr(preps[1]), amount),
+	}
+	jso := sim.EstimateIScore(bonder, nil, bonds)
+	assert.Equal(t, 2, len(jso["bonds"].([]interface{})))
+	assert.Zero(t, jso["delegating"].(*big.Int).Sign())
+	hypothetical := jso["iscore"].(*big.Int)
+
+	// Decreased bond is locked as unbond, so it still uses the stake
+	bonds2 := icstate.Bonds{icstate.NewBond(common.AddressToPtr(preps[1]), icutils.ToLoop(1500))}
+	assert.Nil(t, sim.EstimateIScore(bonder, nil, bonds2))
+
+	before := estimatedIScores(sim, []module.Address{preps[0], preps[1]})
+
+	block := NewBlock()
+	block.AddTransaction(sim.SetBonderList(
+		preps[1], icstate.BonderList{common.AddressToPtr(bonders[1]), common.AddressToPtr(bonder)}))
+	block.AddTransaction(sim.SetBond(bonder, bonds))
+	receipts, err := sim.GoByBlock(block, nil)
+	assert.NoError(t, err)
+	assert.True(t, checkReceipts(receipts))
+	goToNextTerm(t, sim)
+
+	addrs := []module.Address{bonder, preps[0], preps[1]}
+	estimated := estimatedIScores(sim, addrs)
+	assert.Zero(t, hypothetical.Cmp(estimated[0]))
+	assert.True(t, estimated[1].Cmp(before[0]) < 0)
+	assert.True(t, estimated[2].Cmp(before[1]) > 0)
+
+	earned := earnedIScores(t, sim, addrs)
+	for i := range addrs {
+		assert.Zero(t, earned[i].Cmp(estimated[i]), "addr=%s earned=%s estimated=%s",
+			addrs[i], earned[i], estimated[i])
+	}
+}
@@ -17,6 +17,7 @@ import (
 
 type platform struct {
 	calculator iiss.CalculatorHolder
+	validation *iiss.ValidationHistory
 }
 
 func (p *platform) NewBaseTransaction(wc WorldContext) (module.Transaction, error) {
@@ -56,6 +57,7 @@ func (p *platform) NewBaseTransaction(wc WorldContext) (module.Transaction, erro
 func (p *platform) OnExtensionSnapshotFinalization(ess state.ExtensionSnapshot, logger log.Logger) {
 	// Start background calculator if it's not started.
 	p.calculator.Start(ess, logger)
+	p.validation.OnSnapshotFinalization(ess)
 }
 
 func (p *platform) OnExecutionBegin(wc WorldContext, logger log.Logger) error {
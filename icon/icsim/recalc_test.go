@@ -0,0 +1,59 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package icsim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/icon/iiss"
+	"github.com/icon-project/goloop/icon/iiss/icstate"
+)
+
+func getExtension(t *testing.T, sim Simulator) (*iiss.ExtensionSnapshotImpl, *icstate.RewardCalcInfo) {
+	ess := sim.(*simulatorImpl).wss.GetExtensionSnapshot().(*iiss.ExtensionSnapshotImpl)
+	rcInfo, err := ess.NewState(true).(*iiss.ExtensionStateImpl).State.GetRewardCalcInfo()
+	assert.NoError(t, err)
+	return ess, rcInfo
+}
+
+func TestSimulator_Recalculate(t *testing.T) {
+	sim, _, _, _ := newRewardTestSimulator(t)
+	ess, rcInfo := getExtension(t, sim)
+	startHeight := rcInfo.StartHeight()
+
+	goToNextTerm(t, sim)
+	applied, rcInfo := getExtension(t, sim)
+	assert.Equal(t, startHeight, rcInfo.PrevHeight())
+
+	database := sim.Database()
+	jso, err := iiss.Recalculate(database, ess.Back2(), ess.Reward(), rcInfo.PrevHash(), sim.(*simulatorImpl).logger)
+	assert.NoError(t, err)
+	assert.Equal(t, startHeight, jso["startHeight"])
+	assert.Equal(t, true, jso["match"])
+	assert.Equal(t, 0, len(jso["differences"].([]interface{})))
+
+	// compare with the base reward, which doesn't have rewards of the term
+	jso, err = iiss.Recalculate(database, ess.Back2(), ess.Reward(), ess.Reward().Bytes(), sim.(*simulatorImpl).logger)
+	assert.NoError(t, err)
+	assert.Equal(t, false, jso["match"])
+	assert.True(t, len(jso["differences"].([]interface{})) > 0)
+
+	// nothing is changed by recalculation
+	assert.Equal(t, applied.Bytes(), sim.(*simulatorImpl).wss.GetExtensionSnapshot().Bytes())
+}
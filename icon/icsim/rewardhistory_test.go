@@ -0,0 +1,64 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package icsim
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/icon/iiss"
+	"github.com/icon-project/goloop/module"
+)
+
+func TestSimulator_RewardHistory(t *testing.T) {
+	sim, preps, users, bonders := newRewardTestSimulator(t)
+	history, err := iiss.NewRewardHistory(db.NewMapDB(), 0, sim.(*simulatorImpl).logger)
+	assert.NoError(t, err)
+	sim.(*simulatorImpl).plt.calculator.SetRewardHistory(history)
+
+	addrs := []module.Address{users[0], bonders[0], preps[0]}
+	earned := earnedIScores(t, sim, addrs)
+	for i, addr := range addrs {
+		jso, err := history.GetRewardHistory(addr, 0, 1)
+		assert.NoError(t, err)
+		terms := jso["terms"].([]interface{})
+		assert.Equal(t, 1, len(terms))
+		term := terms[0].(map[string]interface{})
+		assert.Zero(t, earned[i].Cmp(term["iscore"].(*big.Int)), "addr=%s earned=%s history=%+v",
+			addr, earned[i], term)
+	}
+
+	jso, err := history.GetRewardHistory(users[0], 0, 1)
+	assert.NoError(t, err)
+	term := jso["terms"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, 1, len(term["delegations"].([]interface{})))
+	assert.Zero(t, term["voted"].(*big.Int).Sign())
+
+	jso, err = history.GetRewardHistory(bonders[0], 0, 1)
+	assert.NoError(t, err)
+	term = jso["terms"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, 1, len(term["bonds"].([]interface{})))
+	assert.True(t, term["bonding"].(*big.Int).Sign() > 0)
+
+	jso, err = history.GetRewardHistory(preps[0], 0, 1)
+	assert.NoError(t, err)
+	term = jso["terms"].([]interface{})[0].(map[string]interface{})
+	assert.True(t, term["voted"].(*big.Int).Sign() > 0)
+}
@@ -0,0 +1,696 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package icsim
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/icon/icmodule"
+	"github.com/icon-project/goloop/icon/iiss/icstate"
+	"github.com/icon-project/goloop/icon/iiss/icutils"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/state"
+)
+
+var loopPerICX = new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil))
+
+// ParseAmount parses an amount in a scenario. It's an integer in loop
+// (ex. "1000000000000000000", "0xde0b6b3a7640000") or a decimal in ICX
+// with "icx" suffix (ex. "1.5icx").
+func ParseAmount(s string) (*big.Int, error) {
+	s = strings.TrimSpace(s)
+	if v := strings.TrimSuffix(strings.ToLower(s), "icx"); len(v) != len(s) {
+		r, ok := new(big.Rat).SetString(strings.TrimSpace(v))
+		if !ok {
+			return nil, errors.IllegalArgumentError.Errorf("InvalidAmount(%s)", s)
+		}
+		r.Mul(r, loopPerICX)
+		if !r.IsInt() {
+			return nil, errors.IllegalArgumentError.Errorf("InvalidAmount(%s)", s)
+		}
+		return new(big.Int).Set(r.Num()), nil
+	}
+	value := new(big.Int)
+	if err := intconv.ParseBigInt(value, s); err != nil {
+		return nil, errors.IllegalArgumentError.Wrapf(err, "InvalidAmount(%s)", s)
+	}
+	return value, nil
+}
+
+// Amount is an amount of a scenario in JSON or YAML. See ParseAmount for
+// its format.
+type Amount struct {
+	value *big.Int
+}
+
+func (a *Amount) Value() *big.Int {
+	return a.value
+}
+
+func (a *Amount) set(s string) error {
+	value, err := ParseAmount(s)
+	if err != nil {
+		return err
+	}
+	a.value = value
+	return nil
+}
+
+func (a *Amount) UnmarshalJSON(bs []byte) error {
+	return a.set(string(bytes.Trim(bs, "\"")))
+}
+
+func (a *Amount) UnmarshalYAML(node *yaml.Node) error {
+	return a.set(node.Value)
+}
+
+// ScenarioConfig overrides the default configuration of the simulator.
+type ScenarioConfig struct {
+	TermPeriod                            *int64 `json:"termPeriod" yaml:"termPeriod"`
+	MainPRepCount                         *int64 `json:"mainPRepCount" yaml:"mainPRepCount"`
+	SubPRepCount                          *int64 `json:"subPRepCount" yaml:"subPRepCount"`
+	Irep                                  *int64 `json:"irep" yaml:"irep"`
+	Rrep                                  *int64 `json:"rrep" yaml:"rrep"`
+	BondRequirement                       *int64 `json:"bondRequirement" yaml:"bondRequirement"`
+	UnbondingPeriodMultiplier             *int64 `json:"unbondingPeriodMultiplier" yaml:"unbondingPeriodMultiplier"`
+	UnstakeSlotMax                        *int64 `json:"unstakeSlotMax" yaml:"unstakeSlotMax"`
+	LockMinMultiplier                     *int64 `json:"lockMinMultiplier" yaml:"lockMinMultiplier"`
+	LockMaxMultiplier                     *int64 `json:"lockMaxMultiplier" yaml:"lockMaxMultiplier"`
+	UnbondingMax                          *int64 `json:"unbondingMax" yaml:"unbondingMax"`
+	ValidationPenaltyCondition            *int   `json:"validationPenaltyCondition" yaml:"validationPenaltyCondition"`
+	ConsistentValidationPenaltyCondition  *int64 `json:"consistentValidationPenaltyCondition" yaml:"consistentValidationPenaltyCondition"`
+	ConsistentValidationPenaltyMask       *int64 `json:"consistentValidationPenaltyMask" yaml:"consistentValidationPenaltyMask"`
+	ConsistentValidationPenaltySlashRatio *int   `json:"consistentValidationPenaltySlashRatio" yaml:"consistentValidationPenaltySlashRatio"`
+	DelegationSlotMax                     *int64 `json:"delegationSlotMax" yaml:"delegationSlotMax"`
+	Iglobal                               *int64 `json:"iglobal" yaml:"iglobal"`
+	Iprep                                 *int64 `json:"iprep" yaml:"iprep"`
+	Icps                                  *int64 `json:"icps" yaml:"icps"`
+	Irelay                                *int64 `json:"irelay" yaml:"irelay"`
+	Ivoter                                *int64 `json:"ivoter" yaml:"ivoter"`
+}
+
+func overrideInt64(dst *int64, src *int64) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+func overrideInt(dst *int, src *int) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+func (sc *ScenarioConfig) newConfig() *config {
+	c := NewConfig()
+	overrideInt64(&c.TermPeriod, sc.TermPeriod)
+	overrideInt64(&c.MainPRepCount, sc.MainPRepCount)
+	overrideInt64(&c.SubPRepCount, sc.SubPRepCount)
+	overrideInt64(&c.Irep, sc.Irep)
+	overrideInt64(&c.Rrep, sc.Rrep)
+	overrideInt64(&c.BondRequirement, sc.BondRequirement)
+	overrideInt64(&c.UnbondingPeriodMultiplier, sc.UnbondingPeriodMultiplier)
+	overrideInt64(&c.UnstakeSlotMax, sc.UnstakeSlotMax)
+	overrideInt64(&c.LockMinMultiplier, sc.LockMinMultiplier)
+	overrideInt64(&c.LockMaxMultiplier, sc.LockMaxMultiplier)
+	overrideInt64(&c.UnbondingMax, sc.UnbondingMax)
+	overrideInt(&c.ValidationPenaltyCondition, sc.ValidationPenaltyCondition)
+	overrideInt64(&c.ConsistentValidationPenaltyCondition, sc.ConsistentValidationPenaltyCondition)
+	overrideInt64(&c.ConsistentValidationPenaltyMask, sc.ConsistentValidationPenaltyMask)
+	overrideInt(&c.ConsistentValidationPenaltySlashRatio, sc.ConsistentValidationPenaltySlashRatio)
+	overrideInt64(&c.DelegationSlotMax, sc.DelegationSlotMax)
+	overrideInt64(&c.RewardFund.Iglobal, sc.Iglobal)
+	overrideInt64(&c.RewardFund.Iprep, sc.Iprep)
+	overrideInt64(&c.RewardFund.Icps, sc.Icps)
+	overrideInt64(&c.RewardFund.Irelay, sc.Irelay)
+	overrideInt64(&c.RewardFund.Ivoter, sc.Ivoter)
+	return c
+}
+
+// ScenarioAccount is an account with initial balance. Address is optional,
+// and other parts of the scenario may refer the account with Name.
+type ScenarioAccount struct {
+	Name    string `json:"name" yaml:"name"`
+	Address string `json:"address" yaml:"address"`
+	Balance Amount `json:"balance" yaml:"balance"`
+}
+
+type ScenarioVote struct {
+	Address string `json:"address" yaml:"address"`
+	Amount  Amount `json:"amount" yaml:"amount"`
+}
+
+type ScenarioPRepInfo struct {
+	Name        *string `json:"name" yaml:"name"`
+	Country     *string `json:"country" yaml:"country"`
+	City        *string `json:"city" yaml:"city"`
+	Email       *string `json:"email" yaml:"email"`
+	Website     *string `json:"website" yaml:"website"`
+	Details     *string `json:"details" yaml:"details"`
+	P2PEndpoint *string `json:"p2pEndpoint" yaml:"p2pEndpoint"`
+}
+
+// ScenarioTx is a transaction of a scenario. Type is one of setStake,
+// setDelegation, setBond, setBonderList, registerPRep, unregisterPRep,
+// disqualifyPRep, setPRep, setRevision and claimIScore. Fail is true if
+// the transaction is expected to fail.
+type ScenarioTx struct {
+	Type        string            `json:"type" yaml:"type"`
+	From        string            `json:"from" yaml:"from"`
+	Amount      *Amount           `json:"amount" yaml:"amount"`
+	Delegations []ScenarioVote    `json:"delegations" yaml:"delegations"`
+	Bonds       []ScenarioVote    `json:"bonds" yaml:"bonds"`
+	Bonders     []string          `json:"bonders" yaml:"bonders"`
+	PRep        *ScenarioPRepInfo `json:"prep" yaml:"prep"`
+	Address     string            `json:"address" yaml:"address"`
+	Revision    int               `json:"revision" yaml:"revision"`
+	Fail        bool              `json:"fail" yaml:"fail"`
+}
+
+// ScenarioExpect has the values expected at the end of a step.
+type ScenarioExpect struct {
+	Revision    int               `json:"revision" yaml:"revision"`
+	Balances    map[string]Amount `json:"balances" yaml:"balances"`
+	Stakes      map[string]Amount `json:"stakes" yaml:"stakes"`
+	IScores     map[string]Amount `json:"iscores" yaml:"iscores"`
+	TotalSupply *Amount           `json:"totalSupply" yaml:"totalSupply"`
+	TotalStake  *Amount           `json:"totalStake" yaml:"totalStake"`
+	TotalBond   *Amount           `json:"totalBond" yaml:"totalBond"`
+	Validators  []string          `json:"validators" yaml:"validators"`
+}
+
+// ScenarioStep executes Transactions in a block, then goes Blocks blocks
+// and to the first block of the Terms-th next term in order. Expect is
+// checked at the end.
+type ScenarioStep struct {
+	Name         string          `json:"name" yaml:"name"`
+	Transactions []ScenarioTx    `json:"transactions" yaml:"transactions"`
+	Blocks       int64           `json:"blocks" yaml:"blocks"`
+	Terms        int             `json:"terms" yaml:"terms"`
+	Expect       *ScenarioExpect `json:"expect" yaml:"expect"`
+}
+
+type Scenario struct {
+	Revision   int               `json:"revision" yaml:"revision"`
+	Config     ScenarioConfig    `json:"config" yaml:"config"`
+	Accounts   []ScenarioAccount `json:"accounts" yaml:"accounts"`
+	Validators []string          `json:"validators" yaml:"validators"`
+	Steps      []ScenarioStep    `json:"steps" yaml:"steps"`
+}
+
+// ParseScenario parses a scenario in JSON or YAML.
+func ParseScenario(bs []byte) (*Scenario, error) {
+	s := new(Scenario)
+	if trimmed := bytes.TrimSpace(bs); len(trimmed) > 0 && trimmed[0] == '{' {
+		if err := json.Unmarshal(bs, s); err != nil {
+			return nil, errors.IllegalArgumentError.Wrap(err, "InvalidScenario")
+		}
+	} else {
+		if err := yaml.Unmarshal(bs, s); err != nil {
+			return nil, errors.IllegalArgumentError.Wrap(err, "InvalidScenario")
+		}
+	}
+	if s.Revision == 0 {
+		s.Revision = icmodule.RevisionICON2R1
+	}
+	if s.Revision < 0 || s.Revision > icmodule.MaxRevision {
+		return nil, errors.IllegalArgumentError.Errorf("InvalidRevision(%d)", s.Revision)
+	}
+	return s, nil
+}
+
+// LoadScenario reads a scenario from the file.
+func LoadScenario(path string) (*Scenario, error) {
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s, err := ParseScenario(bs)
+	if err != nil {
+		return nil, errors.Wrapf(err, "file=%s", filepath.Base(path))
+	}
+	return s, nil
+}
+
+var scenarioReportHeader = []string{
+	"term", "startHeight", "endHeight", "revision", "iissVersion",
+	"totalSupply", "supplyChange", "totalStake", "totalBond", "totalDelegated",
+	"iglobal", "iprep", "ivoter",
+	"calcStartHeight", "calcPeriod", "calcReward",
+	"validators",
+}
+
+type scenarioRunner struct {
+	scenario *Scenario
+	sim      Simulator
+	accounts map[string]module.Address
+	names    map[string]string
+	report   *csv.Writer
+	failures []string
+
+	term       *icstate.TermSnapshot
+	supply     *big.Int
+	validators []module.Validator
+}
+
+func (r *scenarioRunner) address(s string) (module.Address, error) {
+	if addr, ok := r.accounts[s]; ok {
+		return addr, nil
+	}
+	addr, err := common.NewAddressFromString(s)
+	if err != nil {
+		return nil, errors.IllegalArgumentError.Errorf("UnknownAccount(%s)", s)
+	}
+	return addr, nil
+}
+
+func (r *scenarioRunner) name(addr module.Address) string {
+	if name, ok := r.names[addr.String()]; ok {
+		return name
+	}
+	return addr.String()
+}
+
+func (r *scenarioRunner) init() error {
+	s := r.scenario
+	c := s.Config.newConfig()
+	r.accounts = make(map[string]module.Address)
+	r.names = make(map[string]string)
+	addAccount := func(name string, addr module.Address) error {
+		if _, ok := r.accounts[name]; ok {
+			return errors.IllegalArgumentError.Errorf("DuplicateAccount(%s)", name)
+		}
+		r.accounts[name] = addr
+		r.names[addr.String()] = name
+		return nil
+	}
+
+	balances := make(map[string]*big.Int)
+	for i, account := range s.Accounts {
+		addr := newDummyAddress(i + 1)
+		if len(account.Address) > 0 {
+			var err error
+			if addr, err = common.NewAddressFromString(account.Address); err != nil {
+				return errors.IllegalArgumentError.Wrapf(err, "InvalidAddress(%s)", account.Address)
+			}
+		}
+		if len(account.Name) > 0 {
+			if err := addAccount(account.Name, addr); err != nil {
+				return err
+			}
+		}
+		if balance := account.Balance.Value(); balance != nil {
+			balances[icutils.ToKey(addr)] = balance
+		}
+	}
+
+	var validators []module.Validator
+	if len(s.Validators) > 0 {
+		for _, v := range s.Validators {
+			addr, err := r.address(v)
+			if err != nil {
+				return err
+			}
+			validator, err := state.ValidatorFromAddress(addr)
+			if err != nil {
+				return err
+			}
+			validators = append(validators, validator)
+		}
+	} else {
+		for i := 0; i < int(c.MainPRepCount); i++ {
+			addr := newDummyAddress(4000 + i)
+			if err := addAccount(fmt.Sprintf("validator%d", i), addr); err != nil {
+				return err
+			}
+			validator, _ := state.ValidatorFromAddress(addr)
+			validators = append(validators, validator)
+		}
+	}
+
+	r.sim = NewSimulator(icmodule.ValueToRevision(s.Revision), validators, balances, c)
+	if r.sim == nil {
+		return errors.InvalidStateError.New("FailToCreateSimulator")
+	}
+	r.supply = r.sim.TotalSupply()
+	if err := r.sim.Go(1, nil); err != nil {
+		return err
+	}
+	r.onProgress()
+	return r.report.Write(scenarioReportHeader)
+}
+
+// onProgress writes the report of the last term if a new term has started.
+// Calculation results and validators of the new term are applied on its
+// first block.
+func (r *scenarioRunner) onProgress() {
+	term := r.sim.TermSnapshot()
+	if term == nil || term.StartHeight() > r.sim.BlockHeight() {
+		return
+	}
+	if r.term != nil && term.StartHeight() == r.term.StartHeight() {
+		return
+	}
+	if r.term != nil {
+		r.writeReport(r.term)
+	}
+	r.term = term
+	r.validators = r.sim.ValidatorList()
+}
+
+func formatBigInt(v *big.Int) string {
+	if v == nil {
+		return ""
+	}
+	return v.String()
+}
+
+func (r *scenarioRunner) writeReport(term *icstate.TermSnapshot) {
+	supply := r.sim.TotalSupply()
+	change := new(big.Int).Sub(supply, r.supply)
+	r.supply = supply
+
+	var totalDelegated *big.Int
+	if ni := r.sim.GetNetworkInfo(); ni != nil {
+		totalDelegated, _ = ni["totalDelegated"].(*big.Int)
+	}
+	var calcStart, calcPeriod string
+	var calcReward *big.Int
+	if rcInfo := r.sim.RewardCalcInfo(); rcInfo != nil {
+		calcStart = strconv.FormatInt(rcInfo.PrevHeight(), 10)
+		calcPeriod = strconv.FormatInt(rcInfo.PrevPeriod(), 10)
+		calcReward = rcInfo.PrevCalcReward()
+	}
+	validators := make([]string, len(r.validators))
+	for i, v := range r.validators {
+		validators[i] = r.name(v.Address())
+	}
+
+	_ = r.report.Write([]string{
+		strconv.Itoa(term.Sequence()),
+		strconv.FormatInt(term.StartHeight(), 10),
+		strconv.FormatInt(term.GetEndHeight(), 10),
+		strconv.Itoa(term.Revision()),
+		strconv.Itoa(term.GetIISSVersion()),
+		formatBigInt(supply),
+		formatBigInt(change),
+		formatBigInt(r.sim.TotalStake()),
+		formatBigInt(r.sim.TotalBond()),
+		formatBigInt(totalDelegated),
+		formatBigInt(term.Iglobal()),
+		formatBigInt(term.Iprep()),
+		formatBigInt(term.Ivoter()),
+		calcStart,
+		calcPeriod,
+		formatBigInt(calcReward),
+		strings.Join(validators, ";"),
+	})
+}
+
+func (r *scenarioRunner) fail(step int, format string, args ...interface{}) {
+	name := r.scenario.Steps[step].Name
+	r.failures = append(r.failures,
+		fmt.Sprintf("step %d(%s) height=%d: %s", step+1, name, r.sim.BlockHeight(), fmt.Sprintf(format, args...)))
+}
+
+func (r *scenarioRunner) votes(votes []ScenarioVote) ([]*common.Address, []*big.Int, error) {
+	addrs := make([]*common.Address, len(votes))
+	amounts := make([]*big.Int, len(votes))
+	for i, v := range votes {
+		addr, err := r.address(v.Address)
+		if err != nil {
+			return nil, nil, err
+		}
+		if v.Amount.Value() == nil {
+			return nil, nil, errors.IllegalArgumentError.Errorf("NoAmount(address=%s)", v.Address)
+		}
+		addrs[i] = common.AddressToPtr(addr)
+		amounts[i] = v.Amount.Value()
+	}
+	return addrs, amounts, nil
+}
+
+func (r *scenarioRunner) prepInfo(from module.Address, info *ScenarioPRepInfo) *icstate.PRepInfo {
+	pi := newDummyPRepInfo(len(r.names))
+	if name, ok := r.names[from.String()]; ok {
+		pi.Name = &name
+	}
+	if info != nil {
+		for _, f := range []struct {
+			dst **string
+			src *string
+		}{
+			{&pi.Name, info.Name},
+			{&pi.Country, info.Country},
+			{&pi.City, info.City},
+			{&pi.Email, info.Email},
+			{&pi.WebSite, info.Website},
+			{&pi.Details, info.Details},
+			{&pi.P2PEndpoint, info.P2PEndpoint},
+		} {
+			if f.src != nil {
+				*f.dst = f.src
+			}
+		}
+	}
+	return pi
+}
+
+func (r *scenarioRunner) newTransaction(tx *ScenarioTx) (Transaction, error) {
+	sim := r.sim
+	if tx.Type == "setRevision" {
+		return sim.SetRevision(icmodule.ValueToRevision(tx.Revision)), nil
+	}
+	from, err := r.address(tx.From)
+	if err != nil {
+		return nil, err
+	}
+	switch tx.Type {
+	case "setStake":
+		if tx.Amount == nil {
+			return nil, errors.IllegalArgumentError.New("NoAmount")
+		}
+		return sim.SetStake(from, tx.Amount.Value()), nil
+	case "setDelegation":
+		addrs, amounts, err := r.votes(tx.Delegations)
+		if err != nil {
+			return nil, err
+		}
+		ds := make(icstate.Delegations, len(addrs))
+		for i := range addrs {
+			ds[i] = icstate.NewDelegation(addrs[i], amounts[i])
+		}
+		return sim.SetDelegation(from, ds), nil
+	case "setBond":
+		addrs, amounts, err := r.votes(tx.Bonds)
+		if err != nil {
+			return nil, err
+		}
+		bonds := make(icstate.Bonds, len(addrs))
+		for i := range addrs {
+			bonds[i] = icstate.NewBond(addrs[i], amounts[i])
+		}
+		return sim.SetBond(from, bonds), nil
+	case "setBonderList":
+		bl := make(icstate.BonderList, len(tx.Bonders))
+		for i, b := range tx.Bonders {
+			addr, err := r.address(b)
+			if err != nil {
+				return nil, err
+			}
+			bl[i] = common.AddressToPtr(addr)
+		}
+		return sim.SetBonderList(from, bl), nil
+	case "registerPRep":
+		return sim.RegisterPRep(from, r.prepInfo(from, tx.PRep)), nil
+	case "setPRep":
+		if tx.PRep == nil {
+			return nil, errors.IllegalArgumentError.New("NoPRepInfo")
+		}
+		return sim.SetPRep(from, r.prepInfo(from, tx.PRep)), nil
+	case "unregisterPRep":
+		return sim.UnregisterPRep(from), nil
+	case "disqualifyPRep":
+		addr, err := r.address(tx.Address)
+		if err != nil {
+			return nil, err
+		}
+		return sim.DisqualifyPRep(from, addr), nil
+	case "claimIScore":
+		return sim.ClaimIScore(from), nil
+	default:
+		return nil, errors.IllegalArgumentError.Errorf("UnknownTransactionType(%s)", tx.Type)
+	}
+}
+
+func (r *scenarioRunner) expectAmount(step int, what string, expected *Amount, actual *big.Int) {
+	if expected == nil || expected.Value() == nil {
+		return
+	}
+	if actual == nil {
+		actual = new(big.Int)
+	}
+	if expected.Value().Cmp(actual) != 0 {
+		r.fail(step, "%s expected=%s actual=%s", what, expected.Value(), actual)
+	}
+}
+
+func (r *scenarioRunner) expectAmounts(
+	step int, what string, expected map[string]Amount, getter func(addr module.Address) *big.Int,
+) error {
+	for name, amount := range expected {
+		addr, err := r.address(name)
+		if err != nil {
+			return err
+		}
+		amount := amount
+		r.expectAmount(step, fmt.Sprintf("%s of %s", what, name), &amount, getter(addr))
+	}
+	return nil
+}
+
+func (r *scenarioRunner) checkExpect(step int, e *ScenarioExpect) error {
+	sim := r.sim
+	if e.Revision != 0 && e.Revision != sim.Revision().Value() {
+		r.fail(step, "revision expected=%d actual=%d", e.Revision, sim.Revision().Value())
+	}
+	if err := r.expectAmounts(step, "balance", e.Balances, sim.GetBalance); err != nil {
+		return err
+	}
+	if err := r.expectAmounts(step, "stake", e.Stakes, func(addr module.Address) *big.Int {
+		stake, _ := sim.GetStake(addr)["stake"].(*big.Int)
+		return stake
+	}); err != nil {
+		return err
+	}
+	if err := r.expectAmounts(step, "iscore", e.IScores, sim.QueryIScore); err != nil {
+		return err
+	}
+	r.expectAmount(step, "totalSupply", e.TotalSupply, sim.TotalSupply())
+	r.expectAmount(step, "totalStake", e.TotalStake, sim.TotalStake())
+	r.expectAmount(step, "totalBond", e.TotalBond, sim.TotalBond())
+	if e.Validators != nil {
+		expected := make([]string, len(e.Validators))
+		for i, v := range e.Validators {
+			addr, err := r.address(v)
+			if err != nil {
+				return err
+			}
+			expected[i] = r.name(addr)
+		}
+		vl := sim.ValidatorList()
+		actual := make([]string, len(vl))
+		for i, v := range vl {
+			actual[i] = r.name(v.Address())
+		}
+		if strings.Join(expected, ";") != strings.Join(actual, ";") {
+			r.fail(step, "validators expected=%v actual=%v", expected, actual)
+		}
+	}
+	return nil
+}
+
+func (r *scenarioRunner) runStep(idx int, step *ScenarioStep) error {
+	sim := r.sim
+	if len(step.Transactions) > 0 {
+		blk := NewBlock()
+		for i := range step.Transactions {
+			tx, err := r.newTransaction(&step.Transactions[i])
+			if err != nil {
+				return errors.Wrapf(err, "step=%d tx=%d", idx+1, i)
+			}
+			blk.AddTransaction(tx)
+		}
+		receipts, err := sim.GoByBlock(blk, nil)
+		if err != nil {
+			return err
+		}
+		for i, rct := range receipts {
+			tx := &step.Transactions[i]
+			if failed := rct.Status() != Success; failed != tx.Fail {
+				r.fail(idx, "tx %d(%s) from=%s expected fail=%t error=%v",
+					i, tx.Type, tx.From, tx.Fail, rct.Error())
+			}
+		}
+		r.onProgress()
+	}
+	for blocks := step.Blocks; blocks > 0; {
+		// stop at the first block of each term for the report
+		n := blocks
+		if term := sim.TermSnapshot(); term != nil {
+			next := term.StartHeight()
+			if next <= sim.BlockHeight() {
+				next = term.GetEndHeight() + 1
+			}
+			if left := next - sim.BlockHeight(); left > 0 && left < n {
+				n = left
+			}
+		}
+		if err := sim.Go(n, nil); err != nil {
+			return err
+		}
+		r.onProgress()
+		blocks -= n
+	}
+	for i := 0; i < step.Terms; i++ {
+		if err := sim.GoToTermEnd(nil); err != nil {
+			return err
+		}
+		if err := sim.Go(1, nil); err != nil {
+			return err
+		}
+		r.onProgress()
+	}
+	if step.Expect != nil {
+		return r.checkExpect(idx, step.Expect)
+	}
+	return nil
+}
+
+// RunScenario runs the scenario with a new simulator, and writes the report
+// of each term to w in CSV. It returns failed expectations of the scenario.
+func RunScenario(s *Scenario, w io.Writer) ([]string, error) {
+	r := &scenarioRunner{
+		scenario: s,
+		report:   csv.NewWriter(w),
+	}
+	defer r.report.Flush()
+	if err := r.init(); err != nil {
+		return nil, err
+	}
+	for i := range s.Steps {
+		if err := r.runStep(i, &s.Steps[i]); err != nil {
+			return r.failures, err
+		}
+	}
+	r.report.Flush()
+	return r.failures, r.report.Error()
+}
@@ -0,0 +1,145 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package icsim
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/icon/iiss/icutils"
+)
+
+func TestParseAmount(t *testing.T) {
+	for _, c := range []struct {
+		in  string
+		out *big.Int
+	}{
+		{"100", big.NewInt(100)},
+		{"0x64", big.NewInt(100)},
+		{"1icx", icutils.ToLoop(1)},
+		{"0.5 ICX", new(big.Int).Div(icutils.ToLoop(1), big.NewInt(2))},
+		{"1e-19icx", nil},
+		{"abc", nil},
+	} {
+		v, err := ParseAmount(c.in)
+		if c.out == nil {
+			assert.Error(t, err, c.in)
+		} else {
+			assert.NoError(t, err, c.in)
+			assert.Zero(t, c.out.Cmp(v), c.in)
+		}
+	}
+}
+
+const testScenario = `
+config:
+  termPeriod: 10
+  mainPRepCount: 2
+  subPRepCount: 2
+accounts:
+  - {name: prep0, balance: 2000icx}
+  - {name: prep1, balance: 2000icx}
+  - {name: prep2, balance: 2000icx}
+  - {name: prep3, balance: 2000icx}
+  - {name: alice, balance: 10000icx}
+steps:
+  - name: register
+    transactions:
+      - {type: registerPRep, from: prep0}
+      - {type: registerPRep, from: prep1}
+      - {type: registerPRep, from: prep2}
+      - {type: registerPRep, from: prep3}
+      - {type: registerPRep, from: prep3, fail: true}
+      - {type: setStake, from: alice, amount: 10000icx}
+    expect:
+      stakes: {alice: 10000icx}
+  - name: vote
+    transactions:
+      - {type: setStake, from: prep0, amount: 100icx}
+      - {type: setStake, from: prep1, amount: 100icx}
+      - {type: setStake, from: prep2, amount: 100icx}
+      - {type: setStake, from: prep3, amount: 100icx}
+      - {type: setBonderList, from: prep0, bonders: [prep0]}
+      - {type: setBonderList, from: prep1, bonders: [prep1]}
+      - {type: setBonderList, from: prep2, bonders: [prep2]}
+      - {type: setBonderList, from: prep3, bonders: [prep3]}
+      - {type: setBond, from: prep0, bonds: [{address: prep0, amount: 100icx}]}
+      - {type: setBond, from: prep1, bonds: [{address: prep1, amount: 100icx}]}
+      - {type: setBond, from: prep2, bonds: [{address: prep2, amount: 100icx}]}
+      - {type: setBond, from: prep3, bonds: [{address: prep3, amount: 100icx}]}
+      - type: setDelegation
+        from: alice
+        delegations:
+          - {address: prep3, amount: 4000icx}
+          - {address: prep2, amount: 3000icx}
+          - {address: prep1, amount: 2000icx}
+          - {address: prep0, amount: 1000icx}
+    terms: 2
+    expect:
+      totalBond: 400icx
+      validators: [prep3, prep2]
+  - name: reward
+    blocks: 25
+    transactions:
+      - {type: claimIScore, from: alice}
+`
+
+func TestRunScenario(t *testing.T) {
+	s, err := ParseScenario([]byte(testScenario))
+	assert.NoError(t, err)
+
+	buf := bytes.NewBuffer(nil)
+	failures, err := RunScenario(s, buf)
+	assert.NoError(t, err)
+	assert.Empty(t, failures)
+
+	records, err := csv.NewReader(bytes.NewReader(buf.Bytes())).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, scenarioReportHeader, records[0])
+	// 2 terms with "vote" and 2 more terms with 25 blocks
+	assert.Equal(t, 5, len(records))
+	last := records[len(records)-1]
+	assert.Equal(t, "prep3;prep2", last[len(last)-1])
+	reward, ok := new(big.Int).SetString(last[15], 10)
+	assert.True(t, ok)
+	assert.True(t, reward.Sign() > 0)
+}
+
+func TestRunScenario_Failures(t *testing.T) {
+	s, err := ParseScenario([]byte(`{
+		"accounts": [ {"name": "alice", "balance": "100icx"} ],
+		"steps": [ {
+			"name": "fail",
+			"transactions": [ {"type": "setStake", "from": "alice", "amount": "200icx"} ],
+			"expect": { "balances": {"alice": "0x1"}, "totalStake": "100icx" }
+		} ]
+	}`))
+	assert.NoError(t, err)
+
+	failures, err := RunScenario(s, bytes.NewBuffer(nil))
+	assert.NoError(t, err)
+	// the simulator doesn't check balance for stake
+	assert.Equal(t, 2, len(failures))
+
+	s.Steps[0].Transactions[0].Type = "unknown"
+	_, err = RunScenario(s, bytes.NewBuffer(nil))
+	assert.Error(t, err)
+}
@@ -82,6 +82,7 @@ type Simulator interface {
 	GetPReps() map[string]interface{}
 	GetNetworkInfo() map[string]interface{}
 	TermSnapshot() *icstate.TermSnapshot
+	RewardCalcInfo() *icstate.RewardCalcInfo
 
 	Go(blocks int64, csi module.ConsensusInfo) error
 	GoTo(blockHeight int64, csi module.ConsensusInfo) error
@@ -95,6 +96,7 @@ type Simulator interface {
 	SetStake(from module.Address, amount *big.Int) Transaction
 
 	QueryIScore(address module.Address) *big.Int
+	EstimateIScore(address module.Address, ds icstate.Delegations, bonds icstate.Bonds) map[string]interface{}
 	ClaimIScore(from module.Address) Transaction
 
 	GetPRepStats(address module.Address) map[string]interface{}
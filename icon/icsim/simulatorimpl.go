@@ -273,6 +273,7 @@ func (sim *simulatorImpl) onExecutionBegin(wc WorldContext) error {
 func (sim *simulatorImpl) onBaseTx(wc WorldContext) error {
 	cc := NewCallContext(wc, state.SystemAddress)
 	es := wc.GetExtensionState().(*iiss.ExtensionStateImpl)
+	es.TrackValidation(sim.plt.validation != nil)
 	return es.HandleConsensusInfo(cc)
 }
 
@@ -490,6 +491,13 @@ func (sim *simulatorImpl) QueryIScore(address module.Address) *big.Int {
 	return iscore
 }
 
+func (sim *simulatorImpl) EstimateIScore(
+	address module.Address, ds icstate.Delegations, bonds icstate.Bonds) map[string]interface{} {
+	es := sim.getExtensionState(true)
+	jso, _ := es.EstimateIScore(sim.newCallContext(), address, nil, ds, bonds)
+	return jso
+}
+
 func (sim *simulatorImpl) GetPRepTerm() map[string]interface{} {
 	es := sim.getExtensionState(true)
 	jso, _ := es.GetPRepTermInJSON(sim.BlockHeight())
@@ -546,6 +554,15 @@ func (sim *simulatorImpl) TermSnapshot() *icstate.TermSnapshot {
 	return es.State.GetTermSnapshot()
 }
 
+func (sim *simulatorImpl) RewardCalcInfo() *icstate.RewardCalcInfo {
+	es := sim.getExtensionState(true)
+	rcInfo, err := es.State.GetRewardCalcInfo()
+	if err != nil {
+		return nil
+	}
+	return rcInfo
+}
+
 func (sim *simulatorImpl) ValidatorList() []module.Validator {
 	vss := sim.wss.GetValidatorSnapshot()
 	size := vss.Len()
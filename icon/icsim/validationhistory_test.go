@@ -0,0 +1,73 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package icsim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/icon/icmodule"
+	"github.com/icon-project/goloop/icon/iiss"
+)
+
+func TestSimulator_ValidationHistory(t *testing.T) {
+	const (
+		mainPRepCount              = 22
+		validationPenaltyCondition = 5
+	)
+	c := NewConfig()
+	c.MainPRepCount = mainPRepCount
+	c.TermPeriod = 100
+	c.ValidationPenaltyCondition = validationPenaltyCondition
+
+	env := initEnv(t, c, icmodule.Revision13)
+	sim := env.sim
+	history, err := iiss.NewValidationHistory(db.NewMapDB(), 100, sim.(*simulatorImpl).logger)
+	assert.NoError(t, err)
+	sim.(*simulatorImpl).plt.validation = history
+
+	voted := make([]bool, mainPRepCount)
+	for i := range voted {
+		voted[i] = true
+	}
+	vl := sim.ValidatorList()
+	assert.NoError(t, sim.Go(2, newConsensusInfo(sim.Database(), vl, voted)))
+	start := sim.BlockHeight()
+
+	// prep0 is penalized at the 5th failure
+	voted[0] = false
+	assert.NoError(t, sim.Go(validationPenaltyCondition, newConsensusInfo(sim.Database(), vl, voted)))
+	penalized := sim.BlockHeight()
+
+	jso, err := history.GetValidationHistory(env.preps[0], 3)
+	assert.NoError(t, err)
+	assert.Equal(t, start-1, jso["startHeight"])
+	assert.Equal(t, penalized, jso["lastHeight"])
+	assert.Equal(t, int64(validationPenaltyCondition+2), jso["total"])
+	assert.Equal(t, int64(validationPenaltyCondition), jso["failed"])
+	assert.Zero(t, jso["failCont"])
+	assert.Equal(t, int64(validationPenaltyCondition), jso["maxFailCont"])
+	assert.Equal(t, []interface{}{penalized}, jso["penalties"])
+	assert.Equal(t, []interface{}{start + 3}, jso["alerts"])
+
+	jso, err = history.GetValidationHistory(env.preps[1], 3)
+	assert.NoError(t, err)
+	assert.Zero(t, jso["failed"])
+	assert.Equal(t, []interface{}{}, jso["alerts"])
+}
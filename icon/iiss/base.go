@@ -139,6 +139,7 @@ func (tx *baseV3) Execute(ctx contract.Context, wcs state.WorldSnapshot, estimat
 
 	icc := NewCallContext(cc, tx.From())
 	es := cc.GetExtensionState().(*ExtensionStateImpl)
+	es.TrackValidation(IsValidationTracked(ctx.ContractManager()))
 	if err := es.OnBaseTx(icc, tx.Data); err != nil {
 		return nil, err
 	}
@@ -210,6 +211,10 @@ func (es *ExtensionStateImpl) updateBlockVoteStats(
 	blockHeight := cc.BlockHeight()
 
 	size := voters.Len()
+	var bv *blockValidation
+	if es.trackValidation {
+		bv = newBlockValidation(blockHeight, size)
+	}
 	for i := 0; i < size; i++ {
 		voter := voters.Get(i)
 		if err = es.State.OnBlockVote(voter, voted[i], blockHeight); err != nil {
@@ -220,7 +225,18 @@ func (es *ExtensionStateImpl) updateBlockVoteStats(
 				return err
 			}
 		}
+		if bv != nil {
+			var failCont int64
+			penalized := false
+			if !voted[i] {
+				// penalty resets consecutive failures
+				failCont = es.State.GetPRepStatusByOwner(voter, false).GetVFailCont(blockHeight)
+				penalized = failCont == 0
+			}
+			bv.add(voter, voted[i], failCont, penalized)
+		}
 	}
+	es.validation = bv
 
 	lastVoters := es.State.GetLastBlockVotersSnapshot()
 	if lastVoters != nil {
@@ -484,5 +500,10 @@ func (es *ExtensionStateImpl) OnBaseTx(cc icmodule.CallContext, data []byte) err
 			return err
 		}
 	}
+	if cc.Revision().Value() >= icmodule.RevisionIISSStateEvents {
+		if err := es.handleUnstakingTimerOnBaseTx(cc); err != nil {
+			return err
+		}
+	}
 	return nil
 }
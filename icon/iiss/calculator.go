@@ -75,6 +75,8 @@ type Calculator struct {
 	global      icstage.Global
 	temp        *icreward.State
 	stats       *statistics
+	history     *RewardHistory
+	recorder    *rewardRecorder
 
 	lock    sync.Mutex
 	waiters []*sync.Cond
@@ -162,7 +164,7 @@ func (c *Calculator) Stop() {
 	}
 }
 
-func UpdateCalculator(c *Calculator, ess state.ExtensionSnapshot, logger log.Logger) *Calculator {
+func UpdateCalculator(c *Calculator, ess state.ExtensionSnapshot, history *RewardHistory, logger log.Logger) *Calculator {
 	essi := ess.(*ExtensionSnapshotImpl)
 	back := essi.Back2()
 	reward := essi.Reward()
@@ -174,16 +176,23 @@ func UpdateCalculator(c *Calculator, ess state.ExtensionSnapshot, logger log.Log
 		}
 		c.Stop()
 	}
-	return NewCalculator(essi.database, back, reward, logger)
+	return NewCalculator(essi.database, back, reward, history, logger)
 }
 
 func (c *Calculator) run() (err error) {
 	defer func() {
 		if err != nil {
+			c.recorder.Abort()
 			c.setResult(nil, err)
 		}
 	}()
 
+	// failure on reward history doesn't affect calculation
+	if c.recorder, err = c.history.Begin(c.global); err != nil {
+		c.log.Warnf("Failed to begin reward history. %+v", err)
+		c.recorder, err = nil, nil
+	}
+
 	startTS := time.Now()
 	if err = c.prepare(); err != nil {
 		err = icmodule.CalculationFailedError.Wrapf(err, "Failed to prepare calculator")
@@ -215,6 +224,10 @@ func (c *Calculator) run() (err error) {
 	}
 	finalTS := time.Now()
 
+	if err := c.recorder.Finish(); err != nil {
+		c.log.Warnf("Failed to record reward history. %+v", err)
+	}
+
 	c.log.Infof("Calculation time: total=%s prepare=%s blockProduce=%s voted=%s voting=%s postwork=%s",
 		finalTS.Sub(startTS), prepareTS.Sub(startTS), bpTS.Sub(prepareTS),
 		votedTS.Sub(bpTS), votingTS.Sub(votedTS), finalTS.Sub(votingTS),
@@ -272,6 +285,7 @@ func (c *Calculator) processClaim() error {
 			if err = c.temp.SetIScore(addr, nIScore); err != nil {
 				return err
 			}
+			c.recorder.AddClaim(addr, claim.Value())
 		}
 	}
 	return nil
@@ -300,6 +314,7 @@ func (c *Calculator) replayBugDisabledPRep() error {
 		if err = c.updateIScore(addr, obj.Value(), TypeVoting); err != nil {
 			return err
 		}
+		c.recorder.AddVoting(addr, icreward.TypeDelegating, obj.Value(), nil)
 		if err = c.temp.DeleteBugDisabledPRep(addr); err != nil {
 			return err
 		}
@@ -370,6 +385,7 @@ func (c *Calculator) calculateBlockProduce() error {
 		if err = c.updateIScore(v.Address(), v.IScore(), TypeBlockProduce); err != nil {
 			return err
 		}
+		c.recorder.AddReward(v.Address(), TypeBlockProduce, v.IScore())
 	}
 
 	return nil
@@ -530,6 +546,7 @@ func (c *Calculator) calculateVotedReward() error {
 		if err = c.updateIScore(addr, prep.IScore(), TypeVoted); err != nil {
 			return err
 		}
+		c.recorder.AddReward(addr, TypeVoted, prep.IScore())
 	}
 	return nil
 }
@@ -817,7 +834,7 @@ func (c *Calculator) addDataForBugDisabledPRep(prepInfo map[string]*pRepEnable,
 				}
 				if delegating != nil {
 					offset := int(intconv.BytesToInt64(keySplit[1]))
-					reward := c.votingReward(multiplier, divider, offset, c.global.GetOffsetLimit(), prepInfo, delegating.Iterator())
+					reward := c.votingReward(multiplier, divider, offset, c.global.GetOffsetLimit(), prepInfo, delegating.Iterator(), nil)
 					bug := icreward.NewBugDisabledPRep(reward)
 					if err = c.temp.AddBugDisabledPRep(event.Target(), bug); err != nil {
 						return err
@@ -866,19 +883,22 @@ func (c *Calculator) processVoting(
 			return err
 		}
 		var reward *big.Int
+		var detail *votingDetail
 		if _, ok := eventMap[string(addr.Bytes())]; ok {
 			continue
 		} else {
+			detail = c.recorder.NewVotingDetail()
 			voting := toVoting(_type, o)
 			if voting == nil {
 				c.log.Errorf("Failed to convert data to voting instance")
 				continue
 			}
-			reward = c.votingReward(multiplier, divider, from, to, prepInfo, voting.Iterator())
+			reward = c.votingReward(multiplier, divider, from, to, prepInfo, voting.Iterator(), detail.add)
 		}
 		if err = c.updateIScore(addr, reward, TypeVoting); err != nil {
 			return err
 		}
+		c.recorder.AddVoting(addr, _type, reward, detail)
 	}
 
 	return nil
@@ -894,6 +914,7 @@ func (c *Calculator) processVoting(
 //   multiplier = Iglobal * Ivoter * IScoreICXRatio
 //   divider = 100 * Term period * total voting amount
 // reward = multiplier * voting amount * period / divider
+// onReward is called with reward of each voting if it's not nil.
 func (c *Calculator) votingReward(
 	multiplier *big.Int,
 	divider *big.Int,
@@ -901,6 +922,7 @@ func (c *Calculator) votingReward(
 	to int,
 	prepInfo map[string]*pRepEnable,
 	iter icstate.VotingIterator,
+	onReward func(prep module.Address, reward *big.Int),
 ) *big.Int {
 	total := new(big.Int)
 	checkMinVoting := c.global.GetIISSVersion() == icstate.IISSVersion2
@@ -928,6 +950,9 @@ func (c *Calculator) votingReward(
 				reward.Mul(reward, big.NewInt(int64(period)))
 				reward.Div(reward, divider)
 				total.Add(total, reward)
+				if onReward != nil {
+					onReward(voting.To(), reward)
+				}
 				c.log.Tracef("VotingReward %s: %s = %s * %s * %d / %s",
 					voting.To(), reward, multiplier, voting.Amount(), period, divider)
 			}
@@ -958,6 +983,7 @@ func (c *Calculator) processVotingEvent(
 		if err != nil {
 			return err
 		}
+		detail := c.recorder.NewVotingDetail()
 
 		// initial voting took place in the previous period
 		// New configuration works from the next block
@@ -968,15 +994,15 @@ func (c *Calculator) processVotingEvent(
 			to := offsets[i]
 			switch iissVersion {
 			case icstate.IISSVersion2:
-				ret := c.votingReward(multiplier, divider, from, offsetLimit, prepInfo, voting.Iterator())
+				ret := c.votingReward(multiplier, divider, from, offsetLimit, prepInfo, voting.Iterator(), detail.add)
 				reward.Add(reward, ret)
 				c.log.Tracef("VotingEvent %s %d add: %d-%d %s", addr, i, from, offsetLimit, ret)
-				ret = c.votingReward(multiplier, divider, to, offsetLimit, prepInfo, voting.Iterator())
+				ret = c.votingReward(multiplier, divider, to, offsetLimit, prepInfo, voting.Iterator(), detail.sub)
 				reward.Sub(reward, ret)
 				c.log.Tracef("VotingEvent %s %d sub: %d-%d %s", addr, i, to, offsetLimit, ret)
 			case icstate.IISSVersion3:
 				to = offsets[i]
-				ret := c.votingReward(multiplier, divider, from, to, prepInfo, voting.Iterator())
+				ret := c.votingReward(multiplier, divider, from, to, prepInfo, voting.Iterator(), detail.add)
 				reward.Add(reward, ret)
 				c.log.Tracef("VotingEvent %s %d: %d-%d %s", addr, i, from, to, ret)
 			}
@@ -991,7 +1017,7 @@ func (c *Calculator) processVotingEvent(
 			from = to
 		}
 		// calculate reward for last event
-		ret := c.votingReward(multiplier, divider, from, offsetLimit, prepInfo, voting.Iterator())
+		ret := c.votingReward(multiplier, divider, from, offsetLimit, prepInfo, voting.Iterator(), detail.add)
 		reward.Add(reward, ret)
 		c.log.Tracef("VotingEvent %s last: %d, %d: %s", addr, from, offsetLimit, ret)
 
@@ -1001,6 +1027,7 @@ func (c *Calculator) processVotingEvent(
 		if err = c.updateIScore(addr, reward, TypeVoting); err != nil {
 			return err
 		}
+		c.recorder.AddVoting(addr, _type, reward, detail)
 	}
 	return nil
 }
@@ -1116,7 +1143,9 @@ func (c *Calculator) processBTP() error {
 
 const InitBlockHeight = -1
 
-func NewCalculator(database db.Database, back *icstage.Snapshot, reward *icreward.Snapshot, logger log.Logger) *Calculator {
+func NewCalculator(
+	database db.Database, back *icstage.Snapshot, reward *icreward.Snapshot, history *RewardHistory, logger log.Logger,
+) *Calculator {
 	var err error
 	var global icstage.Global
 	var startHeight int64
@@ -1141,6 +1170,7 @@ func NewCalculator(database db.Database, back *icstage.Snapshot, reward *icrewar
 		global:      global,
 		startHeight: startHeight,
 		stats:       newStatistics(),
+		history:     history,
 	}
 	if startHeight != InitBlockHeight {
 		go c.run()
@@ -1149,8 +1179,17 @@ func NewCalculator(database db.Database, back *icstage.Snapshot, reward *icrewar
 }
 
 type CalculatorHolder struct {
-	lock   sync.Mutex
-	runner *Calculator
+	lock    sync.Mutex
+	runner  *Calculator
+	history *RewardHistory
+}
+
+// SetRewardHistory sets RewardHistory to record rewards of following calculations
+func (h *CalculatorHolder) SetRewardHistory(history *RewardHistory) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.history = history
 }
 
 func (h *CalculatorHolder) Start(ess state.ExtensionSnapshot, logger log.Logger) {
@@ -1158,7 +1197,7 @@ func (h *CalculatorHolder) Start(ess state.ExtensionSnapshot, logger log.Logger)
 	defer h.lock.Unlock()
 
 	if ess != nil {
-		h.runner = UpdateCalculator(h.runner, ess, logger)
+		h.runner = UpdateCalculator(h.runner, ess, h.history, logger)
 	} else {
 		if h.runner != nil {
 			h.runner.Stop()
@@ -783,6 +783,7 @@ func TestCalculator_VotingReward(t *testing.T) {
 				args.to,
 				prepInfo,
 				args.delegating.Iterator(),
+				nil,
 			)
 			assert.Equal(t, tt.want, reward.Int64())
 		})
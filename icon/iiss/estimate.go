@@ -0,0 +1,262 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package iiss
+
+import (
+	"math/big"
+
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/icon/icmodule"
+	"github.com/icon-project/goloop/icon/iiss/icreward"
+	"github.com/icon-project/goloop/icon/iiss/icstage"
+	"github.com/icon-project/goloop/icon/iiss/icstate"
+	"github.com/icon-project/goloop/icon/iiss/icutils"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/scoreresult"
+)
+
+// newGlobalOfTerm returns reward variables which will be used
+// to calculate rewards of the term. It's the same as the one written
+// to the front stage on the start of the term.
+func newGlobalOfTerm(term *icstate.TermSnapshot) (icstage.Global, error) {
+	iissVersion := term.GetIISSVersion()
+	switch iissVersion {
+	case icstate.IISSVersion2:
+		return icstage.NewGlobalV1(
+			iissVersion,
+			term.StartHeight(),
+			int(term.Period()-1),
+			term.Revision(),
+			term.Irep(),
+			term.Rrep(),
+			term.MainPRepCount(),
+			term.GetElectedPRepCount(),
+		), nil
+	case icstate.IISSVersion3:
+		return icstage.NewGlobalV2(
+			iissVersion,
+			term.StartHeight(),
+			int(term.Period()-1),
+			term.Revision(),
+			term.Iglobal(),
+			term.Iprep(),
+			term.Ivoter(),
+			term.Icps(),
+			term.Irelay(),
+			term.GetElectedPRepCount(),
+			term.BondRequirement(),
+		), nil
+	default:
+		return nil, errors.CriticalFormatError.Errorf(
+			"InvalidIISSVersion(version=%d)", iissVersion)
+	}
+}
+
+// rewardEstimator estimates rewards for a whole term assuming that
+// nothing changes during the term.
+type rewardEstimator struct {
+	global     icstage.Global
+	period     int
+	vInfo      *votedInfo
+	multiplier *big.Int
+	divider    *big.Int
+}
+
+// votingReward returns voting reward of a single voting for a term
+// reward = multiplier * voting amount * period / divider
+func (re *rewardEstimator) votingReward(voting icstate.Voting) *big.Int {
+	reward := new(big.Int)
+	if re.multiplier.Sign() == 0 || re.divider.Sign() == 0 {
+		return reward
+	}
+	if re.global.GetIISSVersion() == icstate.IISSVersion2 &&
+		voting.Amount().Cmp(BigIntMinDelegation) < 0 {
+		return reward
+	}
+	// ICONist can't get voting reward from disabled P-Reps
+	if prep := re.vInfo.GetPRepByAddress(voting.To()); prep == nil || !prep.Enable() {
+		return reward
+	}
+	reward.Mul(re.multiplier, voting.Amount())
+	reward.Mul(reward, big.NewInt(int64(re.period)))
+	reward.Div(reward, re.divider)
+	return reward
+}
+
+func (re *rewardEstimator) votingRewardsToJSON(iter icstate.VotingIterator, total *big.Int) ([]interface{}, error) {
+	votings := make([]interface{}, 0)
+	for ; iter.Has(); iter.Next() {
+		voting, err := iter.Get()
+		if err != nil {
+			return nil, err
+		}
+		reward := re.votingReward(voting)
+		total.Add(total, reward)
+		votings = append(votings, map[string]interface{}{
+			"address": voting.To(),
+			"value":   voting.Amount(),
+			"iscore":  reward,
+		})
+	}
+	return votings, nil
+}
+
+func newRewardEstimator(
+	term *icstate.TermSnapshot, prepSet icstate.PRepSet, delegated, bonded icstage.VoteList,
+) (*rewardEstimator, error) {
+	global, err := newGlobalOfTerm(term)
+	if err != nil {
+		return nil, err
+	}
+
+	vInfo := newVotedInfo(global.GetElectedPRepCount())
+	for i := 0; i < prepSet.Size(); i++ {
+		entry := prepSet.GetByIndex(i)
+		voted := icreward.NewVoted()
+		voted.SetEnable(true)
+		voted.SetDelegated(new(big.Int).Set(entry.Delegated()))
+		voted.SetBonded(new(big.Int).Set(entry.Bonded()))
+		data := newVotedData(voted)
+		data.SetPubKey(entry.HasPubKey())
+		vInfo.AddVotedData(entry.Owner(), data)
+	}
+	vInfo.UpdateDelegated(delegated)
+	vInfo.UpdateBonded(bonded)
+	for _, data := range vInfo.PReps() {
+		data.UpdateBondedDelegation(global.GetBondRequirement())
+	}
+	vInfo.Sort()
+	vInfo.UpdateTotalBondedDelegation()
+
+	period := global.GetTermPeriod()
+	multiplier, divider := varForVotedReward(global)
+	vInfo.CalculateReward(multiplier, divider, period)
+
+	multiplier, divider = varForVotingReward(global, vInfo.TotalVoted())
+	return &rewardEstimator{
+		global:     global,
+		period:     period,
+		vInfo:      vInfo,
+		multiplier: multiplier,
+		divider:    divider,
+	}, nil
+}
+
+// unbondAfterBondDelta returns the total amount of unbonds after applying
+// bond changes. Decreased bonds are added to unbonds and increased bonds
+// cancel unbonds to the same P-Rep like AccountState.UpdateUnbonds.
+func unbondAfterBondDelta(unbonds icstate.Unbonds, delta map[string]*big.Int) *big.Int {
+	total := new(big.Int)
+	unbondsMapByAddr := unbonds.MapByAddr()
+	for key, unbond := range unbondsMapByAddr {
+		value := new(big.Int).Set(unbond.Value())
+		if d, ok := delta[key]; ok {
+			value.Sub(value, d)
+		}
+		if value.Sign() > 0 {
+			total.Add(total, value)
+		}
+	}
+	for key, d := range delta {
+		if _, ok := unbondsMapByAddr[key]; !ok && d.Sign() < 0 {
+			total.Sub(total, d)
+		}
+	}
+	return total
+}
+
+// EstimateIScore returns the amount of I-Score which owner would get for a term
+// with reward variables of the current term and current votes of P-Reps.
+// Delegations and bonds of owner are replaced with ds and bonds before estimation,
+// and nil means keeping current ones. The stake is only used to check voting power.
+func (es *ExtensionStateImpl) EstimateIScore(
+	cc icmodule.CallContext, owner module.Address, stake *big.Int, ds icstate.Delegations, bonds icstate.Bonds,
+) (map[string]interface{}, error) {
+	term := es.State.GetTermSnapshot()
+	if term == nil {
+		return nil, errors.InvalidStateError.New("TermNotReady")
+	}
+
+	account := es.State.GetAccountSnapshot(owner)
+	if account == nil {
+		account = icstate.GetEmptyAccountSnapshot()
+	}
+	if stake == nil {
+		stake = account.Stake()
+	}
+	if ds == nil {
+		ds = account.Delegations()
+	}
+	if bonds == nil {
+		bonds = account.Bonds()
+	}
+	if stake.Sign() < 0 {
+		return nil, scoreresult.InvalidParameterError.Errorf("NegativeStake(stake=%s)", stake)
+	}
+	bondDelta := account.Bonds().Delta(bonds)
+	using := new(big.Int).Set(ds.GetDelegationAmount())
+	using.Add(using, bonds.GetBondAmount())
+	using.Add(using, unbondAfterBondDelta(account.Unbonds(), bondDelta))
+	if stake.Cmp(using) < 0 {
+		return nil, icmodule.IllegalArgumentError.Errorf("Not enough voting power")
+	}
+
+	delegated, err := deltaToVotes(account.Delegations().Delta(ds))
+	if err != nil {
+		return nil, err
+	}
+	bonded, err := deltaToVotes(bondDelta)
+	if err != nil {
+		return nil, err
+	}
+
+	prepSet := es.State.GetPRepSet(cc.GetBTPContext(), cc.Revision().Value())
+	re, err := newRewardEstimator(term, prepSet, delegated, bonded)
+	if err != nil {
+		return nil, err
+	}
+
+	delegating := new(big.Int)
+	delegations, err := re.votingRewardsToJSON(ds.Iterator(), delegating)
+	if err != nil {
+		return nil, err
+	}
+	bonding := new(big.Int)
+	bondList, err := re.votingRewardsToJSON(bonds.Iterator(), bonding)
+	if err != nil {
+		return nil, err
+	}
+	voted := new(big.Int)
+	if prep := re.vInfo.GetPRepByAddress(owner); prep != nil {
+		voted.Set(prep.IScore())
+	}
+
+	iScore := new(big.Int).Add(delegating, bonding)
+	iScore.Add(iScore, voted)
+
+	jso := make(map[string]interface{})
+	jso["blockHeight"] = cc.BlockHeight()
+	jso["termPeriod"] = int64(re.period)
+	jso["delegations"] = delegations
+	jso["bonds"] = bondList
+	jso["delegating"] = delegating
+	jso["bonding"] = bonding
+	jso["voted"] = voted
+	jso["iscore"] = iScore
+	jso["estimatedICX"] = icutils.IScoreToICX(iScore)
+	return jso, nil
+}
@@ -18,6 +18,7 @@ package iiss
 
 import (
 	"bytes"
+	"encoding/json"
 	"math"
 	"math/big"
 	"sort"
@@ -50,6 +51,9 @@ type ExtensionSnapshotImpl struct {
 	back1  *icstage.Snapshot
 	back2  *icstage.Snapshot
 	reward *icreward.Snapshot
+
+	// validation is votes of validators for the block, which isn't a part of the state
+	validation *blockValidation
 }
 
 func (s *ExtensionSnapshotImpl) Back1() *icstage.Snapshot {
@@ -166,6 +170,8 @@ type ExtensionStateImpl struct {
 	log              []ExtensionLog
 	illegalDelegated map[string]*icstate.PRepStatusState
 	claimed          map[string]*Claimed
+	validation       *blockValidation
+	trackValidation  bool
 
 	State  *icstate.State
 	Front  *icstage.State
@@ -192,6 +198,8 @@ func (es *ExtensionStateImpl) GetSnapshot() state.ExtensionSnapshot {
 		back1:    es.Back1.GetSnapshot(),
 		back2:    es.Back2.GetSnapshot(),
 		reward:   es.Reward.GetSnapshot(),
+
+		validation: es.validation,
 	}
 }
 
@@ -204,6 +212,13 @@ func (es *ExtensionStateImpl) Reset(isnapshot state.ExtensionSnapshot) {
 	es.Back1.Reset(snapshot.back1)
 	es.Back2.Reset(snapshot.back2)
 	es.Reward.Reset(snapshot.reward)
+	es.validation = snapshot.validation
+}
+
+// TrackValidation sets whether votes of validators for the block are
+// captured for validation history. They aren't captured by default.
+func (es *ExtensionStateImpl) TrackValidation(on bool) {
+	es.trackValidation = on
 }
 
 // ClearCache clear cache. It's called before executing first transaction
@@ -439,7 +454,7 @@ func (es *ExtensionStateImpl) SetDelegation(
 	if icmodule.RevisionMultipleUnstakes <= revision && revision < icmodule.RevisionFixInvalidUnstake {
 		migrate.ReproduceUnstakeBugForDelegation(cc, es.logger)
 	}
-	return nil
+	return DelegationSetEventLog(cc, from, account)
 }
 
 func deltaToVotes(delta map[string]*big.Int) (votes icstage.VoteList, err error) {
@@ -1141,7 +1156,7 @@ func (es *ExtensionStateImpl) GenesisTerm(blockHeight int64, revision int) error
 func (es *ExtensionStateImpl) updateValidators(wc icmodule.WorldContext, isTermEnd bool) error {
 	var err error
 	vss := es.State.GetValidatorsSnapshot()
-	if vss == nil {
+	if vss == nil || es.State.IsFixedValidators() {
 		return nil
 	}
 
@@ -1279,7 +1294,7 @@ func (es *ExtensionStateImpl) SetStake(cc icmodule.CallContext, v *big.Int) (err
 	if icmodule.RevisionMultipleUnstakes <= revision && revision < icmodule.RevisionFixInvalidUnstake {
 		migrate.ReproduceUnstakeBugForStake(cc, es.logger)
 	}
-	return
+	return StakeSetEventLog(cc, from, ia)
 }
 
 func (es *ExtensionStateImpl) RegisterPRep(cc icmodule.CallContext, info *icstate.PRepInfo) error {
@@ -1575,6 +1590,83 @@ func ClaimEventLog(cc icmodule.CallContext, address module.Address, claim *big.I
 	}
 }
 
+// listToEventData returns JSON string of the list for event logs.
+// Integers are represented in hex as in JSON-RPC.
+func listToEventData(l []interface{}) ([]byte, error) {
+	if l == nil {
+		l = []interface{}{}
+	}
+	o, err := common.EncodeAny(l)
+	if err != nil {
+		return nil, err
+	}
+	jso, err := common.DecodeAnyForJSON(o)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jso)
+}
+
+// StakeSetEventLog records the stake and unstakes of the account after they are changed
+func StakeSetEventLog(cc icmodule.CallContext, address module.Address, account *icstate.AccountState) error {
+	if cc.Revision().Value() < icmodule.RevisionIISSStateEvents {
+		return nil
+	}
+	unstakes := make([]interface{}, len(account.UnStakes()))
+	for i, u := range account.UnStakes() {
+		unstakes[i] = map[string]interface{}{
+			"unstake":            u.Value,
+			"unstakeBlockHeight": u.Expire,
+		}
+	}
+	data, err := listToEventData(unstakes)
+	if err != nil {
+		return scoreresult.UnknownFailureError.Wrapf(err, "Failed to encode unstakes")
+	}
+	cc.OnEvent(state.SystemAddress,
+		[][]byte{[]byte("StakeSet(Address,int,str)"), address.Bytes()},
+		[][]byte{intconv.BigIntToBytes(account.Stake()), data},
+	)
+	return nil
+}
+
+// DelegationSetEventLog records all delegations of the account after they are changed
+func DelegationSetEventLog(cc icmodule.CallContext, address module.Address, account *icstate.AccountState) error {
+	if cc.Revision().Value() < icmodule.RevisionIISSStateEvents {
+		return nil
+	}
+	data, err := listToEventData(account.Delegations().ToJSON(module.JSONVersion3))
+	if err != nil {
+		return scoreresult.UnknownFailureError.Wrapf(err, "Failed to encode delegations")
+	}
+	cc.OnEvent(state.SystemAddress,
+		[][]byte{[]byte("DelegationSet(Address,str)"), address.Bytes()},
+		[][]byte{data},
+	)
+	return nil
+}
+
+// BondSetEventLog records all bonds and unbonds of the account after they are changed
+func BondSetEventLog(cc icmodule.CallContext, address module.Address, account *icstate.AccountState) error {
+	if cc.Revision().Value() < icmodule.RevisionIISSStateEvents {
+		return nil
+	}
+	bonds := account.Bonds()
+	bondsData, err := listToEventData(bonds.ToJSON(module.JSONVersion3))
+	if err != nil {
+		return scoreresult.UnknownFailureError.Wrapf(err, "Failed to encode bonds")
+	}
+	unbondsData, err := listToEventData(account.Unbonds().ToJSON(module.JSONVersion3))
+	if err != nil {
+		return scoreresult.UnknownFailureError.Wrapf(err, "Failed to encode unbonds")
+	}
+	cc.OnEvent(state.SystemAddress,
+		[][]byte{[]byte("BondSet(Address,str,str)"), address.Bytes()},
+		[][]byte{bondsData, unbondsData},
+	)
+	return nil
+}
+
 func calculateIRep(prepSet icstate.PRepSet) *big.Int {
 	irep := new(big.Int)
 	mainPRepCount := prepSet.GetPRepSize(icstate.GradeMain)
@@ -7,7 +7,14 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/icon/icmodule"
+	"github.com/icon-project/goloop/icon/iiss/icstate"
 	"github.com/icon-project/goloop/icon/iiss/icutils"
+	"github.com/icon-project/goloop/module"
 )
 
 func TestExtension_calculateRRep(t *testing.T) {
@@ -155,3 +162,94 @@ func TestExtension_validateRewardFund(t *testing.T) {
 		})
 	}
 }
+
+type eventCallContext struct {
+	icmodule.CallContext
+	revision int
+	events   [][][]byte
+}
+
+func (cc *eventCallContext) Revision() module.Revision {
+	return icmodule.ValueToRevision(cc.revision)
+}
+
+func (cc *eventCallContext) OnEvent(addr module.Address, indexed, data [][]byte) {
+	cc.events = append(cc.events, append(indexed, data...))
+}
+
+func TestExtension_IISSStateEventLogs(t *testing.T) {
+	s := icstate.NewStateFromSnapshot(icstate.NewSnapshot(db.NewMapDB(), nil), false, log.New())
+	addr := common.MustNewAddressFromString("hx01")
+	prep := common.MustNewAddressFromString("hx02")
+	account := s.GetAccountState(addr)
+	assert.NoError(t, account.SetStake(big.NewInt(100)))
+	account.SetDelegation(icstate.Delegations{icstate.NewDelegation(prep, big.NewInt(30))})
+	account.SetBonds(icstate.Bonds{icstate.NewBond(prep, big.NewInt(20))})
+
+	cc := &eventCallContext{revision: icmodule.RevisionIISSStateEvents - 1}
+	assert.NoError(t, StakeSetEventLog(cc, addr, account))
+	assert.NoError(t, DelegationSetEventLog(cc, addr, account))
+	assert.NoError(t, BondSetEventLog(cc, addr, account))
+	assert.Empty(t, cc.events)
+
+	cc.revision = icmodule.RevisionIISSStateEvents
+	assert.NoError(t, StakeSetEventLog(cc, addr, account))
+	assert.NoError(t, DelegationSetEventLog(cc, addr, account))
+	assert.NoError(t, BondSetEventLog(cc, addr, account))
+	assert.Equal(t, [][][]byte{
+		{[]byte("StakeSet(Address,int,str)"), addr.Bytes(), intconv.BigIntToBytes(big.NewInt(100)), []byte("[]")},
+		{[]byte("DelegationSet(Address,str)"), addr.Bytes(),
+			[]byte(`[{"address":"` + prep.String() + `","value":"0x1e"}]`)},
+		{[]byte("BondSet(Address,str,str)"), addr.Bytes(),
+			[]byte(`[{"address":"` + prep.String() + `","value":"0x14"}]`), []byte("[]")},
+	}, cc.events)
+}
+
+type unstakeCallContext struct {
+	eventCallContext
+	blockHeight int64
+	deposits    map[string]*big.Int
+}
+
+func (cc *unstakeCallContext) BlockHeight() int64 {
+	return cc.blockHeight
+}
+
+func (cc *unstakeCallContext) Deposit(address module.Address, amount *big.Int, opType module.OpType) error {
+	cc.deposits[address.String()] = amount
+	return nil
+}
+
+func TestExtension_handleUnstakingTimerOnBaseTx(t *testing.T) {
+	es := NewExtensionSnapshot(db.NewMapDB(), nil).NewState(false).(*ExtensionStateImpl)
+	addr := common.MustNewAddressFromString("hx01")
+	account := es.State.GetAccountState(addr)
+	assert.NoError(t, account.SetStake(big.NewInt(100)))
+	for _, u := range []*icstate.Unstake{
+		icstate.NewUnstake(big.NewInt(10), 10),
+		icstate.NewUnstake(big.NewInt(20), 20),
+	} {
+		tj, err := account.IncreaseUnstake(u.Value, u.Expire, 1000, icmodule.RevisionIISSStateEvents)
+		assert.NoError(t, err)
+		for _, j := range tj {
+			icstate.ScheduleTimerJob(es.State.GetUnstakingTimerState(j.Height), j, addr)
+		}
+	}
+
+	cc := &unstakeCallContext{
+		eventCallContext: eventCallContext{revision: icmodule.RevisionIISSStateEvents},
+		blockHeight:      10,
+		deposits:         make(map[string]*big.Int),
+	}
+	assert.NoError(t, es.handleUnstakingTimerOnBaseTx(cc))
+	assert.Equal(t, 0, big.NewInt(10).Cmp(cc.deposits[addr.String()]))
+	assert.Equal(t, [][][]byte{
+		{[]byte("StakeSet(Address,int,str)"), addr.Bytes(), intconv.BigIntToBytes(big.NewInt(100)),
+			[]byte(`[{"unstake":"0x14","unstakeBlockHeight":"0x14"}]`)},
+	}, cc.events)
+
+	// the timer job at the end of the block doesn't return it again
+	assert.NoError(t, es.handleTimerJob(cc))
+	assert.Len(t, cc.events, 1)
+	assert.Len(t, account.UnStakes(), 1)
+}
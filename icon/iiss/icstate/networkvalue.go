@@ -51,6 +51,7 @@ const (
 	VarDelegationSlotMax                     = "delegation_slot_max"
 	DictNetworkScores                        = "network_scores"
 	VarNonVotePenaltySlashRatio              = "nonvote_penalty_slashRatio"
+	VarFixedValidators                       = "fixed_validators"
 )
 
 const (
@@ -344,6 +345,16 @@ func (s *State) SetDelegationSlotMax(value int64) error {
 	return setValue(s.store, VarDelegationSlotMax, value)
 }
 
+// IsFixedValidators returns whether validators are kept regardless of
+// the terms. It's set for chains forked with new validators.
+func (s *State) IsFixedValidators() bool {
+	return getValue(s.store, VarFixedValidators).Bool()
+}
+
+func (s *State) SetFixedValidators(value bool) error {
+	return setValue(s.store, VarFixedValidators, value)
+}
+
 func (s *State) GetNonVotePenaltySlashRatio() int {
 	return int(getValue(s.store, VarNonVotePenaltySlashRatio).Int64())
 }
@@ -26,7 +26,8 @@ import (
 	"github.com/icon-project/goloop/module"
 )
 
-// Unstake is done on OnExecutionEnd of ExpireHeight
+// Unstake is done on OnExecutionEnd of ExpireHeight, or on the base transaction
+// of ExpireHeight from RevisionIISSStateEvents
 type Unstake struct {
 	Value  *big.Int `json:"unstake"`
 	Expire int64    `json:"unstakeBlockHeight"`
@@ -126,6 +126,12 @@ func (es *ExtensionStateImpl) slash(cc icmodule.CallContext, owner module.Addres
 			if err := es.AddEventBond(cc.BlockHeight(), bonder, delta); err != nil {
 				return err
 			}
+			if err := StakeSetEventLog(cc, bonder, account); err != nil {
+				return err
+			}
+			if err := BondSetEventLog(cc, bonder, account); err != nil {
+				return err
+			}
 		}
 
 		// Record Slashed eventlog
@@ -0,0 +1,153 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package iiss
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/containerdb"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/common/trie"
+	"github.com/icon-project/goloop/icon/iiss/icreward"
+	"github.com/icon-project/goloop/icon/iiss/icstage"
+)
+
+// rewardKeyNames maps prefixes of keys in icreward to the type names
+var rewardKeyNames = map[string]string{
+	string(icreward.VotedKey.Build()):           "voted",
+	string(icreward.DelegatingKey.Build()):      "delegating",
+	string(icreward.BondingKey.Build()):         "bonding",
+	string(icreward.IScoreKey.Build()):          "iscore",
+	string(icreward.BugDisabledPRepKey.Build()): "bugDisabledPRep",
+	string(icreward.PubKeyKey.Build()):          "pubKey",
+}
+
+func rewardEntryToJSON(key []byte, expected, result trie.Object) map[string]interface{} {
+	jso := map[string]interface{}{
+		"key": common.HexBytes(key),
+	}
+	if keys, err := containerdb.SplitKeys(key); err == nil && len(keys) == 2 {
+		prefix := containerdb.ToKey(containerdb.RLPBuilder, keys[0]).Build()
+		if name, ok := rewardKeyNames[string(prefix)]; ok {
+			jso["type"] = name
+		}
+		if addr, err := common.NewAddress(keys[1]); err == nil {
+			jso["address"] = addr
+		}
+	}
+	if expected != nil {
+		jso["expected"] = fmt.Sprintf("%+v", expected)
+	}
+	if result != nil {
+		jso["result"] = fmt.Sprintf("%+v", result)
+	}
+	return jso
+}
+
+// DiffRewardSnapshots returns entries which are different between
+// expected and result in order of keys.
+func DiffRewardSnapshots(expected, result *icreward.Snapshot) ([]interface{}, error) {
+	diffs := make([]interface{}, 0)
+	eIter := expected.Filter(nil)
+	rIter := result.Filter(nil)
+	for eIter.Has() || rIter.Has() {
+		var eKey, rKey []byte
+		var eObj, rObj trie.Object
+		var err error
+		if eIter.Has() {
+			if eObj, eKey, err = eIter.Get(); err != nil {
+				return nil, err
+			}
+		}
+		if rIter.Has() {
+			if rObj, rKey, err = rIter.Get(); err != nil {
+				return nil, err
+			}
+		}
+		switch {
+		case rKey == nil || (eKey != nil && bytes.Compare(eKey, rKey) < 0):
+			diffs = append(diffs, rewardEntryToJSON(eKey, eObj, nil))
+			err = eIter.Next()
+		case eKey == nil || bytes.Compare(eKey, rKey) > 0:
+			diffs = append(diffs, rewardEntryToJSON(rKey, nil, rObj))
+			err = rIter.Next()
+		default:
+			if !bytes.Equal(eObj.Bytes(), rObj.Bytes()) {
+				diffs = append(diffs, rewardEntryToJSON(eKey, eObj, rObj))
+			}
+			if err = eIter.Next(); err == nil {
+				err = rIter.Next()
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return diffs, nil
+}
+
+// Recalculate runs the calculation with back and base again on a throwaway
+// database and compares the result with the stored one of which hash is
+// expected. Nothing is written to dbase.
+func Recalculate(
+	dbase db.Database, back *icstage.Snapshot, base *icreward.Snapshot, expected []byte, logger log.Logger,
+) (map[string]interface{}, error) {
+	ldb := db.NewLayerDB(dbase)
+	back = icstage.NewSnapshot(ldb, back.Bytes())
+	base = icreward.NewSnapshot(ldb, base.Bytes())
+
+	c := NewCalculator(ldb, back, base, nil, logger)
+	if c == nil {
+		return nil, errors.InvalidStateError.New("FailToCreateCalculator")
+	}
+	if c.StartHeight() == InitBlockHeight {
+		return nil, errors.InvalidStateError.New("NoCalculation")
+	}
+	if err := c.WaitResult(c.StartHeight()); err != nil {
+		return nil, err
+	}
+	result := c.Result()
+
+	jso := map[string]interface{}{
+		"startHeight":  c.StartHeight(),
+		"iissVersion":  int64(c.global.GetIISSVersion()),
+		"blockProduce": c.stats.BlockProduce(),
+		"voted":        c.stats.Voted(),
+		"voting":       c.stats.Voting(),
+		"totalReward":  c.TotalReward(),
+		"result":       common.HexBytes(result.Bytes()),
+	}
+	if expected == nil {
+		return jso, nil
+	}
+	jso["expected"] = common.HexBytes(expected)
+	jso["match"] = bytes.Equal(expected, result.Bytes())
+	if bytes.Equal(expected, result.Bytes()) {
+		jso["differences"] = []interface{}{}
+		return jso, nil
+	}
+	diffs, err := DiffRewardSnapshots(icreward.NewSnapshot(ldb, expected), result)
+	if err != nil {
+		return nil, err
+	}
+	jso["differences"] = diffs
+	return jso, nil
+}
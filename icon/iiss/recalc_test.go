@@ -0,0 +1,67 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package iiss
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/icon/iiss/icreward"
+)
+
+func TestDiffRewardSnapshots(t *testing.T) {
+	database := db.NewMapDB()
+	addr1 := common.MustNewAddressFromString("hx01")
+	addr2 := common.MustNewAddressFromString("hx02")
+	addr3 := common.MustNewAddressFromString("hx03")
+
+	s1 := icreward.NewState(database, nil)
+	assert.NoError(t, s1.SetIScore(addr1, icreward.NewIScore(big.NewInt(100))))
+	assert.NoError(t, s1.SetIScore(addr2, icreward.NewIScore(big.NewInt(200))))
+	expected := s1.GetSnapshot()
+
+	diffs, err := DiffRewardSnapshots(expected, expected)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(diffs))
+
+	s2 := icreward.NewState(database, nil)
+	assert.NoError(t, s2.SetIScore(addr1, icreward.NewIScore(big.NewInt(100))))
+	assert.NoError(t, s2.SetIScore(addr2, icreward.NewIScore(big.NewInt(201))))
+	assert.NoError(t, s2.SetIScore(addr3, icreward.NewIScore(big.NewInt(300))))
+	result := s2.GetSnapshot()
+
+	diffs, err = DiffRewardSnapshots(expected, result)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(diffs))
+	for _, d := range diffs {
+		diff := d.(map[string]interface{})
+		assert.Equal(t, "iscore", diff["type"])
+		assert.Contains(t, diff, "result")
+		switch diff["address"].(*common.Address).String() {
+		case addr2.String():
+			assert.Contains(t, diff, "expected")
+		case addr3.String():
+			assert.NotContains(t, diff, "expected")
+		default:
+			assert.Fail(t, "unexpected difference", "diff=%+v", diff)
+		}
+	}
+}
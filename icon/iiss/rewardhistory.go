@@ -0,0 +1,580 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package iiss
+
+import (
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/containerdb"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/icon/icdb"
+	"github.com/icon-project/goloop/icon/iiss/icreward"
+	"github.com/icon-project/goloop/icon/iiss/icstage"
+	"github.com/icon-project/goloop/icon/iiss/icutils"
+	"github.com/icon-project/goloop/module"
+)
+
+const (
+	// RewardHistoryQueryMax is the maximum number of terms returned by a query
+	RewardHistoryQueryMax = 100
+	// RewardHistoryQueryDefault is the number of terms returned by a query without count
+	RewardHistoryQueryDefault = 10
+
+	rewardHistoryChunkSize = 1000
+)
+
+var (
+	rewardHistoryTermsKey    = containerdb.ToKey(containerdb.RLPBuilder, "terms").Build()
+	rewardHistoryAccountsKey = containerdb.ToKey(containerdb.RLPBuilder, "accounts")
+	rewardHistoryRewardKey   = containerdb.ToKey(containerdb.RLPBuilder, "reward")
+)
+
+// RewardHistoryConfig is the configuration of reward history.
+// Retention is the number of terms to keep. Zero means keeping all terms.
+type RewardHistoryConfig struct {
+	Enable    bool  `json:"enable"`
+	Retention int64 `json:"retention,omitempty"`
+}
+
+type rewardHistoryTerm struct {
+	StartHeight int64
+	Period      int64
+	IISSVersion int
+	Accounts    int64
+	Done        bool
+}
+
+func (t *rewardHistoryTerm) chunks() int64 {
+	return (t.Accounts + rewardHistoryChunkSize - 1) / rewardHistoryChunkSize
+}
+
+type prepReward struct {
+	Address *common.Address
+	IScore  *big.Int
+}
+
+type accountReward struct {
+	BlockProduce *big.Int
+	Voted        *big.Int
+	Delegating   *big.Int
+	Bonding      *big.Int
+	Claimed      *big.Int
+	Delegations  []*prepReward
+	Bonds        []*prepReward
+}
+
+func newAccountReward() *accountReward {
+	return &accountReward{
+		BlockProduce: new(big.Int),
+		Voted:        new(big.Int),
+		Delegating:   new(big.Int),
+		Bonding:      new(big.Int),
+		Claimed:      new(big.Int),
+	}
+}
+
+// IScore returns the amount of I-Score earned during the term
+func (ar *accountReward) IScore() *big.Int {
+	iScore := new(big.Int).Add(ar.BlockProduce, ar.Voted)
+	iScore.Add(iScore, ar.Delegating)
+	iScore.Add(iScore, ar.Bonding)
+	return iScore
+}
+
+// mergePRepRewards adds rewards to the list. Rewards of the P-Rep in the
+// list are merged into the entry of the P-Rep.
+func mergePRepRewards(rewards, added []*prepReward) []*prepReward {
+	for _, a := range added {
+		merged := false
+		for _, r := range rewards {
+			if r.Address.Equal(a.Address) {
+				r.IScore = new(big.Int).Add(r.IScore, a.IScore)
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			rewards = append(rewards, &prepReward{
+				Address: a.Address,
+				IScore:  new(big.Int).Set(a.IScore),
+			})
+		}
+	}
+	return rewards
+}
+
+func prepRewardsToJSON(rewards []*prepReward) []interface{} {
+	jso := make([]interface{}, len(rewards))
+	for i, r := range rewards {
+		jso[i] = map[string]interface{}{
+			"address": r.Address,
+			"iscore":  r.IScore,
+		}
+	}
+	return jso
+}
+
+func (ar *accountReward) ToJSON(term *rewardHistoryTerm) map[string]interface{} {
+	return map[string]interface{}{
+		"startHeight":  term.StartHeight,
+		"period":       term.Period,
+		"iissVersion":  int64(term.IISSVersion),
+		"blockProduce": ar.BlockProduce,
+		"voted":        ar.Voted,
+		"delegating":   ar.Delegating,
+		"bonding":      ar.Bonding,
+		"delegations":  prepRewardsToJSON(ar.Delegations),
+		"bonds":        prepRewardsToJSON(ar.Bonds),
+		"claimed":      ar.Claimed,
+		"iscore":       ar.IScore(),
+	}
+}
+
+// votingDetail accumulates voting rewards of an account by P-Rep
+type votingDetail struct {
+	rewards []*prepReward
+	index   map[string]int
+}
+
+func (vd *votingDetail) add(prep module.Address, reward *big.Int) {
+	if vd == nil {
+		return
+	}
+	key := icutils.ToKey(prep)
+	if i, ok := vd.index[key]; ok {
+		vd.rewards[i].IScore.Add(vd.rewards[i].IScore, reward)
+		return
+	}
+	vd.index[key] = len(vd.rewards)
+	vd.rewards = append(vd.rewards, &prepReward{
+		Address: common.AddressToPtr(prep),
+		IScore:  new(big.Int).Set(reward),
+	})
+}
+
+func (vd *votingDetail) sub(prep module.Address, reward *big.Int) {
+	vd.add(prep, new(big.Int).Neg(reward))
+}
+
+// Rewards returns rewards by P-Rep except zero ones
+func (vd *votingDetail) Rewards() []*prepReward {
+	if vd == nil {
+		return nil
+	}
+	rewards := make([]*prepReward, 0, len(vd.rewards))
+	for _, r := range vd.rewards {
+		if r.IScore.Sign() != 0 {
+			rewards = append(rewards, r)
+		}
+	}
+	return rewards
+}
+
+// RewardHistory keeps rewards of accounts for each term in a separate bucket.
+// It's filled by Calculator and it's not a part of the state.
+type RewardHistory struct {
+	lock      sync.Mutex
+	bucket    db.Bucket
+	retention int64
+	active    map[int64]bool
+	log       log.Logger
+}
+
+func (h *RewardHistory) Retention() int64 {
+	return h.retention
+}
+
+func (h *RewardHistory) getTerms() ([]*rewardHistoryTerm, error) {
+	bs, err := h.bucket.Get(rewardHistoryTermsKey)
+	if err != nil || bs == nil {
+		return nil, err
+	}
+	var terms []*rewardHistoryTerm
+	if _, err = codec.BC.UnmarshalFromBytes(bs, &terms); err != nil {
+		return nil, err
+	}
+	return terms, nil
+}
+
+func (h *RewardHistory) setTerms(terms []*rewardHistoryTerm) error {
+	bs, err := codec.BC.MarshalToBytes(terms)
+	if err != nil {
+		return err
+	}
+	return h.bucket.Set(rewardHistoryTermsKey, bs)
+}
+
+func (h *RewardHistory) getAccountReward(addr module.Address, height int64) (*accountReward, error) {
+	bs, err := h.bucket.Get(rewardHistoryRewardKey.Append(addr, height).Build())
+	if err != nil || bs == nil {
+		return nil, err
+	}
+	ar := new(accountReward)
+	if _, err = codec.BC.UnmarshalFromBytes(bs, ar); err != nil {
+		return nil, err
+	}
+	return ar, nil
+}
+
+func (h *RewardHistory) setAccountReward(addr module.Address, height int64, ar *accountReward) error {
+	bs, err := codec.BC.MarshalToBytes(ar)
+	if err != nil {
+		return err
+	}
+	return h.bucket.Set(rewardHistoryRewardKey.Append(addr, height).Build(), bs)
+}
+
+func (h *RewardHistory) getAccounts(height, chunk int64) ([]*common.Address, error) {
+	bs, err := h.bucket.Get(rewardHistoryAccountsKey.Append(height, chunk).Build())
+	if err != nil || bs == nil {
+		return nil, err
+	}
+	var accounts []*common.Address
+	if _, err = codec.BC.UnmarshalFromBytes(bs, &accounts); err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+func (h *RewardHistory) setAccounts(height, chunk int64, accounts []*common.Address) error {
+	bs, err := codec.BC.MarshalToBytes(accounts)
+	if err != nil {
+		return err
+	}
+	return h.bucket.Set(rewardHistoryAccountsKey.Append(height, chunk).Build(), bs)
+}
+
+// Begin returns a recorder for the term of global. It returns nil if
+// the term is already recorded or it's being recorded by another one.
+func (h *RewardHistory) Begin(global icstage.Global) (*rewardRecorder, error) {
+	if h == nil || global == nil {
+		return nil, nil
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	height := global.GetStartHeight()
+	if h.active[height] {
+		return nil, nil
+	}
+	terms, err := h.getTerms()
+	if err != nil {
+		return nil, err
+	}
+	var term *rewardHistoryTerm
+	for _, t := range terms {
+		if t.StartHeight == height {
+			term = t
+			break
+		}
+	}
+	if term != nil && term.Done {
+		return nil, nil
+	}
+	// remove data left by the interrupted recording
+	if err = h.removeAccounts(height, -1); err != nil {
+		return nil, err
+	}
+	h.active[height] = true
+	return &rewardRecorder{
+		history: h,
+		term: &rewardHistoryTerm{
+			StartHeight: height,
+			Period:      int64(global.GetTermPeriod()),
+			IISSVersion: global.GetIISSVersion(),
+		},
+		touched: make(map[string]bool),
+		pending: make(map[string]*accountReward),
+	}, nil
+}
+
+func (h *RewardHistory) finish(r *rewardRecorder) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	defer delete(h.active, r.term.StartHeight)
+	if r.err != nil {
+		return r.err
+	}
+	if err := r.flushAccounts(); err != nil {
+		return err
+	}
+	terms, err := h.getTerms()
+	if err != nil {
+		return err
+	}
+	newTerms := make([]*rewardHistoryTerm, 0, len(terms)+1)
+	for _, t := range terms {
+		if t.StartHeight != r.term.StartHeight {
+			newTerms = append(newTerms, t)
+		}
+	}
+	r.term.Done = true
+	newTerms = append(newTerms, r.term)
+	sort.Slice(newTerms, func(i, j int) bool {
+		return newTerms[i].StartHeight < newTerms[j].StartHeight
+	})
+	if h.retention > 0 && int64(len(newTerms)) > h.retention {
+		pruned := newTerms[:int64(len(newTerms))-h.retention]
+		newTerms = newTerms[len(pruned):]
+		// update the term list first not to expose pruning terms
+		if err = h.setTerms(newTerms); err != nil {
+			return err
+		}
+		for _, t := range pruned {
+			if err = h.prune(t); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return h.setTerms(newTerms)
+}
+
+func (h *RewardHistory) abort(r *rewardRecorder) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	delete(h.active, r.term.StartHeight)
+}
+
+func (h *RewardHistory) prune(term *rewardHistoryTerm) error {
+	h.log.Debugf("Prune reward history of term(start=%d,accounts=%d)", term.StartHeight, term.Accounts)
+	return h.removeAccounts(term.StartHeight, term.chunks())
+}
+
+// removeAccounts removes chunks of accounts of the term and rewards of the
+// accounts in them. Negative chunks removes all chunks in the bucket.
+func (h *RewardHistory) removeAccounts(height, chunks int64) error {
+	for chunk := int64(0); chunks < 0 || chunk < chunks; chunk++ {
+		accounts, err := h.getAccounts(height, chunk)
+		if err != nil {
+			return err
+		}
+		if accounts == nil && chunks < 0 {
+			return nil
+		}
+		for _, addr := range accounts {
+			key := rewardHistoryRewardKey.Append(addr, height).Build()
+			if err = h.bucket.Delete(key); err != nil {
+				return err
+			}
+		}
+		if err = h.bucket.Delete(rewardHistoryAccountsKey.Append(height, chunk).Build()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetRewardHistory returns rewards of the account for terms starting at
+// startHeight or later in ascending order. Zero count returns
+// RewardHistoryQueryDefault terms.
+func (h *RewardHistory) GetRewardHistory(addr module.Address, startHeight int64, count int) (map[string]interface{}, error) {
+	if count == 0 {
+		count = RewardHistoryQueryDefault
+	}
+	if count < 0 || count > RewardHistoryQueryMax {
+		return nil, errors.IllegalArgumentError.Errorf("InvalidCount(count=%d)", count)
+	}
+	terms, err := h.getTerms()
+	if err != nil {
+		return nil, err
+	}
+	rewards := make([]interface{}, 0, count)
+	for _, t := range terms {
+		if len(rewards) >= count {
+			break
+		}
+		if t.StartHeight < startHeight || !t.Done {
+			continue
+		}
+		ar, err := h.getAccountReward(addr, t.StartHeight)
+		if err != nil {
+			return nil, err
+		}
+		if ar != nil {
+			rewards = append(rewards, ar.ToJSON(t))
+		}
+	}
+	jso := map[string]interface{}{
+		"address":   addr,
+		"retention": h.retention,
+		"terms":     rewards,
+	}
+	if len(terms) > 0 {
+		jso["firstHeight"] = terms[0].StartHeight
+		jso["lastHeight"] = terms[len(terms)-1].StartHeight
+	}
+	return jso, nil
+}
+
+func NewRewardHistory(dbase db.Database, retention int64, logger log.Logger) (*RewardHistory, error) {
+	if retention < 0 {
+		return nil, errors.IllegalArgumentError.Errorf("InvalidRetention(retention=%d)", retention)
+	}
+	bk, err := dbase.GetBucket(icdb.RewardHistory)
+	if err != nil {
+		return nil, err
+	}
+	return &RewardHistory{
+		bucket:    bk,
+		retention: retention,
+		active:    make(map[int64]bool),
+		log:       logger,
+	}, nil
+}
+
+// rewardRecorder records rewards of a term which are calculated by Calculator.
+// Failure of recording doesn't affect calculation, so it keeps the first error
+// and ignores following records.
+//
+// Rewards of accounts in the current chunk are kept in pending until the
+// chunk is written, so rewards in the bucket are always listed in chunks,
+// and they can be removed with the chunks.
+type rewardRecorder struct {
+	history  *RewardHistory
+	term     *rewardHistoryTerm
+	touched  map[string]bool
+	pending  map[string]*accountReward
+	accounts []*common.Address
+	chunk    int64
+	err      error
+}
+
+func (r *rewardRecorder) flushAccounts() error {
+	if len(r.accounts) == 0 {
+		return nil
+	}
+	if err := r.history.setAccounts(r.term.StartHeight, r.chunk, r.accounts); err != nil {
+		return err
+	}
+	for _, addr := range r.accounts {
+		if ar, ok := r.pending[icutils.ToKey(addr)]; ok {
+			if err := r.history.setAccountReward(addr, r.term.StartHeight, ar); err != nil {
+				return err
+			}
+		}
+	}
+	if len(r.accounts) >= rewardHistoryChunkSize {
+		r.chunk++
+		r.accounts = nil
+		r.pending = make(map[string]*accountReward)
+	}
+	return nil
+}
+
+func (r *rewardRecorder) update(addr module.Address, f func(ar *accountReward)) {
+	if r == nil || r.err != nil {
+		return
+	}
+	key := icutils.ToKey(addr)
+	if ar, ok := r.pending[key]; ok {
+		f(ar)
+		return
+	}
+	if !r.touched[key] {
+		ar := newAccountReward()
+		r.touched[key] = true
+		r.term.Accounts++
+		r.accounts = append(r.accounts, common.AddressToPtr(addr))
+		r.pending[key] = ar
+		f(ar)
+		if len(r.accounts) >= rewardHistoryChunkSize {
+			r.err = r.flushAccounts()
+		}
+		return
+	}
+	var ar *accountReward
+	if ar, r.err = r.history.getAccountReward(addr, r.term.StartHeight); r.err != nil {
+		return
+	}
+	if ar == nil {
+		ar = newAccountReward()
+	}
+	f(ar)
+	r.err = r.history.setAccountReward(addr, r.term.StartHeight, ar)
+}
+
+func (r *rewardRecorder) AddClaim(addr module.Address, claimed *big.Int) {
+	r.update(addr, func(ar *accountReward) {
+		ar.Claimed.Add(ar.Claimed, claimed)
+	})
+}
+
+func (r *rewardRecorder) AddReward(addr module.Address, t RewardType, reward *big.Int) {
+	if reward.Sign() == 0 {
+		return
+	}
+	r.update(addr, func(ar *accountReward) {
+		switch t {
+		case TypeBlockProduce:
+			ar.BlockProduce.Add(ar.BlockProduce, reward)
+		case TypeVoted:
+			ar.Voted.Add(ar.Voted, reward)
+		}
+	})
+}
+
+// AddVoting records voting reward of addr. Voting type is one of
+// icreward.TypeDelegating and icreward.TypeBonding.
+func (r *rewardRecorder) AddVoting(addr module.Address, _type int, reward *big.Int, detail *votingDetail) {
+	if reward.Sign() == 0 {
+		return
+	}
+	r.update(addr, func(ar *accountReward) {
+		switch _type {
+		case icreward.TypeDelegating:
+			ar.Delegating.Add(ar.Delegating, reward)
+			ar.Delegations = mergePRepRewards(ar.Delegations, detail.Rewards())
+		case icreward.TypeBonding:
+			ar.Bonding.Add(ar.Bonding, reward)
+			ar.Bonds = mergePRepRewards(ar.Bonds, detail.Rewards())
+		}
+	})
+}
+
+// NewVotingDetail returns votingDetail to collect voting rewards by P-Rep.
+// It returns nil if it doesn't need to record.
+func (r *rewardRecorder) NewVotingDetail() *votingDetail {
+	if r == nil {
+		return nil
+	}
+	return &votingDetail{index: make(map[string]int)}
+}
+
+// Finish marks the term as recorded and prunes old terms.
+func (r *rewardRecorder) Finish() error {
+	if r == nil {
+		return nil
+	}
+	return r.history.finish(r)
+}
+
+// Abort releases the term without marking it as recorded.
+func (r *rewardRecorder) Abort() {
+	if r == nil {
+		return
+	}
+	r.history.abort(r)
+}
@@ -0,0 +1,184 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package iiss
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/icon/iiss/icreward"
+	"github.com/icon-project/goloop/icon/iiss/icstage"
+	"github.com/icon-project/goloop/icon/iiss/icstate"
+)
+
+func newTestGlobal(startHeight int64) icstage.Global {
+	return icstage.NewGlobalV2(
+		icstate.IISSVersion3, startHeight, 99, 0,
+		big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0),
+		22, 5,
+	)
+}
+
+func recordTestTerm(t *testing.T, h *RewardHistory, startHeight int64, prep, voter *common.Address) {
+	r, err := h.Begin(newTestGlobal(startHeight))
+	assert.NoError(t, err)
+	assert.NotNil(t, r)
+
+	r.AddClaim(voter, big.NewInt(10))
+	r.AddReward(prep, TypeVoted, big.NewInt(100))
+	detail := r.NewVotingDetail()
+	detail.add(prep, big.NewInt(30))
+	detail.add(common.MustNewAddressFromString("hx03"), big.NewInt(20))
+	detail.sub(prep, big.NewInt(10))
+	r.AddVoting(voter, icreward.TypeDelegating, big.NewInt(40), detail)
+	assert.NoError(t, r.Finish())
+}
+
+func TestRewardHistory(t *testing.T) {
+	h, err := NewRewardHistory(db.NewMapDB(), 2, log.New())
+	assert.NoError(t, err)
+
+	prep := common.MustNewAddressFromString("hx01")
+	voter := common.MustNewAddressFromString("hx02")
+	recordTestTerm(t, h, 100, prep, voter)
+
+	// recorded term is not recorded again
+	r, err := h.Begin(newTestGlobal(100))
+	assert.NoError(t, err)
+	assert.Nil(t, r)
+
+	jso, err := h.GetRewardHistory(voter, 0, RewardHistoryQueryDefault)
+	assert.NoError(t, err)
+	terms := jso["terms"].([]interface{})
+	assert.Equal(t, 1, len(terms))
+	term := terms[0].(map[string]interface{})
+	assert.Equal(t, int64(100), term["startHeight"])
+	assert.Equal(t, int64(100), term["period"])
+	assert.Zero(t, big.NewInt(40).Cmp(term["delegating"].(*big.Int)))
+	assert.Zero(t, big.NewInt(10).Cmp(term["claimed"].(*big.Int)))
+	assert.Zero(t, big.NewInt(40).Cmp(term["iscore"].(*big.Int)))
+	delegations := term["delegations"].([]interface{})
+	assert.Equal(t, 2, len(delegations))
+	assert.Equal(t, prep, delegations[0].(map[string]interface{})["address"])
+	assert.Zero(t, big.NewInt(20).Cmp(delegations[0].(map[string]interface{})["iscore"].(*big.Int)))
+
+	jso, err = h.GetRewardHistory(prep, 0, RewardHistoryQueryDefault)
+	assert.NoError(t, err)
+	terms = jso["terms"].([]interface{})
+	assert.Equal(t, 1, len(terms))
+	assert.Zero(t, big.NewInt(100).Cmp(terms[0].(map[string]interface{})["voted"].(*big.Int)))
+
+	// aborted term is recorded from the start on the next try
+	r, err = h.Begin(newTestGlobal(200))
+	assert.NoError(t, err)
+	r.AddClaim(voter, big.NewInt(1000))
+	r.Abort()
+	recordTestTerm(t, h, 200, prep, voter)
+	recordTestTerm(t, h, 300, prep, voter)
+
+	// the oldest term is pruned with retention
+	jso, err = h.GetRewardHistory(voter, 0, RewardHistoryQueryDefault)
+	assert.NoError(t, err)
+	terms = jso["terms"].([]interface{})
+	assert.Equal(t, 2, len(terms))
+	assert.Equal(t, int64(200), terms[0].(map[string]interface{})["startHeight"])
+	assert.Zero(t, big.NewInt(10).Cmp(terms[0].(map[string]interface{})["claimed"].(*big.Int)))
+	assert.Equal(t, int64(300), terms[1].(map[string]interface{})["startHeight"])
+	ar, err := h.getAccountReward(voter, 100)
+	assert.NoError(t, err)
+	assert.Nil(t, ar)
+
+	jso, err = h.GetRewardHistory(voter, 201, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(jso["terms"].([]interface{})))
+
+	_, err = h.GetRewardHistory(voter, 0, RewardHistoryQueryMax+1)
+	assert.Error(t, err)
+}
+
+func TestRewardHistory_MergeVotings(t *testing.T) {
+	h, err := NewRewardHistory(db.NewMapDB(), 0, log.New())
+	assert.NoError(t, err)
+
+	prep := common.MustNewAddressFromString("hx01")
+	prep2 := common.MustNewAddressFromString("hx03")
+	voter := common.MustNewAddressFromString("hx02")
+	r, err := h.Begin(newTestGlobal(100))
+	assert.NoError(t, err)
+	detail := r.NewVotingDetail()
+	detail.add(prep, big.NewInt(30))
+	r.AddVoting(voter, icreward.TypeDelegating, big.NewInt(30), detail)
+	detail = r.NewVotingDetail()
+	detail.add(prep2, big.NewInt(20))
+	detail.add(prep, big.NewInt(10))
+	r.AddVoting(voter, icreward.TypeDelegating, big.NewInt(30), detail)
+	assert.NoError(t, r.Finish())
+
+	ar, err := h.getAccountReward(voter, 100)
+	assert.NoError(t, err)
+	assert.Zero(t, big.NewInt(60).Cmp(ar.Delegating))
+	assert.Len(t, ar.Delegations, 2)
+	assert.True(t, prep.Equal(ar.Delegations[0].Address))
+	assert.Zero(t, big.NewInt(40).Cmp(ar.Delegations[0].IScore))
+	assert.True(t, prep2.Equal(ar.Delegations[1].Address))
+	assert.Zero(t, big.NewInt(20).Cmp(ar.Delegations[1].IScore))
+}
+
+func TestRewardHistory_Interrupted(t *testing.T) {
+	h, err := NewRewardHistory(db.NewMapDB(), 0, log.New())
+	assert.NoError(t, err)
+
+	// the first chunk is written before it's interrupted
+	r, err := h.Begin(newTestGlobal(100))
+	assert.NoError(t, err)
+	accounts := make([]*common.Address, rewardHistoryChunkSize+1)
+	for i := range accounts {
+		accounts[i] = common.MustNewAddressFromString(fmt.Sprintf("hx%040x", i+0x100))
+		r.AddClaim(accounts[i], big.NewInt(1))
+	}
+	assert.NoError(t, r.err)
+	r.Abort()
+	ar, err := h.getAccountReward(accounts[0], 100)
+	assert.NoError(t, err)
+	assert.NotNil(t, ar)
+	ar, err = h.getAccountReward(accounts[rewardHistoryChunkSize], 100)
+	assert.NoError(t, err)
+	assert.Nil(t, ar)
+
+	// data of the interrupted recording is removed on the next try
+	prep := common.MustNewAddressFromString("hx01")
+	voter := common.MustNewAddressFromString("hx02")
+	recordTestTerm(t, h, 100, prep, voter)
+	ar, err = h.getAccountReward(accounts[0], 100)
+	assert.NoError(t, err)
+	assert.Nil(t, ar)
+	chunk, err := h.getAccounts(100, 1)
+	assert.NoError(t, err)
+	assert.Nil(t, chunk)
+	jso, err := h.GetRewardHistory(accounts[0], 0, RewardHistoryQueryDefault)
+	assert.NoError(t, err)
+	assert.Len(t, jso["terms"], 0)
+	jso, err = h.GetRewardHistory(voter, 0, RewardHistoryQueryDefault)
+	assert.NoError(t, err)
+	assert.Len(t, jso["terms"], 1)
+}
@@ -45,25 +45,58 @@ func (es *ExtensionStateImpl) handleUnstakingTimer(wc icmodule.WorldContext, ts
 	es.logger.Tracef("handleUnstakingTimer() start: bh=%d", h)
 	for itr := ts.Iterator(); itr.Has(); itr.Next() {
 		a, _ := itr.Get()
-		ea := es.State.GetAccountState(a)
-		es.logger.Tracef("account %s: %s", a, ea)
-		ra, err := ea.RemoveUnstake(h)
+		if _, err := es.returnUnstake(wc, a, h); err != nil {
+			return err
+		}
+	}
+	es.logger.Tracef("handleUnstakingTimer() end")
+	return nil
+}
+
+// handleUnstakingTimerOnBaseTx returns the expired unstakes in the base
+// transaction, so that StakeSet events are recorded in its receipt.
+// Handled accounts are removed from the timer, then handleTimerJob
+// doesn't handle them again at the end of the block.
+func (es *ExtensionStateImpl) handleUnstakingTimerOnBaseTx(cc icmodule.CallContext) error {
+	h := cc.BlockHeight()
+	es.logger.Tracef("handleUnstakingTimerOnBaseTx() start: bh=%d", h)
+	ts := es.State.GetUnstakingTimerState(h)
+	for itr := ts.GetSnapshot().Iterator(); itr.Has(); itr.Next() {
+		a, _ := itr.Get()
+		ea, err := es.returnUnstake(cc, a, h)
 		if err != nil {
 			return err
 		}
-		if err = wc.Deposit(a, ra, module.Unstake); err != nil {
+		ts.Delete(a)
+		if err = StakeSetEventLog(cc, a, ea); err != nil {
 			return err
 		}
-		blockHeight := wc.BlockHeight()
-		es.logger.Tracef(
-			"after remove unstake, stake information of %s : %s",
-			a, ea.GetStakeInJSON(blockHeight),
-		)
 	}
-	es.logger.Tracef("handleUnstakingTimer() end")
+	es.logger.Tracef("handleUnstakingTimerOnBaseTx() end")
 	return nil
 }
 
+// returnUnstake returns the unstakes of the account expired at the height
+// to its balance.
+func (es *ExtensionStateImpl) returnUnstake(
+	wc icmodule.WorldContext, a module.Address, h int64,
+) (*icstate.AccountState, error) {
+	ea := es.State.GetAccountState(a)
+	es.logger.Tracef("account %s: %s", a, ea)
+	ra, err := ea.RemoveUnstake(h)
+	if err != nil {
+		return nil, err
+	}
+	if err = wc.Deposit(a, ra, module.Unstake); err != nil {
+		return nil, err
+	}
+	es.logger.Tracef(
+		"after remove unstake, stake information of %s : %s",
+		a, ea.GetStakeInJSON(wc.BlockHeight()),
+	)
+	return ea, nil
+}
+
 func (es *ExtensionStateImpl) handleUnbondingTimer(ts *icstate.TimerSnapshot, h int64) error {
 	es.logger.Tracef("handleUnbondingTimer() start: bh=%d", h)
 	for itr := ts.Iterator(); itr.Has(); itr.Next() {
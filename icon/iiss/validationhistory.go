@@ -0,0 +1,438 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package iiss
+
+import (
+	"sync"
+
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/containerdb"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/icon/icdb"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/state"
+)
+
+const (
+	// ValidationHistorySizeDefault is the number of blocks kept for each validator by default
+	ValidationHistorySizeDefault = 1000
+	// ValidationHistorySizeMax is the maximum number of blocks kept for each validator
+	ValidationHistorySizeMax = 10000
+)
+
+var (
+	validationHistoryLastKey   = containerdb.ToKey(containerdb.RLPBuilder, "last").Build()
+	validationHistoryRecordKey = containerdb.ToKey(containerdb.RLPBuilder, "validator")
+)
+
+// ValidationHistoryConfig is the configuration of validation history.
+// Size is the number of recent blocks to keep for each validator.
+type ValidationHistoryConfig struct {
+	Enable bool  `json:"enable"`
+	Size   int64 `json:"size,omitempty"`
+}
+
+// validationVote is a vote of a validator for a block, which is captured on
+// handling consensus information of the block.
+type validationVote struct {
+	owner     module.Address
+	voted     bool
+	failCont  int64
+	penalized bool
+}
+
+// blockValidation is votes of validators for a block. It's kept in the
+// extension state until the block is finalized.
+type blockValidation struct {
+	height int64
+	votes  []validationVote
+}
+
+func newBlockValidation(height int64, size int) *blockValidation {
+	return &blockValidation{
+		height: height,
+		votes:  make([]validationVote, 0, size),
+	}
+}
+
+func (bv *blockValidation) add(owner module.Address, voted bool, failCont int64, penalized bool) {
+	bv.votes = append(bv.votes, validationVote{owner, voted, failCont, penalized})
+}
+
+// validationHistoryHolder is implemented by the contract manager of the
+// platform keeping validation history.
+type validationHistoryHolder interface {
+	ValidationHistory() *ValidationHistory
+}
+
+// IsValidationTracked returns whether the contract manager keeps validation
+// history, so votes of validators need to be captured.
+func IsValidationTracked(cm interface{}) bool {
+	if h, ok := cm.(validationHistoryHolder); ok {
+		return h.ValidationHistory() != nil
+	}
+	return false
+}
+
+// validationRecord is a ring of recent votes of a validator.
+// Block at height h is kept at bit (h % Size) of Present and Voted.
+type validationRecord struct {
+	Size        int64
+	StartHeight int64
+	LastHeight  int64
+	FailCont    int64
+	Present     []byte
+	Voted       []byte
+	Penalties   []int64
+}
+
+func newValidationRecord(size, height int64) *validationRecord {
+	n := (size + 7) / 8
+	return &validationRecord{
+		Size:        size,
+		StartHeight: height,
+		LastHeight:  height - 1,
+		Present:     make([]byte, n),
+		Voted:       make([]byte, n),
+	}
+}
+
+func (r *validationRecord) bit(bs []byte, height int64) bool {
+	idx := height % r.Size
+	return bs[idx/8]&(1<<(idx%8)) != 0
+}
+
+func (r *validationRecord) setBit(bs []byte, height int64, on bool) {
+	idx := height % r.Size
+	if on {
+		bs[idx/8] |= 1 << (idx % 8)
+	} else {
+		bs[idx/8] &^= 1 << (idx % 8)
+	}
+}
+
+func (r *validationRecord) has(height int64) bool {
+	return height >= r.StartHeight && height <= r.LastHeight && r.bit(r.Present, height)
+}
+
+func (r *validationRecord) missed(height int64) bool {
+	return r.has(height) && !r.bit(r.Voted, height)
+}
+
+func (r *validationRecord) penalized(height int64) bool {
+	for _, p := range r.Penalties {
+		if p == height {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *validationRecord) add(height int64, v *validationVote) {
+	for h := r.LastHeight + 1; h < height; h++ {
+		r.setBit(r.Present, h, false)
+	}
+	r.setBit(r.Present, height, true)
+	r.setBit(r.Voted, height, v.voted)
+	r.LastHeight = height
+	r.FailCont = v.failCont
+	if start := height - r.Size + 1; r.StartHeight < start {
+		r.StartHeight = start
+	}
+	penalties := r.Penalties[:0]
+	for _, p := range r.Penalties {
+		if p >= r.StartHeight {
+			penalties = append(penalties, p)
+		}
+	}
+	if v.penalized {
+		penalties = append(penalties, height)
+	}
+	r.Penalties = penalties
+}
+
+type validationStreak struct {
+	start, end, count int64
+}
+
+// streaks returns consecutive failures in the window. Blocks without the
+// validator don't break a streak, but a penalty does as it resets the
+// counter of consecutive failures.
+func (r *validationRecord) streaks() []*validationStreak {
+	var streaks []*validationStreak
+	var cur *validationStreak
+	for h := r.StartHeight; h <= r.LastHeight; h++ {
+		if !r.has(h) {
+			continue
+		}
+		if !r.missed(h) {
+			cur = nil
+			continue
+		}
+		if cur == nil {
+			cur = &validationStreak{start: h}
+			streaks = append(streaks, cur)
+		}
+		cur.end = h
+		cur.count += 1
+		if r.penalized(h) {
+			cur = nil
+		}
+	}
+	return streaks
+}
+
+func (r *validationRecord) ToJSON(threshold int64) map[string]interface{} {
+	var total, failed int64
+	failedHeights := make([]interface{}, 0)
+	for h := r.StartHeight; h <= r.LastHeight; h++ {
+		if !r.has(h) {
+			continue
+		}
+		total += 1
+		if r.missed(h) {
+			failed += 1
+			failedHeights = append(failedHeights, h)
+		}
+	}
+	streaks := r.streaks()
+	var maxFailCont int64
+	streaksJSON := make([]interface{}, len(streaks))
+	for i, s := range streaks {
+		if s.count > maxFailCont {
+			maxFailCont = s.count
+		}
+		streaksJSON[i] = map[string]interface{}{
+			"startHeight": s.start,
+			"endHeight":   s.end,
+			"count":       s.count,
+		}
+	}
+	penalties := make([]interface{}, len(r.Penalties))
+	for i, p := range r.Penalties {
+		penalties[i] = p
+	}
+	jso := map[string]interface{}{
+		"size":          r.Size,
+		"startHeight":   r.StartHeight,
+		"lastHeight":    r.LastHeight,
+		"total":         total,
+		"failed":        failed,
+		"failCont":      r.FailCont,
+		"maxFailCont":   maxFailCont,
+		"failedHeights": failedHeights,
+		"streaks":       streaksJSON,
+		"penalties":     penalties,
+	}
+	if threshold > 0 {
+		jso["alerts"] = r.alerts(streaks, threshold)
+	}
+	return jso
+}
+
+// alerts returns heights where consecutive failures reached the threshold.
+// The streak at the beginning of the window may be started before the window,
+// so the number of failures before the window is counted with FailCont if
+// the streak is still going on.
+func (r *validationRecord) alerts(streaks []*validationStreak, threshold int64) []interface{} {
+	alerts := make([]interface{}, 0)
+	for i, s := range streaks {
+		offset := int64(0)
+		if i == len(streaks)-1 && s.end == r.LastHeight && r.FailCont > s.count {
+			offset = r.FailCont - s.count
+		}
+		if offset >= threshold {
+			continue
+		}
+		if s.count+offset < threshold {
+			continue
+		}
+		n := offset
+		for h := s.start; h <= s.end; h++ {
+			if r.missed(h) {
+				n += 1
+				if n == threshold {
+					alerts = append(alerts, h)
+					break
+				}
+			}
+		}
+	}
+	return alerts
+}
+
+// ValidationHistory keeps recent votes of validators in a separate bucket.
+// It's filled on finalization of blocks and it's not a part of the state.
+type ValidationHistory struct {
+	lock   sync.Mutex
+	bucket db.Bucket
+	size   int64
+	log    log.Logger
+}
+
+func (h *ValidationHistory) Size() int64 {
+	return h.size
+}
+
+func (h *ValidationHistory) getLastHeight() (int64, error) {
+	bs, err := h.bucket.Get(validationHistoryLastKey)
+	if err != nil || bs == nil {
+		return 0, err
+	}
+	var height int64
+	if _, err = codec.BC.UnmarshalFromBytes(bs, &height); err != nil {
+		return 0, err
+	}
+	return height, nil
+}
+
+func (h *ValidationHistory) setLastHeight(height int64) error {
+	bs, err := codec.BC.MarshalToBytes(height)
+	if err != nil {
+		return err
+	}
+	return h.bucket.Set(validationHistoryLastKey, bs)
+}
+
+func (h *ValidationHistory) getRecord(owner module.Address) (*validationRecord, error) {
+	bs, err := h.bucket.Get(validationHistoryRecordKey.Append(owner).Build())
+	if err != nil || bs == nil {
+		return nil, err
+	}
+	r := new(validationRecord)
+	if _, err = codec.BC.UnmarshalFromBytes(bs, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (h *ValidationHistory) setRecord(owner module.Address, r *validationRecord) error {
+	bs, err := codec.BC.MarshalToBytes(r)
+	if err != nil {
+		return err
+	}
+	return h.bucket.Set(validationHistoryRecordKey.Append(owner).Build(), bs)
+}
+
+func (h *ValidationHistory) record(bv *blockValidation) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	last, err := h.getLastHeight()
+	if err != nil {
+		return err
+	}
+	if bv.height <= last {
+		return nil
+	}
+	for i := range bv.votes {
+		v := &bv.votes[i]
+		r, err := h.getRecord(v.owner)
+		if err != nil {
+			return err
+		}
+		if r == nil || r.Size != h.size || bv.height-r.LastHeight >= h.size {
+			r = newValidationRecord(h.size, bv.height)
+		} else if bv.height <= r.LastHeight {
+			continue
+		}
+		r.add(bv.height, v)
+		if err = h.setRecord(v.owner, r); err != nil {
+			return err
+		}
+	}
+	return h.setLastHeight(bv.height)
+}
+
+// OnSnapshotFinalization records votes of validators for the block
+// if the snapshot has them.
+func (h *ValidationHistory) OnSnapshotFinalization(ess state.ExtensionSnapshot) {
+	if h == nil {
+		return
+	}
+	s, ok := ess.(*ExtensionSnapshotImpl)
+	if !ok || s.validation == nil {
+		return
+	}
+	if err := h.record(s.validation); err != nil {
+		h.log.Warnf("Fail to record validation height=%d err=%+v", s.validation.height, err)
+	}
+}
+
+// GetValidationHistory returns recent votes of the validator.
+// With positive threshold, it also returns heights where consecutive
+// failures reached the threshold.
+func (h *ValidationHistory) GetValidationHistory(owner module.Address, threshold int64) (map[string]interface{}, error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	return h.getValidationHistory(owner, threshold)
+}
+
+func (h *ValidationHistory) getValidationHistory(owner module.Address, threshold int64) (map[string]interface{}, error) {
+	if threshold < 0 {
+		return nil, errors.IllegalArgumentError.Errorf("InvalidThreshold(threshold=%d)", threshold)
+	}
+	r, err := h.getRecord(owner)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		r = newValidationRecord(h.size, 1)
+		r.LastHeight = 0
+	}
+	jso := r.ToJSON(threshold)
+	jso["address"] = owner
+	return jso, nil
+}
+
+// GetValidationHistories returns recent votes of the validators at once.
+// Records of the validators are read while no block is being recorded.
+func (h *ValidationHistory) GetValidationHistories(owners []module.Address, threshold int64) ([]map[string]interface{}, error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	jsos := make([]map[string]interface{}, len(owners))
+	for i, owner := range owners {
+		jso, err := h.getValidationHistory(owner, threshold)
+		if err != nil {
+			return nil, err
+		}
+		jsos[i] = jso
+	}
+	return jsos, nil
+}
+
+func NewValidationHistory(dbase db.Database, size int64, logger log.Logger) (*ValidationHistory, error) {
+	if size == 0 {
+		size = ValidationHistorySizeDefault
+	}
+	if size < 0 || size > ValidationHistorySizeMax {
+		return nil, errors.IllegalArgumentError.Errorf("InvalidSize(size=%d)", size)
+	}
+	bk, err := dbase.GetBucket(icdb.ValidationHistory)
+	if err != nil {
+		return nil, err
+	}
+	return &ValidationHistory{
+		bucket: bk,
+		size:   size,
+		log:    logger,
+	}, nil
+}
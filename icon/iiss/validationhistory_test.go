@@ -0,0 +1,140 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package iiss
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/module"
+)
+
+func TestValidationHistory(t *testing.T) {
+	_, err := NewValidationHistory(db.NewMapDB(), ValidationHistorySizeMax+1, log.New())
+	assert.Error(t, err)
+
+	h, err := NewValidationHistory(db.NewMapDB(), 10, log.New())
+	assert.NoError(t, err)
+
+	p1 := common.MustNewAddressFromString("hx01")
+	p2 := common.MustNewAddressFromString("hx02")
+
+	// p1 misses 3-6, 8 and 10-12 with a penalty at 5, and it's out at 7.
+	// p2 misses all blocks without penalty.
+	var failCont int64
+	for height := int64(1); height <= 12; height++ {
+		bv := &blockValidation{height: height}
+		switch {
+		case height == 7:
+		case height <= 2 || height == 9:
+			failCont = 0
+			bv.add(p1, true, 0, false)
+		default:
+			failCont += 1
+			if height == 5 {
+				failCont = 0
+			}
+			bv.add(p1, false, failCont, height == 5)
+		}
+		bv.add(p2, false, height, false)
+		h.OnSnapshotFinalization(&ExtensionSnapshotImpl{validation: bv})
+	}
+	// finalized block is not recorded again
+	h.OnSnapshotFinalization(&ExtensionSnapshotImpl{validation: &blockValidation{
+		height: 12, votes: []validationVote{{p1, true, 0, false}},
+	}})
+
+	jso, err := h.GetValidationHistory(p1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), jso["startHeight"])
+	assert.Equal(t, int64(12), jso["lastHeight"])
+	assert.Equal(t, int64(9), jso["total"])
+	assert.Equal(t, int64(8), jso["failed"])
+	assert.Equal(t, int64(3), jso["failCont"])
+	assert.Equal(t, int64(3), jso["maxFailCont"])
+	assert.Equal(t, []interface{}{int64(3), int64(4), int64(5), int64(6), int64(8), int64(10), int64(11), int64(12)},
+		jso["failedHeights"])
+	assert.Equal(t, []interface{}{int64(5)}, jso["penalties"])
+	streaks := jso["streaks"].([]interface{})
+	assert.Equal(t, 3, len(streaks))
+	assert.Equal(t, map[string]interface{}{
+		"startHeight": int64(6), "endHeight": int64(8), "count": int64(2),
+	}, streaks[1])
+	assert.Equal(t, []interface{}{int64(4), int64(8), int64(11)}, jso["alerts"])
+
+	jso, err = h.GetValidationHistory(p1, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{int64(5), int64(12)}, jso["alerts"])
+
+	// failures before the window are counted with failCont
+	jso, err = h.GetValidationHistory(p2, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), jso["maxFailCont"])
+	assert.Equal(t, []interface{}{int64(5)}, jso["alerts"])
+	jso, err = h.GetValidationHistory(p2, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{}, jso["alerts"])
+
+	// record is restarted after a long gap
+	bv := &blockValidation{height: 30}
+	bv.add(p1, true, 0, false)
+	h.OnSnapshotFinalization(&ExtensionSnapshotImpl{validation: bv})
+	jso, err = h.GetValidationHistory(p1, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(30), jso["startHeight"])
+	assert.Equal(t, int64(1), jso["total"])
+	assert.Equal(t, []interface{}{}, jso["penalties"])
+	assert.NotContains(t, jso, "alerts")
+
+	// unknown validator
+	jso, err = h.GetValidationHistory(common.MustNewAddressFromString("hx03"), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), jso["total"])
+
+	_, err = h.GetValidationHistory(p1, -1)
+	assert.Error(t, err)
+
+	// histories of validators at once
+	jsos, err := h.GetValidationHistories([]module.Address{p1, p2}, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(jsos))
+	assert.Equal(t, p1, jsos[0]["address"])
+	assert.Equal(t, []interface{}{int64(5)}, jsos[1]["alerts"])
+	_, err = h.GetValidationHistories([]module.Address{p1}, -1)
+	assert.Error(t, err)
+}
+
+func TestIsValidationTracked(t *testing.T) {
+	assert.False(t, IsValidationTracked(nil))
+	assert.False(t, IsValidationTracked(testValidationHolder{}))
+
+	h, err := NewValidationHistory(db.NewMapDB(), 10, log.New())
+	assert.NoError(t, err)
+	assert.True(t, IsValidationTracked(testValidationHolder{h}))
+}
+
+type testValidationHolder struct {
+	h *ValidationHistory
+}
+
+func (th testValidationHolder) ValidationHistory() *ValidationHistory {
+	return th.h
+}
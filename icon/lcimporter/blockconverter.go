@@ -19,6 +19,7 @@ package lcimporter
 import (
 	"bytes"
 	"fmt"
+	"math/big"
 	"time"
 
 	"github.com/icon-project/goloop/chain/base"
@@ -38,6 +39,7 @@ import (
 
 const (
 	KeyLastBlockHeight = "block.lastHeight"
+	KeyCheckpoint      = "block.checkpoint"
 	ChanBuf            = 2048
 )
 
@@ -54,6 +56,7 @@ type BlockConverter struct {
 	blkByHash   db.Bucket
 	chainBucket db.Bucket
 	svc         Service
+	workers     int
 
 	stopCh chan<- struct{}
 	resCh  <-chan interface{}
@@ -67,6 +70,28 @@ type Transition struct {
 	prevBlock   blockv0.Block
 	oldReceipts module.ReceiptList
 	blockHash   []byte
+
+	// original receipts and consensus information of transactions in
+	// the block if it's converted in advance
+	blockReceipts []txresult.Receipt
+	blockCSI      module.ConsensusInfo
+}
+
+// Checkpoint is the progress of the import. It's stored along with
+// the last block height, so the throughput is reported across restarts.
+// Blocks following it are fetched in advance on restart, while stored
+// blocks are replayed and the last state is loaded.
+type Checkpoint struct {
+	Height       int64
+	Transactions int64
+	Elapsed      int64 // in milliseconds
+}
+
+func (cp *Checkpoint) GetTPS() float32 {
+	if cp.Elapsed == 0 {
+		return 0
+	}
+	return float32(cp.Transactions*100_000/cp.Elapsed) / 100
 }
 
 type Store interface {
@@ -80,6 +105,10 @@ type GetTPSer interface {
 	GetTPS() float32
 }
 
+type Prefetcher interface {
+	Prefetch(height int64)
+}
+
 func NewBlockConverter(c module.Chain, plt base.Platform, pm eeproxy.Manager, cs Store, data string) (*BlockConverter, error) {
 	svc, err := NewService(c, plt, pm, data)
 	if err != nil {
@@ -210,13 +239,13 @@ func (e *BlockConverter) initTransitionFor(height int64) (*Transition, error) {
 		if err != nil {
 			return nil, err
 		}
-		return &Transition{tr, blkV0, prevV0, nil, blk.Hash()}, nil
+		return &Transition{tr, blkV0, prevV0, nil, blk.Hash(), nil, nil}, nil
 	} else {
 		tr, err := e.svc.NewInitTransition(nil, nil, logger)
 		if err != nil {
 			return nil, err
 		} else {
-			return &Transition{tr, nil, nil, nil, nil}, nil
+			return &Transition{tr, nil, nil, nil, nil, nil, nil}, nil
 		}
 	}
 }
@@ -263,43 +292,64 @@ func (e *BlockConverter) newConsensusInfoForTxsInBlock(
 	return csi, nil
 }
 
-func (e *BlockConverter) proposeTransition(last *Transition) (*Transition, error) {
-	var height int64
-	if last.block != nil {
-		height = last.block.Height() + 1
-	} else {
-		height = 0
-	}
-	prevV0 := last.block
-	blkv0, err := e.cs.GetBlockByHeight(int(height))
-	if err != nil {
-		return nil, err
-	}
-	if err := blkv0.Verify(last.block); err != nil {
-		return nil, err
-	}
+func (e *BlockConverter) fetchBlock(height int64) (blockv0.Block, error) {
+	return e.cs.GetBlockByHeight(int(height))
+}
+
+// convertTransactions returns the original receipts and the consensus
+// information of transactions in the block.
+func (e *BlockConverter) convertTransactions(
+	blk blockv0.Block,
+	prevBlk blockv0.Block,
+) ([]txresult.Receipt, module.ConsensusInfo, error) {
 	var rcts []txresult.Receipt
-	if last.block != nil {
-		txs := last.block.NormalTransactions()
+	if blk != nil {
+		var err error
+		txs := blk.NormalTransactions()
 		rcts, err = e.originalReceipts(txs)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
-		if lastV03, ok := last.block.(*blockv0.BlockV03); ok {
-			eReceiptListHash := lastV03.ReceiptsHash()
-			rReceiptListHash := blockv0.CalcMerkleRootOfReceiptSlice(rcts, txs, lastV03.Height())
+		if blkV03, ok := blk.(*blockv0.BlockV03); ok {
+			eReceiptListHash := blkV03.ReceiptsHash()
+			rReceiptListHash := blockv0.CalcMerkleRootOfReceiptSlice(rcts, txs, blkV03.Height())
 			if !bytes.Equal(eReceiptListHash, rReceiptListHash) {
-				return nil, errors.Errorf("DifferentReceiptListHash(stored=%#x,real=%#x)",
+				return nil, nil, errors.Errorf("DifferentReceiptListHash(stored=%#x,real=%#x)",
 					eReceiptListHash, rReceiptListHash)
 			}
 		}
 	}
-	csi, err := e.newConsensusInfoForTxsInBlock(last.block, last.prevBlock)
+	csi, err := e.newConsensusInfoForTxsInBlock(blk, prevBlk)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	return rcts, csi, nil
+}
+
+func (e *BlockConverter) convertBlock(cb *convertedBlock, prev blockv0.Block) {
+	if err := cb.block.Verify(prev); err != nil {
+		cb.err = err
+		return
+	}
+	cb.receipts, cb.csi, cb.err = e.convertTransactions(cb.block, prev)
+}
+
+func (e *BlockConverter) proposeTransition(last *Transition, cb *convertedBlock) (*Transition, error) {
+	if cb.err != nil {
+		return nil, cb.err
+	}
+	prevV0 := last.block
+	blkv0 := cb.block
+	rcts, csi := last.blockReceipts, last.blockCSI
+	if csi == nil {
+		var err error
+		rcts, csi, err = e.convertTransactions(last.block, last.prevBlock)
+		if err != nil {
+			return nil, err
+		}
 	}
 	var tr module.Transition
-	if height == 0 {
+	if cb.height == 0 {
 		tr = e.svc.NewTransition(
 			last.Transition,
 			nil,
@@ -319,7 +369,27 @@ func (e *BlockConverter) proposeTransition(last *Transition) (*Transition, error
 		)
 	}
 	rctList := txresult.NewReceiptListFromSlice(e.database, rcts)
-	return &Transition{tr, blkv0, prevV0, rctList, nil}, nil
+	return &Transition{tr, blkv0, prevV0, rctList, nil, cb.receipts, cb.csi}, nil
+}
+
+// SetWorkers sets the number of workers verifying and converting blocks
+// in advance. DefaultConvertWorkers is used if it's not positive.
+func (e *BlockConverter) SetWorkers(n int) {
+	e.workers = n
+}
+
+// GetCheckpoint returns the progress of the import. It returns nil if
+// no block is imported yet.
+func (e *BlockConverter) GetCheckpoint() *Checkpoint {
+	bk := db.NewCodedBucketFromBucket(e.chainBucket, nil, nil)
+	cp := new(Checkpoint)
+	if err := bk.Get(db.Raw(KeyCheckpoint), cp); err != nil {
+		if !errors.NotFoundError.Equals(err) {
+			e.log.Warnf("Fail to get checkpoint err=%+v", err)
+		}
+		return nil
+	}
+	return cp
 }
 
 func (e *BlockConverter) GetLastHeight() int64 {
@@ -442,6 +512,24 @@ func D(v interface{}) string {
 	return ret
 }
 
+// prefetch lets the store fetch blocks to be executed in advance, so
+// they are ready after stored blocks are replayed. On resume, blocks
+// after the checkpoint are fetched.
+func (e *BlockConverter) prefetch(from, to int64, resume bool) {
+	p, ok := e.cs.(Prefetcher)
+	if !ok {
+		return
+	}
+	if resume {
+		if cp := e.GetCheckpoint(); cp != nil && cp.Height >= from {
+			from = cp.Height + 1
+		}
+	}
+	if to < 0 || from <= to {
+		p.Prefetch(from)
+	}
+}
+
 func (e *BlockConverter) execute(from, to int64, firstNForcedResults []*BlockTransaction) (<-chan interface{}, error) {
 	e.Term()
 	resCh := make(chan interface{}, ChanBuf)
@@ -455,11 +543,13 @@ func (e *BlockConverter) execute(from, to int64, firstNForcedResults []*BlockTra
 		if from < 0 {
 			from = last + 1
 		}
+		e.prefetch(from, to, len(firstNForcedResults) == 0)
 		if last > 0 && len(firstNForcedResults) == 0 {
-			if last < to {
-				last = to
+			end := last
+			if to >= 0 && to < end {
+				end = to
 			}
-			for i := from; i <= last; i++ {
+			for i := from; i <= end; i++ {
 				blk, err := e.GetBlockByHeight(i)
 				if err != nil {
 					resCh <- err
@@ -478,6 +568,9 @@ func (e *BlockConverter) execute(from, to int64, firstNForcedResults []*BlockTra
 					TXCount:       int32(txCount),
 				}
 			}
+			if to >= 0 && to <= last {
+				return
+			}
 			from = last + 1
 		}
 		err := e.doExecute(from, to, firstNForcedResults, resCh, stopCh)
@@ -531,8 +624,15 @@ func (e *BlockConverter) doExecute(
 		return ErrAfterLastBlock
 	}
 	callback := make(transitionCallback, 1)
-	var rps, tps float32
+	var rps, tps, bps float32
 	tm := new(TPSMeasure).Init(100)
+	bm := new(BPSMeasure).Init(100)
+	cp := e.GetCheckpoint()
+	if cp == nil {
+		cp = &Checkpoint{Height: -1}
+	}
+	queue := newBlockQueue(from, to, prevTR.block, e.workers, e.fetchBlock, e.convertBlock)
+	defer queue.Stop()
 	forcedEnd := from + int64(len(firstNForcedResults))
 	for height := from; to < 0 || height <= to; height = height + 1 {
 		select {
@@ -544,19 +644,23 @@ func (e *BlockConverter) doExecute(
 			rps = getTPSer.GetTPS()
 		}
 		tps = tm.GetTPS()
+		bps = bm.GetBPS()
 		var ts int64
 		if prevTR.block != nil {
 			ts = prevTR.block.Timestamp()
 		}
 		e.log.Infof(
-			"[%s] Executing Block[ %10s ] %s RPS[ %6.2f ] TPS[ %6.2f ]",
+			"[%s] Executing Block[ %10s ] %s RPS[ %6.2f ] TPS[ %6.2f ] BPS[ %6.2f ] AVG[ %6.2f ]",
 			spinner(height, height),
 			D(height),
 			TimestampToString(ts),
 			rps,
 			tps,
+			bps,
+			cp.GetTPS(),
 		)
-		tr, err := e.proposeTransition(prevTR)
+		started := time.Now()
+		tr, err := e.proposeTransition(prevTR, queue.Get(height, prevTR.block))
 		if err != nil {
 			return errors.Wrapf(err, "FailureInPropose(height=%d)", height)
 		}
@@ -608,12 +712,23 @@ func (e *BlockConverter) doExecute(
 		if err = bk.Set(db.Raw(KeyLastBlockHeight), blk.Height()); err != nil {
 			return err
 		}
+		txCount := len(tr.block.NormalTransactions())
+		tm.OnTransactions(big.NewInt(int64(txCount)))
+		bm.OnBlock()
+		if height > cp.Height {
+			cp.Height = height
+			cp.Transactions += int64(txCount)
+			cp.Elapsed += time.Since(started).Milliseconds()
+			if err = bk.Set(db.Raw(KeyCheckpoint), cp); err != nil {
+				return err
+			}
+		}
 		resCh <- &BlockTransaction{
 			blk.Height(),
 			blk.Hash(),
 			blk.Result(),
 			blk.NextValidatorsHash(),
-			int32(len(tr.block.NormalTransactions())),
+			int32(txCount),
 			nil,
 		}
 		nbv := e.svc.GetNextBlockVersion(tr.Result(), prevTR.NextValidators())
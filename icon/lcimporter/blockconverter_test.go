@@ -615,3 +615,59 @@ func TestBlockConverter_BlockInfoAndConsensusInfo(_t *testing.T) {
 	res, ok := <-ch
 	assert.False(_t, ok)
 }
+
+type prefetchStore struct {
+	lcimporter.Store
+	prefetched []int64
+}
+
+func (s *prefetchStore) Prefetch(height int64) {
+	s.prefetched = append(s.prefetched, height)
+}
+
+func TestBlockConverter_ParallelConvertAndCheckpoint(_t *testing.T) {
+	bg := ictest.NewBlockV0Generator(_t, "")
+	defer bg.Close()
+
+	bg.AddSetRandomValidatorsTx(4)
+	w := bg.ValidatorsInTx()[0]
+	for i := 0; i < 8; i++ {
+		bg.GenerateNext(w)
+	}
+
+	dbase := db.NewMapDB()
+	t := newBlockConverterTest2(_t, dbase, bg, ictest.NewPlatform())
+	assert.Nil(t, t.GetCheckpoint())
+	t.SetWorkers(3)
+	ch, err := t.Start(0, 5)
+	assert.NoError(t, err)
+	for h := 0; h <= 5; h++ {
+		txCount := 0
+		if h <= 1 {
+			txCount = 1
+		}
+		assertBlockTransaction(t, <-ch, h, txCount, nil)
+	}
+	_, ok := <-ch
+	assert.False(t, ok)
+
+	cp := t.GetCheckpoint()
+	assert.NotNil(t, cp)
+	assert.EqualValues(t, 5, cp.Height)
+	assert.EqualValues(t, 2, cp.Transactions)
+
+	// resume from the checkpoint with a new converter, which lets the
+	// store fetch blocks after the checkpoint in advance
+	ps := &prefetchStore{Store: bg}
+	t = newBlockConverterTest2(_t, dbase, ps, ictest.NewPlatform())
+	t.SetWorkers(2)
+	ch, err = t.Start(2, 7)
+	assert.NoError(t, err)
+	for h := 2; h <= 7; h++ {
+		assertBlockTransaction(t, <-ch, h, 0, nil)
+	}
+	assert.Equal(t, []int64{6}, ps.prefetched)
+	cp = t.GetCheckpoint()
+	assert.EqualValues(t, 7, cp.Height)
+	assert.EqualValues(t, 2, cp.Transactions)
+}
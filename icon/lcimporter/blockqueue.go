@@ -0,0 +1,166 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lcimporter
+
+import (
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/icon/blockv0"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/txresult"
+)
+
+const DefaultConvertWorkers = 4
+
+// convertedBlock is a legacy block verified against the previous block
+// with the original receipts and the consensus information of its
+// transactions.
+type convertedBlock struct {
+	height   int64
+	block    blockv0.Block
+	receipts []txresult.Receipt
+	csi      module.ConsensusInfo
+	err      error
+
+	// closed after block is fetched. block is nil if it fails.
+	fetched chan struct{}
+}
+
+func newConvertedBlock(height int64) *convertedBlock {
+	return &convertedBlock{
+		height:  height,
+		fetched: make(chan struct{}),
+	}
+}
+
+type fetchFunc func(height int64) (blockv0.Block, error)
+type convertFunc func(cb *convertedBlock, prev blockv0.Block)
+
+// blockQueue converts blocks with multiple workers and returns them
+// in order of height. Blocks are fetched through the store, which is
+// expected to fetch them in advance. Each worker waits only for the
+// previous block to be fetched, so verification and conversion of
+// blocks run in parallel. It runs ahead of the consumer at most twice
+// of the number of workers.
+type blockQueue struct {
+	fetch   fetchFunc
+	convert convertFunc
+	slots   chan struct{}
+	queue   chan chan *convertedBlock
+	stopCh  chan struct{}
+	next    int64
+}
+
+func (q *blockQueue) doConvert(cb *convertedBlock, prev *convertedBlock) {
+	blk, err := q.fetch(cb.height)
+	if err == nil {
+		cb.block = blk
+	}
+	close(cb.fetched)
+	if err != nil {
+		cb.err = err
+		return
+	}
+	<-prev.fetched
+	if prev.block == nil && prev.height >= 0 {
+		cb.err = errors.InvalidStateError.Errorf(
+			"NoPreviousBlock(height=%d)", cb.height)
+		return
+	}
+	q.convert(cb, prev.block)
+}
+
+func (q *blockQueue) schedule(from, to int64, prev blockv0.Block) {
+	defer close(q.queue)
+	last := newConvertedBlock(from - 1)
+	last.block = prev
+	close(last.fetched)
+	for height := from; to < 0 || height <= to; height++ {
+		select {
+		case q.slots <- struct{}{}:
+		case <-q.stopCh:
+			return
+		}
+		ch := make(chan *convertedBlock, 1)
+		select {
+		case q.queue <- ch:
+		case <-q.stopCh:
+			<-q.slots
+			return
+		}
+		cb := newConvertedBlock(height)
+		go func(cb, prev *convertedBlock) {
+			q.doConvert(cb, prev)
+			ch <- cb
+			<-q.slots
+		}(cb, last)
+		last = cb
+	}
+}
+
+// convertNow fetches and converts the block at the height in the
+// caller's goroutine.
+func (q *blockQueue) convertNow(height int64, prev blockv0.Block) *convertedBlock {
+	cb := newConvertedBlock(height)
+	if cb.block, cb.err = q.fetch(height); cb.err == nil {
+		q.convert(cb, prev)
+	}
+	return cb
+}
+
+// Get returns the block at the height converted with the previous block.
+// Heights should be requested in order. A failed block is converted
+// again, because the queue may run ahead of the store while it follows
+// a live node.
+func (q *blockQueue) Get(height int64, prev blockv0.Block) *convertedBlock {
+	if height != q.next {
+		return q.convertNow(height, prev)
+	}
+	ch, ok := <-q.queue
+	if !ok {
+		return q.convertNow(height, prev)
+	}
+	q.next += 1
+	if cb := <-ch; cb.err == nil {
+		return cb
+	}
+	return q.convertNow(height, prev)
+}
+
+func (q *blockQueue) Stop() {
+	close(q.stopCh)
+}
+
+// newBlockQueue returns a queue converting blocks from the height. prev
+// is the block before the height, which is nil for the genesis.
+func newBlockQueue(
+	from, to int64, prev blockv0.Block, workers int,
+	fetch fetchFunc, convert convertFunc,
+) *blockQueue {
+	if workers <= 0 {
+		workers = DefaultConvertWorkers
+	}
+	q := &blockQueue{
+		fetch:   fetch,
+		convert: convert,
+		slots:   make(chan struct{}, workers),
+		queue:   make(chan chan *convertedBlock, workers*2),
+		stopCh:  make(chan struct{}),
+		next:    from,
+	}
+	go q.schedule(from, to, prev)
+	return q
+}
@@ -28,6 +28,7 @@ type Config struct {
 	StoreURI    string              `json:"store_uri"`
 	MaxRPS      int                 `json:"max_rps"`
 	CacheConfig lcstore.CacheConfig `json:"cache_config"`
+	Workers     int                 `json:"workers"`
 	BaseDir  string
 	Platform base.Platform
 	ProxyMgr eeproxy.Manager
@@ -537,6 +537,7 @@ func NewExecutor(chain module.Chain, dbase db.Database, cfg *Config) (*Executor,
 	if err != nil {
 		return nil, err
 	}
+	bc.SetWorkers(cfg.Workers)
 
 	return NewExecutorWithBC(rdb, idb, logger, bc)
 }
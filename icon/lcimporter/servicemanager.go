@@ -208,7 +208,11 @@ func (sm *ServiceManager) SendPatch(patch module.Patch) error {
 	return errors.ErrInvalidState
 }
 
-func (sm *ServiceManager) Call(result []byte, vl module.ValidatorList, js []byte, bi module.BlockInfo) (interface{}, error) {
+func (sm *ServiceManager) Call(result []byte, vl module.ValidatorList, js []byte, bi module.BlockInfo, ovs []module.AccountOverride) (interface{}, error) {
+	return nil, errors.ErrInvalidState
+}
+
+func (sm *ServiceManager) MultiCall(result []byte, vl module.ValidatorList, jss [][]byte, bi module.BlockInfo) ([]module.CallResult, error) {
 	return nil, errors.ErrInvalidState
 }
 
@@ -278,7 +282,7 @@ func (sm *ServiceManager) ImportResult(result []byte, vh []byte, src db.Database
 	return errors.ErrInvalidState
 }
 
-func (sm *ServiceManager) ExecuteTransaction(result []byte, vh []byte, js []byte, bi module.BlockInfo) (module.Receipt, error) {
+func (sm *ServiceManager) ExecuteTransaction(result []byte, vh []byte, js []byte, bi module.BlockInfo, ovs []module.AccountOverride) (module.Receipt, error) {
 	return nil, errors.ErrInvalidState
 }
 
@@ -377,6 +381,14 @@ func (sm *ServiceManager) GetSCOREStatus(result []byte, addr module.Address) (mo
 	return nil, common.ErrInvalidState
 }
 
+func (sm *ServiceManager) GetStorage(result []byte, addr module.Address, hints []module.StorageHint, cursor []byte, limit int) ([]module.StorageEntry, error) {
+	return nil, common.ErrInvalidState
+}
+
+func (sm *ServiceManager) DiffStorage(result1, result2 []byte, addr module.Address, hints []module.StorageHint, cursor []byte, limit int) ([]module.StorageChange, error) {
+	return nil, common.ErrInvalidState
+}
+
 func NewServiceManagerWithExecutor(chain module.Chain, ex *Executor, ps BlockV1ProofStorage, vs []*common.Address, cb ImportCallback) (*ServiceManager, error) {
 	logger := chain.Logger()
 	dbase := chain.Database()
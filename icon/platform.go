@@ -49,18 +49,56 @@ import (
 	"github.com/icon-project/goloop/service/txresult"
 )
 
+// PlatformConfig is the configuration of the platform given on joining the chain
+type PlatformConfig struct {
+	RewardHistory     iiss.RewardHistoryConfig     `json:"rewardHistory"`
+	ValidationHistory iiss.ValidationHistoryConfig `json:"validationHistory"`
+}
+
 type platform struct {
 	calculator iiss.CalculatorHolder
 	base       string
+	config     PlatformConfig
+	history    *iiss.RewardHistory
+	validation *iiss.ValidationHistory
 }
 
 func (p *platform) NewContractManager(dbase db.Database, dir string, logger log.Logger) (contract.ContractManager, error) {
 	if err := blockv1.CheckAndApplyPatch(dbase); err != nil {
 		return nil, err
 	}
+	if p.config.RewardHistory.Enable && p.history == nil {
+		history, err := iiss.NewRewardHistory(dbase, p.config.RewardHistory.Retention, icutils.NewIconLogger(logger))
+		if err != nil {
+			return nil, err
+		}
+		p.history = history
+		p.calculator.SetRewardHistory(history)
+	}
+	if p.config.ValidationHistory.Enable && p.validation == nil {
+		validation, err := iiss.NewValidationHistory(dbase, p.config.ValidationHistory.Size, icutils.NewIconLogger(logger))
+		if err != nil {
+			return nil, err
+		}
+		p.validation = validation
+	}
 	return newContractManager(p, dbase, dir, logger)
 }
 
+func (p *platform) RewardHistory() module.RewardHistory {
+	if p.history == nil {
+		return nil
+	}
+	return p.history
+}
+
+func (p *platform) ValidationHistory() module.ValidationHistory {
+	if p.validation == nil {
+		return nil
+	}
+	return p.validation
+}
+
 func (p *platform) NewExtensionSnapshot(dbase db.Database, raw []byte) state.ExtensionSnapshot {
 	// TODO return valid ExtensionSnapshot(not nil) which can return valid ExtensionState.
 	//  with that state, we may change state of extension.
@@ -72,6 +110,24 @@ func (p *platform) NewExtensionSnapshot(dbase db.Database, raw []byte) state.Ext
 	return iiss.NewExtensionSnapshot(dbase, raw)
 }
 
+// ForkExtension keeps the validators of the forked chain. Otherwise,
+// ExtensionState resets validators with main P-Reps at the end of the term.
+func (p *platform) ForkExtension(dbase db.Database, ed []byte) ([]byte, error) {
+	ess := p.NewExtensionSnapshot(dbase, ed)
+	if ess == nil {
+		return ed, nil
+	}
+	es := ess.NewState(false).(*iiss.ExtensionStateImpl)
+	if err := es.State.SetFixedValidators(true); err != nil {
+		return nil, err
+	}
+	ess = es.GetSnapshot()
+	if err := ess.Flush(); err != nil {
+		return nil, err
+	}
+	return ess.Bytes(), nil
+}
+
 func (p *platform) NewExtensionWithBuilder(builder merkle.Builder, raw []byte) state.ExtensionSnapshot {
 	return iiss.NewExtensionSnapshotWithBuilder(builder, raw)
 }
@@ -117,6 +173,7 @@ func (p *platform) NewBaseTransaction(wc state.WorldContext) (module.Transaction
 func (p *platform) OnExtensionSnapshotFinalization(ess state.ExtensionSnapshot, logger log.Logger) {
 	// Start background calculator if it's not started.
 	p.calculator.Start(ess, logger)
+	p.validation.OnSnapshotFinalization(ess)
 }
 
 func checkBaseTX(txs module.TransactionList) bool {
@@ -284,10 +341,16 @@ func (p *platform) SetBlockV1Proof(root []byte, size int64, votes *blockv0.Block
 	return ioutil.WriteFile(file, bs, os.FileMode(0500))
 }
 
-func NewPlatform(base string, cid int) (base.Platform, error) {
-	return &platform{
+func NewPlatform(base string, cid int, config json.RawMessage) (base.Platform, error) {
+	p := &platform{
 		base: base,
-	}, nil
+	}
+	if len(config) > 0 {
+		if err := json.Unmarshal(config, &p.config); err != nil {
+			return nil, errors.IllegalArgumentError.Wrapf(err, "InvalidPlatformConfig(config=%s)", config)
+		}
+	}
+	return p, nil
 }
 
 func init() {
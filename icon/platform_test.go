@@ -18,15 +18,24 @@ package icon
 
 import (
 	"io/ioutil"
+	"math/big"
 	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/icon-project/goloop/chain/base"
+	"github.com/icon-project/goloop/common"
 	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/db"
 	"github.com/icon-project/goloop/common/wallet"
 	"github.com/icon-project/goloop/icon/blockv0"
+	"github.com/icon-project/goloop/icon/icmodule"
+	"github.com/icon-project/goloop/icon/iiss"
+	"github.com/icon-project/goloop/icon/iiss/icstate"
 	"github.com/icon-project/goloop/icon/lcimporter"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/state"
 )
 
 func TestPlatform_BlockV1Proof(t *testing.T) {
@@ -34,7 +43,7 @@ func TestPlatform_BlockV1Proof(t *testing.T) {
 	assert.NoError(t, err)
 	defer os.RemoveAll(base)
 
-	plt, err := NewPlatform(base, 1)
+	plt, err := NewPlatform(base, 1, nil)
 	assert.NoError(t, err)
 
 	wallet := wallet.New()
@@ -52,4 +61,81 @@ func TestPlatform_BlockV1Proof(t *testing.T) {
 	assert.Equal(t, votes.Hash(), votes2.Hash())
 	assert.Equal(t, root, mh2.RootHash)
 	assert.Equal(t, height, mh2.Leaves)
-}
\ No newline at end of file
+}
+
+type forkWorldContext struct {
+	icmodule.WorldContext
+	height     int64
+	validators []module.Validator
+	updated    bool
+}
+
+func (wc *forkWorldContext) Revision() module.Revision {
+	return icmodule.ValueToRevision(icmodule.RevisionDecentralize)
+}
+
+func (wc *forkWorldContext) BlockHeight() int64 {
+	return wc.height
+}
+
+func (wc *forkWorldContext) GetTotalSupply() *big.Int {
+	return new(big.Int).Mul(big.NewInt(800_000_000), icmodule.BigIntICX)
+}
+
+func (wc *forkWorldContext) GetBTPContext() state.BTPContext {
+	return nil
+}
+
+func (wc *forkWorldContext) SetValidators(validators []module.Validator) error {
+	wc.validators = validators
+	wc.updated = true
+	return nil
+}
+
+func TestPlatform_ForkExtension(t *testing.T) {
+	plt, err := NewPlatform(t.TempDir(), 1, nil)
+	assert.NoError(t, err)
+	fp := plt.(base.ForkPlatform)
+	dbase := db.NewMapDB()
+
+	// before IISS
+	ed, err := fp.ForkExtension(dbase, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, ed)
+
+	// decentralized state with a main P-Rep
+	es := iiss.NewExtensionSnapshot(dbase, nil).NewState(false).(*iiss.ExtensionStateImpl)
+	assert.NoError(t, es.State.SetTermPeriod(10))
+	term := icstate.GenesisTerm(es.State, 10, icmodule.RevisionDecentralize)
+	term.SetIsDecentralized(true)
+	assert.NoError(t, es.State.SetTermSnapshot(term.GetSnapshot()))
+	prep := common.MustNewAddressFromString("hx01")
+	vss := icstate.NewValidatorsSnapshotWithPRepSnapshot(
+		icstate.PRepSnapshots{icstate.NewPRepSnapshot(prep, big.NewInt(100))}, es.State, 1)
+	assert.NoError(t, es.State.SetValidatorsSnapshot(vss))
+	ess := es.GetSnapshot()
+	assert.NoError(t, ess.Flush())
+
+	ed, err = fp.ForkExtension(dbase, ess.Bytes())
+	assert.NoError(t, err)
+	assert.NotEqual(t, ess.Bytes(), ed)
+
+	// the original state resets validators with main P-Reps of the next term
+	wc := &forkWorldContext{height: term.GetEndHeight()}
+	es = plt.NewExtensionSnapshot(dbase, ess.Bytes()).NewState(false).(*iiss.ExtensionStateImpl)
+	assert.NoError(t, es.OnExecutionEnd(wc, new(big.Int), nil))
+	assert.True(t, wc.updated)
+	assert.Len(t, wc.validators, 0)
+
+	// the forked state keeps validators across the term boundary
+	wc = &forkWorldContext{height: term.GetEndHeight()}
+	es = plt.NewExtensionSnapshot(dbase, ed).NewState(false).(*iiss.ExtensionStateImpl)
+	assert.True(t, es.IsDecentralized())
+	assert.NoError(t, es.OnExecutionEnd(wc, new(big.Int), nil))
+	assert.False(t, wc.updated)
+	assert.Greater(t, es.State.GetTermSnapshot().StartHeight(), term.GetEndHeight())
+
+	wc = &forkWorldContext{height: es.State.GetTermSnapshot().GetEndHeight()}
+	assert.NoError(t, es.OnExecutionEnd(wc, new(big.Int), nil))
+	assert.False(t, wc.updated)
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package icon
+
+import (
+	"github.com/icon-project/goloop/block"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/icon/iiss"
+	"github.com/icon-project/goloop/icon/iiss/icstate"
+	"github.com/icon-project/goloop/service"
+)
+
+// extensionAt returns the extension snapshot after executing the block
+// at the height, which is stored in the header of the next block.
+func extensionAt(dbase db.Database, height int64) (*iiss.ExtensionSnapshotImpl, *icstate.RewardCalcInfo, error) {
+	result, err := block.GetBlockResultByHeight(dbase, nil, height+1)
+	if err != nil {
+		return nil, nil, err
+	}
+	wss, err := service.NewWorldSnapshot(dbase, &platform{}, result, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	ess, ok := wss.GetExtensionSnapshot().(*iiss.ExtensionSnapshotImpl)
+	if !ok || ess == nil {
+		return nil, nil, errors.NotFoundError.Errorf("NoExtension(height=%d)", height)
+	}
+	es := ess.NewState(true).(*iiss.ExtensionStateImpl)
+	rcInfo, err := es.State.GetRewardCalcInfo()
+	if err != nil {
+		return nil, nil, err
+	}
+	return ess, rcInfo, nil
+}
+
+// searchExtension returns the first block height in [from, to] whose
+// extension satisfies cond, or -1 if there is none. cond is assumed to be
+// monotonic with block height, and blocks without extension (ex. blocks
+// imported from ICON1) are regarded as not satisfying it.
+func searchExtension(dbase db.Database, from, to int64, cond func(rcInfo *icstate.RewardCalcInfo) bool) int64 {
+	found := int64(-1)
+	for from <= to {
+		mid := from + (to-from)/2
+		if _, rcInfo, err := extensionAt(dbase, mid); err == nil && cond(rcInfo) {
+			found = mid
+			to = mid - 1
+		} else {
+			from = mid + 1
+		}
+	}
+	return found
+}
+
+// RecalculateIISS runs the calculation of the term starting at startHeight
+// again with the snapshots stored in dbase, then compares its result with
+// the one applied to the chain. dbase is not modified.
+func RecalculateIISS(dbase db.Database, startHeight int64, logger log.Logger) (map[string]interface{}, error) {
+	last, err := block.GetLastHeight(dbase)
+	if err != nil {
+		return nil, err
+	}
+	// the state of the last block is in the header of the next block
+	last -= 1
+
+	calcHeight := searchExtension(dbase, 0, last, func(rcInfo *icstate.RewardCalcInfo) bool {
+		return rcInfo.StartHeight() >= startHeight
+	})
+	if calcHeight < 0 {
+		return nil, errors.NotFoundError.Errorf("CalculationNotStarted(start=%d)", startHeight)
+	}
+	ess, rcInfo, err := extensionAt(dbase, calcHeight)
+	if err != nil {
+		return nil, err
+	}
+	if rcInfo.StartHeight() != startHeight {
+		return nil, errors.NotFoundError.Errorf(
+			"NoCalculation(start=%d,next=%d)", startHeight, rcInfo.StartHeight())
+	}
+
+	var expected []byte
+	applyHeight := searchExtension(dbase, calcHeight, last, func(rcInfo *icstate.RewardCalcInfo) bool {
+		return rcInfo.PrevHeight() >= startHeight
+	})
+	if applyHeight >= 0 {
+		if _, rcInfo, err = extensionAt(dbase, applyHeight); err != nil {
+			return nil, err
+		}
+		expected = rcInfo.PrevHash()
+	}
+
+	jso, err := iiss.Recalculate(dbase, ess.Back2(), ess.Reward(), expected, logger)
+	if err != nil {
+		return nil, err
+	}
+	jso["calculationHeight"] = calcHeight
+	if applyHeight >= 0 {
+		jso["applyHeight"] = applyHeight
+	}
+	return jso, nil
+}
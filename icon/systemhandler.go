@@ -60,6 +60,7 @@ type CallHandler interface {
 	TLogStart()
 	TLogDone(status error, steps *big.Int, result *codec.TypedObj)
 	ApplyCallSteps(cc contract.CallContext) error
+	TraceCall() *module.TraceCall
 }
 
 type SystemCallHandler struct {
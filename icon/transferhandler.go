@@ -39,6 +39,12 @@ func newTransferHandler(from, to module.Address, value *big.Int, call bool, logg
 	}
 }
 
+func (h *TransferHandler) TraceCall() *module.TraceCall {
+	call := h.CommonHandler.TraceCall()
+	call.Type = "transfer"
+	return call
+}
+
 func (h *TransferHandler) ExecuteSync(cc contract.CallContext) (err error, ro *codec.TypedObj, addr module.Address) {
 	h.Log.TSystemf("TRANSFER start from=%s to=%s value=%s", h.From, h.To, h.Value)
 	defer func() {
@@ -50,6 +50,8 @@ class Message(object):
     CLOSE = 11
     SETFEEPCT = 15
     CONTAINS = 16
+    PING = 17
+    PONG = 18
 
 
 class InvokeFlag(object):
@@ -423,6 +425,8 @@ class ServiceManagerProxy:
                 self.__handle_invoke(data)
             elif msg == Message.GETAPI:
                 self.__handle_get_api(data)
+            elif msg == Message.PING:
+                self.__client.send(Message.PONG, None)
             elif msg == Message.CLOSE:
                 return
 
@@ -0,0 +1,68 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package eetest;
+
+import score.Address;
+import score.Context;
+import score.VarDB;
+import score.annotation.EventLog;
+import score.annotation.External;
+
+/*
+ * Fixture contract for the conformance suite of execution engines.
+ * See service/eeproxy/eetest for the expected behavior.
+ */
+public class Fixture {
+    private final VarDB<byte[]> value = Context.newVarDB("value", byte[].class);
+
+    @External
+    public void setValue(byte[] v) {
+        value.set(v);
+    }
+
+    @External(readonly = true)
+    public byte[] getValue() {
+        return value.get();
+    }
+
+    @EventLog(indexed = 1)
+    public void Emitted(byte[] v) {
+    }
+
+    @External
+    public void emit(byte[] v) {
+        Emitted(v);
+    }
+
+    @External
+    public void fail(int code) {
+        Context.revert(code, "fail");
+    }
+
+    @External
+    public void loop() {
+        long i = 0;
+        while (true) {
+            i += 1;
+        }
+    }
+
+    @External(readonly = true)
+    public byte[] callGet(Address addr) {
+        return (byte[]) Context.call(addr, "getValue");
+    }
+}
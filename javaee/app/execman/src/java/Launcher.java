@@ -15,7 +15,11 @@
  */
 
 import foundation.icon.ee.ipc.Client;
+import foundation.icon.ee.ipc.Connector;
 import foundation.icon.ee.ipc.ExecutorManager;
+import foundation.icon.ee.ipc.TLSClient;
+import foundation.icon.ee.score.FileIO;
+import foundation.icon.ee.score.SharedFileIO;
 import foundation.icon.ee.score.TransactionExecutor;
 import org.slf4j.Logger;
 import org.slf4j.LoggerFactory;
@@ -23,11 +27,40 @@ import org.slf4j.LoggerFactory;
 import java.io.IOException;
 
 public class Launcher {
+    private static final long RECONNECT_DELAY = 5000;
+    private static final String SHARED_DIR = "execman.sharedDir";
+
     public static void main(String[] args) throws IOException {
         Logger logger = LoggerFactory.getLogger(Launcher.class);
         if (args.length == 2) {
-            TransactionExecutor executor = TransactionExecutor.newInstance(Client.connect(args[0]), args[1]);
+            Connector c = TLSClient.isTLSAddress(args[0]) ? TLSClient.connector : Client.connector;
+            TransactionExecutor executor = TransactionExecutor.newInstance(c.connect(args[0]), args[1]);
             executor.connectAndRunLoop();
+        } else if (args.length == 1 && TLSClient.isTLSAddress(args[0])) {
+            // remote pool reads contracts in the directory shared with the node
+            FileIO fileIO;
+            try {
+                fileIO = SharedFileIO.of(System.getProperty(SHARED_DIR));
+            } catch (IOException e) {
+                logger.error("Invalid {}: {}", SHARED_DIR, e.getMessage());
+                System.exit(1);
+                return;
+            }
+            // remote pool keeps connecting to the Service Manager
+            while (true) {
+                try {
+                    ExecutorManager executorManager = new ExecutorManager(args[0], TLSClient.connector, fileIO);
+                    executorManager.run();
+                    logger.info("Disconnected from {}", args[0]);
+                } catch (IOException e) {
+                    logger.warn("Fail to connect to {}: {}", args[0], e.toString());
+                }
+                try {
+                    Thread.sleep(RECONNECT_DELAY);
+                } catch (InterruptedException e) {
+                    return;
+                }
+            }
         } else if (args.length == 1) {
             ExecutorManager executorManager = new ExecutorManager(args[0]);
             executorManager.run();
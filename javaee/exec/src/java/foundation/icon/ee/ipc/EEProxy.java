@@ -71,6 +71,8 @@ public class EEProxy extends Proxy {
         public static final int GETOBJGRAPH = 13;
         public static final int SETOBJGRAPH = 14;
         public static final int SETFEEPCT = 15;
+        public static final int PING = 17;
+        public static final int PONG = 18;
     }
 
     public static class SetValueFlag {
@@ -101,7 +103,7 @@ public class EEProxy extends Proxy {
     }
 
     public void connect(String uuid) throws IOException {
-        sendMessage(MsgType.VERSION, 1, uuid, "java");
+        sendMessage(MsgType.VERSION, 2, uuid, "java");
     }
 
     public void close() throws IOException {
@@ -128,6 +130,10 @@ public class EEProxy extends Proxy {
                 case MsgType.RESULT:
                     logger.trace("[RESULT]");
                     return msg.value;
+                case MsgType.PING:
+                    logger.trace("[PING]");
+                    sendMessage(MsgType.PONG);
+                    break;
                 case MsgType.CLOSE:
                     // TODO: unwind stack
                     logger.trace("[CLOSE]");
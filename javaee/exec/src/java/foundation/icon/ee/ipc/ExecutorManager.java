@@ -20,6 +20,7 @@ import java.io.IOException;
 import java.util.HashMap;
 import java.util.Map;
 
+import foundation.icon.ee.score.FileIO;
 import foundation.icon.ee.score.Loader;
 import foundation.icon.ee.score.TransactionExecutor;
 import org.slf4j.Logger;
@@ -32,20 +33,22 @@ public class ExecutorManager {
     private final String execSockAddr;
     private final Connector connector;
     private final Loader loader = new Loader();
+    private final FileIO fileIO;
 
-    public ExecutorManager(String sockAddr, Connector c) throws IOException {
+    public ExecutorManager(String sockAddr, Connector c, FileIO fileIO) throws IOException {
         Connection client = c.connect(sockAddr);
         proxy = new ManagerProxy(client);
         execSockAddr = sockAddr;
         execMap = new HashMap<>();
         connector = c;
+        this.fileIO = fileIO;
 
         proxy.setOnRunListener(this::runExecutor);
         proxy.setOnKillListener(this::killExecutor);
     }
 
     public ExecutorManager(String sockAddr) throws IOException {
-        this(sockAddr, Client.connector);
+        this(sockAddr, Client.connector, null);
     }
 
     private void killExecutor(String uuid) throws IOException {
@@ -67,7 +70,7 @@ public class ExecutorManager {
                         connector.connect(execSockAddr),
                         uuid,
                         loader,
-                        null,
+                        fileIO,
                         null);
                 execMap.put(uuid, exec);
                 exec.connectAndRunLoop();
@@ -0,0 +1,88 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package foundation.icon.ee.ipc;
+
+import javax.net.ssl.SSLParameters;
+import javax.net.ssl.SSLSocket;
+import javax.net.ssl.SSLSocketFactory;
+import java.io.IOException;
+import java.io.InputStream;
+import java.io.OutputStream;
+
+/*
+ * TCP connection over TLS for communicating with the remote Service Manager.
+ * The address is in the form of "tls://host:port". Key and trust stores are
+ * configured with the standard system properties (javax.net.ssl.*).
+ */
+public class TLSClient implements Connection {
+    public static final String SCHEME = "tls://";
+
+    private final SSLSocket socket;
+    private final InputStream in;
+    private final OutputStream out;
+
+    private TLSClient(SSLSocket socket) throws IOException {
+        this.socket = socket;
+        this.in = socket.getInputStream();
+        this.out = socket.getOutputStream();
+    }
+
+    public static boolean isTLSAddress(String addr) {
+        return addr != null && addr.startsWith(SCHEME);
+    }
+
+    public static TLSClient connect(String addr) throws IOException {
+        if (!isTLSAddress(addr)) {
+            throw new IllegalArgumentException("invalid address " + addr);
+        }
+        String hostPort = addr.substring(SCHEME.length());
+        int idx = hostPort.lastIndexOf(':');
+        if (idx < 0) {
+            throw new IllegalArgumentException("no port in address " + addr);
+        }
+        String host = hostPort.substring(0, idx);
+        int port = Integer.parseInt(hostPort.substring(idx + 1));
+
+        SSLSocketFactory factory = (SSLSocketFactory) SSLSocketFactory.getDefault();
+        SSLSocket socket = (SSLSocket) factory.createSocket(host, port);
+        try {
+            SSLParameters params = socket.getSSLParameters();
+            params.setEndpointIdentificationAlgorithm("HTTPS");
+            socket.setSSLParameters(params);
+            socket.startHandshake();
+            return new TLSClient(socket);
+        } catch (IOException e) {
+            socket.close();
+            throw e;
+        }
+    }
+
+    public InputStream getInputStream() {
+        return in;
+    }
+
+    public void close() throws IOException {
+        socket.close();
+    }
+
+    public synchronized void send(byte[] b) throws IOException {
+        out.write(b);
+        out.flush();
+    }
+
+    static public Connector connector = TLSClient::connect;
+}
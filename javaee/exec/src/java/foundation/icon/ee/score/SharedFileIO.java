@@ -0,0 +1,65 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package foundation.icon.ee.score;
+
+import java.io.IOException;
+import java.nio.file.Files;
+import java.nio.file.Path;
+import java.nio.file.StandardCopyOption;
+
+/*
+ * FileIO for executors in remote pools. The node sends its own paths of
+ * contracts, so the directory containing the node directory should be
+ * shared with the pool and mounted at the same path.
+ */
+public class SharedFileIO implements FileIO {
+    private final Path root;
+
+    private SharedFileIO(Path root) {
+        this.root = root;
+    }
+
+    public static SharedFileIO of(String dir) throws IOException {
+        if (dir == null || dir.isEmpty()) {
+            throw new IOException("no shared directory");
+        }
+        Path root = Path.of(dir).toAbsolutePath().normalize();
+        if (!Files.isDirectory(root)) {
+            throw new IOException("shared directory " + root + " doesn't exist");
+        }
+        return new SharedFileIO(root);
+    }
+
+    private Path resolve(String p) throws IOException {
+        Path path = Path.of(p).toAbsolutePath().normalize();
+        if (!path.startsWith(root)) {
+            throw new IOException("path " + p + " isn't in shared directory " + root);
+        }
+        return path;
+    }
+
+    public byte[] readFile(String p) throws IOException {
+        return Files.readAllBytes(resolve(p));
+    }
+
+    public void writeFile(String p, byte[] bytes) throws IOException {
+        Path path = resolve(p);
+        var temp = Files.createTempFile(path.getParent(), null, null);
+        Files.write(temp, bytes);
+        Files.move(temp, path, StandardCopyOption.REPLACE_EXISTING);
+    }
+}
@@ -0,0 +1,52 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package foundation.icon.ee;
+
+import foundation.icon.ee.score.SharedFileIO;
+import org.junit.jupiter.api.Assertions;
+import org.junit.jupiter.api.Test;
+import org.junit.jupiter.api.io.TempDir;
+
+import java.io.IOException;
+import java.nio.file.Files;
+import java.nio.file.Path;
+
+public class SharedFileIOTest {
+    @TempDir
+    Path dir;
+
+    @Test
+    void testSharedDir() throws IOException {
+        Assertions.assertThrows(IOException.class, () -> SharedFileIO.of(null));
+        Assertions.assertThrows(IOException.class,
+                () -> SharedFileIO.of(dir.resolve("none").toString()));
+
+        var shared = dir.resolve("shared");
+        Files.createDirectories(shared.resolve("contract"));
+        var io = SharedFileIO.of(shared.toString());
+
+        var file = shared.resolve("contract").resolve("code.jar").toString();
+        io.writeFile(file, new byte[]{1, 2, 3});
+        Assertions.assertArrayEquals(new byte[]{1, 2, 3}, io.readFile(file));
+
+        Files.write(dir.resolve("other"), new byte[]{1});
+        Assertions.assertThrows(IOException.class,
+                () -> io.readFile(dir.resolve("other").toString()));
+        Assertions.assertThrows(IOException.class,
+                () -> io.readFile(shared.resolve("../other").toString()));
+    }
+}
@@ -174,6 +174,11 @@ type BlockManager interface {
 	// NewConsensusInfo returns a ConsensusInfo with proposer of previous block
 	// of blk and votes in blk.
 	NewConsensusInfo(blk Block) (ConsensusInfo, error)
+
+	// PrunedHeight returns the height of the last block whose bodies,
+	// receipts and states could be removed by pruning. It returns -1 if
+	// nothing has been pruned.
+	PrunedHeight() int64
 }
 
 type TransactionInfo interface {
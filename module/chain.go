@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"time"
 
 	"github.com/icon-project/goloop/common/db"
@@ -75,6 +76,84 @@ type Chain interface {
 	WalletFor(dsa string) BaseWallet
 }
 
+// DevMode controls the chain running in dev mode. The chain produces blocks
+// by itself without consensus, so only the node can be the validator.
+type DevMode interface {
+	// Mine produces n blocks and returns the height of the last block.
+	// One more block is produced if the last block has transactions, so
+	// their results are available.
+	Mine(n int) (int64, error)
+
+	// SetNextTimestamp sets the timestamp (in microseconds) of the next
+	// block. It must be greater than the timestamp of the last block.
+	SetNextTimestamp(ts int64) error
+
+	// Snapshot returns the ID of the snapshot for the last block.
+	Snapshot() (int, error)
+
+	// Revert resets the chain to the block of the snapshot. All snapshots
+	// are removed after revert.
+	Revert(id int) error
+
+	// SetBalance sets the balance of the account, and produces blocks to
+	// commit the change.
+	SetBalance(addr Address, balance *big.Int) error
+}
+
+// DevChain is implemented by chains supporting dev mode. DevMode returns
+// nil if dev mode is not enabled.
+type DevChain interface {
+	DevMode() DevMode
+}
+
+// RewardHistory is the history of rewards of accounts kept by the node.
+// It's not a part of the state, so it may differ between nodes.
+type RewardHistory interface {
+	// GetRewardHistory returns rewards of the account for terms starting at
+	// startHeight or later. Zero count returns the default number of terms.
+	GetRewardHistory(addr Address, startHeight int64, count int) (map[string]interface{}, error)
+}
+
+// RewardHistoryChain is implemented by chains whose platform may keep
+// reward history. RewardHistory returns nil if it's not kept.
+type RewardHistoryChain interface {
+	RewardHistory() RewardHistory
+}
+
+// ValidationHistory is the history of votes of validators kept by the node.
+// It's not a part of the state, so it may differ between nodes.
+type ValidationHistory interface {
+	// GetValidationHistory returns recent votes of the validator. With
+	// positive threshold, it also returns heights where consecutive failures
+	// reached the threshold.
+	GetValidationHistory(owner Address, threshold int64) (map[string]interface{}, error)
+
+	// GetValidationHistories returns recent votes of the validators at once.
+	GetValidationHistories(owners []Address, threshold int64) ([]map[string]interface{}, error)
+}
+
+// ValidationHistoryChain is implemented by chains whose platform may keep
+// validation history. ValidationHistory returns nil if it's not kept.
+type ValidationHistoryChain interface {
+	ValidationHistory() ValidationHistory
+}
+
+// QuotaChain is implemented by chains limiting the resources shared with
+// other chains of the node.
+type QuotaChain interface {
+	// AcquireRPC reserves a slot for handling a JSON-RPC request. It returns
+	// the function releasing the slot, or an error if the chain is handling
+	// the maximum number of requests.
+	AcquireRPC() (func(), error)
+
+	// NetworkBandwidth returns the maximum bytes per second sent to the
+	// network by the chain. Zero means no limit.
+	NetworkBandwidth() int64
+
+	// InspectQuota returns the quotas and the usages of the resources.
+	InspectQuota() map[string]interface{}
+}
+
 type Regulator interface {
 	MaxTxCount() int
 	OnPropose(now time.Time)
@@ -127,6 +127,8 @@ type NetworkTransport interface {
 	GetSecureSuites(channel string) string
 	SetSecureAeads(channel string, secureAeads string) error
 	GetSecureAeads(channel string) string
+	SetCompressions(channel string, compressions string) error
+	GetCompressions(channel string) string
 }
 
 type NetworkError interface {
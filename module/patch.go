@@ -2,6 +2,7 @@ package module
 
 const (
 	PatchTypeSkipTransaction = "skip_txs"
+	PatchTypeAccountOverride = "account_override"
 )
 
 type Patch interface {
@@ -17,4 +18,12 @@ type SkipTransactionPatch interface {
 	Verify(vl ValidatorList, roundLimit int64, nid int) error
 }
 
+// AccountOverridePatch applies overrides on the state at the block of
+// the height. Only the chain in dev mode decodes it.
+type AccountOverridePatch interface {
+	Patch
+	Height() int64 // height of the block to apply overrides
+	Overrides() []AccountOverride
+}
+
 type PatchDecoder func(t string, bs []byte) (Patch, error)
@@ -297,6 +297,58 @@ type TransitionManager interface {
 	WaitForTransaction(parent Transition, bi BlockInfo, cb func()) bool
 }
 
+// CallResult is the result of a call in MultiCall. Error is set if the call
+// fails, otherwise Result is set.
+type CallResult struct {
+	Result interface{}
+	Error  error
+}
+
+// AccountOverride is a set of changes on the account, which is applied
+// on the state before Call or ExecuteTransaction. Changes are never
+// committed except the ones in the account override patch of the chain
+// in dev mode.
+type AccountOverride struct {
+	Address Address
+
+	// Balance replaces the balance if it's not nil.
+	Balance *big.Int
+
+	// Storage replaces values of the storage. nil value deletes the key.
+	Storage map[string][]byte
+
+	// Content replaces the code of the contract with ContentType if it's
+	// not nil. It doesn't call on_install or on_update of the contract.
+	ContentType string
+	Content     []byte
+}
+
+// StorageHint is a container of the contract, which is used to decode keys
+// in the storage of the contract. Type is one of "var", "dict" and "array".
+// Keys has keys of the items in the "dict".
+type StorageHint struct {
+	Type string
+	Name string
+	Keys [][]interface{}
+}
+
+// StorageEntry is an entry in the storage of the contract. Hint is set if
+// the key is decoded with hints, and Keys has keys (or index) of the item
+// in the container. Value is nil if it doesn't exist.
+type StorageEntry struct {
+	Key   []byte
+	Value []byte
+	Hint  *StorageHint
+	Keys  []interface{}
+}
+
+// StorageChange is a change of the entry in the storage. Value of the entry
+// is the new value, and OldValue is the old one.
+type StorageChange struct {
+	StorageEntry
+	OldValue []byte
+}
+
 type ServiceManager interface {
 	TransitionManager
 
@@ -330,7 +382,12 @@ type ServiceManager interface {
 	SendPatch(patch Patch) error
 
 	// Call handles read-only contract API call.
-	Call(result []byte, vl ValidatorList, js []byte, bi BlockInfo) (interface{}, error)
+	// ovs are applied on the state before the call if they are supplied.
+	Call(result []byte, vl ValidatorList, js []byte, bi BlockInfo, ovs []AccountOverride) (interface{}, error)
+
+	// MultiCall handles read-only contract API calls on the same state.
+	// It returns the result of each call in the order of the calls.
+	MultiCall(result []byte, vl ValidatorList, jss [][]byte, bi BlockInfo) ([]CallResult, error)
 
 	// ValidatorListFromHash returns ValidatorList from hash.
 	ValidatorListFromHash(hash []byte) ValidatorList
@@ -353,6 +410,15 @@ type ServiceManager interface {
 	// GetSCOREStatus returns status of the contract
 	GetSCOREStatus(result []byte, addr Address) (SCOREStatus, error)
 
+	// GetStorage returns entries in the storage of the contract in the
+	// order of keys. It returns up to limit entries after the cursor.
+	GetStorage(result []byte, addr Address, hints []StorageHint, cursor []byte, limit int) ([]StorageEntry, error)
+
+	// DiffStorage returns changes in the storage of the contract from
+	// result1 to result2 in the order of keys. It returns up to limit
+	// changes after the cursor.
+	DiffStorage(result1, result2 []byte, addr Address, hints []StorageHint, cursor []byte, limit int) ([]StorageChange, error)
+
 	// GetMembers returns network member list
 	GetMembers(result []byte) (MemberList, error)
 
@@ -400,7 +466,8 @@ type ServiceManager interface {
 	// ExecuteTransaction executes the transaction on the specified state.
 	// Then it returns the expected result of the transaction.
 	// It ignores supplied step limit.
-	ExecuteTransaction(result []byte, vh []byte, js []byte, bi BlockInfo) (Receipt, error)
+	// ovs are applied on the state before the execution if they are supplied.
+	ExecuteTransaction(result []byte, vh []byte, js []byte, bi BlockInfo, ovs []AccountOverride) (Receipt, error)
 
 	// AddSyncRequest add sync request for specified data.
 	AddSyncRequest(id db.BucketID, key []byte) error
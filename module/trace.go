@@ -41,6 +41,16 @@ const (
 	TraceModeNone TraceMode = iota
 	TraceModeInvoke
 	TraceModeBalanceChange
+	TraceModeAccessList
+	TraceModeCallTree
+)
+
+type StorageOp int
+
+const (
+	StorageGet StorageOp = iota
+	StorageSet
+	StorageDelete
 )
 
 type OpType int
@@ -63,6 +73,18 @@ const (
 	RegPRep
 )
 
+// TraceCall is the information of the call for a frame.
+// It's reported only for TraceModeCallTree.
+type TraceCall struct {
+	Type      string
+	From      Address
+	To        Address
+	Value     *big.Int
+	Method    string
+	Params    interface{}
+	StepLimit *big.Int
+}
+
 type ExecutionPhase int
 
 const (
@@ -80,4 +102,7 @@ type TraceCallback interface {
 	OnFrameEnter() error
 	OnFrameExit(success bool) error
 	OnBalanceChange(opType OpType, from, to Address, amount *big.Int) error
+	OnStorageAccess(op StorageOp, addr Address, key, value, old []byte) error
+	OnCallStart(call *TraceCall) error
+	OnCallEnd(status error, stepUsed *big.Int) error
 }
@@ -0,0 +1,107 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package network
+
+import (
+	"sync"
+	"time"
+
+	"github.com/icon-project/goloop/module"
+)
+
+// isBandwidthExempt returns whether packets of the protocol are sent
+// regardless of the bandwidth limit. Bytes of their packets are still
+// counted, so they delay other packets.
+func isBandwidthExempt(pi module.ProtocolInfo) bool {
+	switch pi.Uint16() {
+	case module.ProtoConsensus.Uint16(), module.ProtoConsensusSync.Uint16():
+		return true
+	default:
+		return false
+	}
+}
+
+// bandwidthLimiter limits bytes of the packets sent by the channel with
+// a token bucket filled with limit bytes per second up to limit bytes.
+// A packet is allowed if the bucket has tokens for the packet or is full,
+// so a packet larger than the limit can be sent. Tokens are consumed for
+// each peer the packet is queued to, including relayed packets.
+type bandwidthLimiter struct {
+	lock     sync.Mutex
+	limit    int64
+	tokens   int64
+	last     time.Time
+	sent     int64
+	rejected int64
+}
+
+func (l *bandwidthLimiter) allow(now time.Time, n int) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.limit > 0 {
+		if elapsed := now.Sub(l.last); elapsed > 0 {
+			l.tokens += int64(elapsed) * l.limit / int64(time.Second)
+			if l.tokens > l.limit {
+				l.tokens = l.limit
+			}
+		}
+		l.last = now
+		if int64(n) > l.tokens && l.tokens < l.limit {
+			l.rejected += 1
+			return false
+		}
+	}
+	return true
+}
+
+// consume counts n bytes queued to a peer. Tokens may become negative,
+// then packets are rejected until the bucket is refilled.
+func (l *bandwidthLimiter) consume(n int) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.limit > 0 {
+		l.tokens -= int64(n)
+	}
+	l.sent += int64(n)
+}
+
+func (l *bandwidthLimiter) setLimit(limit int64) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.limit = limit
+	l.tokens = limit
+	l.last = time.Now()
+}
+
+func (l *bandwidthLimiter) usage() (limit, sent, rejected int64) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	return l.limit, l.sent, l.rejected
+}
+
+// BandwidthUsage returns the limit of bytes per second, bytes of the
+// packets queued to peers, and the number of packets rejected by the limit.
+func BandwidthUsage(nm module.NetworkManager) (limit, sent, rejected int64) {
+	if m, ok := nm.(*manager); ok {
+		return m.bandwidth.usage()
+	}
+	return 0, 0, 0
+}
@@ -0,0 +1,79 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/module"
+)
+
+func Test_bandwidthLimiter(t *testing.T) {
+	var l bandwidthLimiter
+
+	// no limit
+	now := time.Now()
+	assert.True(t, l.allow(now, 10000))
+	l.consume(10000)
+
+	l.setLimit(1000)
+	now = l.last
+	assert.True(t, l.allow(now, 600))
+	l.consume(600)
+	assert.True(t, l.allow(now, 400))
+	l.consume(400)
+	assert.False(t, l.allow(now, 1))
+
+	// refilled for the elapsed time
+	now = now.Add(500 * time.Millisecond)
+	assert.False(t, l.allow(now, 501))
+	assert.True(t, l.allow(now, 500))
+
+	// a packet queued to two peers consumes tokens twice
+	l.consume(500)
+	l.consume(500)
+	now = now.Add(500 * time.Millisecond)
+	assert.False(t, l.allow(now, 1))
+	now = now.Add(500 * time.Millisecond)
+	assert.True(t, l.allow(now, 500))
+
+	// a packet larger than the limit is allowed with the full bucket
+	now = now.Add(2 * time.Second)
+	assert.True(t, l.allow(now, 3000))
+	l.consume(3000)
+	now = now.Add(time.Second)
+	assert.False(t, l.allow(now, 1))
+	now = now.Add(2 * time.Second)
+	assert.True(t, l.allow(now, 1))
+	l.consume(1)
+
+	limit, sent, rejected := l.usage()
+	assert.EqualValues(t, 1000, limit)
+	assert.EqualValues(t, 10000+600+400+500+500+3000+1, sent)
+	assert.EqualValues(t, 4, rejected)
+}
+
+func Test_isBandwidthExempt(t *testing.T) {
+	assert.True(t, isBandwidthExempt(module.ProtoConsensus))
+	assert.True(t, isBandwidthExempt(module.ProtoConsensusSync))
+	assert.False(t, isBandwidthExempt(module.ProtoTransaction))
+	assert.False(t, isBandwidthExempt(module.ProtoFastSync))
+	assert.False(t, isBandwidthExempt(module.ProtoStateSync))
+}
@@ -17,9 +17,10 @@ var (
 
 type ChannelNegotiator struct {
 	*peerHandler
-	netAddress NetAddress
-	m          map[string]*ProtocolInfos
-	mtx        sync.RWMutex
+	netAddress   NetAddress
+	m            map[string]*ProtocolInfos
+	compressions map[string][]Compression
+	mtx          sync.RWMutex
 }
 
 func newChannelNegotiator(netAddress NetAddress, id module.PeerID, l log.Logger) *ChannelNegotiator {
@@ -27,6 +28,8 @@ func newChannelNegotiator(netAddress NetAddress, id module.PeerID, l log.Logger)
 		netAddress:  netAddress,
 		peerHandler: newPeerHandler(id, l.WithFields(log.Fields{LoggerFieldKeySubModule: "negotiator"})),
 		m:           make(map[string]*ProtocolInfos),
+
+		compressions: make(map[string][]Compression),
 	}
 	return cn
 }
@@ -61,12 +64,18 @@ type JoinRequest struct {
 	Channel   string
 	Addr      NetAddress
 	Protocols []module.ProtocolInfo
+	// Compressions is missing in the request from legacy peers, and it's
+	// decoded as empty, which selects no compression.
+	Compressions []Compression
 }
 
 type JoinResponse struct {
 	Channel   string
 	Addr      NetAddress
 	Protocols []module.ProtocolInfo
+	// Compression is missing in the response from legacy peers, and it's
+	// decoded as CompressionUnknown, which selects no compression.
+	Compression Compression
 }
 
 var defaultProtocols = []module.ProtocolInfo{
@@ -109,6 +118,55 @@ func (cn *ChannelNegotiator) ProtocolInfos(channel string) *ProtocolInfos {
 	return cn.m[channel]
 }
 
+func (cn *ChannelNegotiator) SetCompressions(channel string, cs []Compression) error {
+	cn.mtx.Lock()
+	defer cn.mtx.Unlock()
+
+	for i, c := range cs {
+		for j := i + 1; j < len(cs); j++ {
+			if c == cs[j] {
+				return fmt.Errorf("duplicate set %s index:%d and %d", c, i, j)
+			}
+		}
+	}
+	cn.compressions[channel] = cs
+	return nil
+}
+
+func (cn *ChannelNegotiator) GetCompressions(channel string) []Compression {
+	cn.mtx.RLock()
+	defer cn.mtx.RUnlock()
+
+	cs, ok := cn.compressions[channel]
+	if !ok || len(cs) == 0 {
+		return DefaultCompressions
+	}
+	return cs
+}
+
+func (cn *ChannelNegotiator) isSupportedCompression(channel string, c Compression) bool {
+	if c == CompressionNone || c == CompressionUnknown {
+		return false
+	}
+	for _, oc := range cn.GetCompressions(channel) {
+		if oc == c {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveCompression returns the first supported one from the compressions
+// in the order of preference of the requester, CompressionNone if nothing.
+func (cn *ChannelNegotiator) resolveCompression(channel string, cs []Compression) Compression {
+	for _, c := range cs {
+		if cn.isSupportedCompression(channel, c) {
+			return c
+		}
+	}
+	return CompressionNone
+}
+
 func (cn *ChannelNegotiator) resolveProtocols(p *Peer, channel string, protocols []module.ProtocolInfo) error {
 	if p.Channel() != channel {
 		return errors.Errorf("invalid channel")
@@ -146,7 +204,12 @@ func (cn *ChannelNegotiator) sendJoinRequest(p *Peer) {
 		p.CloseByError(err)
 		return
 	}
-	m := &JoinRequest{Channel: p.Channel(), Addr: cn.netAddress, Protocols: pis.Array()}
+	m := &JoinRequest{
+		Channel:      p.Channel(),
+		Addr:         cn.netAddress,
+		Protocols:    pis.Array(),
+		Compressions: cn.GetCompressions(p.Channel()),
+	}
 	cn.sendMessage(p2pProtoChan, p2pProtoChanJoinReq, m, p)
 	cn.logger.Traceln("sendJoinRequest", m, p)
 }
@@ -170,8 +233,15 @@ func (cn *ChannelNegotiator) handleJoinRequest(pkt *Packet, p *Peer) {
 	}
 	p.setNetAddress(rm.Addr)
 
-	m := &JoinResponse{Channel: p.Channel(), Addr: cn.netAddress, Protocols: p.ProtocolInfos().Array()}
+	c := cn.resolveCompression(p.Channel(), rm.Compressions)
+	m := &JoinResponse{
+		Channel:     p.Channel(),
+		Addr:        cn.netAddress,
+		Protocols:   p.ProtocolInfos().Array(),
+		Compression: c,
+	}
 	cn.sendMessage(p2pProtoChan, p2pProtoChanJoinResp, m, p)
+	p.setCompression(c)
 
 	cn.nextOnPeer(p)
 }
@@ -195,5 +265,12 @@ func (cn *ChannelNegotiator) handleJoinResponse(pkt *Packet, p *Peer) {
 	}
 	p.setNetAddress(rm.Addr)
 
+	//JoinResponse from legacy peer has no Compression
+	if cn.isSupportedCompression(p.Channel(), rm.Compression) {
+		p.setCompression(rm.Compression)
+	} else {
+		p.setCompression(CompressionNone)
+	}
+
 	cn.nextOnPeer(p)
 }
@@ -117,9 +117,24 @@ func Test_ChannelNegotiator_Request(t *testing.T) {
 				Addr:    testNetAddress,
 			},
 			expectJoinResponse: &JoinResponse{
-				Channel:   testChannel,
-				Addr:      testNetAddress,
-				Protocols: defaultProtocols,
+				Channel:     testChannel,
+				Addr:        testNetAddress,
+				Protocols:   defaultProtocols,
+				Compression: CompressionNone,
+			},
+		},
+		{ //compression
+			givenJoinRequest: &JoinRequest{
+				Channel:      testChannel,
+				Addr:         testNetAddress,
+				Protocols:    defaultProtocols,
+				Compressions: []Compression{CompressionUnknown, CompressionSnappy},
+			},
+			expectJoinResponse: &JoinResponse{
+				Channel:     testChannel,
+				Addr:        testNetAddress,
+				Protocols:   defaultProtocols,
+				Compression: CompressionSnappy,
 			},
 		},
 		{ //invalid channel
@@ -149,6 +164,7 @@ func Test_ChannelNegotiator_Request(t *testing.T) {
 			assert.Equal(t, scen.givenJoinRequest.Addr, p.NetAddress())
 			sortProtocols(actualJoinResponse.Protocols)
 			assert.Equal(t, *scen.expectJoinResponse, *actualJoinResponse)
+			assert.Equal(t, scen.expectJoinResponse.Compression, p.Compression())
 		}
 
 		assert.Equal(t, scen.expectClose, p.IsClosed())
@@ -162,15 +178,17 @@ func Test_ChannelNegotiator_Response(t *testing.T) {
 	}
 
 	expectJoinRequest := &JoinRequest{
-		Channel:   testChannel,
-		Addr:      testNetAddress,
-		Protocols: defaultProtocols,
+		Channel:      testChannel,
+		Addr:         testNetAddress,
+		Protocols:    defaultProtocols,
+		Compressions: DefaultCompressions,
 	}
 	scens := []struct {
 		givenPeerChannel  string
 		expectJoinRequest *JoinRequest
 		givenJoinResponse *JoinResponse
 		expectClose       bool
+		expectCompression Compression
 	}{
 		{ //legacy support
 			givenPeerChannel:  testChannel,
@@ -180,6 +198,18 @@ func Test_ChannelNegotiator_Response(t *testing.T) {
 				Addr:      testNetAddress,
 				Protocols: defaultProtocols,
 			},
+			expectCompression: CompressionNone,
+		},
+		{ //compression
+			givenPeerChannel:  testChannel,
+			expectJoinRequest: expectJoinRequest,
+			givenJoinResponse: &JoinResponse{
+				Channel:     testChannel,
+				Addr:        testNetAddress,
+				Protocols:   defaultProtocols,
+				Compression: CompressionSnappy,
+			},
+			expectCompression: CompressionSnappy,
 		},
 		{ //invalid channel
 			givenPeerChannel: "invalid",
@@ -218,6 +248,9 @@ func Test_ChannelNegotiator_Response(t *testing.T) {
 				codec.MP.MustMarshalToBytes(scen.givenJoinResponse), nil)
 			c.handleJoinResponse(pkt, p)
 			assert.Equal(t, scen.givenJoinResponse.Addr, p.NetAddress())
+			if !scen.expectClose {
+				assert.Equal(t, scen.expectCompression, p.Compression())
+			}
 		}
 
 		assert.Equal(t, scen.expectClose, p.IsClosed())
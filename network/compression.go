@@ -0,0 +1,80 @@
+package network
+
+import (
+	"fmt"
+
+	"github.com/golang/snappy"
+)
+
+const (
+	// packetFlagCompressed is set on lengthOfPayload of the header on the
+	// wire if the payload is compressed with the negotiated compression.
+	packetFlagCompressed = 0x80000000
+)
+
+var (
+	DefaultCompressions = []Compression{
+		CompressionSnappy,
+	}
+)
+
+type Compression byte
+
+const (
+	CompressionUnknown Compression = iota
+	CompressionNone
+	CompressionSnappy
+)
+
+func (c Compression) String() string {
+	switch c {
+	case CompressionNone:
+		return "none"
+	case CompressionSnappy:
+		return "snappy"
+	default:
+		return "unknown"
+	}
+}
+
+func CompressionFromString(s string) Compression {
+	switch s {
+	case "none":
+		return CompressionNone
+	case "snappy":
+		return CompressionSnappy
+	default:
+		return CompressionUnknown
+	}
+}
+
+type packetCompressor interface {
+	Compress(b []byte) ([]byte, error)
+	Decompress(b []byte, max int) ([]byte, error)
+}
+
+func (c Compression) compressor() packetCompressor {
+	switch c {
+	case CompressionSnappy:
+		return snappyCompressor{}
+	default:
+		return nil
+	}
+}
+
+type snappyCompressor struct{}
+
+func (snappyCompressor) Compress(b []byte) ([]byte, error) {
+	return snappy.Encode(nil, b), nil
+}
+
+func (snappyCompressor) Decompress(b []byte, max int) ([]byte, error) {
+	n, err := snappy.DecodedLen(b)
+	if err != nil {
+		return nil, err
+	}
+	if n > max {
+		return nil, fmt.Errorf("invalid decompressed length %d", n)
+	}
+	return snappy.Decode(nil, b)
+}
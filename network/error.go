@@ -22,6 +22,7 @@ const (
 	DuplicatedPeerError
 	InvalidMessageSequenceError
 	InvalidSignatureError
+	BandwidthExceededError
 )
 
 var (
@@ -41,6 +42,7 @@ var (
 	ErrDuplicatedPeer            = errors.NewBase(DuplicatedPeerError, "DuplicatedPeer")
 	ErrInvalidMessageSequence    = errors.NewBase(InvalidMessageSequenceError, "InvalidMessageSequence")
 	ErrInvalidSignature          = errors.NewBase(InvalidSignatureError, "InvalidSignatureError")
+	ErrBandwidthExceeded         = errors.NewBase(BandwidthExceededError, "BandwidthExceeded")
 	ErrIllegalArgument           = errors.ErrIllegalArgument
 )
 
@@ -58,7 +60,7 @@ func (e *Error) Unwrap() error { return e.error }
 func newNetworkError(err error, op string, opArg interface{}) module.NetworkError {
 	if err != nil {
 		isTemporary := false
-		if QueueOverflowError.Equals(err) {
+		if QueueOverflowError.Equals(err) || BandwidthExceededError.Equals(err) {
 			isTemporary = true
 		}
 		return &Error{err, isTemporary, op, opArg}
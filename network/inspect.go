@@ -85,6 +85,7 @@ func peerToMap(p *Peer, informal bool) map[string]interface{} {
 			m["rrole"] = p.RecvRole()
 			m["rconn"] = p.RecvConnType()
 			m["rtt"] = p.rtt.String()
+			m["compression"] = p.Compression().String()
 			if p.q != nil {
 				sq := make([]string, DefaultSendQueueMaxPriority)
 				for i := 0; i < DefaultSendQueueMaxPriority; i++ {
@@ -4,6 +4,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/icon-project/goloop/common/errors"
 	"github.com/icon-project/goloop/common/log"
@@ -35,6 +36,8 @@ type manager struct {
 	mtr *metric.NetworkMetric
 
 	streamReactors []*streamReactor
+
+	bandwidth bandwidthLimiter
 }
 
 func NewManager(c module.Chain, nt module.NetworkTransport, trustSeeds string, roles ...module.Role) module.NetworkManager {
@@ -51,12 +54,16 @@ func NewManager(c module.Chain, nt module.NetworkTransport, trustSeeds string, r
 		m.t.GetDialer(m.channel),
 		m.mtr,
 		m.logger)
+	m.p2p.bandwidth = &m.bandwidth
 
 	m.SetInitialRoles(roles...)
 	m.SetTrustSeeds(trustSeeds)
 
 	m.p2p.setConnectionLimit(p2pConnTypeChildren, c.ChildrenLimit())
 	m.p2p.setConnectionLimit(p2pConnTypeNephew, c.NephewsLimit())
+	if qc, ok := c.(module.QuotaChain); ok {
+		m.bandwidth.setLimit(qc.NetworkBandwidth())
+	}
 
 	m.logger.Infof("NetworkManager use channel=%s for cid=%#x nid=%#x",
 		m.channel, c.CID(), c.NID())
@@ -199,6 +206,10 @@ func (m *manager) send(pkt *Packet) error {
 		return ErrNotRegisteredReactor
 	}
 	pkt.priority = ph.getPriority()
+	if !isBandwidthExempt(pkt.protocol) &&
+		!m.bandwidth.allow(time.Now(), len(pkt.payload)) {
+		return ErrBandwidthExceeded
+	}
 	return m.p2p.Send(pkt)
 }
 
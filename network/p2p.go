@@ -24,6 +24,7 @@ const (
 	DefaultReceiveQueueSize     = 1000
 	DefaultPacketBufferSize     = 4096 //bufio.defaultBufSize=4096
 	DefaultPacketPayloadMax     = 1024 * 1024
+	DefaultPacketCompressMin    = 1024
 	DefaultPacketPoolNumBucket  = 20
 	DefaultPacketPoolBucketLen  = 500
 	DefaultDiscoveryPeriod      = 2 * time.Second
@@ -102,7 +103,8 @@ type PeerToPeer struct {
 	cLimitMtx sync.RWMutex
 
 	//monitor
-	mtr *metric.NetworkMetric
+	mtr       *metric.NetworkMetric
+	bandwidth *bandwidthLimiter
 
 	stopCh chan bool
 	run    bool
@@ -752,13 +754,26 @@ func (p2p *PeerToPeer) handleRttResponse(pkt *Packet, p *Peer) {
 	}
 }
 
+// sendTo queues the packet to the peer, and counts the bytes of the packet
+// for the bandwidth of the channel.
+func (p2p *PeerToPeer) sendTo(ctx context.Context, p *Peer) error {
+	if err := p.send(ctx); err != nil {
+		return err
+	}
+	if p2p.bandwidth != nil {
+		pkt := ctx.Value(p2pContextKeyPacket).(*Packet)
+		p2p.bandwidth.consume(len(pkt.payload))
+	}
+	return nil
+}
+
 func (p2p *PeerToPeer) sendToPeers(ctx context.Context, connTypes ...PeerConnectionType) int {
 	pkt := ctx.Value(p2pContextKeyPacket).(*Packet)
 	ps := p2p.findPeers(func(p *Peer) bool {
 		return p.ProtocolInfos().Exists(pkt.protocol)
 	}, connTypes...)
 	for _, p := range ps {
-		if err := p.send(ctx); err != nil && err != ErrDuplicatedPacket {
+		if err := p2p.sendTo(ctx, p); err != nil && err != ErrDuplicatedPacket {
 			p2p.logger.Infoln("sendToPeers", err, pkt.protocol, pkt.subProtocol, p.ID())
 		}
 	}
@@ -825,7 +840,7 @@ func (p2p *PeerToPeer) sendToFriends(ctx context.Context) {
 	pkt.footerToBytes(true)
 	pkt.ext = ext[:]
 	for _, p := range ps {
-		if err := p.send(ctx); err != nil && err != ErrDuplicatedPacket {
+		if err := p2p.sendTo(ctx, p); err != nil && err != ErrDuplicatedPacket {
 			p2p.logger.Infoln("sendToFriends", err, pkt.protocol, pkt.subProtocol, p.ID())
 		}
 	}
@@ -851,7 +866,7 @@ Loop:
 				switch pkt.dest {
 				case p2pDestPeer:
 					if p := p2p.getPeerByProtocol(pkt.destPeer, pkt.protocol); p != nil {
-						if err := p.send(ctx); err != nil && err != ErrDuplicatedPacket {
+						if err := p2p.sendTo(ctx, p); err != nil && err != ErrDuplicatedPacket {
 							p2p.logger.Infoln("sendToPeer", err, pkt.protocol, pkt.subProtocol, p.ID())
 						}
 					}
@@ -36,12 +36,13 @@ type Packet struct {
 	footer  []byte
 	ext     []byte
 	//Transient fields
-	sender    module.PeerID //20byte
-	destPeer  module.PeerID //20byte
-	priority  uint8
-	timestamp time.Time
-	forceSend bool
-	mtx       sync.RWMutex
+	sender     module.PeerID //20byte
+	destPeer   module.PeerID //20byte
+	priority   uint8
+	timestamp  time.Time
+	forceSend  bool
+	compressed uint32 //length of compressed payload on the wire
+	mtx        sync.RWMutex
 }
 
 type packetDestInfo uint16
@@ -156,16 +157,37 @@ func (p *Packet) _read(r io.Reader, n int) ([]byte, int, error) {
 }
 
 func (p *Packet) WriteTo(w io.Writer) (n int64, err error) {
+	n, _, err = p.writeTo(w, nil)
+	return
+}
+
+// writeTo writes the packet with the payload compressed by pc if it's
+// larger than DefaultPacketCompressMin, and returns the length of
+// compressed payload as wn, or zero if it's not compressed.
+// hashOfPacket is always calculated with uncompressed payload, so the
+// packet could be relayed to the peer which doesn't support compression.
+func (p *Packet) writeTo(w io.Writer, pc packetCompressor) (n int64, wn uint32, err error) {
 	if err = p.updateHash(false); err != nil {
 		return
 	}
 
+	header := p.headerToBytes(false)
+	payload := p.payload[:p.lengthOfPayload]
+	if pc != nil && len(payload) >= DefaultPacketCompressMin {
+		if cb, cerr := pc.Compress(payload); cerr == nil && len(cb) < len(payload) {
+			wn = uint32(len(cb))
+			header = append([]byte(nil), header...)
+			binary.BigEndian.PutUint32(header[packetHeaderSize-4:], wn|packetFlagCompressed)
+			payload = cb
+		}
+	}
+
 	var tn int
-	tn, err = w.Write(p.headerToBytes(false))
+	tn, err = w.Write(header)
 	if n += int64(tn); err != nil {
 		return
 	}
-	tn, err = w.Write(p.payload[:p.lengthOfPayload])
+	tn, err = w.Write(payload)
 	if n += int64(tn); err != nil {
 		return
 	}
@@ -242,6 +264,12 @@ func (p *Packet) footerToBytes(force bool) []byte {
 }
 
 func (p *Packet) ReadFrom(r io.Reader) (n int64, err error) {
+	return p.readFrom(r, nil)
+}
+
+// readFrom reads the packet and decompresses the payload by pc
+// if it's flagged as compressed.
+func (p *Packet) readFrom(r io.Reader, pc packetCompressor) (n int64, err error) {
 	var b []byte
 	var tn int
 	b, tn, err = p._read(r, packetHeaderSize)
@@ -252,7 +280,11 @@ func (p *Packet) ReadFrom(r io.Reader) (n int64, err error) {
 		return
 	}
 
-	p.payload, tn, err = p._read(r, int(p.lengthOfPayload))
+	if p.compressed > 0 {
+		p.payload, tn, err = p._read(r, int(p.compressed))
+	} else {
+		p.payload, tn, err = p._read(r, int(p.lengthOfPayload))
+	}
 	if n += int64(tn); err != nil {
 		return
 	}
@@ -272,6 +304,19 @@ func (p *Packet) ReadFrom(r io.Reader) (n int64, err error) {
 		}
 	}
 
+	if p.compressed > 0 {
+		if pc == nil {
+			err = fmt.Errorf("not negotiated compression %v", p)
+			return
+		}
+		if p.payload, err = pc.Decompress(p.payload, DefaultPacketPayloadMax); err != nil {
+			err = fmt.Errorf("fail to decompress %v err:%v", p, err)
+			return
+		}
+		p.lengthOfPayload = uint32(len(p.payload))
+		p.headerToBytes(true)
+	}
+
 	h, err := p._hash(false)
 	if err != nil {
 		return
@@ -302,7 +347,13 @@ func (p *Packet) setHeader(b []byte) ([]byte, error) {
 	tb = tb[1:]
 	p.lengthOfPayload = binary.BigEndian.Uint32(tb[:4])
 	tb = tb[4:]
-	if p.lengthOfPayload > DefaultPacketPayloadMax {
+	if p.lengthOfPayload&packetFlagCompressed != 0 {
+		p.compressed = p.lengthOfPayload &^ packetFlagCompressed
+		p.lengthOfPayload = 0
+		if p.compressed == 0 || p.compressed > DefaultPacketPayloadMax {
+			return b[packetHeaderSize:], fmt.Errorf("invalid lengthOfPayload")
+		}
+	} else if p.lengthOfPayload > DefaultPacketPayloadMax {
 		return b[packetHeaderSize:], fmt.Errorf("invalid lengthOfPayload")
 	}
 	return b[packetHeaderSize:], nil
@@ -327,6 +378,7 @@ type PacketReader struct {
 	rd   io.Reader
 	pkt  *Packet
 	hash hash.Hash64
+	pc   packetCompressor
 }
 
 // NewPacketReader returns a new PacketReader whose buffer has the default size.
@@ -339,9 +391,16 @@ func (pr *PacketReader) Reset(rd io.Reader) {
 	pr.Reader.Reset(pr.rd)
 }
 
+// SetCompression sets the compression to decompress the payload of
+// the packet which is flagged as compressed.
+// It must be called by the goroutine which calls ReadPacket.
+func (pr *PacketReader) SetCompression(c Compression) {
+	pr.pc = c.compressor()
+}
+
 func (pr *PacketReader) ReadPacket() (pkt *Packet, e error) {
 	pkt = &Packet{}
-	_, err := pkt.ReadFrom(pr)
+	_, err := pkt.readFrom(pr, pr.pc)
 	if err != nil {
 		e = err
 		return
@@ -352,6 +411,7 @@ func (pr *PacketReader) ReadPacket() (pkt *Packet, e error) {
 type PacketWriter struct {
 	*bufio.Writer
 	wr  io.Writer
+	pc  packetCompressor
 	mtx sync.Mutex
 }
 
@@ -367,15 +427,38 @@ func (pw *PacketWriter) Reset(wr io.Writer) {
 	pw.Writer.Reset(pw.wr)
 }
 
+// SetCompression sets the compression to compress the payload of
+// the packet to write.
+func (pw *PacketWriter) SetCompression(c Compression) {
+	pw.mtx.Lock()
+	defer pw.mtx.Unlock()
+
+	pw.pc = c.compressor()
+}
+
+func (pw *PacketWriter) compressor() packetCompressor {
+	pw.mtx.Lock()
+	defer pw.mtx.Unlock()
+
+	return pw.pc
+}
+
 func (pw *PacketWriter) WritePacket(pkt *Packet) error {
-	_, err := pkt.WriteTo(pw)
+	_, err := pw.writePacket(pkt)
+	return err
+}
+
+// writePacket writes the packet and returns the length of compressed
+// payload, or zero if it's not compressed.
+func (pw *PacketWriter) writePacket(pkt *Packet) (uint32, error) {
+	_, wn, err := pkt.writeTo(pw, pw.compressor())
 	if err != nil {
-		return err
+		return 0, err
 	}
 	if pw.Buffered() > 0 {
-		return pw.Flush()
+		return wn, pw.Flush()
 	}
-	return nil
+	return wn, nil
 }
 
 func (pw *PacketWriter) Write(b []byte) (int, error) {
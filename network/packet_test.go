@@ -8,6 +8,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/module"
 )
 
 func Test_packet_PacketReader(t *testing.T) {
@@ -44,3 +46,36 @@ func FuzzPacketReadFrom(f *testing.F) {
 		assert.LessOrEqual(t, n, int64(len(data)))
 	})
 }
+
+func Test_packet_Compression(t *testing.T) {
+	payload := bytes.Repeat([]byte("compressible"), DefaultPacketCompressMin)
+	pkt := newPacket(module.ProtocolInfo(0x0100), module.ProtocolInfo(0x0101), payload, generatePeerID())
+	assert.NoError(t, pkt.updateHash(false))
+
+	b := bytes.NewBuffer(nil)
+	pw := NewPacketWriter(b)
+	pw.SetCompression(CompressionSnappy)
+	assert.NoError(t, pw.WritePacket(pkt))
+	assert.Less(t, b.Len(), len(payload), "not compressed")
+
+	//reader without compression
+	_, err := NewPacketReader(bytes.NewBuffer(b.Bytes())).ReadPacket()
+	assert.Error(t, err)
+
+	pr := NewPacketReader(b)
+	pr.SetCompression(CompressionSnappy)
+	rpkt, err := pr.ReadPacket()
+	assert.NoError(t, err)
+	assert.Equal(t, payload, rpkt.payload)
+	assert.Equal(t, pkt.hashOfPacket, rpkt.hashOfPacket)
+	assert.Equal(t, pkt.headerToBytes(false), rpkt.headerToBytes(false))
+	assert.True(t, rpkt.compressed > 0)
+
+	//small payload is not compressed
+	pkt = newPacket(module.ProtocolInfo(0x0100), module.ProtocolInfo(0x0101), []byte("test"), generatePeerID())
+	assert.NoError(t, pw.WritePacket(pkt))
+	rpkt, err = NewPacketReader(b).ReadPacket()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("test"), rpkt.payload)
+	assert.Equal(t, uint32(0), rpkt.compressed)
+}
@@ -57,6 +57,8 @@ type Peer struct {
 	pisMtx        sync.RWMutex
 	attr          map[string]interface{}
 	attrMtx       sync.RWMutex
+	compression   Compression
+	compMtx       sync.RWMutex
 
 	//
 	secureKey *secureKey
@@ -391,6 +393,9 @@ func (p *Peer) receiveRoutine() {
 		pkt.sender = p.ID()
 		p.pool.Put(pkt.hashOfPacket)
 		p.getMetric().OnRecv(pkt.dest, pkt.ttl, pkt.extendInfo.hint(), pkt.protocol.Uint16(), pkt.lengthOfPayload)
+		if pkt.compressed > 0 {
+			p.getMetric().OnRecvCompressed(pkt.protocol.Uint16(), pkt.lengthOfPayload, pkt.compressed)
+		}
 		if cbFunc := p.getPacketCbFunc(); cbFunc != nil {
 			cbFunc(pkt, p)
 		} else {
@@ -405,8 +410,12 @@ func (p *Peer) sendDirect(pkt *Packet) error {
 
 	if err := p.conn.SetWriteDeadline(time.Now().Add(DefaultSendTimeout)); err != nil {
 		return err
-	} else if err := p.writer.WritePacket(pkt); err != nil {
+	} else if wn, err := p.writer.writePacket(pkt); err != nil {
 		return err
+	} else if wn > 0 {
+		if mtr := p.getMetric(); mtr != nil {
+			mtr.OnSendCompressed(pkt.protocol.Uint16(), pkt.lengthOfPayload, wn)
+		}
 	}
 	return nil
 }
@@ -523,6 +532,24 @@ func (p *Peer) setProtocolInfos(pis *ProtocolInfos) {
 	p.pis = pis
 }
 
+func (p *Peer) Compression() Compression {
+	p.compMtx.RLock()
+	defer p.compMtx.RUnlock()
+
+	return p.compression
+}
+
+//setCompression must be called in the receiveRoutine, since
+//PacketReader is not guarded
+func (p *Peer) setCompression(c Compression) {
+	p.compMtx.Lock()
+	defer p.compMtx.Unlock()
+
+	p.compression = c
+	p.reader.SetCompression(c)
+	p.writer.SetCompression(c)
+}
+
 func (p *Peer) GetAttr(k string) (interface{}, bool) {
 	p.attrMtx.RLock()
 	defer p.attrMtx.RUnlock()
@@ -136,6 +136,32 @@ func (t *transport) GetSecureAeads(channel string) string {
 	return strings.Join(s, ",")
 }
 
+func (t *transport) SetCompressions(channel string, compressions string) error {
+	if compressions == "" {
+		return t.cn.SetCompressions(channel, nil)
+	}
+	ss := strings.Split(compressions, ",")
+	cs := make([]Compression, len(ss))
+	for i, s := range ss {
+		c := CompressionFromString(s)
+		if c == CompressionUnknown {
+			return fmt.Errorf("parse Compression error from %s", s)
+		}
+		cs[i] = c
+	}
+	return t.cn.SetCompressions(channel, cs)
+}
+
+func (t *transport) GetCompressions(channel string) string {
+	cs := t.cn.GetCompressions(channel)
+
+	s := make([]string, len(cs))
+	for i, c := range cs {
+		s[i] = c.String()
+	}
+	return strings.Join(s, ",")
+}
+
 func (t *transport) addProtocol(channel string, pi module.ProtocolInfo) {
 	t.cn.addProtocol(channel, pi)
 }
@@ -0,0 +1,458 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package node
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/icon-project/goloop/chain"
+	"github.com/icon-project/goloop/chain/backup"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/server/metric"
+)
+
+const (
+	BackupStateWaiting = "waiting"
+	BackupStateRunning = "running"
+
+	// ScheduledBackupSuffix is the suffix of the names of scheduled backups.
+	// The retention policy is applied only to them.
+	ScheduledBackupSuffix = "_auto"
+
+	backupPollInterval = 200 * time.Millisecond
+)
+
+type BackupResult struct {
+	Name     string    `json:"name,omitempty"`
+	Start    time.Time `json:"start"`
+	Duration string    `json:"duration"`
+	Height   int64     `json:"height,omitempty"`
+	Size     int64     `json:"size,omitempty"`
+	Removed  []string  `json:"removed,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+type BackupStatus struct {
+	Schedule    string        `json:"schedule"`
+	Retention   string        `json:"retention,omitempty"`
+	State       string        `json:"state"`
+	Next        *time.Time    `json:"next,omitempty"`
+	Last        *BackupResult `json:"last,omitempty"`
+	LastSuccess string        `json:"lastSuccess,omitempty"`
+	Failures    int           `json:"failures"`
+}
+
+// backupScheduler makes backups of the chain on the schedule. If the chain
+// is running, then it stops the chain during the backup and starts it
+// again after the backup.
+type backupScheduler struct {
+	n   *Node
+	cid int
+	log log.Logger
+
+	lock      sync.Mutex
+	schedule  backup.Schedule
+	retention *backup.Retention
+	status    BackupStatus
+
+	updateCh chan struct{}
+	stopCh   chan struct{}
+}
+
+func (s *backupScheduler) Configure(schedule backup.Schedule, retention *backup.Retention) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.schedule = schedule
+	s.retention = retention
+	select {
+	case s.updateCh <- struct{}{}:
+	default:
+	}
+}
+
+func (s *backupScheduler) Status() *BackupStatus {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	status := s.status
+	status.Schedule = s.schedule.String()
+	if s.retention != nil {
+		status.Retention = s.retention.String()
+	}
+	return &status
+}
+
+func (s *backupScheduler) Start() {
+	go s.run()
+}
+
+// Stop stops scheduling. It doesn't wait for the running backup, which
+// ends on the termination of the chain.
+func (s *backupScheduler) Stop() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	select {
+	case <-s.stopCh:
+	default:
+		close(s.stopCh)
+	}
+}
+
+func (s *backupScheduler) setState(state string, next time.Time) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.status.State = state
+	if next.IsZero() {
+		s.status.Next = nil
+	} else {
+		s.status.Next = &next
+	}
+}
+
+func (s *backupScheduler) run() {
+	last, err := s.n.lastScheduledBackup(s.cid)
+	if err != nil && !os.IsNotExist(err) {
+		s.log.Warnf("Fail to find the last scheduled backup err=%+v", err)
+	}
+	if last.IsZero() {
+		last = time.Now()
+	}
+	for {
+		s.lock.Lock()
+		next := s.schedule.Next(last)
+		s.lock.Unlock()
+		s.setState(BackupStateWaiting, next)
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-s.stopCh:
+			timer.Stop()
+			return
+		case <-s.updateCh:
+			timer.Stop()
+			continue
+		case <-timer.C:
+		}
+
+		s.setState(BackupStateRunning, time.Time{})
+		s.backup()
+		last = time.Now()
+	}
+}
+
+func (s *backupScheduler) backup() {
+	s.lock.Lock()
+	retention := s.retention
+	s.lock.Unlock()
+
+	start := time.Now()
+	result := &BackupResult{Start: start}
+	s.log.Infof("Scheduled backup starts")
+	info, err := s.n.runScheduledBackup(s.cid, s.stopCh)
+	if err == nil {
+		result.Name = info.Name
+		result.Height = info.Height
+		result.Size = info.Size
+		if retention != nil {
+			removed, err := s.n.removeExpiredBackups(s.cid, retention)
+			if err != nil {
+				s.log.Warnf("Fail to remove expired backups err=%+v", err)
+			}
+			result.Removed = removed
+		}
+	}
+	d := time.Since(start)
+	result.Duration = d.String()
+	metric.RecordBackup(metric.GetMetricContextByCID(s.cid), d, result.Size, err)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.status.Last = result
+	if err != nil {
+		result.Error = err.Error()
+		s.status.Failures += 1
+		s.log.Warnf("Scheduled backup fails failures=%d err=%+v",
+			s.status.Failures, err)
+	} else {
+		s.status.LastSuccess = result.Name
+		s.status.Failures = 0
+		s.log.Infof("Scheduled backup done name=%s height=%d removed=%d",
+			result.Name, result.Height, len(result.Removed))
+	}
+}
+
+func newBackupScheduler(n *Node, cid int, schedule backup.Schedule, retention *backup.Retention) *backupScheduler {
+	return &backupScheduler{
+		n:         n,
+		cid:       cid,
+		log:       n.logger.WithFields(log.Fields{log.FieldKeyCID: strconv.FormatInt(int64(cid), 16)}),
+		schedule:  schedule,
+		retention: retention,
+		updateCh:  make(chan struct{}, 1),
+		stopCh:    make(chan struct{}),
+	}
+}
+
+// parseBackupConfig returns the schedule and the retention policy of the
+// configuration. It returns nil schedule if the schedule is not configured.
+func parseBackupConfig(cfg *chain.Config) (backup.Schedule, *backup.Retention, error) {
+	if len(cfg.BackupSchedule) == 0 {
+		return nil, nil, nil
+	}
+	schedule, err := backup.ParseSchedule(cfg.BackupSchedule)
+	if err != nil {
+		return nil, nil, err
+	}
+	retention, err := backup.ParseRetention(cfg.BackupRetention)
+	if err != nil {
+		return nil, nil, err
+	}
+	return schedule, retention, nil
+}
+
+// _updateBackupScheduler applies the backup configuration of the chain to
+// its scheduler.
+func (n *Node) _updateBackupScheduler(cid int, cfg *chain.Config) error {
+	schedule, retention, err := parseBackupConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	n.backupLock.Lock()
+	defer n.backupLock.Unlock()
+
+	s, ok := n.backups[cid]
+	if schedule == nil {
+		if ok {
+			s.Stop()
+			delete(n.backups, cid)
+		}
+		return nil
+	}
+	if ok {
+		s.Configure(schedule, retention)
+	} else {
+		s = newBackupScheduler(n, cid, schedule, retention)
+		n.backups[cid] = s
+		s.Start()
+	}
+	return nil
+}
+
+func (n *Node) _configureBackup(c *Chain, key, value string) error {
+	schedule, retention := c.cfg.BackupSchedule, c.cfg.BackupRetention
+	if key == "backupSchedule" {
+		schedule = value
+	} else {
+		retention = value
+	}
+	if err := chain.CheckBackupConfig(schedule, retention); err != nil {
+		return err
+	}
+	c.cfg.BackupSchedule = schedule
+	c.cfg.BackupRetention = retention
+	return n._updateBackupScheduler(c.CID(), c.cfg)
+}
+
+func (n *Node) _stopBackupScheduler(cid int) {
+	n.backupLock.Lock()
+	defer n.backupLock.Unlock()
+
+	if s, ok := n.backups[cid]; ok {
+		s.Stop()
+		delete(n.backups, cid)
+	}
+}
+
+// GetChainBackup returns the status of the scheduled backup of the chain.
+func (n *Node) GetChainBackup(cid int) (*BackupStatus, error) {
+	n.backupLock.Lock()
+	defer n.backupLock.Unlock()
+
+	s, ok := n.backups[cid]
+	if !ok {
+		return nil, errors.NotFoundError.Errorf("NoBackupSchedule(cid=%#x)", cid)
+	}
+	return s.Status(), nil
+}
+
+func (n *Node) waitChainStopped(cid int, stopCh <-chan struct{}) error {
+	ticker := time.NewTicker(backupPollInterval)
+	defer ticker.Stop()
+	for {
+		c := n.GetChain(cid)
+		if c == nil {
+			return errors.NotFoundError.Errorf("ChainNotFound(cid=%#x)", cid)
+		}
+		if c.IsStopped() {
+			return nil
+		}
+		select {
+		case <-stopCh:
+			return errors.ErrInterrupted
+		case <-ticker.C:
+		}
+	}
+}
+
+// runScheduledBackup makes a backup of the chain. The chain is stopped
+// during the backup if it's running. The archive is verified after
+// the backup.
+func (n *Node) runScheduledBackup(cid int, stopCh <-chan struct{}) (ret *BackupInfo, err error) {
+	c := n.GetChain(cid)
+	if c == nil {
+		return nil, errors.NotFoundError.Errorf("ChainNotFound(cid=%#x)", cid)
+	}
+	if c.IsStarted() {
+		if err := n.StopChain(cid); err != nil {
+			return nil, err
+		}
+		defer func() {
+			if serr := n.StartChain(cid); serr != nil && err == nil {
+				ret, err = nil, errors.Wrap(serr, "FailToResumeChain")
+			}
+		}()
+		if err := n.waitChainStopped(cid, stopCh); err != nil {
+			return nil, err
+		}
+	} else if !c.IsStopped() {
+		state, _, _ := c.State()
+		return nil, errors.InvalidStateError.Errorf(
+			"ChainIsBusy(state=%s)", state)
+	}
+
+	name, err := func() (string, error) {
+		n.mtx.RLock()
+		defer n.mtx.RUnlock()
+		c, err := n._get(cid)
+		if err != nil {
+			return "", err
+		}
+		return n._backupChain(c, ScheduledBackupSuffix)
+	}()
+	if err != nil {
+		return nil, err
+	}
+	if err := n.waitChainStopped(cid, stopCh); err != nil {
+		return nil, err
+	}
+	c = n.GetChain(cid)
+	if c == nil {
+		return nil, errors.NotFoundError.Errorf("ChainNotFound(cid=%#x)", cid)
+	}
+	if _, _, lastErr := c.State(); lastErr != nil {
+		return nil, lastErr
+	}
+	return n.verifyBackup(c, name)
+}
+
+// verifyBackup checks the archive made for the chain. The archive is
+// removed if it's invalid.
+func (n *Node) verifyBackup(c *Chain, name string) (*BackupInfo, error) {
+	file := path.Join(n.cfg.ResolveAbsolute(n.cfg.BackupDir), name)
+	st, err := os.Stat(file)
+	if err != nil {
+		return nil, errors.NotFoundError.Wrapf(err, "NoBackupFile(name=%s)", name)
+	}
+	info, err := chain.GetBackupInfoOf(file)
+	if err == nil {
+		if int(info.CID.Value) != c.CID() || int(info.NID.Value) != c.NID() {
+			err = errors.InvalidStateError.Errorf(
+				"BackupInfoMismatch(cid=%#x,nid=%#x)", info.CID.Value, info.NID.Value)
+		}
+	}
+	if err != nil {
+		if rerr := os.Remove(file); rerr != nil {
+			n.logger.Warnf("Fail to remove invalid backup file=%s err=%+v", file, rerr)
+		}
+		return nil, errors.InvalidStateError.Wrapf(err, "InvalidBackup(name=%s)", name)
+	}
+	return &BackupInfo{
+		Name:       name,
+		Size:       st.Size(),
+		BackupInfo: *info,
+	}, nil
+}
+
+// scheduledBackupsOf returns the scheduled backups of the chain.
+func (n *Node) scheduledBackupsOf(cid int) ([]backup.File, error) {
+	backupDir := n.cfg.ResolveAbsolute(n.cfg.BackupDir)
+	fis, err := ioutil.ReadDir(backupDir)
+	if err != nil {
+		return nil, err
+	}
+	prefix := fmt.Sprintf("%#x_", cid)
+	suffix := ScheduledBackupSuffix + ".zip"
+	var files []backup.File
+	for _, fi := range fis {
+		if !fi.Mode().IsRegular() {
+			continue
+		}
+		if strings.HasPrefix(fi.Name(), prefix) && strings.HasSuffix(fi.Name(), suffix) {
+			files = append(files, backup.File{
+				Name: fi.Name(),
+				Time: fi.ModTime(),
+			})
+		}
+	}
+	return files, nil
+}
+
+// lastScheduledBackup returns the time of the latest scheduled backup of
+// the chain. It returns zero time if there is no scheduled backup.
+func (n *Node) lastScheduledBackup(cid int) (time.Time, error) {
+	files, err := n.scheduledBackupsOf(cid)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var last time.Time
+	for _, f := range files {
+		if f.Time.After(last) {
+			last = f.Time
+		}
+	}
+	return last, nil
+}
+
+// removeExpiredBackups removes scheduled backups of the chain expired by
+// the retention policy. It returns the names of the removed files.
+func (n *Node) removeExpiredBackups(cid int, r *backup.Retention) ([]string, error) {
+	files, err := n.scheduledBackupsOf(cid)
+	if err != nil {
+		return nil, err
+	}
+	backupDir := n.cfg.ResolveAbsolute(n.cfg.BackupDir)
+	var removed []string
+	for _, f := range r.Expired(files) {
+		if err := os.Remove(path.Join(backupDir, f.Name)); err != nil {
+			return removed, err
+		}
+		removed = append(removed, f.Name)
+	}
+	return removed, nil
+}
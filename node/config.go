@@ -6,11 +6,14 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"time"
 
+	"github.com/icon-project/goloop/common/errors"
 	"github.com/icon-project/goloop/common/log"
 	"github.com/icon-project/goloop/module"
 	"github.com/icon-project/goloop/server"
 	"github.com/icon-project/goloop/server/jsonrpc"
+	"github.com/icon-project/goloop/service/eeproxy"
 )
 
 const (
@@ -29,6 +32,14 @@ type StaticConfig struct {
 	Engines       string `json:"engines"`
 	BackupDir     string `json:"backup_dir"`
 
+	// remote execution engines
+	EERemoteAddr  string `json:"ee_remote_addr,omitempty"`
+	EERemotePools string `json:"ee_remote_pools,omitempty"`
+	EETLSCert     string `json:"ee_tls_cert,omitempty"`
+	EETLSKey      string `json:"ee_tls_key,omitempty"`
+	EETLSCA       string `json:"ee_tls_ca,omitempty"`
+	EESharedDir   string `json:"ee_shared_dir,omitempty"`
+
 	AuthSkipIfEmptyUsers bool `json:"auth_skip_if_empty_users,omitempty"`
 	NIDForP2P            bool `json:"nid_for_p2p,omitempty"`
 
@@ -80,6 +91,11 @@ func (c *StaticConfig) SetFilePath(path string) string {
 	if c.BackupDir != "" {
 		c.BackupDir = c.ResolveRelative(ResolveAbsolute(o, c.BackupDir))
 	}
+	for _, p := range []*string{&c.EETLSCert, &c.EETLSKey, &c.EETLSCA, &c.EESharedDir} {
+		if *p != "" {
+			*p = c.ResolveRelative(ResolveAbsolute(o, *p))
+		}
+	}
 	return o
 }
 
@@ -108,6 +124,8 @@ const (
 
 type RuntimeConfig struct {
 	EEInstances       int    `json:"eeInstances"`
+	EEHeartbeat       string `json:"eeHeartbeat,omitempty"`
+	EEDeadline        string `json:"eeDeadline,omitempty"`
 	RPCDefaultChannel string `json:"rpcDefaultChannel"`
 	RPCIncludeDebug   bool   `json:"rpcIncludeDebug"`
 	RPCRosetta        bool   `json:"rpcRosetta"`
@@ -117,6 +135,42 @@ type RuntimeConfig struct {
 	FilePath string `json:"-"` // absolute path
 }
 
+func parseEEDuration(s string, def time.Duration) (time.Duration, error) {
+	if s == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, errors.IllegalArgumentError.Wrapf(err, "InvalidDuration(%s)", s)
+	}
+	if d < 0 {
+		return 0, errors.IllegalArgumentError.Errorf("NegativeDuration(%s)", s)
+	}
+	return d, nil
+}
+
+// EEHealthConfig returns the configuration for supervising execution
+// engines. Heartbeats time out after twice the interval, and "0" disables
+// the check.
+func (c *RuntimeConfig) EEHealthConfig() (eeproxy.HealthConfig, error) {
+	var cfg eeproxy.HealthConfig
+	var err error
+	if cfg.HeartbeatInterval, err = parseEEDuration(c.EEHeartbeat,
+		eeproxy.DefaultHeartbeatInterval); err != nil {
+		return cfg, err
+	}
+	if c.EEHeartbeat == "" {
+		cfg.HeartbeatTimeout = eeproxy.DefaultHeartbeatTimeout
+	} else {
+		cfg.HeartbeatTimeout = cfg.HeartbeatInterval * 2
+	}
+	if cfg.ExecutionDeadline, err = parseEEDuration(c.EEDeadline,
+		eeproxy.DefaultExecutionDeadline); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
 func (c *RuntimeConfig) load() error {
 	log.Println("load ", c.FilePath)
 	if _, err := os.Stat(c.FilePath); err != nil {
@@ -14,8 +14,11 @@ import (
 	"time"
 
 	"github.com/icon-project/goloop/chain"
+	"github.com/icon-project/goloop/chain/export"
 	"github.com/icon-project/goloop/chain/gs"
+	"github.com/icon-project/goloop/chain/notify"
 	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/ipc"
 	"github.com/icon-project/goloop/common/log"
 	"github.com/icon-project/goloop/module"
 	"github.com/icon-project/goloop/network"
@@ -42,8 +45,12 @@ type Node struct {
 
 	mtx sync.RWMutex
 
-	chains   map[string]*Chain
-	channels map[int]string
+	chains    map[string]*Chain
+	channels  map[int]string
+	exporters map[int]*export.Exporter
+
+	backupLock sync.Mutex
+	backups    map[int]*backupScheduler
 
 	cliSrv *UnixDomainSockHttpServer
 }
@@ -228,6 +235,9 @@ func (n *Node) _add(cfg *chain.Config) (module.Chain, error) {
 	if err := n.nt.SetSecureAeads(nc, cfg.SecureAeads); err != nil {
 		return nil, err
 	}
+	if err := n.nt.SetCompressions(nc, cfg.Compressions); err != nil {
+		return nil, err
+	}
 
 	c := &Chain{chain.NewChain(n.w, n.nt, n.srv, n.pm, n.logger, cfg), cfg, false}
 	if err := c.Init(); err != nil {
@@ -235,6 +245,9 @@ func (n *Node) _add(cfg *chain.Config) (module.Chain, error) {
 	}
 	n.channels[cid] = channel
 	n.chains[channel] = c
+	if err := n._updateBackupScheduler(cid, cfg); err != nil {
+		n.logger.Warnf("Fail to schedule backup cid=%#x err=%+v", cid, err)
+	}
 	return c, nil
 }
 
@@ -378,6 +391,25 @@ func (n *Node) JoinChain(
 
 	channel := chain.GetChannel(p.Channel, nid)
 
+	if len(p.Notifier) > 0 {
+		if _, err := notify.ParseConfig(p.Notifier); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := chain.CheckPruneRetention(p.PruneRetention); err != nil {
+		return nil, err
+	}
+
+	if err := chain.CheckBackupConfig(p.BackupSchedule, p.BackupRetention); err != nil {
+		return nil, err
+	}
+
+	if err := n._checkQuota(p.NodeCache, p.EEInstances, p.NodeCacheMemory,
+		p.RPCConcurrency, p.NetworkBandwidth); err != nil {
+		return nil, err
+	}
+
 	if err := n._canAdd(cid, nid, channel, false); err != nil {
 		return nil, err
 	}
@@ -394,9 +426,11 @@ func (n *Node) JoinChain(
 		NID:              nid,
 		DBType:           p.DBType,
 		Platform:         p.Platform,
+		PlatformConfig:   p.PlatformConfig,
 		Channel:          channel,
 		SecureSuites:     p.SecureSuites,
 		SecureAeads:      p.SecureAeads,
+		Compressions:     p.Compressions,
 		SeedAddr:         p.SeedAddr,
 		Role:             p.Role,
 		GenesisStorage:   genesisStorage,
@@ -414,6 +448,14 @@ func (n *Node) JoinChain(
 		ChildrenLimit:    p.ChildrenLimit,
 		NephewsLimit:     p.NephewsLimit,
 		ValidateTxOnSend: p.ValidateTxOnSend,
+		Notifier:         p.Notifier,
+		PruneRetention:   p.PruneRetention,
+		BackupSchedule:   p.BackupSchedule,
+		BackupRetention:  p.BackupRetention,
+		EEInstances:      p.EEInstances,
+		NodeCacheMemory:  p.NodeCacheMemory,
+		RPCConcurrency:   p.RPCConcurrency,
+		NetworkBandwidth: p.NetworkBandwidth,
 	}
 
 	if err := cfg.Save(); err != nil {
@@ -443,6 +485,11 @@ func (n *Node) LeaveChain(cid int) error {
 	if err != nil {
 		return err
 	}
+	if e, ok := n.exporters[cid]; ok {
+		e.Stop()
+		delete(n.exporters, cid)
+	}
+	n._stopBackupScheduler(cid)
 	err = n._remove(c)
 	if err != nil {
 		return err
@@ -530,6 +577,102 @@ func (n *Node) PruneChain(cid int, dbt string, height int64) error {
 	return c.Prune(gs, dbt, height)
 }
 
+// ForkChain starts to make a new chain from the block of the height of
+// the chain. The new chain is added after the chain finishes the fork,
+// and it produces blocks with the given validators.
+func (n *Node) ForkChain(cid int, p *ChainForkParam) error {
+	defer n.mtx.Unlock()
+	n.mtx.Lock()
+
+	c, err := n._get(cid)
+	if err != nil {
+		return err
+	}
+	newCID := int(p.CID.Value)
+	nid := int(p.NID.Value)
+	if nid == 0 {
+		nid = newCID
+	}
+	if err := n._canAdd(newCID, nid, chain.GetChannel(p.Channel, nid), false); err != nil {
+		return err
+	}
+	chainDir, err := n._mkChainDir(newCID)
+	if err != nil {
+		return errors.Wrapf(err, "Fail to create directory for cid=%d", newCID)
+	}
+	params, err := json.Marshal(&chain.ForkParams{
+		Dir:         chainDir,
+		GenesisFile: path.Join(chainDir, ChainGenesisZipFileName),
+		CID:         newCID,
+		NID:         nid,
+		Height:      p.Height,
+		Validators:  p.Validators,
+	})
+	if err != nil {
+		_ = os.RemoveAll(chainDir)
+		return err
+	}
+	if err := c.RunTask(chain.ForkTask, params); err != nil {
+		_ = os.RemoveAll(chainDir)
+		return err
+	}
+
+	// the new chain doesn't connect to the seeds of the chain.
+	cfg := *c.cfg
+	cfg.NID = nid
+	cfg.Channel = p.Channel
+	cfg.SeedAddr = ""
+	cfg.AutoStart = false
+	cfg.Notifier = nil
+	cfg.BackupSchedule = ""
+	cfg.BackupRetention = ""
+	cfg.BaseDir = ""
+	cfg.FilePath, _ = filepath.Abs(path.Join(chainDir, ChainConfigFileName))
+	go n.finishFork(cid, &cfg)
+	return nil
+}
+
+// finishFork adds the new chain after the fork of the chain.
+// The directory of the new chain is removed on failure.
+func (n *Node) finishFork(cid int, cfg *chain.Config) {
+	chainDir := filepath.Dir(cfg.FilePath)
+	err := func() error {
+		if err := n.waitChainStopped(cid, nil); err != nil {
+			return err
+		}
+		if c := n.GetChain(cid); c == nil {
+			return errors.NotFoundError.Errorf("ChainNotFound(cid=%#x)", cid)
+		} else if _, _, lastErr := c.State(); lastErr != nil {
+			return lastErr
+		}
+
+		n.mtx.Lock()
+		defer n.mtx.Unlock()
+
+		genesis, err := ioutil.ReadFile(path.Join(chainDir, ChainGenesisZipFileName))
+		if err != nil {
+			return err
+		}
+		genesisStorage, err := gs.New(genesis)
+		if err != nil {
+			return err
+		}
+		cfg.GenesisStorage = genesisStorage
+		cfg.Genesis = genesisStorage.Genesis()
+		if err := cfg.Save(); err != nil {
+			return err
+		}
+		_, err = n._add(cfg)
+		return err
+	}()
+	if err != nil {
+		n.logger.Warnf("Fail to fork chain cid=%#x err=%+v", cid, err)
+		_ = os.RemoveAll(chainDir)
+		return
+	}
+	n.logger.Infof("Forked chain cid=%#x to cid=%#x dir=%s", cid, cfg.CID(), chainDir)
+}
+
 func (n *Node) BackupChain(cid int, manual bool) (string, error) {
 	defer n.mtx.RUnlock()
 	n.mtx.RLock()
@@ -542,14 +685,18 @@ func (n *Node) BackupChain(cid int, manual bool) (string, error) {
 	if manual {
 		return "manual", c.Backup("", nil)
 	}
+	return n._backupChain(c, "")
+}
+
+func (n *Node) _backupChain(c *Chain, suffix string) (string, error) {
 	backupDir := n.cfg.ResolveAbsolute(n.cfg.BackupDir)
 	if err := os.MkdirAll(backupDir, 0700); err != nil {
 		return "", errors.InvalidStateError.Wrapf(err,
 			"Fail to make backup directory=%s", backupDir)
 	}
 	now := time.Now()
-	name := fmt.Sprintf("%#x_%#x_%s_%s.zip", c.CID(), c.NID(), c.Channel(),
-		now.Format("20060102-150405"))
+	name := fmt.Sprintf("%#x_%#x_%s_%s%s.zip", c.CID(), c.NID(), c.Channel(),
+		now.Format("20060102-150405"), suffix)
 	file := path.Join(backupDir, name)
 	return name, c.Backup(file, []string{ChainGenesisZipFileName, ChainConfigFileName})
 }
@@ -663,6 +810,10 @@ func (n *Node) ConfigureChain(cid int, key string, value string) error {
 			} else {
 				c.cfg.AutoStart = as
 			}
+		case "backupSchedule", "backupRetention":
+			if err := n._configureBackup(c, key, value); err != nil {
+				return err
+			}
 		default:
 			return errors.ErrInvalidState
 		}
@@ -698,7 +849,41 @@ func (n *Node) ConfigureChain(cid int, key string, value string) error {
 			if !chain.IsNodeCacheOption(value) {
 				return errors.Errorf("InvalidNodeCacheOption(%s)", value)
 			}
+			if err := n._checkQuota(value, c.cfg.EEInstances, c.cfg.NodeCacheMemory,
+				c.cfg.RPCConcurrency, c.cfg.NetworkBandwidth); err != nil {
+				return err
+			}
 			c.cfg.NodeCache = value
+		case "eeInstances", "rpcConcurrency":
+			intVal, err := strconv.Atoi(value)
+			if err != nil {
+				return errors.Wrapf(err, "invalid value type")
+			}
+			cfg := *c.cfg
+			if key == "eeInstances" {
+				cfg.EEInstances = intVal
+			} else {
+				cfg.RPCConcurrency = intVal
+			}
+			if err := n._checkQuota(cfg.NodeCache, cfg.EEInstances, cfg.NodeCacheMemory,
+				cfg.RPCConcurrency, cfg.NetworkBandwidth); err != nil {
+				return err
+			}
+			c.cfg.EEInstances = cfg.EEInstances
+			c.cfg.RPCConcurrency = cfg.RPCConcurrency
+		case "nodeCacheMemory", "networkBandwidth":
+			cfg := *c.cfg
+			if key == "nodeCacheMemory" {
+				cfg.NodeCacheMemory = value
+			} else {
+				cfg.NetworkBandwidth = value
+			}
+			if err := n._checkQuota(cfg.NodeCache, cfg.EEInstances, cfg.NodeCacheMemory,
+				cfg.RPCConcurrency, cfg.NetworkBandwidth); err != nil {
+				return err
+			}
+			c.cfg.NodeCacheMemory = cfg.NodeCacheMemory
+			c.cfg.NetworkBandwidth = cfg.NetworkBandwidth
 		case "defaultWaitTimeout":
 			if intVal, err := strconv.ParseInt(value, 0, 64); err != nil {
 				return errors.Wrapf(err, "invalid value type")
@@ -735,6 +920,12 @@ func (n *Node) ConfigureChain(cid int, key string, value string) error {
 				return err
 			}
 			c.cfg.SecureAeads = value
+		case "compressions":
+			nc := network.ChannelOfNetID(c.cfg.NetID())
+			if err := n.nt.SetCompressions(nc, value); err != nil {
+				return err
+			}
+			c.cfg.Compressions = value
 		case "seedAddress":
 			c.cfg.SeedAddr = value
 		case "role":
@@ -767,6 +958,26 @@ func (n *Node) ConfigureChain(cid int, key string, value string) error {
 			} else {
 				c.cfg.ValidateTxOnSend = bc
 			}
+		case "notifier":
+			if len(value) == 0 {
+				c.cfg.Notifier = nil
+			} else if _, err := notify.ParseConfig([]byte(value)); err != nil {
+				return err
+			} else {
+				c.cfg.Notifier = json.RawMessage(value)
+			}
+		case "pruneRetention":
+			if intVal, err := strconv.ParseInt(value, 0, 64); err != nil {
+				return errors.Wrapf(err, "invalid value type")
+			} else if err := chain.CheckPruneRetention(intVal); err != nil {
+				return err
+			} else {
+				c.cfg.PruneRetention = intVal
+			}
+		case "backupSchedule", "backupRetention":
+			if err := n._configureBackup(c, key, value); err != nil {
+				return err
+			}
 		default:
 			return errors.Errorf("not found key %s", key)
 		}
@@ -790,6 +1001,22 @@ func (n *Node) ConfigureChain(cid int, key string, value string) error {
 	}
 }
 
+// _checkQuota checks the quotas of the chain. The chain can't reserve
+// more EE instances than the node has.
+func (n *Node) _checkQuota(nodeCache string, eeInstances int, nodeCacheMemory string,
+	rpcConcurrency int, networkBandwidth string,
+) error {
+	if err := chain.CheckQuota(nodeCache, eeInstances, nodeCacheMemory,
+		rpcConcurrency, networkBandwidth); err != nil {
+		return err
+	}
+	if eeInstances > n.rcfg.EEInstances {
+		return errors.IllegalArgumentError.Errorf(
+			"EEInstancesOverNode(chain=%d,node=%d)", eeInstances, n.rcfg.EEInstances)
+	}
+	return nil
+}
+
 func (n *Node) RunChainTask(cid int, task string, params json.RawMessage) error {
 	defer n.mtx.RUnlock()
 	n.mtx.RLock()
@@ -801,6 +1028,62 @@ func (n *Node) RunChainTask(cid int, task string, params json.RawMessage) error
 	return c.RunTask(task, params)
 }
 
+// ExportChain starts to export blocks of the chain to the directory.
+// The export runs along with the chain, so the chain should be started.
+func (n *Node) ExportChain(cid int, p *export.Params) error {
+	defer n.mtx.Unlock()
+	n.mtx.Lock()
+
+	c, err := n._get(cid)
+	if err != nil {
+		return err
+	}
+	if e, ok := n.exporters[cid]; ok && e.Status().State == export.StateRunning {
+		return errors.InvalidStateError.Errorf("ExportInProgress(dir=%s)", e.Status().Dir)
+	}
+	params := *p
+	params.Dir = n.cfg.ResolveAbsolute(p.Dir)
+	e, err := export.New(c, &params)
+	if err != nil {
+		return err
+	}
+	n.exporters[cid] = e
+	e.Start()
+	return nil
+}
+
+// GetChainExport returns the status of the last export of the chain.
+func (n *Node) GetChainExport(cid int) (*export.Status, error) {
+	defer n.mtx.RUnlock()
+	n.mtx.RLock()
+
+	if _, err := n._get(cid); err != nil {
+		return nil, err
+	}
+	e, ok := n.exporters[cid]
+	if !ok {
+		return nil, errors.NotFoundError.Errorf("NoExport(cid=%#x)", cid)
+	}
+	return e.Status(), nil
+}
+
+// StopChainExport stops the export of the chain. The export may continue
+// from the cursor in the directory later.
+func (n *Node) StopChainExport(cid int) error {
+	defer n.mtx.RUnlock()
+	n.mtx.RLock()
+
+	if _, err := n._get(cid); err != nil {
+		return err
+	}
+	e, ok := n.exporters[cid]
+	if !ok {
+		return errors.NotFoundError.Errorf("NoExport(cid=%#x)", cid)
+	}
+	e.Stop()
+	return nil
+}
+
 func (n *Node) GetChains() []*Chain {
 	defer n.mtx.RUnlock()
 	n.mtx.RLock()
@@ -862,6 +1145,21 @@ func (n *Node) Configure(key string, value string) error {
 		if err := n.pm.SetInstances(n.rcfg.EEInstances, n.rcfg.EEInstances, n.rcfg.EEInstances); err != nil {
 			return err
 		}
+	case "eeHeartbeat", "eeDeadline":
+		rcfg := *n.rcfg
+		if key == "eeHeartbeat" {
+			rcfg.EEHeartbeat = value
+		} else {
+			rcfg.EEDeadline = value
+		}
+		hc, err := rcfg.EEHealthConfig()
+		if err != nil {
+			return err
+		}
+		if err := n.pm.SetHealthConfig(hc); err != nil {
+			return err
+		}
+		n.rcfg.EEHeartbeat, n.rcfg.EEDeadline = rcfg.EEHeartbeat, rcfg.EEDeadline
 	case "rpcDefaultChannel":
 		n.rcfg.RPCDefaultChannel = value
 		n.srv.SetDefaultChannel(n.rcfg.RPCDefaultChannel)
@@ -902,6 +1200,63 @@ func (n *Node) Configure(key string, value string) error {
 	return nil
 }
 
+// allocEngines returns engines for the configuration. The java engine runs
+// in remote pools if they are configured.
+func allocEngines(cfg *StaticConfig, l log.Logger) ([]eeproxy.Engine, error) {
+	if cfg.EERemotePools == "" {
+		return eeproxy.AllocEngines(l, strings.Split(cfg.Engines, ",")...)
+	}
+	if cfg.EERemoteAddr == "" {
+		return nil, errors.IllegalArgumentError.New("NoAddressForRemotePools")
+	}
+	if err := checkSharedDir(cfg); err != nil {
+		return nil, err
+	}
+	pools, err := eeproxy.ParseRemotePools(cfg.EERemotePools)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	var remote eeproxy.Engine
+	for _, name := range strings.Split(cfg.Engines, ",") {
+		if name != "java" {
+			names = append(names, name)
+			continue
+		}
+		if remote, err = eeproxy.NewRemoteJavaEE(l, pools); err != nil {
+			return nil, err
+		}
+	}
+	if remote == nil {
+		return nil, errors.IllegalArgumentError.New("RemotePoolsWithoutJava")
+	}
+	ee, err := eeproxy.AllocEngines(l, names...)
+	if err != nil {
+		return nil, err
+	}
+	return append(ee, remote), nil
+}
+
+// checkSharedDir checks whether contracts of the chains are in the directory
+// shared with remote pools. Remote executors read contracts with the paths
+// of the node, so the directory should be mounted at the same path in the
+// hosts of the pools.
+func checkSharedDir(cfg *StaticConfig) error {
+	if cfg.EESharedDir == "" {
+		return errors.IllegalArgumentError.New("NoSharedDirForRemotePools")
+	}
+	shared := cfg.ResolveAbsolute(cfg.EESharedDir)
+	if fi, err := os.Stat(shared); err != nil || !fi.IsDir() {
+		return errors.IllegalArgumentError.Errorf("InvalidSharedDir(%s)", shared)
+	}
+	rel, err := filepath.Rel(shared, cfg.AbsBaseDir())
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return errors.IllegalArgumentError.Errorf(
+			"NodeDirNotShared(node_dir=%s,shared=%s)", cfg.AbsBaseDir(), shared)
+	}
+	return nil
+}
+
 func NewNode(
 	w module.Wallet,
 	cfg *StaticConfig,
@@ -935,7 +1290,7 @@ func NewNode(
 	}
 	srv := server.NewManager(config, w, l)
 
-	ee, err := eeproxy.AllocEngines(l, strings.Split(cfg.Engines, ",")...)
+	ee, err := allocEngines(cfg, l)
 	if err != nil {
 		log.Panicf("fail to create engines err=%+v", err)
 	}
@@ -944,10 +1299,25 @@ func NewNode(
 	if err != nil {
 		log.Panicf("fail to start EEManager err=%+v", err)
 	}
+	if cfg.EERemoteAddr != "" {
+		tlsCfg, err := ipc.NewTLSConfig(cfg.ResolveAbsolute(cfg.EETLSCert),
+			cfg.ResolveAbsolute(cfg.EETLSKey), cfg.ResolveAbsolute(cfg.EETLSCA), true)
+		if err != nil {
+			log.Panicf("fail to load TLS config for remote EE err=%+v", err)
+		}
+		if err := pm.ListenRemote(cfg.EERemoteAddr, tlsCfg); err != nil {
+			log.Panicf("fail to listen remote EE err=%+v", err)
+		}
+	}
 
 	if err := pm.SetInstances(rcfg.EEInstances, rcfg.EEInstances, rcfg.EEInstances); err != nil {
 		log.Panicf("fail to EEManager.SetInstances err=%+v", err)
 	}
+	if hc, err := rcfg.EEHealthConfig(); err != nil {
+		log.Panicf("fail to parse health config of EE err=%+v", err)
+	} else if err := pm.SetHealthConfig(hc); err != nil {
+		log.Panicf("fail to EEManager.SetHealthConfig err=%+v", err)
+	}
 	go func() {
 		if err := pm.Loop(); err != nil {
 			log.Panic(err)
@@ -958,16 +1328,18 @@ func NewNode(
 	cliSrv.e.Logger.SetOutput(l.WriterLevel(log.DebugLevel))
 
 	n := &Node{
-		w:        w,
-		nt:       nt,
-		srv:      srv,
-		pm:       pm,
-		logger:   l,
-		cfg:      *cfg,
-		rcfg:     rcfg,
-		chains:   make(map[string]*Chain),
-		channels: make(map[int]string),
-		cliSrv:   cliSrv,
+		w:         w,
+		nt:        nt,
+		srv:       srv,
+		pm:        pm,
+		logger:    l,
+		cfg:       *cfg,
+		rcfg:      rcfg,
+		chains:    make(map[string]*Chain),
+		channels:  make(map[int]string),
+		exporters: make(map[int]*export.Exporter),
+		backups:   make(map[int]*backupScheduler),
+		cliSrv:    cliSrv,
 	}
 
 	// Load chains
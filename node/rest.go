@@ -17,6 +17,8 @@ import (
 	"github.com/labstack/echo/v4"
 
 	"github.com/icon-project/goloop/chain"
+	"github.com/icon-project/goloop/chain/export"
+	"github.com/icon-project/goloop/chain/notify"
 	"github.com/icon-project/goloop/common"
 	"github.com/icon-project/goloop/common/db"
 	"github.com/icon-project/goloop/common/errors"
@@ -84,25 +86,35 @@ type ChainInspectView struct {
 }
 
 type ChainConfig struct {
-	DBType           string `json:"dbType"`
-	Platform         string `json:"platform"`
-	SeedAddr         string `json:"seedAddress"`
-	Role             uint   `json:"role"`
-	ConcurrencyLevel int    `json:"concurrencyLevel,omitempty"`
-	NormalTxPoolSize int    `json:"normalTxPool,omitempty"`
-	PatchTxPoolSize  int    `json:"patchTxPool,omitempty"`
-	MaxBlockTxBytes  int    `json:"maxBlockTxBytes,omitempty"`
-	NodeCache        string `json:"nodeCache,omitempty"`
-	Channel          string `json:"channel"`
-	SecureSuites     string `json:"secureSuites"`
-	SecureAeads      string `json:"secureAeads"`
-	DefWaitTimeout   int64  `json:"defaultWaitTimeout"`
-	MaxWaitTimeout   int64  `json:"maxWaitTimeout"`
-	TxTimeout        int64  `json:"txTimeout"`
-	AutoStart        bool   `json:"autoStart"`
-	ChildrenLimit    *int   `json:"childrenLimit,omitempty"`
-	NephewsLimit     *int   `json:"nephewsLimit,omitempty"`
-	ValidateTxOnSend bool   `json:"validateTxOnSend,omitempty"`
+	DBType           string          `json:"dbType"`
+	Platform         string          `json:"platform"`
+	PlatformConfig   json.RawMessage `json:"platformConfig,omitempty"`
+	SeedAddr         string          `json:"seedAddress"`
+	Role             uint            `json:"role"`
+	ConcurrencyLevel int             `json:"concurrencyLevel,omitempty"`
+	NormalTxPoolSize int             `json:"normalTxPool,omitempty"`
+	PatchTxPoolSize  int             `json:"patchTxPool,omitempty"`
+	MaxBlockTxBytes  int             `json:"maxBlockTxBytes,omitempty"`
+	NodeCache        string          `json:"nodeCache,omitempty"`
+	Channel          string          `json:"channel"`
+	SecureSuites     string          `json:"secureSuites"`
+	SecureAeads      string          `json:"secureAeads"`
+	Compressions     string          `json:"compressions"`
+	DefWaitTimeout   int64           `json:"defaultWaitTimeout"`
+	MaxWaitTimeout   int64           `json:"maxWaitTimeout"`
+	TxTimeout        int64           `json:"txTimeout"`
+	AutoStart        bool            `json:"autoStart"`
+	ChildrenLimit    *int            `json:"childrenLimit,omitempty"`
+	NephewsLimit     *int            `json:"nephewsLimit,omitempty"`
+	ValidateTxOnSend bool            `json:"validateTxOnSend,omitempty"`
+	Notifier         json.RawMessage `json:"notifier,omitempty"`
+	PruneRetention   int64           `json:"pruneRetention,omitempty"`
+	BackupSchedule   string          `json:"backupSchedule,omitempty"`
+	BackupRetention  string          `json:"backupRetention,omitempty"`
+	EEInstances      int             `json:"eeInstances,omitempty"`
+	NodeCacheMemory  string          `json:"nodeCacheMemory,omitempty"`
+	RPCConcurrency   int             `json:"rpcConcurrency,omitempty"`
+	NetworkBandwidth string          `json:"networkBandwidth,omitempty"`
 }
 
 type ChainResetParam struct {
@@ -120,10 +132,25 @@ type ChainPruneParam struct {
 	Height int64  `json:"height"`
 }
 
+type ChainForkParam struct {
+	CID        common.HexInt32   `json:"cid"`
+	NID        common.HexInt32   `json:"nid,omitempty"`
+	Channel    string            `json:"channel,omitempty"`
+	Height     int64             `json:"height"`
+	Validators []*common.Address `json:"validators"`
+}
+
 type ChainBackupParam struct {
 	Manual bool `json:"manual,omitempty"`
 }
 
+type ChainExportParam struct {
+	From   int64  `json:"from"`
+	To     int64  `json:"to"`
+	Format string `json:"format"`
+	Dir    string `json:"dir"`
+}
+
 type ConfigureParam struct {
 	Key   string `json:"key"`
 	Value string `json:"value"`
@@ -168,6 +195,7 @@ func NewChainConfig(cfg *chain.Config) *ChainConfig {
 	v := &ChainConfig{
 		DBType:           cfg.DBType,
 		Platform:         cfg.Platform,
+		PlatformConfig:   cfg.PlatformConfig,
 		SeedAddr:         cfg.SeedAddr,
 		Role:             cfg.Role,
 		ConcurrencyLevel: cfg.ConcurrencyLevel,
@@ -178,6 +206,7 @@ func NewChainConfig(cfg *chain.Config) *ChainConfig {
 		Channel:          cfg.Channel,
 		SecureSuites:     cfg.SecureSuites,
 		SecureAeads:      cfg.SecureAeads,
+		Compressions:     cfg.Compressions,
 		DefWaitTimeout:   cfg.DefWaitTimeout,
 		MaxWaitTimeout:   cfg.MaxWaitTimeout,
 		TxTimeout:        cfg.TxTimeout,
@@ -185,10 +214,28 @@ func NewChainConfig(cfg *chain.Config) *ChainConfig {
 		ChildrenLimit:    cfg.ChildrenLimit,
 		NephewsLimit:     cfg.NephewsLimit,
 		ValidateTxOnSend: cfg.ValidateTxOnSend,
+		Notifier:         notify.RedactConfig(cfg.Notifier),
+		PruneRetention:   cfg.PruneRetention,
+		BackupSchedule:   cfg.BackupSchedule,
+		BackupRetention:  cfg.BackupRetention,
+		EEInstances:      cfg.EEInstances,
+		NodeCacheMemory:  cfg.NodeCacheMemory,
+		RPCConcurrency:   cfg.RPCConcurrency,
+		NetworkBandwidth: cfg.NetworkBandwidth,
 	}
 	return v
 }
 
+func inspectQuota(c module.Chain, informal bool) map[string]interface{} {
+	if nc, ok := c.(*Chain); ok {
+		c = nc.Chain
+	}
+	if qc, ok := c.(module.QuotaChain); ok {
+		return qc.InspectQuota()
+	}
+	return nil
+}
+
 func RegisterInspectFunc(name string, f InspectFunc) error {
 	if _, ok := inspectFuncs[name]; ok {
 		return fmt.Errorf("already exist function name:%s", name)
@@ -216,6 +263,7 @@ func RegisterRest(n *Node) {
 	_ = RegisterInspectFunc("metrics", metric.Inspect)
 	_ = RegisterInspectFunc("network", network.Inspect)
 	_ = RegisterInspectFunc("service", service.Inspect)
+	_ = RegisterInspectFunc("quota", inspectQuota)
 
 	// json rpc
 	n.srv.RegisterAPIHandler(n.cliSrv.e.Group("/api"))
@@ -236,7 +284,12 @@ func (r *Rest) RegisterChainHandlers(g *echo.Group) {
 	g.POST(UrlChainRes+"/verify", r.VerifyChain, r.ChainInjector)
 	g.POST(UrlChainRes+"/import", r.ImportChain, r.ChainInjector)
 	g.POST(UrlChainRes+"/prune", r.PruneChain, r.ChainInjector)
+	g.POST(UrlChainRes+"/fork", r.ForkChain, r.ChainInjector)
 	g.POST(UrlChainRes+"/backup", r.BackupChain, r.ChainInjector)
+	g.GET(UrlChainRes+"/backup", r.GetChainBackup, r.ChainInjector)
+	g.GET(UrlChainRes+"/export", r.GetChainExport, r.ChainInjector)
+	g.POST(UrlChainRes+"/export", r.ExportChain, r.ChainInjector)
+	g.DELETE(UrlChainRes+"/export", r.StopChainExport, r.ChainInjector)
 	route := g.GET(UrlChainRes+"/genesis", r.GetChainGenesis, r.ChainInjector)
 	if r.a != nil {
 		r.a.SetSkip(route, false)
@@ -415,6 +468,21 @@ func (r *Rest) PruneChain(ctx echo.Context) error {
 	return ctx.String(http.StatusOK, "OK")
 }
 
+func (r *Rest) ForkChain(ctx echo.Context) error {
+	c := ctx.Get("chain").(*Chain)
+	param := &ChainForkParam{}
+	if err := ctx.Bind(param); err != nil {
+		return echo.ErrBadRequest
+	}
+	if param.Height < 2 || param.CID.Value == 0 || len(param.Validators) == 0 {
+		return echo.ErrBadRequest
+	}
+	if err := r.n.ForkChain(c.CID(), param); err != nil {
+		return err
+	}
+	return ctx.String(http.StatusOK, "OK")
+}
+
 func (r *Rest) BackupChain(ctx echo.Context) error {
 	c := ctx.Get("chain").(*Chain)
 	param := &ChainBackupParam{}
@@ -428,6 +496,61 @@ func (r *Rest) BackupChain(ctx echo.Context) error {
 	}
 }
 
+func (r *Rest) GetChainBackup(ctx echo.Context) error {
+	c := ctx.Get("chain").(*Chain)
+	status, err := r.n.GetChainBackup(c.CID())
+	if err != nil {
+		if errors.NotFoundError.Equals(err) {
+			return ctx.String(http.StatusNotFound, err.Error())
+		}
+		return err
+	}
+	return ctx.JSON(http.StatusOK, status)
+}
+
+func (r *Rest) ExportChain(ctx echo.Context) error {
+	c := ctx.Get("chain").(*Chain)
+	param := &ChainExportParam{}
+	if err := ctx.Bind(param); err != nil {
+		return echo.ErrBadRequest
+	}
+	if len(param.Dir) == 0 {
+		return echo.ErrBadRequest
+	}
+	if err := r.n.ExportChain(c.CID(), &export.Params{
+		From:   param.From,
+		To:     param.To,
+		Format: param.Format,
+		Dir:    param.Dir,
+	}); err != nil {
+		return err
+	}
+	return ctx.String(http.StatusOK, "OK")
+}
+
+func (r *Rest) GetChainExport(ctx echo.Context) error {
+	c := ctx.Get("chain").(*Chain)
+	status, err := r.n.GetChainExport(c.CID())
+	if err != nil {
+		if errors.NotFoundError.Equals(err) {
+			return ctx.String(http.StatusNotFound, err.Error())
+		}
+		return err
+	}
+	return ctx.JSON(http.StatusOK, status)
+}
+
+func (r *Rest) StopChainExport(ctx echo.Context) error {
+	c := ctx.Get("chain").(*Chain)
+	if err := r.n.StopChainExport(c.CID()); err != nil {
+		if errors.NotFoundError.Equals(err) {
+			return ctx.String(http.StatusNotFound, err.Error())
+		}
+		return err
+	}
+	return ctx.String(http.StatusOK, "OK")
+}
+
 func (r *Rest) GetChainGenesis(ctx echo.Context) error {
 	c := ctx.Get("chain").(*Chain)
 	gsFile := path.Join(c.cfg.AbsBaseDir(), ChainGenesisZipFileName)
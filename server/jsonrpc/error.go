@@ -72,6 +72,8 @@ func (c ErrorCode) String() string {
 		return "Timeout"
 	case ErrorCodeSystemTimeout:
 		return "SystemTimeout"
+	case ErrorCodePruned:
+		return "Pruned"
 	default:
 		switch {
 		case c < ErrorCodeServer && c > ErrorCodeServer-1000:
@@ -104,6 +106,7 @@ const (
 	ErrorLackOfResource     ErrorCode = -31005
 	ErrorCodeTimeout        ErrorCode = -31006
 	ErrorCodeSystemTimeout  ErrorCode = -31007
+	ErrorCodePruned         ErrorCode = -31008
 )
 
 type Error struct {
@@ -0,0 +1,65 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metric
+
+import (
+	"context"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+const (
+	BackupResultSuccess = "success"
+	BackupResultFailure = "failure"
+)
+
+var (
+	msBackup            = stats.Int64("backup", "Scheduled Backup", stats.UnitDimensionless)
+	msBackupDuration    = stats.Int64("backup_duration", "Scheduled Backup Duration", stats.UnitMilliseconds)
+	msBackupSize        = stats.Int64("backup_size", "Scheduled Backup Size", stats.UnitBytes)
+	msBackupLastSuccess = stats.Int64("backup_last_success", "Time of the last successful backup", stats.UnitSeconds)
+	mkBackupResult      = NewMetricKey("result")
+	backupMks           = []tag.Key{mkBackupResult}
+)
+
+func RegisterBackup() {
+	RegisterMetricView(msBackup, view.Count(), backupMks)
+	RegisterMetricView(msBackupDuration, view.LastValue(), []tag.Key{})
+	RegisterMetricView(msBackupSize, view.LastValue(), []tag.Key{})
+	RegisterMetricView(msBackupLastSuccess, view.LastValue(), []tag.Key{})
+}
+
+// RecordBackup records the result of the scheduled backup of the chain.
+// The size is used only for successful backup.
+func RecordBackup(ctx context.Context, d time.Duration, size int64, err error) {
+	if err != nil {
+		rctx := GetMetricContext(ctx, &mkBackupResult, BackupResultFailure)
+		stats.Record(rctx, msBackup.M(1))
+		stats.Record(ctx, msBackupDuration.M(int64(d/time.Millisecond)))
+		return
+	}
+	rctx := GetMetricContext(ctx, &mkBackupResult, BackupResultSuccess)
+	stats.Record(rctx, msBackup.M(1))
+	stats.Record(ctx,
+		msBackupDuration.M(int64(d/time.Millisecond)),
+		msBackupSize.M(size),
+		msBackupLastSuccess.M(time.Now().Unix()),
+	)
+}
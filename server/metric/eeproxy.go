@@ -0,0 +1,42 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metric
+
+import (
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+var (
+	msEERestart = stats.Int64("ee_restart", "Restart Execution Engine", stats.UnitDimensionless)
+	mkEEType    = NewMetricKey("ee_type")
+	mkEEReason  = NewMetricKey("reason")
+	eeMks       = []tag.Key{mkEEType, mkEEReason}
+)
+
+func RegisterEEProxy() {
+	RegisterMetricView(msEERestart, view.Count(), eeMks)
+}
+
+// RecordEERestart records a restart of the execution engine. Execution
+// engines are shared by chains, so it's recorded without the chain.
+func RecordEERestart(eeType, reason string) {
+	ctx := GetMetricContext(DefaultMetricContext(), &mkEEType, eeType)
+	ctx = GetMetricContext(ctx, &mkEEReason, reason)
+	stats.Record(ctx, msEERestart.M(1))
+}
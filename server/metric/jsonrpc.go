@@ -32,6 +32,13 @@ var (
 			stats.Int64("jsonrpc_call_avg", "moving average of jsonrpc icx_call method", "ns"),
 			emptyMks,
 		},
+		"icx_multiCall": {
+			stats.Int64("jsonrpc_multi_call", "jsonrpc icx_multiCall method", "ns"),
+			stats.Int64("jsonrpc_multi_call_avg", "moving average of jsonrpc icx_multiCall method", "ns"),
+			emptyMks,
+		},
+		"icx_getRewardHistory":     msRetrieve,
+		"icx_getValidationHistory": msRetrieve,
 		"icx_getBalance":           msRetrieve,
 		"icx_getScoreApi":          msRetrieve,
 		"icx_getTotalSupply":       msRetrieve,
@@ -74,6 +81,13 @@ var (
 			stats.Int64("jsonrpc_estimate_step_avg", "moving average of jsonrpc debug_estimateStep method", "ns"),
 			emptyMks,
 		},
+		"debug_getStorage":          msRetrieve,
+		"debug_diffStorage":         msRetrieve,
+		"dev_mine":                  msRetrieve,
+		"dev_setNextBlockTimestamp": msRetrieve,
+		"dev_snapshot":              msRetrieve,
+		"dev_revert":                msRetrieve,
+		"dev_setBalance":            msRetrieve,
 		"rosetta_getTrace": {
 			stats.Int64("jsonrpc_rosetta_trace_", "jsonrpc rosetta_getTrace method", "ns"),
 			stats.Int64("jsonrpc_rosetta_trace_avg", "moving average of jsonrpc rosetta_getTTrace method", "ns"),
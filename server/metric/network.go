@@ -18,11 +18,25 @@ var (
 	networkMks = []tag.Key{mkDest, mkProtocol}
 )
 
+// raw and compressed bytes of the compressed packets,
+// compression ratio is network_send_compressed / network_send_raw
+var (
+	msSendRaw        = stats.Int64("network_send_raw", "send raw of compressed", stats.UnitBytes)
+	msSendCompressed = stats.Int64("network_send_compressed", "send compressed", stats.UnitBytes)
+	msRecvRaw        = stats.Int64("network_recv_raw", "recv raw of compressed", stats.UnitBytes)
+	msRecvCompressed = stats.Int64("network_recv_compressed", "recv compressed", stats.UnitBytes)
+	compressMks      = []tag.Key{mkProtocol}
+)
+
 func RegisterNetwork() {
 	RegisterMetricView(msSend, view.Count(), networkMks)
 	RegisterMetricView(msSend, view.Sum(), networkMks)
 	RegisterMetricView(msRecv, view.Count(), networkMks)
 	RegisterMetricView(msRecv, view.Sum(), networkMks)
+	RegisterMetricView(msSendRaw, view.Sum(), compressMks)
+	RegisterMetricView(msSendCompressed, view.Sum(), compressMks)
+	RegisterMetricView(msRecvRaw, view.Sum(), compressMks)
+	RegisterMetricView(msRecvCompressed, view.Sum(), compressMks)
 }
 
 type NetworkMetric struct {
@@ -69,6 +83,27 @@ func (m *NetworkMetric) OnRecv(dest byte, ttl byte, hint byte, protocol uint16,
 	stats.Record(ctx, msRecv.M(int64(pktLen)))
 }
 
+func (m *NetworkMetric) getCompressMetricContext(protocol uint16) context.Context {
+	strProtocol := fmt.Sprintf("%#04x", protocol)
+	key := "compress" + strProtocol
+	ctx, ok := m.get(key)
+	if !ok {
+		ctx = GetMetricContext(m.ctx, &mkProtocol, strProtocol)
+		m.put(key, ctx)
+	}
+	return ctx
+}
+
+func (m *NetworkMetric) OnSendCompressed(protocol uint16, rawLen uint32, compressedLen uint32) {
+	ctx := m.getCompressMetricContext(protocol)
+	stats.Record(ctx, msSendRaw.M(int64(rawLen)), msSendCompressed.M(int64(compressedLen)))
+}
+
+func (m *NetworkMetric) OnRecvCompressed(protocol uint16, rawLen uint32, compressedLen uint32) {
+	ctx := m.getCompressMetricContext(protocol)
+	stats.Record(ctx, msRecvRaw.M(int64(rawLen)), msRecvCompressed.M(int64(compressedLen)))
+}
+
 func NewNetworkMetric(ctx context.Context) *NetworkMetric {
 	return &NetworkMetric{
 		ctx: ctx,
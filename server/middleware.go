@@ -9,6 +9,7 @@ import (
 
 	"github.com/labstack/echo/v4"
 
+	"github.com/icon-project/goloop/module"
 	"github.com/icon-project/goloop/server/jsonrpc"
 )
 
@@ -44,6 +45,25 @@ func ChainInjector(srv *Manager) echo.MiddlewareFunc {
 	}
 }
 
+// LimitRPC limits the number of requests handled concurrently for the
+// chain injected by ChainInjector.
+func LimitRPC() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			qc, ok := ctx.Get("chain").(module.QuotaChain)
+			if !ok {
+				return next(ctx)
+			}
+			release, err := qc.AcquireRPC()
+			if err != nil {
+				return jsonrpc.ErrorLackOfResource.New(err.Error())
+			}
+			defer release()
+			return next(ctx)
+		}
+	}
+}
+
 // Chunk()
 func Chunk() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
@@ -219,30 +219,39 @@ func (srv *Manager) RegisterAPIHandler(g *echo.Group) {
 	mr := v3.MethodRepository(srv.mtr)
 	v3api := rpc.Group("/v3")
 	v3api.Use(JsonRpc(), Chunk())
-	v3api.POST("", mr.Handle, ChainInjector(srv))
-	v3api.POST("/", mr.Handle, ChainInjector(srv))
-	v3api.POST("/:channel", mr.Handle, ChainInjector(srv))
+	v3api.POST("", mr.Handle, ChainInjector(srv), LimitRPC())
+	v3api.POST("/", mr.Handle, ChainInjector(srv), LimitRPC())
+	v3api.POST("/:channel", mr.Handle, ChainInjector(srv), LimitRPC())
 
 	dmr := v3.DebugMethodRepository(srv.mtr)
 	v3dbg := rpc.Group("/v3d")
 	v3dbg.Use(srv.CheckDebug(), JsonRpc(), Chunk())
-	v3dbg.POST("", dmr.Handle, ChainInjector(srv))
-	v3dbg.POST("/", dmr.Handle, ChainInjector(srv))
-	v3dbg.POST("/:channel", dmr.Handle, ChainInjector(srv))
+	v3dbg.POST("", dmr.Handle, ChainInjector(srv), LimitRPC())
+	v3dbg.POST("/", dmr.Handle, ChainInjector(srv), LimitRPC())
+	v3dbg.POST("/:channel", dmr.Handle, ChainInjector(srv), LimitRPC())
+
+	// Dev APIs, available only for the chain in dev mode
+	devmr := v3.DevMethodRepository(srv.mtr)
+	v3dev := rpc.Group("/v3dev")
+	v3dev.Use(JsonRpc(), Chunk())
+	v3dev.POST("", devmr.Handle, ChainInjector(srv), LimitRPC())
+	v3dev.POST("/", devmr.Handle, ChainInjector(srv), LimitRPC())
+	v3dev.POST("/:channel", devmr.Handle, ChainInjector(srv), LimitRPC())
 
 	// Rosetta APIs
 	rmr := v3.RosettaMethodRepository(srv.mtr)
 	rosetta := rpc.Group("/rosetta")
 	rosetta.Use(srv.CheckRosetta(), JsonRpc(), Chunk())
-	rosetta.POST("", rmr.Handle, ChainInjector(srv))
-	rosetta.POST("/", rmr.Handle, ChainInjector(srv))
-	rosetta.POST("/:channel", rmr.Handle, ChainInjector(srv))
+	rosetta.POST("", rmr.Handle, ChainInjector(srv), LimitRPC())
+	rosetta.POST("/", rmr.Handle, ChainInjector(srv), LimitRPC())
+	rosetta.POST("/:channel", rmr.Handle, ChainInjector(srv), LimitRPC())
 
 	// group for websocket
 	ws := g.Group("")
 	ws.GET("/v3/:channel/block", srv.wssm.RunBlockSession, ChainInjector(srv))
 	ws.GET("/v3/:channel/event", srv.wssm.RunEventSession, ChainInjector(srv))
 	ws.GET("/v3/:channel/btp", srv.wssm.RunBtpSession, ChainInjector(srv))
+	ws.GET("/v3/:channel/validation", srv.wssm.RunValidationSession, ChainInjector(srv))
 }
 
 func (srv *Manager) RegisterMetricsHandler(g *echo.Group) {
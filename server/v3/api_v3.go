@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"encoding/base64"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"math/big"
 	"strconv"
 	"strings"
 	"time"
@@ -37,6 +39,9 @@ func MethodRepository(mtr *metric.JsonrpcMetric) *jsonrpc.MethodRepository {
 	mr.RegisterMethod("icx_getBlockByHeight", getBlockByHeight)
 	mr.RegisterMethod("icx_getBlockByHash", getBlockByHash)
 	mr.RegisterMethod("icx_call", call)
+	mr.RegisterMethod("icx_multiCall", multiCall)
+	mr.RegisterMethod("icx_getRewardHistory", getRewardHistory)
+	mr.RegisterMethod("icx_getValidationHistory", getValidationHistory)
 	mr.RegisterMethod("icx_getBalance", getBalance)
 	mr.RegisterMethod("icx_getScoreApi", getScoreApi)
 	mr.RegisterMethod("icx_getTotalSupply", getTotalSupply)
@@ -152,7 +157,19 @@ func (c *contextWithBM) Init(ctx *jsonrpc.Context) error {
 	return nil
 }
 
-func (c *contextWithBM) GetBlockByHeight(height jsonrpc.HexInt) (module.Block, error) {
+// CheckPrunedHeight returns jsonrpc.ErrorCodePruned if bodies, receipts
+// and states of the block have been removed by pruning.
+func (c *contextWithBM) CheckPrunedHeight(height int64) error {
+	if pruned := c.bm.PrunedHeight(); height <= pruned {
+		return jsonrpc.ErrorCodePruned.Errorf(
+			"PrunedBlock(height=%d,pruned=%d)", height, pruned)
+	}
+	return nil
+}
+
+// GetBlockHeaderByHeight returns the block at the height. Only the header
+// of the block is available if it has been pruned.
+func (c *contextWithBM) GetBlockHeaderByHeight(height jsonrpc.HexInt) (module.Block, error) {
 	if height == "" {
 		blk, err := c.bm.GetLastBlock()
 		return blk, c.AsRPCError(err)
@@ -169,6 +186,17 @@ func (c *contextWithBM) GetBlockByHeight(height jsonrpc.HexInt) (module.Block, e
 	}
 }
 
+func (c *contextWithBM) GetBlockByHeight(height jsonrpc.HexInt) (module.Block, error) {
+	blk, err := c.GetBlockHeaderByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.CheckPrunedHeight(blk.Height()); err != nil {
+		return nil, err
+	}
+	return blk, nil
+}
+
 func (c *contextWithBM) GetBlockByID(id []byte) (module.Block, error) {
 	blk, err := c.bm.GetBlock(id)
 	if err != nil {
@@ -177,6 +205,9 @@ func (c *contextWithBM) GetBlockByID(id []byte) (module.Block, error) {
 	if err = c.CheckBaseHeight(blk.Height()); err != nil {
 		return nil, err
 	}
+	if err = c.CheckPrunedHeight(blk.Height()); err != nil {
+		return nil, err
+	}
 	return blk, nil
 }
 
@@ -304,24 +335,178 @@ func call(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
 		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
 	}
 
+	if param.Overrides != nil && !overridesAllowed(&c.contextWithChain) {
+		return nil, jsonrpc.ErrorCodeInvalidParams.New("OverridesDisabled")
+	}
+
 	blk, err := c.GetBlockByHeight(param.Height)
 	if err != nil {
 		return nil, err
 	}
 
-	bi := common.NewBlockInfo(blk.Height(), blk.Timestamp())
-	result, err := c.sm.Call(blk.Result(), blk.NextValidators(), params.RawMessage(), bi)
+	bi, err := param.Overrides.BlockInfo(common.NewBlockInfo(blk.Height(), blk.Timestamp()))
 	if err != nil {
-		if service.InvalidQueryError.Equals(err) {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+	}
+	ovs, err := param.Overrides.AccountOverrides()
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+	}
+	js, err := paramsWithoutOverrides(params)
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+	}
+	result, err := c.sm.Call(blk.Result(), blk.NextValidators(), js, bi, ovs)
+	if err != nil {
+		return nil, callError(err, c.debug)
+	} else {
+		return result, nil
+	}
+}
+
+func callError(err error, debug bool) *jsonrpc.Error {
+	if service.InvalidQueryError.Equals(err) {
+		return jsonrpc.ErrorCodeInvalidParams.Wrap(err, debug)
+	} else if scoreresult.IsValid(err) {
+		return jsonrpc.ErrScore(err, debug)
+	} else {
+		return jsonrpc.ErrorCodeSystem.Wrap(err, debug)
+	}
+}
+
+func multiCall(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
+	var c contextWithSM
+	if err := c.Init(ctx); err != nil {
+		return nil, err
+	}
+
+	var param MultiCallParam
+	if err := params.Convert(&param); err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+	}
+	if len(param.Calls) > ctx.BatchLimit() {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Errorf(
+			"too many calls (limit=%d)", ctx.BatchLimit())
+	}
+
+	jss := make([][]byte, len(param.Calls))
+	for i, call := range param.Calls {
+		if len(call.Height) > 0 || call.Overrides != nil {
+			return nil, jsonrpc.ErrorCodeInvalidParams.Errorf(
+				"height or overrides in calls[%d]", i)
+		}
+		js, err := json.Marshal(call)
+		if err != nil {
 			return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
-		} else if scoreresult.IsValid(err) {
-			return nil, jsonrpc.ErrScore(err, c.debug)
+		}
+		jss[i] = js
+	}
+
+	blk, err := c.GetBlockByHeight(param.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	bi := common.NewBlockInfo(blk.Height(), blk.Timestamp())
+	results, err := c.sm.MultiCall(blk.Result(), blk.NextValidators(), jss, bi)
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, c.debug)
+	}
+	res := make([]interface{}, len(results))
+	for i, r := range results {
+		if r.Error != nil {
+			res[i] = map[string]interface{}{
+				"error": callError(r.Error, c.debug),
+			}
 		} else {
-			return nil, jsonrpc.ErrorCodeSystem.Wrap(err, c.debug)
+			res[i] = map[string]interface{}{
+				"result": r.Result,
+			}
 		}
-	} else {
-		return result, nil
 	}
+	return res, nil
+}
+
+// getRewardHistory returns reward history of the account kept by the node.
+// It's available only on platforms keeping it.
+func getRewardHistory(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
+	var c contextWithChain
+	if err := c.Init(ctx); err != nil {
+		return nil, err
+	}
+	var history module.RewardHistory
+	if rc, ok := c.chain.(module.RewardHistoryChain); ok {
+		history = rc.RewardHistory()
+	}
+	if history == nil {
+		return nil, jsonrpc.ErrorCodeMethodNotFound.New("RewardHistoryDisabled")
+	}
+
+	var param RewardHistoryParam
+	if err := params.Convert(&param); err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+	}
+	addr := param.Address.Address()
+	var startHeight, count int64
+	if len(param.StartHeight) > 0 {
+		v, err := param.StartHeight.Int64()
+		if err != nil {
+			return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+		}
+		startHeight = v
+	}
+	if len(param.Count) > 0 {
+		v, err := param.Count.ParseInt(32)
+		if err != nil {
+			return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+		}
+		count = v
+	}
+	result, err := history.GetRewardHistory(addr, startHeight, int(count))
+	if err != nil {
+		if errors.IllegalArgumentError.Equals(err) {
+			return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+		}
+		return nil, c.AsRPCError(err)
+	}
+	return result, nil
+}
+
+// getValidationHistory returns validation history of the validator kept by
+// the node. It's available only on platforms keeping it.
+func getValidationHistory(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
+	var c contextWithChain
+	if err := c.Init(ctx); err != nil {
+		return nil, err
+	}
+	var history module.ValidationHistory
+	if vc, ok := c.chain.(module.ValidationHistoryChain); ok {
+		history = vc.ValidationHistory()
+	}
+	if history == nil {
+		return nil, jsonrpc.ErrorCodeMethodNotFound.New("ValidationHistoryDisabled")
+	}
+
+	var param ValidationHistoryParam
+	if err := params.Convert(&param); err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+	}
+	var threshold int64
+	if len(param.Threshold) > 0 {
+		v, err := param.Threshold.Int64()
+		if err != nil {
+			return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+		}
+		threshold = v
+	}
+	result, err := history.GetValidationHistory(param.Address.Address(), threshold)
+	if err != nil {
+		if errors.IllegalArgumentError.Equals(err) {
+			return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+		}
+		return nil, c.AsRPCError(err)
+	}
+	return result, nil
 }
 
 func getBalance(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
@@ -432,6 +617,9 @@ func getTransactionResult(ctx *jsonrpc.Context, params *jsonrpc.Params) (interfa
 	if err = c.CheckBaseHeight(blk.Height()); err != nil {
 		return nil, err
 	}
+	if err = c.CheckPrunedHeight(blk.Height()); err != nil {
+		return nil, err
+	}
 	receipt, err := txInfo.GetReceipt()
 	if block.ResultNotFinalizedError.Equals(err) {
 		return nil, jsonrpc.ErrorCodeExecuting.New("Executing")
@@ -467,6 +655,9 @@ func getTransactionByHash(ctx *jsonrpc.Context, params *jsonrpc.Params) (interfa
 	if err != nil {
 		return nil, c.AsRPCError(err)
 	}
+	if err = c.CheckPrunedHeight(txInfo.Block().Height()); err != nil {
+		return nil, err
+	}
 
 	tx, err := txInfo.Transaction()
 	if err != nil {
@@ -571,7 +762,7 @@ func getBlockHeaderByHeight(ctx *jsonrpc.Context, params *jsonrpc.Params) (inter
 		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
 	}
 
-	blk, err := c.GetBlockByHeight(param.Height)
+	blk, err := c.GetBlockHeaderByHeight(param.Height)
 	if err != nil {
 		return nil, err
 	}
@@ -1093,6 +1284,9 @@ func waitTransactionResultOnChannel(c *contextWithBM, id []byte, timeout time.Du
 	if err = c.CheckBaseHeight(blk.Height()); err != nil {
 		return nil, err
 	}
+	if err = c.CheckPrunedHeight(blk.Height()); err != nil {
+		return nil, err
+	}
 	res, err := receipt.ToJSON(module.JSONVersion3)
 	if err != nil {
 		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, c.debug)
@@ -1112,45 +1306,108 @@ func DebugMethodRepository(mtr *metric.JsonrpcMetric) *jsonrpc.MethodRepository
 
 	mr.RegisterMethod("debug_getTrace", getTrace)
 	mr.RegisterMethod("debug_estimateStep", estimateStep)
+	mr.RegisterMethod("debug_getStorage", getStorage)
+	mr.RegisterMethod("debug_diffStorage", diffStorage)
 
 	return mr
 }
 
+const (
+	TraceModeInvoke     = "invoke"
+	TraceModeAccessList = "accessList"
+	TraceModeCallTree   = "callTree"
+)
+
+func traceModeOf(s string) (module.TraceMode, bool) {
+	switch s {
+	case "", TraceModeInvoke:
+		return module.TraceModeInvoke, true
+	case TraceModeAccessList:
+		return module.TraceModeAccessList, true
+	case TraceModeCallTree:
+		return module.TraceModeCallTree, true
+	default:
+		return module.TraceModeNone, false
+	}
+}
+
+func findBlockAndTxInfoByTraceParam(
+	c *contextWithSM,
+	param *TraceParam,
+) (module.Block, module.TransactionInfo, error) {
+	switch {
+	case len(param.Hash) > 0:
+		txInfo, err := c.bm.GetTransactionInfo(param.Hash.Bytes())
+		if errors.NotFoundError.Equals(err) {
+			if c.sm.HasTransaction(param.Hash.Bytes()) {
+				return nil, nil, jsonrpc.ErrorCodePending.New("Pending")
+			}
+			return nil, nil, jsonrpc.ErrorCodeNotFound.Wrap(err, c.debug)
+		} else if err != nil {
+			return nil, nil, jsonrpc.ErrorCodeSystem.Wrap(err, c.debug)
+		}
+
+		if txInfo.Group() == module.TransactionGroupPatch {
+			return nil, nil, jsonrpc.ErrorCodeInvalidParams.New("Patch transaction can't be replayed")
+		}
+
+		blk := txInfo.Block()
+		if err = c.CheckBaseHeight(blk.Height()); err != nil {
+			return nil, nil, err
+		}
+		if err = c.CheckPrunedHeight(blk.Height()); err != nil {
+			return nil, nil, err
+		}
+		_, err = txInfo.GetReceipt()
+		if block.ResultNotFinalizedError.Equals(err) {
+			return nil, nil, jsonrpc.ErrorCodeExecuting.New("Executing")
+		} else if err != nil {
+			return nil, nil, jsonrpc.ErrorCodeSystem.Wrap(err, c.debug)
+		}
+		return blk, txInfo, nil
+	case len(param.Block) > 0:
+		blk, err := c.GetBlockByID(param.Block.Bytes())
+		if err != nil {
+			return nil, nil, err
+		}
+		return blk, nil, c.CheckBaseHeight(blk.Height())
+	case len(param.Height) > 0:
+		blk, err := c.GetBlockByHeight(param.Height)
+		if err != nil {
+			return nil, nil, err
+		}
+		return blk, nil, c.CheckBaseHeight(blk.Height())
+	default:
+		return nil, nil, jsonrpc.ErrorCodeInvalidParams.New(
+			"one of txHash, block or height is required")
+	}
+}
+
 func getTrace(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
 	var c contextWithSM
 	if err := c.Init(ctx); err != nil {
 		return nil, err
 	}
 
-	var param TransactionHashParam
+	var param TraceParam
 	if err := params.Convert(&param); err != nil {
 		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
 	}
-
-	txInfo, err := c.bm.GetTransactionInfo(param.Hash.Bytes())
-	if errors.NotFoundError.Equals(err) {
-		if c.sm.HasTransaction(param.Hash.Bytes()) {
-			return nil, jsonrpc.ErrorCodePending.New("Pending")
-		}
-		return nil, jsonrpc.ErrorCodeNotFound.Wrap(err, c.debug)
-	} else if err != nil {
-		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, c.debug)
-	}
-
-	if txInfo.Group() == module.TransactionGroupPatch {
-		return nil, jsonrpc.ErrorCodeInvalidParams.New("Patch transaction can't be replayed")
+	mode, ok := traceModeOf(param.Mode)
+	if !ok {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Errorf(
+			"invalid trace mode %q", param.Mode)
 	}
 
-	blk := txInfo.Block()
-	if err = c.CheckBaseHeight(blk.Height()); err != nil {
+	blk, txInfo, err := findBlockAndTxInfoByTraceParam(&c, &param)
+	if err != nil {
 		return nil, err
 	}
-	_, err = txInfo.GetReceipt()
-	if block.ResultNotFinalizedError.Equals(err) {
-		return nil, jsonrpc.ErrorCodeExecuting.New("Executing")
-	} else if err != nil {
-		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, c.debug)
+	if txInfo == nil && mode == module.TraceModeInvoke {
+		return nil, jsonrpc.ErrorCodeInvalidParams.New(
+			"invoke mode is only for a transaction")
 	}
+
 	csi, err := c.bm.NewConsensusInfo(blk)
 	if err != nil {
 		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, c.debug)
@@ -1173,27 +1430,49 @@ func getTrace(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error)
 		logs:    make([]interface{}, 0, 100),
 		channel: make(chan interface{}, 10),
 	}
+	switch mode {
+	case module.TraceModeAccessList:
+		cb.at = trace.NewAccessTracer(1)
+	case module.TraceModeCallTree:
+		cb.ct = trace.NewCallTreeTracer(1)
+	}
 	ti := module.TraceInfo{
-		TraceMode: module.TraceModeInvoke,
-		Range:     module.TraceRangeTransaction,
-		Group:     txInfo.Group(),
-		Index:     txInfo.Index(),
+		TraceMode: mode,
 		Callback:  cb,
 	}
+	timeout := time.Second * 5
+	if txInfo != nil {
+		ti.Range = module.TraceRangeTransaction
+		ti.Group = txInfo.Group()
+		ti.Index = txInfo.Index()
+	} else {
+		ti.Range = module.TraceRangeBlock
+		timeout = time.Second * 60
+	}
 	canceller, err := tr2.ExecuteForTrace(ti)
 	if err != nil {
 		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, c.debug)
 	}
 
-	timer := time.After(time.Second * 5)
+	timer := time.After(timeout)
 	for {
 		select {
 		case <-timer:
 			canceller()
 			return nil, jsonrpc.ErrorCodeSystemTimeout.Errorf(
-				"Not enough time to get result of %x", param.Hash.Bytes())
+				"Not enough time to get result of %+v", param)
 		case <-cb.channel:
-			return cb.invokeTraceToJSON(), nil
+			if txInfo == nil {
+				return cb.blockTraceToJSON(blk), nil
+			}
+			switch mode {
+			case module.TraceModeAccessList:
+				return cb.accessListToJSON(), nil
+			case module.TraceModeCallTree:
+				return cb.callTreeToJSON(), nil
+			default:
+				return cb.invokeTraceToJSON(), nil
+			}
 		}
 	}
 }
@@ -1221,16 +1500,31 @@ func estimateStep(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, er
 	if newTS <= oldTS {
 		newTS = oldTS + 1
 	}
-	bi := common.NewBlockInfo(blk.Height()+1, newTS)
+	bi, err := param.Overrides.BlockInfo(common.NewBlockInfo(blk.Height()+1, newTS))
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+	}
+	ovs, err := param.Overrides.AccountOverrides()
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+	}
+	js, err := paramsWithoutOverrides(params)
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+	}
 
 	// execute transaction
 	rct, err := c.sm.ExecuteTransaction(
 		blk.Result(),
 		blk.NextValidators().Hash(),
-		params.RawMessage(),
+		js,
 		bi,
+		ovs,
 	)
 	if err != nil {
+		if service.InvalidQueryError.Equals(err) {
+			return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+		}
 		return nil, jsonrpc.ErrorCodeServer.Wrap(err, c.debug)
 	}
 	if status := rct.Status(); status != module.StatusSuccess {
@@ -1243,7 +1537,24 @@ func estimateStep(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, er
 	}
 	steps := new(common.HexInt)
 	steps.Set(rct.StepUsed())
-	return steps, nil
+	if param.MaxStepPrice == "" && param.StepPriceTip == "" {
+		return steps, nil
+	}
+
+	// estimation with the fee market
+	stepPrice := rct.StepPrice()
+	tip := new(big.Int)
+	if rctex, ok := rct.(txresult.Receipt); ok {
+		tip = rctex.StepPriceTip()
+	}
+	fee := new(big.Int).Add(stepPrice, tip)
+	fee.Mul(fee, rct.StepUsed())
+	return map[string]interface{}{
+		"steps":        steps,
+		"stepPrice":    common.NewHexInt(0).SetValue(stepPrice),
+		"stepPriceTip": common.NewHexInt(0).SetValue(tip),
+		"fee":          common.NewHexInt(0).SetValue(fee),
+	}, nil
 }
 
 type MissingTransactionInfo interface {
@@ -1366,6 +1677,9 @@ func findBlockAndTxInfoByRosettaTraceParam(
 			if txInfo.Group() == module.TransactionGroupPatch {
 				return nil, nil, jsonrpc.ErrorCodeInvalidParams.New("Patch transaction can't be replayed")
 			}
+			if err = c.CheckPrunedHeight(txInfo.Block().Height()); err != nil {
+				return nil, nil, err
+			}
 			_, err = txInfo.GetReceipt()
 			if block.ResultNotFinalizedError.Equals(err) {
 				return nil, nil, jsonrpc.ErrorCodeExecuting.New("Executing")
@@ -0,0 +1,165 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v3
+
+import (
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/server/jsonrpc"
+	"github.com/icon-project/goloop/server/metric"
+)
+
+// DevMethodRepository returns methods for the chain in dev mode. They
+// are not found for the chain if dev mode is not enabled.
+func DevMethodRepository(mtr *metric.JsonrpcMetric) *jsonrpc.MethodRepository {
+	mr := jsonrpc.NewMethodRepository(mtr)
+	RegisterValidationRule(mr.Validator())
+
+	mr.RegisterMethod("dev_mine", devMine)
+	mr.RegisterMethod("dev_setNextBlockTimestamp", devSetNextBlockTimestamp)
+	mr.RegisterMethod("dev_snapshot", devSnapshot)
+	mr.RegisterMethod("dev_revert", devRevert)
+	mr.RegisterMethod("dev_setBalance", devSetBalance)
+
+	return mr
+}
+
+type contextWithDev struct {
+	contextWithChain
+	dev module.DevMode
+}
+
+func (c *contextWithDev) Init(ctx *jsonrpc.Context) error {
+	if err := c.contextWithChain.Init(ctx); err != nil {
+		return err
+	}
+	if dc, ok := c.chain.(module.DevChain); ok {
+		c.dev = dc.DevMode()
+	}
+	if c.dev == nil {
+		return jsonrpc.ErrorCodeMethodNotFound.New("DevModeDisabled")
+	}
+	return nil
+}
+
+// AsRPCError returns jsonrpc.ErrorCodeInvalidParams for
+// errors.IllegalArgumentError in addition to contextWithChain.AsRPCError.
+func (c *contextWithDev) AsRPCError(err error) error {
+	if errors.IllegalArgumentError.Equals(err) {
+		return jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+	}
+	return c.contextWithChain.AsRPCError(err)
+}
+
+func devMine(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
+	var c contextWithDev
+	if err := c.Init(ctx); err != nil {
+		return nil, err
+	}
+	var param *DevMineParam
+	if err := params.Convert(&param); err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+	}
+	count := int64(1)
+	if param != nil && len(param.Count) > 0 {
+		var err error
+		if count, err = param.Count.ParseInt(32); err != nil {
+			return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+		}
+	}
+	height, err := c.dev.Mine(int(count))
+	if err != nil {
+		return nil, c.AsRPCError(err)
+	}
+	return map[string]interface{}{
+		"height": intconv.FormatInt(height),
+	}, nil
+}
+
+func devSetNextBlockTimestamp(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
+	var c contextWithDev
+	if err := c.Init(ctx); err != nil {
+		return nil, err
+	}
+	var param DevTimestampParam
+	if err := params.Convert(&param); err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+	}
+	ts, err := param.Timestamp.Int64()
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+	}
+	if err := c.dev.SetNextTimestamp(ts); err != nil {
+		return nil, c.AsRPCError(err)
+	}
+	return true, nil
+}
+
+func devSnapshot(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
+	var c contextWithDev
+	if err := c.Init(ctx); err != nil {
+		return nil, err
+	}
+	var param struct{}
+	if err := params.Convert(&param); err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+	}
+	id, err := c.dev.Snapshot()
+	if err != nil {
+		return nil, c.AsRPCError(err)
+	}
+	return intconv.FormatInt(int64(id)), nil
+}
+
+func devRevert(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
+	var c contextWithDev
+	if err := c.Init(ctx); err != nil {
+		return nil, err
+	}
+	var param DevSnapshotParam
+	if err := params.Convert(&param); err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+	}
+	id, err := param.ID.ParseInt(32)
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+	}
+	if err := c.dev.Revert(int(id)); err != nil {
+		return nil, c.AsRPCError(err)
+	}
+	return true, nil
+}
+
+func devSetBalance(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
+	var c contextWithDev
+	if err := c.Init(ctx); err != nil {
+		return nil, err
+	}
+	var param DevBalanceParam
+	if err := params.Convert(&param); err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+	}
+	balance, err := param.Balance.BigInt()
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+	}
+	if err := c.dev.SetBalance(param.Address.Address(), balance); err != nil {
+		return nil, c.AsRPCError(err)
+	}
+	return true, nil
+}
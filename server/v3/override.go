@@ -0,0 +1,122 @@
+package v3
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/server/jsonrpc"
+)
+
+const overridesKey = "overrides"
+
+func decodeHexBytes(s string) ([]byte, error) {
+	if !strings.HasPrefix(s, "0x") {
+		return nil, errors.IllegalArgumentError.Errorf("InvalidHexBytes(%s)", s)
+	}
+	return hex.DecodeString(s[2:])
+}
+
+// overridesAllowed returns whether overrides are allowed for icx_call.
+// They're allowed only on the node including debug information or the chain
+// in dev mode.
+func overridesAllowed(c *contextWithChain) bool {
+	if c.debug {
+		return true
+	}
+	if dc, ok := c.chain.(module.DevChain); ok {
+		return dc.DevMode() != nil
+	}
+	return false
+}
+
+// AccountOverrides returns changes of accounts in the order of addresses.
+func (p *StateOverrideParam) AccountOverrides() ([]module.AccountOverride, error) {
+	if p == nil || len(p.Accounts) == 0 {
+		return nil, nil
+	}
+	ovs := make([]module.AccountOverride, 0, len(p.Accounts))
+	for addr, ap := range p.Accounts {
+		ov := module.AccountOverride{
+			Address: addr.Address(),
+		}
+		if len(ap.Balance) > 0 {
+			balance, err := ap.Balance.BigInt()
+			if err != nil {
+				return nil, errors.IllegalArgumentError.Wrapf(err, "InvalidBalance(%s)", ap.Balance)
+			}
+			ov.Balance = balance
+		}
+		if len(ap.Storage) > 0 {
+			ov.Storage = make(map[string][]byte, len(ap.Storage))
+			for k, v := range ap.Storage {
+				key, err := decodeHexBytes(k)
+				if err != nil || len(key) == 0 {
+					return nil, errors.IllegalArgumentError.Errorf("InvalidStorageKey(%s)", k)
+				}
+				var value []byte
+				if v != nil {
+					if value, err = decodeHexBytes(string(*v)); err != nil {
+						return nil, errors.IllegalArgumentError.Errorf("InvalidStorageValue(%s)", *v)
+					}
+				}
+				ov.Storage[string(key)] = value
+			}
+		}
+		if ap.Code != nil {
+			content, err := decodeHexBytes(string(ap.Code.Content))
+			if err != nil || len(content) == 0 {
+				return nil, errors.IllegalArgumentError.New("InvalidCodeContent")
+			}
+			ov.ContentType = ap.Code.ContentType
+			ov.Content = content
+		}
+		ovs = append(ovs, ov)
+	}
+	sort.Slice(ovs, func(i, j int) bool {
+		return ovs[i].Address.String() < ovs[j].Address.String()
+	})
+	return ovs, nil
+}
+
+// BlockInfo returns the block information replacing height and timestamp
+// of bi if they are specified.
+func (p *StateOverrideParam) BlockInfo(bi module.BlockInfo) (module.BlockInfo, error) {
+	if p == nil || p.Block == nil {
+		return bi, nil
+	}
+	height, timestamp := bi.Height(), bi.Timestamp()
+	if len(p.Block.Height) > 0 {
+		v, err := p.Block.Height.Int64()
+		if err != nil || v < 0 {
+			return nil, errors.IllegalArgumentError.Errorf("InvalidBlockHeight(%s)", p.Block.Height)
+		}
+		height = v
+	}
+	if len(p.Block.Timestamp) > 0 {
+		v, err := p.Block.Timestamp.Int64()
+		if err != nil || v < 0 {
+			return nil, errors.IllegalArgumentError.Errorf("InvalidBlockTimestamp(%s)", p.Block.Timestamp)
+		}
+		timestamp = v
+	}
+	return common.NewBlockInfo(height, timestamp), nil
+}
+
+// paramsWithoutOverrides returns raw message of params excluding overrides,
+// so that the service manager could parse it as before.
+func paramsWithoutOverrides(params *jsonrpc.Params) ([]byte, error) {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(params.RawMessage(), &m); err != nil {
+		return nil, err
+	}
+	if _, ok := m[overridesKey]; !ok {
+		return params.RawMessage(), nil
+	}
+	delete(m, overridesKey)
+	return json.Marshal(m)
+}
@@ -0,0 +1,124 @@
+package v3
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/server/jsonrpc"
+)
+
+func TestStateOverrideParam(t *testing.T) {
+	validator := jsonrpc.NewValidator()
+	RegisterValidationRule(validator)
+
+	callParams := []byte(`
+		{
+			"to": "cx059e19601bcb1424884f4ef19addc0a03de9e9cd",
+			"dataType": "call",
+			"data": {
+				"method": "balanceOf"
+			},
+			"overrides": {
+				"accounts": {
+					"hx4873b94352c8c1f3b2f09aaeccea31ce9e90bd31": {
+						"balance": "0x10"
+					},
+					"cx059e19601bcb1424884f4ef19addc0a03de9e9cd": {
+						"storage": {
+							"0x01": "0x02",
+							"0x03": null
+						}
+					}
+				},
+				"block": {
+					"height": "0x64"
+				}
+			}
+		}
+	`)
+
+	var param CallParam
+	err := jsonrpc.UnmarshalWithValidate(callParams, &param, validator)
+	assert.NoError(t, err)
+
+	ovs, err := param.Overrides.AccountOverrides()
+	assert.NoError(t, err)
+	assert.Len(t, ovs, 2)
+	assert.Equal(t, "cx059e19601bcb1424884f4ef19addc0a03de9e9cd", ovs[0].Address.String())
+	assert.Nil(t, ovs[0].Balance)
+	assert.Equal(t, map[string][]byte{"\x01": {0x02}, "\x03": nil}, ovs[0].Storage)
+	assert.Equal(t, "hx4873b94352c8c1f3b2f09aaeccea31ce9e90bd31", ovs[1].Address.String())
+	assert.Equal(t, big.NewInt(0x10), ovs[1].Balance)
+
+	bi, err := param.Overrides.BlockInfo(common.NewBlockInfo(10, 1000))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100), bi.Height())
+	assert.Equal(t, int64(1000), bi.Timestamp())
+
+	// no overrides
+	var nilParam *StateOverrideParam
+	ovs, err = nilParam.AccountOverrides()
+	assert.NoError(t, err)
+	assert.Nil(t, ovs)
+
+	// invalid address for the key
+	invalidParams := []byte(`
+		{
+			"to": "cx059e19601bcb1424884f4ef19addc0a03de9e9cd",
+			"dataType": "call",
+			"data": {
+				"method": "balanceOf"
+			},
+			"overrides": {
+				"accounts": {
+					"invalid": {
+						"balance": "0x10"
+					}
+				}
+			}
+		}
+	`)
+	err = jsonrpc.UnmarshalWithValidate(invalidParams, &param, validator)
+	assert.Error(t, err)
+
+	// invalid storage key
+	param.Overrides = &StateOverrideParam{
+		Accounts: map[jsonrpc.Address]*AccountOverrideParam{
+			"cx059e19601bcb1424884f4ef19addc0a03de9e9cd": {
+				Storage: map[string]*jsonrpc.HexBytes{"01": nil},
+			},
+		},
+	}
+	_, err = param.Overrides.AccountOverrides()
+	assert.Error(t, err)
+}
+
+type overrideTestChain struct {
+	module.Chain
+}
+
+type overrideTestDevChain struct {
+	module.Chain
+	dev module.DevMode
+}
+
+func (c *overrideTestDevChain) DevMode() module.DevMode {
+	return c.dev
+}
+
+type overrideTestDevMode struct {
+	module.DevMode
+}
+
+func TestOverridesAllowed(t *testing.T) {
+	assert.False(t, overridesAllowed(&contextWithChain{chain: &overrideTestChain{}}))
+	assert.True(t, overridesAllowed(&contextWithChain{chain: &overrideTestChain{}, debug: true}))
+
+	assert.False(t, overridesAllowed(&contextWithChain{chain: &overrideTestDevChain{}}))
+	dc := &overrideTestDevChain{dev: &overrideTestDevMode{}}
+	assert.True(t, overridesAllowed(&contextWithChain{chain: dc}))
+}
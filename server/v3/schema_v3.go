@@ -26,11 +26,38 @@ type BlockHashParam struct {
 }
 
 type CallParam struct {
-	FromAddress jsonrpc.Address `json:"from,omitempty" validate:"optional,t_addr_eoa"`
-	ToAddress   jsonrpc.Address `json:"to" validate:"required,t_addr_score"`
-	DataType    string          `json:"dataType" validate:"required,call"`
-	Data        interface{}     `json:"data"`
-	Height      jsonrpc.HexInt  `json:"height,omitempty" validate:"optional,t_int"`
+	FromAddress jsonrpc.Address     `json:"from,omitempty" validate:"optional,t_addr_eoa"`
+	ToAddress   jsonrpc.Address     `json:"to" validate:"required,t_addr_score"`
+	DataType    string              `json:"dataType" validate:"required,call"`
+	Data        interface{}         `json:"data"`
+	Height      jsonrpc.HexInt      `json:"height,omitempty" validate:"optional,t_int"`
+	Overrides   *StateOverrideParam `json:"overrides,omitempty" validate:"optional"`
+}
+
+type MultiCallParam struct {
+	Calls  []*CallParam   `json:"calls" validate:"gt=0,dive,required"`
+	Height jsonrpc.HexInt `json:"height,omitempty" validate:"optional,t_int"`
+}
+
+type StateOverrideParam struct {
+	Accounts map[jsonrpc.Address]*AccountOverrideParam `json:"accounts,omitempty" validate:"optional,dive,keys,t_addr,endkeys,required"`
+	Block    *BlockOverrideParam                       `json:"block,omitempty" validate:"optional"`
+}
+
+type AccountOverrideParam struct {
+	Balance jsonrpc.HexInt               `json:"balance,omitempty" validate:"optional,t_int"`
+	Storage map[string]*jsonrpc.HexBytes `json:"storage,omitempty"`
+	Code    *CodeOverrideParam           `json:"code,omitempty" validate:"optional"`
+}
+
+type CodeOverrideParam struct {
+	ContentType string           `json:"contentType" validate:"required"`
+	Content     jsonrpc.HexBytes `json:"content" validate:"required"`
+}
+
+type BlockOverrideParam struct {
+	Height    jsonrpc.HexInt `json:"height,omitempty" validate:"optional,t_int"`
+	Timestamp jsonrpc.HexInt `json:"timestamp,omitempty" validate:"optional,t_int"`
 }
 
 type AddressParam struct {
@@ -38,15 +65,73 @@ type AddressParam struct {
 	Height  jsonrpc.HexInt  `json:"height,omitempty" validate:"optional,t_int"`
 }
 
+type RewardHistoryParam struct {
+	Address     jsonrpc.Address `json:"address" validate:"required,t_addr"`
+	StartHeight jsonrpc.HexInt  `json:"startHeight,omitempty" validate:"optional,t_int"`
+	Count       jsonrpc.HexInt  `json:"count,omitempty" validate:"optional,t_int"`
+}
+
+type ValidationHistoryParam struct {
+	Address   jsonrpc.Address `json:"address" validate:"required,t_addr_eoa"`
+	Threshold jsonrpc.HexInt  `json:"threshold,omitempty" validate:"optional,t_int"`
+}
+
 type ScoreAddressParam struct {
 	Address jsonrpc.Address `json:"address" validate:"required,t_addr_score"`
 	Height  jsonrpc.HexInt  `json:"height,omitempty" validate:"optional,t_int"`
 }
 
+type StorageHintParam struct {
+	Type string     `json:"type" validate:"required,oneof=var dict array"`
+	Name string     `json:"name" validate:"required"`
+	Keys [][]string `json:"keys,omitempty"`
+}
+
+type StorageParam struct {
+	Address jsonrpc.Address     `json:"address" validate:"required,t_addr_score"`
+	Height  jsonrpc.HexInt      `json:"height,omitempty" validate:"optional,t_int"`
+	Hints   []*StorageHintParam `json:"hints,omitempty" validate:"optional,dive,required"`
+	Cursor  jsonrpc.HexBytes    `json:"cursor,omitempty"`
+	Limit   jsonrpc.HexInt      `json:"limit,omitempty" validate:"optional,t_int"`
+}
+
+type StorageDiffParam struct {
+	Address     jsonrpc.Address     `json:"address" validate:"required,t_addr_score"`
+	StartHeight jsonrpc.HexInt      `json:"startHeight" validate:"required,t_int"`
+	Height      jsonrpc.HexInt      `json:"height,omitempty" validate:"optional,t_int"`
+	Hints       []*StorageHintParam `json:"hints,omitempty" validate:"optional,dive,required"`
+	Cursor      jsonrpc.HexBytes    `json:"cursor,omitempty"`
+	Limit       jsonrpc.HexInt      `json:"limit,omitempty" validate:"optional,t_int"`
+}
+
+type DevMineParam struct {
+	Count jsonrpc.HexInt `json:"count,omitempty" validate:"optional,t_int"`
+}
+
+type DevTimestampParam struct {
+	Timestamp jsonrpc.HexInt `json:"timestamp" validate:"required,t_int"`
+}
+
+type DevSnapshotParam struct {
+	ID jsonrpc.HexInt `json:"id" validate:"required,t_int"`
+}
+
+type DevBalanceParam struct {
+	Address jsonrpc.Address `json:"address" validate:"required,t_addr"`
+	Balance jsonrpc.HexInt  `json:"balance" validate:"required,t_int"`
+}
+
 type TransactionHashParam struct {
 	Hash jsonrpc.HexBytes `json:"txHash" validate:"required,t_hash"`
 }
 
+type TraceParam struct {
+	Hash   jsonrpc.HexBytes `json:"txHash,omitempty" validate:"optional,t_hash"`
+	Block  jsonrpc.HexBytes `json:"block,omitempty" validate:"optional,t_hash"`
+	Height jsonrpc.HexInt   `json:"height,omitempty" validate:"optional,t_int"`
+	Mode   string           `json:"mode,omitempty"`
+}
+
 type TransactionParamForEstimate struct {
 	Version     jsonrpc.HexInt  `json:"version" validate:"required,t_int"`
 	FromAddress jsonrpc.Address `json:"from" validate:"required,t_addr_eoa"`
@@ -57,6 +142,11 @@ type TransactionParamForEstimate struct {
 	Nonce       jsonrpc.HexInt  `json:"nonce,omitempty" validate:"optional,t_int"`
 	DataType    string          `json:"dataType,omitempty" validate:"optional,call|deploy|message|deposit"`
 	Data        interface{}     `json:"data,omitempty"`
+
+	MaxStepPrice jsonrpc.HexInt `json:"maxStepPrice,omitempty" validate:"optional,t_int"`
+	StepPriceTip jsonrpc.HexInt `json:"stepPriceTip,omitempty" validate:"optional,t_int"`
+
+	Overrides *StateOverrideParam `json:"overrides,omitempty" validate:"optional"`
 }
 
 type TransactionParam struct {
@@ -71,6 +161,9 @@ type TransactionParam struct {
 	Signature   string          `json:"signature" validate:"required,t_sig"`
 	DataType    string          `json:"dataType,omitempty" validate:"optional,call|deploy|message|deposit"`
 	Data        interface{}     `json:"data,omitempty"`
+
+	MaxStepPrice jsonrpc.HexInt `json:"maxStepPrice,omitempty" validate:"optional,t_int"`
+	StepPriceTip jsonrpc.HexInt `json:"stepPriceTip,omitempty" validate:"optional,t_int"`
 }
 
 type DataHashParam struct {
@@ -0,0 +1,230 @@
+package v3
+
+import (
+	"encoding/hex"
+	"math/big"
+	"strings"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/server/jsonrpc"
+)
+
+const (
+	DefaultStorageLimit = 100
+	MaxStorageLimit     = 1000
+)
+
+// hintKeyOf converts a key of the dict in the hint. Addresses are used as
+// they are, and hex strings with "0x" prefix are used as integers.
+// Others are used as strings.
+func hintKeyOf(s string) (interface{}, error) {
+	if strings.HasPrefix(s, "hx") || strings.HasPrefix(s, "cx") {
+		addr := new(common.Address)
+		if err := addr.SetStringStrict(s); err == nil {
+			return addr, nil
+		}
+	}
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "-0x") {
+		v := new(big.Int)
+		if err := intconv.ParseBigInt(v, s); err != nil {
+			return nil, errors.IllegalArgumentError.Errorf("InvalidHintKey(%s)", s)
+		}
+		return v, nil
+	}
+	return s, nil
+}
+
+func storageHintsOf(params []*StorageHintParam) ([]module.StorageHint, error) {
+	if len(params) == 0 {
+		return nil, nil
+	}
+	hints := make([]module.StorageHint, 0, len(params))
+	for _, p := range params {
+		hint := module.StorageHint{
+			Type: p.Type,
+			Name: p.Name,
+		}
+		for _, ks := range p.Keys {
+			keys := make([]interface{}, 0, len(ks))
+			for _, k := range ks {
+				key, err := hintKeyOf(k)
+				if err != nil {
+					return nil, err
+				}
+				keys = append(keys, key)
+			}
+			hint.Keys = append(hint.Keys, keys)
+		}
+		hints = append(hints, hint)
+	}
+	return hints, nil
+}
+
+func storageCursorOf(cursor jsonrpc.HexBytes) ([]byte, error) {
+	if len(cursor) == 0 {
+		return nil, nil
+	}
+	bs, err := decodeHexBytes(string(cursor))
+	if err != nil {
+		return nil, errors.IllegalArgumentError.Errorf("InvalidCursor(%s)", cursor)
+	}
+	return bs, nil
+}
+
+func storageLimitOf(limit jsonrpc.HexInt) (int, error) {
+	if len(limit) == 0 {
+		return DefaultStorageLimit, nil
+	}
+	v, err := limit.Int64()
+	if err != nil || v <= 0 || v > MaxStorageLimit {
+		return 0, errors.IllegalArgumentError.Errorf("InvalidLimit(%s)", limit)
+	}
+	return int(v), nil
+}
+
+func hexBytesOf(bs []byte) interface{} {
+	if bs == nil {
+		return nil
+	}
+	return "0x" + hex.EncodeToString(bs)
+}
+
+func storageKeyToJSON(key interface{}) interface{} {
+	switch k := key.(type) {
+	case module.Address:
+		return k.String()
+	case *big.Int:
+		return intconv.FormatBigInt(k)
+	case int:
+		return intconv.FormatInt(int64(k))
+	case []byte:
+		return hexBytesOf(k)
+	default:
+		return k
+	}
+}
+
+func storageEntryToJSON(e *module.StorageEntry) map[string]interface{} {
+	jso := map[string]interface{}{
+		"key":   hexBytesOf(e.Key),
+		"value": hexBytesOf(e.Value),
+	}
+	if e.Hint != nil {
+		container := map[string]interface{}{
+			"type": e.Hint.Type,
+			"name": e.Hint.Name,
+		}
+		if len(e.Keys) > 0 {
+			keys := make([]interface{}, 0, len(e.Keys))
+			for _, k := range e.Keys {
+				keys = append(keys, storageKeyToJSON(k))
+			}
+			container["keys"] = keys
+		}
+		jso["container"] = container
+	}
+	return jso
+}
+
+// storageResultToJSON returns entries with the cursor for the next page.
+// The cursor is set only if there could be more entries.
+func storageResultToJSON(entries []interface{}, last []byte, limit int) interface{} {
+	jso := map[string]interface{}{
+		"entries": entries,
+	}
+	if len(entries) == limit {
+		jso["next"] = hexBytesOf(last)
+	}
+	return jso
+}
+
+func getStorage(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
+	var c contextWithSM
+	if err := c.Init(ctx); err != nil {
+		return nil, err
+	}
+	var param StorageParam
+	if err := params.Convert(&param); err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+	}
+	hints, err := storageHintsOf(param.Hints)
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+	}
+	cursor, err := storageCursorOf(param.Cursor)
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+	}
+	limit, err := storageLimitOf(param.Limit)
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+	}
+
+	blk, err := c.GetBlockByHeight(param.Height)
+	if err != nil {
+		return nil, err
+	}
+	es, err := c.sm.GetStorage(blk.Result(), param.Address.Address(), hints, cursor, limit)
+	if err != nil {
+		return nil, c.AsRPCError(err)
+	}
+	entries := make([]interface{}, 0, len(es))
+	var last []byte
+	for i := range es {
+		entries = append(entries, storageEntryToJSON(&es[i]))
+		last = es[i].Key
+	}
+	return storageResultToJSON(entries, last, limit), nil
+}
+
+func diffStorage(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
+	var c contextWithSM
+	if err := c.Init(ctx); err != nil {
+		return nil, err
+	}
+	var param StorageDiffParam
+	if err := params.Convert(&param); err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+	}
+	hints, err := storageHintsOf(param.Hints)
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+	}
+	cursor, err := storageCursorOf(param.Cursor)
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+	}
+	limit, err := storageLimitOf(param.Limit)
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+	}
+
+	blk1, err := c.GetBlockByHeight(param.StartHeight)
+	if err != nil {
+		return nil, err
+	}
+	blk2, err := c.GetBlockByHeight(param.Height)
+	if err != nil {
+		return nil, err
+	}
+	if blk1.Height() > blk2.Height() {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Errorf(
+			"startHeight(%d) is higher than height(%d)", blk1.Height(), blk2.Height())
+	}
+	cs, err := c.sm.DiffStorage(blk1.Result(), blk2.Result(), param.Address.Address(), hints, cursor, limit)
+	if err != nil {
+		return nil, c.AsRPCError(err)
+	}
+	entries := make([]interface{}, 0, len(cs))
+	var last []byte
+	for i := range cs {
+		jso := storageEntryToJSON(&cs[i].StorageEntry)
+		jso["oldValue"] = hexBytesOf(cs[i].OldValue)
+		entries = append(entries, jso)
+		last = cs[i].Key
+	}
+	return storageResultToJSON(entries, last, limit), nil
+}
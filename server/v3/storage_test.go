@@ -0,0 +1,103 @@
+package v3
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/server/jsonrpc"
+)
+
+func TestStorageParam(t *testing.T) {
+	validator := jsonrpc.NewValidator()
+	RegisterValidationRule(validator)
+
+	params := []byte(`
+		{
+			"address": "cx059e19601bcb1424884f4ef19addc0a03de9e9cd",
+			"hints": [
+				{ "type": "var", "name": "total" },
+				{
+					"type": "dict",
+					"name": "balances",
+					"keys": [
+						["hx4873b94352c8c1f3b2f09aaeccea31ce9e90bd31"],
+						["0x10", "name"]
+					]
+				}
+			],
+			"cursor": "0x0102",
+			"limit": "0x10"
+		}
+	`)
+	var param StorageParam
+	err := jsonrpc.UnmarshalWithValidate(params, &param, validator)
+	assert.NoError(t, err)
+
+	hints, err := storageHintsOf(param.Hints)
+	assert.NoError(t, err)
+	assert.Len(t, hints, 2)
+	assert.Equal(t, module.StorageHint{Type: "var", Name: "total"}, hints[0])
+	assert.Equal(t, "dict", hints[1].Type)
+	assert.Len(t, hints[1].Keys, 2)
+	assert.Equal(t, common.MustNewAddressFromString("hx4873b94352c8c1f3b2f09aaeccea31ce9e90bd31"), hints[1].Keys[0][0])
+	assert.Equal(t, []interface{}{big.NewInt(0x10), "name"}, hints[1].Keys[1])
+
+	cursor, err := storageCursorOf(param.Cursor)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x01, 0x02}, cursor)
+
+	limit, err := storageLimitOf(param.Limit)
+	assert.NoError(t, err)
+	assert.Equal(t, 16, limit)
+
+	limit, err = storageLimitOf("")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultStorageLimit, limit)
+
+	_, err = storageLimitOf("0x0")
+	assert.Error(t, err)
+
+	// invalid type of the hint
+	invalidParams := []byte(`
+		{
+			"address": "cx059e19601bcb1424884f4ef19addc0a03de9e9cd",
+			"hints": [ { "type": "map", "name": "total" } ]
+		}
+	`)
+	err = jsonrpc.UnmarshalWithValidate(invalidParams, &param, validator)
+	assert.Error(t, err)
+
+	// invalid key of the hint
+	_, err = storageHintsOf([]*StorageHintParam{
+		{Type: "dict", Name: "balances", Keys: [][]string{{"0xzz"}}},
+	})
+	assert.Error(t, err)
+}
+
+func TestStorageEntryToJSON(t *testing.T) {
+	e := &module.StorageEntry{
+		Key:   []byte{0x01},
+		Value: []byte{0x02},
+		Hint:  &module.StorageHint{Type: "array", Name: "items"},
+		Keys:  []interface{}{10},
+	}
+	assert.Equal(t, map[string]interface{}{
+		"key":   "0x01",
+		"value": "0x02",
+		"container": map[string]interface{}{
+			"type": "array",
+			"name": "items",
+			"keys": []interface{}{"0xa"},
+		},
+	}, storageEntryToJSON(e))
+
+	e = &module.StorageEntry{Key: []byte{0x03}}
+	assert.Equal(t, map[string]interface{}{
+		"key":   "0x03",
+		"value": nil,
+	}, storageEntryToJSON(e))
+}
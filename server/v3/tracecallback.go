@@ -19,6 +19,29 @@ type traceCallback struct {
 	ts      time.Time
 	channel chan interface{}
 	bt      *trace.BalanceTracer
+	at      *trace.AccessTracer
+	ct      *trace.CallTreeTracer
+}
+
+type txTracer interface {
+	OnTransactionStart(txIndex int, txHash []byte, isBlockTx bool) error
+	OnTransactionReset() error
+	OnTransactionEnd(txIndex int, txHash []byte) error
+	OnFrameEnter() error
+	OnFrameExit(success bool) error
+}
+
+func (t *traceCallback) tracer() txTracer {
+	if t.bt != nil {
+		return t.bt
+	}
+	if t.at != nil {
+		return t.at
+	}
+	if t.ct != nil {
+		return t.ct
+	}
+	return nil
 }
 
 type traceLog struct {
@@ -49,13 +72,7 @@ func (t *traceCallback) OnEnd(e error) {
 	close(t.channel)
 }
 
-func (t *traceCallback) invokeTraceToJSON() interface{} {
-	t.lock.Lock()
-	defer t.lock.Unlock()
-
-	result := map[string]interface{}{
-		"logs": t.logs,
-	}
+func (t *traceCallback) setStatusInLock(result map[string]interface{}) {
 	if t.last == nil {
 		result["status"] = "0x1"
 	} else {
@@ -66,6 +83,55 @@ func (t *traceCallback) invokeTraceToJSON() interface{} {
 			"message": t.last.Error(),
 		}
 	}
+}
+
+func (t *traceCallback) invokeTraceToJSON() interface{} {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	result := map[string]interface{}{
+		"logs": t.logs,
+	}
+	t.setStatusInLock(result)
+	return result
+}
+
+func (t *traceCallback) accessListToJSON() interface{} {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	result := map[string]interface{}{
+		"frames": t.at.FramesToJSON(),
+	}
+	t.setStatusInLock(result)
+	return result
+}
+
+func (t *traceCallback) callTreeToJSON() interface{} {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	result := map[string]interface{}{
+		"calls": t.ct.CallsToJSON(),
+	}
+	t.setStatusInLock(result)
+	return result
+}
+
+func (t *traceCallback) blockTraceToJSON(blk module.Block) interface{} {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	result := map[string]interface{}{
+		"blockHash":   "0x" + hex.EncodeToString(blk.ID()),
+		"blockHeight": fmt.Sprintf("%#x", blk.Height()),
+	}
+	if t.at != nil {
+		result["transactions"] = t.at.ToJSON()
+	} else if t.ct != nil {
+		result["transactions"] = t.ct.ToJSON()
+	}
+	t.setStatusInLock(result)
 	return result
 }
 
@@ -89,10 +155,10 @@ func (t *traceCallback) balanceChangeToJSON(blk module.Block) interface{} {
 }
 
 func (t *traceCallback) OnTransactionStart(txIndex int, txHash []byte, isBlockTx bool) error {
-	if t.bt != nil {
+	if tr := t.tracer(); tr != nil {
 		t.lock.Lock()
 		defer t.lock.Unlock()
-		return t.bt.OnTransactionStart(txIndex, txHash, isBlockTx)
+		return tr.OnTransactionStart(txIndex, txHash, isBlockTx)
 	}
 	return nil
 }
@@ -102,35 +168,35 @@ func (t *traceCallback) OnTransactionReset() error {
 	defer t.lock.Unlock()
 
 	t.logs = nil
-	if t.bt != nil {
-		return t.bt.OnTransactionReset()
+	if tr := t.tracer(); tr != nil {
+		return tr.OnTransactionReset()
 	}
 	return nil
 }
 
 func (t *traceCallback) OnTransactionEnd(txIndex int, txHash []byte) error {
-	if t.bt != nil {
+	if tr := t.tracer(); tr != nil {
 		t.lock.Lock()
 		defer t.lock.Unlock()
-		return t.bt.OnTransactionEnd(txIndex, txHash)
+		return tr.OnTransactionEnd(txIndex, txHash)
 	}
 	return nil
 }
 
 func (t *traceCallback) OnFrameEnter() error {
-	if t.bt != nil {
+	if tr := t.tracer(); tr != nil {
 		t.lock.Lock()
 		defer t.lock.Unlock()
-		return t.bt.OnFrameEnter()
+		return tr.OnFrameEnter()
 	}
 	return nil
 }
 
 func (t *traceCallback) OnFrameExit(success bool) error {
-	if t.bt != nil {
+	if tr := t.tracer(); tr != nil {
 		t.lock.Lock()
 		defer t.lock.Unlock()
-		return t.bt.OnFrameExit(success)
+		return tr.OnFrameExit(success)
 	}
 	return nil
 }
@@ -143,3 +209,30 @@ func (t *traceCallback) OnBalanceChange(opType module.OpType, from, to module.Ad
 	}
 	return nil
 }
+
+func (t *traceCallback) OnStorageAccess(op module.StorageOp, addr module.Address, key, value, old []byte) error {
+	if t.at != nil {
+		t.lock.Lock()
+		defer t.lock.Unlock()
+		return t.at.OnStorageAccess(op, addr, key, value, old)
+	}
+	return nil
+}
+
+func (t *traceCallback) OnCallStart(call *module.TraceCall) error {
+	if t.ct != nil {
+		t.lock.Lock()
+		defer t.lock.Unlock()
+		return t.ct.OnCallStart(call)
+	}
+	return nil
+}
+
+func (t *traceCallback) OnCallEnd(status error, stepUsed *big.Int) error {
+	if t.ct != nil {
+		t.lock.Lock()
+		defer t.lock.Unlock()
+		return t.ct.OnCallEnd(status, stepUsed)
+	}
+	return nil
+}
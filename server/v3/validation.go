@@ -12,7 +12,7 @@ import (
 
 var (
 	hexString          = regexp.MustCompile("^0x[0-9a-f]+$")
-	deployContentTypes = []string{"application/zip", "application/java"}
+	deployContentTypes = []string{"application/zip", "application/java", "application/wasm"}
 )
 
 func RegisterValidationRule(v *jsonrpc.Validator) {
@@ -86,3 +86,69 @@ func TestTransactionParamValidator(t *testing.T) {
 		assert.Fail(t, "validate fail", err.Error())
 	}
 }
+
+func TestMultiCallParamValidator(t *testing.T) {
+	validator := jsonrpc.NewValidator()
+	RegisterValidationRule(validator)
+
+	cases := []struct {
+		name  string
+		param string
+		valid bool
+	}{
+		{
+			"Valid",
+			`{
+				"height": "0x10",
+				"calls": [
+					{
+						"to": "cx059e19601bcb1424884f4ef19addc0a03de9e9cd",
+						"dataType": "call",
+						"data": { "method": "name" }
+					},
+					{
+						"from": "hx4873b94352c8c1f3b2f09aaeccea31ce9e90bd31",
+						"to": "cx059e19601bcb1424884f4ef19addc0a03de9e9ce",
+						"dataType": "call",
+						"data": { "method": "balanceOf", "params": { "_owner": "hx4e436ed6adf72b6d2a80613cc15d5af5ddb6701e" } }
+					}
+				]
+			}`,
+			true,
+		},
+		{
+			"NoCalls",
+			`{ "calls": [] }`,
+			false,
+		},
+		{
+			"NullCall",
+			`{ "calls": [ null ] }`,
+			false,
+		},
+		{
+			"InvalidCall",
+			`{
+				"calls": [
+					{
+						"to": "hx4873b94352c8c1f3b2f09aaeccea31ce9e90bd31",
+						"dataType": "call",
+						"data": { "method": "name" }
+					}
+				]
+			}`,
+			false,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var param MultiCallParam
+			err := jsonrpc.UnmarshalWithValidate([]byte(c.param), &param, validator)
+			if c.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
@@ -223,6 +223,19 @@ func (wss *wsSession) RunLoop(ech chan<- error) {
 	}
 }
 
+// RunMessageLoop is same as RunLoop except that it passes messages from
+// the client to mch until done is closed.
+func (wss *wsSession) RunMessageLoop(ech chan<- error, mch chan<- []byte, done <-chan struct{}) {
+	wss.lock.Lock()
+	defer wss.lock.Unlock()
+
+	if wss.c != nil {
+		go readMessageLoop(wss.c, ech, mch, done)
+	} else {
+		ech <- errors.New("AlreadyClosed")
+	}
+}
+
 const DefaultWSMaxSession = 10
 
 type WSResponse struct {
@@ -257,3 +270,18 @@ func readLoop(c WebSocketConn, ech chan<- error) {
 		}
 	}
 }
+
+func readMessageLoop(c WebSocketConn, ech chan<- error, mch chan<- []byte, done <-chan struct{}) {
+	for {
+		_, bs, err := c.ReadMessage()
+		if err != nil {
+			ech <- err
+			break
+		}
+		select {
+		case mch <- bs:
+		case <-done:
+			return
+		}
+	}
+}
@@ -294,6 +294,10 @@ func (b *testBlock) LogsBloom() module.LogsBloom {
 	}
 }
 
+func (bm *testBlockManager) PrunedHeight() int64 {
+	return -1
+}
+
 func (bm *testBlockManager) WaitForBlock(h int64) (<-chan module.Block, error) {
 	getBlock, err := bm.fetcher(h)
 	if err != nil {
@@ -51,6 +51,11 @@ func (wm *wsSessionManager) RunBlockSession(ctx echo.Context) error {
 			fmt.Sprintf("given height(%d) is lower than genesis height(%d)", h, gh))
 		return nil
 	}
+	if ph := bm.PrunedHeight(); ph >= h {
+		_ = wss.response(int(jsonrpc.ErrorCodePruned),
+			fmt.Sprintf("given height(%d) is pruned(pruned=%d)", h, ph))
+		return nil
+	}
 
 	_ = wss.response(0, "")
 
@@ -11,6 +11,7 @@ import (
 	"github.com/icon-project/goloop/module"
 	"github.com/icon-project/goloop/server/jsonrpc"
 	"github.com/icon-project/goloop/service/scoreapi"
+	"github.com/icon-project/goloop/service/state"
 	"github.com/icon-project/goloop/service/txresult"
 )
 
@@ -21,15 +22,44 @@ type EventRequest struct {
 	ProgressInterval common.HexInt64 `json:"progressInterval,omitempty"`
 
 	Filters EventFilters `json:"eventFilters,omitempty"`
+	Preset  string       `json:"preset,omitempty"`
+}
+
+// eventFilterPresets are signatures of events from the chain SCORE for
+// each preset, which can be used instead of specifying event filters.
+var eventFilterPresets = map[string][]string{
+	"iiss": {
+		"StakeSet(Address,int,str)",
+		"DelegationSet(Address,str)",
+		"BondSet(Address,str,str)",
+	},
+}
+
+func presetEventFilters(name string) ([]*EventFilter, error) {
+	sigs, ok := eventFilterPresets[name]
+	if !ok {
+		return nil, errors.IllegalArgumentError.Errorf("UnknownPreset(preset=%s)", name)
+	}
+	filters := make([]*EventFilter, len(sigs))
+	for i, sig := range sigs {
+		filters[i] = &EventFilter{
+			Addr:      common.AddressToPtr(state.SystemAddress),
+			Signature: sig,
+		}
+	}
+	return filters, nil
 }
 
 type EventFilters []*EventFilter
 
 type EventFilter struct {
-	Addr       *common.Address `json:"addr,omitempty"`
-	Signature  string          `json:"event"`
-	Indexed    []*string       `json:"indexed,omitempty"`
-	Data       []*string       `json:"data,omitempty"`
+	Addr       *common.Address   `json:"addr,omitempty"`
+	Addrs      []*common.Address `json:"addrs,omitempty"`
+	Signature  string            `json:"event"`
+	Indexed    []*string         `json:"indexed,omitempty"`
+	Data       []*string         `json:"data,omitempty"`
+	Args       []*ArgFilter      `json:"args,omitempty"`
+	addrs      []*common.Address
 	indexedBSs [][]byte
 	dataBSs    [][]byte
 	numOfArgs  int
@@ -127,25 +157,43 @@ func (wm *wsSessionManager) RunEventSession(ctx echo.Context) error {
 			fmt.Sprintf("given height(%d) is lower than genesis height(%d)", h, gh))
 		return nil
 	}
+	if ph := bm.PrunedHeight(); ph >= h {
+		_ = wss.response(int(jsonrpc.ErrorCodePruned),
+			fmt.Sprintf("given height(%d) is pruned(pruned=%d)", h, ph))
+		return nil
+	}
 
 	_ = wss.response(0, "")
 
 	ech := make(chan error, 1)
-	wss.RunLoop(ech)
+	mch := make(chan []byte, 1)
+	done := make(chan struct{})
+	defer close(done)
+	wss.RunMessageLoop(ech, mch, done)
 
 	var bch <-chan module.Block
-	var pn ProgressNotification;
+	var pn ProgressNotification
 loop:
 	for {
-		bch, err = bm.WaitForBlock(h)
-		if err != nil {
-			break loop
+		if bch == nil {
+			bch, err = bm.WaitForBlock(h)
+			if err != nil {
+				break loop
+			}
 		}
 		msgSent := 0
 		select {
 		case err = <-ech:
 			break loop
+		case msg := <-mch:
+			res := filters.update(msg)
+			if err = wss.WriteJSON(res); err != nil {
+				wm.logger.Infof("fail to write json EventFilterUpdateResponse err:%+v\n", err)
+				break loop
+			}
+			continue loop
 		case blk, ok := <-bch:
+			bch = nil
 			if !ok {
 				break loop
 			}
@@ -198,8 +246,18 @@ loop:
 
 func (f *EventFilter) Compile() error {
 	lb := txresult.NewLogsBloom(nil)
+	f.addrs = nil
 	if f.Addr != nil {
-		lb.AddAddressOfLog(f.Addr)
+		f.addrs = append(f.addrs, f.Addr)
+	}
+	for idx, addr := range f.Addrs {
+		if addr == nil {
+			return errors.IllegalArgumentError.Errorf("NullAddress(idx=%d)", idx)
+		}
+		f.addrs = append(f.addrs, addr)
+	}
+	if len(f.addrs) == 1 {
+		lb.AddAddressOfLog(f.addrs[0])
 	}
 	f.numOfArgs = len(f.Indexed) + len(f.Data)
 	name, pts := txresult.DecomposeEventSignature(f.Signature)
@@ -237,10 +295,30 @@ func (f *EventFilter) Compile() error {
 		}
 		idx++
 	}
+	for i, arg := range f.Args {
+		if arg == nil {
+			return errors.IllegalArgumentError.Errorf("NullArgFilter(idx=%d)", i)
+		}
+		if err := arg.compile(pts); err != nil {
+			return err
+		}
+	}
 	f.lb = lb
 	return nil
 }
 
+func (f *EventFilter) matchAddress(addr module.Address) bool {
+	if len(f.addrs) == 0 {
+		return true
+	}
+	for _, a := range f.addrs {
+		if addr.Equal(a) {
+			return true
+		}
+	}
+	return false
+}
+
 // bytesEqual check equality of byte slice.
 // But it doesn't assume nil as empty bytes.
 func bytesEqual(b1 []byte, b2 []byte) bool {
@@ -269,7 +347,7 @@ func (f *EventFilter) MatchEvents(r module.Receipt, includeLogs bool) ([]common.
 
 func (f *EventFilter) MatchLog(el module.EventLog) bool {
 	if bytes.Equal([]byte(f.Signature), el.Indexed()[0]) {
-		if f.Addr != nil && !el.Address().Equal(f.Addr) {
+		if !f.matchAddress(el.Address()) {
 			return false
 		}
 		if f.numOfArgs > 0 {
@@ -291,6 +369,11 @@ func (f *EventFilter) MatchLog(el module.EventLog) bool {
 				}
 			}
 		}
+		for _, arg := range f.Args {
+			if !arg.matchLog(el) {
+				return false
+			}
+		}
 		return true
 	} else {
 		return false
@@ -319,10 +402,20 @@ func (f *EventRequest) Compile() (EventFilters, error) {
 		if len(f.Signature) != 0 {
 			return nil, errors.New("both eventFilters and event is used")
 		}
-		filters = f.Filters
-	} else {
+		filters = append(filters, f.Filters...)
+	} else if len(f.Signature) != 0 || len(f.Preset) == 0 {
 		filters = []*EventFilter{&f.EventFilter}
 	}
+	if len(f.Preset) != 0 {
+		preset, err := presetEventFilters(f.Preset)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, preset...)
+	}
+	if len(filters) > MaxEventFilters {
+		return nil, fmt.Errorf("too many filters %d > %d", len(filters), MaxEventFilters)
+	}
 	for idx, filter := range filters {
 		if filter == nil {
 			return nil, fmt.Errorf("invalid filter idx:%d", idx)
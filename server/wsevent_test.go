@@ -625,7 +625,9 @@ func TestEventRequest_Compile(t *testing.T) {
 		Height      common.HexInt64
 		Logs        common.HexBool
 		Filters     EventFilters
+		Preset      string
 	}
+	systemAddress := common.MustNewAddressFromString("cx0000000000000000000000000000000000000000")
 	tests := []struct {
 		name    string
 		fields  fields
@@ -687,6 +689,43 @@ func TestEventRequest_Compile(t *testing.T) {
 			},
 			assert.NoError,
 		},
+		{
+			"PresetWithFilters",
+			fields{
+				Filters: EventFilters{
+					&EventFilter{
+						Signature: "TestEvent2()",
+					},
+				},
+				Preset: "iiss",
+			},
+			EventFilters{
+				&EventFilter{
+					Signature: "TestEvent2()",
+				},
+				&EventFilter{
+					Addr:      systemAddress,
+					Signature: "StakeSet(Address,int,str)",
+				},
+				&EventFilter{
+					Addr:      systemAddress,
+					Signature: "DelegationSet(Address,str)",
+				},
+				&EventFilter{
+					Addr:      systemAddress,
+					Signature: "BondSet(Address,str,str)",
+				},
+			},
+			assert.NoError,
+		},
+		{
+			"UnknownPreset",
+			fields{
+				Preset: "unknown",
+			},
+			nil,
+			assert.Error,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -695,6 +734,7 @@ func TestEventRequest_Compile(t *testing.T) {
 				Height:      tt.fields.Height,
 				Logs:        tt.fields.Logs,
 				Filters:     tt.fields.Filters,
+				Preset:      tt.fields.Preset,
 			}
 			got, err := f.Compile()
 			if !tt.wantErr(t, err, fmt.Sprintf("Compile()")) {
@@ -0,0 +1,249 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/server/jsonrpc"
+	"github.com/icon-project/goloop/service/scoreapi"
+	"github.com/icon-project/goloop/service/txresult"
+)
+
+// MaxEventFilters is the maximum number of filters of an event session.
+const MaxEventFilters = 100
+
+// ArgFilter is a condition on a parameter of the event. Index is the
+// position of the parameter in the signature. The value should be one of
+// In, and it should be in the range of Gt, Gte, Lt and Lte, which are
+// allowed only for int parameters. Not negates the condition.
+type ArgFilter struct {
+	Index common.HexInt32 `json:"index"`
+	In    []string        `json:"in,omitempty"`
+	Gt    *string         `json:"gt,omitempty"`
+	Gte   *string         `json:"gte,omitempty"`
+	Lt    *string         `json:"lt,omitempty"`
+	Lte   *string         `json:"lte,omitempty"`
+	Not   common.HexBool  `json:"not,omitempty"`
+
+	inBSs         [][]byte
+	gt, gte       *big.Int
+	lt, lte       *big.Int
+	hasComparison bool
+}
+
+func parseIntArg(v *string) (*big.Int, error) {
+	if v == nil {
+		return nil, nil
+	}
+	value := new(big.Int)
+	if err := intconv.ParseBigInt(value, *v); err != nil {
+		return nil, errors.IllegalArgumentError.Wrapf(err, "InvalidInteger(%s)", *v)
+	}
+	return value, nil
+}
+
+func (f *ArgFilter) compile(pts []string) error {
+	idx := int(f.Index.Value)
+	if idx < 0 || idx >= len(pts) {
+		return errors.IllegalArgumentError.Errorf("InvalidArgIndex(idx=%d)", idx)
+	}
+	pt := pts[idx]
+	f.inBSs = make([][]byte, len(f.In))
+	for i, v := range f.In {
+		bs, err := txresult.EventDataStringToBytesByType(pt, v)
+		if err != nil {
+			return errors.IllegalArgumentError.Wrapf(err, "InvalidArgValue(idx=%d,value=%s)", idx, v)
+		}
+		f.inBSs[i] = bs
+	}
+	f.hasComparison = f.Gt != nil || f.Gte != nil || f.Lt != nil || f.Lte != nil
+	if f.hasComparison {
+		if scoreapi.DataTypeOf(pt).Tag() != scoreapi.TInteger {
+			return errors.IllegalArgumentError.Errorf("NotComparable(idx=%d,type=%s)", idx, pt)
+		}
+		var err error
+		if f.gt, err = parseIntArg(f.Gt); err != nil {
+			return err
+		}
+		if f.gte, err = parseIntArg(f.Gte); err != nil {
+			return err
+		}
+		if f.lt, err = parseIntArg(f.Lt); err != nil {
+			return err
+		}
+		if f.lte, err = parseIntArg(f.Lte); err != nil {
+			return err
+		}
+	}
+	if len(f.In) == 0 && !f.hasComparison {
+		return errors.IllegalArgumentError.Errorf("NoCondition(idx=%d)", idx)
+	}
+	return nil
+}
+
+func (f *ArgFilter) matchValue(v []byte) bool {
+	if v == nil {
+		return false
+	}
+	if len(f.inBSs) > 0 {
+		found := false
+		for _, bs := range f.inBSs {
+			if bytesEqual(bs, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.hasComparison {
+		value := intconv.BigIntSetBytes(new(big.Int), v)
+		if f.gt != nil && value.Cmp(f.gt) <= 0 {
+			return false
+		}
+		if f.gte != nil && value.Cmp(f.gte) < 0 {
+			return false
+		}
+		if f.lt != nil && value.Cmp(f.lt) >= 0 {
+			return false
+		}
+		if f.lte != nil && value.Cmp(f.lte) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// valueOf returns the value of the parameter in the event log. Indexed
+// parameters come first, and others are in data.
+func (f *ArgFilter) valueOf(el module.EventLog) []byte {
+	idx := int(f.Index.Value)
+	indexed := el.Indexed()
+	if idx+1 < len(indexed) {
+		return indexed[idx+1]
+	}
+	idx -= len(indexed) - 1
+	if data := el.Data(); idx < len(data) {
+		return data[idx]
+	}
+	return nil
+}
+
+func (f *ArgFilter) matchLog(el module.EventLog) bool {
+	return f.matchValue(f.valueOf(el)) != f.Not.Value
+}
+
+// EventFilterUpdate is sent by the client of the event session to change
+// filters without losing the current position. Filters are identified by
+// their position in the session. Filters of the request take positions
+// from zero in order, and added filters take positions of removed ones
+// first, then following positions.
+type EventFilterUpdate struct {
+	AddFilters    EventFilters      `json:"addFilters,omitempty"`
+	RemoveFilters []common.HexInt32 `json:"removeFilters,omitempty"`
+}
+
+type EventFilterUpdateResponse struct {
+	WSResponse
+	Filters []common.HexInt32 `json:"filters,omitempty"`
+}
+
+func (fs *EventFilters) apply(u *EventFilterUpdate) ([]common.HexInt32, error) {
+	for _, id := range u.RemoveFilters {
+		idx := int(id.Value)
+		if idx < 0 || idx >= len(*fs) || (*fs)[idx] == nil {
+			return nil, errors.IllegalArgumentError.Errorf("UnknownFilter(id=%d)", idx)
+		}
+	}
+	for idx, f := range u.AddFilters {
+		if f == nil {
+			return nil, errors.IllegalArgumentError.Errorf("NullFilter(idx=%d)", idx)
+		}
+		if err := f.Compile(); err != nil {
+			return nil, err
+		}
+	}
+	removed := make(map[int32]bool)
+	for _, id := range u.RemoveFilters {
+		removed[id.Value] = true
+	}
+	active := len(u.AddFilters) - len(removed)
+	for _, f := range *fs {
+		if f != nil {
+			active += 1
+		}
+	}
+	if active > MaxEventFilters {
+		return nil, errors.IllegalArgumentError.Errorf(
+			"TooManyFilters(filters=%d,max=%d)", active, MaxEventFilters)
+	}
+
+	for id := range removed {
+		(*fs)[id] = nil
+	}
+	ids := make([]common.HexInt32, len(u.AddFilters))
+	slot := 0
+	for i, f := range u.AddFilters {
+		for slot < len(*fs) && (*fs)[slot] != nil {
+			slot += 1
+		}
+		if slot < len(*fs) {
+			(*fs)[slot] = f
+		} else {
+			*fs = append(*fs, f)
+		}
+		ids[i].Value = int32(slot)
+	}
+	for len(*fs) > 0 && (*fs)[len(*fs)-1] == nil {
+		*fs = (*fs)[:len(*fs)-1]
+	}
+	return ids, nil
+}
+
+// update applies the update message from the client. Nothing is changed
+// on failure.
+func (fs *EventFilters) update(msg []byte) *EventFilterUpdateResponse {
+	var u EventFilterUpdate
+	jd := json.NewDecoder(bytes.NewBuffer(msg))
+	jd.DisallowUnknownFields()
+	if err := jd.Decode(&u); err != nil {
+		return &EventFilterUpdateResponse{
+			WSResponse: WSResponse{
+				Code:    int(jsonrpc.ErrorCodeJsonParse),
+				Message: "bad filter update",
+			},
+		}
+	}
+	ids, err := fs.apply(&u)
+	if err != nil {
+		return &EventFilterUpdateResponse{
+			WSResponse: WSResponse{
+				Code:    int(jsonrpc.ErrorCodeInvalidParams),
+				Message: err.Error(),
+			},
+		}
+	}
+	return &EventFilterUpdateResponse{Filters: ids}
+}
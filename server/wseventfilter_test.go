@@ -0,0 +1,341 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/server/jsonrpc"
+)
+
+const (
+	testTransferSig = "Transfer(Address,Address,int,bytes)"
+	testAddr1       = "hx0000000000000000000000000000000000000001"
+	testAddr2       = "hx0000000000000000000000000000000000000002"
+	testAddr3       = "hx0000000000000000000000000000000000000003"
+)
+
+func newTestTransfer(score, from, to, value string) *testEventLog {
+	return newTestEventLog(score, testTransferSig,
+		[][]string{{"Address", from}, {"Address", to}, {"int", value}},
+		[][]string{{"bytes", "0x01"}},
+	)
+}
+
+func argFilter(index int32, f func(af *ArgFilter)) *ArgFilter {
+	af := &ArgFilter{Index: common.HexInt32{Value: index}}
+	f(af)
+	return af
+}
+
+func TestArgFilter_Compile(t *testing.T) {
+	tests := []struct {
+		name string
+		arg  *ArgFilter
+	}{
+		{"InvalidIndex", argFilter(4, func(af *ArgFilter) { af.In = []string{"0x1"} })},
+		{"NoCondition", argFilter(2, func(af *ArgFilter) {})},
+		{"InvalidValue", argFilter(0, func(af *ArgFilter) { af.In = []string{"0x1"} })},
+		{"NotComparable", argFilter(3, func(af *ArgFilter) { af.Gt = stringPtr("0x1") })},
+		{"InvalidInteger", argFilter(2, func(af *ArgFilter) { af.Gt = stringPtr("abc") })},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &EventFilter{
+				Signature: testTransferSig,
+				Args:      []*ArgFilter{tt.arg},
+			}
+			assert.Error(t, f.Compile())
+		})
+	}
+}
+
+func TestEventFilter_MatchLogWithArgs(t *testing.T) {
+	el := newTestTransfer("cx01", testAddr1, testAddr2, "0x64")
+	tests := []struct {
+		name   string
+		filter *EventFilter
+		want   bool
+	}{
+		{
+			"AddressList",
+			&EventFilter{
+				Addrs: []*common.Address{
+					common.MustNewAddressFromString("cx02"),
+					common.MustNewAddressFromString("cx01"),
+				},
+				Signature: testTransferSig,
+			},
+			true,
+		},
+		{
+			"AddressListMismatch",
+			&EventFilter{
+				Addr:      common.MustNewAddressFromString("cx02"),
+				Addrs:     []*common.Address{common.MustNewAddressFromString("cx03")},
+				Signature: testTransferSig,
+			},
+			false,
+		},
+		{
+			"InSet",
+			&EventFilter{
+				Signature: testTransferSig,
+				Args: []*ArgFilter{
+					argFilter(0, func(af *ArgFilter) { af.In = []string{testAddr3, testAddr1} }),
+				},
+			},
+			true,
+		},
+		{
+			"InSetMismatch",
+			&EventFilter{
+				Signature: testTransferSig,
+				Args: []*ArgFilter{
+					argFilter(1, func(af *ArgFilter) { af.In = []string{testAddr3, testAddr1} }),
+				},
+			},
+			false,
+		},
+		{
+			"Range",
+			&EventFilter{
+				Signature: testTransferSig,
+				Args: []*ArgFilter{
+					argFilter(2, func(af *ArgFilter) {
+						af.Gte = stringPtr("0x64")
+						af.Lt = stringPtr("1000")
+					}),
+				},
+			},
+			true,
+		},
+		{
+			"RangeMismatch",
+			&EventFilter{
+				Signature: testTransferSig,
+				Args: []*ArgFilter{
+					argFilter(2, func(af *ArgFilter) { af.Gt = stringPtr("0x64") }),
+				},
+			},
+			false,
+		},
+		{
+			"Not",
+			&EventFilter{
+				Signature: testTransferSig,
+				Args: []*ArgFilter{
+					argFilter(1, func(af *ArgFilter) {
+						af.In = []string{testAddr2}
+						af.Not.Value = true
+					}),
+				},
+			},
+			false,
+		},
+		{
+			"Data",
+			&EventFilter{
+				Signature: testTransferSig,
+				Args: []*ArgFilter{
+					argFilter(3, func(af *ArgFilter) { af.In = []string{"0x02", "0x01"} }),
+				},
+			},
+			true,
+		},
+		{
+			"Combined",
+			&EventFilter{
+				Signature: testTransferSig,
+				Indexed:   []*string{stringPtr(testAddr1)},
+				Args: []*ArgFilter{
+					argFilter(1, func(af *ArgFilter) {
+						af.In = []string{testAddr1}
+						af.Not.Value = true
+					}),
+					argFilter(2, func(af *ArgFilter) { af.Lte = stringPtr("0x64") }),
+				},
+			},
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.NoError(t, tt.filter.Compile())
+			assert.Equal(t, tt.want, tt.filter.MatchLog(el))
+		})
+	}
+}
+
+func TestEventFilters_Update(t *testing.T) {
+	filters := EventFilters{
+		&EventFilter{Signature: "A()"},
+		&EventFilter{Signature: "B()"},
+	}
+	for _, f := range filters {
+		assert.NoError(t, f.Compile())
+	}
+
+	res := filters.update([]byte(`{"addFilters":[{"event":"C()"},{"event":"D()"}],"removeFilters":["0x0"]}`))
+	assert.Equal(t, 0, res.Code)
+	assert.Equal(t, []common.HexInt32{{Value: 0}, {Value: 2}}, res.Filters)
+	assert.Len(t, filters, 3)
+	assert.Equal(t, "C()", filters[0].Signature)
+
+	res = filters.update([]byte(`{"removeFilters":["0x2"]}`))
+	assert.Equal(t, 0, res.Code)
+	assert.Len(t, filters, 2)
+	res = filters.update([]byte(`{"removeFilters":["0x0"]}`))
+	assert.Equal(t, 0, res.Code)
+	assert.Len(t, filters, 2)
+	assert.Nil(t, filters[0])
+
+	// nothing is changed on failure
+	for _, msg := range []string{
+		`{"removeFilters":["0x0"]}`,
+		`{"removeFilters":["0x2"]}`,
+		`{"addFilters":[{"event":"D()"}],"removeFilters":["0x5"]}`,
+		`{"addFilters":[{"event":"D("}]}`,
+		`{"unknown":"0x1"}`,
+	} {
+		res = filters.update([]byte(msg))
+		assert.NotEqual(t, 0, res.Code, msg)
+	}
+	assert.Len(t, filters, 2)
+
+	res = filters.update([]byte(`{"removeFilters":["0x1"]}`))
+	assert.Equal(t, 0, res.Code)
+	assert.Len(t, filters, 0)
+	_, contained := filters.FilteredByLogBloom(newTestReceipt([]*testEventLog{
+		newTestEventLog("cx01", "B()", nil, nil),
+		newTestEventLog("cx01", "C()", nil, nil),
+	}).LogsBloom())
+	assert.False(t, contained)
+
+	// filters of a session are limited
+	var u EventFilterUpdate
+	for i := 0; i < MaxEventFilters+1; i++ {
+		u.AddFilters = append(u.AddFilters, &EventFilter{Signature: "A()"})
+	}
+	bs, _ := json.Marshal(&u)
+	res = filters.update(bs)
+	assert.NotEqual(t, 0, res.Code)
+	assert.Len(t, filters, 0)
+	u.AddFilters = u.AddFilters[1:]
+	bs, _ = json.Marshal(&u)
+	res = filters.update(bs)
+	assert.Equal(t, 0, res.Code)
+	assert.Len(t, filters, MaxEventFilters)
+	res = filters.update([]byte(`{"addFilters":[{"event":"A()"}]}`))
+	assert.NotEqual(t, 0, res.Code)
+	res = filters.update([]byte(`{"addFilters":[{"event":"A()"}],"removeFilters":["0x10"]}`))
+	assert.Equal(t, 0, res.Code)
+	assert.Equal(t, []common.HexInt32{{Value: 0x10}}, res.Filters)
+}
+
+func TestWSSessionManager_UpdateEventFilters(t *testing.T) {
+	logger := log.New()
+	logger.SetOutput(io.Discard)
+
+	connCh := make(chan *testWebSocketConn, 1)
+	upgrader := newTestWebsocketUpgrader(func(ctx echo.Context, conn *testWebSocketConn) {
+		connCh <- conn
+	})
+	wm := newWSSessionManagerWithUpgrader(logger, 10, upgrader)
+
+	blkReceipts := blockReceipts{
+		"empty": testReceiptList{},
+		"events": testReceiptList{
+			newTestReceipt([]*testEventLog{
+				newTestEventLog("cx01", "A()", nil, nil),
+				newTestEventLog("cx01", "B()", nil, nil),
+			}),
+		},
+	}
+	release := make(chan struct{})
+	chain := newTestChain(0,
+		func(h int64) (getBlockFunc, error) {
+			if h < 2 {
+				return func() module.Block {
+					return &testBlock{height: h, result: "empty"}
+				}, nil
+			}
+			return func() module.Block {
+				<-release
+				return &testBlock{
+					height: h,
+					result: "events",
+					lb:     blkReceipts["events"].LogsBloom(),
+				}
+			}, nil
+		},
+		blkReceipts,
+	)
+	go wm.RunEventSession(newTestContext(chain))
+
+	conn := <-connCh
+	assert.NoError(t, conn.clientWriteJSON(map[string]interface{}{
+		"height": "0x1",
+		"event":  "A()",
+	}))
+	var res EventFilterUpdateResponse
+	bs, err := conn.clientRead()
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(bs, &res))
+	assert.Equal(t, 0, res.Code)
+
+	// while it waits for the block 2
+	assert.NoError(t, conn.clientWriteJSON(map[string]interface{}{
+		"addFilters": []interface{}{
+			map[string]interface{}{"event": "B()"},
+		},
+		"removeFilters": []string{"0x0"},
+	}))
+	bs, err = conn.clientRead()
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(bs, &res))
+	assert.Equal(t, 0, res.Code)
+	assert.Equal(t, []common.HexInt32{{Value: 0}}, res.Filters)
+
+	assert.NoError(t, conn.clientWriteJSON(map[string]interface{}{
+		"removeFilters": []string{"0x1"},
+	}))
+	bs, err = conn.clientRead()
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(bs, &res))
+	assert.Equal(t, int(jsonrpc.ErrorCodeInvalidParams), res.Code)
+
+	release <- struct{}{}
+	bs, err = conn.clientRead()
+	assert.NoError(t, err)
+	var en EventNotification
+	assert.NoError(t, json.Unmarshal(bs, &en))
+	assert.EqualValues(t, 2, en.Height.Value)
+	assert.Equal(t, []common.HexInt32{{Value: 1}}, en.Events)
+
+	conn.Close()
+	close(release)
+	wm.StopAllSessions()
+}
@@ -0,0 +1,167 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/server/jsonrpc"
+)
+
+type ValidationRequest struct {
+	Height           common.HexInt64   `json:"height"`
+	Addresses        []*common.Address `json:"addresses"`
+	Threshold        common.HexInt64   `json:"threshold"`
+	ProgressInterval common.HexInt64   `json:"progressInterval,omitempty"`
+}
+
+type ValidationNotification struct {
+	Address  *common.Address `json:"address"`
+	Height   common.HexInt64 `json:"height"`
+	FailCont common.HexInt64 `json:"failCont"`
+}
+
+// validationHistory is a part of the validation history of a validator
+// which is used for notifications.
+type validationHistory struct {
+	FailCont int64
+	Alerts   []int64
+}
+
+// getValidationHistories returns validation histories of the validators
+// with one lookup.
+func getValidationHistories(vh module.ValidationHistory, addrs []*common.Address, threshold int64) ([]validationHistory, error) {
+	owners := make([]module.Address, len(addrs))
+	for i, addr := range addrs {
+		owners[i] = addr
+	}
+	jsos, err := vh.GetValidationHistories(owners, threshold)
+	if err != nil {
+		return nil, err
+	}
+	hs := make([]validationHistory, len(jsos))
+	for i, jso := range jsos {
+		hs[i].FailCont, _ = jso["failCont"].(int64)
+		alerts, _ := jso["alerts"].([]interface{})
+		for _, alert := range alerts {
+			if h, ok := alert.(int64); ok {
+				hs[i].Alerts = append(hs[i].Alerts, h)
+			}
+		}
+	}
+	return hs, nil
+}
+
+// RunValidationSession notifies when consecutive validation failures of
+// the validators reach the threshold. It uses validation history kept by
+// the node, so it's available only on nodes keeping the history.
+func (wm *wsSessionManager) RunValidationSession(ctx echo.Context) error {
+	var vr ValidationRequest
+	wss, err := wm.initSession(ctx, &vr)
+	if err != nil {
+		return err
+	}
+	defer wm.StopSession(wss)
+
+	bm := wss.chain.BlockManager()
+	if bm == nil {
+		_ = wss.response(int(jsonrpc.ErrorCodeServer), "Stopped")
+		return nil
+	}
+	var vh module.ValidationHistory
+	if vc, ok := wss.chain.(module.ValidationHistoryChain); ok {
+		vh = vc.ValidationHistory()
+	}
+	if vh == nil {
+		_ = wss.response(int(jsonrpc.ErrorCodeMethodNotFound), "ValidationHistoryDisabled")
+		return nil
+	}
+
+	h := vr.Height.Value
+	if gh := wss.chain.GenesisStorage().Height(); gh > h {
+		_ = wss.response(int(jsonrpc.ErrorCodeInvalidParams),
+			fmt.Sprintf("given height(%d) is lower than genesis height(%d)", h, gh))
+		return nil
+	}
+	if len(vr.Addresses) == 0 || vr.Threshold.Value <= 0 {
+		_ = wss.response(int(jsonrpc.ErrorCodeInvalidParams),
+			fmt.Sprintf("invalid addresses(%d) or threshold(%d)", len(vr.Addresses), vr.Threshold.Value))
+		return nil
+	}
+	for _, addr := range vr.Addresses {
+		if addr == nil {
+			_ = wss.response(int(jsonrpc.ErrorCodeInvalidParams), "invalid address(null)")
+			return nil
+		}
+	}
+
+	_ = wss.response(0, "")
+
+	ech := make(chan error, 1)
+	wss.RunLoop(ech)
+
+	// alerts at or before the height are already notified
+	notified := make([]int64, len(vr.Addresses))
+	for i := range notified {
+		notified[i] = h - 1
+	}
+
+	var bch <-chan module.Block
+	var pn ProgressNotification
+loop:
+	for {
+		bch, err = bm.WaitForBlock(h)
+		if err != nil {
+			break loop
+		}
+		select {
+		case err = <-ech:
+			break loop
+		case _, ok := <-bch:
+			if !ok {
+				break loop
+			}
+			msgSent := 0
+			hs, err := getValidationHistories(vh, vr.Addresses, vr.Threshold.Value)
+			if err != nil {
+				wm.logger.Infof("fail to get validation history height=%d err:%+v\n", h, err)
+				hs = nil
+			}
+			for i, hist := range hs {
+				addr := vr.Addresses[i]
+				for _, alert := range hist.Alerts {
+					if alert <= notified[i] {
+						continue
+					}
+					notified[i] = alert
+					vn := ValidationNotification{
+						Address:  addr,
+						Height:   common.HexInt64{Value: alert},
+						FailCont: common.HexInt64{Value: hist.FailCont},
+					}
+					if err = wss.WriteJSON(&vn); err != nil {
+						wm.logger.Infof("fail to write json ValidationNotification err:%+v\n", err)
+						break loop
+					}
+					msgSent += 1
+				}
+			}
+			// notify progress
+			if pi := vr.ProgressInterval.Value; pi > 0 {
+				last := pn.Progress.Value
+				if last == 0 || (h-last) >= pi || msgSent > 0 {
+					pn.Progress.Value = h
+					if err := wss.WriteJSON(&pn); err != nil {
+						wm.logger.Infof("fail to write json ProgressNotification(height=%d)", h)
+						break loop
+					}
+				}
+			}
+		}
+		h++
+	}
+	wm.logger.Warnf("%+v\n", err)
+	return nil
+}
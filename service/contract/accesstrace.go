@@ -0,0 +1,56 @@
+package contract
+
+import (
+	"bytes"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/state"
+	"github.com/icon-project/goloop/service/trace"
+)
+
+// accessTracingAccountState reports storage accesses of the account to the
+// trace logger. It's used only for module.TraceModeAccessList.
+type accessTracingAccountState struct {
+	state.AccountState
+	addr module.Address
+	log  *trace.Logger
+}
+
+func (s *accessTracingAccountState) GetValue(k []byte) ([]byte, error) {
+	value, err := s.AccountState.GetValue(k)
+	if err == nil {
+		s.log.OnStorageAccess(module.StorageGet, s.addr, k, value, nil)
+	}
+	return value, err
+}
+
+func (s *accessTracingAccountState) SetValue(k, v []byte) ([]byte, error) {
+	old, err := s.AccountState.SetValue(k, v)
+	if err == nil {
+		s.log.OnStorageAccess(module.StorageSet, s.addr, k, v, old)
+	}
+	return old, err
+}
+
+func (s *accessTracingAccountState) DeleteValue(k []byte) ([]byte, error) {
+	old, err := s.AccountState.DeleteValue(k)
+	if err == nil {
+		s.log.OnStorageAccess(module.StorageDelete, s.addr, k, nil, old)
+	}
+	return old, err
+}
+
+func newAccessTracingAccountState(id []byte, as state.AccountState, log *trace.Logger) state.AccountState {
+	var addr module.Address
+	if as.IsContract() || bytes.Equal(id, state.SystemID) {
+		addr = common.NewContractAddress(id)
+	} else {
+		addr = common.NewAccountAddress(id)
+	}
+	return &accessTracingAccountState{
+		AccountState: as,
+		addr:         addr,
+		log:          log,
+	}
+}
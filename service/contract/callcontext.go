@@ -33,6 +33,7 @@ type (
 	CallContext interface {
 		Context
 		ReadOnlyMode() bool
+		QueryMode() bool
 		Call(handler ContractHandler, limit *big.Int) (error, *big.Int, *codec.TypedObj, module.Address)
 		OnResult(status error, flags ResultFlag, stepUsed *big.Int, result *codec.TypedObj, addr module.Address)
 		OnCall(handler ContractHandler, limit *big.Int)
@@ -108,6 +109,8 @@ type callContext struct {
 	ioTime  time.Duration
 
 	log *trace.Logger
+
+	isQuery bool
 }
 
 func prefixForFrame(id int) string {
@@ -125,9 +128,17 @@ func NewCallContext(ctx Context, limit *big.Int, isQuery bool) CallContext {
 
 		waiter: make(chan interface{}, 8),
 		log:    traceLogger,
+
+		isQuery: isQuery,
 	}
 }
 
+// QueryMode returns whether it's called for the query, not for
+// the transaction.
+func (cc *callContext) QueryMode() bool {
+	return cc.isQuery
+}
+
 func (cc *callContext) ReadOnlyMode() bool {
 	cc.lock.Lock()
 	defer cc.lock.Unlock()
@@ -148,18 +159,30 @@ func (cc *callContext) pushFrame(handler ContractHandler, limit *big.Int) *callF
 		frame.snapshot = cc.GetSnapshot()
 	}
 	logger.OnFrameEnter(cc.frame.fid)
+	if logger.TraceMode() == module.TraceModeCallTree {
+		var call *module.TraceCall
+		if th, ok := handler.(TraceableHandler); ok {
+			call = th.TraceCall()
+		} else {
+			call = new(module.TraceCall)
+		}
+		call.StepLimit = limit
+		logger.OnCallStart(call)
+	}
 	frame.fid = cc.nextFID
 	cc.nextFID += 1
 	cc.frame = frame
 	return frame
 }
 
-func (cc *callContext) popFrame(success bool) *callFrame {
+func (cc *callContext) popFrame(status error) *callFrame {
 	cc.lock.Lock()
 	defer cc.lock.Unlock()
 
+	success := status == nil
 	frame := cc.frame
-	cc.frame.log.OnFrameExit(success, &frame.stepUsed)
+	frame.log.OnCallEnd(status, &frame.stepUsed)
+	frame.log.OnFrameExit(success, &frame.stepUsed)
 	if !frame.isReadOnly {
 		if success {
 			frame.parent.applyFrameLogsOf(frame)
@@ -385,7 +408,7 @@ func (cc *callContext) handleResult(target *callFrame, status error, result *cod
 		return false
 	}
 
-	current := cc.popFrame(status == nil)
+	current := cc.popFrame(status)
 	if current == nil {
 		return false
 	}
@@ -456,6 +479,14 @@ func (cc *callContext) GetBalance(addr module.Address) *big.Int {
 	}
 }
 
+func (cc *callContext) GetAccountState(id []byte) state.AccountState {
+	as := cc.Context.GetAccountState(id)
+	if as != nil && cc.log.TraceMode() == module.TraceModeAccessList {
+		return newAccessTracingAccountState(id, as, cc.log)
+	}
+	return as
+}
+
 func (cc *callContext) ReserveExecutor() error {
 	if cc.executor == nil {
 		cc.executor = cc.EEManager().GetExecutor(cc.EEPriority())
@@ -363,6 +363,18 @@ func (h *CallHandler) ensureMethodAndParams(eeType state.EEType) error {
 	return err
 }
 
+func (h *CallHandler) TraceCall() *module.TraceCall {
+	call := h.CommonHandler.TraceCall()
+	call.Type = "call"
+	call.Method = h.name
+	if h.paramObj != nil {
+		call.Params, _ = common.DecodeAnyForJSON(h.paramObj)
+	} else if len(h.params) > 0 {
+		call.Params = json.RawMessage(h.params)
+	}
+	return call
+}
+
 func (h *CallHandler) GetMethodName() string {
 	return h.name
 }
@@ -639,6 +651,10 @@ type TransferAndCallHandler struct {
 	*CallHandler
 }
 
+func (h *TransferAndCallHandler) TraceCall() *module.TraceCall {
+	return h.CallHandler.TraceCall()
+}
+
 func (h *TransferAndCallHandler) Prepare(ctx Context) (state.WorldContext, error) {
 	if h.To.IsContract() {
 		return h.CallHandler.Prepare(ctx)
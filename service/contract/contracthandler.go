@@ -30,6 +30,12 @@ type (
 		TraceLogger() *trace.Logger
 	}
 
+	// TraceableHandler provides the information of the call
+	// for module.TraceModeCallTree.
+	TraceableHandler interface {
+		TraceCall() *module.TraceCall
+	}
+
 	SyncContractHandler interface {
 		ContractHandler
 		ExecuteSync(cc CallContext) (error, *codec.TypedObj, module.Address)
@@ -95,3 +101,11 @@ func (h *CommonHandler) TraceLogger() *trace.Logger {
 func (h *CommonHandler) Logger() log.Logger {
 	return h.Log
 }
+
+func (h *CommonHandler) TraceCall() *module.TraceCall {
+	return &module.TraceCall{
+		From:  h.From,
+		To:    h.To,
+		Value: h.Value,
+	}
+}
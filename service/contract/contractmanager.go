@@ -20,6 +20,7 @@ import (
 
 	"github.com/icon-project/goloop/common/codec"
 	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/service/eeproxy"
 	"github.com/icon-project/goloop/service/state"
 
 	"github.com/icon-project/goloop/common/db"
@@ -443,6 +444,20 @@ func storeJava(path string, code []byte, log log.Logger) error {
 	return nil
 }
 
+func storeWasm(path string, code []byte, log log.Logger) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err = os.MkdirAll(path, 0755); err != nil {
+			return errors.WithCode(err, errors.CriticalIOError)
+		}
+	}
+	sPath := filepath.Join(path, eeproxy.WasmCode)
+	if err := ioutil.WriteFile(sPath, code, 0644); err != nil {
+		_ = os.RemoveAll(sPath)
+		return errors.WithCode(err, errors.CriticalIOError)
+	}
+	return nil
+}
+
 func storeByEEType(e state.EEType, path string, code []byte, log log.Logger) error {
 	var err error
 	switch e {
@@ -450,6 +465,8 @@ func storeByEEType(e state.EEType, path string, code []byte, log log.Logger) err
 		err = storePython(path, code, log)
 	case state.JavaEE:
 		err = storeJava(path, code, log)
+	case state.WasmEE:
+		err = storeWasm(path, code, log)
 	default:
 		err = scoreresult.Errorf(module.StatusInvalidParameter,
 			"UnexpectedEEType(%v)\n", e)
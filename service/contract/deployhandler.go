@@ -15,6 +15,7 @@ import (
 	"github.com/icon-project/goloop/common/intconv"
 	"github.com/icon-project/goloop/common/log"
 	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/eeproxy"
 	"github.com/icon-project/goloop/service/scoreapi"
 	"github.com/icon-project/goloop/service/scoredb"
 	"github.com/icon-project/goloop/service/scoreresult"
@@ -223,6 +224,12 @@ func genContractAddr(from module.Address, timestamp int64, nonce, salt *big.Int)
 	return addr
 }
 
+func (h *DeployHandler) TraceCall() *module.TraceCall {
+	call := h.CommonHandler.TraceCall()
+	call.Type = "deploy"
+	return call
+}
+
 func (h *DeployHandler) Prepare(ctx Context) (state.WorldContext, error) {
 	lq := []state.LockRequest{
 		{state.WorldIDStr, state.AccountWriteLock},
@@ -330,6 +337,12 @@ func (h *DeployHandler) DoExecuteSync(cc CallContext) (error, *codec.TypedObj, m
 			h.contentType, cc.GetEnabledEETypes().String()), nil, nil
 	}
 
+	if h.eeType == state.WasmEE {
+		if err := eeproxy.ValidateWasmCode(h.content.GetBytes()); err != nil {
+			return err, nil, nil
+		}
+	}
+
 	if update == false {
 		if as.InitContractAccount(h.From) == false {
 			return errors.ErrExecutionFail, nil, nil
@@ -58,6 +58,15 @@ type DepositHandler struct {
 	data *DepositJSON
 }
 
+func (h *DepositHandler) TraceCall() *module.TraceCall {
+	call := h.CommonHandler.TraceCall()
+	call.Type = "deposit"
+	if h.data != nil {
+		call.Method = h.data.Action
+	}
+	return call
+}
+
 func (h *DepositHandler) Prepare(ctx Context) (state.WorldContext, error) {
 	var lq []state.LockRequest
 	if h.data != nil && h.data.Action == DepositActionWithdraw {
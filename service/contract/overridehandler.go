@@ -0,0 +1,112 @@
+package contract
+
+import (
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/scoreresult"
+	"github.com/icon-project/goloop/service/state"
+)
+
+// CodeOverrideHandler replaces the code of the contract without calling
+// on_install or on_update of the contract. It's used to override the state
+// for the query or the estimation, and for the account override patch of
+// the chain in dev mode.
+type CodeOverrideHandler struct {
+	*CommonHandler
+	contentType string
+	content     []byte
+}
+
+func NewCodeOverrideHandler(ch *CommonHandler, contentType string, content []byte) *CodeOverrideHandler {
+	return &CodeOverrideHandler{
+		CommonHandler: ch,
+		contentType:   contentType,
+		content:       content,
+	}
+}
+
+func (h *CodeOverrideHandler) ExecuteSync(cc CallContext) (error, *codec.TypedObj, module.Address) {
+	h.Log.TSystemf("OVERRIDE start to=%s contentType=%s", h.To, h.contentType)
+
+	eeType, ok := state.EETypeFromContentType(h.contentType)
+	if !ok || eeType == state.SystemEE {
+		return scoreresult.InvalidParameterError.Errorf(
+			"InvalidContentType(%s)", h.contentType), nil, nil
+	}
+	if !cc.GetEnabledEETypes().Contains(eeType) {
+		return scoreresult.InvalidParameterError.Errorf(
+			"NotEnabledContentType(%s)", h.contentType), nil, nil
+	}
+
+	as := cc.GetAccountState(h.To.ID())
+	if !as.IsContract() {
+		as.InitContractAccount(h.From)
+	}
+	id := crypto.SHA3Sum256(h.content)
+	if _, err := as.DeployContract(h.content, eeType, h.contentType, nil, id); err != nil {
+		return err, nil, nil
+	}
+
+	cgah := newCallGetAPIHandler(NewCommonHandler(h.From, h.To, nil, false, h.Log))
+	status, _, _, _ := cc.Call(cgah, cc.StepAvailable())
+	if status != nil {
+		return status, nil, nil
+	}
+	if err := as.ActivateNextContract(); err != nil {
+		return err, nil, nil
+	}
+	if err := as.AcceptContract(id, id); err != nil {
+		return err, nil, nil
+	}
+	h.Log.TSystemf("OVERRIDE done code=<%x>", as.Contract().CodeHash())
+	return nil, nil, nil
+}
+
+// ApplyAccountOverride applies changes for the account on the state of
+// the call context.
+func ApplyAccountOverride(cc CallContext, ov *module.AccountOverride, logger log.Logger) error {
+	if ov.Address == nil {
+		return scoreresult.InvalidParameterError.New("InvalidOverride(NoAddress)")
+	}
+	as := cc.GetAccountState(ov.Address.ID())
+	if ov.Content != nil {
+		if !ov.Address.IsContract() {
+			return scoreresult.InvalidParameterError.Errorf(
+				"InvalidOverride(%s,CodeForEOA)", ov.Address)
+		}
+		owner := as.ContractOwner()
+		if owner == nil {
+			owner = state.SystemAddress
+		}
+		handler := NewCodeOverrideHandler(
+			NewCommonHandler(owner, ov.Address, nil, false, logger),
+			ov.ContentType, ov.Content)
+		status, _, _, _ := cc.Call(handler, cc.StepAvailable())
+		if status != nil {
+			return scoreresult.InvalidParameterError.Wrapf(scoreresult.Validate(status),
+				"InvalidOverride(%s,Code)", ov.Address)
+		}
+	}
+	if ov.Balance != nil {
+		if ov.Balance.Sign() < 0 {
+			return scoreresult.InvalidParameterError.Errorf(
+				"InvalidOverride(%s,Balance=%d)", ov.Address, ov.Balance)
+		}
+		as.SetBalance(ov.Balance)
+	}
+	for k, v := range ov.Storage {
+		var err error
+		if v == nil {
+			_, err = as.DeleteValue([]byte(k))
+		} else {
+			_, err = as.SetValue([]byte(k), v)
+		}
+		if err != nil {
+			return scoreresult.InvalidParameterError.Wrapf(err,
+				"InvalidOverride(%s,Storage)", ov.Address)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package contract
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/service/state"
+)
+
+// OverrideContractManager is the contract manager for a query with code
+// overrides. Codes of the overrides are kept only for the query. They are
+// extracted to a temporary directory removed on Dispose instead of the
+// store shared by the contract manager, which is kept for the chain.
+type OverrideContractManager struct {
+	ContractManager
+	log log.Logger
+
+	lock  sync.Mutex
+	dir   string
+	codes map[string]bool
+}
+
+// AddCode registers the hash of the code overriding the contract.
+func (cm *OverrideContractManager) AddCode(codeHash []byte) {
+	cm.lock.Lock()
+	defer cm.lock.Unlock()
+	cm.codes[string(codeHash)] = true
+}
+
+func (cm *OverrideContractManager) PrepareContractStore(
+	ws state.WorldState, contract state.ContractState) (ContractStore, error) {
+	codeHash := contract.CodeHash()
+	cm.lock.Lock()
+	defer cm.lock.Unlock()
+	if !cm.codes[string(codeHash)] {
+		return cm.ContractManager.PrepareContractStore(ws, contract)
+	}
+	if cm.dir == "" {
+		dir, err := ioutil.TempDir("", "override")
+		if err != nil {
+			return nil, errors.CriticalIOError.Wrap(err, "FailToMakeTempDir")
+		}
+		cm.dir = dir
+	}
+	path := filepath.Join(cm.dir, "0x"+hex.EncodeToString(codeHash))
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		code, err := contract.Code()
+		if err != nil {
+			return nil, err
+		}
+		if err := storeByEEType(contract.EEType(), path, code, cm.log); err != nil {
+			_ = os.RemoveAll(path)
+			return nil, err
+		}
+	}
+	return overrideContractStore(path), nil
+}
+
+// Dispose removes codes of the overrides extracted for the query.
+func (cm *OverrideContractManager) Dispose() {
+	cm.lock.Lock()
+	defer cm.lock.Unlock()
+	if cm.dir != "" {
+		if err := os.RemoveAll(cm.dir); err != nil {
+			cm.log.Warnf("FailToRemoveOverrideCodes(dir=%s,err=%+v)", cm.dir, err)
+		}
+		cm.dir = ""
+	}
+}
+
+func NewOverrideContractManager(cm ContractManager, logger log.Logger) *OverrideContractManager {
+	return &OverrideContractManager{
+		ContractManager: cm,
+		log:             logger,
+		codes:           make(map[string]bool),
+	}
+}
+
+type overrideContractStore string
+
+func (cs overrideContractStore) WaitResult() (string, error) {
+	return string(cs), nil
+}
+
+func (cs overrideContractStore) Dispose() {
+	// nothing to do
+}
@@ -0,0 +1,81 @@
+package contract
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/service/eeproxy"
+	"github.com/icon-project/goloop/service/state"
+)
+
+type testOverrideContract struct {
+	state.ContractState
+	code []byte
+}
+
+func (c *testOverrideContract) CodeHash() []byte {
+	return crypto.SHA3Sum256(c.code)
+}
+
+func (c *testOverrideContract) Code() ([]byte, error) {
+	return c.code, nil
+}
+
+func (c *testOverrideContract) EEType() state.EEType {
+	return state.WasmEE
+}
+
+type testSharedContractManager struct {
+	ContractManager
+	prepared int
+}
+
+func (cm *testSharedContractManager) PrepareContractStore(
+	ws state.WorldState, contract state.ContractState) (ContractStore, error) {
+	cm.prepared += 1
+	return overrideContractStore("shared"), nil
+}
+
+func TestOverrideContractManager_PrepareContractStore(t *testing.T) {
+	shared := new(testSharedContractManager)
+	cm := NewOverrideContractManager(shared, log.New())
+
+	override := &testOverrideContract{code: []byte("override")}
+	cm.AddCode(override.CodeHash())
+
+	// codes of overrides are extracted for the query
+	cs, err := cm.PrepareContractStore(nil, override)
+	assert.NoError(t, err)
+	path, err := cs.WaitResult()
+	assert.NoError(t, err)
+	cs.Dispose()
+	code, err := ioutil.ReadFile(filepath.Join(path, eeproxy.WasmCode))
+	assert.NoError(t, err)
+	assert.Equal(t, override.code, code)
+	assert.Equal(t, 0, shared.prepared)
+
+	cs, err = cm.PrepareContractStore(nil, override)
+	assert.NoError(t, err)
+	path2, err := cs.WaitResult()
+	assert.NoError(t, err)
+	assert.Equal(t, path, path2)
+
+	// others are prepared by the shared contract manager
+	cs, err = cm.PrepareContractStore(nil, &testOverrideContract{code: []byte("other")})
+	assert.NoError(t, err)
+	path2, err = cs.WaitResult()
+	assert.NoError(t, err)
+	assert.Equal(t, "shared", path2)
+	assert.Equal(t, 1, shared.prepared)
+
+	// codes of overrides are removed after the query
+	cm.Dispose()
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -77,6 +77,41 @@ func (h *patchHandler) handleSkipTransaction(cc CallContext) error {
 	return nil
 }
 
+// handleAccountOverride applies overrides of the patch. It's only for
+// the chain in dev mode, so it requires the decoder of the chain to support
+// it, and the proposer to be the only validator.
+func (h *patchHandler) handleAccountOverride(cc CallContext) error {
+	if vs := cc.GetValidatorState(); vs.Len() != 1 {
+		return scoreresult.AccessDeniedError.Errorf(
+			"AccountOverrideWithValidators(n=%d)", vs.Len())
+	}
+	decode := cc.PatchDecoder()
+	if decode == nil {
+		return scoreresult.InvalidParameterError.New("PatchDecoderIsNil")
+	}
+	pd, err := decode(h.patch.Type, h.patch.Data)
+	if err != nil {
+		h.Log.Warnf("PatchHandler: decode fail err=%+v", err)
+		return scoreresult.InvalidParameterError.Wrap(err, "DecodeFail")
+	}
+	p, ok := pd.(module.AccountOverridePatch)
+	if !ok {
+		return scoreresult.InvalidParameterError.Errorf("InvalidPatch(%T)", pd)
+	}
+	if cc.BlockHeight() != p.Height() {
+		return scoreresult.InvalidParameterError.Errorf("InvalidHeight(bh=%d,ph=%d)",
+			cc.BlockHeight(), p.Height())
+	}
+	ovs := p.Overrides()
+	for i := range ovs {
+		if err := ApplyAccountOverride(cc, &ovs[i], h.Log); err != nil {
+			return err
+		}
+	}
+	h.Log.Warnf("PatchHandler: ACCOUNT OVERRIDE height=%d accounts=%d", p.Height(), len(ovs))
+	return nil
+}
+
 func (h *patchHandler) ExecuteSync(cc CallContext) (error, *codec.TypedObj, module.Address) {
 	vs := cc.GetValidatorState()
 	if idx := vs.IndexOf(h.From); idx < 0 {
@@ -93,6 +128,9 @@ func (h *patchHandler) ExecuteSync(cc CallContext) (error, *codec.TypedObj, modu
 	case module.PatchTypeSkipTransaction:
 		s := h.handleSkipTransaction(cc)
 		return s, nil, nil
+	case module.PatchTypeAccountOverride:
+		s := h.handleAccountOverride(cc)
+		return s, nil, nil
 	default:
 		return scoreresult.InvalidParameterError.Errorf("InvalidDataType(%s)", h.patch.Type), nil, nil
 	}
@@ -117,7 +155,7 @@ func ParsePatchData(data []byte) (*Patch, error) {
 			"InvalidJSON(json=%s)", data)
 	}
 	switch p.Type {
-	case module.PatchTypeSkipTransaction:
+	case module.PatchTypeSkipTransaction, module.PatchTypeAccountOverride:
 		// do nothing
 	default:
 		return nil, scoreresult.InvalidParameterError.Errorf(
@@ -18,6 +18,12 @@ func newTransferHandler(ch *CommonHandler) *TransferHandler {
 	return &TransferHandler{ch}
 }
 
+func (h *TransferHandler) TraceCall() *module.TraceCall {
+	call := h.CommonHandler.TraceCall()
+	call.Type = "transfer"
+	return call
+}
+
 func (h *TransferHandler) ExecuteSync(cc CallContext) (err error, ro *codec.TypedObj, addr module.Address) {
 	h.Log.TSystemf("TRANSFER start from=%s to=%s value=%s",
 		h.From, h.To, h.Value)
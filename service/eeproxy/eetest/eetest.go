@@ -0,0 +1,488 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package eetest provides a conformance suite for execution engines. It
+// runs a fixture contract through eeproxy.Proxy with an in-memory world
+// implementing eeproxy.CallContext, so the same cases apply to all engines.
+//
+// The fixture contract should have following APIs.
+//
+//	setValue(v: bytes)            stores v
+//	getValue() -> bytes           returns the stored value (readonly)
+//	emit(v: bytes)                emits Emitted(bytes) with indexed v
+//	fail(code: int)               reverts with the code
+//	loop()                        never ends
+//	callGet(addr: Address)->bytes returns getValue() of addr
+package eetest
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/eeproxy"
+	"github.com/icon-project/goloop/service/scoreapi"
+	"github.com/icon-project/goloop/service/scoreresult"
+)
+
+const (
+	EventSignature = "Emitted(bytes)"
+
+	resultTimeout = 10 * time.Second
+)
+
+// Target is an execution engine under the test.
+type Target interface {
+	// Proxy returns a proxy for the test. The proxy should be released
+	// on the cleanup of the test.
+	Proxy(t *testing.T) eeproxy.Proxy
+	// Code returns the path of the directory of the fixture contract.
+	Code(t *testing.T) string
+}
+
+// Installer is implemented by targets whose contracts should be installed
+// before the calls.
+type Installer interface {
+	// InstallMethod returns the method called to install the contract.
+	InstallMethod() string
+}
+
+var defaultStepCosts = map[string]interface{}{
+	"get":        25,
+	"set":        320,
+	"delete":     -240,
+	"log":        100,
+	"getBase":    2000,
+	"setBase":    20000,
+	"deleteBase": 200,
+	"logBase":    5000,
+}
+
+type Event struct {
+	Addr    module.Address
+	Indexed [][]byte
+	Data    [][]byte
+}
+
+type graphEntry struct {
+	next  int
+	hash  []byte
+	graph []byte
+}
+
+// World is an in-memory world for contracts sharing the code.
+type World struct {
+	lock      sync.Mutex
+	proxy     eeproxy.Proxy
+	code      string
+	stores    map[string]map[string][]byte
+	graphs    map[string]*graphEntry
+	events    []Event
+	eid       int
+	Height    int64
+	Timestamp int64
+	Owner     module.Address
+}
+
+func NewWorld(proxy eeproxy.Proxy, code string) *World {
+	return &World{
+		proxy:     proxy,
+		code:      code,
+		stores:    make(map[string]map[string][]byte),
+		graphs:    make(map[string]*graphEntry),
+		Height:    10,
+		Timestamp: 1700000000000000,
+		Owner:     common.MustNewAddressFromString("hx0000000000000000000000000000000000000001"),
+	}
+}
+
+// Deploy registers the contract address using the code.
+func (w *World) Deploy(addr module.Address) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.stores[string(addr.Bytes())] = make(map[string][]byte)
+}
+
+func (w *World) Events() []Event {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return append([]Event{}, w.events...)
+}
+
+func (w *World) nextEID() int {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.eid += 1
+	return w.eid
+}
+
+func (w *World) store(addr module.Address) map[string][]byte {
+	return w.stores[string(addr.Bytes())]
+}
+
+type Result struct {
+	Status error
+	Steps  *big.Int
+	Result *codec.TypedObj
+}
+
+// Invoke calls the method of the contract and waits for the result.
+func (w *World) Invoke(from, to module.Address, readOnly bool, limit int64,
+	method string, params ...interface{},
+) *Result {
+	ctx := w.newContext(from, to, readOnly, nil)
+	if ps, err := common.EncodeAny(params); err != nil {
+		return &Result{Status: err}
+	} else {
+		return ctx.invoke(big.NewInt(limit), method, ps)
+	}
+}
+
+// GetAPI returns the API information of the code.
+func (w *World) GetAPI() (*scoreapi.Info, error) {
+	ctx := w.newContext(nil, nil, true, nil)
+	if err := w.proxy.GetAPI(ctx, w.code); err != nil {
+		return nil, err
+	}
+	select {
+	case r := <-ctx.api:
+		return r.info, r.status
+	case <-time.After(resultTimeout):
+		return nil, scoreresult.ErrTimeout
+	}
+}
+
+type apiResult struct {
+	status error
+	info   *scoreapi.Info
+}
+
+// Context is a context of a call implementing eeproxy.CallContext.
+type Context struct {
+	world    *World
+	from, to module.Address
+	readOnly bool
+	parent   *Context
+
+	result chan *Result
+	api    chan *apiResult
+}
+
+func (w *World) newContext(from, to module.Address, readOnly bool, parent *Context) *Context {
+	return &Context{
+		world:    w,
+		from:     from,
+		to:       to,
+		readOnly: readOnly,
+		parent:   parent,
+		result:   make(chan *Result, 1),
+		api:      make(chan *apiResult, 1),
+	}
+}
+
+func (ctx *Context) invoke(limit *big.Int, method string, params *codec.TypedObj) *Result {
+	w := ctx.world
+	w.lock.Lock()
+	store := w.store(ctx.to)
+	w.lock.Unlock()
+	if store == nil {
+		return &Result{
+			Status: scoreresult.ErrContractNotFound,
+			Steps:  new(big.Int),
+		}
+	}
+	err := w.proxy.Invoke(ctx, w.code, ctx.readOnly, ctx.from, ctx.to,
+		new(big.Int), limit, method, params, ctx.to.ID(), w.nextEID(), nil)
+	if err != nil {
+		return &Result{Status: err, Steps: new(big.Int)}
+	}
+	select {
+	case r := <-ctx.result:
+		return r
+	case <-time.After(resultTimeout):
+		_ = w.proxy.Kill()
+		return &Result{Status: scoreresult.ErrTimeout, Steps: limit}
+	}
+}
+
+func (ctx *Context) GetValue(key []byte) ([]byte, error) {
+	w := ctx.world
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.store(ctx.to)[string(key)], nil
+}
+
+func (ctx *Context) SetValue(key []byte, value []byte) ([]byte, error) {
+	if ctx.readOnly {
+		return nil, scoreresult.AccessDeniedError.New("SetValueInQuery")
+	}
+	w := ctx.world
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	store := w.store(ctx.to)
+	old := store[string(key)]
+	store[string(key)] = append([]byte{}, value...)
+	return old, nil
+}
+
+func (ctx *Context) DeleteValue(key []byte) ([]byte, error) {
+	if ctx.readOnly {
+		return nil, scoreresult.AccessDeniedError.New("DeleteValueInQuery")
+	}
+	w := ctx.world
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	store := w.store(ctx.to)
+	old := store[string(key)]
+	delete(store, string(key))
+	return old, nil
+}
+
+func (ctx *Context) ArrayDBContains(prefix, value []byte, limit int64) (bool, int, int, error) {
+	return false, 0, 0, nil
+}
+
+func (ctx *Context) GetInfo() *codec.TypedObj {
+	w := ctx.world
+	origin := ctx
+	for origin.parent != nil {
+		origin = origin.parent
+	}
+	return common.MustEncodeAny(map[string]interface{}{
+		"B.height":    w.Height,
+		"B.timestamp": w.Timestamp,
+		"T.hash":      crypto.SHA3Sum256([]byte("tx")),
+		"T.index":     0,
+		"T.timestamp": w.Timestamp,
+		"T.nonce":     0,
+		"T.from":      origin.from,
+		"Revision":    int(module.LatestRevision),
+		"StepCosts":   defaultStepCosts,
+		"C.owner":     w.Owner,
+	})
+}
+
+func (ctx *Context) GetBalance(addr module.Address) *big.Int {
+	return new(big.Int)
+}
+
+func (ctx *Context) OnEvent(addr module.Address, indexed, data [][]byte) error {
+	if ctx.readOnly {
+		return errors.InvalidStateError.New("EventInReadOnlyMode")
+	}
+	w := ctx.world
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.events = append(w.events, Event{addr, indexed, data})
+	return nil
+}
+
+func (ctx *Context) OnResult(status error, flag int, steps *big.Int, result *codec.TypedObj) {
+	ctx.result <- &Result{status, steps, result}
+}
+
+// OnCall runs the call in the world, then sends the result to the caller.
+func (ctx *Context) OnCall(from, to module.Address, value, limit *big.Int, dataType string, dataObj *codec.TypedObj) {
+	go func() {
+		var r *Result
+		if dataType != "call" || dataObj.Type != codec.TypeDict {
+			r = &Result{
+				Status: scoreresult.InvalidParameterError.New("UnsupportedCall"),
+				Steps:  new(big.Int),
+			}
+		} else {
+			data := dataObj.Object.(*codec.TypedDict).Map
+			method := common.DecodeAsString(data["method"], "")
+			callee := ctx.world.newContext(from, to, ctx.readOnly, ctx)
+			r = callee.invoke(limit, method, data["params"])
+		}
+		eid := ctx.world.nextEID()
+		err := ctx.world.proxy.SendResult(ctx, r.Status, r.Steps, r.Result, eid, eid-1)
+		if err != nil {
+			ctx.OnResult(err, 0, limit, nil)
+		}
+	}()
+}
+
+func (ctx *Context) OnAPI(status error, info *scoreapi.Info) {
+	ctx.api <- &apiResult{status, info}
+}
+
+func (ctx *Context) OnSetFeeProportion(portion int) {
+	// fee isn't handled by the world
+}
+
+func (ctx *Context) SetCode(code []byte) error {
+	return errors.InvalidStateError.New("UnsupportedSetCode")
+}
+
+func (ctx *Context) GetObjGraph(flags bool) (int, []byte, []byte, error) {
+	w := ctx.world
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	g, ok := w.graphs[string(ctx.to.Bytes())]
+	if !ok {
+		return 0, nil, nil, errors.NotFoundError.New("NoObjGraph")
+	}
+	if flags {
+		return g.next, g.hash, g.graph, nil
+	}
+	return g.next, g.hash, nil, nil
+}
+
+func (ctx *Context) SetObjGraph(flags bool, nextHash int, objGraph []byte) error {
+	if ctx.readOnly {
+		return nil
+	}
+	w := ctx.world
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	g, ok := w.graphs[string(ctx.to.Bytes())]
+	if !ok {
+		g = new(graphEntry)
+		w.graphs[string(ctx.to.Bytes())] = g
+	}
+	g.next = nextHash
+	if flags {
+		g.graph = objGraph
+		g.hash = crypto.SHA3Sum256(objGraph)
+	}
+	return nil
+}
+
+func (ctx *Context) Logger() log.Logger {
+	return log.GlobalLogger()
+}
+
+func statusOf(err error) module.Status {
+	s, _ := scoreresult.StatusOf(err)
+	return s
+}
+
+var (
+	user  = common.MustNewAddressFromString("hx0000000000000000000000000000000000000002")
+	addr1 = common.MustNewAddressFromString("cx0000000000000000000000000000000000000011")
+	addr2 = common.MustNewAddressFromString("cx0000000000000000000000000000000000000012")
+)
+
+const stepLimit = 10000000
+
+// Run runs the conformance suite for the target.
+func Run(t *testing.T, target Target) {
+	newWorld := func(t *testing.T) *World {
+		w := NewWorld(target.Proxy(t), target.Code(t))
+		for _, addr := range []module.Address{addr1, addr2} {
+			w.Deploy(addr)
+			if inst, ok := target.(Installer); ok {
+				r := w.Invoke(user, addr, false, stepLimit, inst.InstallMethod())
+				if !assert.NoError(t, r.Status) {
+					t.FailNow()
+				}
+			}
+		}
+		return w
+	}
+
+	t.Run("GetAPI", func(t *testing.T) {
+		w := newWorld(t)
+		info, err := w.GetAPI()
+		assert.NoError(t, err)
+		if assert.NotNil(t, info) {
+			m := info.GetMethod("getValue")
+			if assert.NotNil(t, m) {
+				assert.True(t, m.IsReadOnly())
+				assert.Equal(t, []scoreapi.DataType{scoreapi.Bytes}, m.Outputs)
+			}
+			assert.NotNil(t, info.GetMethod(EventSignature))
+		}
+	})
+
+	t.Run("Storage", func(t *testing.T) {
+		w := newWorld(t)
+		r := w.Invoke(user, addr1, false, stepLimit, "setValue", []byte("hello"))
+		assert.NoError(t, r.Status)
+		assert.True(t, r.Steps.Sign() > 0)
+
+		r = w.Invoke(user, addr1, true, stepLimit, "getValue")
+		assert.NoError(t, r.Status)
+		assert.Equal(t, common.MustEncodeAny([]byte("hello")), r.Result)
+
+		r = w.Invoke(user, addr2, true, stepLimit, "getValue")
+		assert.NoError(t, r.Status)
+		assert.True(t, r.Result == nil || r.Result.Type == codec.TypeNil)
+	})
+
+	t.Run("ReadOnly", func(t *testing.T) {
+		w := newWorld(t)
+		r := w.Invoke(user, addr1, true, stepLimit, "setValue", []byte("hello"))
+		assert.Error(t, r.Status)
+		assert.Empty(t, w.store(addr1))
+	})
+
+	t.Run("Event", func(t *testing.T) {
+		w := newWorld(t)
+		r := w.Invoke(user, addr1, false, stepLimit, "emit", []byte{0x12, 0x34})
+		assert.NoError(t, r.Status)
+		events := w.Events()
+		if assert.Len(t, events, 1) {
+			assert.True(t, addr1.Equal(events[0].Addr))
+			assert.Equal(t, [][]byte{[]byte(EventSignature), {0x12, 0x34}}, events[0].Indexed)
+			assert.Empty(t, events[0].Data)
+		}
+	})
+
+	t.Run("Revert", func(t *testing.T) {
+		w := newWorld(t)
+		r := w.Invoke(user, addr1, false, stepLimit, "fail", 7)
+		assert.Error(t, r.Status)
+		assert.Equal(t, module.StatusReverted+7, statusOf(r.Status))
+	})
+
+	t.Run("OutOfStep", func(t *testing.T) {
+		w := newWorld(t)
+		r := w.Invoke(user, addr1, false, 100000, "loop")
+		assert.Equal(t, module.StatusOutOfStep, statusOf(r.Status))
+		assert.EqualValues(t, 100000, r.Steps.Int64())
+	})
+
+	t.Run("Call", func(t *testing.T) {
+		w := newWorld(t)
+		r := w.Invoke(user, addr2, false, stepLimit, "setValue", []byte("world"))
+		assert.NoError(t, r.Status)
+
+		direct := w.Invoke(user, addr2, true, stepLimit, "getValue")
+		assert.NoError(t, direct.Status)
+
+		r = w.Invoke(user, addr1, true, stepLimit, "callGet", addr2)
+		assert.NoError(t, r.Status)
+		assert.Equal(t, common.MustEncodeAny([]byte("world")), r.Result)
+		assert.True(t, r.Steps.Cmp(direct.Steps) > 0)
+
+		// failure of the callee fails the caller
+		unknown := common.MustNewAddressFromString("cx0000000000000000000000000000000000000099")
+		r = w.Invoke(user, addr1, true, stepLimit, "callGet", unknown)
+		assert.Equal(t, module.StatusContractNotFound, statusOf(r.Status))
+	})
+}
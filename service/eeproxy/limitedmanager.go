@@ -0,0 +1,112 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package eeproxy
+
+import (
+	"sync"
+
+	"github.com/icon-project/goloop/common/errors"
+)
+
+// LimitedManager is a Manager limiting the number of executors reserved
+// from the base manager. It's used to share the executors of the node with
+// other chains. Limits are set by SetInstances, and it doesn't change the
+// instances of the base manager. Requests for transactions take released
+// executors before requests for queries.
+type LimitedManager struct {
+	Manager
+
+	lock     sync.Mutex
+	waiter   *sync.Cond
+	total    int
+	limits   [numberOfPriorities]int
+	assigned [numberOfPriorities]int
+	waiting  [numberOfPriorities]int
+}
+
+func (m *LimitedManager) canAssignInLock(pr RequestPriority) bool {
+	if m.limits[pr] > 0 && m.assigned[pr] >= m.limits[pr] {
+		return false
+	}
+	if pr == ForQuery && m.waiting[ForTransaction] > 0 {
+		return false
+	}
+	if m.total > 0 && m.assigned[ForTransaction]+m.assigned[ForQuery] >= m.total {
+		return false
+	}
+	return true
+}
+
+func (m *LimitedManager) GetExecutor(pr RequestPriority) *Executor {
+	m.lock.Lock()
+	for !m.canAssignInLock(pr) {
+		m.waiting[pr] += 1
+		m.waiter.Wait()
+		m.waiting[pr] -= 1
+	}
+	m.assigned[pr] += 1
+	m.lock.Unlock()
+
+	e := m.Manager.GetExecutor(pr)
+	if e == nil {
+		m.onRelease(pr)
+		return nil
+	}
+	e.limiter = m
+	return e
+}
+
+func (m *LimitedManager) onRelease(pr RequestPriority) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.assigned[pr] -= 1
+	m.waiter.Broadcast()
+}
+
+// SetInstances sets the maximum number of executors reserved at the same
+// time in total and for each priority. Zero means no limit.
+func (m *LimitedManager) SetInstances(total, tx, query int) error {
+	if total < 0 || tx < 0 || query < 0 {
+		return errors.IllegalArgumentError.Errorf(
+			"InvalidInstances(total=%d,tx=%d,query=%d)", total, tx, query)
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.total = total
+	m.limits[ForTransaction] = tx
+	m.limits[ForQuery] = query
+	m.waiter.Broadcast()
+	return nil
+}
+
+// Usage returns the number of executors reserved for each priority.
+func (m *LimitedManager) Usage() (tx, query int) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.assigned[ForTransaction], m.assigned[ForQuery]
+}
+
+func NewLimitedManager(m Manager) *LimitedManager {
+	lm := &LimitedManager{
+		Manager: m,
+	}
+	lm.waiter = sync.NewCond(&lm.lock)
+	return lm
+}
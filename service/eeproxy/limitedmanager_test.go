@@ -0,0 +1,123 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package eeproxy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimitedManager_GetExecutor(t *testing.T) {
+	em, _ := newTestManager(t)
+	em.engines = nil
+	assert.NoError(t, em.SetInstances(10, 10, 10))
+
+	lm := NewLimitedManager(em)
+	assert.NoError(t, lm.SetInstances(2, 1, 2))
+
+	tx := lm.GetExecutor(ForTransaction)
+	q := lm.GetExecutor(ForQuery)
+	txCnt, qCnt := lm.Usage()
+	assert.Equal(t, 1, txCnt)
+	assert.Equal(t, 1, qCnt)
+
+	// total limit is reached
+	ch := make(chan *Executor)
+	go func() {
+		ch <- lm.GetExecutor(ForQuery)
+	}()
+	select {
+	case <-ch:
+		assert.Fail(t, "executor over the limit")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	tx.Release()
+	select {
+	case e := <-ch:
+		e.Release()
+	case <-time.After(time.Second):
+		assert.Fail(t, "executor isn't assigned after release")
+	}
+
+	// releasing twice doesn't change the usage
+	tx.Release()
+	q.Release()
+	txCnt, qCnt = lm.Usage()
+	assert.Equal(t, 0, txCnt)
+	assert.Equal(t, 0, qCnt)
+
+	assert.Error(t, lm.SetInstances(-1, 0, 0))
+}
+
+func TestLimitedManager_TransactionFirst(t *testing.T) {
+	em, _ := newTestManager(t)
+	em.engines = nil
+	assert.NoError(t, em.SetInstances(10, 10, 10))
+
+	lm := NewLimitedManager(em)
+	assert.NoError(t, lm.SetInstances(1, 1, 1))
+
+	waitFor := func(pr RequestPriority) {
+		for i := 0; i < 100; i++ {
+			lm.lock.Lock()
+			waiting := lm.waiting[pr]
+			lm.lock.Unlock()
+			if waiting > 0 {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		assert.Fail(t, "no waiting request")
+	}
+
+	q := lm.GetExecutor(ForQuery)
+	txCh := make(chan *Executor)
+	go func() {
+		txCh <- lm.GetExecutor(ForTransaction)
+	}()
+	waitFor(ForTransaction)
+	qCh := make(chan *Executor)
+	go func() {
+		qCh <- lm.GetExecutor(ForQuery)
+	}()
+	waitFor(ForQuery)
+
+	// the transaction takes the released executor
+	q.Release()
+	var tx *Executor
+	select {
+	case tx = <-txCh:
+	case <-time.After(time.Second):
+		assert.Fail(t, "executor isn't assigned to the transaction")
+	}
+	select {
+	case <-qCh:
+		assert.Fail(t, "executor is assigned to the query first")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	tx.Release()
+	select {
+	case q = <-qCh:
+		q.Release()
+	case <-time.After(time.Second):
+		assert.Fail(t, "executor isn't assigned to the query")
+	}
+}
@@ -1,6 +1,7 @@
 package eeproxy
 
 import (
+	"crypto/tls"
 	"sync"
 
 	"github.com/icon-project/goloop/common"
@@ -29,6 +30,9 @@ const (
 type Manager interface {
 	GetExecutor(pr RequestPriority) *Executor
 	SetInstances(total, tx, query int) error
+	SetHealthConfig(cfg HealthConfig) error
+	Stats() *Stats
+	ListenRemote(addr string, cfg *tls.Config) error
 	Loop() error
 	Close() error
 }
@@ -44,32 +48,103 @@ type Engine interface {
 	OnClose(conn ipc.Connection) bool
 }
 
+// LocalEngine is an engine running contracts in the process. It doesn't
+// use connections, and a proxy is created for each executor.
+type LocalEngine interface {
+	Engine
+	NewProxy() Proxy
+}
+
+// RemoteEngine is an engine running executors on remote hosts. Only the
+// remote engine accepts connections over TLS, and it authenticates the
+// executor with the name of the peer.
+type RemoteEngine interface {
+	Engine
+	Authenticate(peer, uid string) error
+	Retire(uid string) bool
+}
+
 type Executor struct {
 	priority RequestPriority
 	manager  *executorManager
 	proxies  map[string]*proxy
+	locals   map[string]Proxy
+	owner    *Executor
+	limiter  *LimitedManager
+
+	// it may be killed by the other goroutine while it's released.
+	lock     sync.Mutex
+	released bool
 }
 
 func (e *Executor) Get(name string) Proxy {
 	if p, ok := e.proxies[name]; ok {
 		return p
+	} else if p, ok := e.locals[name]; ok {
+		return p
 	} else {
 		return nil
 	}
 }
 
 func (e *Executor) Release() {
+	if e.owner != nil {
+		// proxies are released by the owner.
+		return
+	}
+	e.release()
+}
+
+func (e *Executor) isReleased() bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.released
+}
+
+func (e *Executor) release() {
+	e.lock.Lock()
+	released := e.released
+	e.released = true
+	e.lock.Unlock()
+	if released {
+		return
+	}
 	e.manager.onRelease(e.priority, e)
+	if e.limiter != nil {
+		e.limiter.onRelease(e.priority)
+	}
 	for _, p := range e.proxies {
 		p.Release()
 	}
+	for _, p := range e.locals {
+		p.Release()
+	}
 }
 
 func (e *Executor) Kill() {
 	for _, p := range e.proxies {
 		p.Kill()
 	}
-	e.Release()
+	for _, p := range e.locals {
+		p.Kill()
+	}
+	if e.owner != nil {
+		e.owner.release()
+	} else {
+		e.release()
+	}
+}
+
+// share returns an executor using same proxies with the executor.
+// Releasing returned executor doesn't release the proxies.
+func (e *Executor) share() *Executor {
+	return &Executor{
+		priority: e.priority,
+		manager:  e.manager,
+		proxies:  e.proxies,
+		locals:   e.locals,
+		owner:    e,
+	}
 }
 
 type engine struct {
@@ -90,12 +165,19 @@ type executorManager struct {
 	lock sync.Mutex
 
 	server ipc.Server
+	remote ipc.Server
 
 	engines map[string]*engine
+	locals  map[string]LocalEngine
 
 	executorLimit  int
 	executorStates [numberOfPriorities]executorState
 
+	health      HealthConfig
+	restarts    map[string]map[string]int
+	lastRestart *RestartRecord
+	stop        chan struct{}
+
 	log log.Logger
 }
 
@@ -117,6 +199,14 @@ func (em *executorManager) onReady(p *proxy) error {
 			return ScaleDownError.Errorf("ScalingDown(target=%d,active=%d)",
 				em.executorLimit, e.active)
 		}
+		if re, ok := e.engine.(RemoteEngine); ok && re.Retire(p.uid) {
+			e.active -= 1
+			em.log.Infof("Retire proxy=%s-%s", p.scoreType, p.uid)
+			if _, err := re.Kill(p.uid); err != nil {
+				em.log.Warnf("Fail to kill proxy=%s-%s err=%+v", p.scoreType, p.uid, err)
+			}
+			return ScaleDownError.Errorf("Retired(uid=%s)", p.uid)
+		}
 	} else {
 		if !e.engine.OnAttach(p.uid) {
 			em.log.Warnf("InvalidUUID(uid=%s)", p.uid)
@@ -158,6 +248,7 @@ func (em *executorManager) OnClose(c ipc.Connection) {
 		for p := e.ready; p != nil; p = p.next {
 			if p.conn == c {
 				p.detach()
+				em.recordRestartInLock(p, p.closeReason())
 				e.active -= 1
 				return
 			}
@@ -165,6 +256,7 @@ func (em *executorManager) OnClose(c ipc.Connection) {
 		for p := e.using; p != nil; p = p.next {
 			if p.conn == c {
 				p.detach()
+				em.recordRestartInLock(p, p.closeReason())
 				l.CallAfterUnlock(func() {
 					p.OnClose()
 				})
@@ -179,12 +271,53 @@ func (em *executorManager) OnClose(c ipc.Connection) {
 }
 
 func (em *executorManager) Close() error {
+	em.lock.Lock()
+	if em.stop != nil {
+		close(em.stop)
+		em.stop = nil
+	}
+	remote := em.remote
+	em.remote = nil
+	em.lock.Unlock()
+
+	if remote != nil {
+		if err := remote.Close(); err != nil {
+			em.log.Warnf("Fail to close remote server err=%+v", err)
+		}
+	}
 	if err := em.server.Close(); err != nil {
 		return err
 	}
 	return nil
 }
 
+// ListenRemote accepts connections of remote engines over TLS. The config
+// should verify certificates of peers.
+func (em *executorManager) ListenRemote(addr string, cfg *tls.Config) error {
+	if cfg == nil || cfg.ClientAuth != tls.RequireAndVerifyClientCert {
+		return errors.IllegalArgumentError.New("ClientCertificateNotRequired")
+	}
+	em.lock.Lock()
+	defer em.lock.Unlock()
+
+	if em.remote != nil {
+		return errors.InvalidStateError.New("AlreadyListening")
+	}
+	srv := ipc.NewTLSServer(cfg)
+	if err := srv.Listen("tcp", addr); err != nil {
+		return err
+	}
+	srv.SetHandler(em)
+	em.remote = srv
+	em.log.Infof("Listen remote engines addr=%s", srv.Addr())
+	go func() {
+		if err := srv.Loop(); err != nil {
+			em.log.Infof("Remote server stopped err=%+v", err)
+		}
+	}()
+	return nil
+}
+
 func (em *executorManager) createExecutorInLock(pr RequestPriority) *Executor {
 	ps := make(map[string]*proxy)
 	for name, e := range em.engines {
@@ -196,12 +329,17 @@ func (em *executorManager) createExecutorInLock(pr RequestPriority) *Executor {
 	for i, p := range ps {
 		p.detach()
 		p.attachTo(&em.engines[i].using)
-		p.reserve()
+		p.reserve(pr)
+	}
+	ls := make(map[string]Proxy, len(em.locals))
+	for name, e := range em.locals {
+		ls[name] = e.NewProxy()
 	}
 	return &Executor{
 		priority: pr,
 		manager:  em,
 		proxies:  ps,
+		locals:   ls,
 	}
 }
 
@@ -271,6 +409,13 @@ func (em *executorManager) onEEMConnect(conn ipc.Connection, t string, v uint16)
 
 	for _, e := range em.engines {
 		if e.engine.Type() == t {
+			_, remote := e.engine.(RemoteEngine)
+			if remote != ipc.IsTLS(conn) {
+				if remote {
+					return errors.InvalidStateError.Errorf("LocalForRemoteEngine(%s)", t)
+				}
+				return errors.InvalidStateError.Errorf("RemoteForLocalEngine(%s)", t)
+			}
 			return e.engine.OnConnect(conn, v)
 		}
 	}
@@ -280,12 +425,37 @@ func (em *executorManager) onEEMConnect(conn ipc.Connection, t string, v uint16)
 // onEEConnect handle a connection from Execution Environment
 func (em *executorManager) onEEConnect(conn ipc.Connection, t string, v uint16, uid string) error {
 	em.log.Infof("ExecutorManager.onEEConnect(type=%s,version=%d,uid=%s)", t, v, uid)
+	if err := em.authenticate(conn, t, uid); err != nil {
+		return err
+	}
 	if _, err := newProxy(em, conn, em.log, t, v, uid); err != nil {
 		return err
 	}
 	return nil
 }
 
+// authenticate checks whether the executor may connect. Executors of
+// remote engines must connect over TLS and are authenticated by the engine,
+// and other engines accept only local connections.
+func (em *executorManager) authenticate(conn ipc.Connection, t, uid string) error {
+	em.lock.Lock()
+	e, ok := em.engines[t]
+	em.lock.Unlock()
+
+	if ok {
+		if re, ok := e.engine.(RemoteEngine); ok {
+			if !ipc.IsTLS(conn) {
+				return InvalidUUIDError.Errorf("LocalForRemoteEngine(type=%s,uid=%s)", t, uid)
+			}
+			return re.Authenticate(ipc.PeerName(conn), uid)
+		}
+	}
+	if ipc.IsTLS(conn) {
+		return InvalidUUIDError.Errorf("RemoteForLocalEngine(type=%s,uid=%s)", t, uid)
+	}
+	return nil
+}
+
 func NewManager(net, addr string, l log.Logger, engines ...Engine) (Manager, error) {
 	srv := ipc.NewServer()
 	err := srv.Listen(net, addr)
@@ -297,17 +467,26 @@ func NewManager(net, addr string, l log.Logger, engines ...Engine) (Manager, err
 	srv.SetHandler(em)
 	em.server = srv
 	em.log = l.WithFields(log.Fields{log.FieldKeyModule: "EEP"})
+	em.health = DefaultHealthConfig
+	em.restarts = make(map[string]map[string]int)
+	em.stop = make(chan struct{})
 
 	for i := 0; i < len(em.executorStates); i++ {
 		em.executorStates[i].waiter = sync.NewCond(&em.lock)
 	}
 
 	em.engines = make(map[string]*engine)
+	em.locals = make(map[string]LocalEngine)
 	for _, e := range engines {
 		if err := e.Init(net, addr); err != nil {
 			return nil, err
 		}
-		em.engines[e.Type()] = &engine{engine: e}
+		if le, ok := e.(LocalEngine); ok {
+			em.locals[e.Type()] = le
+		} else {
+			em.engines[e.Type()] = &engine{engine: e}
+		}
 	}
+	go em.supervise(em.stop)
 	return em, nil
 }
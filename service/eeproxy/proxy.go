@@ -3,6 +3,7 @@ package eeproxy
 import (
 	"math/big"
 	"sync"
+	"time"
 
 	"github.com/gofrs/uuid"
 
@@ -37,6 +38,13 @@ const (
 	msgSETOBJGRAPH = 14
 	msgSETFEEPCT   = 15
 	msgCONTAINS    = 16
+	msgPING        = 17
+	msgPONG        = 18
+)
+
+const (
+	// versionHeartbeat is the version of executors handling msgPING.
+	versionHeartbeat = 2
 )
 
 type proxyState int
@@ -115,6 +123,17 @@ type proxy struct {
 
 	frame *callFrame
 
+	// priority of the executor reserving the proxy
+	priority RequestPriority
+
+	// lastSeen is the time of the last message from the executor, and
+	// pingAt is the time of the heartbeat waiting for the response.
+	lastSeen time.Time
+	pingAt   time.Time
+	// waitSince is the time since when the executor should send a message
+	// for the execution. It's zero if it waits for a message from us.
+	waitSince time.Time
+
 	next  *proxy
 	pprev **proxy
 }
@@ -277,6 +296,7 @@ func (p *proxy) Invoke(
 		prev: p.frame,
 	}
 	p.log = logger
+	p.waitSince = time.Now()
 	return p.conn.Send(msgINVOKE, &m)
 }
 
@@ -294,6 +314,7 @@ func (p *proxy) GetAPI(ctx CallContext, code string) error {
 		prev: p.frame,
 	}
 	p.log = logger
+	p.waitSince = time.Now()
 	return p.conn.Send(msgGETAPI, code)
 }
 
@@ -314,12 +335,13 @@ type resultMessage struct {
 	PrevEID  int
 }
 
-func (p *proxy) reserve() bool {
+func (p *proxy) reserve(pr RequestPriority) bool {
 	p.lock.Lock()
 	defer p.lock.Unlock()
 
 	if p.state == stateReady {
 		p.state = stateReserved
+		p.priority = pr
 		return true
 	}
 	return false
@@ -355,6 +377,10 @@ func (p *proxy) SendResult(ctx CallContext, status error, steps *big.Int, result
 	}
 	m.EID = eid
 	m.PrevEID = last
+
+	p.lock.Lock()
+	p.waitSince = time.Now()
+	p.lock.Unlock()
 	return p.conn.Send(msgRESULT, &m)
 }
 
@@ -392,8 +418,30 @@ func (p *proxy) tryToBeReadyInLock() bool {
 	return p.state == stateReady
 }
 
+func (p *proxy) onMessage(msg uint) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	now := time.Now()
+	p.lastSeen = now
+	switch msg {
+	case msgCALL, msgRESULT, msgGETAPI:
+		p.waitSince = time.Time{}
+	case msgPONG:
+		p.pingAt = time.Time{}
+	default:
+		if !p.waitSince.IsZero() {
+			p.waitSince = now
+		}
+	}
+}
+
 func (p *proxy) HandleMessage(c ipc.Connection, msg uint, data []byte) error {
+	p.onMessage(msg)
 	switch msg {
+	case msgPONG:
+		return nil
+
 	case msgRESULT:
 		var m resultMessage
 		if _, err := codec.MP.UnmarshalFromBytes(data, &m); err != nil {
@@ -628,7 +676,7 @@ func (p *proxy) OnClose() {
 
 	if p.frame != nil && p.state == stateReserved {
 		frame := p.frame
-		status := errors.ExecutionFailError.New("ProxyIsClosed")
+		status := errors.Wrap(ErrRestarted, "ProxyIsClosed")
 		l.CallAfterUnlock(func() {
 			frame.ctx.OnResult(status, 0, new(big.Int), nil)
 		})
@@ -643,11 +691,84 @@ func (p *proxy) Kill() error {
 	p.lock.Lock()
 	defer p.lock.Unlock()
 
+	if p.state >= stateStopped {
+		return nil
+	}
 	p.log.Warnf("Proxy[%p].Kill() type=%s uid=%s", p, p.scoreType, p.uid)
 	p.state = stateStopped
 	return p.mgr.kill(p.uid)
 }
 
+// checkHeartbeat sends a heartbeat to the idle proxy if it's required.
+// It returns false if the proxy doesn't respond to the heartbeat in time.
+func (p *proxy) checkHeartbeat(now time.Time, cfg *HealthConfig) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.version < versionHeartbeat || cfg.HeartbeatInterval == 0 || p.state != stateReady {
+		return true
+	}
+	if !p.pingAt.IsZero() {
+		return cfg.HeartbeatTimeout == 0 || now.Sub(p.pingAt) <= cfg.HeartbeatTimeout
+	}
+	if now.Sub(p.lastSeen) >= cfg.HeartbeatInterval {
+		if err := p.conn.Send(msgPING, nil); err != nil {
+			p.log.Warnf("Proxy[%p].checkHeartbeat fail to send err=%+v", p, err)
+			return false
+		}
+		p.pingAt = now
+	}
+	return true
+}
+
+// checkDeadline returns false if the executor doesn't send any message
+// for the query until the deadline. Executions of transactions are not
+// aborted, because it fails the whole block.
+func (p *proxy) checkDeadline(now time.Time, cfg *HealthConfig) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if cfg.ExecutionDeadline == 0 || p.priority != ForQuery ||
+		p.waitSince.IsZero() || p.state >= stateStopped {
+		return true
+	}
+	return now.Sub(p.waitSince) <= cfg.ExecutionDeadline
+}
+
+// abort stops the wedged proxy, and kills the executor to be restarted.
+// The current execution fails with ErrRestarted.
+func (p *proxy) abort(reason string) {
+	l := common.LockForAutoCall(&p.lock)
+	defer l.Unlock()
+
+	if p.state >= stateStopped {
+		return
+	}
+	p.log.Warnf("Proxy[%p].abort() type=%s uid=%s reason=%s", p, p.scoreType, p.uid, reason)
+	p.state = stateStopped
+	if p.frame != nil {
+		frame := p.frame
+		status := errors.Wrapf(ErrRestarted, "ExecutorRestarted(reason=%s)", reason)
+		l.CallAfterUnlock(func() {
+			frame.ctx.OnResult(status, 0, new(big.Int), nil)
+		})
+	}
+	if err := p.mgr.kill(p.uid); err != nil {
+		p.log.Warnf("Proxy[%p].abort() fail to kill err=%+v", p, err)
+		_ = p.conn.Close()
+	}
+}
+
+func (p *proxy) closeReason() string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.state == stateStopped {
+		return RestartByKill
+	}
+	return RestartByClose
+}
+
 func (p *proxy) detach() bool {
 	if p.pprev == nil {
 		return false
@@ -683,6 +804,7 @@ func newProxy(m proxyManager, c ipc.Connection, l log.Logger, t string, v uint16
 		version:   v,
 		uid:       uid,
 		state:     stateIdle,
+		lastSeen:  time.Now(),
 	}
 	c.SetHandler(msgRESULT, p)
 	c.SetHandler(msgGETVALUE, p)
@@ -698,6 +820,7 @@ func newProxy(m proxyManager, c ipc.Connection, l log.Logger, t string, v uint16
 	c.SetHandler(msgSETOBJGRAPH, p)
 	c.SetHandler(msgSETFEEPCT, p)
 	c.SetHandler(msgCONTAINS, p)
+	c.SetHandler(msgPONG, p)
 
 	if err := m.onReady(p); err != nil {
 		p.state = stateStopped
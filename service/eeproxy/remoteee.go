@@ -0,0 +1,348 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package eeproxy
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/ipc"
+	"github.com/icon-project/goloop/common/log"
+)
+
+const (
+	RemoteJavaEE = "remotejavaee"
+)
+
+// RemotePool is the pool of executors on a remote host. The executor
+// manager of the pool is identified by the common name of its certificate.
+type RemotePool struct {
+	Name   string
+	Weight int
+}
+
+// ParseRemotePools parses pools in the form of "name[:weight],...".
+// The weight is 1 if it's omitted.
+func ParseRemotePools(s string) ([]RemotePool, error) {
+	var pools []RemotePool
+	names := make(map[string]bool)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if len(item) == 0 {
+			continue
+		}
+		pool := RemotePool{Name: item, Weight: 1}
+		if idx := strings.LastIndex(item, ":"); idx >= 0 {
+			w, err := strconv.Atoi(item[idx+1:])
+			if err != nil || w < 1 {
+				return nil, errors.IllegalArgumentError.Errorf(
+					"InvalidWeight(pool=%s)", item)
+			}
+			pool.Name, pool.Weight = item[:idx], w
+		}
+		if len(pool.Name) == 0 || names[pool.Name] {
+			return nil, errors.IllegalArgumentError.Errorf(
+				"InvalidPoolName(pool=%s)", item)
+		}
+		names[pool.Name] = true
+		pools = append(pools, pool)
+	}
+	if len(pools) == 0 {
+		return nil, errors.IllegalArgumentError.Errorf("NoPools(%q)", s)
+	}
+	return pools, nil
+}
+
+type remotePool struct {
+	RemotePool
+	conn    ipc.Connection
+	manager ManagerProxy
+}
+
+type remoteInstance struct {
+	uid      string
+	pool     *remotePool
+	status   InstanceStatus
+	retiring bool
+}
+
+// remoteExecutionEngine runs executors of the Java EE in remote pools.
+// New executors are placed in the connected pool having the least
+// executors for its weight. Executors of the disconnected pool are placed
+// in other pools, and they move back to the pool on its reconnection
+// when they become idle.
+type remoteExecutionEngine struct {
+	lock      sync.Mutex
+	pools     []*remotePool
+	target    int
+	instances map[string]*remoteInstance
+
+	logger log.Logger
+}
+
+func (e *remoteExecutionEngine) Type() string {
+	return "java"
+}
+
+func (e *remoteExecutionEngine) Init(net, addr string) error {
+	return nil
+}
+
+func (e *remoteExecutionEngine) SetInstances(n int) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if n < 0 {
+		return errors.ErrIllegalArgument
+	}
+	e.target = n
+	return e.runInstancesInLock()
+}
+
+// quotasInLock returns the number of executors for each connected pool.
+// The target is divided by weights, and the remainder is given to pools
+// in the order of the configuration.
+func (e *remoteExecutionEngine) quotasInLock() map[*remotePool]int {
+	total := 0
+	for _, p := range e.pools {
+		if p.manager != nil {
+			total += p.Weight
+		}
+	}
+	quotas := make(map[*remotePool]int)
+	if total == 0 {
+		return quotas
+	}
+	type remainder struct {
+		pool  *remotePool
+		value int
+	}
+	var remainders []remainder
+	assigned := 0
+	for _, p := range e.pools {
+		if p.manager != nil {
+			quotas[p] = e.target * p.Weight / total
+			assigned += quotas[p]
+			remainders = append(remainders, remainder{p, e.target * p.Weight % total})
+		}
+	}
+	sort.SliceStable(remainders, func(i, j int) bool {
+		return remainders[i].value > remainders[j].value
+	})
+	for i := 0; assigned < e.target; i++ {
+		quotas[remainders[i].pool] += 1
+		assigned += 1
+	}
+	return quotas
+}
+
+func (e *remoteExecutionEngine) countsInLock() map[*remotePool]int {
+	counts := make(map[*remotePool]int)
+	for _, i := range e.instances {
+		if !i.retiring {
+			counts[i.pool] += 1
+		}
+	}
+	return counts
+}
+
+func (e *remoteExecutionEngine) runInstancesInLock() error {
+	quotas := e.quotasInLock()
+	counts := e.countsInLock()
+	for e.target > len(e.instances) {
+		var pool *remotePool
+		for _, p := range e.pools {
+			if p.manager == nil {
+				continue
+			}
+			if pool == nil || quotas[p]-counts[p] > quotas[pool]-counts[pool] {
+				pool = p
+			}
+		}
+		if pool == nil {
+			e.logger.Warnf("No connected pools to run executors (target=%d,instances=%d)",
+				e.target, len(e.instances))
+			return nil
+		}
+		uid := newUID()
+		e.logger.Debugf("runInstances with uid(%s) pool(%s)\n", uid, pool.Name)
+		if err := pool.manager.Run(uid); err != nil {
+			e.logger.Errorf("Fail to start execution engine pool=%s err=%+v", pool.Name, err)
+			return err
+		}
+		e.instances[uid] = &remoteInstance{
+			uid:    uid,
+			pool:   pool,
+			status: instanceStarted,
+		}
+		counts[pool] += 1
+	}
+	return nil
+}
+
+func (e *remoteExecutionEngine) OnAttach(uid string) bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if i, ok := e.instances[uid]; ok {
+		i.status = instanceOnline
+		return true
+	}
+	e.logger.Debugf("Invalid UID(%s)\n", uid)
+	return false
+}
+
+// Authenticate checks whether the executor is in the pool of the peer.
+func (e *remoteExecutionEngine) Authenticate(peer, uid string) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if peer == "" {
+		return InvalidUUIDError.Errorf("NoPeerName(uid=%s)", uid)
+	}
+	if i, ok := e.instances[uid]; !ok || i.pool.Name != peer {
+		return InvalidUUIDError.Errorf("NotInPool(uid=%s,peer=%s)", uid, peer)
+	}
+	return nil
+}
+
+// Retire returns true if the idle executor should be stopped to move it
+// to another pool having fewer executors than its quota.
+func (e *remoteExecutionEngine) Retire(uid string) bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	i, ok := e.instances[uid]
+	if !ok || i.retiring {
+		return false
+	}
+	quotas := e.quotasInLock()
+	counts := e.countsInLock()
+	if counts[i.pool] <= quotas[i.pool] {
+		return false
+	}
+	for _, p := range e.pools {
+		if p.manager != nil && counts[p] < quotas[p] {
+			e.logger.Infof("Retire uid(%s) pool(%s) to move to pool(%s)\n",
+				uid, i.pool.Name, p.Name)
+			i.retiring = true
+			return true
+		}
+	}
+	return false
+}
+
+func (e *remoteExecutionEngine) Kill(uid string) (bool, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	i, ok := e.instances[uid]
+	if !ok {
+		return false, nil
+	}
+	if i.pool.manager == nil {
+		return true, errors.InvalidStateError.Errorf(
+			"PoolDisconnected(pool=%s)", i.pool.Name)
+	}
+	return true, i.pool.manager.Kill(uid)
+}
+
+func (e *remoteExecutionEngine) OnConnect(conn ipc.Connection, version uint16) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	peer := ipc.PeerName(conn)
+	if peer == "" {
+		return errors.InvalidStateError.New("NoPeerName")
+	}
+	var pool *remotePool
+	for _, p := range e.pools {
+		if p.Name == peer {
+			pool = p
+			break
+		}
+	}
+	if pool == nil {
+		return errors.NotFoundError.Errorf("UnknownPool(peer=%q)", peer)
+	}
+	if pool.conn != nil {
+		return errors.InvalidStateError.Errorf("AlreadyConnected(pool=%s)", peer)
+	}
+	mp, err := newManagerProxy(version, conn, e, e.logger)
+	if err != nil {
+		return err
+	}
+	e.logger.Infof("Pool connected pool(%s) weight(%d)\n", pool.Name, pool.Weight)
+	pool.conn, pool.manager = conn, mp
+	return e.runInstancesInLock()
+}
+
+// OnEnd is called when executor is terminated
+func (e *remoteExecutionEngine) OnEnd(uid string) bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if i, ok := e.instances[uid]; ok {
+		e.logger.Infof("OnEnd uid(%s) pool(%s) status(%s)\n", uid, i.pool.Name, i.status)
+		delete(e.instances, uid)
+		return e.runInstancesInLock() == nil
+	}
+	e.logger.Debugf("Invalid UID(%s)\n", uid)
+	return false
+}
+
+// OnClose is called when the executor manager of the pool is disconnected.
+// Its executors are started in other pools until it connects again.
+func (e *remoteExecutionEngine) OnClose(conn ipc.Connection) bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	for _, p := range e.pools {
+		if p.conn != conn {
+			continue
+		}
+		e.logger.Warnf("Pool disconnected pool(%s)\n", p.Name)
+		p.conn, p.manager = nil, nil
+		for uid, i := range e.instances {
+			if i.pool == p {
+				delete(e.instances, uid)
+			}
+		}
+		if err := e.runInstancesInLock(); err != nil {
+			e.logger.Warnf("Fail to run executors err=%+v", err)
+		}
+		return true
+	}
+	return false
+}
+
+func NewRemoteJavaEE(logger log.Logger, pools []RemotePool) (Engine, error) {
+	if len(pools) == 0 {
+		return nil, errors.IllegalArgumentError.New("NoRemotePools")
+	}
+	e := &remoteExecutionEngine{
+		instances: make(map[string]*remoteInstance),
+		logger:    logger.WithFields(log.Fields{log.FieldKeyModule: RemoteJavaEE}),
+	}
+	for _, p := range pools {
+		e.pools = append(e.pools, &remotePool{RemotePool: p})
+	}
+	return e, nil
+}
@@ -0,0 +1,153 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package eeproxy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common/log"
+)
+
+type peerConnection struct {
+	testConnection
+	peer string
+}
+
+func (c *peerConnection) PeerName() string {
+	return c.peer
+}
+
+func (c *peerConnection) IsTLS() bool {
+	return true
+}
+
+func TestParseRemotePools(t *testing.T) {
+	pools, err := ParseRemotePools("pool1:3, pool2,pool3:1")
+	assert.NoError(t, err)
+	assert.Equal(t, []RemotePool{
+		{"pool1", 3}, {"pool2", 1}, {"pool3", 1},
+	}, pools)
+
+	for _, s := range []string{"", ",", "pool1:0", "pool1:x", ":1", "pool1,pool1:2"} {
+		_, err := ParseRemotePools(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func newTestRemoteEngine(t *testing.T, pools string) *remoteExecutionEngine {
+	ps, err := ParseRemotePools(pools)
+	assert.NoError(t, err)
+	e, err := NewRemoteJavaEE(log.GlobalLogger(), ps)
+	assert.NoError(t, err)
+	return e.(*remoteExecutionEngine)
+}
+
+func (e *remoteExecutionEngine) instancesOf(name string) []string {
+	var uids []string
+	for uid, i := range e.instances {
+		if i.pool.Name == name {
+			uids = append(uids, uid)
+		}
+	}
+	return uids
+}
+
+func TestRemoteEngine_Placement(t *testing.T) {
+	e := newTestRemoteEngine(t, "pool1:2,pool2:1")
+	assert.NoError(t, e.SetInstances(3))
+	assert.Empty(t, e.instances, "no connected pools")
+
+	c1 := &peerConnection{peer: "pool1"}
+	assert.NoError(t, e.OnConnect(c1, 1))
+	assert.Len(t, e.instancesOf("pool1"), 3)
+	assert.Len(t, c1.sent, 3)
+
+	assert.Error(t, e.OnConnect(&peerConnection{peer: "pool1"}, 1), "duplicate")
+	assert.Error(t, e.OnConnect(&peerConnection{peer: "pool9"}, 1), "unknown")
+	assert.Error(t, e.OnConnect(&testConnection{}, 1), "not over TLS")
+	assert.Error(t, e.OnConnect(&peerConnection{}, 1), "no common name")
+
+	// pool2 gets executors retired from pool1
+	c2 := &peerConnection{peer: "pool2"}
+	assert.NoError(t, e.OnConnect(c2, 1))
+	assert.Empty(t, e.instancesOf("pool2"))
+
+	var retired []string
+	for _, uid := range e.instancesOf("pool1") {
+		if e.Retire(uid) {
+			retired = append(retired, uid)
+		}
+	}
+	assert.Len(t, retired, 1)
+
+	killed, err := e.Kill(retired[0])
+	assert.True(t, killed)
+	assert.NoError(t, err)
+	assert.Equal(t, managerKILL, int(c1.sent[len(c1.sent)-1]))
+
+	assert.True(t, e.OnEnd(retired[0]))
+	assert.Len(t, e.instancesOf("pool1"), 2)
+	assert.Len(t, e.instancesOf("pool2"), 1)
+	for _, uid := range e.instancesOf("pool1") {
+		assert.False(t, e.Retire(uid))
+	}
+
+	// executors of the disconnected pool move to other pools
+	assert.True(t, e.OnClose(c1))
+	assert.Len(t, e.instancesOf("pool2"), 3)
+	assert.False(t, e.OnClose(c1))
+
+	// it gets executors again on reconnection
+	c1 = &peerConnection{peer: "pool1"}
+	assert.NoError(t, e.OnConnect(c1, 1))
+	cnt := 0
+	for _, uid := range e.instancesOf("pool2") {
+		if e.Retire(uid) {
+			cnt += 1
+		}
+	}
+	assert.Equal(t, 2, cnt)
+}
+
+func TestRemoteEngine_Authenticate(t *testing.T) {
+	e := newTestRemoteEngine(t, "pool1,pool2")
+	assert.NoError(t, e.OnConnect(&peerConnection{peer: "pool1"}, 1))
+	assert.NoError(t, e.SetInstances(1))
+	uids := e.instancesOf("pool1")
+	assert.Len(t, uids, 1)
+
+	assert.NoError(t, e.Authenticate("pool1", uids[0]))
+	assert.Error(t, e.Authenticate("pool2", uids[0]))
+	assert.Error(t, e.Authenticate("", uids[0]))
+	assert.Error(t, e.Authenticate("pool1", newUID()))
+
+	em, _ := newTestManager(t)
+	em.engines[e.Type()] = &engine{engine: e}
+	assert.NoError(t, em.authenticate(&peerConnection{peer: "pool1"}, "java", uids[0]))
+	assert.Error(t, em.authenticate(&testConnection{}, "java", uids[0]))
+	assert.Error(t, em.authenticate(&peerConnection{}, "java", uids[0]))
+
+	// local engines accept only local executors
+	assert.NoError(t, em.authenticate(&testConnection{}, "test", newUID()))
+	assert.Error(t, em.authenticate(&peerConnection{peer: "pool1"}, "test", newUID()))
+	// TLS connection without a common name isn't local
+	assert.Error(t, em.authenticate(&peerConnection{}, "test", newUID()))
+	assert.Error(t, em.onEEMConnect(&peerConnection{}, "test", 1))
+	assert.Error(t, em.onEEMConnect(&testConnection{}, "java", 1))
+}
@@ -0,0 +1,49 @@
+package eeproxy
+
+import "sync"
+
+// SharedManager is a Manager returning executors sharing one executor
+// reserved from the base manager for the priority. It's used to handle
+// multiple requests in a row without returning proxies to the pool for
+// each request. Release should be called after all requests are handled.
+type SharedManager struct {
+	Manager
+	priority RequestPriority
+
+	lock     sync.Mutex
+	executor *Executor
+}
+
+func (m *SharedManager) GetExecutor(pr RequestPriority) *Executor {
+	if pr != m.priority {
+		return m.Manager.GetExecutor(pr)
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.executor == nil || m.executor.isReleased() {
+		m.executor = m.Manager.GetExecutor(pr)
+		if m.executor == nil {
+			return nil
+		}
+	}
+	return m.executor.share()
+}
+
+// Release releases the executor reserved for the requests.
+func (m *SharedManager) Release() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.executor != nil {
+		m.executor.Release()
+		m.executor = nil
+	}
+}
+
+func NewSharedManager(m Manager, pr RequestPriority) *SharedManager {
+	return &SharedManager{
+		Manager:  m,
+		priority: pr,
+	}
+}
@@ -0,0 +1,71 @@
+package eeproxy
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func assignedOf(em *executorManager, pr RequestPriority) int {
+	em.lock.Lock()
+	defer em.lock.Unlock()
+	return em.executorStates[pr].assigned
+}
+
+func TestSharedManager_GetExecutor(t *testing.T) {
+	em, _ := newTestManager(t)
+	em.engines = nil
+	assert.NoError(t, em.SetInstances(10, 10, 10))
+
+	sm := NewSharedManager(em, ForQuery)
+	e1 := sm.GetExecutor(ForQuery)
+	e2 := sm.GetExecutor(ForQuery)
+	assert.Equal(t, e1.owner, e2.owner)
+	assert.Equal(t, 1, assignedOf(em, ForQuery))
+
+	// releasing a shared executor keeps the owner
+	e1.Release()
+	assert.False(t, e2.owner.isReleased())
+	assert.Equal(t, 1, assignedOf(em, ForQuery))
+
+	// killing a shared executor releases the owner, then a new one is used
+	e2.Kill()
+	assert.True(t, e2.owner.isReleased())
+	assert.Equal(t, 0, assignedOf(em, ForQuery))
+	e3 := sm.GetExecutor(ForQuery)
+	assert.NotEqual(t, e2.owner, e3.owner)
+	assert.Equal(t, 1, assignedOf(em, ForQuery))
+
+	// other priorities aren't shared
+	tx := sm.GetExecutor(ForTransaction)
+	assert.Nil(t, tx.owner)
+	tx.Release()
+
+	sm.Release()
+	assert.Equal(t, 0, assignedOf(em, ForQuery))
+	assert.Equal(t, 0, assignedOf(em, ForTransaction))
+}
+
+func TestExecutor_ReleaseConcurrently(t *testing.T) {
+	em, _ := newTestManager(t)
+	em.engines = nil
+	assert.NoError(t, em.SetInstances(10, 10, 10))
+
+	e := em.GetExecutor(ForQuery)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			e.Release()
+		}()
+		go func() {
+			defer wg.Done()
+			e.Kill()
+		}()
+	}
+	wg.Wait()
+	assert.True(t, e.isReleased())
+	assert.Equal(t, 0, assignedOf(em, ForQuery))
+}
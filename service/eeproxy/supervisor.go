@@ -0,0 +1,201 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package eeproxy
+
+import (
+	"time"
+
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/server/metric"
+)
+
+const (
+	DefaultHeartbeatInterval = 5 * time.Second
+	DefaultHeartbeatTimeout  = 10 * time.Second
+	DefaultExecutionDeadline = 60 * time.Second
+
+	superviseInterval = time.Second
+)
+
+// Reasons of restarts of executors.
+const (
+	RestartByHeartbeat = "heartbeat"
+	RestartByDeadline  = "deadline"
+	RestartByClose     = "closed"
+	RestartByKill      = "killed"
+)
+
+// ErrRestarted is the cause of the failure of the execution, which is
+// aborted by the restart of the executor. The execution may be retried
+// on another executor if it doesn't change the state. A transaction
+// failing with it fails the block.
+var ErrRestarted = errors.NewBase(errors.ExecutionFailError, "ExecutorRestarted")
+
+// HealthConfig is the configuration for supervising executors. Zero
+// value of the field disables the check.
+type HealthConfig struct {
+	// HeartbeatInterval is the interval of heartbeats to idle executors.
+	HeartbeatInterval time.Duration
+	// HeartbeatTimeout is the time to wait the response of the heartbeat.
+	HeartbeatTimeout time.Duration
+	// ExecutionDeadline is the time to wait a message from the executor
+	// while it's executing a query. It's independent of step limits.
+	// Executors for transactions are not restarted by the deadline.
+	ExecutionDeadline time.Duration
+}
+
+var DefaultHealthConfig = HealthConfig{
+	HeartbeatInterval: DefaultHeartbeatInterval,
+	HeartbeatTimeout:  DefaultHeartbeatTimeout,
+	ExecutionDeadline: DefaultExecutionDeadline,
+}
+
+type RestartRecord struct {
+	Type   string    `json:"type"`
+	UID    string    `json:"uid"`
+	Reason string    `json:"reason"`
+	Time   time.Time `json:"time"`
+}
+
+// Stats has statistics of restarts of executors.
+type Stats struct {
+	// Restarts has the number of restarts for the type of the engine and
+	// the reason.
+	Restarts    map[string]map[string]int `json:"restarts"`
+	LastRestart *RestartRecord            `json:"lastRestart,omitempty"`
+}
+
+func (em *executorManager) SetHealthConfig(cfg HealthConfig) error {
+	if cfg.HeartbeatInterval < 0 || cfg.HeartbeatTimeout < 0 || cfg.ExecutionDeadline < 0 {
+		return errors.IllegalArgumentError.Errorf("InvalidHealthConfig(%+v)", cfg)
+	}
+	em.lock.Lock()
+	defer em.lock.Unlock()
+
+	em.health = cfg
+	return nil
+}
+
+func (em *executorManager) Stats() *Stats {
+	em.lock.Lock()
+	defer em.lock.Unlock()
+
+	stats := &Stats{
+		Restarts: make(map[string]map[string]int, len(em.restarts)),
+	}
+	for t, rs := range em.restarts {
+		m := make(map[string]int, len(rs))
+		for reason, cnt := range rs {
+			m[reason] = cnt
+		}
+		stats.Restarts[t] = m
+	}
+	if em.lastRestart != nil {
+		r := *em.lastRestart
+		stats.LastRestart = &r
+	}
+	return stats
+}
+
+func (em *executorManager) recordRestartInLock(p *proxy, reason string) {
+	if em.stop == nil {
+		// connections are closed on closing the manager
+		return
+	}
+	em.log.Warnf("Restart proxy=%s-%s reason=%s", p.scoreType, p.uid, reason)
+	rs, ok := em.restarts[p.scoreType]
+	if !ok {
+		rs = make(map[string]int)
+		em.restarts[p.scoreType] = rs
+	}
+	rs[reason] += 1
+	em.lastRestart = &RestartRecord{
+		Type:   p.scoreType,
+		UID:    p.uid,
+		Reason: reason,
+		Time:   time.Now(),
+	}
+	metric.RecordEERestart(p.scoreType, reason)
+}
+
+func (em *executorManager) supervise(stop <-chan struct{}) {
+	ticker := time.NewTicker(superviseInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			em.checkHealth(now)
+		}
+	}
+}
+
+type wedgedProxy struct {
+	proxy  *proxy
+	reason string
+}
+
+// checkHealth sends heartbeats to idle proxies, and restarts proxies
+// missing heartbeats or deadlines of executions.
+func (em *executorManager) checkHealth(now time.Time) {
+	em.lock.Lock()
+	cfg := em.health
+	var wedged []wedgedProxy
+	for _, e := range em.engines {
+		var next *proxy
+		for p := e.ready; p != nil; p = next {
+			next = p.next
+			if !p.checkHeartbeat(now, &cfg) {
+				wedged = append(wedged, wedgedProxy{p, RestartByHeartbeat})
+				p.detach()
+				e.active -= 1
+			}
+		}
+		for p := e.using; p != nil; p = next {
+			next = p.next
+			if !p.checkDeadline(now, &cfg) {
+				wedged = append(wedged, wedgedProxy{p, RestartByDeadline})
+				p.detach()
+				e.active -= 1
+			}
+		}
+	}
+	for _, w := range wedged {
+		em.recordRestartInLock(w.proxy, w.reason)
+	}
+	em.lock.Unlock()
+
+	for _, w := range wedged {
+		w.proxy.abort(w.reason)
+	}
+}
+
+// Inspect returns statistics of executors of the manager for inspection.
+func Inspect(m Manager) map[string]interface{} {
+	stats := m.Stats()
+	if stats == nil {
+		return nil
+	}
+	res := map[string]interface{}{
+		"restarts": stats.Restarts,
+	}
+	if stats.LastRestart != nil {
+		res["lastRestart"] = stats.LastRestart
+	}
+	return res
+}
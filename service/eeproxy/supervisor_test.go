@@ -0,0 +1,189 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package eeproxy
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common/ipc"
+	"github.com/icon-project/goloop/common/log"
+)
+
+type testConnection struct {
+	sent   []uint
+	closed bool
+}
+
+func (c *testConnection) Send(msg uint, data interface{}) error {
+	c.sent = append(c.sent, msg)
+	return nil
+}
+
+func (c *testConnection) SendAndReceive(msg uint, data interface{}, buf interface{}) error {
+	return c.Send(msg, data)
+}
+
+func (c *testConnection) SetHandler(msg uint, handler ipc.MessageHandler) {
+}
+
+func (c *testConnection) HandleMessage() error {
+	return nil
+}
+
+func (c *testConnection) Close() error {
+	c.closed = true
+	return nil
+}
+
+type testEngine struct {
+	killed []string
+}
+
+func (e *testEngine) Type() string                                  { return "test" }
+func (e *testEngine) Init(net, addr string) error                   { return nil }
+func (e *testEngine) SetInstances(n int) error                      { return nil }
+func (e *testEngine) OnAttach(uid string) bool                      { return true }
+func (e *testEngine) OnEnd(uid string) bool                         { return true }
+func (e *testEngine) OnConnect(conn ipc.Connection, v uint16) error { return nil }
+func (e *testEngine) OnClose(conn ipc.Connection) bool              { return false }
+
+func (e *testEngine) Kill(uid string) (bool, error) {
+	e.killed = append(e.killed, uid)
+	return true, nil
+}
+
+func newTestManager(t *testing.T) (*executorManager, *testEngine) {
+	te := new(testEngine)
+	em := &executorManager{
+		log:           log.GlobalLogger(),
+		health:        DefaultHealthConfig,
+		restarts:      make(map[string]map[string]int),
+		stop:          make(chan struct{}),
+		executorLimit: 1,
+		engines: map[string]*engine{
+			te.Type(): {engine: te},
+		},
+	}
+	for i := range em.executorStates {
+		em.executorStates[i].waiter = sync.NewCond(&em.lock)
+	}
+	return em, te
+}
+
+func newTestProxy(t *testing.T, em *executorManager, v uint16, uid string) (*proxy, *testConnection) {
+	conn := new(testConnection)
+	p, err := newProxy(em, conn, em.log, "test", v, uid)
+	assert.NoError(t, err)
+	return p, conn
+}
+
+func TestSupervisor_Heartbeat(t *testing.T) {
+	em, te := newTestManager(t)
+	p1, c1 := newTestProxy(t, em, versionHeartbeat, newUID())
+	p2, c2 := newTestProxy(t, em, versionHeartbeat, newUID())
+	_, c3 := newTestProxy(t, em, 1, newUID())
+
+	now := time.Now().Add(DefaultHeartbeatInterval)
+	em.checkHealth(now)
+	assert.Equal(t, []uint{msgPING}, c1.sent)
+	assert.Equal(t, []uint{msgPING}, c2.sent)
+	assert.Empty(t, c3.sent, "heartbeat to old version")
+
+	// no more heartbeats until it gets the response
+	em.checkHealth(now.Add(time.Second))
+	assert.Len(t, c1.sent, 1)
+
+	assert.NoError(t, p1.HandleMessage(c1, msgPONG, nil))
+
+	em.checkHealth(now.Add(DefaultHeartbeatTimeout + time.Second))
+	assert.Equal(t, []string{p2.uid}, te.killed)
+	assert.Equal(t, stateStopped, p2.state)
+	assert.Equal(t, stateReady, p1.state)
+	assert.Equal(t, 2, em.engines["test"].active)
+
+	stats := em.Stats()
+	assert.Equal(t, 1, stats.Restarts["test"][RestartByHeartbeat])
+	if assert.NotNil(t, stats.LastRestart) {
+		assert.Equal(t, p2.uid, stats.LastRestart.UID)
+		assert.Equal(t, RestartByHeartbeat, stats.LastRestart.Reason)
+	}
+
+	// closing the connection of the killed proxy isn't recorded again
+	em.OnClose(c2)
+	assert.Equal(t, 1, em.Stats().Restarts["test"][RestartByHeartbeat])
+}
+
+func TestSupervisor_Deadline(t *testing.T) {
+	em, te := newTestManager(t)
+	p, _ := newTestProxy(t, em, versionHeartbeat, newUID())
+
+	e := em.engines["test"]
+	p.detach()
+	p.attachTo(&e.using)
+	assert.True(t, p.reserve(ForQuery))
+
+	start := time.Now()
+	p.waitSince = start
+	em.checkHealth(start.Add(DefaultExecutionDeadline))
+	assert.Empty(t, te.killed)
+
+	// a message from the executor extends the deadline
+	p.onMessage(msgLOG)
+	since := p.waitSince
+	assert.False(t, since.Before(start))
+	em.checkHealth(since.Add(DefaultExecutionDeadline))
+	assert.Empty(t, te.killed)
+
+	em.checkHealth(since.Add(DefaultExecutionDeadline + time.Second))
+	assert.Equal(t, []string{p.uid}, te.killed)
+	assert.Nil(t, e.using)
+	assert.Equal(t, 0, e.active)
+	assert.Equal(t, 1, em.Stats().Restarts["test"][RestartByDeadline])
+}
+
+func TestSupervisor_DeadlineForTransaction(t *testing.T) {
+	em, te := newTestManager(t)
+	p, _ := newTestProxy(t, em, versionHeartbeat, newUID())
+
+	e := em.engines["test"]
+	p.detach()
+	p.attachTo(&e.using)
+	assert.True(t, p.reserve(ForTransaction))
+
+	// executions of transactions are not aborted
+	p.waitSince = time.Now()
+	em.checkHealth(p.waitSince.Add(DefaultExecutionDeadline + time.Second))
+	assert.Empty(t, te.killed)
+	assert.Equal(t, stateReserved, p.state)
+	assert.Equal(t, 1, e.active)
+}
+
+func TestSupervisor_SetHealthConfig(t *testing.T) {
+	em, te := newTestManager(t)
+	_, c := newTestProxy(t, em, versionHeartbeat, newUID())
+
+	assert.Error(t, em.SetHealthConfig(HealthConfig{HeartbeatInterval: -1}))
+	assert.NoError(t, em.SetHealthConfig(HealthConfig{}))
+
+	em.checkHealth(time.Now().Add(time.Hour))
+	assert.Empty(t, c.sent)
+	assert.Empty(t, te.killed)
+}
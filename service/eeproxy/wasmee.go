@@ -0,0 +1,473 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package eeproxy
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"sync"
+
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/ipc"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/scoreapi"
+	"github.com/icon-project/goloop/service/scoreresult"
+	"github.com/icon-project/goloop/service/trace"
+	"github.com/icon-project/goloop/service/wasm"
+)
+
+const (
+	WasmEE = "wasmee"
+
+	// WasmCode is the name of the file for the code in the contract
+	// directory.
+	WasmCode = "code.wasm"
+	// WasmAPISection is the name of the custom section having APIs of the
+	// contract in JSON.
+	WasmAPISection = "icon.api"
+	// WasmInstallMethod is the name of the function for deployment.
+	WasmInstallMethod = "on_install"
+	// WasmUpdateMethod is the name of the function for update.
+	WasmUpdateMethod = "on_update"
+
+	wasmCacheSize = 64
+)
+
+const wasmFallbackMethod = "fallback"
+
+type wasmCode struct {
+	module  *wasm.Module
+	info    *scoreapi.Info
+	methods []*scoreapi.Method
+}
+
+// method returns the callable method. Fallback is registered with its
+// own name in the info, so it's checked separately.
+func (c *wasmCode) method(name string) *scoreapi.Method {
+	for _, m := range c.methods {
+		if m.Name == name && m.IsCallable() {
+			return m
+		}
+	}
+	return nil
+}
+
+type wasmExecutionEngine struct {
+	lock   sync.Mutex
+	codes  map[string]*wasmCode
+	logger log.Logger
+}
+
+func (e *wasmExecutionEngine) Type() string {
+	return "wasm"
+}
+
+func (e *wasmExecutionEngine) Init(net, addr string) error {
+	return nil
+}
+
+func (e *wasmExecutionEngine) SetInstances(n int) error {
+	return nil
+}
+
+func (e *wasmExecutionEngine) OnAttach(uid string) bool {
+	return false
+}
+
+func (e *wasmExecutionEngine) OnEnd(uid string) bool {
+	return false
+}
+
+func (e *wasmExecutionEngine) Kill(uid string) (bool, error) {
+	return false, nil
+}
+
+func (e *wasmExecutionEngine) OnConnect(conn ipc.Connection, version uint16) error {
+	return errors.InvalidStateError.New("NoConnectionForWasmEE")
+}
+
+func (e *wasmExecutionEngine) OnClose(conn ipc.Connection) bool {
+	return false
+}
+
+func (e *wasmExecutionEngine) NewProxy() Proxy {
+	return &wasmProxy{
+		engine: e,
+		calls:  make(map[CallContext]*wasmCall),
+	}
+}
+
+// load returns the code in the contract directory. Directories are named
+// after hash of the code, so parsed codes are cached by the path.
+func (e *wasmExecutionEngine) load(path string) (*wasmCode, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if c, ok := e.codes[path]; ok {
+		return c, nil
+	}
+	bs, err := ioutil.ReadFile(filepath.Join(path, WasmCode))
+	if err != nil {
+		return nil, errors.CriticalIOError.Wrapf(err, "FailToReadCode(path=%s)", path)
+	}
+	c, err := parseWasmCode(bs)
+	if err != nil {
+		return nil, err
+	}
+	if len(e.codes) >= wasmCacheSize {
+		e.codes = make(map[string]*wasmCode)
+	}
+	e.codes[path] = c
+	return c, nil
+}
+
+func NewWasmEE(logger log.Logger) (Engine, error) {
+	return &wasmExecutionEngine{
+		codes:  make(map[string]*wasmCode),
+		logger: logger.WithFields(log.Fields{log.FieldKeyModule: WasmEE}),
+	}, nil
+}
+
+type wasmParameterJSON struct {
+	Name    string          `json:"name"`
+	Type    string          `json:"type"`
+	Indexed string          `json:"indexed"`
+	Default json.RawMessage `json:"default"`
+}
+
+type wasmMethodJSON struct {
+	Type    string              `json:"type"`
+	Name    string              `json:"name"`
+	Inputs  []wasmParameterJSON `json:"inputs"`
+	Outputs []struct {
+		Type string `json:"type"`
+	} `json:"outputs"`
+	ReadOnly string `json:"readonly"`
+	Payable  string `json:"payable"`
+}
+
+func wasmDataTypeOf(s string) (scoreapi.DataType, error) {
+	t := scoreapi.DataTypeOf(s)
+	switch t {
+	case scoreapi.Integer, scoreapi.String, scoreapi.Bytes, scoreapi.Bool, scoreapi.Address:
+		return t, nil
+	default:
+		return scoreapi.Unknown, scoreresult.IllegalFormatError.Errorf(
+			"UnsupportedType(type=%s)", s)
+	}
+}
+
+// parseWasmAPI parses APIs in the same format with the result of
+// getScoreApi. Only primitive types are allowed, and default values of
+// optional parameters should be null.
+func parseWasmAPI(bs []byte) ([]*scoreapi.Method, error) {
+	var jso []wasmMethodJSON
+	if err := json.Unmarshal(bs, &jso); err != nil {
+		return nil, scoreresult.IllegalFormatError.Wrap(err, "InvalidAPIJSON")
+	}
+	methods := make([]*scoreapi.Method, 0, len(jso))
+	names := make(map[string]bool)
+	for _, mj := range jso {
+		if names[mj.Name] || len(mj.Name) == 0 {
+			return nil, scoreresult.IllegalFormatError.Errorf(
+				"InvalidMethodName(name=%s)", mj.Name)
+		}
+		names[mj.Name] = true
+		m := &scoreapi.Method{Name: mj.Name}
+		switch mj.Type {
+		case "function":
+			m.Type = scoreapi.Function
+			if mj.Name != WasmInstallMethod && mj.Name != WasmUpdateMethod {
+				m.Flags |= scoreapi.FlagExternal
+			}
+		case "fallback":
+			if mj.Name != wasmFallbackMethod || len(mj.Inputs) > 0 {
+				return nil, scoreresult.IllegalFormatError.New("InvalidFallback")
+			}
+			m.Type = scoreapi.Fallback
+		case "eventlog":
+			m.Type = scoreapi.Event
+		default:
+			return nil, scoreresult.IllegalFormatError.Errorf(
+				"InvalidMethodType(type=%s)", mj.Type)
+		}
+		if mj.ReadOnly == "0x1" {
+			m.Flags |= scoreapi.FlagReadOnly
+		}
+		if mj.Payable == "0x1" {
+			m.Flags |= scoreapi.FlagPayable
+		}
+		optional := false
+		for i, ij := range mj.Inputs {
+			t, err := wasmDataTypeOf(ij.Type)
+			if err != nil {
+				return nil, err
+			}
+			m.Inputs = append(m.Inputs, scoreapi.Parameter{Name: ij.Name, Type: t})
+			if m.Type == scoreapi.Event {
+				if ij.Indexed == "0x1" {
+					if i != m.Indexed {
+						return nil, scoreresult.IllegalFormatError.Errorf(
+							"InvalidIndexed(event=%s)", mj.Name)
+					}
+					m.Indexed += 1
+				}
+				continue
+			}
+			if ij.Default != nil {
+				if string(ij.Default) != "null" {
+					return nil, scoreresult.IllegalFormatError.Errorf(
+						"UnsupportedDefault(method=%s,param=%s)", mj.Name, ij.Name)
+				}
+				optional = true
+			} else if optional {
+				return nil, scoreresult.IllegalFormatError.Errorf(
+					"RequiredAfterOptional(method=%s,param=%s)", mj.Name, ij.Name)
+			} else {
+				m.Indexed += 1
+			}
+		}
+		if len(mj.Outputs) > 1 {
+			return nil, scoreresult.IllegalFormatError.Errorf(
+				"TooManyOutputs(method=%s)", mj.Name)
+		}
+		for _, oj := range mj.Outputs {
+			t, err := wasmDataTypeOf(oj.Type)
+			if err != nil {
+				return nil, err
+			}
+			m.Outputs = append(m.Outputs, t)
+		}
+		methods = append(methods, m)
+	}
+	return methods, nil
+}
+
+func parseWasmCode(bs []byte) (*wasmCode, error) {
+	m, err := wasm.Parse(bs)
+	if err != nil {
+		return nil, err
+	}
+	api, ok := m.Custom(WasmAPISection)
+	if !ok {
+		return nil, scoreresult.IllegalFormatError.New("NoAPISection")
+	}
+	methods, err := parseWasmAPI(api)
+	if err != nil {
+		return nil, err
+	}
+	return &wasmCode{
+		module:  m,
+		info:    scoreapi.NewInfo(methods),
+		methods: methods,
+	}, nil
+}
+
+// ValidateWasmCode checks the code for deployment. It should be a valid
+// module with the memory, importing only host functions, and exporting
+// functions for all callable methods in APIs including the install method.
+func ValidateWasmCode(bs []byte) error {
+	c, err := parseWasmCode(bs)
+	if err != nil {
+		return err
+	}
+	m := c.module
+	if e, ok := m.Export(wasmMemoryExport); !ok || e.Kind != wasm.ExternalMemory {
+		return scoreresult.IllegalFormatError.New("NoMemoryExport")
+	}
+	for _, imp := range m.Imports {
+		hf, ok := resolveWasmHost(imp.Module, imp.Name)
+		if !ok {
+			return scoreresult.IllegalFormatError.Errorf(
+				"UnknownImport(name=%s.%s)", imp.Module, imp.Name)
+		}
+		if !hf.Type.Equal(&m.Types[imp.Type]) {
+			return scoreresult.IllegalFormatError.Errorf(
+				"InvalidImportType(name=%s.%s)", imp.Module, imp.Name)
+		}
+	}
+	if method := c.method(WasmInstallMethod); method == nil || method.Type != scoreapi.Function {
+		return scoreresult.IllegalFormatError.New("NoInstallMethod")
+	}
+	for _, method := range c.methods {
+		if !method.IsCallable() {
+			continue
+		}
+		e, ok := m.Export(method.Name)
+		if !ok || e.Kind != wasm.ExternalFunction {
+			return scoreresult.IllegalFormatError.Errorf(
+				"NoFunctionForMethod(name=%s)", method.Name)
+		}
+		if ft := m.TypeOfFunction(e.Index); len(ft.Params) > 0 || len(ft.Results) > 0 {
+			return scoreresult.IllegalFormatError.Errorf(
+				"InvalidFunctionType(name=%s)", method.Name)
+		}
+	}
+	return nil
+}
+
+type wasmResult struct {
+	status error
+	steps  *big.Int
+	result *codec.TypedObj
+}
+
+type wasmProxy struct {
+	engine *wasmExecutionEngine
+
+	lock   sync.Mutex
+	calls  map[CallContext]*wasmCall
+	killed bool
+}
+
+func (p *wasmProxy) Invoke(
+	ctx CallContext, code string, readOnly bool,
+	from, to module.Address, value, limit *big.Int, method string, params *codec.TypedObj,
+	cid []byte, eid int, state *CodeState,
+) error {
+	logger := trace.LoggerOf(ctx.Logger())
+	logger.Tracef("WasmProxy[%p].Invoke(code=%s,method=%s,limit=%s)", p, code, method, limit)
+
+	c, err := p.engine.load(code)
+	if err != nil {
+		return err
+	}
+	m := c.method(method)
+	if m == nil {
+		return scoreresult.MethodNotFoundError.Errorf("MethodNotFound(name=%s)", method)
+	}
+	if !limit.IsInt64() {
+		limit = big.NewInt(1<<63 - 1)
+	}
+	call := &wasmCall{
+		proxy:    p,
+		ctx:      ctx,
+		log:      logger,
+		readOnly: readOnly,
+		from:     from,
+		to:       to,
+		value:    value,
+		method:   m,
+		results:  make(chan *wasmResult, 1),
+	}
+	if params != nil && params.Type == codec.TypeList {
+		call.params = params.Object.([]*codec.TypedObj)
+	}
+	in, err := wasm.NewInstance(c.module, call.resolve, limit.Int64())
+	if err != nil {
+		if scoreresult.OutOfStepError.Equals(err) {
+			// the initial memory takes all steps
+			go ctx.OnResult(err, 0, limit, nil)
+			return nil
+		}
+		return err
+	}
+	call.in = in
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.killed {
+		return scoreresult.ErrTimeout
+	}
+	p.calls[ctx] = call
+	go p.run(call, method, limit.Int64())
+	return nil
+}
+
+// call calls the method of the instance. A panic in the execution is
+// returned as UnknownFailure instead of stopping the node.
+func (p *wasmProxy) call(c *wasmCall, method string) (status error) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.log.Warnf("WasmProxy[%p].Invoke panic=%+v", p, err)
+			status = scoreresult.UnknownFailureError.Errorf("Recover obj=%+v", err)
+		}
+	}()
+	_, status = c.in.Call(method)
+	return status
+}
+
+func (p *wasmProxy) run(c *wasmCall, method string, limit int64) {
+	status := p.call(c, method)
+	result := c.result
+	if status != nil {
+		c.log.Tracef("WasmProxy[%p].Invoke done status=%v", p, status)
+		result = nil
+	}
+	steps := big.NewInt(limit - c.in.StepsLeft())
+
+	p.lock.Lock()
+	delete(p.calls, c.ctx)
+	killed := p.killed
+	p.lock.Unlock()
+
+	if !killed {
+		c.ctx.OnResult(status, 0, steps, result)
+	}
+}
+
+func (p *wasmProxy) SendResult(ctx CallContext, status error, steps *big.Int, result *codec.TypedObj, eid int, last int) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	c, ok := p.calls[ctx]
+	if !ok {
+		return errors.InvalidStateError.New("NoCallForResult")
+	}
+	select {
+	case c.results <- &wasmResult{status, steps, result}:
+		return nil
+	default:
+		return errors.InvalidStateError.New("UnexpectedResult")
+	}
+}
+
+func (p *wasmProxy) GetAPI(ctx CallContext, code string) error {
+	c, err := p.engine.load(code)
+	go func() {
+		if err != nil {
+			ctx.OnAPI(err, nil)
+		} else {
+			ctx.OnAPI(nil, c.info)
+		}
+	}()
+	return nil
+}
+
+func (p *wasmProxy) Release() {
+	// nothing to release, calls are removed on the end of executions
+}
+
+func (p *wasmProxy) Kill() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.engine.logger.Warnf("WasmProxy[%p].Kill() calls=%d", p, len(p.calls))
+	p.killed = true
+	for _, c := range p.calls {
+		c.in.Abort()
+		select {
+		case c.results <- &wasmResult{status: scoreresult.ErrTimeout}:
+		default:
+		}
+	}
+	return nil
+}
@@ -0,0 +1,284 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package eeproxy_test
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/service/eeproxy"
+	"github.com/icon-project/goloop/service/eeproxy/eetest"
+	"github.com/icon-project/goloop/service/wasm"
+)
+
+const fixtureAPI = `[
+	{"type":"function","name":"on_install","inputs":[],"outputs":[]},
+	{"type":"function","name":"setValue","inputs":[{"name":"v","type":"bytes"}],"outputs":[]},
+	{"type":"function","name":"getValue","inputs":[],"outputs":[{"type":"bytes"}],"readonly":"0x1"},
+	{"type":"function","name":"emit","inputs":[{"name":"v","type":"bytes"}],"outputs":[]},
+	{"type":"function","name":"fail","inputs":[{"name":"code","type":"int"}],"outputs":[]},
+	{"type":"function","name":"loop","inputs":[],"outputs":[]},
+	{"type":"function","name":"callGet","inputs":[{"name":"addr","type":"Address"}],"outputs":[{"type":"bytes"}],"readonly":"0x1"},
+	{"type":"eventlog","name":"Emitted","inputs":[{"name":"v","type":"bytes","indexed":"0x1"}]}
+]`
+
+// indexes of imported host functions in the fixture
+const (
+	hGetValue uint32 = iota
+	hSetValue
+	hBufferCopy
+	hParam
+	hSetResult
+	hEvent
+	hRevert
+	hCall
+)
+
+// layout of the memory of the fixture
+const (
+	memKey    = 0    // "v"
+	memMsg    = 64   // "failed"
+	memMethod = 80   // "getValue"
+	memTemp   = 256  // small parameters
+	memAddr   = 512  // address parameter
+	memBuffer = 1024 // values
+	memEvent  = 2048 // items of the event
+)
+
+func fixtureModule() *wasm.Module {
+	i32 := wasm.I32
+	sig := "Emitted(bytes)"
+	sigItem := append([]byte{byte(len(sig)), 0, 0, 0}, sig...)
+	valueItem := uint32(memEvent + len(sigItem))
+	exported := uint32(7)
+	return &wasm.Module{
+		Types: []wasm.FuncType{
+			{Params: []wasm.ValueType{i32, i32}, Results: []wasm.ValueType{i32}},
+			{Params: []wasm.ValueType{i32, i32, i32, i32}},
+			{Params: []wasm.ValueType{i32}},
+			{Params: []wasm.ValueType{i32}, Results: []wasm.ValueType{i32}},
+			{Params: []wasm.ValueType{i32, i32}},
+			{Params: []wasm.ValueType{i32, i32, i32}},
+			{Params: []wasm.ValueType{i32, i32, i32, i32, i32, i32, i32}, Results: []wasm.ValueType{i32}},
+			{},
+		},
+		Imports: []wasm.Import{
+			{Module: eeproxy.WasmHostModule, Name: "get_value", Type: 0},
+			{Module: eeproxy.WasmHostModule, Name: "set_value", Type: 1},
+			{Module: eeproxy.WasmHostModule, Name: "buffer_copy", Type: 2},
+			{Module: eeproxy.WasmHostModule, Name: "param", Type: 3},
+			{Module: eeproxy.WasmHostModule, Name: "set_result", Type: 4},
+			{Module: eeproxy.WasmHostModule, Name: "event", Type: 5},
+			{Module: eeproxy.WasmHostModule, Name: "revert", Type: 5},
+			{Module: eeproxy.WasmHostModule, Name: "call", Type: 6},
+		},
+		Functions: []wasm.Function{
+			// on_install
+			{Type: exported, Code: new(wasm.Code).End().Bytes()},
+			// setValue
+			{Type: exported, Locals: []wasm.ValueType{i32}, Code: new(wasm.Code).
+				I32Const(0).Call(hParam).LocalSet(0).
+				I32Const(memBuffer).Call(hBufferCopy).
+				I32Const(memKey).I32Const(1).I32Const(memBuffer).LocalGet(0).Call(hSetValue).
+				End().Bytes()},
+			// getValue
+			{Type: exported, Locals: []wasm.ValueType{i32}, Code: new(wasm.Code).
+				I32Const(memKey).I32Const(1).Call(hGetValue).LocalTee(0).
+				I32Const(-1).I32Eq().If().Return().End().
+				I32Const(memBuffer).Call(hBufferCopy).
+				I32Const(memBuffer).LocalGet(0).Call(hSetResult).
+				End().Bytes()},
+			// emit
+			{Type: exported, Locals: []wasm.ValueType{i32}, Code: new(wasm.Code).
+				I32Const(0).Call(hParam).LocalSet(0).
+				I32Const(int32(valueItem)).LocalGet(0).I32Store(0).
+				I32Const(int32(valueItem + 4)).Call(hBufferCopy).
+				I32Const(memEvent).
+				LocalGet(0).I32Const(int32(len(sigItem) + 4)).I32Add().
+				I32Const(2).Call(hEvent).
+				End().Bytes()},
+			// fail
+			{Type: exported, Code: new(wasm.Code).
+				I32Const(0).Call(hParam).Drop().
+				I32Const(memTemp).Call(hBufferCopy).
+				I32Const(memTemp).I32Load8U(0).
+				I32Const(memMsg).I32Const(6).Call(hRevert).
+				End().Bytes()},
+			// loop
+			{Type: exported, Code: new(wasm.Code).
+				Loop().Br(0).End().
+				End().Bytes()},
+			// callGet
+			{Type: exported, Locals: []wasm.ValueType{i32}, Code: new(wasm.Code).
+				I32Const(0).Call(hParam).Drop().
+				I32Const(memAddr).Call(hBufferCopy).
+				I32Const(memAddr).I32Const(0).I32Const(0).
+				I32Const(memMethod).I32Const(8).
+				I32Const(0).I32Const(0).
+				Call(hCall).LocalTee(0).
+				I32Const(-1).I32Eq().If().Return().End().
+				I32Const(memBuffer).Call(hBufferCopy).
+				I32Const(memBuffer).LocalGet(0).Call(hSetResult).
+				End().Bytes()},
+		},
+		Memory: &wasm.Limits{Min: 1},
+		Exports: []wasm.Export{
+			{Name: "memory", Kind: wasm.ExternalMemory},
+			{Name: "on_install", Kind: wasm.ExternalFunction, Index: 8},
+			{Name: "setValue", Kind: wasm.ExternalFunction, Index: 9},
+			{Name: "getValue", Kind: wasm.ExternalFunction, Index: 10},
+			{Name: "emit", Kind: wasm.ExternalFunction, Index: 11},
+			{Name: "fail", Kind: wasm.ExternalFunction, Index: 12},
+			{Name: "loop", Kind: wasm.ExternalFunction, Index: 13},
+			{Name: "callGet", Kind: wasm.ExternalFunction, Index: 14},
+		},
+		Data: []wasm.Data{
+			{Offset: memKey, Init: []byte("v")},
+			{Offset: memMsg, Init: []byte("failed")},
+			{Offset: memMethod, Init: []byte("getValue")},
+			{Offset: memEvent, Init: sigItem},
+		},
+		Customs: []wasm.Custom{
+			{Name: eeproxy.WasmAPISection, Data: []byte(fixtureAPI)},
+		},
+	}
+}
+
+type wasmTarget struct {
+	code []byte
+}
+
+func (w *wasmTarget) Proxy(t *testing.T) eeproxy.Proxy {
+	ee, err := eeproxy.NewWasmEE(log.GlobalLogger())
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	p := ee.(eeproxy.LocalEngine).NewProxy()
+	t.Cleanup(p.Release)
+	return p
+}
+
+func (w *wasmTarget) Code(t *testing.T) string {
+	dir := t.TempDir()
+	err := os.WriteFile(path.Join(dir, eeproxy.WasmCode), w.code, 0644)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return dir
+}
+
+func TestWasmEE_Conformance(t *testing.T) {
+	code := fixtureModule().Encode()
+	assert.NoError(t, eeproxy.ValidateWasmCode(code))
+	eetest.Run(t, &wasmTarget{code: code})
+}
+
+func TestValidateWasmCode(t *testing.T) {
+	cases := map[string]func(m *wasm.Module){
+		"NoAPISection": func(m *wasm.Module) {
+			m.Customs = nil
+		},
+		"InvalidAPI": func(m *wasm.Module) {
+			m.Customs[0].Data = []byte(`[{"type":"function","name":"f","inputs":[{"name":"a","type":"[]int"}]}]`)
+		},
+		"NoMemoryExport": func(m *wasm.Module) {
+			m.Exports = m.Exports[1:]
+		},
+		"UnknownImport": func(m *wasm.Module) {
+			m.Imports[0].Name = "unknown"
+		},
+		"InvalidImportType": func(m *wasm.Module) {
+			m.Imports[0].Type = 1
+		},
+		"NoFunctionForMethod": func(m *wasm.Module) {
+			m.Exports = m.Exports[:len(m.Exports)-1]
+		},
+		"NoInstallMethod": func(m *wasm.Module) {
+			m.Customs[0].Data = []byte(`[]`)
+		},
+	}
+	for name, modify := range cases {
+		t.Run(name, func(t *testing.T) {
+			m := fixtureModule()
+			modify(m)
+			assert.Error(t, eeproxy.ValidateWasmCode(m.Encode()))
+		})
+	}
+}
+
+// javaTarget runs the suite with the Java EE. JAVAEE_BIN should be the
+// path of the script running the Java EE, and JAVAEE_TEST_CONTRACT should
+// be the path of the directory having the fixture contract for it, which
+// is built by the task app:eetest:optimizedJar of javaee.
+type javaTarget struct {
+	code []byte
+}
+
+func (j *javaTarget) Proxy(t *testing.T) eeproxy.Proxy {
+	logger := log.GlobalLogger()
+	ee, err := eeproxy.NewJavaEE(logger)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	mgr, err := eeproxy.NewManager("unix", path.Join(t.TempDir(), "ee.socket"), logger, ee)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	go mgr.Loop()
+	if err := mgr.SetInstances(1, 1, 1); !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	ex := mgr.GetExecutor(eeproxy.ForTransaction)
+	t.Cleanup(func() {
+		ex.Release()
+		mgr.Close()
+	})
+	return ex.Get(ee.Type())
+}
+
+// Code returns a copy of the fixture, because the Java EE writes the
+// transformed code in the directory on the installation.
+func (j *javaTarget) Code(t *testing.T) string {
+	dir := t.TempDir()
+	err := os.WriteFile(path.Join(dir, javaCode), j.code, 0644)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return dir
+}
+
+func (j *javaTarget) InstallMethod() string {
+	return "<init>"
+}
+
+const javaCode = "code.jar"
+
+func TestJavaEE_Conformance(t *testing.T) {
+	dir, ok := os.LookupEnv("JAVAEE_TEST_CONTRACT")
+	if _, hasBin := os.LookupEnv("JAVAEE_BIN"); !ok || !hasBin {
+		t.Skip("JAVAEE_BIN or JAVAEE_TEST_CONTRACT isn't set")
+	}
+	code, err := os.ReadFile(path.Join(dir, javaCode))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	eetest.Run(t, &javaTarget{code: code})
+}
@@ -0,0 +1,551 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package eeproxy
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/scoreapi"
+	"github.com/icon-project/goloop/service/scoreresult"
+	"github.com/icon-project/goloop/service/trace"
+	"github.com/icon-project/goloop/service/wasm"
+)
+
+const (
+	// WasmHostModule is the name of the module for host functions.
+	WasmHostModule = "icon"
+
+	wasmMemoryExport = "memory"
+
+	// wasmBytesPerStep is the number of bytes copied between the memory
+	// and the host for a step.
+	wasmBytesPerStep = 32
+)
+
+// Kinds of addresses for address().
+const (
+	WasmAddressSelf = iota
+	WasmAddressCaller
+	WasmAddressOwner
+	WasmAddressOrigin
+)
+
+// Keys of the information from CallContext.GetInfo().
+const (
+	wasmInfoBlockHeight    = "B.height"
+	wasmInfoBlockTimestamp = "B.timestamp"
+	wasmInfoTxFrom         = "T.from"
+	wasmInfoOwner          = "C.owner"
+	wasmInfoStepCosts      = "StepCosts"
+)
+
+type wasmStepCosts struct {
+	get, set, delete, log                 int64
+	getBase, setBase, deleteBase, logBase int64
+}
+
+// wasmCall is the context of an invocation for host functions.
+type wasmCall struct {
+	proxy *wasmProxy
+	ctx   CallContext
+	log   *trace.Logger
+	in    *wasm.Instance
+
+	readOnly bool
+	from, to module.Address
+	value    *big.Int
+	method   *scoreapi.Method
+	params   []*codec.TypedObj
+
+	info   map[string]interface{}
+	costs  *wasmStepCosts
+	buffer []byte
+	result *codec.TypedObj
+
+	results chan *wasmResult
+}
+
+func (c *wasmCall) getInfo() map[string]interface{} {
+	if c.info == nil {
+		c.info = make(map[string]interface{})
+		if obj, err := common.DecodeAny(c.ctx.GetInfo()); err == nil {
+			if m, ok := obj.(map[string]interface{}); ok {
+				c.info = m
+			}
+		}
+	}
+	return c.info
+}
+
+func (c *wasmCall) infoInt64(key string) int64 {
+	if v, ok := c.getInfo()[key].(*common.HexInt); ok {
+		return v.Int64()
+	}
+	return 0
+}
+
+func (c *wasmCall) stepCosts() *wasmStepCosts {
+	if c.costs == nil {
+		m, _ := c.getInfo()[wasmInfoStepCosts].(map[string]interface{})
+		cost := func(name string) int64 {
+			if v, ok := m[name].(*common.HexInt); ok {
+				return v.Int64()
+			}
+			return 0
+		}
+		c.costs = &wasmStepCosts{
+			get:        cost("get"),
+			set:        cost("set"),
+			delete:     cost("delete"),
+			log:        cost("log"),
+			getBase:    cost("getBase"),
+			setBase:    cost("setBase"),
+			deleteBase: cost("deleteBase"),
+			logBase:    cost("logBase"),
+		}
+	}
+	return c.costs
+}
+
+// charge consumes steps for storage and events. Costs can be negative for
+// deletion, but they are not refunded.
+func (c *wasmCall) charge(steps int64) error {
+	if steps < 0 {
+		steps = 0
+	}
+	return c.in.UseSteps(steps)
+}
+
+func (c *wasmCall) read(ptr, size uint64) ([]byte, error) {
+	if err := c.in.UseSteps(int64(size / wasmBytesPerStep)); err != nil {
+		return nil, err
+	}
+	return c.in.Read(uint32(ptr), uint32(size))
+}
+
+func (c *wasmCall) readString(ptr, size uint64) (string, error) {
+	bs, err := c.read(ptr, size)
+	return string(bs), err
+}
+
+// setBuffer stores the bytes for buffer_copy, and returns the length of
+// bytes or -1 for nil.
+func (c *wasmCall) setBuffer(bs []byte) []uint64 {
+	c.buffer = bs
+	if bs == nil {
+		return []uint64{uint64(0xffffffff)}
+	}
+	return []uint64{uint64(len(bs))}
+}
+
+func (c *wasmCall) checkWritable() error {
+	if c.readOnly {
+		return scoreresult.AccessDeniedError.New("WriteInReadOnly")
+	}
+	return nil
+}
+
+func wasmBytesOf(obj *codec.TypedObj) ([]byte, error) {
+	switch obj.Type {
+	case codec.TypeNil:
+		return nil, nil
+	case codec.TypeString:
+		return []byte(obj.Object.(string)), nil
+	case codec.TypeBytes, codec.TypeBool, common.TypeInt, common.TypeAddress:
+		return obj.Object.([]byte), nil
+	default:
+		return nil, scoreresult.UnknownFailureError.Errorf(
+			"UnsupportedValueType(type=%d)", obj.Type)
+	}
+}
+
+// wasmParamsOf decodes parameters for call(). Each item has the type in a
+// byte, the length in four bytes (little endian) and bytes of the value.
+// The type is zero for null, or the tag of scoreapi.TypeTag for primitive
+// types.
+func wasmParamsOf(bs []byte) ([]*codec.TypedObj, error) {
+	params := make([]*codec.TypedObj, 0)
+	for len(bs) > 0 {
+		if len(bs) < 5 {
+			return nil, scoreresult.InvalidParameterError.New("InvalidParams")
+		}
+		tag := scoreapi.TypeTag(bs[0])
+		size := binary.LittleEndian.Uint32(bs[1:5])
+		bs = bs[5:]
+		if uint64(size) > uint64(len(bs)) {
+			return nil, scoreresult.InvalidParameterError.New("InvalidParams")
+		}
+		value := bs[:size]
+		bs = bs[size:]
+		if tag == scoreapi.TUnknown {
+			params = append(params, codec.Nil)
+			continue
+		}
+		if tag > scoreapi.TAddress {
+			return nil, scoreresult.InvalidParameterError.Errorf(
+				"InvalidParamType(type=%d)", tag)
+		}
+		obj, err := scoreapi.DataType(tag).ConvertBytesToTypedObj(value)
+		if err != nil {
+			return nil, scoreresult.InvalidParameterError.Wrap(err, "InvalidParamValue")
+		}
+		params = append(params, obj)
+	}
+	return params, nil
+}
+
+func (c *wasmCall) getValue(args []uint64) ([]uint64, error) {
+	key, err := c.read(args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	value, err := c.ctx.GetValue(key)
+	if err != nil {
+		return nil, err
+	}
+	sc := c.stepCosts()
+	if err := c.charge(sc.getBase + sc.get*int64(len(value))); err != nil {
+		return nil, err
+	}
+	return c.setBuffer(value), nil
+}
+
+func (c *wasmCall) setValue(args []uint64) ([]uint64, error) {
+	if err := c.checkWritable(); err != nil {
+		return nil, err
+	}
+	key, err := c.read(args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	value, err := c.read(args[2], args[3])
+	if err != nil {
+		return nil, err
+	}
+	old, err := c.ctx.SetValue(key, value)
+	if err != nil {
+		return nil, err
+	}
+	sc := c.stepCosts()
+	var steps int64
+	if old == nil {
+		steps = sc.setBase + sc.set*int64(len(value))
+	} else {
+		steps = (sc.setBase+sc.deleteBase)/2 +
+			sc.delete*int64(len(old)) + sc.set*int64(len(value))
+	}
+	return nil, c.charge(steps)
+}
+
+func (c *wasmCall) deleteValue(args []uint64) ([]uint64, error) {
+	if err := c.checkWritable(); err != nil {
+		return nil, err
+	}
+	key, err := c.read(args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	old, err := c.ctx.DeleteValue(key)
+	if err != nil {
+		return nil, err
+	}
+	sc := c.stepCosts()
+	return nil, c.charge(sc.deleteBase + sc.delete*int64(len(old)))
+}
+
+func (c *wasmCall) bufferCopy(args []uint64) ([]uint64, error) {
+	if err := c.in.UseSteps(int64(len(c.buffer) / wasmBytesPerStep)); err != nil {
+		return nil, err
+	}
+	return nil, c.in.Write(uint32(args[0]), c.buffer)
+}
+
+func (c *wasmCall) param(args []uint64) ([]uint64, error) {
+	idx := args[0]
+	if idx >= uint64(len(c.params)) {
+		return c.setBuffer(nil), nil
+	}
+	bs, err := wasmBytesOf(c.params[idx])
+	if err != nil {
+		return nil, err
+	}
+	return c.setBuffer(bs), nil
+}
+
+func (c *wasmCall) setResult(args []uint64) ([]uint64, error) {
+	if len(c.method.Outputs) == 0 {
+		return nil, scoreresult.UnknownFailureError.Errorf(
+			"NoOutputForMethod(name=%s)", c.method.Name)
+	}
+	bs, err := c.read(args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	result, err := c.method.Outputs[0].ConvertBytesToTypedObj(bs)
+	if err != nil {
+		return nil, scoreresult.UnknownFailureError.Wrap(err, "InvalidResult")
+	}
+	c.result = result
+	return nil, nil
+}
+
+func (c *wasmCall) address(args []uint64) ([]uint64, error) {
+	var addr module.Address
+	switch args[0] {
+	case WasmAddressSelf:
+		addr = c.to
+	case WasmAddressCaller:
+		addr = c.from
+	case WasmAddressOwner:
+		addr, _ = c.getInfo()[wasmInfoOwner].(module.Address)
+	case WasmAddressOrigin:
+		addr, _ = c.getInfo()[wasmInfoTxFrom].(module.Address)
+	default:
+		return nil, scoreresult.InvalidParameterError.Errorf(
+			"InvalidAddressKind(kind=%d)", args[0])
+	}
+	if addr == nil {
+		return c.setBuffer(nil), nil
+	}
+	return c.setBuffer(addr.Bytes()), nil
+}
+
+func (c *wasmCall) getValueOfCall(args []uint64) ([]uint64, error) {
+	if c.value == nil {
+		return c.setBuffer(intconv.BigIntToBytes(new(big.Int))), nil
+	}
+	return c.setBuffer(intconv.BigIntToBytes(c.value)), nil
+}
+
+func (c *wasmCall) balance(args []uint64) ([]uint64, error) {
+	bs, err := c.read(args[0], common.AddressBytes)
+	if err != nil {
+		return nil, err
+	}
+	addr, err := common.NewAddress(bs)
+	if err != nil {
+		return nil, scoreresult.InvalidParameterError.Wrap(err, "InvalidAddress")
+	}
+	return c.setBuffer(intconv.BigIntToBytes(c.ctx.GetBalance(addr))), nil
+}
+
+func (c *wasmCall) blockHeight(args []uint64) ([]uint64, error) {
+	return []uint64{uint64(c.infoInt64(wasmInfoBlockHeight))}, nil
+}
+
+func (c *wasmCall) blockTimestamp(args []uint64) ([]uint64, error) {
+	return []uint64{uint64(c.infoInt64(wasmInfoBlockTimestamp))}, nil
+}
+
+// event emits an event. Items are encoded with the length in four bytes
+// (little endian) and bytes of the item. The first item is the signature.
+func (c *wasmCall) event(args []uint64) ([]uint64, error) {
+	if err := c.checkWritable(); err != nil {
+		return nil, err
+	}
+	bs, err := c.read(args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	nIndexed := int(uint32(args[2]))
+	var items [][]byte
+	size := 0
+	for len(bs) > 0 {
+		if len(bs) < 4 {
+			return nil, scoreresult.InvalidParameterError.New("InvalidEventItems")
+		}
+		l := binary.LittleEndian.Uint32(bs)
+		bs = bs[4:]
+		if uint64(l) > uint64(len(bs)) {
+			return nil, scoreresult.InvalidParameterError.New("InvalidEventItems")
+		}
+		items = append(items, bs[:l])
+		size += int(l)
+		bs = bs[l:]
+	}
+	if nIndexed < 1 || nIndexed > len(items) {
+		return nil, scoreresult.InvalidParameterError.Errorf(
+			"InvalidIndexed(indexed=%d,items=%d)", nIndexed, len(items))
+	}
+	sc := c.stepCosts()
+	if err := c.charge(sc.logBase + sc.log*int64(size)); err != nil {
+		return nil, err
+	}
+	return nil, c.ctx.OnEvent(c.to, items[:nIndexed], items[nIndexed:])
+}
+
+// call calls the method of other contract, and returns the length of the
+// result in the buffer or -1 for null. Failure of the call fails the caller
+// with the same status.
+func (c *wasmCall) call(args []uint64) ([]uint64, error) {
+	bs, err := c.read(args[0], common.AddressBytes)
+	if err != nil {
+		return nil, err
+	}
+	to, err := common.NewAddress(bs)
+	if err != nil {
+		return nil, scoreresult.InvalidParameterError.Wrap(err, "InvalidAddress")
+	}
+	vbs, err := c.read(args[1], args[2])
+	if err != nil {
+		return nil, err
+	}
+	value := intconv.BigIntSetBytes(new(big.Int), vbs)
+	if value.Sign() < 0 {
+		return nil, scoreresult.InvalidParameterError.New("NegativeValue")
+	}
+	if value.Sign() > 0 {
+		if err := c.checkWritable(); err != nil {
+			return nil, err
+		}
+	}
+	method, err := c.readString(args[3], args[4])
+	if err != nil {
+		return nil, err
+	}
+	pbs, err := c.read(args[5], args[6])
+	if err != nil {
+		return nil, err
+	}
+	params, err := wasmParamsOf(pbs)
+	if err != nil {
+		return nil, err
+	}
+	data, err := common.EncodeAny(map[string]interface{}{
+		"method": method,
+		"params": params,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	limit := big.NewInt(c.in.StepsLeft())
+	c.ctx.OnCall(c.to, to, value, limit, "call", data)
+	r := <-c.results
+	if r.steps != nil {
+		if err := c.in.UseSteps(r.steps.Int64()); err != nil {
+			return nil, err
+		}
+	}
+	if r.status != nil {
+		return nil, r.status
+	}
+	if r.result == nil {
+		return c.setBuffer(nil), nil
+	}
+	rbs, err := wasmBytesOf(r.result)
+	if err != nil {
+		return nil, err
+	}
+	return c.setBuffer(rbs), nil
+}
+
+func (c *wasmCall) revert(args []uint64) ([]uint64, error) {
+	code := module.Status(uint32(args[0]))
+	if code > module.StatusLimit-module.StatusReverted {
+		code = module.StatusLimit - module.StatusReverted
+	}
+	msg, err := c.readString(args[1], args[2])
+	if err != nil {
+		return nil, err
+	}
+	return nil, scoreresult.New(module.StatusReverted+code, msg)
+}
+
+func (c *wasmCall) logMessage(args []uint64) ([]uint64, error) {
+	msg, err := c.readString(args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	c.log.TSystemf("WASM log addr=%s msg=%s", c.to, msg)
+	c.log.Debugf("wasm|%s|%s", common.StrLeft(10, c.to.String()), msg)
+	return nil, nil
+}
+
+type wasmHostFunction struct {
+	params  []wasm.ValueType
+	results []wasm.ValueType
+	call    func(c *wasmCall, args []uint64) ([]uint64, error)
+}
+
+func wasmI32s(n int) []wasm.ValueType {
+	ts := make([]wasm.ValueType, n)
+	for i := range ts {
+		ts[i] = wasm.I32
+	}
+	return ts
+}
+
+var wasmHostFunctions = map[string]wasmHostFunction{
+	"get_value":       {wasmI32s(2), wasmI32s(1), (*wasmCall).getValue},
+	"set_value":       {wasmI32s(4), nil, (*wasmCall).setValue},
+	"delete_value":    {wasmI32s(2), nil, (*wasmCall).deleteValue},
+	"buffer_copy":     {wasmI32s(1), nil, (*wasmCall).bufferCopy},
+	"param":           {wasmI32s(1), wasmI32s(1), (*wasmCall).param},
+	"set_result":      {wasmI32s(2), nil, (*wasmCall).setResult},
+	"address":         {wasmI32s(1), wasmI32s(1), (*wasmCall).address},
+	"value":           {nil, wasmI32s(1), (*wasmCall).getValueOfCall},
+	"balance":         {wasmI32s(1), wasmI32s(1), (*wasmCall).balance},
+	"block_height":    {nil, []wasm.ValueType{wasm.I64}, (*wasmCall).blockHeight},
+	"block_timestamp": {nil, []wasm.ValueType{wasm.I64}, (*wasmCall).blockTimestamp},
+	"event":           {wasmI32s(3), nil, (*wasmCall).event},
+	"call":            {wasmI32s(7), wasmI32s(1), (*wasmCall).call},
+	"revert":          {wasmI32s(3), nil, (*wasmCall).revert},
+	"log":             {wasmI32s(2), nil, (*wasmCall).logMessage},
+}
+
+func resolveWasmHost(mod, name string) (*wasm.HostFunction, bool) {
+	if mod != WasmHostModule {
+		return nil, false
+	}
+	hf, ok := wasmHostFunctions[name]
+	if !ok {
+		return nil, false
+	}
+	return &wasm.HostFunction{
+		Type: wasm.FuncType{Params: hf.params, Results: hf.results},
+	}, true
+}
+
+// resolve binds host functions to the call.
+func (c *wasmCall) resolve(mod, name string) (*wasm.HostFunction, bool) {
+	hf, ok := resolveWasmHost(mod, name)
+	if !ok {
+		return nil, false
+	}
+	call := wasmHostFunctions[name].call
+	hf.Call = func(in *wasm.Instance, args []uint64) ([]uint64, error) {
+		return call(c, args)
+	}
+	return hf, true
+}
+
+// WasmHostType returns the type of the host function. It's for tools
+// building contracts.
+func WasmHostType(name string) (wasm.FuncType, error) {
+	hf, ok := resolveWasmHost(WasmHostModule, name)
+	if !ok {
+		return wasm.FuncType{}, errors.NotFoundError.Errorf("UnknownHostFunction(name=%s)", name)
+	}
+	return hf.Type, nil
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/eeproxy"
 )
 
 func Inspect(c module.Chain, informal bool) map[string]interface{} {
@@ -19,6 +20,7 @@ func Inspect(c module.Chain, informal bool) map[string]interface{} {
 	m["normalTxPool"] = inspectTxPool(mgr.tm.normalTxPool)
 	m["patchTxPool"] = inspectTxPool(mgr.tm.patchTxPool)
 	m["resultCache"] = inspectResultCache(mgr.trc)
+	m["executors"] = eeproxy.Inspect(mgr.eem)
 	return m
 }
 
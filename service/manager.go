@@ -58,7 +58,8 @@ type manager struct {
 
 	log log.Logger
 
-	skipTxPatch atomic.Value
+	skipTxPatch     atomic.Value
+	accountOverride atomic.Value
 }
 
 func NewManager(chain module.Chain, nm module.NetworkManager,
@@ -207,6 +208,13 @@ func (m *manager) SendPatch(data module.Patch) error {
 		}
 		m.skipTxPatch.Store(patch)
 		return nil
+	} else if data.Type() == module.PatchTypeAccountOverride {
+		patch, ok := data.(module.AccountOverridePatch)
+		if !ok {
+			return InvalidPatchDataError.New("Invalid Account Override Patch Data")
+		}
+		m.accountOverride.Store(patch)
+		return nil
 	} else {
 		return InvalidPatchDataError.New("UnknownPatch")
 	}
@@ -246,6 +254,17 @@ func (m *manager) GetPatches(parent module.Transition, bi module.BlockInfo) modu
 			txs = append(txs, tx)
 		}
 	}
+	// the patch is kept until the block of the height is made, so it's
+	// proposed again if the proposal is discarded.
+	op, _ := m.accountOverride.Load().(module.AccountOverridePatch)
+	if op != nil && op.Height() == wc.BlockHeight() {
+		tx, err := transaction.NewPatchTransaction(
+			op, m.chain.NID(), wc.BlockTimeStamp(), m.chain.Wallet())
+		if err != nil {
+			m.log.Panicf("Fail to make transaction from patch err=%+v", err)
+		}
+		txs = append(txs, tx)
+	}
 	return transaction.NewTransactionListFromSlice(m.db, txs)
 }
 
@@ -463,15 +482,13 @@ func (m *manager) SendTransaction(result []byte, height int64, txi interface{})
 	return newTx.ID(), nil
 }
 
-func (m *manager) Call(resultHash []byte,
-	vl module.ValidatorList, js []byte, bi module.BlockInfo,
-) (interface{}, error) {
-	type callJSON struct {
-		To       common.Address  `json:"to"`
-		DataType *string         `json:"dataType"`
-		Data     json.RawMessage `json:"data"`
-	}
+type callJSON struct {
+	To       common.Address  `json:"to"`
+	DataType *string         `json:"dataType"`
+	Data     json.RawMessage `json:"data"`
+}
 
+func (m *manager) newQueryHandler(js []byte) (*QueryHandler, error) {
 	var jso callJSON
 	if json.Unmarshal(js, &jso) != nil {
 		return nil, InvalidQueryError.Errorf("FailToParse(%s)", string(js))
@@ -479,20 +496,76 @@ func (m *manager) Call(resultHash []byte,
 	if jso.DataType == nil || *jso.DataType != contract.DataTypeCall {
 		return nil, InvalidQueryError.New("InvalidDataType")
 	}
+	return NewQueryHandler(m.cm, &jso.To, jso.Data)
+}
+
+func (m *manager) Call(resultHash []byte,
+	vl module.ValidatorList, js []byte, bi module.BlockInfo,
+	ovs []module.AccountOverride,
+) (interface{}, error) {
+	qh, err := m.newQueryHandler(js)
+	if err != nil {
+		return nil, err
+	}
 
 	var wc state.WorldContext
 	if wss, err := m.trc.GetWorldSnapshot(resultHash, vl.Hash()); err == nil {
-		ws := state.NewReadOnlyWorldState(wss)
+		var ws state.WorldState
+		if len(ovs) > 0 {
+			// changes by overrides are discarded as it's never flushed
+			if ws, err = state.WorldStateFromSnapshot(wss); err != nil {
+				return nil, err
+			}
+		} else {
+			ws = state.NewReadOnlyWorldState(wss)
+		}
 		wc = state.NewWorldContext(ws, bi, nil, m.plt)
 	} else {
 		return nil, err
 	}
 
-	qh, err := NewQueryHandler(m.cm, &jso.To, jso.Data)
+	cm := m.cm
+	if len(ovs) > 0 {
+		ocm := newContractManagerForOverride(m.cm, ovs, m.log)
+		defer ocm.Dispose()
+		cm = ocm
+	}
+	ctx := contract.NewContext(wc, cm, m.eem, m.chain, m.log, nil, eeproxy.ForQuery)
+	if len(ovs) > 0 {
+		if err := applyAccountOverrides(ctx, ovs, m.log); err != nil {
+			return nil, err
+		}
+		ctx.UpdateSystemInfo()
+	}
+	return qh.Query(ctx)
+}
+
+func (m *manager) MultiCall(resultHash []byte,
+	vl module.ValidatorList, jss [][]byte, bi module.BlockInfo,
+) ([]module.CallResult, error) {
+	wss, err := m.trc.GetWorldSnapshot(resultHash, vl.Hash())
 	if err != nil {
 		return nil, err
 	}
-	return qh.Query(contract.NewContext(wc, m.cm, m.eem, m.chain, m.log, nil, eeproxy.ForQuery))
+	ws := state.NewReadOnlyWorldState(wss)
+	wc := state.NewWorldContext(ws, bi, nil, m.plt)
+
+	// Keep one executor for all calls instead of getting an executor
+	// from the pool for each call.
+	eem := eeproxy.NewSharedManager(m.eem, eeproxy.ForQuery)
+	defer eem.Release()
+	ctx := contract.NewContext(wc, m.cm, eem, m.chain, m.log, nil, eeproxy.ForQuery)
+
+	results := make([]module.CallResult, len(jss))
+	for i, js := range jss {
+		qh, err := m.newQueryHandler(js)
+		if err != nil {
+			results[i].Error = err
+			continue
+		}
+		results[i].Result, results[i].Error = qh.Query(ctx)
+	}
+	return results, nil
 }
 
 func (m *manager) ValidatorListFromHash(hash []byte) module.ValidatorList {
@@ -838,7 +911,7 @@ func (m *manager) ImportResult(result []byte, vh []byte, src db.Database) error
 	return e.Run()
 }
 
-func (m *manager) ExecuteTransaction(result []byte, vh []byte, js []byte, bi module.BlockInfo) (module.Receipt, error) {
+func (m *manager) ExecuteTransaction(result []byte, vh []byte, js []byte, bi module.BlockInfo, ovs []module.AccountOverride) (module.Receipt, error) {
 	tx, err := transaction.NewTransactionFromJSON(js)
 	if err != nil {
 		return nil, err
@@ -864,7 +937,13 @@ func (m *manager) ExecuteTransaction(result []byte, vh []byte, js []byte, bi mod
 	} else {
 		return nil, err
 	}
-	ctx := contract.NewContext(wc, m.cm, m.eem, m.chain, m.log, nil, eeproxy.ForQuery)
+	cm := m.cm
+	if len(ovs) > 0 {
+		ocm := newContractManagerForOverride(m.cm, ovs, m.log)
+		defer ocm.Dispose()
+		cm = ocm
+	}
+	ctx := contract.NewContext(wc, cm, m.eem, m.chain, m.log, nil, eeproxy.ForQuery)
 	ctx.SetTransactionInfo(&state.TransactionInfo{
 		Group:     module.TransactionGroupNormal,
 		Index:     0,
@@ -873,6 +952,12 @@ func (m *manager) ExecuteTransaction(result []byte, vh []byte, js []byte, bi mod
 		Timestamp: tx.Timestamp(),
 		Nonce:     tx.Nonce(),
 	})
+	if len(ovs) > 0 {
+		if err := applyAccountOverrides(ctx, ovs, m.log); err != nil {
+			return nil, err
+		}
+		wss = ctx.GetSnapshot()
+	}
 	ctx.UpdateSystemInfo()
 
 	return txh.Execute(ctx, wss, true)
@@ -0,0 +1,182 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service_test
+
+import (
+	"fmt"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/common/wallet"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service"
+	"github.com/icon-project/goloop/service/eeproxy"
+	"github.com/icon-project/goloop/service/scoreresult"
+	"github.com/icon-project/goloop/service/state"
+	"github.com/icon-project/goloop/service/wasm"
+	"github.com/icon-project/goloop/test"
+)
+
+// countingEEManager counts executors reserved from the manager.
+type countingEEManager struct {
+	eeproxy.Manager
+
+	lock  sync.Mutex
+	count map[eeproxy.RequestPriority]int
+}
+
+func (m *countingEEManager) GetExecutor(pr eeproxy.RequestPriority) *eeproxy.Executor {
+	m.lock.Lock()
+	m.count[pr] += 1
+	m.lock.Unlock()
+	return m.Manager.GetExecutor(pr)
+}
+
+func (m *countingEEManager) countOf(pr eeproxy.RequestPriority) int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.count[pr]
+}
+
+func newCountingEEManager(t *testing.T) *countingEEManager {
+	wee, err := eeproxy.NewWasmEE(log.New())
+	assert.NoError(t, err)
+	eem, err := eeproxy.NewManager("unix", path.Join(t.TempDir(), "ee.sock"), log.New(), wee)
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		eem.Close()
+	})
+	assert.NoError(t, eem.SetInstances(1, 1, 1))
+	return &countingEEManager{
+		Manager: eem,
+		count:   make(map[eeproxy.RequestPriority]int),
+	}
+}
+
+// multiCallContract returns "ok" on get(), and reverts on fail().
+func multiCallContract() []byte {
+	i32 := wasm.I32
+	m := &wasm.Module{
+		Types: []wasm.FuncType{
+			{Params: []wasm.ValueType{i32, i32}},
+			{Params: []wasm.ValueType{i32, i32, i32}},
+			{},
+		},
+		Imports: []wasm.Import{
+			{Module: eeproxy.WasmHostModule, Name: "set_result", Type: 0},
+			{Module: eeproxy.WasmHostModule, Name: "revert", Type: 1},
+		},
+		Functions: []wasm.Function{
+			{Type: 2, Code: new(wasm.Code).
+				I32Const(0).I32Const(2).Call(0).
+				End().Bytes()},
+			{Type: 2, Code: new(wasm.Code).
+				I32Const(1).I32Const(0).I32Const(2).Call(1).
+				End().Bytes()},
+		},
+		Memory: &wasm.Limits{Min: 1},
+		Exports: []wasm.Export{
+			{Name: "memory", Kind: wasm.ExternalMemory},
+			{Name: "get", Kind: wasm.ExternalFunction, Index: 2},
+			{Name: "fail", Kind: wasm.ExternalFunction, Index: 3},
+		},
+		Data: []wasm.Data{{Offset: 0, Init: []byte("ok")}},
+		Customs: []wasm.Custom{{Name: eeproxy.WasmAPISection, Data: []byte(`[
+			{"type":"function","name":"get","inputs":[],"outputs":[{"type":"bytes"}],"readonly":"0x1"},
+			{"type":"function","name":"fail","inputs":[],"outputs":[{"type":"bytes"}],"readonly":"0x1"}
+		]`)}},
+	}
+	return m.Encode()
+}
+
+func TestManager_MultiCall(t *testing.T) {
+	test.RegisterTransactionFactory()
+	w := wallet.New()
+	gs := fmt.Sprintf(`{
+		"accounts": [
+			{ "name": "god", "address": "hx0000000000000000000000000000000000000000", "balance": "0x0" },
+			{ "name": "treasury", "address": "hx1000000000000000000000000000000000000000", "balance": "0x0" }
+		],
+		"message": "",
+		"nid": "0x1",
+		"chain": {
+			"validatorList": [ "%s" ],
+			"fee": { "stepLimit": { "invoke": "0x10000000", "query": "0x1000000" } }
+		}
+	}`, w.Address())
+	eem := newCountingEEManager(t)
+	n := newOverrideTestNodeWithEE(t, w, gs, true, eem)
+	sm := n.sm
+
+	// install the contract with the account override
+	score := common.MustNewAddressFromString("cx0000000000000000000000000000000000000100")
+	assert.NoError(t, sm.SendPatch(service.NewAccountOverridePatch(1, []module.AccountOverride{{
+		Address:     score,
+		ContentType: state.CTAppWasm,
+		Content:     multiCallContract(),
+	}})))
+	n.executeBlock(1, nil)
+	n.executeBlock(2, nil)
+
+	callOf := func(method string) []byte {
+		return []byte(fmt.Sprintf(`{"to":"%s","dataType":"call","data":{"method":"%s"}}`, score, method))
+	}
+	jss := [][]byte{
+		callOf("get"),
+		callOf("fail"),
+		[]byte(`{"to":"cx0000000000000000000000000000000000000000","dataType":"deploy"}`),
+		callOf("get"),
+	}
+	results, err := sm.MultiCall(n.last.Result(), n.last.NextValidators(), jss, common.NewBlockInfo(2, 2))
+	assert.NoError(t, err)
+	assert.Len(t, results, len(jss))
+
+	// failures are returned for each call, and others are handled
+	assert.NoError(t, results[0].Error)
+	assert.Equal(t, common.HexBytes("ok"), results[0].Result)
+	assert.Error(t, results[1].Error)
+	status, _ := scoreresult.StatusOf(results[1].Error)
+	assert.Equal(t, module.StatusReverted+1, status)
+	assert.Error(t, results[2].Error)
+	assert.True(t, service.InvalidQueryError.Equals(results[2].Error))
+	assert.NoError(t, results[3].Error)
+	assert.Equal(t, results[0].Result, results[3].Result)
+
+	// calls share an executor
+	assert.Equal(t, 1, eem.countOf(eeproxy.ForQuery))
+
+	// the executor is released after the calls, so the only executor is
+	// available for the next call.
+	ch := make(chan error, 1)
+	go func() {
+		_, err := sm.Call(n.last.Result(), n.last.NextValidators(), callOf("get"), common.NewBlockInfo(2, 2), nil)
+		ch <- err
+	}()
+	select {
+	case err := <-ch:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "executor isn't released after the calls")
+	}
+	assert.Equal(t, 2, eem.countOf(eeproxy.ForQuery))
+}
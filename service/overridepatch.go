@@ -0,0 +1,127 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+)
+
+type storageOverrideData struct {
+	Key   []byte
+	Value []byte
+}
+
+type accountOverrideData struct {
+	Address     *common.Address
+	Balance     *common.HexInt
+	Storage     []storageOverrideData
+	ContentType string
+	Content     []byte
+}
+
+type accountOverridePatchData struct {
+	Height    int64
+	Overrides []accountOverrideData
+}
+
+// accountOverridePatch records account overrides of the chain in dev mode
+// in the block, so the block is executed in the same way by others.
+type accountOverridePatch struct {
+	data accountOverridePatchData
+}
+
+func (p *accountOverridePatch) Type() string {
+	return module.PatchTypeAccountOverride
+}
+
+func (p *accountOverridePatch) Data() []byte {
+	return codec.MustMarshalToBytes(&p.data)
+}
+
+func (p *accountOverridePatch) Height() int64 {
+	return p.data.Height
+}
+
+func (p *accountOverridePatch) Overrides() []module.AccountOverride {
+	ovs := make([]module.AccountOverride, len(p.data.Overrides))
+	for i, od := range p.data.Overrides {
+		ov := &ovs[i]
+		ov.Address = od.Address
+		if od.Balance != nil {
+			ov.Balance = new(big.Int).Set(od.Balance.Value())
+		}
+		if len(od.Storage) > 0 {
+			ov.Storage = make(map[string][]byte, len(od.Storage))
+			for _, item := range od.Storage {
+				ov.Storage[string(item.Key)] = item.Value
+			}
+		}
+		ov.ContentType = od.ContentType
+		ov.Content = od.Content
+	}
+	return ovs
+}
+
+// NewAccountOverridePatch returns the patch applying overrides on the state
+// at the block of the height.
+func NewAccountOverridePatch(height int64, ovs []module.AccountOverride) module.AccountOverridePatch {
+	p := &accountOverridePatch{
+		data: accountOverridePatchData{
+			Height:    height,
+			Overrides: make([]accountOverrideData, len(ovs)),
+		},
+	}
+	for i, ov := range ovs {
+		od := &p.data.Overrides[i]
+		if ov.Address != nil {
+			od.Address = common.AddressToPtr(ov.Address)
+		}
+		if ov.Balance != nil {
+			od.Balance = new(common.HexInt).SetValue(ov.Balance)
+		}
+		// sort keys to make the patch deterministic
+		keys := make([]string, 0, len(ov.Storage))
+		for k := range ov.Storage {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			od.Storage = append(od.Storage, storageOverrideData{
+				Key:   []byte(k),
+				Value: ov.Storage[k],
+			})
+		}
+		od.ContentType = ov.ContentType
+		od.Content = ov.Content
+	}
+	return p
+}
+
+// DecodeAccountOverridePatch decodes the data of the account override patch.
+func DecodeAccountOverridePatch(bs []byte) (module.AccountOverridePatch, error) {
+	p := new(accountOverridePatch)
+	if _, err := codec.UnmarshalFromBytes(bs, &p.data); err != nil {
+		return nil, errors.IllegalArgumentError.Wrap(err, "InvalidAccountOverridePatch")
+	}
+	return p, nil
+}
@@ -0,0 +1,188 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/common/wallet"
+	"github.com/icon-project/goloop/consensus"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service"
+	"github.com/icon-project/goloop/service/eeproxy"
+	"github.com/icon-project/goloop/service/platform/basic"
+	"github.com/icon-project/goloop/test"
+)
+
+// overrideTestChain decodes the account override patch only if it's in
+// dev mode like the chain.
+type overrideTestChain struct {
+	*test.Chain
+	dev bool
+}
+
+func (c *overrideTestChain) MetricContext() context.Context {
+	return context.Background()
+}
+
+func (c *overrideTestChain) PatchDecoder() module.PatchDecoder {
+	if !c.dev {
+		return consensus.DecodePatch
+	}
+	return func(t string, bs []byte) (module.Patch, error) {
+		if t == module.PatchTypeAccountOverride {
+			return service.DecodeAccountOverridePatch(bs)
+		}
+		return consensus.DecodePatch(t, bs)
+	}
+}
+
+type overrideTestNode struct {
+	t    *testing.T
+	sm   module.ServiceManager
+	last module.Transition
+}
+
+func newOverrideTestNode(t *testing.T, w module.Wallet, gs string, dev bool) *overrideTestNode {
+	return newOverrideTestNodeWithEE(t, w, gs, dev, nil)
+}
+
+func newOverrideTestNodeWithEE(t *testing.T, w module.Wallet, gs string, dev bool, eem eeproxy.Manager) *overrideTestNode {
+	c, err := test.NewChain(t, w, db.NewMapDB(), log.New(), nil, gs)
+	assert.NoError(t, err)
+	chain := &overrideTestChain{c, dev}
+	sm, err := service.NewManager(chain, nil, eem, basic.Platform, t.TempDir())
+	assert.NoError(t, err)
+
+	n := &overrideTestNode{t: t, sm: sm}
+	n.last, err = sm.CreateInitialTransition(nil, nil)
+	assert.NoError(t, err)
+	gtx, err := sm.GenesisTransactionFromBytes(c.Genesis(), module.BlockVersion2)
+	assert.NoError(t, err)
+	txs := sm.TransactionListFromSlice([]module.Transaction{gtx}, module.BlockVersion2)
+	tr, err := sm.CreateTransition(n.last, txs, common.NewBlockInfo(0, 0), nil, true)
+	assert.NoError(t, err)
+	n.execute(tr)
+	return n
+}
+
+func (n *overrideTestNode) execute(tr module.Transition) {
+	ch := make(chan error, 2)
+	_, err := tr.Execute(&transitionCallback{ch})
+	assert.NoError(n.t, err)
+	assert.NoError(n.t, <-ch)
+	assert.NoError(n.t, <-ch)
+	assert.NoError(n.t, n.sm.Finalize(tr, module.FinalizeResult|module.FinalizePatchTransaction))
+	n.last = tr
+}
+
+// executeBlock executes the block at the height with patches. It returns
+// the transition executing the block.
+func (n *overrideTestNode) executeBlock(height int64, patches module.TransactionList) module.Transition {
+	bi := common.NewBlockInfo(height, height)
+	empty := n.sm.TransactionListFromSlice(nil, module.BlockVersion2)
+	tr, err := n.sm.CreateTransition(n.last, empty, bi, nil, false)
+	assert.NoError(n.t, err)
+	if patches == nil {
+		patches = n.sm.GetPatches(n.last, bi)
+	}
+	tr = n.sm.PatchTransition(tr, patches, bi)
+	n.execute(tr)
+	return tr
+}
+
+func (n *overrideTestNode) balanceOf(addr module.Address) *big.Int {
+	balance, err := n.sm.GetBalance(n.last.Result(), addr)
+	assert.NoError(n.t, err)
+	return balance
+}
+
+type transitionCallback struct {
+	ch chan error
+}
+
+func (cb *transitionCallback) OnValidate(tr module.Transition, e error) {
+	cb.ch <- e
+}
+
+func (cb *transitionCallback) OnExecute(tr module.Transition, e error) {
+	cb.ch <- e
+}
+
+func copyTransactions(sm module.ServiceManager, txs module.TransactionList) module.TransactionList {
+	var slice []module.Transaction
+	for itr := txs.Iterator(); itr.Has(); itr.Next() {
+		tx, _, _ := itr.Get()
+		slice = append(slice, tx)
+	}
+	return sm.TransactionListFromSlice(slice, module.BlockVersion2)
+}
+
+func TestManager_AccountOverridePatch(t *testing.T) {
+	test.RegisterTransactionFactory()
+	w := wallet.New()
+	gs := fmt.Sprintf(`{
+		"accounts": [
+			{ "name": "god", "address": "hx0000000000000000000000000000000000000000", "balance": "0x0" },
+			{ "name": "treasury", "address": "hx1000000000000000000000000000000000000000", "balance": "0x0" }
+		],
+		"message": "",
+		"nid": "0x1",
+		"chain": { "validatorList": [ "%s" ] }
+	}`, w.Address())
+	addr := common.MustNewAddressFromString("hx0000000000000000000000000000000000000001")
+	balance := big.NewInt(1000)
+
+	proposer := newOverrideTestNode(t, w, gs, true)
+	p := service.NewAccountOverridePatch(1, []module.AccountOverride{{
+		Address: addr,
+		Balance: balance,
+	}})
+	assert.NoError(t, proposer.sm.SendPatch(p))
+
+	// the patch is proposed until the block of the height is made
+	for i := 0; i < 2; i++ {
+		patches := proposer.sm.GetPatches(proposer.last, common.NewBlockInfo(1, 1))
+		assert.NotNil(t, patches.Hash())
+	}
+	tr := proposer.executeBlock(1, nil)
+	assert.NotNil(t, tr.PatchTransactions().Hash())
+	proposer.executeBlock(2, nil)
+	assert.Equal(t, 0, balance.Cmp(proposer.balanceOf(addr)))
+
+	// another node in dev mode gets the same result from the block
+	follower := newOverrideTestNode(t, wallet.New(), gs, true)
+	follower.executeBlock(1, copyTransactions(follower.sm, tr.PatchTransactions()))
+	follower.executeBlock(2, nil)
+	assert.Equal(t, proposer.last.Result(), follower.last.Result())
+	assert.Equal(t, 0, balance.Cmp(follower.balanceOf(addr)))
+
+	// other chains refuse the patch
+	other := newOverrideTestNode(t, wallet.New(), gs, false)
+	other.executeBlock(1, copyTransactions(other.sm, tr.PatchTransactions()))
+	other.executeBlock(2, nil)
+	assert.NotEqual(t, proposer.last.Result(), other.last.Result())
+	assert.Equal(t, 0, other.balanceOf(addr).Sign())
+}
@@ -0,0 +1,68 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/module"
+)
+
+func TestAccountOverridePatch(t *testing.T) {
+	addr1 := common.MustNewAddressFromString("hx0000000000000000000000000000000000000001")
+	addr2 := common.MustNewAddressFromString("cx0000000000000000000000000000000000000002")
+	ovs := []module.AccountOverride{
+		{Address: addr1, Balance: big.NewInt(1)},
+		{Address: addr2, Storage: map[string][]byte{
+			"key2": []byte("value2"),
+			"key1": []byte("value1"),
+		}},
+	}
+
+	p := NewAccountOverridePatch(10, ovs)
+	assert.Equal(t, module.PatchTypeAccountOverride, p.Type())
+
+	// same overrides make same data regardless of the order of the map
+	p2 := NewAccountOverridePatch(10, []module.AccountOverride{
+		ovs[0],
+		{Address: addr2, Storage: map[string][]byte{
+			"key1": []byte("value1"),
+			"key2": []byte("value2"),
+		}},
+	})
+	assert.True(t, bytes.Equal(p.Data(), p2.Data()))
+
+	dp, err := DecodeAccountOverridePatch(p.Data())
+	assert.NoError(t, err)
+	assert.EqualValues(t, 10, dp.Height())
+	dovs := dp.Overrides()
+	assert.Len(t, dovs, 2)
+	assert.True(t, addr1.Equal(dovs[0].Address))
+	assert.Equal(t, 0, dovs[0].Balance.Cmp(big.NewInt(1)))
+	assert.Nil(t, dovs[0].Storage)
+	assert.True(t, addr2.Equal(dovs[1].Address))
+	assert.Nil(t, dovs[1].Balance)
+	assert.Equal(t, ovs[1].Storage, dovs[1].Storage)
+
+	_, err = DecodeAccountOverridePatch([]byte{0x01})
+	assert.Error(t, err)
+}
@@ -7,12 +7,18 @@ import (
 	"github.com/icon-project/goloop/common/errors"
 	"github.com/icon-project/goloop/module"
 	"github.com/icon-project/goloop/service/contract"
+	"github.com/icon-project/goloop/service/eeproxy"
 	"github.com/icon-project/goloop/service/scoreresult"
 	"github.com/icon-project/goloop/service/state"
 	"github.com/icon-project/goloop/service/transaction"
 )
 
+// queryRetryLimit is the number of retries of the query aborted by the
+// restart of the executor.
+const queryRetryLimit = 1
+
 type QueryHandler struct {
+	cm   contract.ContractManager
 	to   module.Address
 	data []byte
 
@@ -46,6 +52,22 @@ func (qh *QueryHandler) Query(ctx contract.Context) (interface{}, error) {
 		}
 	}
 
+	for retry := 0; ; retry++ {
+		value, err := qh.call(ctx)
+		if err != nil && retry < queryRetryLimit && errors.Is(err, eeproxy.ErrRestarted) {
+			// the executor is restarted during the query, so run it again
+			// with a new handler on a fresh executor.
+			ctx.Logger().Warnf("Retry query to=%s by err=%+v", qh.to, err)
+			if qh.contractHandler, err = qh.newContractHandler(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		return value, err
+	}
+}
+
+func (qh *QueryHandler) call(ctx contract.Context) (interface{}, error) {
 	limit := ctx.GetStepLimit(state.StepLimitTypeQuery)
 	cc := contract.NewCallContext(ctx, limit, true)
 
@@ -73,15 +95,24 @@ func (qh *QueryHandler) Query(ctx contract.Context) (interface{}, error) {
 	return value, nil
 }
 
-func NewQueryHandler(cm contract.ContractManager, to module.Address, data []byte) (*QueryHandler, error) {
-	handler, err := cm.GetHandler(nil, to, big.NewInt(0), contract.CTypeCall, data)
+func (qh *QueryHandler) newContractHandler() (contract.ContractHandler, error) {
+	handler, err := qh.cm.GetHandler(nil, qh.to, big.NewInt(0), contract.CTypeCall, qh.data)
 	if err != nil {
 		return nil, errors.InvalidStateError.Wrap(err, "NoSuitableHandler")
 	}
-	return &QueryHandler{
+	return handler, nil
+}
+
+func NewQueryHandler(cm contract.ContractManager, to module.Address, data []byte) (*QueryHandler, error) {
+	qh := &QueryHandler{
+		cm:   cm,
 		to:   to,
 		data: data,
-
-		contractHandler: handler,
-	}, nil
+	}
+	handler, err := qh.newContractHandler()
+	if err != nil {
+		return nil, err
+	}
+	qh.contractHandler = handler
+	return qh, nil
 }
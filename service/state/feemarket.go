@@ -0,0 +1,85 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package state
+
+import (
+	"math/big"
+
+	"github.com/icon-project/goloop/service/scoredb"
+)
+
+const (
+	VarBaseStepPrice = "base_step_price"
+)
+
+const (
+	// FeeMarketTargetTxBytes is the target size of normal transactions in
+	// a block. It's a half of the default max block tx bytes.
+	FeeMarketTargetTxBytes = 512 * 1024
+
+	// FeeMarketChangeDenominator limits the change of the base step price
+	// in a block to 1/8 of it.
+	FeeMarketChangeDenominator = 8
+
+	// FeeMarketBurnRate is the percentage of the base fee to be burned.
+	FeeMarketBurnRate = 50
+)
+
+// NextBaseStepPrice returns the base step price for the next block.
+// The price goes up if the block used more bytes than the target, and goes
+// down if it used less. It never goes below the floor, which is the step
+// price set by the governance.
+func NextBaseStepPrice(base, floor *big.Int, used, target int64) *big.Int {
+	next := new(big.Int).Set(base)
+	if target > 0 && used != target {
+		if used > 2*target {
+			used = 2 * target
+		}
+		delta := new(big.Int).Mul(base, big.NewInt(used-target))
+		delta.Quo(delta, big.NewInt(target*FeeMarketChangeDenominator))
+		if used > target && delta.Sign() == 0 {
+			delta.SetInt64(1)
+		}
+		next.Add(next, delta)
+	}
+	if floor != nil && next.Cmp(floor) < 0 {
+		next.Set(floor)
+	}
+	return next
+}
+
+// BurnedFeeOf returns the amount to be burned for the base fee.
+func BurnedFeeOf(baseFee *big.Int) *big.Int {
+	burned := new(big.Int).Mul(baseFee, big.NewInt(FeeMarketBurnRate))
+	return burned.Quo(burned, big.NewInt(100))
+}
+
+// UpdateBaseStepPrice stores the base step price for the next block based
+// on the size of normal transactions in the current block.
+func UpdateBaseStepPrice(wc WorldContext, used int64) error {
+	as := wc.GetAccountState(SystemID)
+	floor := scoredb.NewVarDB(as, VarStepPrice).BigInt()
+	baseVar := scoredb.NewVarDB(as, VarBaseStepPrice)
+	base := baseVar.BigInt()
+	if base == nil || (floor != nil && base.Cmp(floor) < 0) {
+		base = floor
+	}
+	if base == nil {
+		return nil
+	}
+	return baseVar.Set(NextBaseStepPrice(base, floor, used, FeeMarketTargetTxBytes))
+}
@@ -0,0 +1,54 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextBaseStepPrice(t *testing.T) {
+	const target = 1000
+	cases := []struct {
+		name  string
+		base  int64
+		floor int64
+		used  int64
+		next  int64
+	}{
+		{"AtTarget", 8000, 100, target, 8000},
+		{"Full", 8000, 100, 2 * target, 9000},
+		{"OverFull", 8000, 100, 5 * target, 9000},
+		{"Half", 8000, 100, target * 3 / 2, 8500},
+		{"Empty", 8000, 100, 0, 7000},
+		{"Floor", 8000, 7500, 0, 7500},
+		{"SmallBase", 1, 1, 2 * target, 2},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			next := NextBaseStepPrice(big.NewInt(c.base), big.NewInt(c.floor), c.used, target)
+			assert.Equal(t, c.next, next.Int64())
+		})
+	}
+}
+
+func TestBurnedFeeOf(t *testing.T) {
+	assert.Equal(t, int64(500), BurnedFeeOf(big.NewInt(1000)).Int64())
+	assert.Equal(t, int64(0), BurnedFeeOf(big.NewInt(1)).Int64())
+}
@@ -202,6 +202,12 @@ func (si *systemStorageInfo) Update(wc *worldContext) bool {
 	si.revision = wc.platform.ToRevision(revision)
 
 	stepPrice := scoredb.NewVarDB(as, VarStepPrice).BigInt()
+	if si.revision.Has(module.FeeMarket) {
+		base := scoredb.NewVarDB(as, VarBaseStepPrice).BigInt()
+		if base != nil && (stepPrice == nil || base.Cmp(stepPrice) > 0) {
+			stepPrice = base
+		}
+	}
 	si.stepPrice = stepPrice
 
 	stepCosts := make(map[string]int64)
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/contract"
+	"github.com/icon-project/goloop/service/state"
+)
+
+// newContractManagerForOverride returns the contract manager keeping codes
+// of the overrides only for the query. It should be disposed after the query.
+func newContractManagerForOverride(
+	cm contract.ContractManager, ovs []module.AccountOverride, log log.Logger,
+) *contract.OverrideContractManager {
+	ocm := contract.NewOverrideContractManager(cm, log)
+	for i := range ovs {
+		if ovs[i].Content != nil {
+			ocm.AddCode(crypto.SHA3Sum256(ovs[i].Content))
+		}
+	}
+	return ocm
+}
+
+// applyAccountOverrides applies changes for accounts on the state of
+// the context.
+func applyAccountOverrides(ctx contract.Context, ovs []module.AccountOverride, log log.Logger) error {
+	limit := ctx.GetStepLimit(state.StepLimitTypeInvoke)
+	cc := contract.NewCallContext(ctx, limit, false)
+	defer cc.Dispose()
+	for i := range ovs {
+		if err := contract.ApplyAccountOverride(cc, &ovs[i], log); err != nil {
+			return InvalidQueryError.Wrap(err, "FailToApplyOverride")
+		}
+	}
+	return nil
+}
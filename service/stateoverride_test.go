@@ -0,0 +1,89 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service_test
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/containerdb"
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/common/wallet"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/scoredb"
+	"github.com/icon-project/goloop/service/state"
+	"github.com/icon-project/goloop/test"
+)
+
+func TestManager_CallWithOverrides(t *testing.T) {
+	test.RegisterTransactionFactory()
+	w := wallet.New()
+	gs := fmt.Sprintf(`{
+		"accounts": [
+			{ "name": "god", "address": "hx0000000000000000000000000000000000000000", "balance": "0x0" },
+			{ "name": "treasury", "address": "hx1000000000000000000000000000000000000000", "balance": "0x0" }
+		],
+		"message": "",
+		"nid": "0x1",
+		"chain": { "validatorList": [ "%s" ] }
+	}`, w.Address())
+	n := newOverrideTestNode(t, w, gs, false)
+	n.executeBlock(1, nil)
+
+	result := n.last.Result()
+	vl := n.last.NextValidators()
+	bi := common.NewBlockInfo(1, 1)
+	js := []byte(`{"to":"cx0000000000000000000000000000000000000000","dataType":"call","data":{"method":"getStepPrice"}}`)
+
+	price, err := n.sm.Call(result, vl, js, bi, nil)
+	assert.NoError(t, err)
+
+	addr := common.MustNewAddressFromString("hx0000000000000000000000000000000000000001")
+	key := containerdb.ToKey(containerdb.HashBuilder, scoredb.VarDBPrefix, state.VarStepPrice).Build()
+	ovs := []module.AccountOverride{
+		{
+			Address: addr,
+			Balance: big.NewInt(1000),
+		},
+		{
+			Address: state.SystemAddress,
+			Storage: map[string][]byte{string(key): intconv.Int64ToBytes(12345)},
+		},
+	}
+	overridden, err := n.sm.Call(result, vl, js, bi, ovs)
+	assert.NoError(t, err)
+	assert.Equal(t, &common.HexInt{Int: *big.NewInt(12345)}, overridden)
+	assert.NotEqual(t, price, overridden)
+
+	// the state is untouched after the call
+	assert.Equal(t, result, n.last.Result())
+	price2, err := n.sm.Call(result, vl, js, bi, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, price, price2)
+	assert.Equal(t, 0, n.balanceOf(addr).Sign())
+
+	// invalid overrides fail the call
+	_, err = n.sm.Call(result, vl, js, bi, []module.AccountOverride{{
+		Address: addr,
+		Balance: big.NewInt(-1),
+	}})
+	assert.Error(t, err)
+}
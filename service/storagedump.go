@@ -0,0 +1,299 @@
+package service
+
+import (
+	"bytes"
+
+	"github.com/icon-project/goloop/common/containerdb"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/common/trie"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/scoredb"
+)
+
+const (
+	StorageHintVar   = "var"
+	StorageHintDict  = "dict"
+	StorageHintArray = "array"
+
+	// ConfigMaxStorageHintArraySize is the maximum number of items in
+	// an ArrayDB to be decoded with a hint.
+	ConfigMaxStorageHintArraySize = 10000
+)
+
+type storageKeyInfo struct {
+	hint *module.StorageHint
+	keys []interface{}
+}
+
+// storageDecoder maps keys in the storage to the containers in hints.
+// Keys built by HashBuilder can't be reversed, so it builds all keys of
+// the containers described by hints. Keys not in hints are decoded if
+// they are built without hashing.
+type storageDecoder map[string]storageKeyInfo
+
+func newStorageDecoder(hints []module.StorageHint, stores ...trie.Immutable) (storageDecoder, error) {
+	d := make(storageDecoder)
+	for i := range hints {
+		h := &hints[i]
+		switch h.Type {
+		case StorageHintVar:
+			key := containerdb.ToKey(containerdb.HashBuilder, scoredb.VarDBPrefix, h.Name)
+			d.add(key.Build(), h)
+		case StorageHintDict:
+			key := containerdb.ToKey(containerdb.HashBuilder, scoredb.DictDBPrefix, h.Name)
+			for _, keys := range h.Keys {
+				if len(keys) == 0 {
+					return nil, errors.IllegalArgumentError.Errorf(
+						"EmptyDictKeys(name=%s)", h.Name)
+				}
+				d.add(key.Append(keys...).Build(), h, keys...)
+			}
+		case StorageHintArray:
+			key := containerdb.ToKey(containerdb.HashBuilder, scoredb.ArrayDBPrefix, h.Name)
+			sizeKey := key.Build()
+			d.add(sizeKey, h)
+			var size int64
+			for _, store := range stores {
+				if store == nil {
+					continue
+				}
+				value, err := store.Get(sizeKey)
+				if err != nil {
+					return nil, err
+				}
+				if sz := intconv.BytesToInt64(value); sz > size {
+					size = sz
+				}
+			}
+			if size > ConfigMaxStorageHintArraySize {
+				size = ConfigMaxStorageHintArraySize
+			}
+			for idx := 0; idx < int(size); idx++ {
+				d.add(key.Append(idx).Build(), h, idx)
+			}
+		default:
+			return nil, errors.IllegalArgumentError.Errorf(
+				"InvalidHintType(type=%s)", h.Type)
+		}
+	}
+	return d, nil
+}
+
+func (d storageDecoder) add(key []byte, hint *module.StorageHint, keys ...interface{}) {
+	d[string(key)] = storageKeyInfo{hint: hint, keys: keys}
+}
+
+// decodeRawKey decodes the key built by RLPBuilder, which is used by
+// system contracts. It returns false if the key doesn't look like it.
+func decodeRawKey(key []byte) (*module.StorageHint, []interface{}, bool) {
+	parts, err := containerdb.SplitKeys(key)
+	if err != nil || len(parts) < 2 || len(parts[0]) != 1 {
+		return nil, nil, false
+	}
+	var typ string
+	switch parts[0][0] {
+	case scoredb.VarDBPrefix:
+		typ = StorageHintVar
+	case scoredb.DictDBPrefix:
+		typ = StorageHintDict
+	case scoredb.ArrayDBPrefix:
+		typ = StorageHintArray
+	default:
+		return nil, nil, false
+	}
+	var keys []interface{}
+	for _, part := range parts[2:] {
+		keys = append(keys, part)
+	}
+	return &module.StorageHint{Type: typ, Name: string(parts[1])}, keys, true
+}
+
+func (d storageDecoder) entryOf(key, value []byte) module.StorageEntry {
+	e := module.StorageEntry{
+		Key:   key,
+		Value: value,
+	}
+	if info, ok := d[string(key)]; ok {
+		e.Hint = info.hint
+		e.Keys = info.keys
+	} else if hint, keys, ok := decodeRawKey(key); ok {
+		e.Hint = hint
+		e.Keys = keys
+	}
+	return e
+}
+
+// storageIterator iterates items of the storage after the cursor. It
+// handles nil store as an empty one.
+type storageIterator struct {
+	itr   trie.Iterator
+	key   []byte
+	value []byte
+}
+
+func newStorageIterator(store trie.Immutable, cursor []byte) (*storageIterator, error) {
+	si := new(storageIterator)
+	if store == nil {
+		return si, nil
+	}
+	if seeker, ok := store.(trie.Seeker); ok && cursor != nil {
+		si.itr = seeker.Seek(cursor)
+	} else {
+		si.itr = store.Iterator()
+	}
+	for {
+		if err := si.load(); err != nil {
+			return nil, err
+		}
+		if si.key == nil || cursor == nil || bytes.Compare(si.key, cursor) > 0 {
+			return si, nil
+		}
+		if err := si.itr.Next(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (si *storageIterator) load() error {
+	if si.itr == nil || !si.itr.Has() {
+		si.key, si.value = nil, nil
+		return nil
+	}
+	value, key, err := si.itr.Get()
+	if err != nil {
+		return err
+	}
+	si.key, si.value = key, value
+	return nil
+}
+
+func (si *storageIterator) Has() bool {
+	return si.key != nil
+}
+
+func (si *storageIterator) Next() error {
+	if err := si.itr.Next(); err != nil {
+		return err
+	}
+	return si.load()
+}
+
+// getStoreOf returns the storage of the contract. It returns false if
+// there is no contract at the address.
+func (m *manager) getStoreOf(result []byte, addr module.Address) (trie.Immutable, bool, error) {
+	if !addr.IsContract() {
+		return nil, false, errors.IllegalArgumentError.Errorf("Given Address(%s) isn't contract", addr)
+	}
+	wss, err := m.trc.GetWorldSnapshot(result, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	ass := wss.GetAccountSnapshot(addr.ID())
+	if ass == nil || !ass.IsContract() {
+		return nil, false, nil
+	}
+	if s, ok := ass.(interface{ Store() trie.Immutable }); ok {
+		return s.Store(), true, nil
+	}
+	return nil, false, errors.UnsupportedError.Errorf("UnknownAccountSnapshot(addr=%s)", addr)
+}
+
+func getStorage(store trie.Immutable, hints []module.StorageHint, cursor []byte, limit int) ([]module.StorageEntry, error) {
+	d, err := newStorageDecoder(hints, store)
+	if err != nil {
+		return nil, err
+	}
+	itr, err := newStorageIterator(store, cursor)
+	if err != nil {
+		return nil, err
+	}
+	var entries []module.StorageEntry
+	for itr.Has() && len(entries) < limit {
+		entries = append(entries, d.entryOf(itr.key, itr.value))
+		if err := itr.Next(); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+func diffStorage(store1, store2 trie.Immutable, hints []module.StorageHint, cursor []byte, limit int) ([]module.StorageChange, error) {
+	d, err := newStorageDecoder(hints, store1, store2)
+	if err != nil {
+		return nil, err
+	}
+	itr1, err := newStorageIterator(store1, cursor)
+	if err != nil {
+		return nil, err
+	}
+	itr2, err := newStorageIterator(store2, cursor)
+	if err != nil {
+		return nil, err
+	}
+	var changes []module.StorageChange
+	for (itr1.Has() || itr2.Has()) && len(changes) < limit {
+		var cmp int
+		switch {
+		case !itr1.Has():
+			cmp = 1
+		case !itr2.Has():
+			cmp = -1
+		default:
+			cmp = bytes.Compare(itr1.key, itr2.key)
+		}
+		switch {
+		case cmp < 0:
+			changes = append(changes, module.StorageChange{
+				StorageEntry: d.entryOf(itr1.key, nil),
+				OldValue:     itr1.value,
+			})
+			err = itr1.Next()
+		case cmp > 0:
+			changes = append(changes, module.StorageChange{
+				StorageEntry: d.entryOf(itr2.key, itr2.value),
+			})
+			err = itr2.Next()
+		default:
+			if !bytes.Equal(itr1.value, itr2.value) {
+				changes = append(changes, module.StorageChange{
+					StorageEntry: d.entryOf(itr2.key, itr2.value),
+					OldValue:     itr1.value,
+				})
+			}
+			if err = itr1.Next(); err == nil {
+				err = itr2.Next()
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return changes, nil
+}
+
+func (m *manager) GetStorage(result []byte, addr module.Address, hints []module.StorageHint, cursor []byte, limit int) ([]module.StorageEntry, error) {
+	store, ok, err := m.getStoreOf(result, addr)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.NotFoundError.Errorf("NoValidContract(addr=%s)", addr)
+	}
+	return getStorage(store, hints, cursor, limit)
+}
+
+func (m *manager) DiffStorage(result1, result2 []byte, addr module.Address, hints []module.StorageHint, cursor []byte, limit int) ([]module.StorageChange, error) {
+	store1, ok1, err := m.getStoreOf(result1, addr)
+	if err != nil {
+		return nil, err
+	}
+	store2, ok2, err := m.getStoreOf(result2, addr)
+	if err != nil {
+		return nil, err
+	}
+	if !ok1 && !ok2 {
+		return nil, errors.NotFoundError.Errorf("NoValidContract(addr=%s)", addr)
+	}
+	return diffStorage(store1, store2, hints, cursor, limit)
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/containerdb"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/trie"
+	"github.com/icon-project/goloop/common/trie/trie_manager"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/scoredb"
+)
+
+func newTestStorage(t *testing.T, dbase db.Database, update func(store containerdb.BytesStoreState)) trie.Immutable {
+	mt := trie_manager.NewMutable(dbase, nil)
+	update(containerdb.NewBytesStoreStateFromRaw(mt))
+	ss := mt.GetSnapshot()
+	assert.NoError(t, ss.Flush())
+	return ss
+}
+
+func TestGetStorage(t *testing.T) {
+	dbase := db.NewMapDB()
+	owner := common.MustNewAddressFromString("hx4873b94352c8c1f3b2f09aaeccea31ce9e90bd31")
+	store := newTestStorage(t, dbase, func(store containerdb.BytesStoreState) {
+		assert.NoError(t, scoredb.NewVarDB(store, "total").Set(100))
+		assert.NoError(t, scoredb.NewDictDB(store, "balances", 1).Set(owner, 100))
+		items := scoredb.NewArrayDB(store, "items")
+		assert.NoError(t, items.Put("a"))
+		assert.NoError(t, items.Put("b"))
+		raw := containerdb.ToKey(containerdb.RLPBuilder, scoredb.VarDBPrefix, "raw")
+		assert.NoError(t, containerdb.NewVarDB(store, raw).Set(1))
+	})
+	hints := []module.StorageHint{
+		{Type: StorageHintVar, Name: "total"},
+		{Type: StorageHintDict, Name: "balances", Keys: [][]interface{}{{owner}}},
+		{Type: StorageHintArray, Name: "items"},
+	}
+
+	entries, err := getStorage(store, hints, nil, 100)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 6)
+	decoded := make(map[string]int)
+	for _, e := range entries {
+		if assert.NotNil(t, e.Hint) {
+			decoded[e.Hint.Name] += 1
+		}
+	}
+	assert.Equal(t, map[string]int{"total": 1, "balances": 1, "items": 3, "raw": 1}, decoded)
+
+	// pagination with the cursor
+	var paged []module.StorageEntry
+	var cursor []byte
+	for {
+		es, err := getStorage(store, hints, cursor, 4)
+		assert.NoError(t, err)
+		paged = append(paged, es...)
+		if len(es) < 4 {
+			break
+		}
+		cursor = es[len(es)-1].Key
+	}
+	assert.Equal(t, entries, paged)
+
+	// invalid hint
+	_, err = getStorage(store, []module.StorageHint{{Type: "map", Name: "x"}}, nil, 100)
+	assert.Error(t, err)
+}
+
+func TestDiffStorage(t *testing.T) {
+	dbase := db.NewMapDB()
+	store1 := newTestStorage(t, dbase, func(store containerdb.BytesStoreState) {
+		assert.NoError(t, scoredb.NewVarDB(store, "total").Set(100))
+		assert.NoError(t, scoredb.NewVarDB(store, "removed").Set(1))
+		assert.NoError(t, scoredb.NewVarDB(store, "same").Set(2))
+	})
+	store2 := newTestStorage(t, dbase, func(store containerdb.BytesStoreState) {
+		assert.NoError(t, scoredb.NewVarDB(store, "total").Set(200))
+		assert.NoError(t, scoredb.NewVarDB(store, "added").Set(3))
+		assert.NoError(t, scoredb.NewVarDB(store, "same").Set(2))
+	})
+	hints := []module.StorageHint{
+		{Type: StorageHintVar, Name: "total"},
+		{Type: StorageHintVar, Name: "removed"},
+		{Type: StorageHintVar, Name: "added"},
+		{Type: StorageHintVar, Name: "same"},
+	}
+
+	changes, err := diffStorage(store1, store2, hints, nil, 100)
+	assert.NoError(t, err)
+	assert.Len(t, changes, 3)
+	for _, c := range changes {
+		switch c.Hint.Name {
+		case "total":
+			assert.Equal(t, []byte{100}, c.OldValue)
+			assert.Equal(t, []byte{0x00, 200}, c.Value)
+		case "removed":
+			assert.Equal(t, []byte{1}, c.OldValue)
+			assert.Nil(t, c.Value)
+		case "added":
+			assert.Nil(t, c.OldValue)
+			assert.Equal(t, []byte{3}, c.Value)
+		default:
+			t.Errorf("unexpected change %s", c.Hint.Name)
+		}
+	}
+
+	// the contract didn't exist at the start
+	changes, err = diffStorage(nil, store2, hints, nil, 100)
+	assert.NoError(t, err)
+	assert.Len(t, changes, 3)
+
+	// pagination with the cursor
+	changes, err = diffStorage(store1, store2, hints, nil, 2)
+	assert.NoError(t, err)
+	assert.Len(t, changes, 2)
+	rest, err := diffStorage(store1, store2, hints, changes[1].Key, 2)
+	assert.NoError(t, err)
+	assert.Len(t, rest, 1)
+}
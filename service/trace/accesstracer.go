@@ -0,0 +1,246 @@
+package trace
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+)
+
+var storageOpNames = []string{
+	"GET",
+	"SET",
+	"DELETE",
+}
+
+func storageOpToString(o module.StorageOp) string {
+	return storageOpNames[o]
+}
+
+type storageAccess struct {
+	op    module.StorageOp
+	addr  module.Address
+	key   []byte
+	value []byte
+	old   []byte
+}
+
+func (a *storageAccess) toJSON() map[string]interface{} {
+	jso := map[string]interface{}{
+		"op":      storageOpToString(a.op),
+		"address": a.addr,
+		"key":     common.HexBytes(a.key),
+	}
+	switch a.op {
+	case module.StorageGet:
+		jso["value"] = bytesToJSON(a.value)
+	case module.StorageSet:
+		jso["value"] = bytesToJSON(a.value)
+		jso["old"] = bytesToJSON(a.old)
+	case module.StorageDelete:
+		jso["old"] = bytesToJSON(a.old)
+	}
+	return jso
+}
+
+func bytesToJSON(b []byte) interface{} {
+	if b == nil {
+		return nil
+	}
+	return common.HexBytes(b)
+}
+
+type accessFrame struct {
+	parent   *accessFrame
+	children []*accessFrame
+	id       int
+	depth    int
+	reverted bool
+	accesses []*storageAccess
+}
+
+func (f *accessFrame) revert() {
+	f.reverted = true
+	for _, c := range f.children {
+		c.revert()
+	}
+}
+
+func (f *accessFrame) toJSON() map[string]interface{} {
+	accesses := make([]interface{}, len(f.accesses))
+	for i, a := range f.accesses {
+		accesses[i] = a.toJSON()
+	}
+	jso := map[string]interface{}{
+		"frame":    fmt.Sprintf("%#x", f.id),
+		"depth":    fmt.Sprintf("%#x", f.depth),
+		"accesses": accesses,
+	}
+	if f.reverted {
+		jso["reverted"] = "0x1"
+	}
+	return jso
+}
+
+type accessTransaction struct {
+	index     int
+	hash      []byte
+	isBlockTx bool
+	frames    []*accessFrame
+}
+
+func (t *accessTransaction) framesToJSON() []interface{} {
+	frames := make([]interface{}, 0, len(t.frames))
+	for _, f := range t.frames {
+		if len(f.accesses) > 0 {
+			frames = append(frames, f.toJSON())
+		}
+	}
+	return frames
+}
+
+func (t *accessTransaction) toJSON() map[string]interface{} {
+	prefix := "0x"
+	if t.isBlockTx {
+		prefix = "bx"
+	}
+	jso := map[string]interface{}{
+		"txIndex": fmt.Sprintf("%#x", t.index),
+		"frames":  t.framesToJSON(),
+	}
+	if t.hash != nil {
+		jso["txHash"] = prefix + hex.EncodeToString(t.hash)
+	}
+	return jso
+}
+
+// AccessTracer records storage accesses of each call frame in transactions.
+type AccessTracer struct {
+	txs      []*accessTransaction
+	curFrame *accessFrame
+}
+
+func (at *AccessTracer) getCurrentTx() (*accessTransaction, error) {
+	txCount := len(at.txs)
+	if txCount == 0 {
+		return nil, errors.InvalidStateError.New("No transaction")
+	}
+	return at.txs[txCount-1], nil
+}
+
+func (at *AccessTracer) newFrame(tx *accessTransaction, parent *accessFrame) *accessFrame {
+	frame := &accessFrame{
+		parent: parent,
+		id:     len(tx.frames),
+	}
+	if parent != nil {
+		frame.depth = parent.depth + 1
+		parent.children = append(parent.children, frame)
+	}
+	tx.frames = append(tx.frames, frame)
+	at.curFrame = frame
+	return frame
+}
+
+func (at *AccessTracer) OnTransactionStart(txIndex int, txHash []byte, isBlockTx bool) error {
+	if at.curFrame != nil {
+		return errors.InvalidStateError.Errorf(
+			"Invalid curFrame: txIndex=%d txHash=%#x", txIndex, txHash)
+	}
+	tx := &accessTransaction{index: txIndex, hash: txHash, isBlockTx: isBlockTx}
+	at.txs = append(at.txs, tx)
+	at.newFrame(tx, nil)
+	return nil
+}
+
+func (at *AccessTracer) OnTransactionReset() error {
+	curTx, err := at.getCurrentTx()
+	if err != nil {
+		return err
+	}
+	curTx.frames = nil
+	at.newFrame(curTx, nil)
+	return nil
+}
+
+func (at *AccessTracer) OnTransactionEnd(txIndex int, txHash []byte) error {
+	curTx, err := at.getCurrentTx()
+	if err != nil {
+		return err
+	}
+	if curTx.index != txIndex {
+		return errors.InvalidStateError.Errorf(
+			"Invalid txIndex: curTxIndex=%d txIndex=%d", curTx.index, txIndex)
+	}
+	if at.curFrame == nil || at.curFrame.depth != 0 {
+		return errors.InvalidStateError.New("Invalid callFrame depth")
+	}
+	at.curFrame = nil
+	return nil
+}
+
+func (at *AccessTracer) OnFrameEnter() error {
+	if at.curFrame == nil {
+		return errors.InvalidStateError.Errorf("AccessTracer Not Ready")
+	}
+	curTx, err := at.getCurrentTx()
+	if err != nil {
+		return err
+	}
+	at.newFrame(curTx, at.curFrame)
+	return nil
+}
+
+func (at *AccessTracer) OnFrameExit(success bool) error {
+	curFrame := at.curFrame
+	if curFrame == nil {
+		return errors.InvalidStateError.New("curFrame Not Ready")
+	}
+	if curFrame.depth <= 0 {
+		return errors.InvalidStateError.Errorf("Invalid frameDepth: %d", curFrame.depth)
+	}
+	if !success {
+		curFrame.revert()
+	}
+	at.curFrame = curFrame.parent
+	return nil
+}
+
+func (at *AccessTracer) OnStorageAccess(op module.StorageOp, addr module.Address, key, value, old []byte) error {
+	if at.curFrame == nil {
+		return errors.InvalidStateError.New("curFrame Not Ready")
+	}
+	at.curFrame.accesses = append(at.curFrame.accesses, &storageAccess{
+		op:    op,
+		addr:  addr,
+		key:   key,
+		value: value,
+		old:   old,
+	})
+	return nil
+}
+
+// FramesToJSON returns storage accesses of the last transaction.
+func (at *AccessTracer) FramesToJSON() interface{} {
+	curTx, err := at.getCurrentTx()
+	if err != nil {
+		return []interface{}{}
+	}
+	return curTx.framesToJSON()
+}
+
+func (at *AccessTracer) ToJSON() interface{} {
+	jso := make([]interface{}, 0, len(at.txs))
+	for _, tx := range at.txs {
+		jso = append(jso, tx.toJSON())
+	}
+	return jso
+}
+
+func NewAccessTracer(capacity int) *AccessTracer {
+	return &AccessTracer{
+		txs: make([]*accessTransaction, 0, capacity),
+	}
+}
@@ -0,0 +1,92 @@
+package trace
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/module"
+)
+
+func TestAccessTracer(t *testing.T) {
+	at := NewAccessTracer(1)
+
+	txIndex := 0
+	txHash := newRandomHash(32)
+	score1 := common.MustNewAddressFromString("cx01")
+	score2 := common.MustNewAddressFromString("cx02")
+
+	assert.NoError(t, at.OnTransactionStart(txIndex, txHash, false))
+
+	// frame 1 : succeeded
+	assert.NoError(t, at.OnFrameEnter())
+	assert.NoError(t, at.OnStorageAccess(module.StorageGet, score1, []byte("k1"), []byte("v1"), nil))
+	assert.NoError(t, at.OnStorageAccess(module.StorageSet, score1, []byte("k1"), []byte("v2"), []byte("v1")))
+
+	// frame 2 : failed
+	assert.NoError(t, at.OnFrameEnter())
+	assert.NoError(t, at.OnStorageAccess(module.StorageDelete, score2, []byte("k2"), nil, []byte("v3")))
+	assert.NoError(t, at.OnFrameExit(false))
+
+	// frame 3 : no access
+	assert.NoError(t, at.OnFrameEnter())
+	assert.NoError(t, at.OnFrameExit(true))
+
+	assert.NoError(t, at.OnFrameExit(true))
+
+	// invalid depth on end of transaction
+	assert.NoError(t, at.OnFrameEnter())
+	assert.Error(t, at.OnTransactionEnd(txIndex, txHash))
+	assert.NoError(t, at.OnFrameExit(true))
+	assert.Error(t, at.OnFrameExit(true))
+
+	assert.NoError(t, at.OnTransactionEnd(txIndex, txHash))
+
+	frames, ok := at.FramesToJSON().([]interface{})
+	assert.True(t, ok)
+	assert.Equal(t, 2, len(frames))
+
+	f1 := frames[0].(map[string]interface{})
+	assert.Equal(t, "0x1", f1["frame"])
+	assert.Equal(t, "0x1", f1["depth"])
+	assert.Nil(t, f1["reverted"])
+	accesses := f1["accesses"].([]interface{})
+	assert.Equal(t, 2, len(accesses))
+	a := accesses[1].(map[string]interface{})
+	assert.Equal(t, "SET", a["op"])
+	assert.Equal(t, score1, a["address"])
+	assert.Equal(t, common.HexBytes("k1"), a["key"])
+	assert.Equal(t, common.HexBytes("v2"), a["value"])
+	assert.Equal(t, common.HexBytes("v1"), a["old"])
+
+	f2 := frames[1].(map[string]interface{})
+	assert.Equal(t, "0x2", f2["frame"])
+	assert.Equal(t, "0x2", f2["depth"])
+	assert.Equal(t, "0x1", f2["reverted"])
+	a = f2["accesses"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "DELETE", a["op"])
+	assert.Nil(t, a["value"])
+	assert.Equal(t, common.HexBytes("v3"), a["old"])
+
+	txs, ok := at.ToJSON().([]interface{})
+	assert.True(t, ok)
+	assert.Equal(t, 1, len(txs))
+}
+
+func TestAccessTracer_Reset(t *testing.T) {
+	at := NewAccessTracer(1)
+
+	score := common.MustNewAddressFromString("cx01")
+	assert.Error(t, at.OnTransactionReset())
+	assert.Error(t, at.OnFrameEnter())
+	assert.Error(t, at.OnStorageAccess(module.StorageGet, score, []byte("k"), nil, nil))
+
+	assert.NoError(t, at.OnTransactionStart(0, nil, true))
+	assert.NoError(t, at.OnStorageAccess(module.StorageGet, score, []byte("k"), nil, nil))
+	assert.NoError(t, at.OnTransactionReset())
+	assert.NoError(t, at.OnTransactionEnd(0, nil))
+
+	frames := at.FramesToJSON().([]interface{})
+	assert.Equal(t, 0, len(frames))
+}
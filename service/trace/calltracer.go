@@ -0,0 +1,224 @@
+package trace
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/scoreresult"
+)
+
+type callNode struct {
+	parent   *callNode
+	depth    int
+	call     *module.TraceCall
+	done     bool
+	status   error
+	stepUsed *big.Int
+	calls    []*callNode
+}
+
+func (n *callNode) callsToJSON() []interface{} {
+	calls := make([]interface{}, len(n.calls))
+	for i, c := range n.calls {
+		calls[i] = c.toJSON()
+	}
+	return calls
+}
+
+func (n *callNode) toJSON() map[string]interface{} {
+	jso := make(map[string]interface{})
+	if call := n.call; call != nil {
+		if len(call.Type) > 0 {
+			jso["type"] = call.Type
+		}
+		if call.From != nil {
+			jso["from"] = call.From
+		}
+		if call.To != nil {
+			jso["to"] = call.To
+		}
+		if call.Value != nil {
+			jso["value"] = common.NewHexInt(0).SetValue(call.Value)
+		}
+		if len(call.Method) > 0 {
+			jso["method"] = call.Method
+		}
+		if call.Params != nil {
+			jso["params"] = call.Params
+		}
+		if call.StepLimit != nil {
+			jso["stepLimit"] = common.NewHexInt(0).SetValue(call.StepLimit)
+		}
+	}
+	if n.stepUsed != nil {
+		jso["stepUsed"] = common.NewHexInt(0).SetValue(n.stepUsed)
+	}
+	if n.done && n.status == nil {
+		jso["status"] = "0x1"
+	} else {
+		jso["status"] = "0x0"
+		if n.status != nil {
+			status, _ := scoreresult.StatusOf(n.status)
+			jso["failure"] = map[string]interface{}{
+				"code":    fmt.Sprintf("%#x", int(status)),
+				"message": n.status.Error(),
+			}
+		}
+	}
+	jso["calls"] = n.callsToJSON()
+	return jso
+}
+
+type callTreeTransaction struct {
+	index     int
+	hash      []byte
+	isBlockTx bool
+	root      *callNode
+}
+
+func (t *callTreeTransaction) toJSON() map[string]interface{} {
+	prefix := "0x"
+	if t.isBlockTx {
+		prefix = "bx"
+	}
+	jso := map[string]interface{}{
+		"txIndex": fmt.Sprintf("%#x", t.index),
+		"calls":   t.root.callsToJSON(),
+	}
+	if t.hash != nil {
+		jso["txHash"] = prefix + hex.EncodeToString(t.hash)
+	}
+	return jso
+}
+
+// CallTreeTracer builds trees of calls in transactions.
+type CallTreeTracer struct {
+	txs     []*callTreeTransaction
+	curNode *callNode
+}
+
+func (ct *CallTreeTracer) getCurrentTx() (*callTreeTransaction, error) {
+	txCount := len(ct.txs)
+	if txCount == 0 {
+		return nil, errors.InvalidStateError.New("No transaction")
+	}
+	return ct.txs[txCount-1], nil
+}
+
+func (ct *CallTreeTracer) OnTransactionStart(txIndex int, txHash []byte, isBlockTx bool) error {
+	if ct.curNode != nil {
+		return errors.InvalidStateError.Errorf(
+			"Invalid curNode: txIndex=%d txHash=%#x", txIndex, txHash)
+	}
+	root := &callNode{}
+	tx := &callTreeTransaction{index: txIndex, hash: txHash, isBlockTx: isBlockTx, root: root}
+	ct.txs = append(ct.txs, tx)
+	ct.curNode = root
+	return nil
+}
+
+func (ct *CallTreeTracer) OnTransactionReset() error {
+	curTx, err := ct.getCurrentTx()
+	if err != nil {
+		return err
+	}
+	curTx.root = &callNode{}
+	ct.curNode = curTx.root
+	return nil
+}
+
+func (ct *CallTreeTracer) OnTransactionEnd(txIndex int, txHash []byte) error {
+	curTx, err := ct.getCurrentTx()
+	if err != nil {
+		return err
+	}
+	if curTx.index != txIndex {
+		return errors.InvalidStateError.Errorf(
+			"Invalid txIndex: curTxIndex=%d txIndex=%d", curTx.index, txIndex)
+	}
+	if ct.curNode == nil {
+		return errors.InvalidStateError.New("curNode Not Ready")
+	}
+	// Frames cleaned up on timeout or critical failure are not exited,
+	// so they are left as not done.
+	ct.curNode = nil
+	return nil
+}
+
+func (ct *CallTreeTracer) OnFrameEnter() error {
+	parent := ct.curNode
+	if parent == nil {
+		return errors.InvalidStateError.Errorf("CallTreeTracer Not Ready")
+	}
+	node := &callNode{
+		parent: parent,
+		depth:  parent.depth + 1,
+	}
+	parent.calls = append(parent.calls, node)
+	ct.curNode = node
+	return nil
+}
+
+func (ct *CallTreeTracer) OnFrameExit(success bool) error {
+	node := ct.curNode
+	if node == nil {
+		return errors.InvalidStateError.New("curNode Not Ready")
+	}
+	if node.depth <= 0 {
+		return errors.InvalidStateError.Errorf("Invalid frameDepth: %d", node.depth)
+	}
+	if !success && node.status == nil {
+		node.status = scoreresult.UnknownFailureError.New("Failure")
+	}
+	node.done = true
+	ct.curNode = node.parent
+	return nil
+}
+
+func (ct *CallTreeTracer) OnCallStart(call *module.TraceCall) error {
+	node := ct.curNode
+	if node == nil || node.depth <= 0 {
+		return errors.InvalidStateError.New("NoFrameForCall")
+	}
+	node.call = call
+	return nil
+}
+
+func (ct *CallTreeTracer) OnCallEnd(status error, stepUsed *big.Int) error {
+	node := ct.curNode
+	if node == nil || node.depth <= 0 {
+		return errors.InvalidStateError.New("NoFrameForCall")
+	}
+	node.status = status
+	if stepUsed != nil {
+		node.stepUsed = new(big.Int).Set(stepUsed)
+	}
+	return nil
+}
+
+// CallsToJSON returns the call tree of the last transaction.
+func (ct *CallTreeTracer) CallsToJSON() interface{} {
+	curTx, err := ct.getCurrentTx()
+	if err != nil {
+		return []interface{}{}
+	}
+	return curTx.root.callsToJSON()
+}
+
+func (ct *CallTreeTracer) ToJSON() interface{} {
+	jso := make([]interface{}, 0, len(ct.txs))
+	for _, tx := range ct.txs {
+		jso = append(jso, tx.toJSON())
+	}
+	return jso
+}
+
+func NewCallTreeTracer(capacity int) *CallTreeTracer {
+	return &CallTreeTracer{
+		txs: make([]*callTreeTransaction, 0, capacity),
+	}
+}
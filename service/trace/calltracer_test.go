@@ -0,0 +1,119 @@
+package trace
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/scoreresult"
+)
+
+func TestCallTreeTracer(t *testing.T) {
+	ct := NewCallTreeTracer(1)
+
+	txIndex := 0
+	txHash := newRandomHash(32)
+	user := common.MustNewAddressFromString("hx01")
+	score1 := common.MustNewAddressFromString("cx01")
+	score2 := common.MustNewAddressFromString("cx02")
+
+	assert.NoError(t, ct.OnTransactionStart(txIndex, txHash, false))
+	assert.Error(t, ct.OnCallStart(&module.TraceCall{}))
+
+	assert.NoError(t, ct.OnFrameEnter())
+	assert.NoError(t, ct.OnCallStart(&module.TraceCall{
+		Type:      "call",
+		From:      user,
+		To:        score1,
+		Value:     big.NewInt(10),
+		Method:    "swap",
+		Params:    map[string]interface{}{"_amount": "0x64"},
+		StepLimit: big.NewInt(1000),
+	}))
+
+	assert.NoError(t, ct.OnFrameEnter())
+	assert.NoError(t, ct.OnCallStart(&module.TraceCall{
+		Type:   "call",
+		From:   score1,
+		To:     score2,
+		Method: "transfer",
+	}))
+	assert.NoError(t, ct.OnCallEnd(scoreresult.ErrInvalidParameter, big.NewInt(30)))
+	assert.NoError(t, ct.OnFrameExit(false))
+
+	assert.NoError(t, ct.OnFrameEnter())
+	assert.NoError(t, ct.OnCallStart(&module.TraceCall{
+		Type:  "transfer",
+		From:  score1,
+		To:    user,
+		Value: big.NewInt(5),
+	}))
+	assert.NoError(t, ct.OnCallEnd(nil, big.NewInt(20)))
+	assert.NoError(t, ct.OnFrameExit(true))
+
+	assert.NoError(t, ct.OnCallEnd(nil, big.NewInt(100)))
+	assert.NoError(t, ct.OnFrameExit(true))
+	assert.Error(t, ct.OnFrameExit(true))
+
+	assert.NoError(t, ct.OnTransactionEnd(txIndex, txHash))
+
+	calls := ct.CallsToJSON().([]interface{})
+	assert.Equal(t, 1, len(calls))
+
+	c1 := calls[0].(map[string]interface{})
+	assert.Equal(t, "call", c1["type"])
+	assert.Equal(t, user, c1["from"])
+	assert.Equal(t, score1, c1["to"])
+	assert.Equal(t, "0xa", c1["value"].(*common.HexInt).String())
+	assert.Equal(t, "swap", c1["method"])
+	assert.Equal(t, "0x3e8", c1["stepLimit"].(*common.HexInt).String())
+	assert.Equal(t, "0x64", c1["stepUsed"].(*common.HexInt).String())
+	assert.Equal(t, "0x1", c1["status"])
+	assert.Nil(t, c1["failure"])
+
+	subCalls := c1["calls"].([]interface{})
+	assert.Equal(t, 2, len(subCalls))
+
+	c2 := subCalls[0].(map[string]interface{})
+	assert.Equal(t, "transfer", c2["method"])
+	assert.Equal(t, "0x0", c2["status"])
+	failure := c2["failure"].(map[string]interface{})
+	assert.Equal(t, fmt.Sprintf("%#x", int(module.StatusInvalidParameter)), failure["code"])
+	assert.Nil(t, c2["value"])
+
+	c3 := subCalls[1].(map[string]interface{})
+	assert.Equal(t, "transfer", c3["type"])
+	assert.Equal(t, "0x1", c3["status"])
+	assert.Equal(t, 0, len(c3["calls"].([]interface{})))
+
+	txs := ct.ToJSON().([]interface{})
+	assert.Equal(t, 1, len(txs))
+	tx := txs[0].(map[string]interface{})
+	assert.Equal(t, "0x0", tx["txIndex"])
+	assert.Equal(t, 1, len(tx["calls"].([]interface{})))
+}
+
+func TestCallTreeTracer_NotExited(t *testing.T) {
+	ct := NewCallTreeTracer(1)
+
+	assert.NoError(t, ct.OnTransactionStart(0, nil, true))
+	assert.NoError(t, ct.OnFrameEnter())
+	assert.NoError(t, ct.OnCallStart(&module.TraceCall{Type: "call"}))
+	assert.NoError(t, ct.OnTransactionEnd(0, nil))
+
+	calls := ct.CallsToJSON().([]interface{})
+	assert.Equal(t, 1, len(calls))
+	c := calls[0].(map[string]interface{})
+	assert.Equal(t, "0x0", c["status"])
+	assert.Nil(t, c["failure"])
+
+	assert.NoError(t, ct.OnTransactionStart(1, nil, false))
+	assert.NoError(t, ct.OnFrameEnter())
+	assert.NoError(t, ct.OnTransactionReset())
+	assert.NoError(t, ct.OnTransactionEnd(1, nil))
+	assert.Equal(t, 0, len(ct.CallsToJSON().([]interface{})))
+}
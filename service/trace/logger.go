@@ -167,16 +167,26 @@ func (l *Logger) onFee(from, to module.Address, rct module.Receipt, feeByDeposit
 	}
 
 	stepPrice := rct.StepPrice()
+	burned := new(big.Int)
+	if frct, ok := rct.(txresult.Receipt); ok {
+		stepPrice.Add(stepPrice, frct.StepPriceTip())
+		burned = frct.BurnedFee()
+	}
 	feePayerCnt := 0
 	for it := rct.FeePaymentIterator(); it.Has(); log.Must(it.Next()) {
 		feePayment, _ := it.Get()
 		if feePayment.Payer().Equal(from) {
-			l.OnBalanceChange(module.Fee, from, to, new(big.Int).Mul(stepPrice, feePayment.Amount()))
+			fee := new(big.Int).Mul(stepPrice, feePayment.Amount())
+			l.OnBalanceChange(module.Fee, from, to, fee.Sub(fee, burned))
 		}
 		feePayerCnt++
 	}
 	if feePayerCnt == 0 {
-		l.OnBalanceChange(module.Fee, from, to, new(big.Int).Mul(stepPrice, rct.StepUsed()))
+		fee := new(big.Int).Mul(stepPrice, rct.StepUsed())
+		l.OnBalanceChange(module.Fee, from, to, fee.Sub(fee, burned))
+	}
+	if burned.Sign() > 0 {
+		l.OnBalanceChange(module.Burn, from, nil, burned)
 	}
 	if feeByDeposit.Sign() > 0 {
 		l.OnBalanceChange(module.FSFee, nil, to, feeByDeposit)
@@ -230,6 +240,35 @@ func (l *Logger) OnBalanceChange(opType module.OpType, from, to module.Address,
 	}
 }
 
+func (l *Logger) OnStorageAccess(op module.StorageOp, addr module.Address, key, value, old []byte) {
+	if l.TraceMode() != module.TraceModeAccessList {
+		return
+	}
+	if err := l.cb.OnStorageAccess(op, addr, key, value, old); err != nil {
+		l.Warnf("OnStorageAccess() error: op=%d addr=%s key=%#x err=%#v",
+			op, addr, key, err)
+	}
+}
+
+func (l *Logger) OnCallStart(call *module.TraceCall) {
+	if l.TraceMode() != module.TraceModeCallTree {
+		return
+	}
+	if err := l.cb.OnCallStart(call); err != nil {
+		l.Warnf("OnCallStart() error: from=%s to=%s err=%#v",
+			call.From, call.To, err)
+	}
+}
+
+func (l *Logger) OnCallEnd(status error, stepUsed *big.Int) {
+	if l.TraceMode() != module.TraceModeCallTree {
+		return
+	}
+	if err := l.cb.OnCallEnd(status, stepUsed); err != nil {
+		l.Warnf("OnCallEnd() error: status=%v err=%#v", status, err)
+	}
+}
+
 func NewLogger(l log.Logger, ti *module.TraceInfo) *Logger {
 	tlog := &Logger{
 		Logger: l,
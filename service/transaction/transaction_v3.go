@@ -3,6 +3,7 @@ package transaction
 import (
 	"bytes"
 	"encoding/json"
+	"io"
 	"math/big"
 
 	"github.com/icon-project/goloop/common"
@@ -32,6 +33,67 @@ type transactionV3Data struct {
 	Signature common.Signature `json:"signature"`
 	DataType  *string          `json:"dataType,omitempty"`
 	Data      json.RawMessage  `json:"data,omitempty"`
+
+	// optional fields for the fee market (module.FeeMarket)
+	MaxStepPrice *common.HexInt `json:"maxStepPrice,omitempty"`
+	StepPriceTip *common.HexInt `json:"stepPriceTip,omitempty"`
+}
+
+func (tx *transactionV3Data) hasFeeMarketFields() bool {
+	return tx.MaxStepPrice != nil || tx.StepPriceTip != nil
+}
+
+// RLPEncodeSelf encodes fields for the fee market only if they are used,
+// so that the encoding of other transactions is not changed.
+func (tx *transactionV3Data) RLPEncodeSelf(e codec.Encoder) error {
+	e2, err := e.EncodeList()
+	if err != nil {
+		return err
+	}
+	if err = e2.EncodeMulti(
+		&tx.Version,
+		&tx.From,
+		&tx.To,
+		tx.Value,
+		&tx.StepLimit,
+		&tx.TimeStamp,
+		tx.NID,
+		tx.Nonce,
+		&tx.Signature,
+		tx.DataType,
+		tx.Data,
+	); err != nil {
+		return err
+	}
+	if tx.hasFeeMarketFields() {
+		return e2.EncodeMulti(tx.MaxStepPrice, tx.StepPriceTip)
+	}
+	return nil
+}
+
+func (tx *transactionV3Data) RLPDecodeSelf(d codec.Decoder) error {
+	d2, err := d.DecodeList()
+	if err != nil {
+		return err
+	}
+	if _, err = d2.DecodeMulti(
+		&tx.Version,
+		&tx.From,
+		&tx.To,
+		&tx.Value,
+		&tx.StepLimit,
+		&tx.TimeStamp,
+		&tx.NID,
+		&tx.Nonce,
+		&tx.Signature,
+		&tx.DataType,
+		&tx.Data,
+		&tx.MaxStepPrice,
+		&tx.StepPriceTip,
+	); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
 }
 
 func (tx *transactionV3Data) calcHash() ([]byte, error) {
@@ -65,6 +127,12 @@ func (tx *transactionV3Data) calcHash() ([]byte, error) {
 	sha.Write([]byte(".from."))
 	sha.Write([]byte(tx.From.String()))
 
+	// maxStepPrice
+	if tx.MaxStepPrice != nil {
+		sha.Write([]byte(".maxStepPrice."))
+		sha.Write([]byte(tx.MaxStepPrice.String()))
+	}
+
 	// nid
 	if tx.NID != nil {
 		sha.Write([]byte(".nid."))
@@ -81,6 +149,12 @@ func (tx *transactionV3Data) calcHash() ([]byte, error) {
 	sha.Write([]byte(".stepLimit."))
 	sha.Write([]byte(tx.StepLimit.String()))
 
+	// stepPriceTip
+	if tx.StepPriceTip != nil {
+		sha.Write([]byte(".stepPriceTip."))
+		sha.Write([]byte(tx.StepPriceTip.String()))
+	}
+
 	// timestamp
 	sha.Write([]byte(".timestamp."))
 	sha.Write([]byte(tx.TimeStamp.String()))
@@ -164,6 +238,12 @@ func (tx *transactionV3) Verify() error {
 	if tx.StepLimit.Sign() < 0 {
 		return InvalidTxValue.Errorf("InvalidTxStepLimit(%s)", tx.StepLimit.String())
 	}
+	if tx.MaxStepPrice != nil && tx.MaxStepPrice.Sign() < 0 {
+		return InvalidTxValue.Errorf("InvalidTxMaxStepPrice(%s)", tx.MaxStepPrice.String())
+	}
+	if tx.StepPriceTip != nil && tx.StepPriceTip.Sign() < 0 {
+		return InvalidTxValue.Errorf("InvalidTxStepPriceTip(%s)", tx.StepPriceTip.String())
+	}
 
 	// character level size of data element <= 512KB
 	n, err := countBytesOfCompactJSON(tx.Data)
@@ -243,6 +323,17 @@ func (tx *transactionV3) PreValidate(wc state.WorldContext, update bool) error {
 
 	// balance >= (fee + value)
 	stepPrice := wc.StepPrice()
+	if wc.Revision().Has(module.FeeMarket) {
+		if tx.Group() == module.TransactionGroupNormal {
+			tip, err := StepPriceTipFor(stepPrice, tx.MaxStepPrice.Value(), tx.StepPriceTip.Value())
+			if err != nil {
+				return err
+			}
+			stepPrice = new(big.Int).Add(stepPrice, tip)
+		}
+	} else if tx.hasFeeMarketFields() {
+		return InvalidTxValue.New("FeeMarketNotEnabled")
+	}
 
 	trans := new(big.Int).Mul(&tx.StepLimit.Int, stepPrice)
 	if tx.Value != nil {
@@ -284,7 +375,7 @@ func (tx *transactionV3) GetHandler(cm contract.ContractManager) (Handler, error
 	} else {
 		value = big.NewInt(0)
 	}
-	return NewHandler(cm,
+	th, err := newHandler(cm,
 		tx.Group(),
 		tx.From(),
 		tx.To(),
@@ -292,6 +383,12 @@ func (tx *transactionV3) GetHandler(cm contract.ContractManager) (Handler, error
 		&tx.StepLimit.Int,
 		tx.DataType,
 		tx.Data)
+	if err != nil {
+		return nil, err
+	}
+	th.maxStepPrice = tx.MaxStepPrice.Value()
+	th.stepPriceTip = tx.StepPriceTip.Value()
+	return th, nil
 }
 
 func (tx *transactionV3) Group() module.TransactionGroup {
@@ -374,6 +471,12 @@ func (tx *transactionV3) ToJSON(version module.JSONVersion) (interface{}, error)
 	if tx.transactionV3Data.Data != nil {
 		jso["data"] = json.RawMessage(tx.transactionV3Data.Data)
 	}
+	if tx.transactionV3Data.MaxStepPrice != nil {
+		jso["maxStepPrice"] = tx.transactionV3Data.MaxStepPrice
+	}
+	if tx.transactionV3Data.StepPriceTip != nil {
+		jso["stepPriceTip"] = tx.transactionV3Data.StepPriceTip
+	}
 	jso["txHash"] = common.HexBytes(tx.ID())
 
 	return jso, nil
@@ -0,0 +1,139 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transaction
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/wallet"
+)
+
+// legacyTransactionV3Data is the layout of transactionV3Data before
+// the fields for the fee market were added.
+type legacyTransactionV3Data struct {
+	Version   common.HexUint16
+	From      common.Address
+	To        common.Address
+	Value     *common.HexInt
+	StepLimit common.HexInt
+	TimeStamp common.HexInt64
+	NID       *common.HexInt64
+	Nonce     *common.HexInt
+	Signature common.Signature
+	DataType  *string
+	Data      json.RawMessage
+}
+
+func newSignedTransactionV3(t *testing.T, maxPrice, tip *common.HexInt) *transactionV3 {
+	w := wallet.New()
+	dataType := "call"
+	tx := new(transactionV3)
+	tx.transactionV3Data = transactionV3Data{
+		Version:      common.HexUint16{Value: 3},
+		From:         *common.AddressToPtr(w.Address()),
+		To:           *common.MustNewAddressFromString("cx0000000000000000000000000000000000000001"),
+		Value:        common.NewHexInt(10),
+		StepLimit:    *common.NewHexInt(100000),
+		TimeStamp:    common.HexInt64{Value: 1234},
+		NID:          &common.HexInt64{Value: 1},
+		DataType:     &dataType,
+		Data:         json.RawMessage(`{"method":"transfer"}`),
+		MaxStepPrice: maxPrice,
+		StepPriceTip: tip,
+	}
+	sig, err := w.Sign(tx.TxHash())
+	assert.NoError(t, err)
+	tx.Signature.Signature, err = crypto.ParseSignature(sig)
+	assert.NoError(t, err)
+	return tx
+}
+
+func TestTransactionV3_BytesWithoutFeeMarket(t *testing.T) {
+	tx := newSignedTransactionV3(t, nil, nil)
+	d := &tx.transactionV3Data
+	legacy := &legacyTransactionV3Data{
+		d.Version, d.From, d.To, d.Value, d.StepLimit, d.TimeStamp,
+		d.NID, d.Nonce, d.Signature, d.DataType, d.Data,
+	}
+	assert.Equal(t, codec.MustMarshalToBytes(legacy), tx.Bytes())
+
+	tx2, err := parseV3Binary(tx.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, tx.ID(), tx2.ID())
+	assert.NoError(t, tx2.Verify())
+}
+
+func TestTransactionV3_FeeMarketFields(t *testing.T) {
+	tx := newSignedTransactionV3(t, common.NewHexInt(200), common.NewHexInt(5))
+	noFields := newSignedTransactionV3(t, nil, nil)
+	assert.NotEqual(t, len(noFields.Bytes()), len(tx.Bytes()))
+
+	tx2, err := parseV3Binary(tx.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, tx.ID(), tx2.ID())
+	assert.NoError(t, tx2.Verify())
+	v3 := tx2.(*transactionV3)
+	assert.Equal(t, int64(200), v3.MaxStepPrice.Int64())
+	assert.Equal(t, int64(5), v3.StepPriceTip.Int64())
+
+	// hash of JSON should be same as the one of the object
+	js, err := tx.MarshalJSON()
+	assert.NoError(t, err)
+	tx3, err := parseV3JSON(js, false)
+	assert.NoError(t, err)
+	assert.False(t, tx3.(*transactionV3).raw)
+	assert.Equal(t, tx.ID(), tx3.ID())
+
+	tx.StepPriceTip = common.NewHexInt(-1)
+	assert.Error(t, tx.Verify())
+}
+
+func TestStepPriceTipFor(t *testing.T) {
+	base := big.NewInt(100)
+	cases := []struct {
+		name     string
+		maxPrice *big.Int
+		tip      *big.Int
+		expected int64
+		err      bool
+	}{
+		{"NoFields", nil, nil, 0, false},
+		{"TipOnly", nil, big.NewInt(30), 30, false},
+		{"UnderMax", big.NewInt(150), big.NewInt(30), 30, false},
+		{"LimitedByMax", big.NewInt(120), big.NewInt(30), 20, false},
+		{"MaxIsBase", big.NewInt(100), big.NewInt(30), 0, false},
+		{"TooLow", big.NewInt(99), nil, 0, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tip, err := StepPriceTipFor(base, c.maxPrice, c.tip)
+			if c.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, c.expected, tip.Int64())
+		})
+	}
+}
@@ -32,6 +32,10 @@ type transactionHandler struct {
 	dataType  *string
 	data      []byte
 
+	// optional step prices for the fee market
+	maxStepPrice *big.Int
+	stepPriceTip *big.Int
+
 	chandler contract.ContractHandler
 
 	// Assigned at Execute()
@@ -39,6 +43,10 @@ type transactionHandler struct {
 }
 
 func NewHandler(cm contract.ContractManager, group module.TransactionGroup, from, to module.Address, value, stepLimit *big.Int, dataType *string, data []byte) (Handler, error) {
+	return newHandler(cm, group, from, to, value, stepLimit, dataType, data)
+}
+
+func newHandler(cm contract.ContractManager, group module.TransactionGroup, from, to module.Address, value, stepLimit *big.Int, dataType *string, data []byte) (*transactionHandler, error) {
 	th := &transactionHandler{
 		group:     group,
 		from:      from,
@@ -80,8 +88,36 @@ func (th *transactionHandler) Prepare(ctx contract.Context) (state.WorldContext,
 	return th.chandler.Prepare(ctx)
 }
 
+// StepPriceTipFor returns the tip paid for each step on the base step price.
+// The tip is limited not to make the step price higher than maxPrice.
+// It returns an error if maxPrice is lower than the base step price.
+func StepPriceTipFor(base, maxPrice, tip *big.Int) (*big.Int, error) {
+	if tip == nil {
+		tip = new(big.Int)
+	}
+	if maxPrice == nil {
+		return tip, nil
+	}
+	if maxPrice.Cmp(base) < 0 {
+		return nil, scoreresult.InvalidParameterError.Errorf(
+			"StepPriceTooLow(max=%s,base=%s)", maxPrice, base)
+	}
+	if limit := new(big.Int).Sub(maxPrice, base); tip.Cmp(limit) > 0 {
+		return limit, nil
+	}
+	return tip, nil
+}
+
 func (th *transactionHandler) checkBalance(cc contract.CallContext) error {
-	value := new(big.Int).Mul(cc.StepPrice(), th.stepLimit)
+	stepPrice := cc.StepPrice()
+	if cc.Revision().Has(module.FeeMarket) {
+		tip, err := StepPriceTipFor(stepPrice, th.maxStepPrice, th.stepPriceTip)
+		if err != nil {
+			return err
+		}
+		stepPrice = new(big.Int).Add(stepPrice, tip)
+	}
+	value := new(big.Int).Mul(stepPrice, th.stepLimit)
 	if th.value != nil {
 		value.Add(value, th.value)
 	}
@@ -186,9 +222,21 @@ func (th *transactionHandler) Execute(ctx contract.Context, wcs state.WorldSnaps
 	// Try to charge fee
 	stepPrice := ctx.StepPrice()
 	stepUsed := cc.StepUsed()
+	feeMarket := !isPatch && ctx.Revision().Has(module.FeeMarket)
+	var tip *big.Int
 	if isPatch {
 		stepPrice = new(big.Int)
 		logger.TSystem("TRANSACTION reset stepPrice=0 msg=\"patch tx\"")
+	} else if feeMarket {
+		var err error
+		tip, err = StepPriceTipFor(stepPrice, th.maxStepPrice, th.stepPriceTip)
+		if err != nil {
+			// it fails, but the sender still pays for the steps it used
+			// at the highest price it allowed.
+			stepPrice = th.maxStepPrice
+			logger.TSystemf("TRANSACTION setprice price=%d reason=StepPriceTooLow base=%d",
+				stepPrice, ctx.StepPrice())
+		}
 	}
 	minSteps := big.NewInt(cc.StepsFor(state.StepTypeDefault, 1))
 	if stepUsed.Cmp(minSteps) == -1 {
@@ -219,7 +267,7 @@ func (th *transactionHandler) Execute(ctx contract.Context, wcs state.WorldSnaps
 	if stepToPay == nil {
 		logger.Debugf("MKSONG StepToPay is NIL")
 	}
-	fee := new(big.Int).Mul(stepToPay, stepPrice)
+	fee := new(big.Int).Mul(stepToPay, priceWithTip(stepPrice, tip))
 
 	as := ctx.GetAccountState(th.from.ID())
 	bal := as.GetBalance()
@@ -246,7 +294,7 @@ func (th *transactionHandler) Execute(ctx contract.Context, wcs state.WorldSnaps
 				logger.TSystemf("STEP rollback value=%d", stepUsed)
 				stepToPay = stepUsed
 			}
-			fee.Mul(stepToPay, stepPrice)
+			fee.Mul(stepToPay, priceWithTip(stepPrice, tip))
 		} else {
 			if redeemed != nil {
 				ctx.Reset(wcs)
@@ -258,6 +306,7 @@ func (th *transactionHandler) Execute(ctx contract.Context, wcs state.WorldSnaps
 			status = scoreresult.ErrOutOfBalance
 			logger.TSystemf("TRANSACTION setprice price=0 reason=OutOfBalance balance=%d fee=%d", bal, fee)
 			stepPrice = new(big.Int)
+			tip = nil
 			fee.SetInt64(0)
 		}
 	}
@@ -274,6 +323,14 @@ func (th *transactionHandler) Execute(ctx contract.Context, wcs state.WorldSnaps
 	if redeemed := cc.GetRedeemLogs(receipt); redeemed && stepToPay.Sign() != 0 {
 		receipt.AddPayment(th.from, stepToPay, stepToPay)
 	}
+	if feeMarket {
+		if tip == nil {
+			tip = new(big.Int)
+		}
+		burned := state.BurnedFeeOf(new(big.Int).Mul(stepToPay, stepPrice))
+		receipt.SetFeeMarket(tip, burned)
+		logger.TSystemf("TRANSACTION feemarket tip=%d burned=%d", tip, burned)
+	}
 	receipt.SetResult(s, stepUsed, stepPrice, addr)
 	receipt.SetReason(status)
 
@@ -281,6 +338,13 @@ func (th *transactionHandler) Execute(ctx contract.Context, wcs state.WorldSnaps
 	return receipt, nil
 }
 
+func priceWithTip(price, tip *big.Int) *big.Int {
+	if tip == nil || tip.Sign() == 0 {
+		return price
+	}
+	return new(big.Int).Add(price, tip)
+}
+
 func (th *transactionHandler) Dispose() {
 	// Actually it is called after calling Execute(), so cc can't be nil.
 	if th.cc != nil {
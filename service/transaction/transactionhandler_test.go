@@ -0,0 +1,227 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transaction
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/contract"
+	"github.com/icon-project/goloop/service/eeproxy"
+	"github.com/icon-project/goloop/service/scoredb"
+	"github.com/icon-project/goloop/service/state"
+	"github.com/icon-project/goloop/service/trace"
+)
+
+const (
+	testBaseStepPrice  = 10
+	testDefaultSteps   = 100
+	testContractSteps  = 1000
+	testInitialBalance = 1_000_000_000
+)
+
+type feeMarketPlatform struct{}
+
+func (feeMarketPlatform) ToRevision(value int) module.Revision {
+	return module.LatestRevision
+}
+
+type testChain struct {
+	module.Chain
+}
+
+func (c *testChain) TransactionTimeout() time.Duration {
+	return 5 * time.Second
+}
+
+// testContractHandler uses steps, and lets the system deposit pay for
+// the steps if useDeposit is true.
+type testContractHandler struct {
+	useDeposit bool
+}
+
+func (h *testContractHandler) Prepare(ctx contract.Context) (state.WorldContext, error) {
+	return ctx, nil
+}
+
+func (h *testContractHandler) SetTraceLogger(logger *trace.Logger) {
+	// do nothing
+}
+
+func (h *testContractHandler) TraceLogger() *trace.Logger {
+	return nil
+}
+
+func (h *testContractHandler) ExecuteSync(cc contract.CallContext) (error, *codec.TypedObj, module.Address) {
+	cc.DeductSteps(big.NewInt(testContractSteps))
+	if h.useDeposit {
+		cc.SetFeeProportion(state.SystemAddress, 100)
+	}
+	return nil, nil, nil
+}
+
+func newFeeMarketContext(t *testing.T) contract.Context {
+	ws := state.NewWorldState(db.NewMapDB(), nil, nil, nil, nil)
+	as := ws.GetAccountState(state.SystemID)
+	assert.NoError(t, scoredb.NewVarDB(as, state.VarStepPrice).Set(testBaseStepPrice))
+	assert.NoError(t, scoredb.NewArrayDB(as, state.VarStepTypes).Put(state.StepTypeDefault))
+	assert.NoError(t, scoredb.NewDictDB(as, state.VarStepCosts, 1).Set(state.StepTypeDefault, testDefaultSteps))
+	assert.NoError(t, scoredb.NewArrayDB(as, state.VarStepLimitTypes).Put(state.StepLimitTypeInvoke))
+	assert.NoError(t, scoredb.NewDictDB(as, state.VarStepLimit, 1).Set(state.StepLimitTypeInvoke, 1_000_000))
+	assert.NoError(t, scoredb.NewVarDB(as, state.VarServiceConfig).Set(state.SysConfigFeeSharing))
+
+	wc := state.NewWorldContext(ws, common.NewBlockInfo(1, 1), nil, feeMarketPlatform{})
+	assert.True(t, wc.Revision().Has(module.FeeMarket))
+	wc.SetTransactionInfo(&state.TransactionInfo{
+		Group: module.TransactionGroupNormal,
+		Hash:  []byte{0x01},
+	})
+	return contract.NewContext(wc, nil, nil, &testChain{}, log.New(), nil, eeproxy.ForTransaction)
+}
+
+func TestTransactionHandler_ExecuteWithFeeMarket(t *testing.T) {
+	from := common.MustNewAddressFromString("hx0000000000000000000000000000000000000001")
+	to := common.MustNewAddressFromString("hx0000000000000000000000000000000000000002")
+	usedSteps := int64(testDefaultSteps + testContractSteps)
+
+	cases := []struct {
+		name       string
+		maxPrice   *big.Int
+		tip        *big.Int
+		useDeposit bool
+
+		status  module.Status
+		steps   int64
+		price   int64
+		tipPaid int64
+		fee     int64
+		burned  int64
+	}{
+		{
+			name:     "TipUnderMax",
+			maxPrice: big.NewInt(20),
+			tip:      big.NewInt(5),
+			status:   module.StatusSuccess,
+			steps:    usedSteps,
+			price:    testBaseStepPrice,
+			tipPaid:  5,
+			fee:      usedSteps * (testBaseStepPrice + 5),
+			burned:   usedSteps * testBaseStepPrice / 2,
+		},
+		{
+			name:     "TipLimitedByMax",
+			maxPrice: big.NewInt(12),
+			tip:      big.NewInt(5),
+			status:   module.StatusSuccess,
+			steps:    usedSteps,
+			price:    testBaseStepPrice,
+			tipPaid:  2,
+			fee:      usedSteps * 12,
+			burned:   usedSteps * testBaseStepPrice / 2,
+		},
+		{
+			// it fails before execution, and pays for the minimum steps
+			// at the max step price.
+			name:     "MaxPriceTooLow",
+			maxPrice: big.NewInt(8),
+			tip:      big.NewInt(1),
+			status:   module.StatusInvalidParameter,
+			steps:    testDefaultSteps,
+			price:    8,
+			tipPaid:  0,
+			fee:      testDefaultSteps * 8,
+			burned:   testDefaultSteps * 8 / 2,
+		},
+		{
+			// steps paid by the deposit pay neither the tip nor the burned fee.
+			name:       "PaidByDeposit",
+			maxPrice:   big.NewInt(20),
+			tip:        big.NewInt(5),
+			useDeposit: true,
+			status:     module.StatusSuccess,
+			steps:      usedSteps,
+			price:      testBaseStepPrice,
+			tipPaid:    5,
+			fee:        0,
+			burned:     0,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := newFeeMarketContext(t)
+			ctx.GetAccountState(from.ID()).SetBalance(big.NewInt(testInitialBalance))
+			wcs := ctx.GetSnapshot()
+
+			th := &transactionHandler{
+				group:        module.TransactionGroupNormal,
+				from:         from,
+				to:           to,
+				value:        new(big.Int),
+				stepLimit:    big.NewInt(100_000),
+				maxStepPrice: c.maxPrice,
+				stepPriceTip: c.tip,
+				chandler:     &testContractHandler{useDeposit: c.useDeposit},
+			}
+			r, err := th.Execute(ctx, wcs, false)
+			th.Dispose()
+			assert.NoError(t, err)
+
+			assert.Equal(t, c.status, r.Status())
+			assert.EqualValues(t, c.steps, r.StepUsed().Int64())
+			assert.EqualValues(t, c.price, r.StepPrice().Int64())
+			assert.EqualValues(t, c.tipPaid, r.StepPriceTip().Int64())
+			assert.EqualValues(t, c.burned, r.BurnedFee().Int64())
+			assert.EqualValues(t, c.fee, r.FeeByEOA().Int64())
+
+			balance := ctx.GetAccountState(from.ID()).GetBalance()
+			assert.EqualValues(t, testInitialBalance-c.fee, balance.Int64())
+		})
+	}
+}
+
+func TestTransactionHandler_ExecuteWithoutFeeMarketFields(t *testing.T) {
+	from := common.MustNewAddressFromString("hx0000000000000000000000000000000000000001")
+	to := common.MustNewAddressFromString("hx0000000000000000000000000000000000000002")
+	ctx := newFeeMarketContext(t)
+	ctx.GetAccountState(from.ID()).SetBalance(big.NewInt(testInitialBalance))
+
+	// transactions without the fields pay the base step price
+	th := &transactionHandler{
+		group:     module.TransactionGroupNormal,
+		from:      from,
+		to:        to,
+		value:     new(big.Int),
+		stepLimit: big.NewInt(100_000),
+		chandler:  new(testContractHandler),
+	}
+	r, err := th.Execute(ctx, ctx.GetSnapshot(), false)
+	th.Dispose()
+	assert.NoError(t, err)
+	assert.Equal(t, module.StatusSuccess, r.Status())
+	fee := int64(testDefaultSteps+testContractSteps) * testBaseStepPrice
+	assert.EqualValues(t, fee, r.FeeByEOA().Int64())
+	assert.EqualValues(t, 0, r.StepPriceTip().Sign())
+	assert.EqualValues(t, fee/2, r.BurnedFee().Int64())
+}
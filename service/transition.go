@@ -677,6 +677,7 @@ func (t *transition) doExecute(alreadyValidated bool) {
 	}
 	cumulativeSteps := big.NewInt(0)
 	gatheredFee := big.NewInt(0)
+	burnedFee := new(big.Int)
 	virtualFee := new(big.Int)
 	btpMsgs := list.New()
 
@@ -694,6 +695,7 @@ func (t *transition) doExecute(alreadyValidated bool) {
 			} else {
 				gatheredFee.Add(gatheredFee, r.FeeByEOA())
 			}
+			burnedFee.Add(burnedFee, r.BurnedFee())
 
 			t.logsBloom.Merge(r.LogsBloom())
 
@@ -705,12 +707,20 @@ func (t *transition) doExecute(alreadyValidated bool) {
 	t.patchReceipts = txresult.NewReceiptListFromSlice(t.db, patchReceipts)
 	t.normalReceipts = txresult.NewReceiptListFromSlice(t.db, normalReceipts)
 
-	// save gathered fee to treasury
-	tr := ctx.GetAccountState(ctx.Treasury().ID())
-	tb := tr.GetBalance()
-	tr.SetBalance(new(big.Int).Add(tb, gatheredFee))
+	if ctx.Revision().Has(module.FeeMarket) {
+		if err := t.updateBaseStepPrice(ctx); err != nil {
+			t.reportExecution(err)
+			return
+		}
+	}
 
-	er := NewExecutionResult(t.patchReceipts, t.normalReceipts, virtualFee, gatheredFee)
+	treasuryFee, err := distributeFee(ctx, gatheredFee, burnedFee)
+	if err != nil {
+		t.reportExecution(err)
+		return
+	}
+
+	er := NewExecutionResult(t.patchReceipts, t.normalReceipts, virtualFee, treasuryFee)
 	if err = t.onPlatformExecutionEnd(ctx, er); err != nil {
 		t.reportExecution(err)
 		return
@@ -757,6 +767,49 @@ func (t *transition) onPlatformExecutionEnd(ctx contract.Context, er base.Execut
 	return t.plt.OnExecutionEnd(ctx, er, ctx.GetTraceLogger(module.EPhaseExecutionEnd))
 }
 
+// updateBaseStepPrice updates the base step price for the next block with
+// the size of normal transactions in the block.
+func (t *transition) updateBaseStepPrice(wc state.WorldContext) error {
+	var used int64
+	if t.normalTransactions != nil {
+		for i := t.normalTransactions.Iterator(); i.Has(); i.Next() {
+			tx, _, err := i.Get()
+			if err != nil {
+				return errors.Wrap(err, "updateBaseStepPrice: fail to get transaction")
+			}
+			used += int64(len(tx.Bytes()))
+		}
+	}
+	return state.UpdateBaseStepPrice(wc, used)
+}
+
+// distributeFee burns the burned fee, and saves the rest of the gathered
+// fee to the treasury. It returns the fee saved to the treasury.
+func distributeFee(wc state.WorldContext, gathered, burned *big.Int) (*big.Int, error) {
+	fee := new(big.Int).Set(gathered)
+	if burned.Sign() > 0 {
+		if err := burnFee(wc, burned); err != nil {
+			return nil, err
+		}
+		fee.Sub(fee, burned)
+	}
+	tr := wc.GetAccountState(wc.Treasury().ID())
+	tr.SetBalance(new(big.Int).Add(tr.GetBalance(), fee))
+	return fee, nil
+}
+
+// burnFee removes the burned fee from the total supply.
+func burnFee(wc state.WorldContext, amount *big.Int) error {
+	as := wc.GetAccountState(state.SystemID)
+	tsVar := scoredb.NewVarDB(as, state.VarTotalSupply)
+	ts := tsVar.BigInt()
+	if ts == nil || ts.Cmp(amount) < 0 {
+		return errors.InvalidStateError.Errorf(
+			"InvalidTotalSupply(supply=%s,burned=%s)", ts, amount)
+	}
+	return tsVar.Set(new(big.Int).Sub(ts, amount))
+}
+
 func (t *transition) validateTxs(l module.TransactionList, wc state.WorldContext, tsr TimestampRange) error {
 	if l == nil {
 		return nil
@@ -17,9 +17,17 @@
 package service
 
 import (
+	"math/big"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/scoredb"
+	"github.com/icon-project/goloop/service/state"
+	"github.com/icon-project/goloop/service/txresult"
 )
 
 func TestTransitionID(t *testing.T) {
@@ -27,3 +35,47 @@ func TestTransitionID(t *testing.T) {
 	id2 := new(transitionID)
 	assert.False(t, id1 == id2)
 }
+
+type feeMarketPlatform struct{}
+
+func (feeMarketPlatform) ToRevision(value int) module.Revision {
+	return module.LatestRevision
+}
+
+func TestDistributeFee(t *testing.T) {
+	dbase := db.NewMapDB()
+	ws := state.NewWorldState(dbase, nil, nil, nil, nil)
+	as := ws.GetAccountState(state.SystemID)
+	assert.NoError(t, scoredb.NewVarDB(as, state.VarTotalSupply).Set(1_000_000))
+	wc := state.NewWorldContext(ws, common.NewBlockInfo(1, 1), nil, feeMarketPlatform{})
+
+	// receipts with the fee market pay the tip, and a half of the base
+	// fee is burned.
+	to := common.MustNewAddressFromString("hx0000000000000000000000000000000000000001")
+	r1 := txresult.NewReceipt(dbase, wc.Revision(), to)
+	r1.SetResult(module.StatusSuccess, big.NewInt(1000), big.NewInt(10), nil)
+	r1.SetFeeMarket(big.NewInt(2), state.BurnedFeeOf(big.NewInt(1000*10)))
+	r2 := txresult.NewReceipt(dbase, wc.Revision(), to)
+	r2.SetResult(module.StatusSuccess, big.NewInt(100), big.NewInt(10), nil)
+
+	gathered, burned := new(big.Int), new(big.Int)
+	for _, r := range []txresult.Receipt{r1, r2} {
+		gathered.Add(gathered, r.Fee())
+		burned.Add(burned, r.BurnedFee())
+	}
+	assert.EqualValues(t, 1000*12+100*10, gathered.Int64())
+	assert.EqualValues(t, 1000*10/2, burned.Int64())
+
+	fee, err := distributeFee(wc, gathered, burned)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1000*12+100*10-1000*10/2, fee.Int64())
+	treasury := wc.GetAccountState(wc.Treasury().ID()).GetBalance()
+	assert.Equal(t, 0, fee.Cmp(treasury))
+	supply := scoredb.NewVarDB(as, state.VarTotalSupply).BigInt()
+	assert.EqualValues(t, 1_000_000-1000*10/2, supply.Int64())
+
+	// it fails if it burns more than the total supply
+	_, err = distributeFee(wc, gathered, big.NewInt(1_000_000))
+	assert.Error(t, err)
+	assert.Equal(t, 0, treasury.Cmp(wc.GetAccountState(wc.Treasury().ID()).GetBalance()))
+}
@@ -150,3 +150,36 @@ func BTPDigestHashFromResult(result []byte) ([]byte, error) {
 	}
 	return r.BTPData, nil
 }
+
+// ForkResult returns the result with the network ID and the chain ID
+// replaced. If forkExtension isn't nil, the extension data is replaced with
+// the one returned by it. The modified state is written to the database.
+// It's used to make the last block of a chain forked from the result.
+func ForkResult(
+	dbase db.Database, result []byte, nid, cid int,
+	forkExtension func(dbase db.Database, ed []byte) ([]byte, error),
+) ([]byte, error) {
+	tr, err := newTransitionResultFromBytes(result)
+	if err != nil {
+		return nil, err
+	}
+	if forkExtension != nil {
+		if tr.ExtensionData, err = forkExtension(dbase, tr.ExtensionData); err != nil {
+			return nil, err
+		}
+	}
+	ws := state.NewWorldState(dbase, tr.StateHash, nil, nil, tr.BTPData)
+	as := ws.GetAccountState(state.SystemID)
+	if err := scoredb.NewVarDB(as, state.VarNetwork).Set(nid); err != nil {
+		return nil, err
+	}
+	if err := scoredb.NewVarDB(as, state.VarChainID).Set(cid); err != nil {
+		return nil, err
+	}
+	wss := ws.GetSnapshot()
+	if err := wss.Flush(); err != nil {
+		return nil, err
+	}
+	tr.StateHash = wss.StateHash()
+	return tr.Bytes(), nil
+}
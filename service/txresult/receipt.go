@@ -172,6 +172,7 @@ const (
 const (
 	ExtensionFeeDetail = 1 << iota
 	ExtensionDisableLogsBloom
+	ExtensionFeeMarket
 )
 
 type receiptData struct {
@@ -185,6 +186,8 @@ type receiptData struct {
 	SCOREAddress       *common.Address
 	FeeDetail          feeDetail
 	DisableLogsBloom   bool
+	StepPriceTip       *common.HexInt
+	BurnedFee          *common.HexInt
 }
 
 func (r *receiptData) Equal(r2 *receiptData) bool {
@@ -196,9 +199,18 @@ func (r *receiptData) Equal(r2 *receiptData) bool {
 		r.LogsBloom.Equal(&r2.LogsBloom) &&
 		r.SCOREAddress.Equal(r2.SCOREAddress) &&
 		r.DisableLogsBloom == r2.DisableLogsBloom &&
+		equalHexInt(r.StepPriceTip, r2.StepPriceTip) &&
+		equalHexInt(r.BurnedFee, r2.BurnedFee) &&
 		reflect.DeepEqual(r.FeeDetail, r2.FeeDetail)
 }
 
+func equalHexInt(v1, v2 *common.HexInt) bool {
+	if v1 == nil || v2 == nil {
+		return v1 == v2
+	}
+	return v1.Cmp(&v2.Int) == 0
+}
+
 func (r *receiptData) Extension() int {
 	var extension int
 	if r.FeeDetail.Has() {
@@ -207,6 +219,9 @@ func (r *receiptData) Extension() int {
 	if r.DisableLogsBloom {
 		extension |= ExtensionDisableLogsBloom
 	}
+	if r.StepPriceTip != nil {
+		extension |= ExtensionFeeMarket
+	}
 	return extension
 }
 
@@ -318,6 +333,11 @@ func (r *receipt) RLPEncodeSelf(e codec.Encoder) error {
 				return err
 			}
 		}
+		if (extension & ExtensionFeeMarket) != 0 {
+			if err = e2.EncodeMulti(r.data.StepPriceTip, r.data.BurnedFee); err != nil {
+				return err
+			}
+		}
 		return nil
 	}
 }
@@ -353,6 +373,11 @@ func (r *receipt) RLPDecodeSelf(d codec.Decoder) error {
 					return err
 				}
 			}
+			if (extension & ExtensionFeeMarket) != 0 {
+				if err := d2.DecodeAll(&r.data.StepPriceTip, &r.data.BurnedFee); err != nil {
+					return err
+				}
+			}
 		} else {
 			return codec.ErrInvalidFormat
 		}
@@ -431,6 +456,15 @@ func (r *receipt) AddPayment(addr module.Address, steps *big.Int, feeSteps *big.
 	}
 }
 
+// SetFeeMarket sets the tip for each step and the amount of burned fee.
+func (r *receipt) SetFeeMarket(tip, burned *big.Int) {
+	r.data.StepPriceTip = common.NewHexInt(0).SetValue(tip)
+	r.data.BurnedFee = common.NewHexInt(0).SetValue(burned)
+	if r.version < Version3 {
+		r.version = Version3
+	}
+}
+
 func (r *receipt) DisableLogsBloom() {
 	r.data.DisableLogsBloom = true
 	r.data.LogsBloom.SetBytes(nil)
@@ -516,6 +550,11 @@ type Receipt interface {
 	FeeByEOA() *big.Int
 	// Fee returns total fee (excluding virtual steps).
 	Fee() *big.Int
+	// StepPriceTip returns the tip paid by EOA for each step.
+	StepPriceTip() *big.Int
+	// BurnedFee returns the amount of fee to be burned.
+	BurnedFee() *big.Int
+	SetFeeMarket(tip, burned *big.Int)
 	DisableLogsBloom()
 	SetCumulativeStepUsed(cumulativeUsed *big.Int)
 	SetResult(status module.Status, used, price *big.Int, addr module.Address)
@@ -535,6 +574,8 @@ type receiptJSON struct {
 	LogsBloom          *LogsBloom       `json:"logsBloom"`
 	Status             common.HexUint16 `json:"status"`
 	FeeDetail          feeDetail        `json:"stepUsedDetails,omitempty"`
+	StepPriceTip       *common.HexInt   `json:"stepPriceTip,omitempty"`
+	BurnedFee          *common.HexInt   `json:"burnedFee,omitempty"`
 }
 
 func (r *receipt) ToJSON(version module.JSONVersion) (interface{}, error) {
@@ -567,6 +608,11 @@ func (r *receipt) ToJSON(version module.JSONVersion) (interface{}, error) {
 		jso["stepUsedDetails"] = details
 	}
 
+	if r.data.StepPriceTip != nil {
+		jso["stepPriceTip"] = r.data.StepPriceTip
+		jso["burnedFee"] = r.data.BurnedFee
+	}
+
 	if r.data.Status == module.StatusSuccess {
 		jso["status"] = "0x1"
 		if r.data.SCOREAddress != nil {
@@ -619,6 +665,13 @@ func (r *receipt) UnmarshalJSON(bs []byte) error {
 		data.DisableLogsBloom = true
 	}
 	data.FeeDetail = rjson.FeeDetail
+	if rjson.StepPriceTip != nil {
+		data.StepPriceTip = rjson.StepPriceTip
+		data.BurnedFee = rjson.BurnedFee
+		if data.BurnedFee == nil {
+			data.BurnedFee = common.NewHexInt(0)
+		}
+	}
 	if r.data.Extension() != 0 && r.version < Version3 {
 		r.version = Version3
 	}
@@ -725,11 +778,36 @@ func (r *receipt) Fee() *big.Int {
 	} else {
 		feeSteps = r.data.StepUsed.Value()
 	}
-	return new(big.Int).Mul(feeSteps, r.data.StepPrice.Value())
+	fee := new(big.Int).Mul(feeSteps, r.data.StepPrice.Value())
+	return fee.Add(fee, r.tipByEOA())
 }
 
 func (r *receipt) FeeByEOA() *big.Int {
-	return new(big.Int).Mul(r.stepsPaidByEOA(), r.data.StepPrice.Value())
+	fee := new(big.Int).Mul(r.stepsPaidByEOA(), r.data.StepPrice.Value())
+	return fee.Add(fee, r.tipByEOA())
+}
+
+// tipByEOA returns the tip paid by EOA, which isn't paid for the steps
+// covered by the deposit.
+func (r *receipt) tipByEOA() *big.Int {
+	if r.data.StepPriceTip == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Mul(r.stepsPaidByEOA(), r.data.StepPriceTip.Value())
+}
+
+func (r *receipt) StepPriceTip() *big.Int {
+	if r.data.StepPriceTip == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(r.data.StepPriceTip.Value())
+}
+
+func (r *receipt) BurnedFee() *big.Int {
+	if r.data.BurnedFee == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(r.data.BurnedFee.Value())
 }
 
 func (r *receipt) Status() module.Status {
@@ -254,3 +254,56 @@ func TestDecomposeEventSignature(t *testing.T) {
 		})
 	}
 }
+
+func TestReceipt_FeeMarket(t *testing.T) {
+	dbase := db.NewMapDB()
+	eoa1 := common.MustNewAddressFromString("hx9834234")
+	contract1 := common.MustNewAddressFromString("cx0000000000000000000000000000000000000001")
+
+	r := NewReceipt(dbase, module.LatestRevision, contract1)
+	stepPrice := big.NewInt(100)
+	tip := big.NewInt(10)
+	stepByDeposit := big.NewInt(200)
+	stepByEOA := big.NewInt(800)
+	stepUsed := new(big.Int).Add(stepByDeposit, stepByEOA)
+	burned := big.NewInt(40000)
+
+	r.AddPayment(contract1, stepByDeposit, stepByDeposit)
+	r.AddPayment(eoa1, stepByEOA, stepByEOA)
+	r.SetFeeMarket(tip, burned)
+	r.SetResult(module.StatusSuccess, stepUsed, stepPrice, nil)
+
+	feeByEOA := new(big.Int).Mul(stepByEOA, new(big.Int).Add(stepPrice, tip))
+	fee := new(big.Int).Add(feeByEOA, new(big.Int).Mul(stepByDeposit, stepPrice))
+	assert.Equal(t, fee, r.Fee())
+	assert.Equal(t, feeByEOA, r.FeeByEOA())
+	assert.Equal(t, tip, r.StepPriceTip())
+	assert.Equal(t, burned, r.BurnedFee())
+	assert.NoError(t, r.Flush())
+
+	// json marshalling test
+	jb, err := json.Marshal(r)
+	assert.NoError(t, err)
+	var jso map[string]interface{}
+	assert.NoError(t, json.Unmarshal(jb, &jso))
+	assert.Equal(t, "0xa", jso["stepPriceTip"])
+	assert.Equal(t, "0x9c40", jso["burnedFee"])
+
+	r2, err := NewReceiptFromJSON(dbase, module.LatestRevision, jb)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Check(r2))
+
+	// binary marshalling test
+	r3 := new(receipt)
+	assert.NoError(t, r3.Reset(dbase, r.Bytes()))
+	assert.NoError(t, r.Check(r3))
+	assert.Equal(t, tip, r3.StepPriceTip())
+	assert.Equal(t, burned, r3.BurnedFee())
+
+	// receipt without fee market
+	r4 := NewReceipt(dbase, module.LatestRevision, contract1)
+	r4.SetResult(module.StatusSuccess, stepUsed, stepPrice, nil)
+	assert.Error(t, r.Check(r4))
+	assert.Equal(t, 0, r4.BurnedFee().Sign())
+	assert.Equal(t, 0, r4.StepPriceTip().Sign())
+}
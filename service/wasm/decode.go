@@ -0,0 +1,604 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package wasm
+
+import (
+	"bytes"
+	"math"
+	"unicode/utf8"
+
+	"github.com/icon-project/goloop/service/scoreresult"
+)
+
+const (
+	magic   = "\x00asm"
+	version = "\x01\x00\x00\x00"
+)
+
+const (
+	sectionCustom byte = iota
+	sectionType
+	sectionImport
+	sectionFunction
+	sectionTable
+	sectionMemory
+	sectionGlobal
+	sectionExport
+	sectionStart
+	sectionElement
+	sectionCode
+	sectionData
+)
+
+const (
+	typeFunc    = 0x60
+	typeFuncRef = 0x70
+	blockEmpty  = 0x40
+)
+
+type reader struct {
+	bs  []byte
+	pos int
+}
+
+func errFormat(format string, args ...interface{}) error {
+	return scoreresult.IllegalFormatError.Errorf(format, args...)
+}
+
+func (r *reader) eof() bool {
+	return r.pos >= len(r.bs)
+}
+
+func (r *reader) readByte() (byte, error) {
+	if r.pos >= len(r.bs) {
+		return 0, errFormat("UnexpectedEnd(pos=%d)", r.pos)
+	}
+	b := r.bs[r.pos]
+	r.pos++
+	return b, nil
+}
+
+func (r *reader) readBytes(n int) ([]byte, error) {
+	if n < 0 || r.pos+n > len(r.bs) {
+		return nil, errFormat("UnexpectedEnd(pos=%d,size=%d)", r.pos, n)
+	}
+	bs := r.bs[r.pos : r.pos+n]
+	r.pos += n
+	return bs, nil
+}
+
+func readULEB(bs []byte, pos int, bits uint) (uint64, int, error) {
+	var v uint64
+	var shift uint
+	for {
+		if pos >= len(bs) {
+			return 0, pos, errFormat("UnexpectedEnd(pos=%d)", pos)
+		}
+		b := bs[pos]
+		pos++
+		if shift+7 >= bits && (b&0x7f)>>(bits-shift) != 0 {
+			return 0, pos, errFormat("IntegerTooLarge(pos=%d)", pos)
+		}
+		v |= uint64(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return v, pos, nil
+		}
+		shift += 7
+		if shift >= bits {
+			return 0, pos, errFormat("IntegerTooLong(pos=%d)", pos)
+		}
+	}
+}
+
+func readSLEB(bs []byte, pos int, bits uint) (int64, int, error) {
+	var v int64
+	var shift uint
+	for {
+		if pos >= len(bs) {
+			return 0, pos, errFormat("UnexpectedEnd(pos=%d)", pos)
+		}
+		b := bs[pos]
+		pos++
+		if shift == 63 {
+			// the last byte of 64 bits integer has only the sign bit.
+			if b != 0x00 && b != 0x7f {
+				return 0, pos, errFormat("IntegerTooLarge(pos=%d)", pos)
+			}
+			v |= int64(b&1) << 63
+			return v, pos, nil
+		}
+		v |= int64(b&0x7f) << shift
+		shift += 7
+		if b&0x80 == 0 {
+			if b&0x40 != 0 {
+				v |= -1 << shift
+			}
+			if bits == 32 && (v < math.MinInt32 || v > math.MaxInt32) {
+				return 0, pos, errFormat("IntegerTooLarge(pos=%d)", pos)
+			}
+			return v, pos, nil
+		}
+		if bits == 32 && shift >= 35 {
+			return 0, pos, errFormat("IntegerTooLong(pos=%d)", pos)
+		}
+	}
+}
+
+func (r *reader) readU32() (uint32, error) {
+	v, pos, err := readULEB(r.bs, r.pos, 32)
+	r.pos = pos
+	return uint32(v), err
+}
+
+func (r *reader) readSize(limit int) (int, error) {
+	v, err := r.readU32()
+	if err != nil {
+		return 0, err
+	}
+	if int(v) > limit {
+		return 0, errFormat("TooManyItems(pos=%d,n=%d)", r.pos, v)
+	}
+	return int(v), nil
+}
+
+func (r *reader) readName() (string, error) {
+	n, err := r.readSize(len(r.bs) - r.pos)
+	if err != nil {
+		return "", err
+	}
+	bs, err := r.readBytes(n)
+	if err != nil {
+		return "", err
+	}
+	if !utf8.Valid(bs) {
+		return "", errFormat("InvalidName(pos=%d)", r.pos)
+	}
+	return string(bs), nil
+}
+
+func (r *reader) readValueType() (ValueType, error) {
+	b, err := r.readByte()
+	if err != nil {
+		return 0, err
+	}
+	switch t := ValueType(b); t {
+	case I32, I64:
+		return t, nil
+	case F32, F64:
+		return 0, errFormat("UnsupportedType(type=%s)", t)
+	default:
+		return 0, errFormat("InvalidValueType(type=%#x)", b)
+	}
+}
+
+func (r *reader) readLimits(max uint32) (*Limits, error) {
+	flag, err := r.readByte()
+	if err != nil {
+		return nil, err
+	}
+	l := new(Limits)
+	if l.Min, err = r.readU32(); err != nil {
+		return nil, err
+	}
+	switch flag {
+	case 0:
+	case 1:
+		if l.Max, err = r.readU32(); err != nil {
+			return nil, err
+		}
+		l.HasMax = true
+		if l.Max < l.Min {
+			return nil, errFormat("InvalidLimits(min=%d,max=%d)", l.Min, l.Max)
+		}
+	default:
+		return nil, errFormat("InvalidLimitsFlag(flag=%#x)", flag)
+	}
+	if l.Min > max {
+		return nil, errFormat("TooLargeLimits(min=%d,limit=%d)", l.Min, max)
+	}
+	return l, nil
+}
+
+// readConstExpr reads an initializer expression. Only constants are
+// allowed.
+func (r *reader) readConstExpr(t ValueType) (uint64, error) {
+	op, err := r.readByte()
+	if err != nil {
+		return 0, err
+	}
+	var v uint64
+	switch {
+	case op == opI32Const && t == I32:
+		var sv int64
+		sv, r.pos, err = readSLEB(r.bs, r.pos, 32)
+		v = uint64(uint32(sv))
+	case op == opI64Const && t == I64:
+		var sv int64
+		sv, r.pos, err = readSLEB(r.bs, r.pos, 64)
+		v = uint64(sv)
+	default:
+		return 0, errFormat("InvalidConstExpr(op=%#x,type=%s)", op, t)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if end, err := r.readByte(); err != nil {
+		return 0, err
+	} else if end != opEnd {
+		return 0, errFormat("InvalidConstExpr(end=%#x)", end)
+	}
+	return v, nil
+}
+
+func (m *Module) decodeTypes(r *reader) error {
+	n, err := r.readSize(len(r.bs))
+	if err != nil {
+		return err
+	}
+	m.Types = make([]FuncType, n)
+	for i := range m.Types {
+		if b, err := r.readByte(); err != nil {
+			return err
+		} else if b != typeFunc {
+			return errFormat("InvalidFuncType(form=%#x)", b)
+		}
+		np, err := r.readSize(MaxLocals)
+		if err != nil {
+			return err
+		}
+		params := make([]ValueType, np)
+		for j := range params {
+			if params[j], err = r.readValueType(); err != nil {
+				return err
+			}
+		}
+		nr, err := r.readSize(1)
+		if err != nil {
+			return err
+		}
+		results := make([]ValueType, nr)
+		for j := range results {
+			if results[j], err = r.readValueType(); err != nil {
+				return err
+			}
+		}
+		m.Types[i] = FuncType{Params: params, Results: results}
+	}
+	return nil
+}
+
+func (m *Module) decodeImports(r *reader) error {
+	n, err := r.readSize(len(r.bs))
+	if err != nil {
+		return err
+	}
+	m.Imports = make([]Import, n)
+	for i := range m.Imports {
+		imp := &m.Imports[i]
+		if imp.Module, err = r.readName(); err != nil {
+			return err
+		}
+		if imp.Name, err = r.readName(); err != nil {
+			return err
+		}
+		kind, err := r.readByte()
+		if err != nil {
+			return err
+		}
+		if ExternalKind(kind) != ExternalFunction {
+			return errFormat("UnsupportedImport(name=%s.%s,kind=%d)",
+				imp.Module, imp.Name, kind)
+		}
+		if imp.Type, err = r.readU32(); err != nil {
+			return err
+		}
+		if int(imp.Type) >= len(m.Types) {
+			return errFormat("InvalidTypeIndex(idx=%d)", imp.Type)
+		}
+	}
+	return nil
+}
+
+func (m *Module) decodeFunctions(r *reader) error {
+	n, err := r.readSize(len(r.bs))
+	if err != nil {
+		return err
+	}
+	m.Functions = make([]Function, n)
+	for i := range m.Functions {
+		if m.Functions[i].Type, err = r.readU32(); err != nil {
+			return err
+		}
+		if int(m.Functions[i].Type) >= len(m.Types) {
+			return errFormat("InvalidTypeIndex(idx=%d)", m.Functions[i].Type)
+		}
+	}
+	return nil
+}
+
+func (m *Module) decodeTable(r *reader) error {
+	n, err := r.readSize(1)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return nil
+	}
+	if b, err := r.readByte(); err != nil {
+		return err
+	} else if b != typeFuncRef {
+		return errFormat("InvalidElementType(type=%#x)", b)
+	}
+	m.Table, err = r.readLimits(MaxTableSize)
+	return err
+}
+
+func (m *Module) decodeMemory(r *reader) error {
+	n, err := r.readSize(1)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return nil
+	}
+	m.Memory, err = r.readLimits(MaxPages)
+	return err
+}
+
+func (m *Module) decodeGlobals(r *reader) error {
+	n, err := r.readSize(len(r.bs))
+	if err != nil {
+		return err
+	}
+	m.Globals = make([]Global, n)
+	for i := range m.Globals {
+		g := &m.Globals[i]
+		if g.Type, err = r.readValueType(); err != nil {
+			return err
+		}
+		mut, err := r.readByte()
+		if err != nil {
+			return err
+		}
+		if mut > 1 {
+			return errFormat("InvalidMutability(mut=%#x)", mut)
+		}
+		g.Mutable = mut == 1
+		if g.Init, err = r.readConstExpr(g.Type); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Module) decodeExports(r *reader) error {
+	n, err := r.readSize(len(r.bs))
+	if err != nil {
+		return err
+	}
+	m.Exports = make([]Export, n)
+	names := make(map[string]bool, n)
+	for i := range m.Exports {
+		e := &m.Exports[i]
+		if e.Name, err = r.readName(); err != nil {
+			return err
+		}
+		if names[e.Name] {
+			return errFormat("DuplicateExport(name=%s)", e.Name)
+		}
+		names[e.Name] = true
+		kind, err := r.readByte()
+		if err != nil {
+			return err
+		}
+		if kind > byte(ExternalGlobal) {
+			return errFormat("InvalidExportKind(kind=%d)", kind)
+		}
+		e.Kind = ExternalKind(kind)
+		if e.Index, err = r.readU32(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Module) decodeElements(r *reader) error {
+	n, err := r.readSize(len(r.bs))
+	if err != nil {
+		return err
+	}
+	m.Elements = make([]Element, n)
+	for i := range m.Elements {
+		e := &m.Elements[i]
+		if flag, err := r.readU32(); err != nil {
+			return err
+		} else if flag != 0 {
+			return errFormat("UnsupportedElement(flag=%d)", flag)
+		}
+		offset, err := r.readConstExpr(I32)
+		if err != nil {
+			return err
+		}
+		e.Offset = uint32(offset)
+		nf, err := r.readSize(MaxTableSize)
+		if err != nil {
+			return err
+		}
+		e.Funcs = make([]uint32, nf)
+		for j := range e.Funcs {
+			if e.Funcs[j], err = r.readU32(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Module) decodeCode(r *reader) error {
+	n, err := r.readSize(len(r.bs))
+	if err != nil {
+		return err
+	}
+	if n != len(m.Functions) {
+		return errFormat("FunctionCodeMismatch(funcs=%d,codes=%d)", len(m.Functions), n)
+	}
+	for i := range m.Functions {
+		f := &m.Functions[i]
+		size, err := r.readSize(len(r.bs) - r.pos)
+		if err != nil {
+			return err
+		}
+		body, err := r.readBytes(size)
+		if err != nil {
+			return err
+		}
+		br := &reader{bs: body}
+		ng, err := br.readSize(len(body))
+		if err != nil {
+			return err
+		}
+		nParams := len(m.Types[f.Type].Params)
+		total := nParams
+		for j := 0; j < ng; j++ {
+			cnt, err := br.readSize(MaxLocals)
+			if err != nil {
+				return err
+			}
+			if total += cnt; total > MaxLocals {
+				return errFormat("TooManyLocals(func=%d)", i)
+			}
+			t, err := br.readValueType()
+			if err != nil {
+				return err
+			}
+			for k := 0; k < cnt; k++ {
+				f.Locals = append(f.Locals, t)
+			}
+		}
+		f.Code = body[br.pos:]
+	}
+	return nil
+}
+
+func (m *Module) decodeData(r *reader) error {
+	n, err := r.readSize(len(r.bs))
+	if err != nil {
+		return err
+	}
+	m.Data = make([]Data, n)
+	for i := range m.Data {
+		d := &m.Data[i]
+		if flag, err := r.readU32(); err != nil {
+			return err
+		} else if flag != 0 {
+			return errFormat("UnsupportedData(flag=%d)", flag)
+		}
+		offset, err := r.readConstExpr(I32)
+		if err != nil {
+			return err
+		}
+		d.Offset = uint32(offset)
+		size, err := r.readSize(len(r.bs) - r.pos)
+		if err != nil {
+			return err
+		}
+		if d.Init, err = r.readBytes(size); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decode decodes the binary module. It doesn't validate code of functions,
+// so Validate should be called before using the module.
+func Decode(bs []byte) (*Module, error) {
+	if len(bs) < 8 || !bytes.Equal(bs[0:4], []byte(magic)) {
+		return nil, errFormat("InvalidMagic")
+	}
+	if !bytes.Equal(bs[4:8], []byte(version)) {
+		return nil, errFormat("UnsupportedVersion(version=%#x)", bs[4:8])
+	}
+	m := new(Module)
+	r := &reader{bs: bs, pos: 8}
+	var last byte
+	var hasCode bool
+	for !r.eof() {
+		id, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		size, err := r.readSize(len(bs) - r.pos)
+		if err != nil {
+			return nil, err
+		}
+		content, err := r.readBytes(size)
+		if err != nil {
+			return nil, err
+		}
+		sr := &reader{bs: content}
+		if id != sectionCustom {
+			if id <= last {
+				return nil, errFormat("InvalidSectionOrder(id=%d)", id)
+			}
+			last = id
+		}
+		switch id {
+		case sectionCustom:
+			name, err := sr.readName()
+			if err != nil {
+				return nil, err
+			}
+			m.Customs = append(m.Customs, Custom{Name: name, Data: content[sr.pos:]})
+			continue
+		case sectionType:
+			err = m.decodeTypes(sr)
+		case sectionImport:
+			err = m.decodeImports(sr)
+		case sectionFunction:
+			err = m.decodeFunctions(sr)
+		case sectionTable:
+			err = m.decodeTable(sr)
+		case sectionMemory:
+			err = m.decodeMemory(sr)
+		case sectionGlobal:
+			err = m.decodeGlobals(sr)
+		case sectionExport:
+			err = m.decodeExports(sr)
+		case sectionStart:
+			err = errFormat("UnsupportedStartFunction")
+		case sectionElement:
+			err = m.decodeElements(sr)
+		case sectionCode:
+			err = m.decodeCode(sr)
+			hasCode = true
+		case sectionData:
+			err = m.decodeData(sr)
+		default:
+			err = errFormat("UnknownSection(id=%d)", id)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !sr.eof() {
+			return nil, errFormat("InvalidSectionSize(id=%d)", id)
+		}
+	}
+	if len(m.Functions) > 0 && !hasCode {
+		return nil, errFormat("NoCodeSection")
+	}
+	return m, nil
+}
@@ -0,0 +1,296 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package wasm
+
+func appendULEB(bs []byte, v uint64) []byte {
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if v != 0 {
+			bs = append(bs, b|0x80)
+		} else {
+			return append(bs, b)
+		}
+	}
+}
+
+func appendSLEB(bs []byte, v int64) []byte {
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if (v == 0 && b&0x40 == 0) || (v == -1 && b&0x40 != 0) {
+			return append(bs, b)
+		}
+		bs = append(bs, b|0x80)
+	}
+}
+
+func appendName(bs []byte, name string) []byte {
+	bs = appendULEB(bs, uint64(len(name)))
+	return append(bs, name...)
+}
+
+func appendLimits(bs []byte, l *Limits) []byte {
+	if l.HasMax {
+		bs = append(bs, 1)
+		bs = appendULEB(bs, uint64(l.Min))
+		return appendULEB(bs, uint64(l.Max))
+	}
+	bs = append(bs, 0)
+	return appendULEB(bs, uint64(l.Min))
+}
+
+func appendConst(bs []byte, t ValueType, v uint64) []byte {
+	if t == I64 {
+		bs = appendSLEB(append(bs, opI64Const), int64(v))
+	} else {
+		bs = appendSLEB(append(bs, opI32Const), int64(int32(v)))
+	}
+	return append(bs, opEnd)
+}
+
+func appendSection(bs []byte, id byte, content []byte) []byte {
+	bs = append(bs, id)
+	bs = appendULEB(bs, uint64(len(content)))
+	return append(bs, content...)
+}
+
+// Encode returns the binary format of the module. Code of functions
+// should include the last end instruction.
+func (m *Module) Encode() []byte {
+	bs := []byte(magic + version)
+	if len(m.Types) > 0 {
+		s := appendULEB(nil, uint64(len(m.Types)))
+		for _, t := range m.Types {
+			s = append(s, typeFunc)
+			s = appendULEB(s, uint64(len(t.Params)))
+			for _, p := range t.Params {
+				s = append(s, byte(p))
+			}
+			s = appendULEB(s, uint64(len(t.Results)))
+			for _, r := range t.Results {
+				s = append(s, byte(r))
+			}
+		}
+		bs = appendSection(bs, sectionType, s)
+	}
+	if len(m.Imports) > 0 {
+		s := appendULEB(nil, uint64(len(m.Imports)))
+		for _, imp := range m.Imports {
+			s = appendName(s, imp.Module)
+			s = appendName(s, imp.Name)
+			s = append(s, byte(ExternalFunction))
+			s = appendULEB(s, uint64(imp.Type))
+		}
+		bs = appendSection(bs, sectionImport, s)
+	}
+	if len(m.Functions) > 0 {
+		s := appendULEB(nil, uint64(len(m.Functions)))
+		for _, f := range m.Functions {
+			s = appendULEB(s, uint64(f.Type))
+		}
+		bs = appendSection(bs, sectionFunction, s)
+	}
+	if m.Table != nil {
+		s := append(appendULEB(nil, 1), typeFuncRef)
+		bs = appendSection(bs, sectionTable, appendLimits(s, m.Table))
+	}
+	if m.Memory != nil {
+		bs = appendSection(bs, sectionMemory, appendLimits(appendULEB(nil, 1), m.Memory))
+	}
+	if len(m.Globals) > 0 {
+		s := appendULEB(nil, uint64(len(m.Globals)))
+		for _, g := range m.Globals {
+			s = append(s, byte(g.Type))
+			if g.Mutable {
+				s = append(s, 1)
+			} else {
+				s = append(s, 0)
+			}
+			s = appendConst(s, g.Type, g.Init)
+		}
+		bs = appendSection(bs, sectionGlobal, s)
+	}
+	if len(m.Exports) > 0 {
+		s := appendULEB(nil, uint64(len(m.Exports)))
+		for _, e := range m.Exports {
+			s = appendName(s, e.Name)
+			s = append(s, byte(e.Kind))
+			s = appendULEB(s, uint64(e.Index))
+		}
+		bs = appendSection(bs, sectionExport, s)
+	}
+	if len(m.Elements) > 0 {
+		s := appendULEB(nil, uint64(len(m.Elements)))
+		for _, e := range m.Elements {
+			s = appendULEB(s, 0)
+			s = appendConst(s, I32, uint64(e.Offset))
+			s = appendULEB(s, uint64(len(e.Funcs)))
+			for _, f := range e.Funcs {
+				s = appendULEB(s, uint64(f))
+			}
+		}
+		bs = appendSection(bs, sectionElement, s)
+	}
+	if len(m.Functions) > 0 {
+		s := appendULEB(nil, uint64(len(m.Functions)))
+		for _, f := range m.Functions {
+			var body []byte
+			var groups []byte
+			ng := 0
+			for i := 0; i < len(f.Locals); {
+				j := i
+				for j < len(f.Locals) && f.Locals[j] == f.Locals[i] {
+					j++
+				}
+				groups = appendULEB(groups, uint64(j-i))
+				groups = append(groups, byte(f.Locals[i]))
+				ng++
+				i = j
+			}
+			body = appendULEB(body, uint64(ng))
+			body = append(body, groups...)
+			body = append(body, f.Code...)
+			s = appendULEB(s, uint64(len(body)))
+			s = append(s, body...)
+		}
+		bs = appendSection(bs, sectionCode, s)
+	}
+	if len(m.Data) > 0 {
+		s := appendULEB(nil, uint64(len(m.Data)))
+		for _, d := range m.Data {
+			s = appendULEB(s, 0)
+			s = appendConst(s, I32, uint64(d.Offset))
+			s = appendULEB(s, uint64(len(d.Init)))
+			s = append(s, d.Init...)
+		}
+		bs = appendSection(bs, sectionData, s)
+	}
+	for _, c := range m.Customs {
+		bs = appendSection(bs, sectionCustom, append(appendName(nil, c.Name), c.Data...))
+	}
+	return bs
+}
+
+// Code is an assembler for code of functions.
+type Code struct {
+	bs []byte
+}
+
+func (c *Code) op(op byte, args ...uint32) *Code {
+	c.bs = append(c.bs, op)
+	for _, a := range args {
+		c.bs = appendULEB(c.bs, uint64(a))
+	}
+	return c
+}
+
+func (c *Code) block(op byte, results []ValueType) *Code {
+	c.bs = append(c.bs, op)
+	if len(results) == 0 {
+		c.bs = append(c.bs, blockEmpty)
+	} else {
+		c.bs = append(c.bs, byte(results[0]))
+	}
+	return c
+}
+
+func (c *Code) Unreachable() *Code { return c.op(opUnreachable) }
+func (c *Code) Nop() *Code         { return c.op(opNop) }
+func (c *Code) Block(results ...ValueType) *Code {
+	return c.block(opBlock, results)
+}
+func (c *Code) Loop(results ...ValueType) *Code {
+	return c.block(opLoop, results)
+}
+func (c *Code) If(results ...ValueType) *Code {
+	return c.block(opIf, results)
+}
+func (c *Code) Else() *Code              { return c.op(opElse) }
+func (c *Code) End() *Code               { return c.op(opEnd) }
+func (c *Code) Br(depth uint32) *Code    { return c.op(opBr, depth) }
+func (c *Code) BrIf(depth uint32) *Code  { return c.op(opBrIf, depth) }
+func (c *Code) Return() *Code            { return c.op(opReturn) }
+func (c *Code) Call(idx uint32) *Code    { return c.op(opCall, idx) }
+func (c *Code) Drop() *Code              { return c.op(opDrop) }
+func (c *Code) Select() *Code            { return c.op(opSelect) }
+func (c *Code) LocalGet(i uint32) *Code  { return c.op(opLocalGet, i) }
+func (c *Code) LocalSet(i uint32) *Code  { return c.op(opLocalSet, i) }
+func (c *Code) LocalTee(i uint32) *Code  { return c.op(opLocalTee, i) }
+func (c *Code) GlobalGet(i uint32) *Code { return c.op(opGlobalGet, i) }
+func (c *Code) GlobalSet(i uint32) *Code { return c.op(opGlobalSet, i) }
+func (c *Code) MemorySize() *Code        { return c.op(opMemorySize, 0) }
+func (c *Code) MemoryGrow() *Code        { return c.op(opMemoryGrow, 0) }
+
+func (c *Code) BrTable(depths ...uint32) *Code {
+	c.op(opBrTable, uint32(len(depths)-1))
+	for _, d := range depths {
+		c.bs = appendULEB(c.bs, uint64(d))
+	}
+	return c
+}
+
+func (c *Code) CallIndirect(typeIdx uint32) *Code {
+	return c.op(opCallIndirect, typeIdx, 0)
+}
+
+func (c *Code) I32Load(offset uint32) *Code   { return c.op(opI32Load, 2, offset) }
+func (c *Code) I64Load(offset uint32) *Code   { return c.op(opI64Load, 3, offset) }
+func (c *Code) I32Load8U(offset uint32) *Code { return c.op(opI32Load8U, 0, offset) }
+func (c *Code) I32Store(offset uint32) *Code  { return c.op(opI32Store, 2, offset) }
+func (c *Code) I64Store(offset uint32) *Code  { return c.op(opI64Store, 3, offset) }
+func (c *Code) I32Store8(offset uint32) *Code { return c.op(opI32Store8, 0, offset) }
+
+func (c *Code) I32Const(v int32) *Code {
+	c.bs = appendSLEB(append(c.bs, opI32Const), int64(v))
+	return c
+}
+
+func (c *Code) I64Const(v int64) *Code {
+	c.bs = appendSLEB(append(c.bs, opI64Const), v)
+	return c
+}
+
+func (c *Code) I32Eqz() *Code        { return c.op(opI32Eqz) }
+func (c *Code) I32Eq() *Code         { return c.op(opI32Eq) }
+func (c *Code) I32Ne() *Code         { return c.op(opI32Ne) }
+func (c *Code) I32LtS() *Code        { return c.op(opI32LtS) }
+func (c *Code) I32LtU() *Code        { return c.op(opI32LtU) }
+func (c *Code) I32GtS() *Code        { return c.op(opI32GtS) }
+func (c *Code) I32Add() *Code        { return c.op(opI32Add) }
+func (c *Code) I32Sub() *Code        { return c.op(opI32Sub) }
+func (c *Code) I32Mul() *Code        { return c.op(opI32Mul) }
+func (c *Code) I32DivS() *Code       { return c.op(opI32DivS) }
+func (c *Code) I32DivU() *Code       { return c.op(opI32DivU) }
+func (c *Code) I32RemS() *Code       { return c.op(opI32RemS) }
+func (c *Code) I32And() *Code        { return c.op(opI32And) }
+func (c *Code) I32Or() *Code         { return c.op(opI32Or) }
+func (c *Code) I32Shl() *Code        { return c.op(opI32Shl) }
+func (c *Code) I32ShrU() *Code       { return c.op(opI32ShrU) }
+func (c *Code) I64Eqz() *Code        { return c.op(opI64Eqz) }
+func (c *Code) I64Add() *Code        { return c.op(opI64Add) }
+func (c *Code) I64Sub() *Code        { return c.op(opI64Sub) }
+func (c *Code) I64Mul() *Code        { return c.op(opI64Mul) }
+func (c *Code) I64DivS() *Code       { return c.op(opI64DivS) }
+func (c *Code) I64LtS() *Code        { return c.op(opI64LtS) }
+func (c *Code) I64ExtendI32U() *Code { return c.op(opI64ExtendI32U) }
+func (c *Code) I32WrapI64() *Code    { return c.op(opI32WrapI64) }
+
+// Bytes returns the assembled code.
+func (c *Code) Bytes() []byte {
+	return c.bs
+}
@@ -0,0 +1,718 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package wasm
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+	"sync/atomic"
+
+	"github.com/icon-project/goloop/service/scoreresult"
+)
+
+const (
+	// StepsPerInstruction is the number of steps for an instruction.
+	StepsPerInstruction = 1
+	// StepsPerPage is the number of steps for a page of the memory, which
+	// is charged for initial pages and pages grown by memory.grow.
+	StepsPerPage = 1024
+)
+
+// HostFunction is a function provided by the host. Returning an error
+// stops the execution with the error.
+type HostFunction struct {
+	Type FuncType
+	Call func(in *Instance, args []uint64) ([]uint64, error)
+}
+
+// Resolver returns the host function for the import.
+type Resolver func(module, name string) (*HostFunction, bool)
+
+func trap(format string, args ...interface{}) error {
+	return scoreresult.UnknownFailureError.Errorf("Trap("+format+")", args...)
+}
+
+type label struct {
+	arity  int
+	height int
+	cont   int
+}
+
+// Instance is an instance of the module with its own memory and globals.
+// It's not safe for concurrent use.
+type Instance struct {
+	module  *Module
+	hosts   []*HostFunction
+	memory  []byte
+	globals []uint64
+	table   []int64
+
+	stack []uint64
+	depth int
+	steps int64
+	abort int32
+
+	// Context is free for the host.
+	Context interface{}
+}
+
+// NewInstance instantiates the module. Imports are resolved with the
+// resolver. Initial pages of the memory are charged from the steps, and it
+// returns scoreresult.ErrOutOfStep if the steps are not enough for them.
+func NewInstance(m *Module, resolver Resolver, steps int64) (*Instance, error) {
+	in := &Instance{
+		module: m,
+		hosts:  make([]*HostFunction, len(m.Imports)),
+		steps:  steps,
+		stack:  make([]uint64, 0, 256),
+	}
+	for i, imp := range m.Imports {
+		hf, ok := resolver(imp.Module, imp.Name)
+		if !ok {
+			return nil, errFormat("UnknownImport(name=%s.%s)", imp.Module, imp.Name)
+		}
+		if !hf.Type.Equal(&m.Types[imp.Type]) {
+			return nil, errFormat("ImportTypeMismatch(name=%s.%s)", imp.Module, imp.Name)
+		}
+		in.hosts[i] = hf
+	}
+	if m.Memory != nil {
+		if err := in.UseSteps(int64(m.Memory.Min) * StepsPerPage); err != nil {
+			return nil, err
+		}
+		in.memory = make([]byte, int(m.Memory.Min)*PageSize)
+		for _, d := range m.Data {
+			copy(in.memory[d.Offset:], d.Init)
+		}
+	}
+	in.globals = make([]uint64, len(m.Globals))
+	for i, g := range m.Globals {
+		in.globals[i] = g.Init
+	}
+	if m.Table != nil {
+		in.table = make([]int64, m.Table.Min)
+		for i := range in.table {
+			in.table[i] = -1
+		}
+		for _, e := range m.Elements {
+			for j, f := range e.Funcs {
+				in.table[int(e.Offset)+j] = int64(f)
+			}
+		}
+	}
+	return in, nil
+}
+
+// Memory returns the memory of the instance. It may be changed by
+// memory.grow.
+func (in *Instance) Memory() []byte {
+	return in.memory
+}
+
+// Read returns a copy of the memory in the range.
+func (in *Instance) Read(ptr, size uint32) ([]byte, error) {
+	end := uint64(ptr) + uint64(size)
+	if end > uint64(len(in.memory)) {
+		return nil, trap("OutOfBoundsMemoryAccess(ptr=%d,size=%d)", ptr, size)
+	}
+	bs := make([]byte, size)
+	copy(bs, in.memory[ptr:end])
+	return bs, nil
+}
+
+// Write copies the bytes to the memory.
+func (in *Instance) Write(ptr uint32, bs []byte) error {
+	end := uint64(ptr) + uint64(len(bs))
+	if end > uint64(len(in.memory)) {
+		return trap("OutOfBoundsMemoryAccess(ptr=%d,size=%d)", ptr, len(bs))
+	}
+	copy(in.memory[ptr:end], bs)
+	return nil
+}
+
+// StepsLeft returns the number of steps left.
+func (in *Instance) StepsLeft() int64 {
+	return in.steps
+}
+
+// UseSteps consumes the steps. It returns an error if there are not
+// enough steps, then steps left become zero.
+func (in *Instance) UseSteps(steps int64) error {
+	if steps < 0 {
+		return trap("InvalidSteps(steps=%d)", steps)
+	}
+	if in.steps < steps {
+		in.steps = 0
+		return scoreresult.ErrOutOfStep
+	}
+	in.steps -= steps
+	return nil
+}
+
+// Abort stops the execution. It can be called by other goroutines.
+func (in *Instance) Abort() {
+	atomic.StoreInt32(&in.abort, 1)
+}
+
+func (in *Instance) aborted() bool {
+	return atomic.LoadInt32(&in.abort) != 0
+}
+
+// Call calls the exported function with the arguments.
+func (in *Instance) Call(name string, args ...uint64) ([]uint64, error) {
+	e, ok := in.module.Export(name)
+	if !ok || e.Kind != ExternalFunction {
+		return nil, scoreresult.MethodNotFoundError.Errorf("FunctionNotFound(name=%s)", name)
+	}
+	ft := in.module.TypeOfFunction(e.Index)
+	if len(args) != len(ft.Params) {
+		return nil, scoreresult.InvalidParameterError.Errorf(
+			"InvalidArguments(exp=%d,real=%d)", len(ft.Params), len(args))
+	}
+	in.stack = append(in.stack[:0], args...)
+	if err := in.call(e.Index); err != nil {
+		return nil, err
+	}
+	results := make([]uint64, len(ft.Results))
+	copy(results, in.stack[len(in.stack)-len(results):])
+	in.stack = in.stack[:0]
+	return results, nil
+}
+
+func (in *Instance) call(idx uint32) error {
+	ft := in.module.TypeOfFunction(idx)
+	np := len(ft.Params)
+	if int(idx) < len(in.hosts) {
+		hf := in.hosts[idx]
+		args := make([]uint64, np)
+		copy(args, in.stack[len(in.stack)-np:])
+		in.stack = in.stack[:len(in.stack)-np]
+		results, err := hf.Call(in, args)
+		if err != nil {
+			return err
+		}
+		if len(results) != len(ft.Results) {
+			return trap("InvalidHostResults(name=%s)", in.module.Imports[idx].Name)
+		}
+		in.stack = append(in.stack, results...)
+		return nil
+	}
+	if in.depth >= MaxCallDepth {
+		return scoreresult.ErrStackOverflow
+	}
+	fn := &in.module.Functions[int(idx)-len(in.hosts)]
+	locals := make([]uint64, np+len(fn.Locals))
+	copy(locals, in.stack[len(in.stack)-np:])
+	in.stack = in.stack[:len(in.stack)-np]
+
+	in.depth++
+	err := in.execute(fn, ft, locals)
+	in.depth--
+	return err
+}
+
+func (in *Instance) effectiveAddress(code []byte, pc int, size int) (int, uint64, error) {
+	// skip alignment
+	_, pc, _ = readULEB(code, pc, 32)
+	offset, pc, _ := readULEB(code, pc, 32)
+	base := uint32(in.stack[len(in.stack)-1])
+	in.stack = in.stack[:len(in.stack)-1]
+	ea := uint64(base) + offset
+	if ea+uint64(size) > uint64(len(in.memory)) {
+		return pc, 0, trap("OutOfBoundsMemoryAccess(addr=%d,size=%d)", ea, size)
+	}
+	return pc, ea, nil
+}
+
+func b2i(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func (in *Instance) execute(fn *Function, ft *FuncType, locals []uint64) error {
+	code := fn.Code
+	base := len(in.stack)
+	labels := []label{{arity: len(ft.Results), height: base, cont: len(code)}}
+	pc := 0
+
+	branch := func(depth int) {
+		l := labels[len(labels)-1-depth]
+		if l.arity > 0 {
+			copy(in.stack[l.height:], in.stack[len(in.stack)-l.arity:])
+		}
+		in.stack = in.stack[:l.height+l.arity]
+		// a loop is entered again by its instruction at the continuation
+		labels = labels[:len(labels)-1-depth]
+		pc = l.cont
+	}
+
+	for pc < len(code) {
+		if in.steps < StepsPerInstruction {
+			in.steps = 0
+			return scoreresult.ErrOutOfStep
+		}
+		in.steps -= StepsPerInstruction
+		if len(in.stack) >= MaxStackSize {
+			return scoreresult.ErrStackOverflow
+		}
+
+		op := code[pc]
+		opPC := pc
+		pc++
+		switch op {
+		case opUnreachable:
+			return trap("Unreachable(pc=%d)", opPC)
+		case opNop:
+		case opBlock, opLoop:
+			arity := 0
+			if code[pc] != blockEmpty {
+				arity = 1
+			}
+			pc++
+			if op == opLoop {
+				labels = append(labels, label{height: len(in.stack), cont: opPC})
+			} else {
+				labels = append(labels, label{arity: arity, height: len(in.stack), cont: fn.jumps[opPC].endPC + 1})
+			}
+		case opIf:
+			arity := 0
+			if code[pc] != blockEmpty {
+				arity = 1
+			}
+			pc++
+			cond := uint32(in.stack[len(in.stack)-1])
+			in.stack = in.stack[:len(in.stack)-1]
+			j := fn.jumps[opPC]
+			if cond != 0 {
+				labels = append(labels, label{arity: arity, height: len(in.stack), cont: j.endPC + 1})
+			} else if j.elsePC >= 0 {
+				labels = append(labels, label{arity: arity, height: len(in.stack), cont: j.endPC + 1})
+				pc = j.elsePC + 1
+			} else {
+				pc = j.endPC + 1
+			}
+		case opElse:
+			// reached at the end of then clause
+			branch(0)
+		case opEnd:
+			labels = labels[:len(labels)-1]
+			if len(labels) == 0 {
+				return nil
+			}
+		case opBr, opBrIf, opBrTable, opReturn:
+			if in.aborted() {
+				return scoreresult.ErrTimeout
+			}
+			var depth int
+			switch op {
+			case opBr:
+				d, npc, _ := readULEB(code, pc, 32)
+				depth, pc = int(d), npc
+			case opBrIf:
+				d, npc, _ := readULEB(code, pc, 32)
+				pc = npc
+				cond := uint32(in.stack[len(in.stack)-1])
+				in.stack = in.stack[:len(in.stack)-1]
+				if cond == 0 {
+					continue
+				}
+				depth = int(d)
+			case opBrTable:
+				n, npc, _ := readULEB(code, pc, 32)
+				pc = npc
+				idx := uint64(uint32(in.stack[len(in.stack)-1]))
+				in.stack = in.stack[:len(in.stack)-1]
+				if idx > n {
+					idx = n
+				}
+				for i := uint64(0); i <= n; i++ {
+					d, npc, _ := readULEB(code, pc, 32)
+					pc = npc
+					if i == idx {
+						depth = int(d)
+					}
+				}
+			case opReturn:
+				depth = len(labels) - 1
+			}
+			branch(depth)
+			if len(labels) == 0 {
+				return nil
+			}
+		case opCall, opCallIndirect:
+			if in.aborted() {
+				return scoreresult.ErrTimeout
+			}
+			var fidx uint32
+			if op == opCall {
+				idx, npc, _ := readULEB(code, pc, 32)
+				fidx, pc = uint32(idx), npc
+			} else {
+				tidx, npc, _ := readULEB(code, pc, 32)
+				pc = npc + 1
+				elem := uint64(uint32(in.stack[len(in.stack)-1]))
+				in.stack = in.stack[:len(in.stack)-1]
+				if elem >= uint64(len(in.table)) || in.table[elem] < 0 {
+					return trap("UndefinedElement(idx=%d)", elem)
+				}
+				fidx = uint32(in.table[elem])
+				if !in.module.TypeOfFunction(fidx).Equal(&in.module.Types[tidx]) {
+					return trap("IndirectCallTypeMismatch(idx=%d)", elem)
+				}
+			}
+			if err := in.call(fidx); err != nil {
+				return err
+			}
+		case opDrop:
+			in.stack = in.stack[:len(in.stack)-1]
+		case opSelect:
+			n := len(in.stack)
+			if uint32(in.stack[n-1]) == 0 {
+				in.stack[n-3] = in.stack[n-2]
+			}
+			in.stack = in.stack[:n-2]
+		case opLocalGet, opLocalSet, opLocalTee:
+			idx, npc, _ := readULEB(code, pc, 32)
+			pc = npc
+			switch op {
+			case opLocalGet:
+				in.stack = append(in.stack, locals[idx])
+			case opLocalSet:
+				locals[idx] = in.stack[len(in.stack)-1]
+				in.stack = in.stack[:len(in.stack)-1]
+			case opLocalTee:
+				locals[idx] = in.stack[len(in.stack)-1]
+			}
+		case opGlobalGet:
+			idx, npc, _ := readULEB(code, pc, 32)
+			pc = npc
+			in.stack = append(in.stack, in.globals[idx])
+		case opGlobalSet:
+			idx, npc, _ := readULEB(code, pc, 32)
+			pc = npc
+			in.globals[idx] = in.stack[len(in.stack)-1]
+			in.stack = in.stack[:len(in.stack)-1]
+		case opMemorySize:
+			pc++
+			in.stack = append(in.stack, uint64(len(in.memory)/PageSize))
+		case opMemoryGrow:
+			pc++
+			delta := uint32(in.stack[len(in.stack)-1])
+			pages := uint32(len(in.memory) / PageSize)
+			max := uint32(MaxPages)
+			if mm := in.module.Memory; mm.HasMax && mm.Max < max {
+				max = mm.Max
+			}
+			if uint64(pages)+uint64(delta) > uint64(max) {
+				in.stack[len(in.stack)-1] = uint64(math.MaxUint32)
+				continue
+			}
+			if err := in.UseSteps(int64(delta) * StepsPerPage); err != nil {
+				return err
+			}
+			in.memory = append(in.memory, make([]byte, int(delta)*PageSize)...)
+			in.stack[len(in.stack)-1] = uint64(pages)
+		case opI32Const:
+			v, npc, _ := readSLEB(code, pc, 32)
+			pc = npc
+			in.stack = append(in.stack, uint64(uint32(v)))
+		case opI64Const:
+			v, npc, _ := readSLEB(code, pc, 64)
+			pc = npc
+			in.stack = append(in.stack, uint64(v))
+		case opI32Load, opI64Load, opI32Load8S, opI32Load8U, opI32Load16S, opI32Load16U,
+			opI64Load8S, opI64Load8U, opI64Load16S, opI64Load16U, opI64Load32S, opI64Load32U:
+			size, _, _ := memoryAccess(op)
+			npc, ea, err := in.effectiveAddress(code, pc, size)
+			if err != nil {
+				return err
+			}
+			pc = npc
+			mem := in.memory[ea:]
+			var v uint64
+			switch op {
+			case opI32Load, opI64Load32U:
+				v = uint64(binary.LittleEndian.Uint32(mem))
+			case opI64Load:
+				v = binary.LittleEndian.Uint64(mem)
+			case opI32Load8S:
+				v = uint64(uint32(int32(int8(mem[0]))))
+			case opI32Load8U, opI64Load8U:
+				v = uint64(mem[0])
+			case opI32Load16S:
+				v = uint64(uint32(int32(int16(binary.LittleEndian.Uint16(mem)))))
+			case opI32Load16U, opI64Load16U:
+				v = uint64(binary.LittleEndian.Uint16(mem))
+			case opI64Load8S:
+				v = uint64(int64(int8(mem[0])))
+			case opI64Load16S:
+				v = uint64(int64(int16(binary.LittleEndian.Uint16(mem))))
+			case opI64Load32S:
+				v = uint64(int64(int32(binary.LittleEndian.Uint32(mem))))
+			}
+			in.stack = append(in.stack, v)
+		case opI32Store, opI64Store, opI32Store8, opI32Store16,
+			opI64Store8, opI64Store16, opI64Store32:
+			size, _, _ := memoryAccess(op)
+			v := in.stack[len(in.stack)-1]
+			in.stack = in.stack[:len(in.stack)-1]
+			npc, ea, err := in.effectiveAddress(code, pc, size)
+			if err != nil {
+				return err
+			}
+			pc = npc
+			mem := in.memory[ea:]
+			switch size {
+			case 1:
+				mem[0] = byte(v)
+			case 2:
+				binary.LittleEndian.PutUint16(mem, uint16(v))
+			case 4:
+				binary.LittleEndian.PutUint32(mem, uint32(v))
+			case 8:
+				binary.LittleEndian.PutUint64(mem, v)
+			}
+		default:
+			if err := in.numeric(op); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (in *Instance) numeric(op byte) error {
+	n := len(in.stack)
+	switch {
+	case op == opI32Eqz:
+		in.stack[n-1] = b2i(uint32(in.stack[n-1]) == 0)
+	case op == opI64Eqz:
+		in.stack[n-1] = b2i(in.stack[n-1] == 0)
+	case op >= opI32Eq && op <= opI32GeU:
+		a, b := uint32(in.stack[n-2]), uint32(in.stack[n-1])
+		var r bool
+		switch op {
+		case opI32Eq:
+			r = a == b
+		case opI32Ne:
+			r = a != b
+		case opI32LtS:
+			r = int32(a) < int32(b)
+		case opI32LtU:
+			r = a < b
+		case opI32GtS:
+			r = int32(a) > int32(b)
+		case opI32GtU:
+			r = a > b
+		case opI32LeS:
+			r = int32(a) <= int32(b)
+		case opI32LeU:
+			r = a <= b
+		case opI32GeS:
+			r = int32(a) >= int32(b)
+		case opI32GeU:
+			r = a >= b
+		}
+		in.stack[n-2] = b2i(r)
+		in.stack = in.stack[:n-1]
+	case op >= opI64Eq && op <= opI64GeU:
+		a, b := in.stack[n-2], in.stack[n-1]
+		var r bool
+		switch op {
+		case opI64Eq:
+			r = a == b
+		case opI64Ne:
+			r = a != b
+		case opI64LtS:
+			r = int64(a) < int64(b)
+		case opI64LtU:
+			r = a < b
+		case opI64GtS:
+			r = int64(a) > int64(b)
+		case opI64GtU:
+			r = a > b
+		case opI64LeS:
+			r = int64(a) <= int64(b)
+		case opI64LeU:
+			r = a <= b
+		case opI64GeS:
+			r = int64(a) >= int64(b)
+		case opI64GeU:
+			r = a >= b
+		}
+		in.stack[n-2] = b2i(r)
+		in.stack = in.stack[:n-1]
+	case op >= opI32Clz && op <= opI32Popcnt:
+		a := uint32(in.stack[n-1])
+		var r int
+		switch op {
+		case opI32Clz:
+			r = bits.LeadingZeros32(a)
+		case opI32Ctz:
+			r = bits.TrailingZeros32(a)
+		case opI32Popcnt:
+			r = bits.OnesCount32(a)
+		}
+		in.stack[n-1] = uint64(r)
+	case op >= opI64Clz && op <= opI64Popcnt:
+		a := in.stack[n-1]
+		var r int
+		switch op {
+		case opI64Clz:
+			r = bits.LeadingZeros64(a)
+		case opI64Ctz:
+			r = bits.TrailingZeros64(a)
+		case opI64Popcnt:
+			r = bits.OnesCount64(a)
+		}
+		in.stack[n-1] = uint64(r)
+	case op >= opI32Add && op <= opI32Rotr:
+		a, b := uint32(in.stack[n-2]), uint32(in.stack[n-1])
+		var r uint32
+		switch op {
+		case opI32Add:
+			r = a + b
+		case opI32Sub:
+			r = a - b
+		case opI32Mul:
+			r = a * b
+		case opI32DivS:
+			if b == 0 {
+				return trap("IntegerDivideByZero")
+			}
+			if int32(a) == math.MinInt32 && int32(b) == -1 {
+				return trap("IntegerOverflow")
+			}
+			r = uint32(int32(a) / int32(b))
+		case opI32DivU:
+			if b == 0 {
+				return trap("IntegerDivideByZero")
+			}
+			r = a / b
+		case opI32RemS:
+			if b == 0 {
+				return trap("IntegerDivideByZero")
+			}
+			if int32(b) == -1 {
+				r = 0
+			} else {
+				r = uint32(int32(a) % int32(b))
+			}
+		case opI32RemU:
+			if b == 0 {
+				return trap("IntegerDivideByZero")
+			}
+			r = a % b
+		case opI32And:
+			r = a & b
+		case opI32Or:
+			r = a | b
+		case opI32Xor:
+			r = a ^ b
+		case opI32Shl:
+			r = a << (b % 32)
+		case opI32ShrS:
+			r = uint32(int32(a) >> (b % 32))
+		case opI32ShrU:
+			r = a >> (b % 32)
+		case opI32Rotl:
+			r = bits.RotateLeft32(a, int(b%32))
+		case opI32Rotr:
+			r = bits.RotateLeft32(a, -int(b%32))
+		}
+		in.stack[n-2] = uint64(r)
+		in.stack = in.stack[:n-1]
+	case op >= opI64Add && op <= opI64Rotr:
+		a, b := in.stack[n-2], in.stack[n-1]
+		var r uint64
+		switch op {
+		case opI64Add:
+			r = a + b
+		case opI64Sub:
+			r = a - b
+		case opI64Mul:
+			r = a * b
+		case opI64DivS:
+			if b == 0 {
+				return trap("IntegerDivideByZero")
+			}
+			if int64(a) == math.MinInt64 && int64(b) == -1 {
+				return trap("IntegerOverflow")
+			}
+			r = uint64(int64(a) / int64(b))
+		case opI64DivU:
+			if b == 0 {
+				return trap("IntegerDivideByZero")
+			}
+			r = a / b
+		case opI64RemS:
+			if b == 0 {
+				return trap("IntegerDivideByZero")
+			}
+			if int64(b) == -1 {
+				r = 0
+			} else {
+				r = uint64(int64(a) % int64(b))
+			}
+		case opI64RemU:
+			if b == 0 {
+				return trap("IntegerDivideByZero")
+			}
+			r = a % b
+		case opI64And:
+			r = a & b
+		case opI64Or:
+			r = a | b
+		case opI64Xor:
+			r = a ^ b
+		case opI64Shl:
+			r = a << (b % 64)
+		case opI64ShrS:
+			r = uint64(int64(a) >> (b % 64))
+		case opI64ShrU:
+			r = a >> (b % 64)
+		case opI64Rotl:
+			r = bits.RotateLeft64(a, int(b%64))
+		case opI64Rotr:
+			r = bits.RotateLeft64(a, -int(b%64))
+		}
+		in.stack[n-2] = r
+		in.stack = in.stack[:n-1]
+	case op == opI32WrapI64:
+		in.stack[n-1] = uint64(uint32(in.stack[n-1]))
+	case op == opI64ExtendI32S:
+		in.stack[n-1] = uint64(int64(int32(in.stack[n-1])))
+	case op == opI64ExtendI32U:
+		in.stack[n-1] = uint64(uint32(in.stack[n-1]))
+	case op == opI32Extend8S:
+		in.stack[n-1] = uint64(uint32(int32(int8(in.stack[n-1]))))
+	case op == opI32Extend16S:
+		in.stack[n-1] = uint64(uint32(int32(int16(in.stack[n-1]))))
+	case op == opI64Extend8S:
+		in.stack[n-1] = uint64(int64(int8(in.stack[n-1])))
+	case op == opI64Extend16S:
+		in.stack[n-1] = uint64(int64(int16(in.stack[n-1])))
+	case op == opI64Extend32S:
+		in.stack[n-1] = uint64(int64(int32(in.stack[n-1])))
+	default:
+		return trap("UnsupportedOpcode(op=%#x)", op)
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package wasm implements a deterministic WebAssembly interpreter for
+// contracts. It supports the MVP binary format without floating point
+// types and with sign extension operators. Every instruction consumes a
+// step, and host functions may consume more.
+package wasm
+
+type ValueType byte
+
+const (
+	I32 ValueType = 0x7f
+	I64 ValueType = 0x7e
+	F32 ValueType = 0x7d
+	F64 ValueType = 0x7c
+
+	// unknown is used for values on the unreachable stack on validation.
+	unknown ValueType = 0
+)
+
+func (t ValueType) String() string {
+	switch t {
+	case I32:
+		return "i32"
+	case I64:
+		return "i64"
+	case F32:
+		return "f32"
+	case F64:
+		return "f64"
+	default:
+		return "unknown"
+	}
+}
+
+type ExternalKind byte
+
+const (
+	ExternalFunction ExternalKind = iota
+	ExternalTable
+	ExternalMemory
+	ExternalGlobal
+)
+
+const (
+	PageSize = 65536
+
+	// MaxPages is the maximum number of pages of the memory.
+	MaxPages = 256
+	// MaxTableSize is the maximum number of elements of the table.
+	MaxTableSize = 65536
+	// MaxLocals is the maximum number of locals including parameters.
+	MaxLocals = 1024
+	// MaxCallDepth is the maximum depth of calls of functions.
+	MaxCallDepth = 1024
+	// MaxStackSize is the maximum number of values on the stack.
+	MaxStackSize = 65536
+)
+
+type FuncType struct {
+	Params  []ValueType
+	Results []ValueType
+}
+
+func (t *FuncType) Equal(t2 *FuncType) bool {
+	if len(t.Params) != len(t2.Params) || len(t.Results) != len(t2.Results) {
+		return false
+	}
+	for i, p := range t.Params {
+		if p != t2.Params[i] {
+			return false
+		}
+	}
+	for i, r := range t.Results {
+		if r != t2.Results[i] {
+			return false
+		}
+	}
+	return true
+}
+
+type Limits struct {
+	Min    uint32
+	Max    uint32
+	HasMax bool
+}
+
+// Import is an imported function. Other kinds of imports aren't supported.
+type Import struct {
+	Module string
+	Name   string
+	Type   uint32
+}
+
+type Export struct {
+	Name  string
+	Kind  ExternalKind
+	Index uint32
+}
+
+type Global struct {
+	Type    ValueType
+	Mutable bool
+	Init    uint64
+}
+
+type Element struct {
+	Offset uint32
+	Funcs  []uint32
+}
+
+type Data struct {
+	Offset uint32
+	Init   []byte
+}
+
+type Custom struct {
+	Name string
+	Data []byte
+}
+
+type jump struct {
+	elsePC int
+	endPC  int
+}
+
+type Function struct {
+	Type   uint32
+	Locals []ValueType
+	Code   []byte
+
+	// jumps has positions of else and end for the block starting at
+	// the position. It's built on validation.
+	jumps map[int]jump
+}
+
+type Module struct {
+	Types     []FuncType
+	Imports   []Import
+	Functions []Function
+	Table     *Limits
+	Memory    *Limits
+	Globals   []Global
+	Exports   []Export
+	Elements  []Element
+	Data      []Data
+	Customs   []Custom
+}
+
+// NumFunctions returns the number of functions including imported ones.
+func (m *Module) NumFunctions() int {
+	return len(m.Imports) + len(m.Functions)
+}
+
+// TypeOfFunction returns the type of the function in the index space of
+// functions.
+func (m *Module) TypeOfFunction(idx uint32) *FuncType {
+	if int(idx) < len(m.Imports) {
+		return &m.Types[m.Imports[idx].Type]
+	}
+	idx -= uint32(len(m.Imports))
+	if int(idx) < len(m.Functions) {
+		return &m.Types[m.Functions[idx].Type]
+	}
+	return nil
+}
+
+func (m *Module) Export(name string) (*Export, bool) {
+	for i := range m.Exports {
+		if m.Exports[i].Name == name {
+			return &m.Exports[i], true
+		}
+	}
+	return nil, false
+}
+
+// Custom returns data of the first custom section with the name.
+func (m *Module) Custom(name string) ([]byte, bool) {
+	for _, c := range m.Customs {
+		if c.Name == name {
+			return c.Data, true
+		}
+	}
+	return nil, false
+}
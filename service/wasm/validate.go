@@ -0,0 +1,494 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package wasm
+
+type ctrlFrame struct {
+	op          byte
+	pc          int
+	elsePC      int
+	results     []ValueType
+	height      int
+	unreachable bool
+}
+
+func (f *ctrlFrame) labelTypes() []ValueType {
+	if f.op == opLoop {
+		return nil
+	}
+	return f.results
+}
+
+type validator struct {
+	m      *Module
+	fn     *Function
+	locals []ValueType
+	vals   []ValueType
+	ctrls  []ctrlFrame
+}
+
+func (v *validator) push(t ValueType) {
+	v.vals = append(v.vals, t)
+}
+
+func (v *validator) pop() (ValueType, error) {
+	top := &v.ctrls[len(v.ctrls)-1]
+	if len(v.vals) == top.height {
+		if top.unreachable {
+			return unknown, nil
+		}
+		return unknown, errFormat("StackUnderflow")
+	}
+	t := v.vals[len(v.vals)-1]
+	v.vals = v.vals[:len(v.vals)-1]
+	return t, nil
+}
+
+func (v *validator) popExpect(expect ValueType) (ValueType, error) {
+	t, err := v.pop()
+	if err != nil {
+		return unknown, err
+	}
+	if t != expect && t != unknown && expect != unknown {
+		return unknown, errFormat("TypeMismatch(exp=%s,real=%s)", expect, t)
+	}
+	if t == unknown {
+		return expect, nil
+	}
+	return t, nil
+}
+
+func (v *validator) popTypes(ts []ValueType) error {
+	for i := len(ts) - 1; i >= 0; i-- {
+		if _, err := v.popExpect(ts[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *validator) pushCtrl(op byte, pc int, results []ValueType) {
+	v.ctrls = append(v.ctrls, ctrlFrame{
+		op:      op,
+		pc:      pc,
+		elsePC:  -1,
+		results: results,
+		height:  len(v.vals),
+	})
+}
+
+func (v *validator) popCtrl() (ctrlFrame, error) {
+	top := v.ctrls[len(v.ctrls)-1]
+	if err := v.popTypes(top.results); err != nil {
+		return top, err
+	}
+	if len(v.vals) != top.height {
+		return top, errFormat("StackNotEmpty(pc=%d)", top.pc)
+	}
+	v.ctrls = v.ctrls[:len(v.ctrls)-1]
+	return top, nil
+}
+
+func (v *validator) setUnreachable() {
+	top := &v.ctrls[len(v.ctrls)-1]
+	v.vals = v.vals[:top.height]
+	top.unreachable = true
+}
+
+func (v *validator) label(depth uint32) (*ctrlFrame, error) {
+	if int(depth) >= len(v.ctrls) {
+		return nil, errFormat("InvalidLabel(depth=%d)", depth)
+	}
+	return &v.ctrls[len(v.ctrls)-1-int(depth)], nil
+}
+
+func blockType(code []byte, pc int) ([]ValueType, int, error) {
+	if pc >= len(code) {
+		return nil, pc, errFormat("UnexpectedEnd(pc=%d)", pc)
+	}
+	switch b := code[pc]; b {
+	case blockEmpty:
+		return nil, pc + 1, nil
+	case byte(I32), byte(I64):
+		return []ValueType{ValueType(b)}, pc + 1, nil
+	default:
+		return nil, pc, errFormat("UnsupportedBlockType(type=%#x)", b)
+	}
+}
+
+func (v *validator) readIndex(pc int) (uint32, int, error) {
+	idx, pc, err := readULEB(v.fn.Code, pc, 32)
+	return uint32(idx), pc, err
+}
+
+func (v *validator) memArg(op byte, pc int) (int, error) {
+	if v.m.Memory == nil {
+		return pc, errFormat("NoMemory(op=%#x)", op)
+	}
+	align, pc, err := v.readIndex(pc)
+	if err != nil {
+		return pc, err
+	}
+	if _, pc, err = v.readIndex(pc); err != nil {
+		return pc, err
+	}
+	size, _, _ := memoryAccess(op)
+	if 1<<align > size {
+		return pc, errFormat("InvalidAlignment(op=%#x,align=%d)", op, align)
+	}
+	return pc, nil
+}
+
+func (v *validator) validateFunction() error {
+	code := v.fn.Code
+	ft := &v.m.Types[v.fn.Type]
+	v.locals = append(append([]ValueType{}, ft.Params...), v.fn.Locals...)
+	v.fn.jumps = make(map[int]jump)
+	v.pushCtrl(opBlock, -1, ft.Results)
+
+	pc := 0
+	for len(v.ctrls) > 0 {
+		if pc >= len(code) {
+			return errFormat("UnexpectedEnd(pc=%d)", pc)
+		}
+		opPC := pc
+		op := code[pc]
+		pc++
+		var err error
+		switch op {
+		case opUnreachable:
+			v.setUnreachable()
+		case opNop:
+		case opBlock, opLoop:
+			var results []ValueType
+			if results, pc, err = blockType(code, pc); err != nil {
+				return err
+			}
+			v.pushCtrl(op, opPC, results)
+		case opIf:
+			var results []ValueType
+			if results, pc, err = blockType(code, pc); err != nil {
+				return err
+			}
+			if _, err = v.popExpect(I32); err != nil {
+				return err
+			}
+			v.pushCtrl(op, opPC, results)
+		case opElse:
+			top := v.ctrls[len(v.ctrls)-1]
+			if top.op != opIf || top.elsePC >= 0 {
+				return errFormat("UnexpectedElse(pc=%d)", opPC)
+			}
+			frame, err := v.popCtrl()
+			if err != nil {
+				return err
+			}
+			v.pushCtrl(frame.op, frame.pc, frame.results)
+			v.ctrls[len(v.ctrls)-1].elsePC = opPC
+		case opEnd:
+			frame, err := v.popCtrl()
+			if err != nil {
+				return err
+			}
+			if frame.op == opIf && frame.elsePC < 0 && len(frame.results) > 0 {
+				return errFormat("IfWithoutElse(pc=%d)", frame.pc)
+			}
+			if frame.pc >= 0 {
+				v.fn.jumps[frame.pc] = jump{elsePC: frame.elsePC, endPC: opPC}
+			}
+			for _, t := range frame.results {
+				v.push(t)
+			}
+		case opBr, opBrIf:
+			var depth uint32
+			if depth, pc, err = v.readIndex(pc); err != nil {
+				return err
+			}
+			if op == opBrIf {
+				if _, err = v.popExpect(I32); err != nil {
+					return err
+				}
+			}
+			l, err := v.label(depth)
+			if err != nil {
+				return err
+			}
+			types := l.labelTypes()
+			if err = v.popTypes(types); err != nil {
+				return err
+			}
+			if op == opBr {
+				v.setUnreachable()
+			} else {
+				for _, t := range types {
+					v.push(t)
+				}
+			}
+		case opBrTable:
+			var n uint32
+			if n, pc, err = v.readIndex(pc); err != nil {
+				return err
+			}
+			if int(n) > len(code) {
+				return errFormat("InvalidBrTable(pc=%d)", opPC)
+			}
+			depths := make([]uint32, n+1)
+			for i := range depths {
+				if depths[i], pc, err = v.readIndex(pc); err != nil {
+					return err
+				}
+			}
+			if _, err = v.popExpect(I32); err != nil {
+				return err
+			}
+			dl, err := v.label(depths[n])
+			if err != nil {
+				return err
+			}
+			types := dl.labelTypes()
+			for _, d := range depths[:n] {
+				l, err := v.label(d)
+				if err != nil {
+					return err
+				}
+				lt := l.labelTypes()
+				if len(lt) != len(types) || (len(lt) > 0 && lt[0] != types[0]) {
+					return errFormat("InconsistentBrTable(pc=%d)", opPC)
+				}
+			}
+			if err = v.popTypes(types); err != nil {
+				return err
+			}
+			v.setUnreachable()
+		case opReturn:
+			if err = v.popTypes(ft.Results); err != nil {
+				return err
+			}
+			v.setUnreachable()
+		case opCall:
+			var idx uint32
+			if idx, pc, err = v.readIndex(pc); err != nil {
+				return err
+			}
+			t := v.m.TypeOfFunction(idx)
+			if t == nil {
+				return errFormat("InvalidFunctionIndex(idx=%d)", idx)
+			}
+			if err = v.popTypes(t.Params); err != nil {
+				return err
+			}
+			for _, r := range t.Results {
+				v.push(r)
+			}
+		case opCallIndirect:
+			var idx uint32
+			if idx, pc, err = v.readIndex(pc); err != nil {
+				return err
+			}
+			if pc >= len(code) || code[pc] != 0 {
+				return errFormat("InvalidTableIndex(pc=%d)", opPC)
+			}
+			pc++
+			if v.m.Table == nil {
+				return errFormat("NoTable(pc=%d)", opPC)
+			}
+			if int(idx) >= len(v.m.Types) {
+				return errFormat("InvalidTypeIndex(idx=%d)", idx)
+			}
+			if _, err = v.popExpect(I32); err != nil {
+				return err
+			}
+			t := &v.m.Types[idx]
+			if err = v.popTypes(t.Params); err != nil {
+				return err
+			}
+			for _, r := range t.Results {
+				v.push(r)
+			}
+		case opDrop:
+			if _, err = v.pop(); err != nil {
+				return err
+			}
+		case opSelect:
+			if _, err = v.popExpect(I32); err != nil {
+				return err
+			}
+			t1, err := v.pop()
+			if err != nil {
+				return err
+			}
+			t2, err := v.popExpect(t1)
+			if err != nil {
+				return err
+			}
+			v.push(t2)
+		case opLocalGet, opLocalSet, opLocalTee:
+			var idx uint32
+			if idx, pc, err = v.readIndex(pc); err != nil {
+				return err
+			}
+			if int(idx) >= len(v.locals) {
+				return errFormat("InvalidLocalIndex(idx=%d)", idx)
+			}
+			t := v.locals[idx]
+			if op != opLocalGet {
+				if _, err = v.popExpect(t); err != nil {
+					return err
+				}
+			}
+			if op != opLocalSet {
+				v.push(t)
+			}
+		case opGlobalGet, opGlobalSet:
+			var idx uint32
+			if idx, pc, err = v.readIndex(pc); err != nil {
+				return err
+			}
+			if int(idx) >= len(v.m.Globals) {
+				return errFormat("InvalidGlobalIndex(idx=%d)", idx)
+			}
+			g := &v.m.Globals[idx]
+			if op == opGlobalGet {
+				v.push(g.Type)
+			} else {
+				if !g.Mutable {
+					return errFormat("ImmutableGlobal(idx=%d)", idx)
+				}
+				if _, err = v.popExpect(g.Type); err != nil {
+					return err
+				}
+			}
+		case opMemorySize, opMemoryGrow:
+			if v.m.Memory == nil {
+				return errFormat("NoMemory(op=%#x)", op)
+			}
+			if pc >= len(code) || code[pc] != 0 {
+				return errFormat("InvalidMemoryIndex(pc=%d)", opPC)
+			}
+			pc++
+			if op == opMemoryGrow {
+				if _, err = v.popExpect(I32); err != nil {
+					return err
+				}
+			}
+			v.push(I32)
+		case opI32Const:
+			if _, pc, err = readSLEB(code, pc, 32); err != nil {
+				return err
+			}
+			v.push(I32)
+		case opI64Const:
+			if _, pc, err = readSLEB(code, pc, 64); err != nil {
+				return err
+			}
+			v.push(I64)
+		default:
+			if _, t, ok := memoryAccess(op); ok {
+				if pc, err = v.memArg(op, pc); err != nil {
+					return err
+				}
+				if op >= opI32Store {
+					if _, err = v.popExpect(t); err != nil {
+						return err
+					}
+					if _, err = v.popExpect(I32); err != nil {
+						return err
+					}
+				} else {
+					if _, err = v.popExpect(I32); err != nil {
+						return err
+					}
+					v.push(t)
+				}
+			} else if params, result, ok := numericType(op); ok {
+				if err = v.popTypes(params); err != nil {
+					return err
+				}
+				v.push(result)
+			} else {
+				return errFormat("UnsupportedOpcode(op=%#x,pc=%d)", op, opPC)
+			}
+		}
+	}
+	if pc != len(code) {
+		return errFormat("CodeAfterEnd(pc=%d)", pc)
+	}
+	return nil
+}
+
+// Validate checks the module and prepares functions for execution.
+func (m *Module) Validate() error {
+	for i := range m.Functions {
+		v := &validator{m: m, fn: &m.Functions[i]}
+		if err := v.validateFunction(); err != nil {
+			return errFormat("InvalidFunction(idx=%d,err=%s)", i, err.Error())
+		}
+	}
+	for _, e := range m.Exports {
+		switch e.Kind {
+		case ExternalFunction:
+			if int(e.Index) >= m.NumFunctions() {
+				return errFormat("InvalidExport(name=%s)", e.Name)
+			}
+		case ExternalTable:
+			if m.Table == nil || e.Index != 0 {
+				return errFormat("InvalidExport(name=%s)", e.Name)
+			}
+		case ExternalMemory:
+			if m.Memory == nil || e.Index != 0 {
+				return errFormat("InvalidExport(name=%s)", e.Name)
+			}
+		case ExternalGlobal:
+			if int(e.Index) >= len(m.Globals) {
+				return errFormat("InvalidExport(name=%s)", e.Name)
+			}
+		}
+	}
+	for _, e := range m.Elements {
+		if m.Table == nil {
+			return errFormat("NoTable")
+		}
+		if uint64(e.Offset)+uint64(len(e.Funcs)) > uint64(m.Table.Min) {
+			return errFormat("ElementOutOfRange(offset=%d)", e.Offset)
+		}
+		for _, f := range e.Funcs {
+			if int(f) >= m.NumFunctions() {
+				return errFormat("InvalidFunctionIndex(idx=%d)", f)
+			}
+		}
+	}
+	for _, d := range m.Data {
+		if m.Memory == nil {
+			return errFormat("NoMemory")
+		}
+		if uint64(d.Offset)+uint64(len(d.Init)) > uint64(m.Memory.Min)*PageSize {
+			return errFormat("DataOutOfRange(offset=%d)", d.Offset)
+		}
+	}
+	return nil
+}
+
+// Parse decodes and validates the binary module.
+func Parse(bs []byte) (*Module, error) {
+	m, err := Decode(bs)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
@@ -0,0 +1,329 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package wasm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/service/scoreresult"
+)
+
+func noImports(module, name string) (*HostFunction, bool) {
+	return nil, false
+}
+
+// newTestInstance instantiates the module with the steps for the execution
+// in addition to the steps for the initial memory.
+func newTestInstance(t *testing.T, m *Module, steps int64) *Instance {
+	pm, err := Parse(m.Encode())
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if m.Memory != nil {
+		steps += int64(m.Memory.Min) * StepsPerPage
+	}
+	in, err := NewInstance(pm, noImports, steps)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return in
+}
+
+func singleFunction(ft FuncType, locals []ValueType, code *Code) *Module {
+	return &Module{
+		Types:     []FuncType{ft},
+		Functions: []Function{{Type: 0, Locals: locals, Code: code.End().Bytes()}},
+		Memory:    &Limits{Min: 1, Max: 2, HasMax: true},
+		Exports:   []Export{{Name: "f", Kind: ExternalFunction, Index: 0}},
+	}
+}
+
+func TestInstance_Factorial(t *testing.T) {
+	// fac(n) = n <= 1 ? 1 : n * fac(n-1)
+	m := singleFunction(
+		FuncType{Params: []ValueType{I64}, Results: []ValueType{I64}},
+		nil,
+		new(Code).
+			LocalGet(0).I64Const(2).I64LtS().
+			If(I64).
+			I64Const(1).
+			Else().
+			LocalGet(0).
+			LocalGet(0).I64Const(1).I64Sub().Call(0).
+			I64Mul().
+			End(),
+	)
+	in := newTestInstance(t, m, 10000)
+	res, err := in.Call("f", 20)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{2432902008176640000}, res)
+	assert.True(t, in.StepsLeft() < 10000)
+}
+
+func TestInstance_Loop(t *testing.T) {
+	// sum of 1..n with a loop
+	m := singleFunction(
+		FuncType{Params: []ValueType{I32}, Results: []ValueType{I32}},
+		[]ValueType{I32},
+		new(Code).
+			Block().
+			Loop().
+			LocalGet(0).I32Eqz().BrIf(1).
+			LocalGet(1).LocalGet(0).I32Add().LocalSet(1).
+			LocalGet(0).I32Const(1).I32Sub().LocalSet(0).
+			Br(0).
+			End().
+			End().
+			LocalGet(1),
+	)
+	in := newTestInstance(t, m, 100000)
+	res, err := in.Call("f", 100)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{5050}, res)
+
+	in = newTestInstance(t, m, 100)
+	_, err = in.Call("f", 100)
+	assert.Error(t, err)
+	assert.Equal(t, scoreresult.OutOfStepError, errors.CodeOf(err))
+	assert.EqualValues(t, 0, in.StepsLeft())
+}
+
+func TestInstance_BrTable(t *testing.T) {
+	m := singleFunction(
+		FuncType{Params: []ValueType{I32}, Results: []ValueType{I32}},
+		nil,
+		new(Code).
+			Block().
+			Block().
+			Block().
+			LocalGet(0).BrTable(0, 1, 2).
+			End().
+			I32Const(10).Return().
+			End().
+			I32Const(20).Return().
+			End().
+			I32Const(30),
+	)
+	in := newTestInstance(t, m, 1000)
+	for arg, exp := range map[uint64]uint64{0: 10, 1: 20, 2: 30, 3: 30, 100: 30} {
+		res, err := in.Call("f", arg)
+		assert.NoError(t, err)
+		assert.Equal(t, []uint64{exp}, res)
+	}
+}
+
+func TestInstance_Memory(t *testing.T) {
+	m := singleFunction(
+		FuncType{Params: []ValueType{I32}, Results: []ValueType{I32}},
+		nil,
+		new(Code).
+			LocalGet(0).I32Const(0x12345678).I32Store(0).
+			LocalGet(0).I32Load8U(1),
+	)
+	m.Data = []Data{{Offset: 16, Init: []byte("hello")}}
+	in := newTestInstance(t, m, 1000)
+	res, err := in.Call("f", 100)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{0x56}, res)
+
+	bs, err := in.Read(16, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(bs))
+
+	_, err = in.Call("f", PageSize-2)
+	assert.Error(t, err)
+	_, err = in.Read(PageSize-2, 5)
+	assert.Error(t, err)
+}
+
+func TestInstance_MemoryGrow(t *testing.T) {
+	m := singleFunction(
+		FuncType{Params: []ValueType{I32}, Results: []ValueType{I32}},
+		nil,
+		new(Code).LocalGet(0).MemoryGrow(),
+	)
+	in := newTestInstance(t, m, 10000)
+	res, err := in.Call("f", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{1}, res)
+	assert.Len(t, in.Memory(), 2*PageSize)
+
+	// exceeds the maximum of the memory
+	res, err = in.Call("f", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{0xffffffff}, res)
+}
+
+func TestInstance_InitialMemory(t *testing.T) {
+	m := singleFunction(
+		FuncType{Results: []ValueType{I32}},
+		nil,
+		new(Code).I32Const(1),
+	)
+	m.Memory = &Limits{Min: 2}
+	pm, err := Parse(m.Encode())
+	assert.NoError(t, err)
+
+	_, err = NewInstance(pm, noImports, 2*StepsPerPage-1)
+	assert.Error(t, err)
+	assert.Equal(t, scoreresult.OutOfStepError, errors.CodeOf(err))
+
+	in, err := NewInstance(pm, noImports, 2*StepsPerPage+10)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 10, in.StepsLeft())
+	assert.Len(t, in.Memory(), 2*PageSize)
+}
+
+func TestInstance_Traps(t *testing.T) {
+	div := singleFunction(
+		FuncType{Params: []ValueType{I32, I32}, Results: []ValueType{I32}},
+		nil,
+		new(Code).LocalGet(0).LocalGet(1).I32DivS(),
+	)
+	in := newTestInstance(t, div, 1000)
+	res, err := in.Call("f", 7, uint64(uint32(0xfffffffe)))
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{uint64(uint32(0xfffffffd))}, res)
+	_, err = in.Call("f", 1, 0)
+	assert.Error(t, err)
+	_, err = in.Call("f", 0x80000000, 0xffffffff)
+	assert.Error(t, err)
+
+	unreachable := singleFunction(FuncType{}, nil, new(Code).Unreachable())
+	in = newTestInstance(t, unreachable, 1000)
+	_, err = in.Call("f")
+	assert.Error(t, err)
+
+	recursion := singleFunction(FuncType{}, nil, new(Code).Call(0))
+	in = newTestInstance(t, recursion, 1000000)
+	_, err = in.Call("f")
+	assert.Equal(t, scoreresult.StackOverflowError, errors.CodeOf(err))
+
+	_, err = in.Call("g")
+	assert.Error(t, err)
+}
+
+func TestInstance_Host(t *testing.T) {
+	m := &Module{
+		Types: []FuncType{
+			{Params: []ValueType{I32}, Results: []ValueType{I32}},
+		},
+		Imports:   []Import{{Module: "env", Name: "twice", Type: 0}},
+		Functions: []Function{{Type: 0, Code: new(Code).LocalGet(0).Call(0).End().Bytes()}},
+		Exports:   []Export{{Name: "f", Kind: ExternalFunction, Index: 1}},
+	}
+	pm, err := Parse(m.Encode())
+	assert.NoError(t, err)
+
+	_, err = NewInstance(pm, noImports, 1000)
+	assert.Error(t, err)
+
+	hosts := func(module, name string) (*HostFunction, bool) {
+		if module == "env" && name == "twice" {
+			return &HostFunction{
+				Type: FuncType{Params: []ValueType{I32}, Results: []ValueType{I32}},
+				Call: func(in *Instance, args []uint64) ([]uint64, error) {
+					if err := in.UseSteps(10); err != nil {
+						return nil, err
+					}
+					return []uint64{args[0] * 2}, nil
+				},
+			}, true
+		}
+		return nil, false
+	}
+	in, err := NewInstance(pm, hosts, 1000)
+	assert.NoError(t, err)
+	res, err := in.Call("f", 21)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{42}, res)
+	assert.EqualValues(t, 1000-10-3, in.StepsLeft())
+
+	bad := func(module, name string) (*HostFunction, bool) {
+		return &HostFunction{Type: FuncType{}}, true
+	}
+	_, err = NewInstance(pm, bad, 1000)
+	assert.Error(t, err)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	_, err := Parse([]byte("\x00asm\x02\x00\x00\x00"))
+	assert.Error(t, err)
+
+	cases := map[string]*Module{
+		"TypeMismatch": singleFunction(
+			FuncType{Results: []ValueType{I32}}, nil,
+			new(Code).I64Const(1),
+		),
+		"StackUnderflow": singleFunction(
+			FuncType{}, nil,
+			new(Code).I32Add().Drop(),
+		),
+		"InvalidLocal": singleFunction(
+			FuncType{}, nil,
+			new(Code).LocalGet(3).Drop(),
+		),
+		"InvalidLabel": singleFunction(
+			FuncType{}, nil,
+			new(Code).Br(2),
+		),
+		"FloatType": singleFunction(
+			FuncType{Params: []ValueType{F32}}, nil,
+			new(Code),
+		),
+		"InvalidExport": {
+			Exports: []Export{{Name: "f", Kind: ExternalFunction, Index: 3}},
+		},
+	}
+	for name, m := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse(m.Encode())
+			assert.Error(t, err)
+			assert.Equal(t, scoreresult.IllegalFormatError, errors.CodeOf(err))
+		})
+	}
+
+	// float opcode (f32.add)
+	m := singleFunction(FuncType{}, nil, new(Code))
+	m.Functions[0].Code = []byte{0x92, opEnd}
+	_, err = Parse(m.Encode())
+	assert.Error(t, err)
+}
+
+func TestLEB(t *testing.T) {
+	for _, v := range []int64{0, 1, -1, 63, 64, -64, -65, 1 << 40, -(1 << 62)} {
+		bs := appendSLEB(nil, v)
+		r, pos, err := readSLEB(bs, 0, 64)
+		assert.NoError(t, err)
+		assert.Equal(t, v, r)
+		assert.Equal(t, len(bs), pos)
+	}
+	for _, v := range []uint64{0, 127, 128, 1 << 32, 1<<64 - 1} {
+		bs := appendULEB(nil, v)
+		r, pos, err := readULEB(bs, 0, 64)
+		assert.NoError(t, err)
+		assert.Equal(t, v, r)
+		assert.Equal(t, len(bs), pos)
+	}
+	_, _, err := readULEB(appendULEB(nil, 1<<32), 0, 32)
+	assert.Error(t, err)
+	_, _, err = readULEB([]byte{0x80, 0x80}, 0, 32)
+	assert.Error(t, err)
+}